@@ -221,8 +221,8 @@ Gitea or set your environment appropriately.`, "")
 		total++
 		lastline++
 
-		// If the ref is a branch, check if it's protected
-		if strings.HasPrefix(refFullName, git.BranchPrefix) {
+		// If the ref is a branch or tag, check if it's protected
+		if strings.HasPrefix(refFullName, git.BranchPrefix) || strings.HasPrefix(refFullName, git.TagPrefix) {
 			oldCommitIDs[count] = oldCommitID
 			newCommitIDs[count] = newCommitID
 			refFullNames[count] = refFullName
@@ -230,7 +230,7 @@ Gitea or set your environment appropriately.`, "")
 			fmt.Fprintf(out, "*")
 
 			if count >= hookBatchSize {
-				fmt.Fprintf(out, " Checking %d branches\n", count)
+				fmt.Fprintf(out, " Checking %d references\n", count)
 
 				hookOptions.OldCommitIDs = oldCommitIDs
 				hookOptions.NewCommitIDs = newCommitIDs
@@ -238,7 +238,7 @@ Gitea or set your environment appropriately.`, "")
 				statusCode, msg := private.HookPreReceive(username, reponame, hookOptions)
 				switch statusCode {
 				case http.StatusOK:
-					// no-op
+					warn(msg)
 				case http.StatusInternalServerError:
 					fail("Internal Server Error", msg)
 				default:
@@ -261,10 +261,12 @@ Gitea or set your environment appropriately.`, "")
 		hookOptions.NewCommitIDs = newCommitIDs[:count]
 		hookOptions.RefFullNames = refFullNames[:count]
 
-		fmt.Fprintf(out, " Checking %d branches\n", count)
+		fmt.Fprintf(out, " Checking %d references\n", count)
 
 		statusCode, msg := private.HookPreReceive(username, reponame, hookOptions)
 		switch statusCode {
+		case http.StatusOK:
+			warn(msg)
 		case http.StatusInternalServerError:
 			fail("Internal Server Error", msg)
 		case http.StatusForbidden:
@@ -279,6 +281,16 @@ Gitea or set your environment appropriately.`, "")
 	return nil
 }
 
+// warn prints the warnings returned by the pre-receive check to the pusher
+func warn(msg string) {
+	if len(msg) == 0 {
+		return
+	}
+	for _, line := range strings.Split(msg, "\n") {
+		fmt.Fprintln(os.Stderr, "Gitea:", line)
+	}
+}
+
 func runHookUpdate(c *cli.Context) error {
 	// Update is empty and is kept only for backwards compatibility
 	return nil
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/activitypub"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityPubActors(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(enabled bool) { setting.Federation.Enabled = enabled }(setting.Federation.Enabled)
+	setting.Federation.Enabled = true
+
+	req := NewRequest(t, "GET", "/user2")
+	req.Header.Set("Accept", activitypub.ContentType)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, activitypub.ContentType+"; charset=utf-8", resp.Header().Get("Content-Type"))
+	var actor activitypub.Actor
+	DecodeJSON(t, resp, &actor)
+	assert.Equal(t, "Person", actor.Type)
+	assert.Equal(t, setting.AppURL+"user2", actor.ID)
+
+	req = NewRequest(t, "GET", "/user2/repo1")
+	req.Header.Set("Accept", `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &actor)
+	assert.Equal(t, "Repository", actor.Type)
+	assert.Equal(t, setting.AppURL+"user2/repo1", actor.ID)
+
+	// private repositories have no actor
+	req = NewRequest(t, "GET", "/user2/repo2")
+	req.Header.Set("Accept", activitypub.ContentType)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// browsers still get the web page
+	req = NewRequest(t, "GET", "/user2")
+	req.Header.Set("Accept", "text/html")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, resp.Header().Values("Vary"), "Accept")
+}
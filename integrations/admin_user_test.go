@@ -7,9 +7,11 @@ package integrations
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -80,3 +82,91 @@ func TestAdminDeleteUser(t *testing.T) {
 	assertUserDeleted(t, 8)
 	models.CheckConsistencyFor(t, &models.User{})
 }
+
+func TestAdminImpersonateUser(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	csrf := GetCSRF(t, session, "/admin/users/2")
+	req := NewRequestWithValues(t, "POST", "/admin/users/2/impersonate", map[string]string{
+		"_csrf": csrf,
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	// the settings of the user can be seen
+	csrf = GetCSRF(t, session, "/user/settings")
+
+	// but the credentials, the emails and the sessions of the user cannot be changed
+	for _, test := range []struct {
+		Method string
+		Link   string
+		Values map[string]string
+	}{
+		{"GET", "/user/settings/applications", nil},
+		{"POST", "/user/settings/applications", map[string]string{"name": "impersonated"}},
+		{"POST", "/user/settings/applications/oauth2", map[string]string{"application_name": "impersonated", "redirect_uri": "https://example.com/"}},
+		{"POST", "/user/settings/account/email", map[string]string{"email": "impersonated@example.com"}},
+		{"POST", "/user/settings/account/email/delete", map[string]string{"id": "3"}},
+		{"GET", "/user/settings/security/two_factor/enroll", nil},
+		{"POST", "/user/settings/security/two_factor/disable", nil},
+		{"POST", "/user/settings/security/webauthn/request_register", map[string]string{"name": "impersonated"}},
+		{"POST", "/user/settings/security/webauthn/delete", map[string]string{"id": "1"}},
+		{"POST", "/user/settings/keys", map[string]string{"type": "ssh", "title": "impersonated", "content": "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABgQC4cn+iXnA4KvcQYSV88vGn0Yi91vG47t1P7okprVmhNTkipNRIHWr6WdCO4VDr/cvsRkuVJAsLO2enwjGWWueOO6BodiBgyAOZ/5t5nJNMCNuLGT5UIo/RI1b0WRQwxEZTRjt6mFNw6lH14wRd8ulsr9toSWBPMOGWoYs1PDeDL0JuTjL+tr1SZi/EyxCngpYszKdXllJEHyI79KQgeD0Vt3pTrkbNVTOEcCNqZePSVmUH8X8Vhugz3bnE0/iE9Pb5fkWO9c4AnM1FgI/8Bvp27Fw2ShryIXuR6kKvUqhVMTuOSDHwu6A8jLE5Owt3GAYugDpDYuwTVNGrHLXKpPzrGGPE/jPmaLCMZcsdkec95dYeU3zKODEm8UQZFhmJmDeWVJ36nGrGZHL4J5aTTaeFUJmmXDaJYiJ+K2/ioKgXqnXvltu0A9R8/LGy4nrTJRr4JMLuJFoUXvGm1gXQ70w2LSpk6yl71RNC0hCtsBe8BP8IhYCM0EP5jh7eCMQZNvM= nocomment"}},
+		{"POST", "/user/settings/keys/delete", map[string]string{"type": "ssh", "id": "1"}},
+		{"POST", "/user/settings/security/openid", map[string]string{"openid": "https://example.com/impersonated"}},
+		{"POST", "/user/settings/security/account_link", map[string]string{"loginSourceID": "1"}},
+		{"POST", "/user/settings/security/sessions/delete", map[string]string{"id": "1"}},
+		{"POST", "/user/settings/security/sessions/delete_others", nil},
+		{"POST", "/user/settings/security/remembered_device/delete", map[string]string{"id": "1"}},
+	} {
+		if test.Method == "GET" {
+			req = NewRequest(t, "GET", test.Link)
+		} else {
+			values := map[string]string{"_csrf": csrf}
+			for key, value := range test.Values {
+				values[key] = value
+			}
+			req = NewRequestWithValues(t, "POST", test.Link, values)
+		}
+		session.MakeRequest(t, req, http.StatusForbidden)
+	}
+
+	models.AssertNotExistsBean(t, &models.AccessToken{UID: 2, Name: "impersonated"})
+	models.AssertNotExistsBean(t, &models.EmailAddress{UID: 2, Email: "impersonated@example.com"})
+	models.AssertNotExistsBean(t, &models.PublicKey{OwnerID: 2, Name: "impersonated"})
+}
+
+func TestAdminImportUsers(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(confirm bool) {
+		setting.Service.RegisterEmailConfirm = confirm
+	}(setting.Service.RegisterEmailConfirm)
+
+	session := loginUser(t, "user1")
+	importUsers := func(t *testing.T, content string) *HTMLDoc {
+		req := NewRequestWithValues(t, "POST", "/admin/users/import", map[string]string{
+			"_csrf":   GetCSRF(t, session, "/admin/users/import"),
+			"content": content,
+			"confirm": "true",
+		})
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		return NewHTMLParser(t, resp.Body)
+	}
+
+	// the generated passwords are shown once the users have been created
+	setting.Service.RegisterEmailConfirm = false
+	htmlDoc := importUsers(t, "username,email\nimported1,imported1@example.com\n")
+	imported := models.AssertExistsAndLoadBean(t, &models.User{Name: "imported1"}).(*models.User)
+	assert.True(t, imported.IsActive)
+	assert.True(t, imported.MustChangePassword)
+	generated := strings.TrimSpace(htmlDoc.doc.Find("tbody code").Text())
+	assert.NotEmpty(t, generated)
+	assert.True(t, imported.ValidatePassword(generated))
+
+	// the users have to activate their account if email addresses must be confirmed
+	setting.Service.RegisterEmailConfirm = true
+	importUsers(t, "username,email\nimported2,imported2@example.com\n")
+	imported = models.AssertExistsAndLoadBean(t, &models.User{Name: "imported2"}).(*models.User)
+	assert.False(t, imported.IsActive)
+}
@@ -84,6 +84,39 @@ func TestAPIListIssueComments(t *testing.T) {
 	assert.EqualValues(t, expectedCount, len(comments))
 }
 
+func TestAPIListIssueTimeline(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: issue.RepoID}).(*models.Repository)
+	repoOwner := models.AssertExistsAndLoadBean(t, &models.User{ID: repo.OwnerID}).(*models.User)
+
+	session := loginUser(t, repoOwner.Name)
+	req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/issues/%d/timeline",
+		repoOwner.Name, repo.Name, issue.Index)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+
+	var events []*api.TimelineComment
+	DecodeJSON(t, resp, &events)
+	expectedCount := models.GetCount(t, &models.Comment{IssueID: issue.ID})
+	assert.EqualValues(t, expectedCount, len(events))
+	assert.Equal(t, "label", events[0].Type)
+	if assert.NotNil(t, events[0].Label) {
+		assert.EqualValues(t, 1, events[0].Label.ID)
+	}
+	assert.False(t, events[0].LabelRemoved)
+	assert.Equal(t, "comment", events[1].Type)
+
+	// paginated
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/issues/%d/timeline?page=2&limit=1",
+		repoOwner.Name, repo.Name, issue.Index)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &events)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "comment", events[0].Type)
+	}
+}
+
 func TestAPICreateComment(t *testing.T) {
 	defer prepareTestEnv(t)()
 	const commentBody = "Comment body"
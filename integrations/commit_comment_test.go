@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitComments(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	const commitURL = "/user2/repo1/commit/65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	session := loginUser(t, "user5")
+	req := NewRequest(t, "GET", commitURL)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	req = NewRequestWithValues(t, "POST", commitURL+"/comments", map[string]string{
+		"_csrf":     htmlDoc.GetCSRF(),
+		"content":   "Looks **good**",
+		"tree_path": "README.md",
+		"line":      "1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	comment := models.AssertExistsAndLoadBean(t, &models.CommitComment{RepoID: 1, PosterID: 5}).(*models.CommitComment)
+	assert.Equal(t, "README.md", comment.TreePath)
+	assert.EqualValues(t, 1, comment.Line)
+
+	// the comment is rendered on the commit page of anonymous users too
+	req = NewRequest(t, "GET", commitURL)
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("#"+comment.HashTag()+" strong:contains(good)").Length())
+
+	// files which do not exist in the commit are refused
+	req = NewRequestWithValues(t, "POST", commitURL+"/comments", map[string]string{
+		"_csrf":     GetCSRF(t, session, commitURL),
+		"content":   "Nothing here",
+		"tree_path": "missing.md",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.CommitComment{RepoID: 1, TreePath: "missing.md"})
+
+	// only the poster and code writers can delete a comment
+	deleteURL := fmt.Sprintf("%s/comments/%d/delete", commitURL, comment.ID)
+	other := loginUser(t, "user4")
+	req = NewRequestWithValues(t, "POST", deleteURL, map[string]string{
+		"_csrf": GetCSRF(t, other, commitURL),
+	})
+	other.MakeRequest(t, req, http.StatusForbidden)
+
+	owner := loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", deleteURL, map[string]string{
+		"_csrf": GetCSRF(t, owner, commitURL),
+	})
+	owner.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.CommitComment{ID: comment.ID})
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRawDiffAndPatch(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// master...branch2 has two commits
+	req := NewRequest(t, "GET", "/user2/repo1/compare/master...branch2.patch")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "Subject: [PATCH 1/2]")
+	assert.Contains(t, resp.Body.String(), "Subject: [PATCH 2/2]")
+
+	req = NewRequest(t, "GET", "/user2/repo1/compare/master...branch2.patch?combined=true")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.HasPrefix(resp.Body.String(), "diff --git "))
+
+	req = NewRequest(t, "GET", "/user2/repo1/compare/master...branch2.diff")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.HasPrefix(resp.Body.String(), "diff --git "))
+
+	req = NewRequest(t, "GET", "/user2/repo1/compare/master...branch2.diff?combined=false")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.True(t, strings.HasPrefix(resp.Body.String(), "commit "))
+	assert.Equal(t, 1, strings.Count(resp.Body.String(), "\ncommit "))
+
+	// the code of private repositories is not readable anonymously
+	req = NewRequest(t, "GET", "/user2/repo2/compare/master...master.diff")
+	MakeRequest(t, req, http.StatusNotFound)
+}
@@ -62,3 +62,22 @@ func TestDownloadByIDMediaForSVGUsesSecureHeaders(t *testing.T) {
 	assert.Equal(t, "image/svg+xml", resp.HeaderMap.Get("Content-Type"))
 	assert.Equal(t, "nosniff", resp.HeaderMap.Get("X-Content-Type-Options"))
 }
+
+func TestSingleDownloadLastModified(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/raw/branch/master/README.md")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	lastModified := resp.HeaderMap.Get("Last-Modified")
+	assert.NotEmpty(t, lastModified)
+
+	req = NewRequest(t, "GET", "/user2/repo1/raw/branch/master/README.md")
+	req.Header.Set("If-Modified-Since", lastModified)
+	session.MakeRequest(t, req, http.StatusNotModified)
+
+	req = NewRequest(t, "GET", "/user2/repo1/raw/branch/master/README.md")
+	req.Header.Set("If-Modified-Since", "Mon, 02 Jan 2006 15:04:05 GMT")
+	session.MakeRequest(t, req, http.StatusOK)
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitObjects(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/user2/repo1/git/blobs/4b4851ad51df6a7d9f25c979345979eaeb5b349f")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var blob api.GitBlobResponse
+	DecodeJSON(t, resp, &blob)
+	assert.Equal(t, "4b4851ad51df6a7d9f25c979345979eaeb5b349f", blob.SHA)
+	assert.Equal(t, "IyByZXBvMQoKRGVzY3JpcHRpb24gZm9yIHJlcG8x", blob.Content)
+
+	req = NewRequest(t, "GET", "/user2/repo1/git/blobs/4b4851ad51df6a7d9f25c979345979eaeb5b349f?raw=true")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "# repo1\n\nDescription for repo1", resp.Body.String())
+
+	req = NewRequest(t, "GET", "/user2/repo1/git/commits/65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var commit api.Commit
+	DecodeJSON(t, resp, &commit)
+	assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", commit.SHA)
+	assert.Equal(t, "Initial commit\n", commit.RepoCommit.Message)
+
+	// the SHAs must be complete and address an object of the requested type
+	req = NewRequest(t, "GET", "/user2/repo1/git/commits/65f1bf27")
+	MakeRequest(t, req, http.StatusBadRequest)
+	req = NewRequest(t, "GET", "/user2/repo1/git/commits/4b4851ad51df6a7d9f25c979345979eaeb5b349f")
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/user2/repo1/git/blobs/0000000000000000000000000000000000000001")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// the objects of private repositories require read access
+	req = NewRequest(t, "GET", "/user2/repo2/git/commits/205ac761f3326a7ebe416e8673760016450b5cec")
+	MakeRequest(t, req, http.StatusNotFound)
+	session := loginUser(t, "user2")
+	session.MakeRequest(t, req, http.StatusOK)
+}
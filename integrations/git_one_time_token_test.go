@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitOneTimeTokenClone(t *testing.T) {
+	onGiteaRun(t, testGitOneTimeTokenClone)
+}
+
+func testGitOneTimeTokenClone(t *testing.T, u *url.URL) {
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	createGitToken := func(t *testing.T) string {
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo2/git/tokens?token="+token, &api.CreateGitTokenOption{})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var gitToken api.GitToken
+		DecodeJSON(t, resp, &gitToken)
+		return gitToken.Token
+	}
+
+	// clone clones the private repository with the token, a clone takes several requests
+	// with both versions of the protocol
+	clone := func(t *testing.T, gitToken, protocolVersion string) error {
+		dstPath, err := ioutil.TempDir("", "repo2-one-time-token")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		cloneURL := *u
+		cloneURL.Path = "/user2/repo2.git"
+		cloneURL.User = url.UserPassword("user2", gitToken)
+		if _, err = git.NewCommand("-c", "protocol.version="+protocolVersion, "clone", cloneURL.String(), dstPath).Run(); err != nil {
+			return err
+		}
+		exist, err := util.IsExist(filepath.Join(dstPath, "Home.md"))
+		assert.NoError(t, err)
+		assert.True(t, exist)
+		return nil
+	}
+
+	for _, protocolVersion := range []string{"2", "0"} {
+		t.Run("ProtocolV"+protocolVersion, func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+			gitToken := createGitToken(t)
+			assert.NoError(t, clone(t, gitToken, protocolVersion))
+
+			// the token has been consumed by the clone
+			used, err := models.GetGitOneTimeToken(gitToken)
+			assert.NoError(t, err)
+			assert.Greater(t, int64(used.UsedUnix), int64(0))
+
+			// and cannot be used for another operation
+			assert.Error(t, clone(t, gitToken, protocolVersion))
+			req := NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+			req.SetBasicAuth("user2", gitToken)
+			MakeRequest(t, req, http.StatusUnauthorized)
+		})
+	}
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		assert.Error(t, clone(t, "invalid", "2"))
+	})
+}
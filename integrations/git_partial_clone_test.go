@@ -0,0 +1,83 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPartialClone(t *testing.T) {
+	onGiteaRun(t, testGitPartialClone)
+}
+
+func testGitPartialClone(t *testing.T, u *url.URL) {
+	if err := git.CheckGitVersionAtLeast("2.19"); err != nil {
+		t.Skip("partial clone requires git >= 2.19")
+	}
+	u.Path = NewAPITestContext(t, "user2", "repo1").GitPath()
+	u.User = url.UserPassword("user2", userPassword)
+
+	// missingObjects clones the repository with a blob filter and returns the number of objects left out
+	missingObjects := func(t *testing.T, depth string) int {
+		dstPath, err := ioutil.TempDir("", "repo1-partial")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		args := []string{"clone", "--filter=blob:none", "--no-checkout"}
+		if len(depth) > 0 {
+			args = append(args, "--depth", depth)
+		}
+		_, err = git.NewCommand(append(args, u.String(), dstPath)...).Run()
+		assert.NoError(t, err)
+
+		out, err := git.NewCommand("rev-list", "--objects", "--missing=print", "HEAD").RunInDir(dstPath)
+		assert.NoError(t, err)
+		missing := 0
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "?") {
+				missing++
+			}
+		}
+
+		// the filtered out blobs are fetched on demand
+		_, err = git.NewCommand("checkout", "master").RunInDir(dstPath)
+		assert.NoError(t, err)
+		exist, err := util.IsExist(filepath.Join(dstPath, "README.md"))
+		assert.NoError(t, err)
+		assert.True(t, exist)
+		return missing
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		assert.Greater(t, missingObjects(t, ""), 0)
+	})
+
+	t.Run("FilterAndDepth", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		assert.Greater(t, missingObjects(t, "1"), 0)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		defer func(enabled bool) {
+			setting.Repository.EnablePartialClone = enabled
+		}(setting.Repository.EnablePartialClone)
+		setting.Repository.EnablePartialClone = false
+
+		// the filter is ignored, the clone is complete
+		assert.Equal(t, 0, missingObjects(t, ""))
+	})
+}
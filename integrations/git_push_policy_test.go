@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushForbiddenPathsAdminBypass(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		// user2 owns the organization user3 and thus administrates repo3
+		policy, err := models.GetPushPolicyByRepoID(3)
+		assert.NoError(t, err)
+		policy.ForbiddenPaths = "*.local"
+		policy.ForbiddenPathsAdminBypass = true
+		assert.NoError(t, models.UpdatePushPolicy(policy))
+
+		orgPolicy, err := models.GetOrgPushPolicy(3)
+		assert.NoError(t, err)
+		orgPolicy.ForbiddenPaths = "*.secret"
+		assert.NoError(t, models.UpdateOrgPushPolicy(orgPolicy))
+
+		dstPath, err := ioutil.TempDir("", "repo3")
+		assert.NoError(t, err)
+		defer util.RemoveAll(dstPath)
+
+		u.Path = "user3/repo3.git"
+		u.User = url.UserPassword("user2", userPassword)
+		t.Run("Clone", doGitClone(dstPath, u))
+
+		commitFile := func(t *testing.T, name string) {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dstPath, name), []byte("token"), 0644))
+			assert.NoError(t, git.AddChanges(dstPath, true))
+			signature := git.Signature{
+				Email: "user2@example.com",
+				Name:  "User Two",
+				When:  time.Now(),
+			}
+			assert.NoError(t, git.CommitChanges(dstPath, git.CommitChangesOptions{
+				Committer: &signature,
+				Author:    &signature,
+				Message:   "Add " + name,
+			}))
+		}
+
+		// The paths forbidden by the repository may be bypassed by its admins
+		commitFile(t, "settings.local")
+		t.Run("PushRepoForbiddenPath", doGitPushTestRepository(dstPath, "origin", "master"))
+
+		// but never those forbidden by the organization
+		commitFile(t, "deploy.secret")
+		t.Run("PushOrgForbiddenPath", doGitPushTestRepositoryFail(dstPath, "origin", "master"))
+	})
+}
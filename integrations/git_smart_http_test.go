@@ -0,0 +1,137 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitSmartHTTPProtocol(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	infoRefs := func(t *testing.T, protocol string) string {
+		req := NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+		if len(protocol) > 0 {
+			req.Header.Set("Git-Protocol", protocol)
+		}
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, "application/x-git-upload-pack-advertisement", resp.Header().Get("Content-Type"))
+		return resp.Body.String()
+	}
+
+	uploadPack := func(t *testing.T, protocol, body string) string {
+		req := NewRequestWithBody(t, "POST", "/user2/repo1.git/git-upload-pack", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-git-upload-pack-request")
+		if len(protocol) > 0 {
+			req.Header.Set("Git-Protocol", protocol)
+		}
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, "application/x-git-upload-pack-result", resp.Header().Get("Content-Type"))
+		return resp.Body.String()
+	}
+
+	t.Run("Legacy", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		body := infoRefs(t, "")
+		assert.True(t, strings.HasPrefix(body, "001e# service=git-upload-pack\n0000"))
+		assert.Contains(t, body, "refs/heads/master")
+
+		// an unknown version falls back to the original protocol
+		body = infoRefs(t, "version=3")
+		assert.True(t, strings.HasPrefix(body, "001e# service=git-upload-pack\n0000"))
+
+		// a client which wants nothing gets nothing
+		assert.Empty(t, uploadPack(t, "", "0000"))
+	})
+
+	t.Run("V2", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		body := infoRefs(t, "version=2")
+		assert.True(t, strings.HasPrefix(body, "000eversion 2\n"))
+		assert.Contains(t, body, "ls-refs")
+		assert.NotContains(t, body, "refs/heads/master")
+
+		body = uploadPack(t, "version=2", "0014command=ls-refs\n0001001bref-prefix refs/heads/\n0000")
+		assert.Contains(t, body, "refs/heads/master\n")
+		assert.NotContains(t, body, "refs/tags/")
+	})
+	t.Run("MaxAdvertisedRefs", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		defer func(limit int64) {
+			setting.Git.MaxAdvertisedRefs = limit
+		}(setting.Git.MaxAdvertisedRefs)
+		setting.Git.MaxAdvertisedRefs = 1
+
+		body := infoRefs(t, "")
+		assert.True(t, strings.HasPrefix(body, "001e# service=git-upload-pack\n0000"))
+		assert.Contains(t, body, "ERR the repository has more than 1 refs")
+		assert.NotContains(t, body, "refs/heads/master")
+
+		// protocol v2 clients are not limited
+		body = uploadPack(t, "version=2", "0014command=ls-refs\n0001001bref-prefix refs/heads/\n0000")
+		assert.Contains(t, body, "refs/heads/master\n")
+	})
+	t.Run("DisabledCapabilities", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		defer func(capabilities []string) {
+			setting.Git.DisabledCapabilities = capabilities
+		}(setting.Git.DisabledCapabilities)
+		setting.Git.DisabledCapabilities = []string{"ofs-delta", "shallow"}
+
+		body := infoRefs(t, "")
+		assert.Contains(t, body, "refs/heads/master")
+		assert.Contains(t, body, "thin-pack")
+		assert.NotContains(t, body, "ofs-delta")
+		assert.NotContains(t, body, "shallow")
+
+		body = infoRefs(t, "version=2")
+		assert.Contains(t, body, "ls-refs")
+		assert.Contains(t, body, "fetch")
+		assert.NotContains(t, body, "shallow")
+	})
+}
+
+func TestGitRequireAuth(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	repo.RequireGitAuth = true
+	assert.NoError(t, models.UpdateRepositoryCols(repo, "require_git_auth"))
+
+	req := NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-upload-pack")
+	req = AddBasicAuthHeader(req, "user4")
+	MakeRequest(t, req, http.StatusOK)
+
+	// the git data served outside of the git operations is protected too
+	for _, link := range []string{
+		"/user2/repo1/archive/master.bundle",
+		"/api/v1/repos/user2/repo1/archive/master.bundle",
+		"/user2/repo1/git/blobs/4b4851ad51df6a7d9f25c979345979eaeb5b349f",
+		"/user2/repo1/git/commits/65f1bf27bc3bf70f64657658635e66094edbcb4d",
+	} {
+		req = NewRequest(t, "GET", link)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "GET", link)
+		req = AddBasicAuthHeader(req, "user4")
+		MakeRequest(t, req, http.StatusOK)
+	}
+
+	// the repository can still be browsed anonymously
+	req = NewRequest(t, "GET", "/user2/repo1")
+	MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo1/archive/master.tar.gz")
+	MakeRequest(t, req, http.StatusOK)
+}
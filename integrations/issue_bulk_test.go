@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkEditIssues(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/user2/repo1/issues")
+	bulkEdit := func(expectedStatus int, values map[string]interface{}) *httptest.ResponseRecorder {
+		req := NewRequestWithJSON(t, "POST", "/user2/repo1/issues/bulk", values)
+		req.Header.Add("X-Csrf-Token", csrf)
+		return session.MakeRequest(t, req, expectedStatus)
+	}
+
+	// issue index 4 is closed already, pull request index 2 is closed with the issues
+	resp := bulkEdit(http.StatusOK, map[string]interface{}{
+		"issue_indexes": []int64{1, 2, 4},
+		"action":        "close",
+	})
+	var result struct {
+		OK        bool
+		Succeeded []int64
+		Failed    []struct {
+			Index   int64
+			Message string
+		}
+	}
+	DecodeJSON(t, resp, &result)
+	assert.True(t, result.OK)
+	assert.Equal(t, []int64{1, 2, 4}, result.Succeeded)
+	assert.Empty(t, result.Failed)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsClosed: true})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2, IsClosed: true})
+
+	bulkEdit(http.StatusOK, map[string]interface{}{
+		"issue_indexes": []int64{1},
+		"action":        "reopen",
+	})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsClosed: false})
+
+	// nothing is changed when an index does not exist
+	bulkEdit(http.StatusNotFound, map[string]interface{}{
+		"issue_indexes": []int64{1, 99},
+		"action":        "add_label",
+		"id":            2,
+	})
+	models.AssertNotExistsBean(t, &models.IssueLabel{IssueID: 1, LabelID: 2})
+
+	// label 5 and milestone 4 belong to other repositories
+	bulkEdit(http.StatusUnprocessableEntity, map[string]interface{}{
+		"issue_indexes": []int64{1},
+		"action":        "add_label",
+		"id":            5,
+	})
+	bulkEdit(http.StatusUnprocessableEntity, map[string]interface{}{
+		"issue_indexes": []int64{1},
+		"action":        "set_milestone",
+		"id":            4,
+	})
+	bulkEdit(http.StatusUnprocessableEntity, map[string]interface{}{
+		"issue_indexes": []int64{1},
+		"action":        "lock",
+	})
+
+	// a reader cannot bulk edit
+	readerSession := loginUser(t, "user4")
+	req := NewRequestWithJSON(t, "POST", "/user2/repo1/issues/bulk", map[string]interface{}{
+		"issue_indexes": []int64{1},
+		"action":        "close",
+	})
+	req.Header.Add("X-Csrf-Token", GetCSRF(t, readerSession, "/user2/repo1/issues"))
+	readerSession.MakeRequest(t, req, http.StatusNotFound)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsClosed: false})
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentEditHistory(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	comment := models.AssertExistsAndLoadBean(t, &models.Comment{ID: 2}).(*models.Comment)
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/user2/repo1/issues/1")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/comments/2", map[string]string{
+		"_csrf":   csrf,
+		"content": "better work!",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+
+	// the edited comment links to its history
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[href="/user2/repo1/comments/2/history"]`, true)
+
+	// readers can view the history but not restore a version
+	readerSession := loginUser(t, "user4")
+	req = NewRequest(t, "GET", "/user2/repo1/comments/2/history")
+	resp = readerSession.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find("pre.comment-version").Length())
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("pre.comment-version .added-code").Length())
+	htmlDoc.AssertElement(t, ".comment-history form", false)
+
+	original := models.AssertExistsAndLoadBean(t, &models.CommentVersion{CommentID: 2, Content: comment.Content}).(*models.CommentVersion)
+	restoreURL := fmt.Sprintf("/user2/repo1/comments/2/history/%d/restore", original.ID)
+	req = NewRequestWithValues(t, "POST", restoreURL, map[string]string{
+		"_csrf": GetCSRF(t, readerSession, "/user2/repo1/issues/1"),
+	})
+	readerSession.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithValues(t, "POST", restoreURL, map[string]string{
+		"_csrf": csrf,
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Comment{ID: 2, Content: comment.Content})
+	assert.EqualValues(t, 3, models.GetCount(t, &models.CommentVersion{CommentID: 2}))
+}
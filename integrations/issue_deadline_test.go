@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueDeadline(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/user2/repo1/issues/1")
+	dueDate := time.Date(2001, time.January, 1, 12, 0, 0, 0, time.Local)
+	req := NewRequestWithJSON(t, "POST", "/user2/repo1/issues/1/deadline", map[string]interface{}{
+		"due_date": dueDate,
+	})
+	req.Header.Add("X-Csrf-Token", csrf)
+	session.MakeRequest(t, req, http.StatusOK)
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.Equal(t, "2001-01-01", issue.DeadlineUnix.Format("2006-01-02"))
+	assert.True(t, issue.IsOverdue())
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeAddedDeadline})
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues?due=overdue")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.Find(".issue.list li.item").Length())
+
+	// a reader cannot change the due date
+	readerSession := loginUser(t, "user4")
+	req = NewRequestWithJSON(t, "POST", "/user2/repo1/issues/1/deadline", map[string]interface{}{
+		"due_date": nil,
+	})
+	req.Header.Add("X-Csrf-Token", GetCSRF(t, readerSession, "/user2/repo1/issues/1"))
+	readerSession.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "POST", "/user2/repo1/issues/1/deadline", map[string]interface{}{
+		"due_date": nil,
+	})
+	req.Header.Add("X-Csrf-Token", csrf)
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}, "deadline_unix = 0")
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/unknwon/i18n"
+)
+
+func TestCrossRepositoryIssueDependencies(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1?token="+token, &api.EditRepoOption{
+		InternalTracker: &api.InternalTracker{
+			EnableTimeTracker:                true,
+			AllowOnlyContributorsToTrackTime: true,
+			EnableIssueDependencies:          true,
+		},
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+
+	// issue 7 is in the private repo2 of user2
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/dependency/add", map[string]string{
+		"_csrf":         GetCSRF(t, session, "/user2/repo1/issues/1"),
+		"newDependency": "7",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertExistsAndLoadBean(t, &models.IssueDependency{IssueID: 1, DependencyID: 7})
+	models.AssertExistsAndLoadBean(t, &models.Comment{Type: models.CommentTypeAddDependency, IssueID: 7, DependentIssueID: 1})
+
+	// user4 can write the issues of repo1 but cannot read the ones of repo2
+	repo1 := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	user4 := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	assert.NoError(t, repo1.AddCollaborator(user4))
+	session = loginUser(t, "user4")
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.NotContains(t, resp.Body.String(), "issue7")
+	assert.Contains(t, htmlDoc.doc.Find(".ui.depending").Text(), i18n.Tr("en", "repo.issues.dependency.no_permission"))
+
+	// an issue of repo2 cannot be added by user4
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/dependency/add", map[string]string{
+		"_csrf":         htmlDoc.GetCSRF(),
+		"newDependency": "4",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertNotExistsBean(t, &models.IssueDependency{IssueID: 1, DependencyID: 4})
+
+	// but the dependency user4 cannot read can be removed
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/1/dependency/delete", map[string]string{
+		"_csrf":              htmlDoc.GetCSRF(),
+		"removeDependencyID": "7",
+		"dependencyType":     "blockedBy",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertNotExistsBean(t, &models.IssueDependency{IssueID: 1, DependencyID: 7})
+}
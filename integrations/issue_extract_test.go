@@ -0,0 +1,45 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCommentToIssue(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// a reader cannot extract comments
+	readerSession := loginUser(t, "user4")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/comments/2/extract", map[string]string{
+		"_csrf": GetCSRF(t, readerSession, "/user2/repo1/issues/1"),
+		"title": "follow up",
+	})
+	readerSession.MakeRequest(t, req, http.StatusNotFound)
+
+	session := loginUser(t, "user2")
+	csrf := GetCSRF(t, session, "/user2/repo1/issues/1")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/comments/2/extract", map[string]string{
+		"_csrf":          csrf,
+		"title":          "follow up",
+		"inherit_labels": "on",
+	})
+	resp := session.MakeRequest(t, req, http.StatusSeeOther)
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: 1, Title: "follow up"}).(*models.Issue)
+	assert.Equal(t, issue.HTMLURL(), resp.Header().Get("Location"))
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: issue.ID, LabelID: 1})
+	models.AssertExistsAndLoadBean(t, &models.Comment{ID: 2, ExtractedIssueID: issue.ID})
+
+	req = NewRequest(t, "GET", "/user2/repo1/issues/1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.Find(".comment-extracted").Length())
+}
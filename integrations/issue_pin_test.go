@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuePin(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	for _, index := range []string{"1", "5"} {
+		csrf := GetCSRF(t, session, "/user2/repo1/issues/"+index)
+		req := NewRequestWithValues(t, "POST", "/user2/repo1/issues/"+index+"/pin", map[string]string{
+			"_csrf": csrf,
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+	}
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, PinOrder: 1})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 5, PinOrder: 2})
+
+	req := NewRequest(t, "GET", "/user2/repo1/issues")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.Find("#pinned-issues .item").Length())
+
+	csrf := GetCSRF(t, session, "/user2/repo1/issues")
+	req = NewRequestWithJSON(t, "POST", "/user2/repo1/issues/pins/reorder", map[string]interface{}{
+		"issue_ids": []int64{5, 1},
+	})
+	req.Header.Add("X-Csrf-Token", csrf)
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 5, PinOrder: 1})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, PinOrder: 2})
+
+	req = NewRequestWithJSON(t, "POST", "/user2/repo1/issues/pins/reorder", map[string]interface{}{
+		"issue_ids": []int64{5},
+	})
+	req.Header.Add("X-Csrf-Token", csrf)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// pinning again unpins
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/5/pin", map[string]string{
+		"_csrf": csrf,
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, PinOrder: 1})
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 5}).(*models.Issue)
+	assert.False(t, issue.IsPinned())
+}
@@ -53,6 +53,7 @@ func TestRedirectsNoLogin(t *testing.T) {
 		"/user2/repo1/src/master/directory/file.txt": "/user2/repo1/src/branch/master/directory/file.txt",
 		"/user/avatar/Ghost/-1":                      "/img/avatar_default.png",
 		"/api/v1/swagger":                            "/api/swagger",
+		"/.well-known/change-password":               "/user/settings/account",
 	}
 	for link, redirectLink := range redirects {
 		req := NewRequest(t, "GET", link)
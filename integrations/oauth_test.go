@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/setting"
 
 	jsoniter "github.com/json-iterator/go"
@@ -102,6 +103,57 @@ func TestAccessTokenExchangeWithoutPKCE(t *testing.T) {
 	assert.True(t, len(parsed.RefreshToken) > 10)
 }
 
+func TestAccessTokenExchangeWithPublicClient(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// a public client authenticates with the PKCE code verifier instead of the client secret
+	req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":   "authorization_code",
+		"client_id":    "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"redirect_uri": "a",
+		"code":         "authcode",
+	})
+	MakeRequest(t, req, 400)
+
+	// the code can only be exchanged by the application it was granted to
+	other, err := models.CreateOAuth2Application(models.CreateOAuth2ApplicationOptions{
+		Name:         "Other",
+		UserID:       2,
+		RedirectURIs: []string{"a"},
+	})
+	assert.NoError(t, err)
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     other.ClientID,
+		"redirect_uri":  "a",
+		"code":          "authcode",
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	resp := MakeRequest(t, req, 400)
+	assert.Contains(t, resp.Body.String(), "invalid_grant")
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"redirect_uri":  "a",
+		"code":          "authcode",
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	resp = MakeRequest(t, req, 200)
+	type response struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		IDToken      string `json:"id_token"`
+	}
+	parsed := new(response)
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), parsed))
+	assert.True(t, len(parsed.AccessToken) > 10)
+	assert.True(t, len(parsed.RefreshToken) > 10)
+	// without a client secret to sign it with, no id_token is issued
+	assert.Empty(t, parsed.IDToken)
+}
+
 func TestAccessTokenExchangeJSON(t *testing.T) {
 	defer prepareTestEnv(t)()
 	req := NewRequestWithJSON(t, "POST", "/login/oauth/access_token", map[string]string{
@@ -256,8 +308,74 @@ func TestRefreshTokenInvalidation(t *testing.T) {
 	// test with invalidation
 	setting.OAuth2.InvalidateRefreshTokens = true
 	refreshReq.Body = ioutil.NopCloser(bytes.NewReader(bs))
-	MakeRequest(t, refreshReq, 200)
+	resp = MakeRequest(t, refreshReq, 200)
+	rotated := new(response)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), rotated))
+	assert.NotEqual(t, parsed.RefreshToken, rotated.RefreshToken)
 
+	// replaying the used token revokes the whole grant
 	refreshReq.Body = ioutil.NopCloser(bytes.NewReader(bs))
 	MakeRequest(t, refreshReq, 400)
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"refresh_token": rotated.RefreshToken,
+	})
+	MakeRequest(t, req, 400)
+	models.AssertNotExistsBean(t, &models.OAuth2Grant{ID: 1})
+}
+
+func TestRevokeOAuthToken(t *testing.T) {
+	defer prepareTestEnv(t)()
+	req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "authorization_code",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"redirect_uri":  "a",
+		"code":          "authcode",
+		"code_verifier": "N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt",
+	})
+	resp := MakeRequest(t, req, 200)
+	type response struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	parsed := new(response)
+	assert.NoError(t, jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(resp.Body.Bytes(), parsed))
+
+	// unknown tokens are accepted silently
+	req = NewRequestWithValues(t, "POST", "/login/oauth/revoke", map[string]string{
+		"client_id": "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"token":     "invalid",
+	})
+	MakeRequest(t, req, 200)
+
+	// a wrong secret must not revoke the token
+	req = NewRequestWithValues(t, "POST", "/login/oauth/revoke", map[string]string{
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "wrong",
+		"token":         parsed.AccessToken,
+	})
+	MakeRequest(t, req, 400)
+	models.AssertExistsAndLoadBean(t, &models.OAuth2Grant{ID: 1})
+
+	req = NewRequestWithValues(t, "POST", "/login/oauth/revoke", map[string]string{
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"token":         parsed.AccessToken,
+	})
+	MakeRequest(t, req, 200)
+	models.AssertNotExistsBean(t, &models.OAuth2Grant{ID: 1})
+
+	// the refresh token of the same grant is invalid as well
+	req = NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     "da7da3ba-9a13-4167-856f-3899de0b0138",
+		"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		"refresh_token": parsed.RefreshToken,
+	})
+	MakeRequest(t, req, 400)
 }
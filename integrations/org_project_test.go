@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgProject(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// only the owners manage the projects of an organization by default
+	session := loginUser(t, "user4")
+	req := NewRequest(t, "GET", "/org/user3/projects/new")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	session = loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/org/user3/projects/new", map[string]string{
+		"_csrf":      GetCSRF(t, session, "/org/user3/projects/new"),
+		"title":      "Initiative",
+		"content":    "across repositories",
+		"board_type": "1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	project := models.AssertExistsAndLoadBean(t, &models.Project{Title: "Initiative", OwnerID: 3}).(*models.Project)
+	assert.Equal(t, models.ProjectTypeOrganization, project.Type)
+	projectLink := fmt.Sprintf("/org/user3/projects/%d", project.ID)
+
+	// the issues of the repositories of the organization can be added to its projects
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user3/repo3/issues/projects?id=%d&issue_ids=6", project.ID), map[string]string{
+		"_csrf": GetCSRF(t, session, "/user3/repo3/issues/1"),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 6}).(*models.Issue)
+	assert.Equal(t, project.ID, issue.ProjectID())
+
+	// but not the ones of other repositories
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/issues/projects?id=%d&issue_ids=1", project.ID), map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/issues/1"),
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", projectLink)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "repo3#1")
+
+	boards, err := models.GetProjectBoards(project.ID)
+	assert.NoError(t, err)
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("%s/%d/%d", projectLink, boards[1].ID, issue.ID), map[string]string{
+		"_csrf": GetCSRF(t, session, projectLink),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, boards[1].ID, issue.ProjectBoardID())
+
+	// the projects are visible to the members only
+	session = loginUser(t, "user5")
+	req = NewRequest(t, "GET", projectLink)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
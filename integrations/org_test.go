@@ -6,9 +6,14 @@ package integrations
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/test"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -110,3 +115,51 @@ func TestPrivateOrg(t *testing.T) {
 	req = NewRequest(t, "GET", "/privated_org/private_repo_on_private_org")
 	session.MakeRequest(t, req, http.StatusOK)
 }
+
+func TestOrgRequireTwoFactor(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+	org.RequireTwoFactor = true
+	assert.NoError(t, models.UpdateUserCols(org, "require_two_factor"))
+
+	// members without two-factor authentication are asked to enroll it
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/org/user3/settings")
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user/settings/security/two_factor/enroll", test.RedirectURL(resp))
+	req = NewRequest(t, "GET", "/user3/repo3")
+	resp = session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user/settings/security/two_factor/enroll", test.RedirectURL(resp))
+
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	// including for git operations
+	req = NewRequest(t, "GET", "/user3/repo3.git/info/refs?service=git-upload-pack")
+	req = AddBasicAuthHeader(req, "user2")
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// other users are not affected
+	session = loginUser(t, "user5")
+	req = NewRequest(t, "GET", "/user3/repo21")
+	session.MakeRequest(t, req, http.StatusOK)
+}
+
+func TestOrgRequireTwoFactorSSH(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+		org.RequireTwoFactor = true
+		assert.NoError(t, models.UpdateUserCols(org, "require_two_factor"))
+
+		// the key 1 belongs to user2, a member of user3 without two-factor authentication
+		_, err := private.ServCommand(1, "user3", "repo3", models.AccessModeRead, "git-upload-pack")
+		if assert.True(t, private.IsErrServCommand(err)) {
+			assert.EqualValues(t, http.StatusForbidden, err.(private.ErrServCommand).StatusCode)
+		}
+
+		_, err = private.ServCommand(1, "user2", "repo1", models.AccessModeRead, "git-upload-pack")
+		assert.NoError(t, err)
+	})
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -160,3 +162,37 @@ func TestPullBranchDelete(t *testing.T) {
 		session.MakeRequest(t, req, http.StatusOK)
 	})
 }
+
+func TestPullCreateFromIndirectFork(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+
+		// user4 forks the fork, its repository is not a direct fork of user2/repo1
+		session4 := loginUser(t, "user4")
+		testRepoFork(t, session4, "user1", "repo1", "user4", "repo1")
+		testEditFile(t, session4, "user4", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		req := NewRequest(t, "GET", "/user2/repo1/compare/master...user4:master")
+		resp := session4.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "Hello, World (Edited)")
+
+		// an unrelated repository can't be compared
+		req = NewRequest(t, "GET", "/user2/repo1/compare/master...user3/repo3:master")
+		session.MakeRequest(t, req, http.StatusNotFound)
+
+		// the pull request can be created from the comparison
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		link, exists := htmlDoc.doc.Find("form.ui.form").Attr("action")
+		assert.True(t, exists, "The template has changed")
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf": htmlDoc.GetCSRF(),
+			"title": "This is a pull title",
+		})
+		resp = session4.MakeRequest(t, req, http.StatusFound)
+		assert.Regexp(t, "^/user2/repo1/pulls/[0-9]*$", resp.Header().Get("Location"))
+
+		headRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user4", Name: "repo1"}).(*models.Repository)
+		models.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: 1, HeadRepoID: headRepo.ID, HeadBranch: "master"})
+	})
+}
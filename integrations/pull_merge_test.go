@@ -6,6 +6,7 @@ package integrations
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
@@ -333,3 +335,122 @@ func TestCantMergeUnrelated(t *testing.T) {
 		gitRepo.Close()
 	})
 }
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		// require a status check and serialize the merges into the base branch
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		assert.NoError(t, models.UpdateProtectBranch(baseRepo, &models.ProtectedBranch{
+			RepoID:              baseRepo.ID,
+			BranchName:          "master",
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"testci"},
+			EnableMergeQueue:    true,
+		}, models.WhitelistOptions{}))
+
+		testPullMerge(t, session, elem[1], elem[2], elem[4], models.MergeStyleMerge)
+
+		pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{BaseRepoID: baseRepo.ID, HeadBranch: "master"}).(*models.PullRequest)
+		assert.False(t, pr.HasMerged)
+		exist, _, err := models.GetMergeQueueEntryByPullID(pr.ID)
+		assert.NoError(t, err)
+		assert.True(t, exist)
+
+		req := NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4]))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "form[action$='/cancel_merge_queue']", true)
+		htmlDoc.AssertElement(t, ".ui.form.merge-fields", false)
+
+		// the pull request is merged once the required status check passes
+		gitRepo, err := git.OpenRepository(baseRepo.RepoPath())
+		assert.NoError(t, err)
+		commitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		gitRepo.Close()
+		assert.NoError(t, err)
+
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/statuses/%s?token=%s", commitID, token),
+			api.CreateStatusOption{
+				State:   api.CommitStatusSuccess,
+				Context: "testci",
+			},
+		)
+		session.MakeRequest(t, req, http.StatusCreated)
+
+		assert.Eventually(t, func() bool {
+			pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: pr.ID}).(*models.PullRequest)
+			return pr.HasMerged
+		}, 10*time.Second, 100*time.Millisecond)
+		exist, _, err = models.GetMergeQueueEntryByPullID(pr.ID)
+		assert.NoError(t, err)
+		assert.False(t, exist)
+	})
+}
+
+func TestPullMergeCodeOwners(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		// user2 owns everything but the documentation
+		session2 := loginUser(t, "user2")
+		token2 := getTokenForLoggedInUser(t, session2)
+		req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/CODEOWNERS?token="+token2, &api.CreateFileOptions{
+			FileOptions: api.FileOptions{
+				BranchName: "master",
+				Message:    "Add CODEOWNERS",
+			},
+			Content: base64.StdEncoding.EncodeToString([]byte("* @user2\n/docs/ @user5\n")),
+		})
+		session2.MakeRequest(t, req, http.StatusCreated)
+
+		session := loginUser(t, "user1")
+		testRepoFork(t, session, "user2", "repo1", "user1", "repo1")
+		testEditFile(t, session, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+		resp := testPullCreate(t, session, "user1", "repo1", "master", "This is a pull title")
+		elem := strings.Split(test.RedirectURL(resp), "/")
+		assert.EqualValues(t, "pulls", elem[3])
+
+		baseRepo := models.AssertExistsAndLoadBean(t, &models.Repository{OwnerName: "user2", Name: "repo1"}).(*models.Repository)
+		assert.NoError(t, models.UpdateProtectBranch(baseRepo, &models.ProtectedBranch{
+			RepoID:                  baseRepo.ID,
+			BranchName:              "master",
+			RequireCodeOwnerReviews: true,
+		}, models.WhitelistOptions{}))
+
+		// the owner of README.md has to approve the pull request
+		req = NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4]))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Equal(t, "@user2", strings.TrimSpace(htmlDoc.doc.Find(".merge.box .ui.list .item").Text()))
+
+		token := getTokenForLoggedInUser(t, session)
+		mergeURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/merge?token=%s", elem[4], token)
+		req = NewRequestWithJSON(t, http.MethodPost, mergeURL, &auth.MergePullRequestForm{
+			Do: string(models.MergeStyleMerge),
+		})
+		session.MakeRequest(t, req, http.StatusMethodNotAllowed)
+
+		req = NewRequestWithJSON(t, http.MethodPost, fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/reviews?token=%s", elem[4], token2), &api.CreatePullReviewOptions{
+			Body:  "looks good",
+			Event: api.ReviewStateApproved,
+		})
+		session2.MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "GET", path.Join(elem[1], elem[2], "pulls", elem[4]))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, ".merge.box .ui.list .item", false)
+
+		req = NewRequestWithJSON(t, http.MethodPost, mergeURL, &auth.MergePullRequestForm{
+			Do: string(models.MergeStyleMerge),
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+	})
+}
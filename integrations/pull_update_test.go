@@ -5,6 +5,7 @@
 package integrations
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/repofiles"
 	repo_module "code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 
@@ -47,6 +49,41 @@ func TestAPIPullUpdate(t *testing.T) {
 	})
 }
 
+func TestPullUpdateMergeStatus(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+		org26 := models.AssertExistsAndLoadBean(t, &models.User{ID: 26}).(*models.User)
+		pr := createOutdatedPR(t, user, org26)
+		assert.NoError(t, pr.LoadBaseRepo())
+		assert.NoError(t, pr.LoadIssue())
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session)
+		req := NewRequestf(t, "POST", "/api/v1/repos/%s/%s/pulls/%d/update?token="+token, pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index)
+		session.MakeRequest(t, req, http.StatusOK)
+
+		// the pull request is checked again in the background after the update
+		statusURL := fmt.Sprintf("/%s/%s/pulls/%d/merge_status", pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index)
+		assert.Eventually(t, func() bool {
+			resp := session.MakeRequest(t, NewRequest(t, "GET", statusURL), http.StatusOK)
+			var status struct {
+				Status          string   `json:"status"`
+				ConflictedFiles []string `json:"conflicted_files"`
+			}
+			DecodeJSON(t, resp, &status)
+			assert.Empty(t, status.ConflictedFiles)
+			return status.Status == "mergeable"
+		}, 10*time.Second, 200*time.Millisecond)
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/pulls/%d?token="+token, pr.BaseRepo.OwnerName, pr.BaseRepo.Name, pr.Issue.Index)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		var apiPull api.PullRequest
+		DecodeJSON(t, resp, &apiPull)
+		assert.Equal(t, "mergeable", apiPull.MergeableState)
+		assert.True(t, apiPull.Mergeable)
+	})
+}
+
 func createOutdatedPR(t *testing.T, actor, forkOrg *models.User) *models.PullRequest {
 	baseRepo, err := repo_service.CreateRepository(actor, actor, models.CreateRepoOptions{
 		Name:        "repo-pr-update",
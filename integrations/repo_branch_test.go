@@ -5,14 +5,17 @@
 package integrations
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/test"
 
+	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
 	"github.com/unknwon/i18n"
 )
@@ -127,6 +130,31 @@ func testCreateBranches(t *testing.T, giteaURL *url.URL) {
 	}
 }
 
+func TestCreateBranchNamingPolicy(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		policy, err := models.GetPushPolicyByRepoID(1)
+		assert.NoError(t, err)
+		policy.BranchNameMode = models.PushPolicyModeBlock
+		policy.BranchNamePatterns = "feature/*\n/^release-[0-9]+$/"
+		policy.BranchNameExemptions = "hotfix"
+		assert.NoError(t, models.UpdatePushPolicy(policy))
+
+		session := loginUser(t, "user2")
+		for name, expected := range map[string]string{
+			"feature/naming": i18n.Tr("en", "repo.branch.create_success", "feature/naming"),
+			"release-1":      i18n.Tr("en", "repo.branch.create_success", "release-1"),
+			"hotfix":         i18n.Tr("en", "repo.branch.create_success", "hotfix"),
+			"wip":            i18n.Tr("en", "repo.branch.name_not_allowed", "wip", "feature/*, /^release-[0-9]+$/, hotfix"),
+		} {
+			redirectURL := testCreateBranch(t, session, "user2", "repo1", "branch/master", name, http.StatusFound)
+			req := NewRequest(t, "GET", redirectURL)
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			assert.Equal(t, expected, strings.TrimSpace(htmlDoc.doc.Find(".ui.message").Text()))
+		}
+	})
+}
+
 func TestCreateBranchInvalidCSRF(t *testing.T) {
 	defer prepareTestEnv(t)()
 	session := loginUser(t, "user2")
@@ -136,3 +164,68 @@ func TestCreateBranchInvalidCSRF(t *testing.T) {
 	})
 	session.MakeRequest(t, req, http.StatusBadRequest)
 }
+
+func TestListMergedBranches(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+
+	// develop points to the same commit as master while branch2 is ahead of it
+	req := NewRequest(t, "GET", "/user2/repo1/branches")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[href="/user2/repo1/src/branch/develop"]`, true)
+	htmlDoc.AssertElement(t, `a[href="/user2/repo1/src/branch/branch2"]`, true)
+
+	req = NewRequest(t, "GET", "/user2/repo1/branches?merged=true")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	htmlDoc.AssertElement(t, `a[href="/user2/repo1/src/branch/develop"]`, true)
+	htmlDoc.AssertElement(t, `a[href="/user2/repo1/src/branch/branch2"]`, false)
+}
+
+func TestDeleteMergedBranches(t *testing.T) {
+	defer prepareTestEnv(t)()
+	ctx := NewAPITestContext(t, "user2", "repo1")
+	doProtectBranch(ctx, "develop", "")(t)
+	session := ctx.Session
+
+	// the list to confirm has the merged branches but the default and the protected ones
+	req := NewRequest(t, "GET", "/user2/repo1/branches?merged=true")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	names := htmlDoc.doc.Find(`.delete-merged-branches.modal input[name="names"]`).Map(func(_ int, s *goquery.Selection) string {
+		return s.AttrOr("value", "")
+	})
+	assert.ElementsMatch(t, []string{"DefaultBranch", "feature/1"}, names)
+
+	req = NewRequestWithJSON(t, "POST", "/user2/repo1/branches/delete_merged", map[string]interface{}{
+		"names": []string{"feature/1", "develop", "branch2", "master", "feature/1"},
+	})
+	req.Header.Add("X-Csrf-Token", htmlDoc.GetCSRF())
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var result struct {
+		Redirect string
+		Deleted  []string
+		Failed   []struct {
+			Name    string
+			Message string
+		}
+	}
+	DecodeJSON(t, resp, &result)
+	assert.Equal(t, "/user2/repo1/branches", result.Redirect)
+	assert.Equal(t, []string{"feature/1"}, result.Deleted)
+	failed := make([]string, 0, len(result.Failed))
+	for _, failure := range result.Failed {
+		failed = append(failed, failure.Name)
+	}
+	assert.Equal(t, []string{"develop", "branch2", "master"}, failed)
+
+	// the deleted branch can be restored
+	deletedBranch := models.AssertExistsAndLoadBean(t, &models.DeletedBranch{RepoID: 1, Name: "feature/1"}).(*models.DeletedBranch)
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/user2/repo1/branches/restore?branch_id=%d&name=%s", deletedBranch.ID, deletedBranch.Name), map[string]string{
+		"_csrf": htmlDoc.GetCSRF(),
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequest(t, "GET", "/user2/repo1/src/branch/feature/1")
+	session.MakeRequest(t, req, http.StatusOK)
+}
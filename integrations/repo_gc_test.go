@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+func TestRepoGarbageCollect(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// only the administrators of the repository can run a garbage collection
+	session := loginUser(t, "user4")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/gc", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1"),
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+	models.AssertNotExistsBean(t, &models.Task{RepoID: 1, Type: structs.TaskTypeGarbageCollectRepo})
+
+	session = loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/gc", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings"),
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Task{RepoID: 1, DoerID: 2, Type: structs.TaskTypeGarbageCollectRepo})
+
+	req = NewRequest(t, "GET", "/user2/repo1/settings")
+	session.MakeRequest(t, req, http.StatusOK)
+}
@@ -0,0 +1,55 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoProtectedBranchesJSON(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	// user4 needs write access to be allowed to push
+	csrf := GetCSRF(t, session, "/user2/repo1/settings/collaboration")
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/collaboration", map[string]string{
+		"_csrf":        csrf,
+		"collaborator": "user4",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	csrf = GetCSRF(t, session, "/user2/repo1/settings/branches")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/branches/master", map[string]string{
+		"_csrf":              csrf,
+		"protected":          "on",
+		"enable_push":        "whitelist",
+		"enable_whitelist":   "on",
+		"whitelist_users":    "4",
+		"required_approvals": "1",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	req = NewRequest(t, "GET", "/user2/repo1/settings/branches/protected.json")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var rules []*api.BranchProtection
+	DecodeJSON(t, resp, &rules)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, "master", rules[0].BranchName)
+		assert.True(t, rules[0].EnablePush)
+		assert.True(t, rules[0].EnablePushWhitelist)
+		assert.Equal(t, []string{"user4"}, rules[0].PushWhitelistUsernames)
+		assert.EqualValues(t, 1, rules[0].RequiredApprovals)
+	}
+
+	// only admins of the repository can see the rules
+	session = loginUser(t, "user4")
+	req = NewRequest(t, "GET", "/user2/repo1/settings/branches/protected.json")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
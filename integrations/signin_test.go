@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/unknwon/i18n"
@@ -58,3 +59,71 @@ func TestSignin(t *testing.T) {
 		testLoginFailed(t, s.username, s.password, s.message)
 	}
 }
+
+func TestSigninLockout(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(threshold int) {
+		setting.LoginLockoutThreshold = threshold
+	}(setting.LoginLockoutThreshold)
+	setting.LoginLockoutThreshold = 2
+
+	testLoginFailed(t, "user5", "wrongPassword", i18n.Tr("en", "form.username_password_incorrect"))
+
+	// the failed attempt reaching the threshold locks the account
+	session := emptyTestSession(t)
+	req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user/login"),
+		"user_name": "user5",
+		"password":  "wrongPassword",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+	assert.True(t, user.IsLocked())
+	locked := i18n.Tr("en", "auth.account_locked", user.LockedUntilUnix.FormatLong())
+	assert.EqualValues(t, locked, NewHTMLParser(t, resp.Body).doc.Find(".ui.message>p").Text())
+
+	// the correct password doesn't help either
+	testLoginFailed(t, "user5", "password", locked)
+
+	admin := loginUser(t, "user1")
+	req = NewRequestWithValues(t, "POST", "/admin/users/5/unlock", map[string]string{
+		"_csrf": GetCSRF(t, admin, "/admin/users/5"),
+	})
+	admin.MakeRequest(t, req, http.StatusFound)
+
+	loginUser(t, "user5")
+}
+
+func TestBasicAuthLockout(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(threshold int) {
+		setting.LoginLockoutThreshold = threshold
+	}(setting.LoginLockoutThreshold)
+	setting.LoginLockoutThreshold = 2
+
+	// failed passwords on the API and on git over HTTP both count
+	req := NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("user5", "wrongPassword")
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("user5", "wrongPassword")
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 5}).(*models.User)
+	assert.True(t, user.IsLocked())
+
+	// the correct password is refused while the account is locked
+	req = NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("user5", userPassword)
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequest(t, "GET", "/user2/repo2.git/info/refs?service=git-upload-pack")
+	req.SetBasicAuth("user5", userPassword)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	assert.NoError(t, models.UnlockUser(user))
+	req = NewRequest(t, "GET", "/api/v1/user")
+	req.SetBasicAuth("user5", userPassword)
+	MakeRequest(t, req, http.StatusOK)
+}
@@ -322,6 +322,31 @@ func GetFeeds(opts GetFeedsOptions) ([]*Action, error) {
 	return actions, nil
 }
 
+// GetPublicRepoFeeds returns the latest public actions performed in a
+// repository. Actions of users who keep their activity private are left out.
+func GetPublicRepoFeeds(repo *Repository) ([]*Action, error) {
+	actions := make([]*Action, 0, setting.UI.FeedPagingNum)
+	if repo.IsPrivate {
+		return actions, nil
+	}
+
+	// Every action is stored once per watcher, the copy in the feed of the
+	// user who performed it always exists.
+	cond := builder.Eq{"repo_id": repo.ID, "is_private": false, "is_deleted": false}.
+		And(builder.Expr("user_id = act_user_id")).
+		And(builder.NotIn("act_user_id", builder.Select("id").From("`user`").Where(builder.Eq{"keep_activity_private": true})))
+
+	if err := x.Limit(setting.UI.FeedPagingNum).Desc("id").Where(cond).Find(&actions); err != nil {
+		return nil, fmt.Errorf("Find: %v", err)
+	}
+
+	if err := ActionList(actions).LoadAttributes(); err != nil {
+		return nil, fmt.Errorf("LoadAttributes: %v", err)
+	}
+
+	return actions, nil
+}
+
 func activityReadable(user, doer *User) bool {
 	var doerID int64
 	if doer != nil {
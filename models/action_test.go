@@ -89,3 +89,27 @@ func TestGetFeeds2(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
+
+func TestGetPublicRepoFeeds(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 9}).(*Repository)
+	actions, err := GetPublicRepoFeeds(repo)
+	assert.NoError(t, err)
+	if assert.Len(t, actions, 1) {
+		assert.EqualValues(t, 3, actions[0].ID)
+	}
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 11}).(*User)
+	user.KeepActivityPrivate = true
+	assert.NoError(t, UpdateUserCols(user, "keep_activity_private"))
+	actions, err = GetPublicRepoFeeds(repo)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+
+	// private repository
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	actions, err = GetPublicRepoFeeds(repo)
+	assert.NoError(t, err)
+	assert.Len(t, actions, 0)
+}
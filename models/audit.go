@@ -0,0 +1,156 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AuditAction represents the kind of a security relevant event
+type AuditAction string
+
+// The actions recorded in the audit log
+const (
+	AuditUserCreate            AuditAction = "user.create"
+	AuditUserUpdate            AuditAction = "user.update"
+	AuditUserDelete            AuditAction = "user.delete"
+	AuditUserImpersonate       AuditAction = "user.impersonate"
+	AuditUserStopImpersonation AuditAction = "user.stop_impersonation"
+	AuditAuthSourceCreate      AuditAction = "auth_source.create"
+	AuditAuthSourceUpdate      AuditAction = "auth_source.update"
+	AuditAuthSourceDelete      AuditAction = "auth_source.delete"
+	AuditRepoDelete            AuditAction = "repo.delete"
+	AuditRepoRestore           AuditAction = "repo.restore"
+	AuditRepoPurge             AuditAction = "repo.purge"
+	AuditCollaboratorAdd       AuditAction = "collaborator.add"
+	AuditCollaboratorUpdate    AuditAction = "collaborator.update"
+	AuditCollaboratorRemove    AuditAction = "collaborator.remove"
+	AuditTeamCreate            AuditAction = "team.create"
+	AuditTeamUpdate            AuditAction = "team.update"
+	AuditTeamDelete            AuditAction = "team.delete"
+	AuditTeamMemberAdd         AuditAction = "team.member_add"
+	AuditTeamMemberRemove      AuditAction = "team.member_remove"
+	AuditOrgMemberRemove       AuditAction = "org.member_remove"
+	AuditWebhookCreate         AuditAction = "webhook.create"
+	AuditWebhookUpdate         AuditAction = "webhook.update"
+	AuditWebhookDelete         AuditAction = "webhook.delete"
+)
+
+// The types of the targets of audit events
+const (
+	AuditTargetUser       = "user"
+	AuditTargetAuthSource = "auth_source"
+	AuditTargetRepository = "repository"
+	AuditTargetTeam       = "team"
+	AuditTargetOrg        = "organization"
+	AuditTargetWebhook    = "webhook"
+)
+
+// AuditActions lists all actions recorded in the audit log
+var AuditActions = []AuditAction{
+	AuditUserCreate, AuditUserUpdate, AuditUserDelete, AuditUserImpersonate, AuditUserStopImpersonation,
+	AuditAuthSourceCreate, AuditAuthSourceUpdate, AuditAuthSourceDelete,
+	AuditRepoDelete, AuditRepoRestore, AuditRepoPurge,
+	AuditCollaboratorAdd, AuditCollaboratorUpdate, AuditCollaboratorRemove,
+	AuditTeamCreate, AuditTeamUpdate, AuditTeamDelete, AuditTeamMemberAdd, AuditTeamMemberRemove,
+	AuditOrgMemberRemove,
+	AuditWebhookCreate, AuditWebhookUpdate, AuditWebhookDelete,
+}
+
+// AuditEvent represents a security relevant event, the names of the actor and the target are kept
+// so that the event stays readable after they have been deleted
+type AuditEvent struct {
+	ID          int64       `xorm:"pk autoincr"`
+	Action      AuditAction `xorm:"VARCHAR(50) INDEX NOT NULL"`
+	ActorID     int64       `xorm:"INDEX"`
+	ActorName   string      `xorm:"INDEX"`
+	TargetType  string      `xorm:"VARCHAR(50)"`
+	TargetID    int64
+	TargetName  string
+	Description string `xorm:"TEXT"`
+	IP          string `xorm:"VARCHAR(50)"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// CreateAuditEvent records an event in the audit log
+func CreateAuditEvent(event *AuditEvent) error {
+	_, err := x.Insert(event)
+	return err
+}
+
+// FindAuditEventsOptions represents the options to filter the audit log
+type FindAuditEventsOptions struct {
+	ListOptions
+	ActorName string
+	Action    AuditAction
+	// Since and Before limit the creation time of the events, zero means no limit
+	Since  int64
+	Before int64
+}
+
+func (opts *FindAuditEventsOptions) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if opts.ActorName != "" {
+		cond = cond.And(builder.Eq{"actor_name": opts.ActorName})
+	}
+	if opts.Action != "" {
+		cond = cond.And(builder.Eq{"action": opts.Action})
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// FindAuditEvents returns the events of the audit log matching the options, newest first,
+// and the total number of matching events
+func FindAuditEvents(opts *FindAuditEventsOptions) ([]*AuditEvent, int64, error) {
+	sess := x.Where(opts.toCond()).Desc("id")
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	events := make([]*AuditEvent, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&events)
+	return events, count, err
+}
+
+// IterateAuditEvents calls f with each event of the audit log matching the options, newest first
+func IterateAuditEvents(opts *FindAuditEventsOptions, f func(event *AuditEvent) error) error {
+	return x.Where(opts.toCond()).Desc("id").BufferSize(setting.Database.IterateBufferSize).Iterate(new(AuditEvent), func(idx int, bean interface{}) error {
+		return f(bean.(*AuditEvent))
+	})
+}
+
+// DeleteOldAuditEvents deletes the events of the audit log older than the given duration
+func DeleteOldAuditEvents(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ErrCancelledf("before deleting old audit events")
+	default:
+	}
+
+	deleted, err := x.Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(new(AuditEvent))
+	if err != nil {
+		return fmt.Errorf("delete old audit events: %v", err)
+	}
+	log.Trace("Deleted %d audit events older than %v", deleted, olderThan)
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAuditEvents(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, CreateAuditEvent(&AuditEvent{Action: AuditUserCreate, ActorID: 1, ActorName: "user1", TargetType: AuditTargetUser, TargetID: 2, TargetName: "user2"}))
+	assert.NoError(t, CreateAuditEvent(&AuditEvent{Action: AuditUserDelete, ActorID: 1, ActorName: "user1", TargetType: AuditTargetUser, TargetID: 2, TargetName: "user2"}))
+	assert.NoError(t, CreateAuditEvent(&AuditEvent{Action: AuditTeamCreate, ActorID: 2, ActorName: "user2", TargetType: AuditTargetTeam, TargetID: 1, TargetName: "org3/Owners"}))
+
+	events, count, err := FindAuditEvents(&FindAuditEventsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	if assert.Len(t, events, 3) {
+		// newest first
+		assert.Equal(t, AuditTeamCreate, events[0].Action)
+		assert.NotZero(t, events[0].CreatedUnix)
+	}
+
+	events, count, err = FindAuditEvents(&FindAuditEventsOptions{ActorName: "user1"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Len(t, events, 2)
+
+	events, count, err = FindAuditEvents(&FindAuditEventsOptions{ActorName: "user1", Action: AuditUserDelete})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "user2", events[0].TargetName)
+	}
+
+	events, count, err = FindAuditEvents(&FindAuditEventsOptions{ListOptions: ListOptions{Page: 2, PageSize: 2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, events, 1)
+
+	_, count, err = FindAuditEvents(&FindAuditEventsOptions{Before: time.Now().Add(-time.Hour).Unix()})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	var actions []AuditAction
+	assert.NoError(t, IterateAuditEvents(&FindAuditEventsOptions{ActorName: "user1"}, func(event *AuditEvent) error {
+		actions = append(actions, event.Action)
+		return nil
+	}))
+	assert.Equal(t, []AuditAction{AuditUserDelete, AuditUserCreate}, actions)
+}
+
+func TestDeleteOldAuditEvents(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	old := &AuditEvent{Action: AuditUserCreate, ActorName: "user1", CreatedUnix: timeutil.TimeStamp(time.Now().Add(-48 * time.Hour).Unix())}
+	_, err := x.NoAutoTime().Insert(old)
+	assert.NoError(t, err)
+	recent := &AuditEvent{Action: AuditUserDelete, ActorName: "user1"}
+	assert.NoError(t, CreateAuditEvent(recent))
+
+	assert.NoError(t, DeleteOldAuditEvents(context.Background(), 0))
+	AssertExistsAndLoadBean(t, &AuditEvent{ID: old.ID})
+
+	assert.NoError(t, DeleteOldAuditEvents(context.Background(), 24*time.Hour))
+	AssertNotExistsBean(t, &AuditEvent{ID: old.ID})
+	AssertExistsAndLoadBean(t, &AuditEvent{ID: recent.ID})
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CommitComment represents a comment on a commit of a repository, independent of any pull request
+type CommitComment struct {
+	ID        int64  `xorm:"pk autoincr"`
+	RepoID    int64  `xorm:"INDEX(s) NOT NULL"`
+	CommitSHA string `xorm:"INDEX(s) VARCHAR(40) NOT NULL"`
+	// TreePath and Line are empty for a comment on the whole commit
+	TreePath string
+	Line     int64  // - previous line / + proposed line
+	PosterID int64  `xorm:"INDEX"`
+	Poster   *User  `xorm:"-"`
+	Content  string `xorm:"TEXT"`
+
+	RenderedContent string `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// LoadPoster loads the poster of the comment
+func (c *CommitComment) LoadPoster() error {
+	return c.loadPoster(x)
+}
+
+func (c *CommitComment) loadPoster(e Engine) (err error) {
+	if c.Poster != nil {
+		return nil
+	}
+
+	c.Poster, err = getUserByID(e, c.PosterID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			c.PosterID = -1
+			c.Poster = NewGhostUser()
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// HashTag returns the anchor of the comment on the page of its commit
+func (c *CommitComment) HashTag() string {
+	return fmt.Sprintf("commitcomment-%d", c.ID)
+}
+
+// HTMLURL returns the URL of the comment on the page of its commit
+func (c *CommitComment) HTMLURL(repo *Repository) string {
+	return fmt.Sprintf("%s/commit/%s#%s", repo.HTMLURL(), c.CommitSHA, c.HashTag())
+}
+
+// CreateCommitComment creates a comment on a commit
+func CreateCommitComment(c *CommitComment) error {
+	if _, err := x.Insert(c); err != nil {
+		return err
+	}
+	return c.LoadPoster()
+}
+
+// GetCommitCommentByID returns the comment of the repository with the given ID
+func GetCommitCommentByID(repoID, id int64) (*CommitComment, error) {
+	c := new(CommitComment)
+	has, err := x.ID(id).Where("repo_id=?", repoID).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCommitCommentNotExist{ID: id, RepoID: repoID}
+	}
+	return c, c.LoadPoster()
+}
+
+// GetCommitComments returns the comments on a commit of a repository, oldest first
+func GetCommitComments(repoID int64, sha string) ([]*CommitComment, error) {
+	comments := make([]*CommitComment, 0, 5)
+	if err := x.Where("repo_id=? AND commit_sha=?", repoID, sha).
+		Asc("created_unix").Asc("id").
+		Find(&comments); err != nil {
+		return nil, err
+	}
+
+	posters := make(map[int64]*User)
+	for _, c := range comments {
+		if poster, ok := posters[c.PosterID]; ok {
+			c.Poster = poster
+			continue
+		}
+		if err := c.LoadPoster(); err != nil {
+			return nil, err
+		}
+		posters[c.PosterID] = c.Poster
+	}
+	return comments, nil
+}
+
+// DeleteCommitComment deletes a comment on a commit
+func DeleteCommitComment(c *CommitComment) error {
+	_, err := x.ID(c.ID).Delete(new(CommitComment))
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitComments(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	const sha = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	first := &CommitComment{RepoID: repo.ID, CommitSHA: sha, PosterID: 2, Content: "whole commit"}
+	assert.NoError(t, CreateCommitComment(first))
+	assert.EqualValues(t, 2, first.Poster.ID)
+	second := &CommitComment{RepoID: repo.ID, CommitSHA: sha, TreePath: "README.md", Line: 1, PosterID: 5, Content: "first line"}
+	assert.NoError(t, CreateCommitComment(second))
+
+	comments, err := GetCommitComments(repo.ID, sha)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 2) {
+		assert.Equal(t, first.ID, comments[0].ID)
+		assert.Equal(t, "README.md", comments[1].TreePath)
+		assert.EqualValues(t, 5, comments[1].Poster.ID)
+	}
+	assert.Equal(t, repo.HTMLURL()+"/commit/"+sha+"#"+second.HashTag(), second.HTMLURL(repo))
+
+	// comments are scoped to their repository
+	_, err = GetCommitCommentByID(2, first.ID)
+	assert.True(t, IsErrCommitCommentNotExist(err))
+
+	c, err := GetCommitCommentByID(repo.ID, first.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, DeleteCommitComment(c))
+	AssertNotExistsBean(t, &CommitComment{ID: first.ID})
+
+	comments, err = GetCommitComments(repo.ID, sha)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CommitStatusAnnotation holds a message of a check, the commit statuses with the same context,
+// about a line of a file of a commit
+type CommitStatusAnnotation struct {
+	ID          int64                           `xorm:"pk autoincr"`
+	RepoID      int64                           `xorm:"INDEX(s) NOT NULL"`
+	SHA         string                          `xorm:"VARCHAR(64) INDEX(s) NOT NULL"`
+	ContextHash string                          `xorm:"char(40) index"`
+	Context     string                          `xorm:"TEXT"`
+	Path        string                          `xorm:"TEXT NOT NULL"`
+	Line        int64                           `xorm:"NOT NULL"`
+	Level       api.CommitStatusAnnotationLevel `xorm:"VARCHAR(7) NOT NULL"`
+	Message     string                          `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// ErrCommitStatusNotExist represents a "CommitStatusNotExist" kind of error.
+type ErrCommitStatusNotExist struct {
+	RepoID  int64
+	SHA     string
+	Context string
+}
+
+// IsErrCommitStatusNotExist checks if an error is a ErrCommitStatusNotExist.
+func IsErrCommitStatusNotExist(err error) bool {
+	_, ok := err.(ErrCommitStatusNotExist)
+	return ok
+}
+
+func (err ErrCommitStatusNotExist) Error() string {
+	return fmt.Sprintf("commit status does not exist [repo_id: %d, sha: %s, context: %s]", err.RepoID, err.SHA, err.Context)
+}
+
+// ErrInvalidCommitStatusAnnotations represents a "InvalidCommitStatusAnnotations" kind of error.
+type ErrInvalidCommitStatusAnnotations struct {
+	Reason string
+}
+
+// IsErrInvalidCommitStatusAnnotations checks if an error is a ErrInvalidCommitStatusAnnotations.
+func IsErrInvalidCommitStatusAnnotations(err error) bool {
+	_, ok := err.(ErrInvalidCommitStatusAnnotations)
+	return ok
+}
+
+func (err ErrInvalidCommitStatusAnnotations) Error() string {
+	return fmt.Sprintf("invalid commit status annotations: %s", err.Reason)
+}
+
+// ReplaceCommitStatusAnnotations replaces the annotations of the check with the given context
+// for the given commit, a commit status with this context has to exist for the commit.
+func ReplaceCommitStatusAnnotations(repo *Repository, sha, context string, annotations []*CommitStatusAnnotation) error {
+	context = strings.TrimSpace(context)
+	if len(annotations) > setting.Repository.MaxAnnotationsPerCheck {
+		return ErrInvalidCommitStatusAnnotations{fmt.Sprintf("a check can have at most %d annotations", setting.Repository.MaxAnnotationsPerCheck)}
+	}
+	for i, annotation := range annotations {
+		annotation.Path = strings.TrimSpace(annotation.Path)
+		annotation.Message = strings.TrimSpace(annotation.Message)
+		switch {
+		case annotation.Path == "":
+			return ErrInvalidCommitStatusAnnotations{fmt.Sprintf("annotation %d has no path", i)}
+		case annotation.Line < 1:
+			return ErrInvalidCommitStatusAnnotations{fmt.Sprintf("annotation %d has an invalid line", i)}
+		case !annotation.Level.IsValid():
+			return ErrInvalidCommitStatusAnnotations{fmt.Sprintf("annotation %d has an invalid level", i)}
+		case annotation.Message == "":
+			return ErrInvalidCommitStatusAnnotations{fmt.Sprintf("annotation %d has no message", i)}
+		}
+	}
+
+	contextHash := hashCommitStatusContext(context)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	has, err := sess.Where("repo_id = ? AND sha = ? AND context_hash = ?", repo.ID, sha, contextHash).Exist(new(CommitStatus))
+	if err != nil {
+		return err
+	} else if !has {
+		return ErrCommitStatusNotExist{repo.ID, sha, context}
+	}
+
+	if _, err := sess.Where("repo_id = ? AND sha = ? AND context_hash = ?", repo.ID, sha, contextHash).Delete(new(CommitStatusAnnotation)); err != nil {
+		return err
+	}
+	for _, annotation := range annotations {
+		annotation.ID = 0
+		annotation.RepoID = repo.ID
+		annotation.SHA = sha
+		annotation.Context = context
+		annotation.ContextHash = contextHash
+	}
+	if len(annotations) > 0 {
+		if _, err := sess.Insert(&annotations); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// GetCommitStatusAnnotations returns the annotations of all checks for the given commit
+// ordered by file and line
+func GetCommitStatusAnnotations(repoID int64, sha string) ([]*CommitStatusAnnotation, error) {
+	annotations := make([]*CommitStatusAnnotation, 0, 10)
+	return annotations, x.Where("repo_id = ? AND sha = ?", repoID, sha).
+		Asc("path", "line", "id").
+		Find(&annotations)
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceCommitStatusAnnotations(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	sha := "1234123412341234123412341234123412341234"
+
+	annotation := func(path string, line int64, level api.CommitStatusAnnotationLevel) *CommitStatusAnnotation {
+		return &CommitStatusAnnotation{Path: path, Line: line, Level: level, Message: "message"}
+	}
+
+	// the check has to exist
+	err := ReplaceCommitStatusAnnotations(repo, sha, "lint", []*CommitStatusAnnotation{annotation("README.md", 1, api.CommitStatusAnnotationNotice)})
+	assert.True(t, IsErrCommitStatusNotExist(err))
+
+	assert.NoError(t, NewCommitStatus(NewCommitStatusOptions{
+		Repo:         repo,
+		Creator:      user,
+		SHA:          sha,
+		CommitStatus: &CommitStatus{State: api.CommitStatusFailure, Context: "lint"},
+	}))
+
+	assert.NoError(t, ReplaceCommitStatusAnnotations(repo, sha, " lint ", []*CommitStatusAnnotation{
+		annotation("README.md", 3, api.CommitStatusAnnotationFailure),
+		annotation("README.md", 1, api.CommitStatusAnnotationWarning),
+	}))
+	annotations, err := GetCommitStatusAnnotations(repo.ID, sha)
+	assert.NoError(t, err)
+	if assert.Len(t, annotations, 2) {
+		assert.EqualValues(t, 1, annotations[0].Line)
+		assert.EqualValues(t, 3, annotations[1].Line)
+		assert.Equal(t, "lint", annotations[0].Context)
+	}
+
+	// the annotations replace the previous ones of the check
+	assert.NoError(t, ReplaceCommitStatusAnnotations(repo, sha, "lint", []*CommitStatusAnnotation{
+		annotation("main.go", 7, api.CommitStatusAnnotationNotice),
+	}))
+	annotations, err = GetCommitStatusAnnotations(repo.ID, sha)
+	assert.NoError(t, err)
+	if assert.Len(t, annotations, 1) {
+		assert.Equal(t, "main.go", annotations[0].Path)
+	}
+
+	// invalid annotations are rejected
+	for _, invalid := range []*CommitStatusAnnotation{
+		annotation("", 1, api.CommitStatusAnnotationNotice),
+		annotation("main.go", 0, api.CommitStatusAnnotationNotice),
+		annotation("main.go", 1, "error"),
+		{Path: "main.go", Line: 1, Level: api.CommitStatusAnnotationNotice},
+	} {
+		err = ReplaceCommitStatusAnnotations(repo, sha, "lint", []*CommitStatusAnnotation{invalid})
+		assert.True(t, IsErrInvalidCommitStatusAnnotations(err))
+	}
+
+	// the number of annotations per check is limited
+	defer func(max int) {
+		setting.Repository.MaxAnnotationsPerCheck = max
+	}(setting.Repository.MaxAnnotationsPerCheck)
+	setting.Repository.MaxAnnotationsPerCheck = 1
+	err = ReplaceCommitStatusAnnotations(repo, sha, "lint", []*CommitStatusAnnotation{
+		annotation("main.go", 1, api.CommitStatusAnnotationNotice),
+		annotation("main.go", 2, api.CommitStatusAnnotationNotice),
+	})
+	assert.True(t, IsErrInvalidCommitStatusAnnotations(err))
+	AssertExistsAndLoadBean(t, &CommitStatusAnnotation{RepoID: repo.ID, Path: "main.go", Line: 7})
+}
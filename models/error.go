@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // ErrNotExist represents a non-exist error.
@@ -128,6 +129,26 @@ func (err ErrUserAlreadyExist) Error() string {
 	return fmt.Sprintf("user already exists [name: %s]", err.Name)
 }
 
+// ErrBulkCreateUser represents an error creating one of the users created together
+type ErrBulkCreateUser struct {
+	Index int
+	Err   error
+}
+
+// IsErrBulkCreateUser checks if an error is a ErrBulkCreateUser.
+func IsErrBulkCreateUser(err error) bool {
+	_, ok := err.(ErrBulkCreateUser)
+	return ok
+}
+
+func (err ErrBulkCreateUser) Error() string {
+	return fmt.Sprintf("unable to create user [index: %d]: %v", err.Index, err.Err)
+}
+
+func (err ErrBulkCreateUser) Unwrap() error {
+	return err.Err
+}
+
 // ErrUserNotExist represents a "UserNotExist" kind of error.
 type ErrUserNotExist struct {
 	UID   int64
@@ -176,6 +197,23 @@ func (err ErrUserProhibitLogin) Error() string {
 	return fmt.Sprintf("user is not allowed login [uid: %d, name: %s]", err.UID, err.Name)
 }
 
+// ErrUserLocked represents a "ErrUserLocked" kind of error.
+type ErrUserLocked struct {
+	UID         int64
+	Name        string
+	LockedUntil timeutil.TimeStamp
+}
+
+// IsErrUserLocked checks if an error is a ErrUserLocked
+func IsErrUserLocked(err error) bool {
+	_, ok := err.(ErrUserLocked)
+	return ok
+}
+
+func (err ErrUserLocked) Error() string {
+	return fmt.Sprintf("user is locked [uid: %d, name: %s, until: %d]", err.UID, err.Name, err.LockedUntil)
+}
+
 // ErrUserInactive represents a "ErrUserInactive" kind of error.
 type ErrUserInactive struct {
 	UID  int64
@@ -613,6 +651,66 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return "access token is empty"
 }
 
+// ErrRepoAccessTokenNotExist represents a "RepoAccessTokenNotExist" kind of error.
+type ErrRepoAccessTokenNotExist struct {
+	ID int64
+}
+
+// IsErrRepoAccessTokenNotExist checks if an error is a ErrRepoAccessTokenNotExist.
+func IsErrRepoAccessTokenNotExist(err error) bool {
+	_, ok := err.(ErrRepoAccessTokenNotExist)
+	return ok
+}
+
+func (err ErrRepoAccessTokenNotExist) Error() string {
+	return fmt.Sprintf("repository access token does not exist [id: %d]", err.ID)
+}
+
+// ErrRememberTokenInvalid represents a "RememberTokenInvalid" kind of error.
+type ErrRememberTokenInvalid struct {
+	Reason string
+}
+
+// IsErrRememberTokenInvalid checks if an error is a ErrRememberTokenInvalid.
+func IsErrRememberTokenInvalid(err error) bool {
+	_, ok := err.(ErrRememberTokenInvalid)
+	return ok
+}
+
+func (err ErrRememberTokenInvalid) Error() string {
+	return fmt.Sprintf("remember token is invalid [reason: %s]", err.Reason)
+}
+
+// ErrUserSessionNotExist represents a "UserSessionNotExist" kind of error.
+type ErrUserSessionNotExist struct {
+	UID int64
+}
+
+// IsErrUserSessionNotExist checks if an error is a ErrUserSessionNotExist.
+func IsErrUserSessionNotExist(err error) bool {
+	_, ok := err.(ErrUserSessionNotExist)
+	return ok
+}
+
+func (err ErrUserSessionNotExist) Error() string {
+	return fmt.Sprintf("user session does not exist [uid: %d]", err.UID)
+}
+
+// ErrGitOneTimeTokenInvalid represents a "GitOneTimeTokenInvalid" kind of error.
+type ErrGitOneTimeTokenInvalid struct {
+	Reason string
+}
+
+// IsErrGitOneTimeTokenInvalid checks if an error is a ErrGitOneTimeTokenInvalid.
+func IsErrGitOneTimeTokenInvalid(err error) bool {
+	_, ok := err.(ErrGitOneTimeTokenInvalid)
+	return ok
+}
+
+func (err ErrGitOneTimeTokenInvalid) Error() string {
+	return fmt.Sprintf("one-time git token is invalid [reason: %s]", err.Reason)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -1056,6 +1154,21 @@ func (err ErrFilePathProtected) Error() string {
 	return fmt.Sprintf("path is protected and can not be changed [path: %s]", err.Path)
 }
 
+// ErrProtectedTagName represents a "ProtectedTagName" kind of error.
+type ErrProtectedTagName struct {
+	TagName string
+}
+
+// IsErrProtectedTagName checks if an error is a ErrProtectedTagName.
+func IsErrProtectedTagName(err error) bool {
+	_, ok := err.(ErrProtectedTagName)
+	return ok
+}
+
+func (err ErrProtectedTagName) Error() string {
+	return fmt.Sprintf("user is not allowed to change protected tag [name: %s]", err.TagName)
+}
+
 // ErrUserDoesNotHaveAccessToRepo represets an error where the user doesn't has access to a given repo.
 type ErrUserDoesNotHaveAccessToRepo struct {
 	UserID   int64
@@ -1171,6 +1284,36 @@ func (err ErrNotAllowedToMerge) Error() string {
 	return fmt.Sprintf("not allowed to merge [reason: %s]", err.Reason)
 }
 
+// ErrPullAlreadyScheduledToAutoMerge represents an error that a pull request is already scheduled to be merged automatically
+type ErrPullAlreadyScheduledToAutoMerge struct {
+	PullID int64
+}
+
+// IsErrPullAlreadyScheduledToAutoMerge checks if an error is an ErrPullAlreadyScheduledToAutoMerge.
+func IsErrPullAlreadyScheduledToAutoMerge(err error) bool {
+	_, ok := err.(ErrPullAlreadyScheduledToAutoMerge)
+	return ok
+}
+
+func (err ErrPullAlreadyScheduledToAutoMerge) Error() string {
+	return fmt.Sprintf("pull request is already scheduled to auto merge [pull_id: %d]", err.PullID)
+}
+
+// ErrPullAlreadyInMergeQueue represents an error that a pull request is already in the merge queue of its base branch
+type ErrPullAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+// IsErrPullAlreadyInMergeQueue checks if an error is an ErrPullAlreadyInMergeQueue.
+func IsErrPullAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrPullAlreadyInMergeQueue)
+	return ok
+}
+
+func (err ErrPullAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
 // ErrTagAlreadyExists represents an error that tag with such name already exists.
 type ErrTagAlreadyExists struct {
 	TagName string
@@ -1269,6 +1412,22 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrHookTaskNotExist represents a "HookTaskNotExist" kind of error.
+type ErrHookTaskNotExist struct {
+	HookID int64
+	UUID   string
+}
+
+// IsErrHookTaskNotExist checks if an error is a ErrHookTaskNotExist.
+func IsErrHookTaskNotExist(err error) bool {
+	_, ok := err.(ErrHookTaskNotExist)
+	return ok
+}
+
+func (err ErrHookTaskNotExist) Error() string {
+	return fmt.Sprintf("hook task does not exist [hook: %d, uuid: %s]", err.HookID, err.UUID)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1293,6 +1452,39 @@ func (err ErrIssueNotExist) Error() string {
 	return fmt.Sprintf("issue does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrIssueIndexTooLow represents a "IssueIndexTooLow" kind of error.
+type ErrIssueIndexTooLow struct {
+	RepoID   int64
+	Index    int64
+	MaxIndex int64
+}
+
+// IsErrIssueIndexTooLow checks if an error is a ErrIssueIndexTooLow.
+func IsErrIssueIndexTooLow(err error) bool {
+	_, ok := err.(ErrIssueIndexTooLow)
+	return ok
+}
+
+func (err ErrIssueIndexTooLow) Error() string {
+	return fmt.Sprintf("issue index is not above the highest index [repo_id: %d, index: %d, max_index: %d]", err.RepoID, err.Index, err.MaxIndex)
+}
+
+// ErrIssueIndexAlreadyExists represents a "IssueIndexAlreadyExists" kind of error.
+type ErrIssueIndexAlreadyExists struct {
+	RepoID int64
+	Index  int64
+}
+
+// IsErrIssueIndexAlreadyExists checks if an error is a ErrIssueIndexAlreadyExists.
+func IsErrIssueIndexAlreadyExists(err error) bool {
+	_, ok := err.(ErrIssueIndexAlreadyExists)
+	return ok
+}
+
+func (err ErrIssueIndexAlreadyExists) Error() string {
+	return fmt.Sprintf("issue or pull request with index already exists [repo_id: %d, index: %d]", err.RepoID, err.Index)
+}
+
 // ErrIssueIsClosed represents a "IssueIsClosed" kind of error.
 type ErrIssueIsClosed struct {
 	ID     int64
@@ -1310,6 +1502,36 @@ func (err ErrIssueIsClosed) Error() string {
 	return fmt.Sprintf("issue is closed [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
 }
 
+// ErrIssueMaxPinReached represents a "IssueMaxPinReached" kind of error.
+type ErrIssueMaxPinReached struct {
+	Max int
+}
+
+// IsErrIssueMaxPinReached checks if an error is a ErrIssueMaxPinReached.
+func IsErrIssueMaxPinReached(err error) bool {
+	_, ok := err.(ErrIssueMaxPinReached)
+	return ok
+}
+
+func (err ErrIssueMaxPinReached) Error() string {
+	return fmt.Sprintf("maximum number of pinned issues reached [max: %d]", err.Max)
+}
+
+// ErrPinnedIssuesOrderMismatch represents a "PinnedIssuesOrderMismatch" kind of error.
+type ErrPinnedIssuesOrderMismatch struct {
+	RepoID int64
+}
+
+// IsErrPinnedIssuesOrderMismatch checks if an error is a ErrPinnedIssuesOrderMismatch.
+func IsErrPinnedIssuesOrderMismatch(err error) bool {
+	_, ok := err.(ErrPinnedIssuesOrderMismatch)
+	return ok
+}
+
+func (err ErrPinnedIssuesOrderMismatch) Error() string {
+	return fmt.Sprintf("new order does not match the pinned issues [repo_id: %d]", err.RepoID)
+}
+
 // ErrIssueLabelTemplateLoad represents a "ErrIssueLabelTemplateLoad" kind of error.
 type ErrIssueLabelTemplateLoad struct {
 	TemplateFile  string
@@ -1588,6 +1810,22 @@ func (err ErrCommentNotExist) Error() string {
 	return fmt.Sprintf("comment does not exist [id: %d, issue_id: %d]", err.ID, err.IssueID)
 }
 
+// ErrCommitCommentNotExist represents a "CommitCommentNotExist" kind of error.
+type ErrCommitCommentNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrCommitCommentNotExist checks if an error is a ErrCommitCommentNotExist.
+func IsErrCommitCommentNotExist(err error) bool {
+	_, ok := err.(ErrCommitCommentNotExist)
+	return ok
+}
+
+func (err ErrCommitCommentNotExist) Error() string {
+	return fmt.Sprintf("commit comment does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
 //  _________ __                                __         .__
 //  /   _____//  |_  ____ ________  _  _______ _/  |_  ____ |  |__
 //  \_____  \\   __\/  _ \\____ \ \/ \/ /\__  \\   __\/ ___\|  |  \
@@ -1971,6 +2209,21 @@ func IsErrU2FRegistrationNotExist(err error) bool {
 	return ok
 }
 
+// ErrWebAuthnCredentialNotExist represents a "ErrWebAuthnCredentialNotExist" kind of error.
+type ErrWebAuthnCredentialNotExist struct {
+	ID int64
+}
+
+func (err ErrWebAuthnCredentialNotExist) Error() string {
+	return fmt.Sprintf("WebAuthn credential does not exist [id: %d]", err.ID)
+}
+
+// IsErrWebAuthnCredentialNotExist checks if an error is a ErrWebAuthnCredentialNotExist.
+func IsErrWebAuthnCredentialNotExist(err error) bool {
+	_, ok := err.(ErrWebAuthnCredentialNotExist)
+	return ok
+}
+
 // .___                            ________                                   .___                   .__
 // |   | ______ ________ __   ____ \______ \   ____ ______   ____   ____    __| _/____   ____   ____ |__| ____   ______
 // |   |/  ___//  ___/  |  \_/ __ \ |    |  \_/ __ \\____ \_/ __ \ /    \  / __ |/ __ \ /    \_/ ___\|  |/ __ \ /  ___/
@@ -68,6 +68,9 @@ type Issue struct {
 	// with write access
 	IsLocked bool `xorm:"NOT NULL DEFAULT false"`
 
+	// PinOrder is the position of the issue among the pinned issues of its repository, 0 if it is not pinned
+	PinOrder int `xorm:"DEFAULT 0"`
+
 	// For view issue page.
 	ShowTag CommentTag `xorm:"-"`
 }
@@ -656,6 +659,10 @@ func (issue *Issue) doChangeStatus(e *xorm.Session, doer *User, isMergePull bool
 		return nil, err
 	}
 
+	if err := moveIssueByProjectBoardRules(e, issue, isMergePull); err != nil {
+		return nil, err
+	}
+
 	// New action comment
 	cmtType := CommentTypeClose
 	if !issue.IsClosed {
@@ -879,6 +886,68 @@ type NewIssueOptions struct {
 	IsPull      bool
 }
 
+// nextIssueIndexExpr returns the SQL expression of the next free index of the issues and
+// pull requests of a repository, which is above the given offset.
+func nextIssueIndexExpr(offset int64) string {
+	if offset <= 0 {
+		return "coalesce(MAX(`index`),0)+1"
+	}
+	return fmt.Sprintf("CASE WHEN coalesce(MAX(`index`),0) > %[1]d THEN coalesce(MAX(`index`),0) ELSE %[1]d END + 1", offset)
+}
+
+// insertIssueWithNextIndex inserts the issue with the next free index of its repository
+func insertIssueWithNextIndex(e *xorm.Session, issue *Issue, offset int64) error {
+	if _, err := e.SetExpr("`index`", nextIssueIndexExpr(offset)).
+		Where("repo_id=?", issue.RepoID).
+		Insert(issue); err != nil {
+		return ErrNewIssueInsert{err}
+	}
+
+	inserted, err := getIssueByID(e, issue.ID)
+	if err != nil {
+		return err
+	}
+
+	// Patch Index with the value calculated by the database
+	issue.Index = inserted.Index
+	return nil
+}
+
+func getMaxIssueIndex(e Engine, repoID int64) (int64, error) {
+	var maxIndex int64
+	if _, err := e.Table("issue").Where("repo_id=?", repoID).Select("coalesce(MAX(`index`),0)").Get(&maxIndex); err != nil {
+		return 0, err
+	}
+	return maxIndex, nil
+}
+
+// GetNextIssueIndex returns the index the next issue or pull request of the repository gets
+func GetNextIssueIndex(repo *Repository) (int64, error) {
+	maxIndex, err := getMaxIssueIndex(x, repo.ID)
+	if err != nil {
+		return 0, err
+	}
+	if maxIndex < repo.IssueIndexOffset {
+		maxIndex = repo.IssueIndexOffset
+	}
+	return maxIndex + 1, nil
+}
+
+// SetNextIssueIndex changes the index the next issue or pull request of the repository gets.
+// It must be above the indexes of all existing issues and pull requests.
+func SetNextIssueIndex(repo *Repository, next int64) error {
+	maxIndex, err := getMaxIssueIndex(x, repo.ID)
+	if err != nil {
+		return err
+	}
+	if next <= maxIndex {
+		return ErrIssueIndexTooLow{RepoID: repo.ID, Index: next, MaxIndex: maxIndex}
+	}
+	repo.IssueIndexOffset = next - 1
+	_, err = x.ID(repo.ID).Cols("issue_index_offset").NoAutoTime().Update(repo)
+	return err
+}
+
 func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 	opts.Issue.Title = strings.TrimSpace(opts.Issue.Title)
 
@@ -897,20 +966,10 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 	}
 
 	// Milestone validation should happen before insert actual object.
-	if _, err := e.SetExpr("`index`", "coalesce(MAX(`index`),0)+1").
-		Where("repo_id=?", opts.Issue.RepoID).
-		Insert(opts.Issue); err != nil {
-		return ErrNewIssueInsert{err}
-	}
-
-	inserted, err := getIssueByID(e, opts.Issue.ID)
-	if err != nil {
+	if err := insertIssueWithNextIndex(e, opts.Issue, opts.Repo.IssueIndexOffset); err != nil {
 		return err
 	}
 
-	// Patch Index with the value calculated by the database
-	opts.Issue.Index = inserted.Index
-
 	if opts.Issue.MilestoneID > 0 {
 		if _, err = e.Exec("UPDATE `milestone` SET num_issues=num_issues+1 WHERE id=?", opts.Issue.MilestoneID); err != nil {
 			return err
@@ -1123,6 +1182,11 @@ type IssuesOptions struct {
 	// prioritize issues from this repo
 	PriorityRepoID int64
 	IsArchived     util.OptionalBool
+	// DueDate is "overdue" for the open issues past their due date, "set" or "unset"
+	DueDate string
+	// RepoCond further restricts the repositories of the issues if not nil, e.g. to the ones
+	// whose issues or pull requests can be read
+	RepoCond builder.Cond
 }
 
 // sortIssuesSession sort an issues-related session based on the provided
@@ -1153,6 +1217,18 @@ func sortIssuesSession(sess *xorm.Session, sortType string, priorityRepoID int64
 	}
 }
 
+// sortIssuesByRelevance orders the issues like the given ids, which the issue indexer returns ordered
+// by relevance
+func sortIssuesByRelevance(sess *xorm.Session, issueIDs []int64) {
+	var orderBy strings.Builder
+	orderBy.WriteString("CASE issue.id")
+	for i, id := range issueIDs {
+		fmt.Fprintf(&orderBy, " WHEN %d THEN %d", id, i)
+	}
+	fmt.Fprintf(&orderBy, " ELSE %d END, issue.created_unix DESC", len(issueIDs))
+	sess.OrderBy(orderBy.String())
+}
+
 func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 	if opts.Page >= 0 && opts.PageSize > 0 {
 		var start int
@@ -1172,6 +1248,13 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 		applyReposCondition(sess, opts.RepoIDs)
 	}
 
+	if opts.RepoCond != nil {
+		sess.And(opts.RepoCond)
+	}
+
+	// the issues of the repositories pending deletion are hidden with them
+	applyNotDeletedReposCondition(sess)
+
 	switch opts.IsClosed {
 	case util.OptionalBoolTrue:
 		sess.And("issue.is_closed=?", true)
@@ -1202,6 +1285,8 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 	if opts.UpdatedAfterUnix != 0 {
 		sess.And(builder.Gte{"issue.updated_unix": opts.UpdatedAfterUnix})
 	}
+
+	applyDueDateCondition(sess, opts.DueDate)
 	if opts.UpdatedBeforeUnix != 0 {
 		sess.And(builder.Lte{"issue.updated_unix": opts.UpdatedBeforeUnix})
 	}
@@ -1250,10 +1335,29 @@ func (opts *IssuesOptions) setupSession(sess *xorm.Session) {
 	}
 }
 
+func applyDueDateCondition(sess *xorm.Session, dueDate string) *xorm.Session {
+	switch dueDate {
+	case "overdue":
+		// matches Issue.IsOverdue for the open issues
+		return sess.And("issue.is_closed = ?", false).
+			And("issue.deadline_unix > 0").
+			And("issue.deadline_unix <= ?", timeutil.TimeStampNow())
+	case "set":
+		return sess.And("issue.deadline_unix > 0")
+	case "unset":
+		return sess.And("issue.deadline_unix = 0")
+	}
+	return sess
+}
+
 func applyReposCondition(sess *xorm.Session, repoIDs []int64) *xorm.Session {
 	return sess.In("issue.repo_id", repoIDs)
 }
 
+func applyNotDeletedReposCondition(sess *xorm.Session) *xorm.Session {
+	return sess.NotIn("issue.repo_id", builder.Select("id").From("repository").Where(builder.Eq{"is_deleted": true}))
+}
+
 func applyAssigneeCondition(sess *xorm.Session, assigneeID int64) *xorm.Session {
 	return sess.Join("INNER", "issue_assignees", "issue.id = issue_assignees.issue_id").
 		And("issue_assignees.assignee_id = ?", assigneeID)
@@ -1333,7 +1437,11 @@ func Issues(opts *IssuesOptions) ([]*Issue, error) {
 
 	sess.Join("INNER", "repository", "`issue`.repo_id = `repository`.id")
 	opts.setupSession(sess)
-	sortIssuesSession(sess, opts.SortType, opts.PriorityRepoID)
+	if opts.SortType == "relevance" && len(opts.IssueIDs) > 0 {
+		sortIssuesByRelevance(sess, opts.IssueIDs)
+	} else {
+		sortIssuesSession(sess, opts.SortType, opts.PriorityRepoID)
+	}
 
 	issues := make([]*Issue, 0, opts.ListOptions.PageSize)
 	if err := sess.Find(&issues); err != nil {
@@ -1449,6 +1557,7 @@ type IssueStatsOptions struct {
 	ReviewRequestedID int64
 	IsPull            util.OptionalBool
 	IssueIDs          []int64
+	DueDate           string
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1530,6 +1639,8 @@ func getIssueStatsChunk(opts *IssueStatsOptions, issueIDs []int64) (*IssueStats,
 			applyReviewRequestedCondition(sess, opts.ReviewRequestedID)
 		}
 
+		applyDueDateCondition(sess, opts.DueDate)
+
 		switch opts.IsPull {
 		case util.OptionalBoolTrue:
 			sess.And("issue.is_pull=?", true)
@@ -1581,7 +1692,7 @@ func GetUserIssueStats(opts UserIssueStatsOptions) (*IssueStats, error) {
 	}
 
 	sess := func(cond builder.Cond) *xorm.Session {
-		s := x.Where(cond)
+		s := applyNotDeletedReposCondition(x.Where(cond))
 		if len(opts.LabelIDs) > 0 {
 			s.Join("INNER", "issue_label", "issue_label.issue_id = issue.id").
 				In("issue_label.label_id", opts.LabelIDs)
@@ -1820,29 +1931,35 @@ func UpdateIssueByAPI(issue *Issue, doer *User) (statusChangeComment *Comment, t
 }
 
 // UpdateIssueDeadline updates an issue deadline and adds comments. Setting a deadline to 0 means deleting it.
-func UpdateIssueDeadline(issue *Issue, deadlineUnix timeutil.TimeStamp, doer *User) (err error) {
+// The returned comment is nil if the deadline has not changed.
+func UpdateIssueDeadline(issue *Issue, deadlineUnix timeutil.TimeStamp, doer *User) (*Comment, error) {
 	// if the deadline hasn't changed do nothing
 	if issue.DeadlineUnix == deadlineUnix {
-		return nil
+		return nil, nil
 	}
 
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Update the deadline
-	if err = updateIssueCols(sess, &Issue{ID: issue.ID, DeadlineUnix: deadlineUnix}, "deadline_unix"); err != nil {
-		return err
+	if err := updateIssueCols(sess, &Issue{ID: issue.ID, DeadlineUnix: deadlineUnix}, "deadline_unix"); err != nil {
+		return nil, err
 	}
 
 	// Make the comment
-	if _, err = createDeadlineComment(sess, doer, issue, deadlineUnix); err != nil {
-		return fmt.Errorf("createRemovedDueDateComment: %v", err)
+	comment, err := createDeadlineComment(sess, doer, issue, deadlineUnix)
+	if err != nil {
+		return nil, fmt.Errorf("createRemovedDueDateComment: %v", err)
 	}
 
-	return sess.Commit()
+	if err := sess.Commit(); err != nil {
+		return nil, err
+	}
+	issue.DeadlineUnix = deadlineUnix
+	return comment, nil
 }
 
 // DependencyInfo represents high level information about an issue which is a dependency of another issue.
@@ -2102,6 +2219,12 @@ func UpdateReactionsMigrationsByType(gitServiceType structs.GitServiceType, orig
 func deleteIssuesByRepoID(sess Engine, repoID int64) (attachmentPaths []string, err error) {
 	deleteCond := builder.Select("id").From("issue").Where(builder.Eq{"issue.repo_id": repoID})
 
+	// Delete the versions of the edited comments
+	if _, err = sess.In("comment_id", builder.Select("id").From("comment").Where(builder.In("issue_id", deleteCond))).
+		Delete(&CommentVersion{}); err != nil {
+		return
+	}
+
 	// Delete comments and attachments
 	if _, err = sess.In("issue_id", deleteCond).
 		Delete(&Comment{}); err != nil {
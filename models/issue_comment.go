@@ -101,8 +101,64 @@ const (
 	CommentTypeProjectBoard
 	// Dismiss Review
 	CommentTypeDismissReview
+	// 33 Pull request scheduled to be merged automatically
+	CommentTypePRScheduledToAutoMerge
+	// 34 Automatic merge of a pull request canceled
+	CommentTypePRUnScheduledToAutoMerge
+	// 35 Pull request added to the merge queue of its base branch
+	CommentTypePRAddedToMergeQueue
+	// 36 Pull request removed from the merge queue of its base branch
+	CommentTypePRRemovedFromMergeQueue
 )
 
+var commentStrings = []string{
+	"comment",
+	"reopen",
+	"close",
+	"issue_ref",
+	"commit_ref",
+	"comment_ref",
+	"pull_ref",
+	"label",
+	"milestone",
+	"assignees",
+	"change_title",
+	"delete_branch",
+	"start_tracking",
+	"stop_tracking",
+	"add_time_manual",
+	"cancel_tracking",
+	"added_deadline",
+	"modified_deadline",
+	"removed_deadline",
+	"add_dependency",
+	"remove_dependency",
+	"code",
+	"review",
+	"lock",
+	"unlock",
+	"change_target_branch",
+	"delete_time_manual",
+	"review_request",
+	"merge_pull",
+	"pull_push",
+	"project",
+	"project_board",
+	"dismiss_review",
+	"pull_scheduled_merge",
+	"pull_cancel_scheduled_merge",
+	"pull_merge_queue_add",
+	"pull_merge_queue_remove",
+}
+
+// String returns the name of the comment type, as used in the API
+func (t CommentType) String() string {
+	if t < 0 || int(t) >= len(commentStrings) {
+		return "unknown"
+	}
+	return commentStrings[t]
+}
+
 // CommentTag defines comment tag type
 type CommentTag int
 
@@ -151,6 +207,9 @@ type Comment struct {
 	NewRef           string
 	DependentIssueID int64
 	DependentIssue   *Issue `xorm:"-"`
+	// ExtractedIssueID is the issue created from this comment
+	ExtractedIssueID int64  `xorm:"NOT NULL DEFAULT 0"`
+	ExtractedIssue   *Issue `xorm:"-"`
 
 	CommitID        int64
 	Line            int64 // - previous line / + proposed line
@@ -541,6 +600,23 @@ func (c *Comment) LoadDepIssueDetails() (err error) {
 	return err
 }
 
+// LoadExtractedIssue loads the issue created from the comment, if any
+func (c *Comment) LoadExtractedIssue() (err error) {
+	if c.ExtractedIssueID <= 0 || c.ExtractedIssue != nil {
+		return nil
+	}
+	c.ExtractedIssue, err = getIssueByID(x, c.ExtractedIssueID)
+	return err
+}
+
+// SetExtractedIssue records that the issue has been created from the comment
+func (c *Comment) SetExtractedIssue(issue *Issue) error {
+	c.ExtractedIssueID = issue.ID
+	c.ExtractedIssue = issue
+	_, err := x.ID(c.ID).Cols("extracted_issue_id").NoAutoTime().Update(c)
+	return err
+}
+
 // LoadTime loads the associated time for a CommentTypeAddTimeManual
 func (c *Comment) LoadTime() error {
 	if c.Time != nil || c.TimeID == 0 {
@@ -834,6 +910,9 @@ func createIssueDependencyComment(e *xorm.Session, doer *User, issue, dependentI
 	if err = issue.loadRepo(e); err != nil {
 		return
 	}
+	if err = dependentIssue.loadRepo(e); err != nil {
+		return
+	}
 
 	// Make two comments, one in each issue
 	opts := &CreateCommentOptions{
@@ -850,7 +929,7 @@ func createIssueDependencyComment(e *xorm.Session, doer *User, issue, dependentI
 	opts = &CreateCommentOptions{
 		Type:             cType,
 		Doer:             doer,
-		Repo:             issue.Repo,
+		Repo:             dependentIssue.Repo,
 		Issue:            dependentIssue,
 		DependentIssueID: issue.ID,
 	}
@@ -1035,6 +1114,9 @@ func UpdateComment(c *Comment, doer *User) error {
 		return err
 	}
 
+	if err := addCommentVersion(sess, c, doer); err != nil {
+		return err
+	}
 	if _, err := sess.ID(c.ID).AllCols().Update(c); err != nil {
 		return err
 	}
@@ -1081,6 +1163,9 @@ func deleteComment(e Engine, comment *Comment) error {
 	if _, err := e.Where("comment_id = ?", comment.ID).Cols("is_deleted").Update(&Action{IsDeleted: true}); err != nil {
 		return err
 	}
+	if _, err := e.Delete(&CommentVersion{CommentID: comment.ID}); err != nil {
+		return err
+	}
 
 	if err := comment.neuterCrossReferences(e); err != nil {
 		return err
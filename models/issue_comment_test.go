@@ -57,3 +57,10 @@ func TestFetchCodeComments(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, res, 1)
 }
+
+func TestCommentType_String(t *testing.T) {
+	assert.Equal(t, "comment", CommentTypeComment.String())
+	assert.Equal(t, "label", CommentTypeLabel.String())
+	assert.Equal(t, "dismiss_review", CommentTypeDismissReview.String())
+	assert.Equal(t, "unknown", CommentTypeUnknown.String())
+}
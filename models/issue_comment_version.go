@@ -0,0 +1,154 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// CommentVersion represents a version of the content of an edited comment
+type CommentVersion struct {
+	ID        int64 `xorm:"pk autoincr"`
+	CommentID int64 `xorm:"INDEX NOT NULL"`
+	// EditorID is the user who wrote this version, the poster of the comment for the original one
+	EditorID    int64
+	Editor      *User              `xorm:"-"`
+	Content     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// ErrCommentVersionNotExist represents a "CommentVersionNotExist" kind of error.
+type ErrCommentVersionNotExist struct {
+	ID        int64
+	CommentID int64
+}
+
+// IsErrCommentVersionNotExist checks if an error is a ErrCommentVersionNotExist.
+func IsErrCommentVersionNotExist(err error) bool {
+	_, ok := err.(ErrCommentVersionNotExist)
+	return ok
+}
+
+func (err ErrCommentVersionNotExist) Error() string {
+	return fmt.Sprintf("comment version does not exist [id: %d, comment_id: %d]", err.ID, err.CommentID)
+}
+
+// LoadEditor loads the user who wrote the version, a ghost user if it does not exist anymore
+func (v *CommentVersion) LoadEditor() (err error) {
+	if v.Editor != nil {
+		return nil
+	}
+	v.Editor, err = GetUserByID(v.EditorID)
+	if IsErrUserNotExist(err) {
+		v.Editor = NewGhostUser()
+		return nil
+	}
+	return err
+}
+
+// addCommentVersion keeps the new content of the comment as a version if it was changed, along with
+// the original content on the first edit, and removes the oldest versions but the original one above
+// the maximum number of versions
+func addCommentVersion(e Engine, c *Comment, doer *User) error {
+	var oldContent string
+	if has, err := e.Table("comment").Where("id = ?", c.ID).Cols("content").Get(&oldContent); err != nil {
+		return err
+	} else if !has || oldContent == c.Content {
+		return nil
+	}
+
+	count, err := e.Where("comment_id = ?", c.ID).Count(new(CommentVersion))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err := e.Insert(&CommentVersion{
+			CommentID:   c.ID,
+			EditorID:    c.PosterID,
+			Content:     oldContent,
+			CreatedUnix: c.CreatedUnix,
+		}); err != nil {
+			return err
+		}
+		count++
+	}
+	if _, err := e.Insert(&CommentVersion{
+		CommentID:   c.ID,
+		EditorID:    doer.ID,
+		Content:     c.Content,
+		CreatedUnix: timeutil.TimeStampNow(),
+	}); err != nil {
+		return err
+	}
+	count++
+
+	max := int64(setting.Repository.Issue.MaxCommentVersions)
+	if max <= 0 || count <= max {
+		return nil
+	}
+	// the original version is kept, at least two versions are needed for it and the current one
+	if max < 2 {
+		max = 2
+	}
+	removedIDs := make([]int64, 0, count-max)
+	if err := e.Table("comment_version").Where("comment_id = ?", c.ID).Asc("id").
+		Limit(int(count-max), 1).Cols("id").Find(&removedIDs); err != nil {
+		return err
+	}
+	_, err = e.In("id", removedIDs).Delete(new(CommentVersion))
+	return err
+}
+
+// GetCommentVersions returns the versions of an edited comment, the original first, with their editors
+func GetCommentVersions(commentID int64) ([]*CommentVersion, error) {
+	versions := make([]*CommentVersion, 0, 5)
+	if err := x.Where("comment_id = ?", commentID).Asc("id").Find(&versions); err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if err := v.LoadEditor(); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+// GetCommentVersion returns the version of the comment by its id
+func GetCommentVersion(commentID, id int64) (*CommentVersion, error) {
+	v := new(CommentVersion)
+	has, err := x.Where("id = ? AND comment_id = ?", id, commentID).Get(v)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCommentVersionNotExist{ID: id, CommentID: commentID}
+	}
+	return v, nil
+}
+
+// GetEditedCommentIDs returns which of the comments were edited
+func GetEditedCommentIDs(commentIDs []int64) (map[int64]bool, error) {
+	edited := make(map[int64]bool)
+	for len(commentIDs) > 0 {
+		limit := maxQueryParameters
+		if limit > len(commentIDs) {
+			limit = len(commentIDs)
+		}
+		ids := make([]int64, 0, limit)
+		if err := x.Table("comment_version").Where(builder.In("comment_id", commentIDs[:limit])).
+			Distinct("comment_id").Find(&ids); err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			edited[id] = true
+		}
+		commentIDs = commentIDs[limit:]
+	}
+	return edited, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentVersions(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(max int) {
+		setting.Repository.Issue.MaxCommentVersions = max
+	}(setting.Repository.Issue.MaxCommentVersions)
+	setting.Repository.Issue.MaxCommentVersions = 3
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	comment := AssertExistsAndLoadBean(t, &Comment{ID: 2}).(*Comment)
+	original := comment.Content
+
+	// an update which does not change the content is not a version
+	assert.NoError(t, UpdateComment(comment, doer))
+	AssertNotExistsBean(t, &CommentVersion{CommentID: 2})
+
+	for i := 1; i <= 3; i++ {
+		comment.Content = fmt.Sprintf("edit %d", i)
+		assert.NoError(t, UpdateComment(comment, doer))
+	}
+
+	// the original version is kept with its poster
+	versions, err := GetCommentVersions(2)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+	if len(versions) == 3 {
+		assert.Equal(t, original, versions[0].Content)
+		assert.EqualValues(t, comment.PosterID, versions[0].EditorID)
+		assert.Equal(t, comment.CreatedUnix, versions[0].CreatedUnix)
+		assert.Equal(t, "edit 2", versions[1].Content)
+		assert.Equal(t, "edit 3", versions[2].Content)
+		assert.EqualValues(t, doer.ID, versions[2].Editor.ID)
+	}
+
+	_, err = GetCommentVersion(3, versions[0].ID)
+	assert.True(t, IsErrCommentVersionNotExist(err))
+
+	edited, err := GetEditedCommentIDs([]int64{2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]bool{2: true}, edited)
+
+	assert.NoError(t, DeleteComment(comment))
+	AssertNotExistsBean(t, &CommentVersion{CommentID: 2})
+}
@@ -56,3 +56,27 @@ func TestCreateIssueDependency(t *testing.T) {
 	err = RemoveIssueDependency(user1, issue1, issue2, DependencyTypeBlockedBy)
 	assert.NoError(t, err)
 }
+
+func TestCreateCrossRepositoryIssueDependency(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	// issue 7 is in repo2
+	issue7 := AssertExistsAndLoadBean(t, &Issue{ID: 7}).(*Issue)
+
+	assert.NoError(t, CreateIssueDependency(user2, issue1, issue7))
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeAddDependency, IssueID: 1, DependentIssueID: 7})
+	AssertExistsAndLoadBean(t, &Comment{Type: CommentTypeAddDependency, IssueID: 7, DependentIssueID: 1})
+
+	// the open issue of the other repository blocks closing
+	assert.NoError(t, issue1.LoadRepo())
+	issue1.Repo.MustGetUnit(UnitTypeIssues).IssuesConfig().EnableDependencies = true
+	_, err := issue1.ChangeStatus(user2, true)
+	assert.True(t, IsErrDependenciesLeft(err))
+
+	_, err = issue7.ChangeStatus(user2, true)
+	assert.NoError(t, err)
+	_, err = issue1.ChangeStatus(user2, true)
+	assert.NoError(t, err)
+}
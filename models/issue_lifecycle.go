@@ -0,0 +1,236 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IssueLifecycleStats holds the number of issues and pull requests and the total durations of their lifecycles
+type IssueLifecycleStats struct {
+	NumIssues int64
+	NumPulls  int64
+
+	NumFirstResponses    int64
+	FirstResponseSeconds int64
+	NumClosed            int64
+	CloseSeconds         int64
+	NumMerged            int64
+	MergeSeconds         int64
+}
+
+// LabelIssueLifecycleStats holds the lifecycle stats of the issues and pull requests with a label
+type LabelIssueLifecycleStats struct {
+	Label *Label
+	IssueLifecycleStats
+}
+
+// RepoIssueLifecycleStats holds the lifecycle stats of the issues and pull requests of a repository
+type RepoIssueLifecycleStats struct {
+	IssueLifecycleStats
+	Labels []*LabelIssueLifecycleStats
+}
+
+// IssueLifecycleOptions represents the options for computing lifecycle stats
+type IssueLifecycleOptions struct {
+	RepoID int64
+	// Since and Before limit the creation time of the issues, zero means no limit
+	Since         int64
+	Before        int64
+	IncludeIssues bool
+	IncludePulls  bool
+}
+
+// issueLifecycle holds the timestamps of the lifecycle of an issue or pull request
+type issueLifecycle struct {
+	ID                int64
+	PosterID          int64
+	IsPull            bool
+	IsClosed          bool
+	CreatedUnix       timeutil.TimeStamp
+	UpdatedUnix       timeutil.TimeStamp
+	ClosedUnix        timeutil.TimeStamp
+	HasMerged         bool
+	MergedUnix        timeutil.TimeStamp
+	FirstResponseUnix timeutil.TimeStamp `xorm:"-"`
+}
+
+func (stats *IssueLifecycleStats) add(issue *issueLifecycle) {
+	if issue.IsPull {
+		stats.NumPulls++
+	} else {
+		stats.NumIssues++
+	}
+	if issue.FirstResponseUnix >= issue.CreatedUnix && issue.FirstResponseUnix > 0 {
+		stats.NumFirstResponses++
+		stats.FirstResponseSeconds += int64(issue.FirstResponseUnix - issue.CreatedUnix)
+	}
+	if issue.IsClosed && issue.ClosedUnix > 0 {
+		stats.NumClosed++
+		stats.CloseSeconds += int64(issue.ClosedUnix - issue.CreatedUnix)
+	}
+	if issue.HasMerged && issue.MergedUnix > 0 {
+		stats.NumMerged++
+		stats.MergeSeconds += int64(issue.MergedUnix - issue.CreatedUnix)
+	}
+}
+
+// responseCommentTypes are the types of comments which count as a response to an issue or pull request
+var responseCommentTypes = []CommentType{CommentTypeComment, CommentTypeCode, CommentTypeReview}
+
+// firstResponseCacheKey returns the cache key of the first response to the issue, the key changes
+// with each update of the issue so only the issues which have changed are looked up again.
+func firstResponseCacheKey(issue *issueLifecycle) string {
+	return fmt.Sprintf("IssueFirstResponse:%d:%d", issue.ID, issue.UpdatedUnix)
+}
+
+// loadFirstResponses loads the time of the first comment of another user than the poster of each issue
+func loadFirstResponses(e Engine, issues []*issueLifecycle) error {
+	c := cache.GetCache()
+	useCache := c != nil && setting.CacheService.TTL > 0
+
+	missing := make(map[int64]*issueLifecycle, len(issues))
+	for _, issue := range issues {
+		if useCache {
+			switch v := c.Get(firstResponseCacheKey(issue)).(type) {
+			case int64:
+				issue.FirstResponseUnix = timeutil.TimeStamp(v)
+				continue
+			case string:
+				if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+					issue.FirstResponseUnix = timeutil.TimeStamp(unix)
+					continue
+				}
+			}
+		}
+		missing[issue.ID] = issue
+	}
+
+	ids := make([]int64, 0, len(missing))
+	for id := range missing {
+		ids = append(ids, id)
+	}
+	for len(ids) > 0 {
+		batch := ids
+		if len(batch) > setting.Database.IterateBufferSize {
+			batch = batch[:setting.Database.IterateBufferSize]
+		}
+		ids = ids[len(batch):]
+
+		var responses []struct {
+			IssueID     int64
+			CreatedUnix int64
+		}
+		if err := e.Table("comment").
+			Select("comment.issue_id, MIN(comment.created_unix) AS created_unix").
+			Join("INNER", "issue", "issue.id = comment.issue_id").
+			Where(builder.In("comment.issue_id", batch)).
+			And(builder.In("comment.type", responseCommentTypes)).
+			And("comment.poster_id <> issue.poster_id").
+			GroupBy("comment.issue_id").
+			Find(&responses); err != nil {
+			return err
+		}
+		for _, response := range responses {
+			missing[response.IssueID].FirstResponseUnix = timeutil.TimeStamp(response.CreatedUnix)
+		}
+		if useCache {
+			for _, id := range batch {
+				issue := missing[id]
+				if err := c.Put(firstResponseCacheKey(issue), int64(issue.FirstResponseUnix), setting.CacheService.TTLSeconds()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetRepoIssueLifecycleStats returns the lifecycle stats of the issues and pull requests of a repository
+// created in the given time range, in total and by label
+func GetRepoIssueLifecycleStats(opts *IssueLifecycleOptions) (*RepoIssueLifecycleStats, error) {
+	stats := &RepoIssueLifecycleStats{}
+	if !opts.IncludeIssues && !opts.IncludePulls {
+		return stats, nil
+	}
+
+	cond := builder.NewCond().And(builder.Eq{"issue.repo_id": opts.RepoID})
+	if !opts.IncludeIssues {
+		cond = cond.And(builder.Eq{"issue.is_pull": true})
+	} else if !opts.IncludePulls {
+		cond = cond.And(builder.Eq{"issue.is_pull": false})
+	}
+	if opts.Since > 0 {
+		cond = cond.And(builder.Gte{"issue.created_unix": opts.Since})
+	}
+	if opts.Before > 0 {
+		cond = cond.And(builder.Lt{"issue.created_unix": opts.Before})
+	}
+
+	issues := make([]*issueLifecycle, 0, 10)
+	if err := x.Table("issue").
+		Select("issue.id, issue.poster_id, issue.is_pull, issue.is_closed, issue.created_unix, issue.updated_unix, issue.closed_unix, pull_request.has_merged, pull_request.merged_unix").
+		Join("LEFT", "pull_request", "pull_request.issue_id = issue.id").
+		Where(cond).
+		Find(&issues); err != nil {
+		return nil, err
+	}
+	if err := loadFirstResponses(x, issues); err != nil {
+		return nil, err
+	}
+
+	issuesByID := make(map[int64]*issueLifecycle, len(issues))
+	for _, issue := range issues {
+		stats.add(issue)
+		issuesByID[issue.ID] = issue
+	}
+
+	// break down by label
+	var issueLabels []*IssueLabel
+	if err := x.Table("issue_label").
+		Join("INNER", "issue", "issue.id = issue_label.issue_id").
+		Where(cond).
+		Cols("issue_label.issue_id", "issue_label.label_id").
+		Find(&issueLabels); err != nil {
+		return nil, err
+	}
+	labelStats := make(map[int64]*LabelIssueLifecycleStats)
+	for _, issueLabel := range issueLabels {
+		issue, ok := issuesByID[issueLabel.IssueID]
+		if !ok {
+			continue
+		}
+		if labelStats[issueLabel.LabelID] == nil {
+			labelStats[issueLabel.LabelID] = &LabelIssueLifecycleStats{}
+		}
+		labelStats[issueLabel.LabelID].add(issue)
+	}
+	if len(labelStats) > 0 {
+		labelIDs := make([]int64, 0, len(labelStats))
+		for id := range labelStats {
+			labelIDs = append(labelIDs, id)
+		}
+		labels := make([]*Label, 0, len(labelIDs))
+		if err := x.In("id", labelIDs).Find(&labels); err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			labelStats[label.ID].Label = label
+			stats.Labels = append(stats.Labels, labelStats[label.ID])
+		}
+		sort.Slice(stats.Labels, func(i, j int) bool {
+			return stats.Labels[i].Label.Name < stats.Labels[j].Label.Name
+		})
+	}
+	return stats, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRepoIssueLifecycleStats(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	stats, err := GetRepoIssueLifecycleStats(&IssueLifecycleOptions{RepoID: 1, IncludeIssues: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, stats.NumIssues)
+	assert.EqualValues(t, 0, stats.NumPulls)
+	assert.EqualValues(t, 1, stats.NumFirstResponses)
+	assert.EqualValues(t, 11, stats.FirstResponseSeconds)
+	if assert.Len(t, stats.Labels, 2) {
+		assert.Equal(t, "label1", stats.Labels[0].Label.Name)
+		assert.EqualValues(t, 1, stats.Labels[0].NumFirstResponses)
+		assert.Equal(t, "label2", stats.Labels[1].Label.Name)
+		assert.EqualValues(t, 0, stats.Labels[1].NumFirstResponses)
+	}
+
+	// a response of the poster of the issue is no first response
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	if assert.NoError(t, issue.LoadRepo()) && assert.NoError(t, issue.LoadPoster()) {
+		_, err = CreateComment(&CreateCommentOptions{Type: CommentTypeComment, Doer: issue.Poster, Repo: issue.Repo, Issue: issue, Content: "bump"})
+		assert.NoError(t, err)
+	}
+	stats, err = GetRepoIssueLifecycleStats(&IssueLifecycleOptions{RepoID: 1, IncludeIssues: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stats.NumFirstResponses)
+
+	stats, err = GetRepoIssueLifecycleStats(&IssueLifecycleOptions{RepoID: 1, IncludePulls: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, stats.NumIssues)
+	assert.EqualValues(t, 3, stats.NumPulls)
+
+	// the time range limits the creation time
+	stats, err = GetRepoIssueLifecycleStats(&IssueLifecycleOptions{RepoID: 1, IncludeIssues: true, IncludePulls: true, Since: 946684810, Before: 946684840})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, stats.NumIssues)
+	assert.EqualValues(t, 2, stats.NumPulls)
+
+	stats, err = GetRepoIssueLifecycleStats(&IssueLifecycleOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, stats.NumIssues+stats.NumPulls)
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// IsPinned returns true if the issue is pinned to the top of the list of its repository
+func (issue *Issue) IsPinned() bool {
+	return issue.PinOrder > 0
+}
+
+func setIssuePinOrder(e Engine, issueID int64, order int) error {
+	_, err := e.ID(issueID).Cols("pin_order").NoAutoTime().Update(&Issue{PinOrder: order})
+	return err
+}
+
+// PinIssue pins the issue after the already pinned issues (or pull requests) of its repository,
+// unless maxPinned of them are pinned already
+func PinIssue(issue *Issue, maxPinned int) error {
+	if issue.IsPinned() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	count, err := sess.Where("repo_id = ? AND is_pull = ? AND pin_order > 0", issue.RepoID, issue.IsPull).Count(new(Issue))
+	if err != nil {
+		return err
+	}
+	if int(count) >= maxPinned {
+		return ErrIssueMaxPinReached{Max: maxPinned}
+	}
+
+	var maxOrder int
+	if _, err := sess.Table("issue").
+		Where("repo_id = ? AND is_pull = ?", issue.RepoID, issue.IsPull).
+		Select("MAX(pin_order)").Get(&maxOrder); err != nil {
+		return err
+	}
+
+	if err := setIssuePinOrder(sess, issue.ID, maxOrder+1); err != nil {
+		return err
+	}
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	issue.PinOrder = maxOrder + 1
+	return nil
+}
+
+// UnpinIssue unpins the issue and moves the issues pinned after it up
+func UnpinIssue(issue *Issue) error {
+	if !issue.IsPinned() {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if err := setIssuePinOrder(sess, issue.ID, 0); err != nil {
+		return err
+	}
+	if _, err := sess.Exec("UPDATE `issue` SET pin_order = pin_order - 1 WHERE repo_id = ? AND is_pull = ? AND pin_order > ?",
+		issue.RepoID, issue.IsPull, issue.PinOrder); err != nil {
+		return err
+	}
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	issue.PinOrder = 0
+	return nil
+}
+
+// GetPinnedIssues returns the pinned issues, or pull requests, of the repository in their pinned order
+func GetPinnedIssues(repoID int64, isPull bool) (IssueList, error) {
+	issues := make(IssueList, 0, 5)
+	return issues, x.
+		Where("repo_id = ? AND is_pull = ? AND pin_order > 0", repoID, isPull).
+		OrderBy("pin_order").
+		Find(&issues)
+}
+
+// ReorderPinnedIssues sets the order of the pinned issues, or pull requests, of the repository to the
+// order of issueIDs, which must contain all of them exactly once
+func ReorderPinnedIssues(repoID int64, isPull bool, issueIDs []int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	pinnedIDs := make([]int64, 0, len(issueIDs))
+	if err := sess.Table("issue").
+		Where("repo_id = ? AND is_pull = ? AND pin_order > 0", repoID, isPull).
+		Cols("id").Find(&pinnedIDs); err != nil {
+		return err
+	}
+	if len(pinnedIDs) != len(issueIDs) {
+		return ErrPinnedIssuesOrderMismatch{RepoID: repoID}
+	}
+	pinned := make(map[int64]bool, len(pinnedIDs))
+	for _, id := range pinnedIDs {
+		pinned[id] = true
+	}
+	for _, id := range issueIDs {
+		if !pinned[id] {
+			return ErrPinnedIssuesOrderMismatch{RepoID: repoID}
+		}
+		// an id given twice would leave another pinned issue out
+		delete(pinned, id)
+	}
+
+	for i, id := range issueIDs {
+		if err := setIssuePinOrder(sess, id, i+1); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getPinnedIssueIDs(t *testing.T, repoID int64) []int64 {
+	issues, err := GetPinnedIssues(repoID, false)
+	assert.NoError(t, err)
+	ids := make([]int64, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	return ids
+}
+
+func TestPinIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	// pull requests of the same repository do not count against the issues
+	pull := AssertExistsAndLoadBean(t, &Issue{ID: 2, IsPull: true}).(*Issue)
+	assert.NoError(t, PinIssue(pull, 1))
+
+	assert.NoError(t, PinIssue(issue1, 1))
+	assert.EqualValues(t, 1, issue1.PinOrder)
+	assert.True(t, IsErrIssueMaxPinReached(PinIssue(issue5, 1)))
+	assert.False(t, issue5.IsPinned())
+
+	// pinning twice is a no-op
+	assert.NoError(t, PinIssue(issue1, 1))
+
+	assert.NoError(t, PinIssue(issue5, 2))
+	assert.EqualValues(t, 2, issue5.PinOrder)
+	assert.Equal(t, []int64{1, 5}, getPinnedIssueIDs(t, 1))
+
+	assert.NoError(t, UnpinIssue(issue1))
+	assert.False(t, issue1.IsPinned())
+	AssertExistsAndLoadBean(t, &Issue{ID: 5, PinOrder: 1})
+	assert.NoError(t, PinIssue(issue1, 2))
+	assert.Equal(t, []int64{5, 1}, getPinnedIssueIDs(t, 1))
+}
+
+func TestReorderPinnedIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	issue5 := AssertExistsAndLoadBean(t, &Issue{ID: 5}).(*Issue)
+	assert.NoError(t, PinIssue(issue1, 3))
+	assert.NoError(t, PinIssue(issue5, 3))
+
+	assert.NoError(t, ReorderPinnedIssues(1, false, []int64{5, 1}))
+	assert.Equal(t, []int64{5, 1}, getPinnedIssueIDs(t, 1))
+
+	assert.True(t, IsErrPinnedIssuesOrderMismatch(ReorderPinnedIssues(1, false, []int64{5})))
+	assert.True(t, IsErrPinnedIssuesOrderMismatch(ReorderPinnedIssues(1, false, []int64{5, 5})))
+	assert.True(t, IsErrPinnedIssuesOrderMismatch(ReorderPinnedIssues(1, false, []int64{5, 2})))
+	assert.Equal(t, []int64{5, 1}, getPinnedIssueIDs(t, 1))
+}
@@ -149,6 +149,13 @@ func CreateReaction(opts *ReactionOptions) (*Reaction, error) {
 		return nil, err
 	}
 
+	if err := opts.Issue.loadRepo(sess); err != nil {
+		return nil, err
+	}
+	if !opts.Issue.Repo.IsReactionAllowed(opts.Type) {
+		return nil, ErrForbiddenIssueReaction{opts.Type}
+	}
+
 	reaction, err := createReaction(sess, opts)
 	if err != nil {
 		return reaction, err
@@ -56,6 +56,29 @@ func TestIssueAddDuplicateReaction(t *testing.T) {
 	assert.Equal(t, existingR.ID, reaction.ID)
 }
 
+func TestIssueAddReactionAllowedByRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user1 := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	issue1 := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue1.LoadRepo())
+	issue1.Repo.AllowedReactions = []string{"+1", "heart", "unknown"}
+
+	assert.Equal(t, []string{"+1", "heart"}, issue1.Repo.GetAllowedReactions())
+	assert.True(t, issue1.Repo.IsReactionAllowed("heart"))
+	assert.False(t, issue1.Repo.IsReactionAllowed("-1"))
+	assert.False(t, issue1.Repo.IsReactionAllowed("unknown"))
+
+	addReaction(t, user1, issue1, nil, "heart")
+	_, err := CreateIssueReaction(user1, issue1, "-1")
+	assert.Equal(t, ErrForbiddenIssueReaction{Reaction: "-1"}, err)
+	AssertNotExistsBean(t, &Reaction{Type: "-1", UserID: user1.ID, IssueID: issue1.ID})
+
+	issue1.Repo.AllowedReactions = nil
+	assert.Equal(t, setting.UI.Reactions, issue1.Repo.GetAllowedReactions())
+	addReaction(t, user1, issue1, nil, "-1")
+}
+
 func TestIssueDeleteReaction(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -167,6 +169,28 @@ func TestIssues(t *testing.T) {
 			},
 			[]int64{}, // issues with **both** label 1 and 2, none of these issues matches, TODO: add more tests
 		},
+		{
+			IssuesOptions{
+				RepoIDs:  []int64{1},
+				IssueIDs: []int64{3, 1, 2},
+				SortType: "relevance",
+			},
+			[]int64{3, 1, 2},
+		},
+		{
+			IssuesOptions{
+				RepoIDs: []int64{1, 42},
+				DueDate: "overdue",
+			},
+			[]int64{10},
+		},
+		{
+			IssuesOptions{
+				RepoIDs: []int64{42},
+				DueDate: "unset",
+			},
+			[]int64{},
+		},
 	} {
 		issues, err := Issues(&test.Opts)
 		assert.NoError(t, err)
@@ -178,6 +202,30 @@ func TestIssues(t *testing.T) {
 	}
 }
 
+func TestUpdateIssueDeadline(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	deadline := timeutil.TimeStamp(1019307200)
+	comment, err := UpdateIssueDeadline(issue, deadline, doer)
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypeAddedDeadline, comment.Type)
+	assert.Equal(t, deadline, issue.DeadlineUnix)
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, DeadlineUnix: deadline})
+
+	// unchanged
+	comment, err = UpdateIssueDeadline(issue, deadline, doer)
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+
+	comment, err = UpdateIssueDeadline(issue, 0, doer)
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypeRemovedDeadline, comment.Type)
+	AssertExistsAndLoadBean(t, &Issue{ID: 1}, "deadline_unix = 0")
+}
+
 func TestGetUserIssueStats(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	for _, test := range []struct {
@@ -370,6 +418,53 @@ func TestIssue_InsertIssue(t *testing.T) {
 	testInsertIssue(t, `my issue2, this is my son's love \n \r \ `, "special issue's '' comments?")
 }
 
+func TestIssue_NextIssueIndex(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	next, err := GetNextIssueIndex(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, next)
+
+	err = SetNextIssueIndex(repo, 5)
+	assert.True(t, IsErrIssueIndexTooLow(err))
+
+	assert.NoError(t, SetNextIssueIndex(repo, 100))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.EqualValues(t, 99, repo.IssueIndexOffset)
+
+	issue := &Issue{RepoID: repo.ID, PosterID: 2, Title: "continued numbering"}
+	assert.NoError(t, NewIssue(repo, issue, nil, nil))
+	assert.EqualValues(t, 100, issue.Index)
+
+	// the numbering continues above existing issues
+	issue = &Issue{RepoID: repo.ID, PosterID: 2, Title: "next"}
+	assert.NoError(t, NewIssue(repo, issue, nil, nil))
+	assert.EqualValues(t, 101, issue.Index)
+
+	next, err = GetNextIssueIndex(repo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 102, next)
+}
+
+func TestIssue_InsertIssuesKeepIndex(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	issue := &Issue{RepoID: repo.ID, Repo: repo, PosterID: 2, Title: "imported", Index: 42}
+	assert.NoError(t, InsertIssues(issue))
+	AssertExistsAndLoadBean(t, &Issue{RepoID: repo.ID, Index: 42, Title: "imported"})
+
+	// issues and pull requests share the numbers
+	err := InsertIssues(&Issue{RepoID: repo.ID, Repo: repo, PosterID: 2, Title: "collision", Index: 2})
+	assert.True(t, IsErrIssueIndexAlreadyExists(err))
+
+	// an issue without number gets the next free one
+	issue = &Issue{RepoID: repo.ID, Repo: repo, PosterID: 2, Title: "unnumbered"}
+	assert.NoError(t, InsertIssues(issue))
+	assert.EqualValues(t, 43, issue.Index)
+}
+
 func TestIssue_ResolveMentions(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
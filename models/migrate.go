@@ -39,6 +39,7 @@ func InsertMilestones(ms ...*Milestone) (err error) {
 // InsertIssues insert issues to database
 func InsertIssues(issues ...*Issue) error {
 	sess := x.NewSession()
+	defer sess.Close()
 	if err := sess.Begin(); err != nil {
 		return err
 	}
@@ -52,8 +53,25 @@ func InsertIssues(issues ...*Issue) error {
 }
 
 func insertIssue(sess *xorm.Session, issue *Issue) error {
-	if _, err := sess.NoAutoTime().Insert(issue); err != nil {
-		return err
+	if issue.Index > 0 {
+		// keep the number of the issue, issues and pull requests share the numbers
+		has, err := sess.Where("repo_id=? AND `index`=?", issue.RepoID, issue.Index).Exist(new(Issue))
+		if err != nil {
+			return err
+		} else if has {
+			return ErrIssueIndexAlreadyExists{issue.RepoID, issue.Index}
+		}
+		if _, err := sess.NoAutoTime().Insert(issue); err != nil {
+			return err
+		}
+	} else {
+		var offset int64
+		if issue.Repo != nil {
+			offset = issue.Repo.IssueIndexOffset
+		}
+		if err := insertIssueWithNextIndex(sess.NoAutoTime(), issue, offset); err != nil {
+			return err
+		}
 	}
 	issueLabels := make([]IssueLabel, 0, len(issue.Labels))
 	labelIDs := make([]int64, 0, len(issue.Labels))
@@ -194,6 +212,7 @@ func InsertPullRequests(prs ...*PullRequest) error {
 // InsertReleases migrates release
 func InsertReleases(rels ...*Release) error {
 	sess := x.NewSession()
+	defer sess.Close()
 	if err := sess.Begin(); err != nil {
 		return err
 	}
@@ -296,6 +296,76 @@ var migrations = []Migration{
 	NewMigration("Add time_id column to Comment", addTimeIDCommentColumn),
 	// v174 -> v175
 	NewMigration("create repo transfer table", addRepoTransfer),
+	// v175 -> v176
+	NewMigration("create protected tag table", createProtectedTagTable),
+	// v176 -> v177
+	NewMigration("create push policy table", createPushPolicyTable),
+	// v177 -> v178
+	NewMigration("add blob size limit to push policy", addBlobSizeLimitToPushPolicy),
+	// v178 -> v179
+	NewMigration("add forbidden paths to push policy", addForbiddenPathsToPushPolicy),
+	// v179 -> v180
+	NewMigration("add secret scanning to push policy", addSecretScanningToPushPolicy),
+	// v180 -> v181
+	NewMigration("add auth and last error to mirror", addAuthAndLastErrorToMirror),
+	// v181 -> v182
+	NewMigration("create remember token table", createRememberTokenTable),
+	// v182 -> v183
+	NewMigration("add retry info to hook task", addRetryInfoToHookTask),
+	// v183 -> v184
+	NewMigration("add signature type to webhook", addSignatureTypeToWebhook),
+	// v184 -> v185
+	NewMigration("create repo access token table", createRepoAccessTokenTable),
+	// v185 -> v186
+	NewMigration("create git one-time token table", createGitOneTimeTokenTable),
+	// v186 -> v187
+	NewMigration("create webauthn credential table", createWebAuthnCredentialTable),
+	// v187 -> v188
+	NewMigration("add readme settings to repository", addReadmeSettingsToRepository),
+	// v188 -> v189
+	NewMigration("add login lockout to user", addLoginLockoutToUser),
+	// v189 -> v190
+	NewMigration("add issue index offset to repository", addIssueIndexOffsetToRepository),
+	// v190 -> v191
+	NewMigration("add allowed reactions to repository", addAllowedReactionsToRepository),
+	// v191 -> v192
+	NewMigration("create user session table", createUserSessionTable),
+	// v192 -> v193
+	NewMigration("add require two factor to organization", addRequireTwoFactorToUser),
+	// v193 -> v194
+	NewMigration("create commit status annotation table", createCommitStatusAnnotationTable),
+	// v194 -> v195
+	NewMigration("create audit event table", createAuditEventTable),
+	// v195 -> v196
+	NewMigration("create pull auto merge table", createPullAutoMergeTable),
+	// v196 -> v197
+	NewMigration("add pin order to issue", addPinOrderToIssue),
+	// v197 -> v198
+	NewMigration("add soft delete columns to repository", addSoftDeleteToRepository),
+	// v198 -> v199
+	NewMigration("add extracted issue id to comment", addExtractedIssueIDToComment),
+	// v199 -> v200
+	NewMigration("create comment version table", createCommentVersionTable),
+	// v200 -> v201
+	NewMigration("add branch naming to push policy", addBranchNamingToPushPolicy),
+	// v201 -> v202
+	NewMigration("add merge queue to protected branches", addMergeQueue),
+	// v202 -> v203
+	NewMigration("add require code owner reviews to protected branches", addRequireCodeOwnerReviews),
+	// v203 -> v204
+	NewMigration("add require git auth to repository", addRequireGitAuthToRepository),
+	// v204 -> v205
+	NewMigration("add organization projects", addOrganizationProjects),
+	// v205 -> v206
+	NewMigration("add project board automation rules", addProjectBoardRules),
+	// v206 -> v207
+	NewMigration("add commit comments", addCommitComments),
+	// v207 -> v208
+	NewMigration("add default repository visibility to organization", addDefaultRepoVisibilityToUser),
+	// v208 -> v209
+	NewMigration("add remember lookup key to user session", addRememberLookupKeyToUserSession),
+	// v209 -> v210
+	NewMigration("add used by to git one-time token", addUsedByToGitOneTimeToken),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -0,0 +1,18 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addBlobSizeLimitToPushPolicy(x *xorm.Engine) error {
+	type PushPolicy struct {
+		MaxBlobSize        int64  `xorm:"NOT NULL DEFAULT 0"`
+		BlobSizeExemptions string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(PushPolicy))
+}
@@ -0,0 +1,32 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addForbiddenPathsToPushPolicy(x *xorm.Engine) error {
+	type PushPolicy struct {
+		ForbiddenPaths            string `xorm:"TEXT"`
+		ForbiddenPathsUseDefaults bool   `xorm:"NOT NULL DEFAULT false"`
+		ForbiddenPathsAdminBypass bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type OrgPushPolicy struct {
+		ID    int64 `xorm:"pk autoincr"`
+		OrgID int64 `xorm:"UNIQUE"`
+
+		ForbiddenPaths            string `xorm:"TEXT"`
+		ForbiddenPathsUseDefaults bool   `xorm:"NOT NULL DEFAULT false"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(PushPolicy), new(OrgPushPolicy))
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAuthAndLastErrorToMirror(x *xorm.Engine) error {
+	type Mirror struct {
+		AuthType   int    `xorm:"NOT NULL DEFAULT 0"`
+		AuthConfig string `xorm:"TEXT"`
+
+		LastErrorKind int    `xorm:"NOT NULL DEFAULT 0"`
+		LastError     string `xorm:"TEXT"`
+		LastErrorUnix timeutil.TimeStamp
+	}
+
+	return x.Sync2(new(Mirror))
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createRememberTokenTable(x *xorm.Engine) error {
+	type RememberToken struct {
+		ID                int64  `xorm:"pk autoincr"`
+		UID               int64  `xorm:"INDEX NOT NULL"`
+		LookupKey         string `xorm:"UNIQUE NOT NULL"`
+		ValidatorHash     string `xorm:"NOT NULL"`
+		PrevValidatorHash string
+		DeviceHash        string `xorm:"NOT NULL"`
+		DeviceName        string
+		LastIP            string
+		UserStamp         string `xorm:"NOT NULL"`
+
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		LastUsedUnix timeutil.TimeStamp
+		ExpiresUnix  timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(RememberToken))
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addSignatureTypeToWebhook(x *xorm.Engine) error {
+	type Webhook struct {
+		SignatureType string `xorm:"VARCHAR(16)"`
+	}
+	if err := x.Sync2(new(Webhook)); err != nil {
+		return err
+	}
+
+	type HookTask struct {
+		SignatureType string `xorm:"VARCHAR(16)"`
+	}
+	return x.Sync2(new(HookTask))
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createRepoAccessTokenTable(x *xorm.Engine) error {
+	type RepoAccessToken struct {
+		ID             int64 `xorm:"pk autoincr"`
+		RepoID         int64 `xorm:"INDEX"`
+		Name           string
+		TokenHash      string `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+		CreatedUnix  timeutil.TimeStamp `xorm:"INDEX created"`
+		LastUsedUnix timeutil.TimeStamp
+	}
+
+	return x.Sync2(new(RepoAccessToken))
+}
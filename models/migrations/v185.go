@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createGitOneTimeTokenTable(x *xorm.Engine) error {
+	type GitOneTimeToken struct {
+		ID        int64  `xorm:"pk autoincr"`
+		RepoID    int64  `xorm:"INDEX NOT NULL"`
+		UID       int64  `xorm:"INDEX NOT NULL"`
+		Mode      int    `xorm:"NOT NULL"`
+		TokenHash string `xorm:"UNIQUE NOT NULL"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX"`
+		UsedUnix    timeutil.TimeStamp
+	}
+
+	return x.Sync2(new(GitOneTimeToken))
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createWebAuthnCredentialTable(x *xorm.Engine) error {
+	type WebAuthnCredential struct {
+		ID              int64 `xorm:"pk autoincr"`
+		Name            string
+		UserID          int64  `xorm:"INDEX"`
+		CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+		PublicKey       []byte
+		Algorithm       int64
+		AttestationType string
+		AAGUID          []byte
+		SignCount       uint32             `xorm:"BIGINT"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(WebAuthnCredential))
+}
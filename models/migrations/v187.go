@@ -0,0 +1,18 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addReadmeSettingsToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		ReadmePath        string `xorm:"TEXT"`
+		ShowReadmeOverlay bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	return x.Sync2(new(Repository))
+}
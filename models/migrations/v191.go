@@ -0,0 +1,26 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createUserSessionTable(x *xorm.Engine) error {
+	type UserSession struct {
+		ID        int64  `xorm:"pk autoincr"`
+		UID       int64  `xorm:"INDEX NOT NULL"`
+		KeyHash   string `xorm:"UNIQUE NOT NULL"`
+		UserAgent string
+		LastIP    string
+
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		LastSeenUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(UserSession))
+}
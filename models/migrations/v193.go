@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createCommitStatusAnnotationTable(x *xorm.Engine) error {
+	type CommitStatusAnnotation struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX(s) NOT NULL"`
+		SHA         string `xorm:"VARCHAR(64) INDEX(s) NOT NULL"`
+		ContextHash string `xorm:"char(40) index"`
+		Context     string `xorm:"TEXT"`
+		Path        string `xorm:"TEXT NOT NULL"`
+		Line        int64  `xorm:"NOT NULL"`
+		Level       string `xorm:"VARCHAR(7) NOT NULL"`
+		Message     string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(CommitStatusAnnotation))
+}
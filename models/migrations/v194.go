@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createAuditEventTable(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID          int64  `xorm:"pk autoincr"`
+		Action      string `xorm:"VARCHAR(50) INDEX NOT NULL"`
+		ActorID     int64  `xorm:"INDEX"`
+		ActorName   string `xorm:"INDEX"`
+		TargetType  string `xorm:"VARCHAR(50)"`
+		TargetID    int64
+		TargetName  string
+		Description string `xorm:"TEXT"`
+		IP          string `xorm:"VARCHAR(50)"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(AuditEvent))
+}
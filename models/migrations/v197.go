@@ -0,0 +1,21 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addSoftDeleteToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		IsDeleted   bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+		DeletedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+		DeletedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(Repository))
+}
@@ -0,0 +1,23 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createCommentVersionTable(x *xorm.Engine) error {
+	type CommentVersion struct {
+		ID          int64 `xorm:"pk autoincr"`
+		CommentID   int64 `xorm:"INDEX NOT NULL"`
+		EditorID    int64
+		Content     string             `xorm:"LONGTEXT"`
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(CommentVersion))
+}
@@ -0,0 +1,19 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addBranchNamingToPushPolicy(x *xorm.Engine) error {
+	type PushPolicy struct {
+		BranchNameMode       int    `xorm:"NOT NULL DEFAULT 0"`
+		BranchNamePatterns   string `xorm:"TEXT"`
+		BranchNameExemptions string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(PushPolicy))
+}
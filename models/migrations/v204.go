@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addOrganizationProjects(x *xorm.Engine) error {
+	type Project struct {
+		OwnerID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	type User struct {
+		MembersCanManageProjects bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync2(new(Project)); err != nil {
+		return err
+	}
+	return x.Sync2(new(User))
+}
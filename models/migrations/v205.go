@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addProjectBoardRules(x *xorm.Engine) error {
+	type ProjectBoardRule struct {
+		ID          int64 `xorm:"pk autoincr"`
+		ProjectID   int64 `xorm:"INDEX NOT NULL"`
+		BoardID     int64 `xorm:"INDEX NOT NULL"`
+		TriggerType uint8 `xorm:"NOT NULL"`
+		IsActive    bool  `xorm:"NOT NULL DEFAULT true"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type ProjectIssue struct {
+		MovedByTrigger uint8 `xorm:"NOT NULL DEFAULT 0"`
+		MovedUnix      timeutil.TimeStamp
+	}
+
+	if err := x.Sync2(new(ProjectBoardRule)); err != nil {
+		return err
+	}
+	return x.Sync2(new(ProjectIssue))
+}
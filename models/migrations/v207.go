@@ -0,0 +1,18 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addDefaultRepoVisibilityToUser(x *xorm.Engine) error {
+	type User struct {
+		DefaultRepoVisibility string `xorm:"NOT NULL DEFAULT ''"`
+		ForceRepoPrivate      bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	return x.Sync2(new(User))
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addRememberLookupKeyToUserSession(x *xorm.Engine) error {
+	type UserSession struct {
+		RememberLookupKey string `xorm:"INDEX"`
+	}
+
+	return x.Sync2(new(UserSession))
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addUsedByToGitOneTimeToken(x *xorm.Engine) error {
+	type GitOneTimeToken struct {
+		UsedBy string
+	}
+
+	return x.Sync2(new(GitOneTimeToken))
+}
@@ -82,6 +82,8 @@ func init() {
 		new(Action),
 		new(Issue),
 		new(PullRequest),
+		new(PullAutoMerge),
+		new(PullMergeQueue),
 		new(Comment),
 		new(Attachment),
 		new(Label),
@@ -97,6 +99,7 @@ func init() {
 		new(TeamUser),
 		new(TeamRepo),
 		new(Notice),
+		new(AuditEvent),
 		new(EmailAddress),
 		new(Notification),
 		new(IssueUser),
@@ -111,6 +114,7 @@ func init() {
 		new(UserOpenID),
 		new(IssueWatch),
 		new(CommitStatus),
+		new(CommitStatusAnnotation),
 		new(Stopwatch),
 		new(TrackedTime),
 		new(DeletedBranch),
@@ -120,6 +124,7 @@ func init() {
 		new(Reaction),
 		new(IssueAssignees),
 		new(U2FRegistration),
+		new(WebAuthnCredential),
 		new(TeamUnit),
 		new(Review),
 		new(OAuth2Application),
@@ -132,8 +137,18 @@ func init() {
 		new(Project),
 		new(ProjectBoard),
 		new(ProjectIssue),
+		new(ProjectBoardRule),
+		new(CommitComment),
 		new(Session),
 		new(RepoTransfer),
+		new(ProtectedTag),
+		new(PushPolicy),
+		new(OrgPushPolicy),
+		new(RememberToken),
+		new(RepoAccessToken),
+		new(GitOneTimeToken),
+		new(UserSession),
+		new(CommentVersion),
 	)
 
 	gonicNames := []string{"SSL", "UID"}
@@ -506,13 +506,26 @@ func getOAuth2GrantsByUserID(e Engine, uid int64) ([]*OAuth2Grant, error) {
 	return grants, nil
 }
 
-// RevokeOAuth2Grant deletes the grant with grantID and userID
+// RevokeOAuth2Grant deletes the grant with grantID and userID together with its authorization codes.
+// All access and refresh tokens derived from the grant become invalid.
 func RevokeOAuth2Grant(grantID, userID int64) error {
-	return revokeOAuth2Grant(x, grantID, userID)
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := revokeOAuth2Grant(sess, grantID, userID); err != nil {
+		return err
+	}
+	return sess.Commit()
 }
 
 func revokeOAuth2Grant(e Engine, grantID, userID int64) error {
-	_, err := e.Delete(&OAuth2Grant{ID: grantID, UserID: userID})
+	deleted, err := e.Delete(&OAuth2Grant{ID: grantID, UserID: userID})
+	if err != nil || deleted == 0 {
+		return err
+	}
+	_, err = e.Where("grant_id = ?", grantID).Delete(new(OAuth2AuthorizationCode))
 	return err
 }
 
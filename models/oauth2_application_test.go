@@ -160,8 +160,13 @@ func TestGetOAuth2GrantsByUserID(t *testing.T) {
 
 func TestRevokeOAuth2Grant(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
+	// grants of other users are kept
+	assert.NoError(t, RevokeOAuth2Grant(1, 2))
+	AssertExistsAndLoadBean(t, &OAuth2AuthorizationCode{GrantID: 1})
+
 	assert.NoError(t, RevokeOAuth2Grant(1, 1))
 	AssertNotExistsBean(t, &OAuth2Grant{ID: 1, UserID: 1})
+	AssertNotExistsBean(t, &OAuth2AuthorizationCode{GrantID: 1})
 }
 
 //////////////////// Authorization Code
@@ -34,6 +34,31 @@ func (org *User) CanCreateOrgRepo(uid int64) (bool, error) {
 	return CanCreateOrgRepo(org.ID, uid)
 }
 
+// IsTwoFactorMissingFor returns true if the organization requires two-factor authentication
+// and the given user is a member of it who has not enrolled yet
+func (org *User) IsTwoFactorMissingFor(uid int64) (bool, error) {
+	if !org.RequireTwoFactor {
+		return false, nil
+	}
+	if isMember, err := org.IsOrgMember(uid); err != nil || !isMember {
+		return false, err
+	}
+	has, err := x.Where("uid = ?", uid).Exist(new(TwoFactor))
+	return !has, err
+}
+
+// GetMembersWithoutTwoFactor returns the members of the organization who have not enrolled
+// two-factor authentication
+func (org *User) GetMembersWithoutTwoFactor() (UserList, error) {
+	users := make(UserList, 0, 10)
+	return users, x.
+		Join("INNER", "org_user", "org_user.uid = `user`.id").
+		Join("LEFT", "two_factor", "two_factor.uid = `user`.id").
+		Where("org_user.org_id = ? AND two_factor.id IS NULL", org.ID).
+		Asc("`user`.name").
+		Find(&users)
+}
+
 func (org *User) getTeam(e Engine, name string) (*Team, error) {
 	return getTeam(e, org.ID, name)
 }
@@ -296,10 +321,23 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		&OrgUser{OrgID: u.ID},
 		&TeamUser{OrgID: u.ID},
 		&TeamUnit{OrgID: u.ID},
+		&OrgPushPolicy{OrgID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	projects, _, err := getProjects(e, ProjectSearchOptions{
+		OwnerID: u.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("get projects: %v", err)
+	}
+	for i := range projects {
+		if err := deleteProjectByID(e, projects[i].ID); err != nil {
+			return fmt.Errorf("delete project [%d]: %v", projects[i].ID, err)
+		}
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -830,7 +868,7 @@ func (env *accessibleReposEnv) cond() builder.Cond {
 	if env.keyword != "" {
 		cond = cond.And(builder.Like{"`repository`.lower_name", strings.ToLower(env.keyword)})
 	}
-	return cond
+	return builder.And(cond, builder.Eq{"`repository`.is_deleted": false})
 }
 
 func (env *accessibleReposEnv) CountRepos() (int64, error) {
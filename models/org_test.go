@@ -55,6 +55,40 @@ func TestUser_IsOrgMember(t *testing.T) {
 	}
 }
 
+func TestUser_IsTwoFactorMissingFor(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+
+	missing, err := org.IsTwoFactorMissingFor(2)
+	assert.NoError(t, err)
+	assert.False(t, missing)
+
+	org.RequireTwoFactor = true
+	assert.NoError(t, UpdateUserCols(org, "require_two_factor"))
+	assert.NoError(t, AddOrgUser(org.ID, 24))
+
+	for _, testCase := range []struct {
+		UserID          int64
+		ExpectedMissing bool
+	}{
+		{2, true},
+		{24, false}, // has enrolled
+		{1, false},  // is no member
+	} {
+		missing, err := org.IsTwoFactorMissingFor(testCase.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.ExpectedMissing, missing)
+	}
+
+	members, err := org.GetMembersWithoutTwoFactor()
+	assert.NoError(t, err)
+	ids := make([]int64, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ID)
+	}
+	assert.ElementsMatch(t, []int64{2, 4, 28}, ids)
+}
+
 func TestUser_GetTeam(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
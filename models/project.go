@@ -7,7 +7,9 @@ package models
 import (
 	"errors"
 	"fmt"
+	"net/url"
 
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -43,6 +45,7 @@ type Project struct {
 	Title       string `xorm:"INDEX NOT NULL"`
 	Description string `xorm:"TEXT"`
 	RepoID      int64  `xorm:"INDEX"`
+	OwnerID     int64  `xorm:"INDEX NOT NULL DEFAULT 0"` // the organization of an organization project
 	CreatorID   int64  `xorm:"NOT NULL"`
 	IsClosed    bool   `xorm:"INDEX"`
 	BoardType   ProjectBoardType
@@ -67,16 +70,45 @@ func GetProjectsConfig() []ProjectsConfig {
 // IsProjectTypeValid checks if a project type is valid
 func IsProjectTypeValid(p ProjectType) bool {
 	switch p {
-	case ProjectTypeRepository:
+	case ProjectTypeRepository, ProjectTypeOrganization:
 		return true
 	default:
 		return false
 	}
 }
 
+// IsAssignableIn returns true if the issues of the repository can be assigned to the project,
+// i.e. it is a project of the repository or of the organization owning it
+func (p *Project) IsAssignableIn(repo *Repository) bool {
+	if p.Type == ProjectTypeOrganization {
+		return p.OwnerID == repo.OwnerID
+	}
+	return p.RepoID == repo.ID
+}
+
+// Link returns the link to the project, within its repository or its organization
+func (p *Project) Link() string {
+	if p.Type == ProjectTypeOrganization {
+		owner, err := GetUserByID(p.OwnerID)
+		if err != nil {
+			log.Error("GetUserByID[%d]: %v", p.OwnerID, err)
+			return ""
+		}
+		return fmt.Sprintf("%s/org/%s/projects/%d", setting.AppSubURL, url.PathEscape(owner.Name), p.ID)
+	}
+
+	repo, err := GetRepositoryByID(p.RepoID)
+	if err != nil {
+		log.Error("GetRepositoryByID[%d]: %v", p.RepoID, err)
+		return ""
+	}
+	return fmt.Sprintf("%s/projects/%d", repo.Link(), p.ID)
+}
+
 // ProjectSearchOptions are options for GetProjects
 type ProjectSearchOptions struct {
 	RepoID   int64
+	OwnerID  int64
 	Page     int
 	IsClosed util.OptionalBool
 	SortType string
@@ -91,18 +123,7 @@ func GetProjects(opts ProjectSearchOptions) ([]*Project, int64, error) {
 func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error) {
 	projects := make([]*Project, 0, setting.UI.IssuePagingNum)
 
-	var cond builder.Cond = builder.Eq{"repo_id": opts.RepoID}
-	switch opts.IsClosed {
-	case util.OptionalBoolTrue:
-		cond = cond.And(builder.Eq{"is_closed": true})
-	case util.OptionalBoolFalse:
-		cond = cond.And(builder.Eq{"is_closed": false})
-	}
-
-	if opts.Type > 0 {
-		cond = cond.And(builder.Eq{"type": opts.Type})
-	}
-
+	cond := opts.toCond()
 	count, err := e.Where(cond).Count(new(Project))
 	if err != nil {
 		return nil, 0, fmt.Errorf("Count: %v", err)
@@ -128,6 +149,31 @@ func getProjects(e Engine, opts ProjectSearchOptions) ([]*Project, int64, error)
 	return projects, count, e.Find(&projects)
 }
 
+// CountProjects returns the number of the projects matching the options
+func CountProjects(opts ProjectSearchOptions) (int64, error) {
+	return x.Where(opts.toCond()).Count(new(Project))
+}
+
+func (opts *ProjectSearchOptions) toCond() builder.Cond {
+	var cond builder.Cond
+	if opts.OwnerID > 0 {
+		cond = builder.Eq{"owner_id": opts.OwnerID}
+	} else {
+		cond = builder.Eq{"repo_id": opts.RepoID}
+	}
+	switch opts.IsClosed {
+	case util.OptionalBoolTrue:
+		cond = cond.And(builder.Eq{"is_closed": true})
+	case util.OptionalBoolFalse:
+		cond = cond.And(builder.Eq{"is_closed": false})
+	}
+
+	if opts.Type > 0 {
+		cond = cond.And(builder.Eq{"type": opts.Type})
+	}
+	return cond
+}
+
 // NewProject creates a new Project
 func NewProject(p *Project) error {
 	if !IsProjectBoardTypeValid(p.BoardType) {
@@ -149,8 +195,10 @@ func NewProject(p *Project) error {
 		return err
 	}
 
-	if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
-		return err
+	if p.Type == ProjectTypeRepository {
+		if _, err := sess.Exec("UPDATE `repository` SET num_projects = num_projects + 1 WHERE id = ?", p.RepoID); err != nil {
+			return err
+		}
 	}
 
 	if err := createBoardsForProjectsType(sess, p); err != nil {
@@ -259,7 +307,7 @@ func changeProjectStatus(e Engine, p *Project, isClosed bool) error {
 	if err != nil {
 		return err
 	}
-	if count < 1 {
+	if count < 1 || p.Type != ProjectTypeRepository {
 		return nil
 	}
 
@@ -298,9 +346,16 @@ func deleteProjectByID(e Engine, id int64) error {
 		return err
 	}
 
+	if err := deleteProjectBoardRulesByProjectID(e, id); err != nil {
+		return err
+	}
+
 	if _, err = e.ID(p.ID).Delete(new(Project)); err != nil {
 		return err
 	}
+	if p.Type != ProjectTypeRepository {
+		return nil
+	}
 
 	return updateRepositoryProjectCount(e, p.RepoID)
 }
@@ -44,7 +44,8 @@ type ProjectBoard struct {
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 
-	Issues []*Issue `xorm:"-"`
+	Issues []*Issue            `xorm:"-"`
+	Rules  []*ProjectBoardRule `xorm:"-"`
 }
 
 // IsProjectBoardTypeValid checks if the project board type is valid
@@ -128,6 +129,10 @@ func deleteProjectBoardByID(e Engine, boardID int64) error {
 		return err
 	}
 
+	if err = deleteProjectBoardRulesByBoardID(e, board.ID); err != nil {
+		return err
+	}
+
 	if _, err := e.ID(board.ID).Delete(board); err != nil {
 		return err
 	}
@@ -0,0 +1,225 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ProjectBoardTrigger is a status change of an issue or pull request moving its card automatically
+type ProjectBoardTrigger uint8
+
+const (
+	// ProjectBoardTriggerIssueClosed fires when an issue is closed
+	ProjectBoardTriggerIssueClosed ProjectBoardTrigger = iota + 1
+	// ProjectBoardTriggerIssueReopened fires when an issue is reopened
+	ProjectBoardTriggerIssueReopened
+	// ProjectBoardTriggerPullMerged fires when a pull request is merged
+	ProjectBoardTriggerPullMerged
+	// ProjectBoardTriggerPullClosed fires when a pull request is closed without being merged
+	ProjectBoardTriggerPullClosed
+	// ProjectBoardTriggerPullReopened fires when a pull request is reopened
+	ProjectBoardTriggerPullReopened
+)
+
+// ProjectBoardTriggers lists the triggers a project board rule can use
+var ProjectBoardTriggers = []ProjectBoardTrigger{
+	ProjectBoardTriggerIssueClosed,
+	ProjectBoardTriggerIssueReopened,
+	ProjectBoardTriggerPullMerged,
+	ProjectBoardTriggerPullClosed,
+	ProjectBoardTriggerPullReopened,
+}
+
+var projectBoardTriggerNames = map[ProjectBoardTrigger]string{
+	ProjectBoardTriggerIssueClosed:   "issue_closed",
+	ProjectBoardTriggerIssueReopened: "issue_reopened",
+	ProjectBoardTriggerPullMerged:    "pull_merged",
+	ProjectBoardTriggerPullClosed:    "pull_closed",
+	ProjectBoardTriggerPullReopened:  "pull_reopened",
+}
+
+// Name returns the name of the trigger, used in translation keys
+func (t ProjectBoardTrigger) Name() string {
+	return projectBoardTriggerNames[t]
+}
+
+// IsProjectBoardTriggerValid checks if the project board trigger is valid
+func IsProjectBoardTriggerValid(t ProjectBoardTrigger) bool {
+	_, ok := projectBoardTriggerNames[t]
+	return ok
+}
+
+// projectBoardTriggerOf returns the trigger fired by the current status of the issue
+func projectBoardTriggerOf(issue *Issue, isMergePull bool) ProjectBoardTrigger {
+	switch {
+	case issue.IsPull && isMergePull:
+		return ProjectBoardTriggerPullMerged
+	case issue.IsPull && issue.IsClosed:
+		return ProjectBoardTriggerPullClosed
+	case issue.IsPull:
+		return ProjectBoardTriggerPullReopened
+	case issue.IsClosed:
+		return ProjectBoardTriggerIssueClosed
+	default:
+		return ProjectBoardTriggerIssueReopened
+	}
+}
+
+// ProjectBoardRule moves the cards of a project to its board when its trigger fires.
+// A trigger is handled by at most one board of a project.
+type ProjectBoardRule struct {
+	ID          int64               `xorm:"pk autoincr"`
+	ProjectID   int64               `xorm:"INDEX NOT NULL"`
+	BoardID     int64               `xorm:"INDEX NOT NULL"`
+	TriggerType ProjectBoardTrigger `xorm:"NOT NULL"`
+	IsActive    bool                `xorm:"NOT NULL DEFAULT true"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func getProjectBoardRules(e Engine, boardID int64) ([]*ProjectBoardRule, error) {
+	rules := make([]*ProjectBoardRule, 0, len(ProjectBoardTriggers))
+	return rules, e.Where("board_id=?", boardID).OrderBy("trigger_type").Find(&rules)
+}
+
+// LoadRules loads the automation rules of the board
+func (b *ProjectBoard) LoadRules() error {
+	return b.loadRules(x)
+}
+
+func (b *ProjectBoard) loadRules(e Engine) (err error) {
+	if b.ID == 0 {
+		return nil
+	}
+	b.Rules, err = getProjectBoardRules(e, b.ID)
+	return err
+}
+
+// LoadRules loads the automation rules of the boards
+func (bs ProjectBoardList) LoadRules() error {
+	for i := range bs {
+		if err := bs[i].LoadRules(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTriggerActive returns whether the cards are moved to the board when the trigger fires
+func (b *ProjectBoard) IsTriggerActive(trigger ProjectBoardTrigger) bool {
+	for _, rule := range b.Rules {
+		if rule.TriggerType == trigger {
+			return rule.IsActive
+		}
+	}
+	return false
+}
+
+// UpdateProjectBoardRules activates the rules of the board for the given triggers and deactivates the others,
+// the rules of the other boards of the project using the same triggers are deactivated.
+func UpdateProjectBoardRules(board *ProjectBoard, triggers []ProjectBoardTrigger) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	active := make(map[ProjectBoardTrigger]bool, len(triggers))
+	for _, trigger := range triggers {
+		active[trigger] = true
+	}
+
+	rules, err := getProjectBoardRules(sess, board.ID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[ProjectBoardTrigger]*ProjectBoardRule, len(rules))
+	for _, rule := range rules {
+		existing[rule.TriggerType] = rule
+	}
+
+	for _, trigger := range ProjectBoardTriggers {
+		rule, has := existing[trigger]
+		if !active[trigger] {
+			if has && rule.IsActive {
+				rule.IsActive = false
+				if _, err := sess.ID(rule.ID).Cols("is_active").Update(rule); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if _, err := sess.Where(builder.Eq{
+			"project_id":   board.ProjectID,
+			"trigger_type": trigger,
+		}).And(builder.Neq{"board_id": board.ID}).
+			Cols("is_active").Update(&ProjectBoardRule{IsActive: false}); err != nil {
+			return err
+		}
+
+		if !has {
+			if _, err := sess.Insert(&ProjectBoardRule{
+				ProjectID:   board.ProjectID,
+				BoardID:     board.ID,
+				TriggerType: trigger,
+				IsActive:    true,
+			}); err != nil {
+				return err
+			}
+		} else if !rule.IsActive {
+			rule.IsActive = true
+			if _, err := sess.ID(rule.ID).Cols("is_active").Update(rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := board.loadRules(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// moveIssueByProjectBoardRules moves the card of the issue to the board
+// whose active rule handles the trigger fired by its status change
+func moveIssueByProjectBoardRules(e Engine, issue *Issue, isMergePull bool) error {
+	var pi ProjectIssue
+	has, err := e.Where("issue_id=?", issue.ID).Get(&pi)
+	if err != nil || !has {
+		return err
+	}
+
+	trigger := projectBoardTriggerOf(issue, isMergePull)
+	var rule ProjectBoardRule
+	has, err = e.Where(builder.Eq{
+		"project_id":   pi.ProjectID,
+		"trigger_type": trigger,
+		"is_active":    true,
+	}).Get(&rule)
+	if err != nil || !has || rule.BoardID == pi.ProjectBoardID {
+		return err
+	}
+
+	pi.ProjectBoardID = rule.BoardID
+	pi.MovedByTrigger = trigger
+	pi.MovedUnix = timeutil.TimeStampNow()
+	_, err = e.ID(pi.ID).Cols("project_board_id", "moved_by_trigger", "moved_unix").Update(&pi)
+	return err
+}
+
+func deleteProjectBoardRulesByBoardID(e Engine, boardID int64) error {
+	_, err := e.Where("board_id=?", boardID).Delete(&ProjectBoardRule{})
+	return err
+}
+
+func deleteProjectBoardRulesByProjectID(e Engine, projectID int64) error {
+	_, err := e.Where("project_id=?", projectID).Delete(&ProjectBoardRule{})
+	return err
+}
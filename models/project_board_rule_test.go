@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProjectBoardRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	done := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 3}).(*ProjectBoard)
+	assert.NoError(t, UpdateProjectBoardRules(done, []ProjectBoardTrigger{ProjectBoardTriggerIssueClosed, ProjectBoardTriggerPullMerged}))
+	assert.True(t, done.IsTriggerActive(ProjectBoardTriggerIssueClosed))
+	assert.True(t, done.IsTriggerActive(ProjectBoardTriggerPullMerged))
+	assert.False(t, done.IsTriggerActive(ProjectBoardTriggerIssueReopened))
+
+	// a trigger is handled by a single board of the project
+	inProgress := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 2}).(*ProjectBoard)
+	assert.NoError(t, UpdateProjectBoardRules(inProgress, []ProjectBoardTrigger{ProjectBoardTriggerIssueClosed}))
+	assert.True(t, inProgress.IsTriggerActive(ProjectBoardTriggerIssueClosed))
+	assert.NoError(t, done.LoadRules())
+	assert.False(t, done.IsTriggerActive(ProjectBoardTriggerIssueClosed))
+	assert.True(t, done.IsTriggerActive(ProjectBoardTriggerPullMerged))
+
+	// rules are toggled rather than removed
+	assert.NoError(t, UpdateProjectBoardRules(done, nil))
+	AssertExistsAndLoadBean(t, &ProjectBoardRule{BoardID: done.ID, TriggerType: ProjectBoardTriggerPullMerged, IsActive: false}, Cond("is_active = ?", false))
+
+	assert.NoError(t, DeleteProjectBoardByID(inProgress.ID))
+	AssertNotExistsBean(t, &ProjectBoardRule{BoardID: inProgress.ID})
+}
+
+func TestMoveIssueByProjectBoardRules(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	done := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 3}).(*ProjectBoard)
+	assert.NoError(t, UpdateProjectBoardRules(done, []ProjectBoardTrigger{ProjectBoardTriggerIssueClosed}))
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.EqualValues(t, 1, issue.ProjectBoardID())
+
+	_, err := issue.ChangeStatus(doer, true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, done.ID, issue.ProjectBoardID())
+	pi := AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: issue.ID}).(*ProjectIssue)
+	assert.Equal(t, ProjectBoardTriggerIssueClosed, pi.MovedByTrigger)
+
+	moved, err := GetAutomaticallyMovedProjectIssues(1)
+	assert.NoError(t, err)
+	assert.Contains(t, moved, issue.ID)
+
+	// without an active rule for the trigger the card stays where it is
+	_, err = issue.ChangeStatus(doer, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, done.ID, issue.ProjectBoardID())
+
+	// moving the card by hand clears the log of the rule
+	assert.NoError(t, MoveIssueAcrossProjectBoards(issue, &ProjectBoard{ID: 1}))
+	pi = AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: issue.ID}).(*ProjectIssue)
+	assert.EqualValues(t, 0, pi.MovedByTrigger)
+}
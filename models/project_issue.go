@@ -7,6 +7,8 @@ package models
 import (
 	"fmt"
 
+	"code.gitea.io/gitea/modules/timeutil"
+
 	"xorm.io/xorm"
 )
 
@@ -18,6 +20,10 @@ type ProjectIssue struct {
 
 	// If 0, then it has not been added to a specific board in the project
 	ProjectBoardID int64 `xorm:"INDEX"`
+
+	// The trigger of the rule which moved the card last, 0 if it was moved by hand
+	MovedByTrigger ProjectBoardTrigger `xorm:"NOT NULL DEFAULT 0"`
+	MovedUnix      timeutil.TimeStamp
 }
 
 func deleteProjectIssuesByProjectID(e Engine, projectID int64) error {
@@ -194,13 +200,28 @@ func MoveIssueAcrossProjectBoards(issue *Issue, board *ProjectBoard) error {
 	}
 
 	pis.ProjectBoardID = board.ID
-	if _, err := sess.ID(pis.ID).Cols("project_board_id").Update(&pis); err != nil {
+	pis.MovedByTrigger = 0
+	pis.MovedUnix = timeutil.TimeStampNow()
+	if _, err := sess.ID(pis.ID).Cols("project_board_id", "moved_by_trigger", "moved_unix").Update(&pis); err != nil {
 		return err
 	}
 
 	return sess.Commit()
 }
 
+// GetAutomaticallyMovedProjectIssues returns the cards of a project last moved by a board rule, indexed by issue
+func GetAutomaticallyMovedProjectIssues(projectID int64) (map[int64]*ProjectIssue, error) {
+	pis := make([]*ProjectIssue, 0, 10)
+	if err := x.Where("project_id=? AND moved_by_trigger>0", projectID).Find(&pis); err != nil {
+		return nil, err
+	}
+	moved := make(map[int64]*ProjectIssue, len(pis))
+	for _, pi := range pis {
+		moved[pi.IssueID] = pi
+	}
+	return moved, nil
+}
+
 func (pb *ProjectBoard) removeIssues(e Engine) error {
 	_, err := e.Exec("UPDATE `project_issue` SET project_board_id = 0 WHERE project_board_id = ? ", pb.ID)
 	return err
@@ -5,9 +5,12 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,7 +24,7 @@ func TestIsProjectTypeValid(t *testing.T) {
 	}{
 		{ProjectTypeIndividual, false},
 		{ProjectTypeRepository, true},
-		{ProjectTypeOrganization, false},
+		{ProjectTypeOrganization, true},
 		{UnknownType, false},
 	}
 
@@ -80,3 +83,43 @@ func TestProject(t *testing.T) {
 
 	assert.True(t, projectFromDB.IsClosed)
 }
+
+func TestOrganizationProject(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	project := &Project{
+		Type:      ProjectTypeOrganization,
+		BoardType: ProjectBoardTypeBasicKanban,
+		Title:     "Organization Project",
+		OwnerID:   3,
+		CreatorID: 2,
+	}
+	assert.NoError(t, NewProject(project))
+
+	projects, count, err := GetProjects(ProjectSearchOptions{OwnerID: 3, Type: ProjectTypeOrganization})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, projects, 1) {
+		assert.Equal(t, project.ID, projects[0].ID)
+	}
+
+	assert.NoError(t, ChangeProjectStatus(project, true))
+	count, err = CountProjects(ProjectSearchOptions{OwnerID: 3, IsClosed: util.OptionalBoolTrue, Type: ProjectTypeOrganization})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// the issues of all the repositories of the organization can be assigned to its projects
+	repo1 := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	repo3 := AssertExistsAndLoadBean(t, &Repository{ID: 3}).(*Repository)
+	assert.True(t, project.IsAssignableIn(repo3))
+	assert.False(t, project.IsAssignableIn(repo1))
+	assert.Equal(t, fmt.Sprintf("%s/org/user3/projects/%d", setting.AppSubURL, project.ID), project.Link())
+
+	repoProject := AssertExistsAndLoadBean(t, &Project{ID: 2}).(*Project)
+	assert.True(t, repoProject.IsAssignableIn(repo3))
+	assert.False(t, repoProject.IsAssignableIn(repo1))
+	assert.Equal(t, setting.AppSubURL+"/user3/repo3/projects/2", repoProject.Link())
+
+	assert.NoError(t, DeleteProjectByID(project.ID))
+	AssertNotExistsBean(t, &Project{ID: project.ID})
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUserAllowedToControlTag(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	protectedTags := []*ProtectedTag{
+		{
+			NamePattern:      "v-*",
+			AllowlistUserIDs: []int64{1},
+		},
+		{
+			NamePattern:      `/^v\d+\.\d+\.\d+$/`,
+			AllowlistTeamIDs: []int64{2},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		userID  int64
+		allowed bool
+	}{
+		{"unprotected", 1, true},
+		{"unprotected", 3, true},
+		{"v-1.0", 1, true},
+		{"v-1.0", 2, false},
+		{"v1.0.0", 1, false},
+		// user 4 is a member of team 2
+		{"v1.0.0", 4, true},
+		{"v1.0", 4, true},
+	}
+	for _, c := range cases {
+		allowed, err := IsUserAllowedToControlTag(protectedTags, c.name, c.userID)
+		assert.NoError(t, err)
+		assert.Equal(t, c.allowed, allowed, "tag %s / user %d", c.name, c.userID)
+	}
+}
+
+func TestProtectedTag_CRUD(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	pt := &ProtectedTag{
+		RepoID:           repo.ID,
+		NamePattern:      "release-*",
+		AllowlistUserIDs: []int64{2},
+	}
+	assert.NoError(t, InsertProtectedTag(pt))
+
+	tags, err := repo.GetProtectedTags()
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+
+	pt.NamePattern = "rel-*"
+	assert.NoError(t, UpdateProtectedTag(pt))
+	loaded, err := GetProtectedTagByID(pt.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "rel-*", loaded.NamePattern)
+	assert.Equal(t, []int64{2}, loaded.AllowlistUserIDs)
+
+	assert.NoError(t, DeleteProtectedTag(pt))
+	tags, err = repo.GetProtectedTags()
+	assert.NoError(t, err)
+	assert.Len(t, tags, 0)
+}
@@ -38,6 +38,23 @@ const (
 	PullRequestStatusEmpty
 )
 
+// Name returns the name of the status, as reported by the API and the webhooks
+func (status PullRequestStatus) Name() string {
+	switch status {
+	case PullRequestStatusConflict:
+		return "conflict"
+	case PullRequestStatusChecking:
+		return "checking"
+	case PullRequestStatusMergeable:
+		return "mergeable"
+	case PullRequestStatusManuallyMerged:
+		return "manually_merged"
+	case PullRequestStatusEmpty:
+		return "empty"
+	}
+	return "error"
+}
+
 // PullRequest represents relation between pull request and repositories.
 type PullRequest struct {
 	ID              int64 `xorm:"pk autoincr"`
@@ -0,0 +1,128 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// PullAutoMerge represents a pull request scheduled to be merged as soon as all checks and reviews pass
+type PullAutoMerge struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"VARCHAR(30)"`
+	Message     string             `xorm:"LONGTEXT"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// LoadDoer loads the user who scheduled the merge
+func (pam *PullAutoMerge) LoadDoer() (err error) {
+	if pam.Doer != nil {
+		return nil
+	}
+	pam.Doer, err = getUserByID(x, pam.DoerID)
+	return err
+}
+
+// ScheduleAutoMerge schedules the pull request to be merged by doer with the given style and message
+// as soon as it is ready, and adds a comment about it to the pull request
+func ScheduleAutoMerge(doer *User, pr *PullRequest, style MergeStyle, message string) (*Comment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if exist, err := sess.Exist(&PullAutoMerge{PullID: pr.ID}); err != nil {
+		return nil, err
+	} else if exist {
+		return nil, ErrPullAlreadyScheduledToAutoMerge{PullID: pr.ID}
+	}
+
+	if _, err := sess.Insert(&PullAutoMerge{
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	}); err != nil {
+		return nil, err
+	}
+
+	comment, err := createAutoMergeComment(sess, CommentTypePRScheduledToAutoMerge, doer, pr, "")
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
+
+// UnscheduleAutoMerge cancels the automatic merge of the pull request, if it is scheduled, and adds a
+// comment with the reason about it to the pull request. It returns nil if the merge was not scheduled.
+func UnscheduleAutoMerge(doer *User, pr *PullRequest, reason string) (*Comment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if deleted, err := sess.Delete(&PullAutoMerge{PullID: pr.ID}); err != nil || deleted == 0 {
+		return nil, err
+	}
+
+	comment, err := createAutoMergeComment(sess, CommentTypePRUnScheduledToAutoMerge, doer, pr, reason)
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
+
+func createAutoMergeComment(e *xorm.Session, typ CommentType, doer *User, pr *PullRequest, content string) (*Comment, error) {
+	if err := pr.loadIssue(e); err != nil {
+		return nil, err
+	}
+	if err := pr.loadBaseRepo(e); err != nil {
+		return nil, err
+	}
+	return createComment(e, &CreateCommentOptions{
+		Type:    typ,
+		Doer:    doer,
+		Repo:    pr.BaseRepo,
+		Issue:   pr.Issue,
+		Content: content,
+	})
+}
+
+// DeleteScheduledAutoMerge removes the schedule of the automatic merge of a pull request without a comment,
+// e.g. once it has been merged
+func DeleteScheduledAutoMerge(pullID int64) error {
+	_, err := x.Delete(&PullAutoMerge{PullID: pullID})
+	return err
+}
+
+// GetScheduledMergeByPullID returns the scheduled automatic merge of a pull request, if there is one
+func GetScheduledMergeByPullID(pullID int64) (bool, *PullAutoMerge, error) {
+	scheduled := &PullAutoMerge{}
+	exist, err := x.Where("pull_id = ?", pullID).Get(scheduled)
+	if err != nil || !exist {
+		return false, nil, err
+	}
+	return true, scheduled, scheduled.LoadDoer()
+}
+
+// GetScheduledMergePullIDs returns the ids of the unmerged pull requests into the repository which are
+// scheduled to be merged automatically, limited to the pull requests into baseBranch if it is not empty
+func GetScheduledMergePullIDs(baseRepoID int64, baseBranch string) ([]int64, error) {
+	sess := x.Table("pull_auto_merge").
+		Join("INNER", "pull_request", "pull_request.id = pull_auto_merge.pull_id").
+		Where("pull_request.base_repo_id = ? AND pull_request.has_merged = ?", baseRepoID, false)
+	if baseBranch != "" {
+		sess = sess.And("pull_request.base_branch = ?", baseBranch)
+	}
+	ids := make([]int64, 0, 10)
+	return ids, sess.Cols("pull_auto_merge.pull_id").Find(&ids)
+}
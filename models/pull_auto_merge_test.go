@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleAutoMerge(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	comment, err := ScheduleAutoMerge(doer, pr, MergeStyleSquash, "message")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypePRScheduledToAutoMerge, comment.Type)
+	assert.EqualValues(t, pr.IssueID, comment.IssueID)
+
+	_, err = ScheduleAutoMerge(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrPullAlreadyScheduledToAutoMerge(err))
+
+	exist, scheduled, err := GetScheduledMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, MergeStyleSquash, scheduled.MergeStyle)
+	assert.Equal(t, "message", scheduled.Message)
+	assert.EqualValues(t, doer.ID, scheduled.Doer.ID)
+
+	ids, err := GetScheduledMergePullIDs(pr.BaseRepoID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pr.ID}, ids)
+	ids, err = GetScheduledMergePullIDs(pr.BaseRepoID, pr.BaseBranch)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pr.ID}, ids)
+	ids, err = GetScheduledMergePullIDs(pr.BaseRepoID, "other")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	comment, err = UnscheduleAutoMerge(doer, pr, "checks_failed")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypePRUnScheduledToAutoMerge, comment.Type)
+	assert.Equal(t, "checks_failed", comment.Content)
+
+	exist, _, err = GetScheduledMergeByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// not scheduled anymore
+	comment, err = UnscheduleAutoMerge(doer, pr, "")
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+}
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/xorm"
 )
@@ -68,6 +69,17 @@ func GetUnmergedPullRequestsByBaseInfo(repoID int64, branch string) ([]*PullRequ
 		Find(&prs)
 }
 
+// GetUnmergedPullRequestsNotUpdatedSince returns all pull requests that are open and has not been merged
+// and which have not been updated since the given time.
+func GetUnmergedPullRequestsNotUpdatedSince(updatedBefore timeutil.TimeStamp) ([]*PullRequest, error) {
+	prs := make([]*PullRequest, 0, 10)
+	return prs, x.
+		Where("has_merged=? AND issue.is_closed=? AND issue.updated_unix<?",
+			false, false, updatedBefore).
+		Join("INNER", "issue", "issue.id=pull_request.issue_id").
+		Find(&prs)
+}
+
 // GetPullRequestIDsByCheckStatus returns all pull requests according the special checking status.
 func GetPullRequestIDsByCheckStatus(status PullRequestStatus) ([]int64, error) {
 	prs := make([]int64, 0, 10)
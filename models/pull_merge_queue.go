@@ -0,0 +1,153 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueStatus represents the state of a pull request in the merge queue of its base branch
+type MergeQueueStatus int
+
+const (
+	// MergeQueueStatusWaiting the pull requests queued before have to be merged first
+	MergeQueueStatusWaiting MergeQueueStatus = iota
+	// MergeQueueStatusTesting the pull request is the next one to be merged and waits for the required
+	// status checks of its head branch, updated with the latest base branch
+	MergeQueueStatusTesting
+)
+
+// PullMergeQueue represents a pull request queued to be merged into a protected branch after the pull
+// requests queued before it
+type PullMergeQueue struct {
+	ID          int64              `xorm:"pk autoincr"`
+	PullID      int64              `xorm:"UNIQUE"`
+	DoerID      int64              `xorm:"NOT NULL"`
+	Doer        *User              `xorm:"-"`
+	MergeStyle  MergeStyle         `xorm:"VARCHAR(30)"`
+	Message     string             `xorm:"LONGTEXT"`
+	Status      MergeQueueStatus   `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// IsTesting returns true if the pull request is the next one to be merged
+func (entry *PullMergeQueue) IsTesting() bool {
+	return entry.Status == MergeQueueStatusTesting
+}
+
+// LoadDoer loads the user who added the pull request to the queue
+func (entry *PullMergeQueue) LoadDoer() (err error) {
+	if entry.Doer != nil {
+		return nil
+	}
+	entry.Doer, err = getUserByID(x, entry.DoerID)
+	return err
+}
+
+// MergeQueueBranch represents a branch with a non empty merge queue
+type MergeQueueBranch struct {
+	BaseRepoID int64
+	BaseBranch string
+}
+
+// AddToMergeQueue adds the pull request at the end of the merge queue of its base branch, to be merged
+// by doer with the given style and message, and adds a comment about it to the pull request
+func AddToMergeQueue(doer *User, pr *PullRequest, style MergeStyle, message string) (*Comment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if exist, err := sess.Exist(&PullMergeQueue{PullID: pr.ID}); err != nil {
+		return nil, err
+	} else if exist {
+		return nil, ErrPullAlreadyInMergeQueue{PullID: pr.ID}
+	}
+
+	if _, err := sess.Insert(&PullMergeQueue{
+		PullID:     pr.ID,
+		DoerID:     doer.ID,
+		MergeStyle: style,
+		Message:    message,
+	}); err != nil {
+		return nil, err
+	}
+
+	comment, err := createAutoMergeComment(sess, CommentTypePRAddedToMergeQueue, doer, pr, "")
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue, if it is queued, and adds a
+// comment with the reason about it to the pull request. It returns nil if the pull request was not queued.
+func RemoveFromMergeQueue(doer *User, pr *PullRequest, reason string) (*Comment, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if deleted, err := sess.Delete(&PullMergeQueue{PullID: pr.ID}); err != nil || deleted == 0 {
+		return nil, err
+	}
+
+	comment, err := createAutoMergeComment(sess, CommentTypePRRemovedFromMergeQueue, doer, pr, reason)
+	if err != nil {
+		return nil, err
+	}
+	return comment, sess.Commit()
+}
+
+// DeleteMergeQueueEntry removes a pull request from the merge queue without a comment, e.g. once it
+// has been merged
+func DeleteMergeQueueEntry(pullID int64) error {
+	_, err := x.Delete(&PullMergeQueue{PullID: pullID})
+	return err
+}
+
+// UpdateMergeQueueStatus updates the status of a queued pull request
+func UpdateMergeQueueStatus(entry *PullMergeQueue) error {
+	_, err := x.ID(entry.ID).Cols("status").Update(entry)
+	return err
+}
+
+// GetMergeQueueEntryByPullID returns the merge queue entry of a pull request, if it is queued
+func GetMergeQueueEntryByPullID(pullID int64) (bool, *PullMergeQueue, error) {
+	entry := &PullMergeQueue{}
+	exist, err := x.Where("pull_id = ?", pullID).Get(entry)
+	if err != nil || !exist {
+		return false, nil, err
+	}
+	return true, entry, entry.LoadDoer()
+}
+
+// GetMergeQueue returns the entries of the unmerged pull requests into the branch of the repository
+// in the order they have been queued
+func GetMergeQueue(baseRepoID int64, baseBranch string) ([]*PullMergeQueue, error) {
+	entries := make([]*PullMergeQueue, 0, 5)
+	return entries, x.Table("pull_merge_queue").
+		Join("INNER", "pull_request", "pull_request.id = pull_merge_queue.pull_id").
+		Where("pull_request.base_repo_id = ? AND pull_request.base_branch = ? AND pull_request.has_merged = ?", baseRepoID, baseBranch, false).
+		Asc("pull_merge_queue.id").
+		Select("pull_merge_queue.*").
+		Find(&entries)
+}
+
+// GetMergeQueueBranches returns the branches with queued pull requests into them, limited to the
+// branches of the repository if baseRepoID is not 0
+func GetMergeQueueBranches(baseRepoID int64) ([]*MergeQueueBranch, error) {
+	sess := x.Table("pull_merge_queue").
+		Join("INNER", "pull_request", "pull_request.id = pull_merge_queue.pull_id").
+		Where("pull_request.has_merged = ?", false)
+	if baseRepoID != 0 {
+		sess = sess.And("pull_request.base_repo_id = ?", baseRepoID)
+	}
+	branches := make([]*MergeQueueBranch, 0, 5)
+	return branches, sess.Distinct("pull_request.base_repo_id", "pull_request.base_branch").Find(&branches)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+	merged := AssertExistsAndLoadBean(t, &PullRequest{ID: 1, HasMerged: true}).(*PullRequest)
+	other := AssertExistsAndLoadBean(t, &PullRequest{ID: 5}).(*PullRequest)
+
+	comment, err := AddToMergeQueue(doer, pr, MergeStyleSquash, "message")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypePRAddedToMergeQueue, comment.Type)
+	assert.EqualValues(t, pr.IssueID, comment.IssueID)
+	_, err = AddToMergeQueue(doer, merged, MergeStyleMerge, "")
+	assert.NoError(t, err)
+	_, err = AddToMergeQueue(doer, other, MergeStyleMerge, "")
+	assert.NoError(t, err)
+
+	_, err = AddToMergeQueue(doer, pr, MergeStyleMerge, "")
+	assert.True(t, IsErrPullAlreadyInMergeQueue(err))
+
+	exist, entry, err := GetMergeQueueEntryByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, MergeStyleSquash, entry.MergeStyle)
+	assert.Equal(t, "message", entry.Message)
+	assert.EqualValues(t, doer.ID, entry.Doer.ID)
+	assert.False(t, entry.IsTesting())
+
+	entry.Status = MergeQueueStatusTesting
+	assert.NoError(t, UpdateMergeQueueStatus(entry))
+
+	queue, err := GetMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	assert.NoError(t, err)
+	// the merged pull request is ignored
+	if assert.Len(t, queue, 1) {
+		assert.EqualValues(t, pr.ID, queue[0].PullID)
+		assert.True(t, queue[0].IsTesting())
+	}
+	queue, err = GetMergeQueue(other.BaseRepoID, other.BaseBranch)
+	assert.NoError(t, err)
+	if assert.Len(t, queue, 1) {
+		assert.EqualValues(t, other.ID, queue[0].PullID)
+	}
+	queue, err = GetMergeQueue(pr.BaseRepoID, "other")
+	assert.NoError(t, err)
+	assert.Empty(t, queue)
+
+	branches, err := GetMergeQueueBranches(pr.BaseRepoID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*MergeQueueBranch{
+		{BaseRepoID: pr.BaseRepoID, BaseBranch: pr.BaseBranch},
+		{BaseRepoID: other.BaseRepoID, BaseBranch: other.BaseBranch},
+	}, branches)
+	branches, err = GetMergeQueueBranches(2)
+	assert.NoError(t, err)
+	assert.Empty(t, branches)
+
+	comment, err = RemoveFromMergeQueue(doer, pr, "checks_failed")
+	assert.NoError(t, err)
+	assert.Equal(t, CommentTypePRRemovedFromMergeQueue, comment.Type)
+	assert.Equal(t, "checks_failed", comment.Content)
+
+	exist, _, err = GetMergeQueueEntryByPullID(pr.ID)
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	// not queued anymore
+	comment, err = RemoveFromMergeQueue(doer, pr, "")
+	assert.NoError(t, err)
+	assert.Nil(t, comment)
+
+	assert.NoError(t, DeleteMergeQueueEntry(other.ID))
+	branches, err = GetMergeQueueBranches(0)
+	assert.NoError(t, err)
+	assert.Empty(t, branches)
+}
@@ -234,3 +234,12 @@ func TestPullRequest_GetWorkInProgressPrefixWorkInProgress(t *testing.T) {
 	pr.Issue.Title = "[wip] " + original
 	assert.Equal(t, "[wip]", pr.GetWorkInProgressPrefix())
 }
+
+func TestPullRequestStatus_Name(t *testing.T) {
+	assert.Equal(t, "conflict", PullRequestStatusConflict.Name())
+	assert.Equal(t, "checking", PullRequestStatusChecking.Name())
+	assert.Equal(t, "mergeable", PullRequestStatusMergeable.Name())
+	assert.Equal(t, "manually_merged", PullRequestStatusManuallyMerged.Name())
+	assert.Equal(t, "error", PullRequestStatusError.Name())
+	assert.Equal(t, "empty", PullRequestStatusEmpty.Name())
+}
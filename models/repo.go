@@ -236,15 +236,35 @@ type Repository struct {
 	Size                            int64              `xorm:"NOT NULL DEFAULT 0"`
 	CodeIndexerStatus               *RepoIndexerStatus `xorm:"-"`
 	StatsIndexerStatus              *RepoIndexerStatus `xorm:"-"`
+	WikiIndexerStatus               *RepoIndexerStatus `xorm:"-"`
 	IsFsckEnabled                   bool               `xorm:"NOT NULL DEFAULT true"`
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
 	Topics                          []string           `xorm:"TEXT JSON"`
 
 	TrustModel TrustModelType
 
+	// ReadmePath is the README rendered on the home view instead of the one found by the standard search order
+	ReadmePath string `xorm:"TEXT"`
+	// ShowReadmeOverlay renders the README of the .gitea directory on the home view as well
+	ShowReadmeOverlay bool `xorm:"NOT NULL DEFAULT false"`
+	// RequireGitAuth refuses the anonymous git operations over HTTP on a public repository,
+	// which can still be browsed anonymously on the web
+	RequireGitAuth bool `xorm:"NOT NULL DEFAULT false"`
+	// IssueIndexOffset is the lowest index new issues and pull requests are numbered above,
+	// e.g. to continue the numbering of an imported issue tracker
+	IssueIndexOffset int64 `xorm:"NOT NULL DEFAULT 0"`
+	// AllowedReactions restricts the reactions of issues and comments to a subset of
+	// the reactions allowed by the instance, all of them are allowed if empty
+	AllowedReactions []string `xorm:"TEXT JSON"`
+
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
 	Avatar string `xorm:"VARCHAR(64)"`
 
+	// IsDeleted hides a repository which is kept until it is purged or restored, its name stays reserved
+	IsDeleted   bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	DeletedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+	DeletedUnix timeutil.TimeStamp `xorm:"INDEX"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
@@ -416,6 +436,11 @@ func (repo *Repository) MustGetUnit(tp UnitType) *RepoUnit {
 			Type:   tp,
 			Config: new(IssuesConfig),
 		}
+	} else if tp == UnitTypeWiki {
+		return &RepoUnit{
+			Type:   tp,
+			Config: new(WikiConfig),
+		}
 	}
 	return &RepoUnit{
 		Type:   tp,
@@ -677,6 +702,28 @@ func (repo *Repository) IsGenerated() bool {
 	return repo.TemplateID != 0
 }
 
+// GetAllowedReactions returns the reactions which can be added to issues and comments of the repository
+func (repo *Repository) GetAllowedReactions() []string {
+	if len(repo.AllowedReactions) == 0 {
+		return setting.UI.Reactions
+	}
+	reactions := make([]string, 0, len(repo.AllowedReactions))
+	for _, reaction := range repo.AllowedReactions {
+		if setting.UI.ReactionsMap[reaction] {
+			reactions = append(reactions, reaction)
+		}
+	}
+	return reactions
+}
+
+// IsReactionAllowed returns true if the reaction can be added to issues and comments of the repository
+func (repo *Repository) IsReactionAllowed(reaction string) bool {
+	if !setting.UI.ReactionsMap[reaction] {
+		return false
+	}
+	return len(repo.AllowedReactions) == 0 || util.IsStringInSlice(reaction, repo.AllowedReactions)
+}
+
 // GetTemplateRepo populates repo.TemplateRepo for a generated repository and
 // returns an error on failure (NOTE: no error is returned for
 // non-generated repositories, and TemplateRepo will be left untouched)
@@ -946,8 +993,8 @@ func (repo *Repository) CloneLink() (cl *CloneLink) {
 
 // CheckCreateRepository check if could created a repository
 func CheckCreateRepository(doer, u *User, name string, overwriteOrAdopt bool) error {
-	if !doer.CanCreateRepo() {
-		return ErrReachLimitOfRepo{u.MaxRepoCreation}
+	if !doer.IsAdmin && !u.CanCreateRepo() {
+		return ErrReachLimitOfRepo{u.MaxCreationLimit()}
 	}
 
 	if err := IsUsableRepoName(name); err != nil {
@@ -1467,10 +1514,16 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&HookTask{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&CommitStatus{RepoID: repoID},
+		&CommitStatusAnnotation{RepoID: repoID},
+		&CommitComment{RepoID: repoID},
 		&RepoIndexerStatus{RepoID: repoID},
 		&LanguageStat{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&Task{RepoID: repoID},
+		&ProtectedTag{RepoID: repoID},
+		&PushPolicy{RepoID: repoID},
+		&RepoAccessToken{RepoID: repoID},
+		&GitOneTimeToken{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1575,6 +1628,18 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		}
 	}
 
+	// Remove cached archives.
+	archivePrefix := fmt.Sprintf("%d/", repo.ID)
+	archives, err := listRepoArchives(context.Background())
+	if err != nil {
+		log.Error("Unable to list repository archives: %v", err)
+	}
+	for _, archive := range archives {
+		if strings.HasPrefix(archive.path, archivePrefix) {
+			RemoveStorageWithNotice(storage.RepoArchives, "Delete repository archive", archive.path)
+		}
+	}
+
 	return nil
 }
 
@@ -1589,6 +1654,7 @@ func getRepositoryByOwnerAndName(e Engine, ownerName, repoName string) (*Reposit
 		Join("INNER", "`user`", "`user`.id = repository.owner_id").
 		Where("repository.lower_name = ?", strings.ToLower(repoName)).
 		And("`user`.lower_name = ?", strings.ToLower(ownerName)).
+		And("repository.is_deleted = ?", false).
 		Get(&repo)
 	if err != nil {
 		return nil, err
@@ -1604,7 +1670,7 @@ func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
 		OwnerID:   ownerID,
 		LowerName: strings.ToLower(name),
 	}
-	has, err := x.Get(repo)
+	has, err := x.Where("is_deleted = ?", false).Get(repo)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -1624,9 +1690,15 @@ func getRepositoryByID(e Engine, id int64) (*Repository, error) {
 	return repo, nil
 }
 
-// GetRepositoryByID returns the repository by given id if exists.
+// GetRepositoryByID returns the repository by given id if exists and is not pending deletion.
 func GetRepositoryByID(id int64) (*Repository, error) {
-	return getRepositoryByID(x, id)
+	repo, err := getRepositoryByID(x, id)
+	if err != nil {
+		return nil, err
+	} else if repo.IsDeleted {
+		return nil, ErrRepoNotExist{id, 0, "", ""}
+	}
+	return repo, nil
 }
 
 // GetRepositoryByIDCtx returns the repository by given id if exists.
@@ -1647,7 +1719,7 @@ func GetUserRepositories(opts *SearchRepoOptions) ([]*Repository, int64, error)
 	}
 
 	cond := builder.NewCond()
-	cond = cond.And(builder.Eq{"owner_id": opts.Actor.ID})
+	cond = cond.And(builder.Eq{"owner_id": opts.Actor.ID}, builder.Eq{"is_deleted": false})
 	if !opts.Private {
 		cond = cond.And(builder.Eq{"is_private": false})
 	}
@@ -1707,7 +1779,8 @@ func GetPrivateRepositoryCount(u *User) (int64, error) {
 
 // DeleteRepositoryArchives deletes all repositories' archives.
 func DeleteRepositoryArchives(ctx context.Context) error {
-	return x.
+	// Archives used to be stored inside the repositories themselves.
+	if err := x.
 		Where("id > 0").
 		Iterate(new(Repository),
 			func(idx int, bean interface{}) error {
@@ -1718,7 +1791,101 @@ func DeleteRepositoryArchives(ctx context.Context) error {
 				default:
 				}
 				return util.RemoveAll(filepath.Join(repo.RepoPath(), "archives"))
-			})
+			}); err != nil {
+		return err
+	}
+
+	archives, err := listRepoArchives(ctx)
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before deleting repository archive %s", archive.path)
+		default:
+		}
+		if err := storage.RepoArchives.Delete(archive.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type repoArchiveInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// listRepoArchives lists the archives in the archive storage, oldest first.
+func listRepoArchives(ctx context.Context) ([]repoArchiveInfo, error) {
+	var archives []repoArchiveInfo
+	if err := storage.RepoArchives.IterateObjects(func(p string, obj storage.Object) error {
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("while listing repository archives")
+		default:
+		}
+		p = filepath.ToSlash(p)
+		if strings.HasPrefix(p, "tmp/") {
+			// Uploads in progress to the local storage.
+			return nil
+		}
+		fi, err := obj.Stat()
+		if err != nil {
+			return err
+		}
+		archives = append(archives, repoArchiveInfo{
+			path:    p,
+			size:    fi.Size(),
+			modTime: fi.ModTime(),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].modTime.Before(archives[j].modTime)
+	})
+	return archives, nil
+}
+
+// deleteOldRepoArchivesFromStorage removes the cached archives older than
+// olderThan and then the oldest remaining ones until the cache fits within
+// the configured size limit.
+func deleteOldRepoArchivesFromStorage(ctx context.Context, olderThan time.Duration) error {
+	archives, err := listRepoArchives(ctx)
+	if err != nil {
+		return err
+	}
+
+	var totalSize int64
+	for _, archive := range archives {
+		totalSize += archive.size
+	}
+
+	minimumOldestTime := time.Now().Add(-olderThan)
+	for _, archive := range archives {
+		expired := archive.modTime.Before(minimumOldestTime)
+		oversized := setting.RepoArchive.MaxCacheSize > 0 && totalSize > setting.RepoArchive.MaxCacheSize
+		if !expired && !oversized {
+			// Archives are sorted oldest first, so the rest are kept too.
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ErrCancelledf("before deleting old repository archive %s", archive.path)
+		default:
+		}
+		// This is a best-effort purge, so we do not check error codes to confirm removal.
+		if err := storage.RepoArchives.Delete(archive.path); err != nil {
+			log.Trace("Unable to delete %s, but proceeding: %v", archive.path, err)
+			continue
+		}
+		totalSize -= archive.size
+	}
+	return nil
 }
 
 // DeleteOldRepositoryArchives deletes old repository archives.
@@ -1732,6 +1899,11 @@ func DeleteOldRepositoryArchives(ctx context.Context, olderThan time.Duration) e
 		return err
 	}
 
+	if err := deleteOldRepoArchivesFromStorage(ctx, olderThan); err != nil {
+		log.Trace("Error: ArchiveClean: %v", err)
+		return err
+	}
+
 	log.Trace("Finished: ArchiveCleanup")
 	return nil
 }
@@ -1967,6 +2139,74 @@ func HasForkedRepo(ownerID, repoID int64) (*Repository, bool) {
 	return repo, has
 }
 
+// maxForkNetworkDepth limits the number of forks of forks followed in a fork network
+const maxForkNetworkDepth = 10
+
+// GetForkNetworkRootID returns the id of the repository all the repositories of the fork network of
+// the repository have been forked from, directly or not
+func GetForkNetworkRootID(repo *Repository) (int64, error) {
+	rootID, forkID := repo.ID, repo.ForkID
+	for depth := 0; forkID != 0 && depth < maxForkNetworkDepth; depth++ {
+		parent := new(Repository)
+		has, err := x.ID(forkID).Cols("id", "fork_id").Get(parent)
+		if err != nil {
+			return 0, err
+		} else if !has {
+			break
+		}
+		rootID, forkID = parent.ID, parent.ForkID
+	}
+	return rootID, nil
+}
+
+// IsInSameForkNetwork returns true if both repositories have been forked from the same repository,
+// directly or not, or if one has been forked from the other
+func IsInSameForkNetwork(repo, other *Repository) (bool, error) {
+	if repo.ID == other.ID {
+		return true, nil
+	}
+	rootID, err := GetForkNetworkRootID(repo)
+	if err != nil {
+		return false, err
+	}
+	otherRootID, err := GetForkNetworkRootID(other)
+	if err != nil {
+		return false, err
+	}
+	return rootID == otherRootID, nil
+}
+
+// FindRepoInForkNetwork returns the repository owned by ownerID in the fork network of the repository,
+// other than the repository itself, like a fork of a fork. The repository closest to the root of the
+// network is returned if the owner has several.
+func FindRepoInForkNetwork(ownerID int64, repo *Repository) (*Repository, bool, error) {
+	rootID, err := GetForkNetworkRootID(repo)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids := []int64{rootID}
+	for depth := 0; len(ids) > 0 && depth <= maxForkNetworkDepth; depth++ {
+		found := new(Repository)
+		has, err := x.In("id", ids).
+			And("owner_id = ? AND id <> ? AND is_deleted = ?", ownerID, repo.ID, false).
+			Asc("id").
+			Get(found)
+		if err != nil {
+			return nil, false, err
+		} else if has {
+			return found, true, nil
+		}
+
+		forkIDs := make([]int64, 0, len(ids))
+		if err := x.Table("repository").In("fork_id", ids).Cols("id").Find(&forkIDs); err != nil {
+			return nil, false, err
+		}
+		ids = forkIDs
+	}
+	return nil, false, nil
+}
+
 // CopyLFS copies LFS data from one repo to another
 func CopyLFS(ctx DBContext, newRepo, oldRepo *Repository) error {
 	var lfsObjects []*LFSMetaObject
@@ -2093,6 +2333,12 @@ func DoctorUserStarNum() (err error) {
 	return
 }
 
+// GetRepositoriesAfterID returns at most limit repositories whose ids are greater than afterID, ordered by id
+func GetRepositoriesAfterID(afterID int64, limit int) ([]*Repository, error) {
+	repos := make([]*Repository, 0, limit)
+	return repos, x.Where("id > ?", afterID).Asc("id").Limit(limit).Find(&repos)
+}
+
 // IterateRepository iterate repositories
 func IterateRepository(f func(repo *Repository) error) error {
 	var start int
@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+)
+
+// RepoAccessToken represents a read-only access token scoped to a single repository.
+// It is not bound to any user and can be used to clone the repository over HTTP
+// and to read it through the API.
+type RepoAccessToken struct {
+	ID             int64 `xorm:"pk autoincr"`
+	RepoID         int64 `xorm:"INDEX"`
+	Name           string
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+
+	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
+	LastUsedUnix      timeutil.TimeStamp
+	HasRecentActivity bool `xorm:"-"`
+	HasUsed           bool `xorm:"-"`
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (t *RepoAccessToken) AfterLoad() {
+	t.HasUsed = t.LastUsedUnix > 0
+	t.HasRecentActivity = t.LastUsedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
+}
+
+// NewRepoAccessToken creates new repository access token.
+func NewRepoAccessToken(t *RepoAccessToken) error {
+	salt, err := generate.GetRandomString(10)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = base.EncodeSha1(gouuid.New().String())
+	t.TokenHash = hashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	_, err = x.Insert(t)
+	return err
+}
+
+// GetRepoAccessTokenBySHA returns repository access token by given token value
+func GetRepoAccessTokenBySHA(token string) (*RepoAccessToken, error) {
+	if len(token) < 8 {
+		return nil, ErrRepoAccessTokenNotExist{}
+	}
+	var tokens []RepoAccessToken
+	lastEight := token[len(token)-8:]
+	if err := x.Where("token_last_eight = ?", lastEight).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		tempHash := hashToken(token, t.TokenSalt)
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tempHash)) == 1 {
+			return &t, nil
+		}
+	}
+	return nil, ErrRepoAccessTokenNotExist{}
+}
+
+// RepoAccessTokenByNameExists checks if a token name has been used already in a repository.
+func RepoAccessTokenByNameExists(repoID int64, name string) (bool, error) {
+	return x.Exist(&RepoAccessToken{RepoID: repoID, Name: name})
+}
+
+// ListRepoAccessTokens returns the access tokens of a repository
+func ListRepoAccessTokens(repoID int64) ([]*RepoAccessToken, error) {
+	tokens := make([]*RepoAccessToken, 0, 5)
+	return tokens, x.
+		Where("repo_id = ?", repoID).
+		Desc("id").
+		Find(&tokens)
+}
+
+// DeleteRepoAccessTokenByID revokes the access token with the given ID of a repository
+func DeleteRepoAccessTokenByID(id, repoID int64) error {
+	cnt, err := x.ID(id).Delete(&RepoAccessToken{RepoID: repoID})
+	if err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrRepoAccessTokenNotExist{ID: id}
+	}
+	return nil
+}
+
+// UpdateLastUsed records that the token has just been used
+func (t *RepoAccessToken) UpdateLastUsed() error {
+	t.LastUsedUnix = timeutil.TimeStampNow()
+	_, err := x.ID(t.ID).Cols("last_used_unix").Update(t)
+	return err
+}
+
+// Permission returns the read-only permission the token grants on the given
+// repository, which is empty if the token belongs to another repository.
+func (t *RepoAccessToken) Permission(repo *Repository) (Permission, error) {
+	if t.RepoID != repo.ID {
+		return Permission{AccessMode: AccessModeNone}, nil
+	}
+	if err := repo.getUnits(x); err != nil {
+		return Permission{}, err
+	}
+	return Permission{
+		AccessMode: AccessModeRead,
+		Units:      repo.Units,
+	}, nil
+}
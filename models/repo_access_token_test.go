@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoAccessToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	token := &RepoAccessToken{
+		RepoID: 1,
+		Name:   "ci",
+	}
+	assert.NoError(t, NewRepoAccessToken(token))
+	assert.Len(t, token.Token, 40)
+
+	exist, err := RepoAccessTokenByNameExists(1, "ci")
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = RepoAccessTokenByNameExists(2, "ci")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	loaded, err := GetRepoAccessTokenBySHA(token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, loaded.ID)
+	assert.False(t, loaded.HasUsed)
+
+	_, err = GetRepoAccessTokenBySHA("notatoken")
+	assert.True(t, IsErrRepoAccessTokenNotExist(err))
+
+	assert.NoError(t, loaded.UpdateLastUsed())
+	tokens, err := ListRepoAccessTokens(1)
+	assert.NoError(t, err)
+	if assert.Len(t, tokens, 1) {
+		assert.True(t, tokens[0].HasUsed)
+	}
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	perm, err := loaded.Permission(repo)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanRead(UnitTypeCode))
+	assert.False(t, perm.CanWrite(UnitTypeCode))
+
+	other := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	perm, err = loaded.Permission(other)
+	assert.NoError(t, err)
+	assert.False(t, perm.CanRead(UnitTypeCode))
+
+	assert.True(t, IsErrRepoAccessTokenNotExist(DeleteRepoAccessTokenByID(token.ID, 2)))
+	assert.NoError(t, DeleteRepoAccessTokenByID(token.ID, 1))
+	AssertNotExistsBean(t, &RepoAccessToken{ID: token.ID})
+}
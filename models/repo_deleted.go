@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// PurgeUnix returns the time from which the repository pending deletion is purged
+func (repo *Repository) PurgeUnix() timeutil.TimeStamp {
+	return repo.DeletedUnix.AddDuration(setting.Repository.DeletedRetention)
+}
+
+// SoftDeleteRepository marks the repository as deleted by doer, it is hidden but keeps its name
+// until it is purged or restored
+func SoftDeleteRepository(doer *User, repo *Repository) error {
+	if repo.IsDeleted {
+		return nil
+	}
+	repo.IsDeleted = true
+	repo.DeletedByID = doer.ID
+	repo.DeletedUnix = timeutil.TimeStampNow()
+	_, err := x.ID(repo.ID).Cols("is_deleted", "deleted_by_id", "deleted_unix").NoAutoTime().Update(repo)
+	return err
+}
+
+// GetDeletedRepositoryByID returns the repository pending deletion by given id
+func GetDeletedRepositoryByID(id int64) (*Repository, error) {
+	repo, err := getRepositoryByID(x, id)
+	if err != nil {
+		return nil, err
+	} else if !repo.IsDeleted {
+		return nil, ErrRepoNotExist{id, 0, "", ""}
+	}
+	return repo, nil
+}
+
+// RestoreRepository restores a repository pending deletion
+func RestoreRepository(repo *Repository) error {
+	if !repo.IsDeleted {
+		return nil
+	}
+	repo.IsDeleted = false
+	repo.DeletedByID = 0
+	repo.DeletedUnix = 0
+	_, err := x.ID(repo.ID).Cols("is_deleted", "deleted_by_id", "deleted_unix").NoAutoTime().Update(repo)
+	return err
+}
+
+// FindDeletedRepoOptions represents the options to list the repositories pending deletion
+type FindDeletedRepoOptions struct {
+	ListOptions
+	// OwnerIDs limits the list to the repositories of these users and organizations if not empty
+	OwnerIDs []int64
+	// DeletedBefore limits the list to the repositories deleted at or before this time if not zero
+	DeletedBefore timeutil.TimeStamp
+}
+
+func (opts *FindDeletedRepoOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"is_deleted": true})
+	if len(opts.OwnerIDs) > 0 {
+		cond = cond.And(builder.In("owner_id", opts.OwnerIDs))
+	}
+	if opts.DeletedBefore > 0 {
+		cond = cond.And(builder.Lte{"deleted_unix": opts.DeletedBefore})
+	}
+	return cond
+}
+
+// FindDeletedRepositories returns the repositories pending deletion, the most recently deleted first,
+// with their owners loaded
+func FindDeletedRepositories(opts *FindDeletedRepoOptions) (RepositoryList, int64, error) {
+	sess := x.Where(opts.toConds())
+	if opts.Page > 0 {
+		sess = opts.setSessionPagination(sess)
+	}
+	repos := make(RepositoryList, 0, opts.PageSize)
+	count, err := sess.OrderBy("deleted_unix DESC").FindAndCount(&repos)
+	if err != nil {
+		return nil, 0, err
+	}
+	return repos, count, repos.loadAttributes(x)
+}
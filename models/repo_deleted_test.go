@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftDeleteRepository(t *testing.T) {
+	PrepareTestEnv(t)
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, SoftDeleteRepository(doer, repo))
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, IsDeleted: true, DeletedByID: doer.ID})
+
+	// the repository is hidden
+	_, err := GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.True(t, IsErrRepoNotExist(err))
+	_, err = GetRepositoryByName(2, "repo1")
+	assert.True(t, IsErrRepoNotExist(err))
+	repos, _, err := SearchRepository(&SearchRepoOptions{Actor: doer, Private: true, OwnerID: 2, Keyword: "repo1"})
+	assert.NoError(t, err)
+	for _, r := range repos {
+		assert.NotEqual(t, repo.ID, r.ID)
+	}
+	_, err = GetRepositoryByID(repo.ID)
+	assert.True(t, IsErrRepoNotExist(err))
+	deletedRepo, err := GetDeletedRepositoryByID(repo.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, repo.ID, deletedRepo.ID)
+
+	// and so are its issues on the dashboard
+	repoIDs, err := doer.GetActiveAccessRepoIDs(UnitTypeIssues)
+	assert.NoError(t, err)
+	assert.NotContains(t, repoIDs, repo.ID)
+	issues, err := Issues(&IssuesOptions{RepoIDs: []int64{repo.ID}})
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+	stats, err := GetUserIssueStats(UserIssueStatsOptions{UserID: doer.ID, FilterMode: FilterModeAll, UserRepoIDs: []int64{repo.ID}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, stats.OpenCount)
+	assert.EqualValues(t, 0, stats.ClosedCount)
+
+	// but its name is still reserved
+	err = CheckCreateRepository(doer, doer, "repo1", false)
+	assert.True(t, IsErrRepoAlreadyExist(err))
+
+	deleted, count, err := FindDeletedRepositories(&FindDeletedRepoOptions{OwnerIDs: []int64{2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, deleted, 1) {
+		assert.EqualValues(t, repo.ID, deleted[0].ID)
+	}
+	deleted, _, err = FindDeletedRepositories(&FindDeletedRepoOptions{DeletedBefore: repo.DeletedUnix - 1})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+	deleted, _, err = FindDeletedRepositories(&FindDeletedRepoOptions{OwnerIDs: []int64{3}})
+	assert.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	assert.NoError(t, RestoreRepository(repo))
+	restored, err := GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.NoError(t, err)
+	assert.False(t, restored.IsDeleted)
+	assert.EqualValues(t, 0, restored.DeletedUnix)
+	_, err = GetRepositoryByID(repo.ID)
+	assert.NoError(t, err)
+	_, err = GetDeletedRepositoryByID(repo.ID)
+	assert.True(t, IsErrRepoNotExist(err))
+	issues, err = Issues(&IssuesOptions{RepoIDs: []int64{repo.ID}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, issues)
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// GitOneTimeTokenReuseWindow is how long the git operation which consumed a one-time token can take,
+// as a single clone, fetch or push over smart HTTP takes several requests: the advertisement of the
+// refs followed by one or more negotiation rounds, or the ls-refs and fetch commands of protocol v2.
+const GitOneTimeTokenReuseWindow = time.Minute
+
+// GitOneTimeToken represents a short-lived git credential of a repository,
+// which can be used for a single clone, fetch or push over HTTP.
+// Git operations performed with it are attributed to the user who created it.
+type GitOneTimeToken struct {
+	ID        int64      `xorm:"pk autoincr"`
+	RepoID    int64      `xorm:"INDEX NOT NULL"`
+	UID       int64      `xorm:"INDEX NOT NULL"`
+	Mode      AccessMode `xorm:"NOT NULL"`
+	TokenHash string     `xorm:"UNIQUE NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX"`
+	UsedUnix    timeutil.TimeStamp
+	// UsedBy identifies the client and the service of the git operation which consumed the token
+	UsedBy string
+}
+
+func hashGitOneTimeToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// NewGitOneTimeToken creates a one-time token granting the given access mode
+// to the repository, it returns the token value which is not stored.
+func NewGitOneTimeToken(repoID, uid int64, mode AccessMode, ttl time.Duration) (*GitOneTimeToken, string, error) {
+	token, err := generate.GetRandomString(40)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := timeutil.TimeStampNow()
+	t := &GitOneTimeToken{
+		RepoID:      repoID,
+		UID:         uid,
+		Mode:        mode,
+		TokenHash:   hashGitOneTimeToken(token),
+		ExpiresUnix: now.AddDuration(ttl),
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, "", err
+	}
+	if _, err = sess.Where("expires_unix <= ? OR (used_unix > 0 AND used_unix <= ?)",
+		now, now.AddDuration(-GitOneTimeTokenReuseWindow)).Delete(new(GitOneTimeToken)); err != nil {
+		return nil, "", err
+	}
+	if _, err = sess.Insert(t); err != nil {
+		return nil, "", err
+	}
+	return t, token, sess.Commit()
+}
+
+// GetGitOneTimeToken returns the one-time token with the given value if it can still be used
+func GetGitOneTimeToken(token string) (*GitOneTimeToken, error) {
+	if len(token) == 0 {
+		return nil, ErrGitOneTimeTokenInvalid{"empty"}
+	}
+	t := &GitOneTimeToken{TokenHash: hashGitOneTimeToken(token)}
+	has, err := x.Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrGitOneTimeTokenInvalid{"not exist"}
+	}
+	if t.ExpiresUnix <= timeutil.TimeStampNow() {
+		return nil, ErrGitOneTimeTokenInvalid{"expired"}
+	}
+	if t.UsedUnix > 0 && !t.inReuseWindow() {
+		return nil, ErrGitOneTimeTokenInvalid{"already used"}
+	}
+	return t, nil
+}
+
+func (t *GitOneTimeToken) inReuseWindow() bool {
+	return t.UsedUnix.AddDuration(GitOneTimeTokenReuseWindow) > timeutil.TimeStampNow()
+}
+
+// Use consumes the token for a git operation. The operation is started by the advertisement of the refs,
+// which consumes the token, the following requests of the operation are accepted as long as they come from
+// the same client for the same service. The token is rejected for any other request, including the start of
+// another operation, and once it has expired.
+func (t *GitOneTimeToken) Use(client string, startsOperation bool) error {
+	now := timeutil.TimeStampNow()
+	if t.ExpiresUnix <= now {
+		return ErrGitOneTimeTokenInvalid{"expired"}
+	}
+	usedBy := hashGitOneTimeToken(client)
+
+	if t.UsedUnix == 0 {
+		if !startsOperation {
+			return ErrGitOneTimeTokenInvalid{"no operation started"}
+		}
+		cnt, err := x.Where("id = ? AND used_unix = 0 AND expires_unix > ?", t.ID, now).
+			Cols("used_unix", "used_by").
+			Update(&GitOneTimeToken{UsedUnix: now, UsedBy: usedBy})
+		if err != nil {
+			return err
+		} else if cnt == 1 {
+			t.UsedUnix = now
+			t.UsedBy = usedBy
+			return nil
+		}
+		// it has expired or has been used concurrently
+		return ErrGitOneTimeTokenInvalid{"already used"}
+	}
+
+	if startsOperation || t.UsedBy != usedBy || !t.inReuseWindow() {
+		return ErrGitOneTimeTokenInvalid{"already used"}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitOneTimeToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	token, value, err := NewGitOneTimeToken(1, 2, AccessModeWrite, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, value, 40)
+	assert.NotEqual(t, value, token.TokenHash)
+
+	loaded, err := GetGitOneTimeToken(value)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, loaded.ID)
+	assert.EqualValues(t, 1, loaded.RepoID)
+	assert.EqualValues(t, 2, loaded.UID)
+	assert.Equal(t, AccessModeWrite, loaded.Mode)
+
+	_, err = GetGitOneTimeToken("invalid")
+	assert.True(t, IsErrGitOneTimeTokenInvalid(err))
+
+	// a token has to be consumed by the start of a git operation
+	assert.True(t, IsErrGitOneTimeTokenInvalid(loaded.Use("upload-pack\n127.0.0.1\ngit/2.30", false)))
+
+	// the several requests of the operation can use it
+	assert.NoError(t, loaded.Use("upload-pack\n127.0.0.1\ngit/2.30", true))
+	reloaded, err := GetGitOneTimeToken(value)
+	assert.NoError(t, err)
+	assert.NoError(t, reloaded.Use("upload-pack\n127.0.0.1\ngit/2.30", false))
+
+	// but no other client, service or operation
+	assert.True(t, IsErrGitOneTimeTokenInvalid(reloaded.Use("upload-pack\n192.0.2.1\ngit/2.30", false)))
+	assert.True(t, IsErrGitOneTimeTokenInvalid(reloaded.Use("receive-pack\n127.0.0.1\ngit/2.30", false)))
+	assert.True(t, IsErrGitOneTimeTokenInvalid(reloaded.Use("upload-pack\n127.0.0.1\ngit/2.30", true)))
+
+	// and not once the reuse window has passed
+	loaded.UsedUnix = timeutil.TimeStampNow().AddDuration(-GitOneTimeTokenReuseWindow)
+	_, err = x.ID(loaded.ID).Cols("used_unix").Update(loaded)
+	assert.NoError(t, err)
+	assert.True(t, IsErrGitOneTimeTokenInvalid(loaded.Use("upload-pack\n127.0.0.1\ngit/2.30", false)))
+	_, err = GetGitOneTimeToken(value)
+	assert.True(t, IsErrGitOneTimeTokenInvalid(err))
+
+	// nor once the token has expired, even during the operation
+	inUse, value, err := NewGitOneTimeToken(1, 2, AccessModeRead, time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, inUse.Use("upload-pack\n127.0.0.1\ngit/2.30", true))
+	inUse.ExpiresUnix = timeutil.TimeStampNow() - 1
+	_, err = x.ID(inUse.ID).Cols("expires_unix").Update(inUse)
+	assert.NoError(t, err)
+	_, err = GetGitOneTimeToken(value)
+	assert.True(t, IsErrGitOneTimeTokenInvalid(err))
+	assert.True(t, IsErrGitOneTimeTokenInvalid(inUse.Use("upload-pack\n127.0.0.1\ngit/2.30", false)))
+
+	// expired tokens are rejected
+	expired, value, err := NewGitOneTimeToken(1, 2, AccessModeRead, time.Minute)
+	assert.NoError(t, err)
+	expired.ExpiresUnix = timeutil.TimeStampNow() - 1
+	_, err = x.ID(expired.ID).Cols("expires_unix").Update(expired)
+	assert.NoError(t, err)
+	_, err = GetGitOneTimeToken(value)
+	assert.True(t, IsErrGitOneTimeTokenInvalid(err))
+	assert.True(t, IsErrGitOneTimeTokenInvalid(expired.Use("upload-pack\n127.0.0.1\ngit/2.30", true)))
+
+	// a token in use is kept until its reuse window has passed
+	inUse, _, err = NewGitOneTimeToken(1, 2, AccessModeRead, time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, inUse.Use("upload-pack\n127.0.0.1\ngit/2.30", true))
+
+	// used and expired tokens are cleaned up when creating new ones
+	_, _, err = NewGitOneTimeToken(1, 2, AccessModeRead, time.Minute)
+	assert.NoError(t, err)
+	AssertNotExistsBean(t, &GitOneTimeToken{ID: token.ID})
+	AssertNotExistsBean(t, &GitOneTimeToken{ID: expired.ID})
+	AssertExistsAndLoadBean(t, &GitOneTimeToken{ID: inUse.ID})
+}
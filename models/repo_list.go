@@ -207,7 +207,8 @@ const (
 
 // SearchRepositoryCondition creates a query condition according search repository options
 func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
-	cond := builder.NewCond()
+	// repositories pending deletion are only listed by FindDeletedRepositories
+	cond := builder.NewCond().And(builder.Eq{"is_deleted": false})
 
 	if opts.Private {
 		if opts.Actor != nil && !opts.Actor.IsAdmin && opts.Actor.ID != opts.OwnerID {
@@ -449,6 +450,73 @@ func accessibleRepositoryCondition(user *User) builder.Cond {
 	return cond
 }
 
+// AccessibleRepositoryUnitCondition returns the condition selecting the repositories where the user, who is
+// anonymous if nil, can read the unit. Unlike accessibleRepositoryCondition, the access granted by the teams
+// of an organization is limited to the teams with the unit, so it can be used instead of checking the
+// permission of the user for each repository.
+func AccessibleRepositoryUnitCondition(user *User, unitType UnitType) builder.Cond {
+	cond := builder.And(
+		builder.Eq{"`repository`.is_deleted": false},
+		builder.In("`repository`.id", builder.Select("repo_id").From("repo_unit").Where(builder.Eq{"type": unitType})),
+	)
+	if user != nil && user.IsAdmin {
+		return cond
+	}
+
+	accessCond := builder.NewCond()
+	if user == nil || !user.IsRestricted || user.ID <= 0 {
+		orgVisibilityLimit := []structs.VisibleType{structs.VisibleTypePrivate}
+		if user == nil || user.ID <= 0 {
+			orgVisibilityLimit = append(orgVisibilityLimit, structs.VisibleTypeLimited)
+		}
+		// 1. Public repositories which aren't in a private organization, or in a limited one if not signed in
+		accessCond = accessCond.Or(builder.And(
+			builder.Eq{"`repository`.is_private": false},
+			builder.NotIn("`repository`.owner_id", builder.Select("id").From("`user`").Where(
+				builder.And(
+					builder.Eq{"type": UserTypeOrganization},
+					builder.In("visibility", orgVisibilityLimit)),
+			))))
+	}
+
+	if user != nil {
+		accessCond = accessCond.Or(
+			// 2. Repositories that we directly own
+			builder.Eq{"`repository`.owner_id": user.ID},
+			// 3. Repositories that we collaborate on, which gives access to all their units
+			builder.In("`repository`.id", builder.Select("repo_id").
+				From("collaboration").
+				Where(builder.Eq{"user_id": user.ID})),
+			// 4. Repositories of the teams we are in which are owners or have the unit
+			builder.In("`repository`.id", builder.Select("`team_repo`.repo_id").
+				From("team_repo").
+				Join("INNER", "team_user", "`team_user`.team_id = `team_repo`.team_id").
+				Join("INNER", "team", "`team`.id = `team_repo`.team_id").
+				Where(builder.And(
+					builder.Eq{"`team_user`.uid": user.ID},
+					builder.Or(
+						builder.Gte{"`team`.authorize": AccessModeOwner},
+						builder.In("`team`.id", builder.Select("team_id").From("team_unit").Where(builder.Eq{"type": unitType})),
+					)))),
+		)
+		if !user.IsRestricted {
+			// 5. Public repositories of the private organizations that we are a member of
+			accessCond = accessCond.Or(builder.And(
+				builder.Eq{"`repository`.is_private": false},
+				builder.In("`repository`.owner_id", builder.Select("`org_user`.org_id").
+					From("org_user").
+					Where(builder.Eq{"`org_user`.uid": user.ID}))))
+		}
+	}
+	return cond.And(accessCond)
+}
+
+// FindRepoIDsByCond returns the ids of the repositories matching the condition
+func FindRepoIDsByCond(cond builder.Cond) ([]int64, error) {
+	repoIDs := make([]int64, 0, 10)
+	return repoIDs, x.Table("repository").Cols("id").Where(cond).Find(&repoIDs)
+}
+
 // SearchRepositoryByName takes keyword and part of repository name to search,
 // it returns results in given range and number of total results.
 func SearchRepositoryByName(opts *SearchRepoOptions) (RepositoryList, int64, error) {
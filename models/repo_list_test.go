@@ -355,3 +355,31 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessibleRepositoryUnitCondition(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repos := make([]*Repository, 0, 50)
+	assert.NoError(t, x.Where("is_deleted = ?", false).Find(&repos))
+
+	// the condition matches the repositories where the permission allows reading the unit
+	for _, userID := range []int64{0, 1, 2, 4, 5, 15, 18, 29} {
+		var user *User
+		if userID > 0 {
+			user = AssertExistsAndLoadBean(t, &User{ID: userID}).(*User)
+		}
+		for _, unitType := range []UnitType{UnitTypeIssues, UnitTypePullRequests} {
+			var expected []int64
+			for _, repo := range repos {
+				perm, err := GetUserRepoPermission(repo, user)
+				assert.NoError(t, err)
+				if perm.CanRead(unitType) {
+					expected = append(expected, repo.ID)
+				}
+			}
+			repoIDs, err := FindRepoIDsByCond(AccessibleRepositoryUnitCondition(user, unitType))
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, expected, repoIDs, "user %d, unit %s", userID, unitType.String())
+		}
+	}
+}
@@ -6,14 +6,57 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/xorm"
 )
 
+// MirrorAuthType represents how a mirror obtains the credentials to fetch from its remote
+type MirrorAuthType int
+
+const (
+	// MirrorAuthStatic uses the credentials stored in the remote address
+	MirrorAuthStatic MirrorAuthType = iota
+	// MirrorAuthCredentialHelper runs a credential helper configured by the site administrator before each sync
+	MirrorAuthCredentialHelper
+	// MirrorAuthOAuth2 obtains a fresh access token with an OAuth2 refresh token before each sync
+	MirrorAuthOAuth2
+)
+
+// MirrorErrorKind represents the cause of the last failed mirror sync
+type MirrorErrorKind int
+
+const (
+	// MirrorErrorNone the last sync succeeded
+	MirrorErrorNone MirrorErrorKind = iota
+	// MirrorErrorAuth the remote rejected the credentials or they could not be obtained
+	MirrorErrorAuth
+	// MirrorErrorNetwork the remote could not be reached
+	MirrorErrorNetwork
+	// MirrorErrorOther any other failure
+	MirrorErrorOther
+)
+
+// MirrorAuthConfig holds the secrets used to obtain mirror credentials, it is stored encrypted
+type MirrorAuthConfig struct {
+	// Credential helper
+	Helper      string `json:"helper,omitempty"`
+	HelperInput string `json:"helper_input,omitempty"`
+
+	// OAuth2 refresh
+	TokenURL     string `json:"token_url,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Username     string `json:"username,omitempty"`
+}
+
 // Mirror represents mirror information of a repository.
 type Mirror struct {
 	ID          int64       `xorm:"pk autoincr"`
@@ -25,9 +68,49 @@ type Mirror struct {
 	UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX"`
 	NextUpdateUnix timeutil.TimeStamp `xorm:"INDEX"`
 
+	AuthType   MirrorAuthType `xorm:"NOT NULL DEFAULT 0"`
+	AuthConfig string         `xorm:"TEXT"`
+
+	LastErrorKind MirrorErrorKind `xorm:"NOT NULL DEFAULT 0"`
+	LastError     string          `xorm:"TEXT"`
+	LastErrorUnix timeutil.TimeStamp
+
 	Address string `xorm:"-"`
 }
 
+// GetAuthConfig decrypts the credential configuration of the mirror
+func (m *Mirror) GetAuthConfig() (*MirrorAuthConfig, error) {
+	cfg := &MirrorAuthConfig{}
+	if m.AuthConfig == "" {
+		return cfg, nil
+	}
+	plain, err := secret.DecryptSecret(setting.SecretKey, m.AuthConfig)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, json.Unmarshal([]byte(plain), cfg)
+}
+
+// SetAuthConfig encrypts and sets the credential configuration of the mirror
+func (m *Mirror) SetAuthConfig(cfg *MirrorAuthConfig) error {
+	plain, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	m.AuthConfig, err = secret.EncryptSecret(setting.SecretKey, string(plain))
+	return err
+}
+
+// IsAuthError returns true if the last sync failed because of the credentials
+func (m *Mirror) IsAuthError() bool {
+	return m.LastErrorKind == MirrorErrorAuth
+}
+
+// IsNetworkError returns true if the last sync failed because the remote could not be reached
+func (m *Mirror) IsNetworkError() bool {
+	return m.LastErrorKind == MirrorErrorNetwork
+}
+
 // BeforeInsert will be invoked by XORM before inserting a record
 func (m *Mirror) BeforeInsert() {
 	if m != nil {
@@ -74,6 +157,25 @@ func GetMirrorByRepoID(repoID int64) (*Mirror, error) {
 	return getMirrorByRepoID(x, repoID)
 }
 
+// UpdateMirrorAuth updates the credential configuration of a mirror
+func UpdateMirrorAuth(m *Mirror) error {
+	_, err := x.ID(m.ID).Cols("auth_type", "auth_config").Update(m)
+	return err
+}
+
+// UpdateMirrorLastError records the outcome of the last sync of a mirror
+func UpdateMirrorLastError(m *Mirror, kind MirrorErrorKind, message string) error {
+	m.LastErrorKind = kind
+	m.LastError = message
+	if kind == MirrorErrorNone {
+		m.LastErrorUnix = 0
+	} else {
+		m.LastErrorUnix = timeutil.TimeStampNow()
+	}
+	_, err := x.ID(m.ID).Cols("last_error_kind", "last_error", "last_error_unix").Update(m)
+	return err
+}
+
 func updateMirror(e Engine, m *Mirror) error {
 	_, err := e.ID(m.ID).AllCols().Update(m)
 	return err
@@ -0,0 +1,276 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// PushPolicyMode represents how a push policy check is enforced
+type PushPolicyMode int
+
+const (
+	// PushPolicyModeDisabled the check is not run
+	PushPolicyModeDisabled PushPolicyMode = iota
+	// PushPolicyModeWarn violations are reported to the pusher but accepted
+	PushPolicyModeWarn
+	// PushPolicyModeBlock violations reject the push
+	PushPolicyModeBlock
+)
+
+// IsEnabled returns true if the check has to be run
+func (mode PushPolicyMode) IsEnabled() bool {
+	return mode == PushPolicyModeWarn || mode == PushPolicyModeBlock
+}
+
+// IsBlocking returns true if violations reject the push
+func (mode PushPolicyMode) IsBlocking() bool {
+	return mode == PushPolicyModeBlock
+}
+
+// ConventionalCommitPattern matches the header of a commit message following
+// the Conventional Commits specification, e.g. "feat(ui)!: add dark theme".
+const ConventionalCommitPattern = `^(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([\w\-./ ]+\))?!?: \S.*`
+
+// PushPolicy represents the checks run on every push to a repository
+type PushPolicy struct {
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE"`
+
+	CommitMessageMode        PushPolicyMode `xorm:"NOT NULL DEFAULT 0"`
+	CommitMessagePattern     string         `xorm:"TEXT"`
+	CommitMessageAdminBypass bool           `xorm:"NOT NULL DEFAULT false"`
+
+	MaxBlobSize        int64  `xorm:"NOT NULL DEFAULT 0"`
+	BlobSizeExemptions string `xorm:"TEXT"`
+
+	ForbiddenPaths            string `xorm:"TEXT"`
+	ForbiddenPathsUseDefaults bool   `xorm:"NOT NULL DEFAULT false"`
+	ForbiddenPathsAdminBypass bool   `xorm:"NOT NULL DEFAULT false"`
+
+	SecretScanningMode PushPolicyMode `xorm:"NOT NULL DEFAULT 0"`
+
+	BranchNameMode       PushPolicyMode `xorm:"NOT NULL DEFAULT 0"`
+	BranchNamePatterns   string         `xorm:"TEXT"`
+	BranchNameExemptions string         `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetPushPolicyByRepoID returns the push policy of the repository.
+// If none has been configured a disabled default policy is returned.
+func GetPushPolicyByRepoID(repoID int64) (*PushPolicy, error) {
+	policy := &PushPolicy{RepoID: repoID}
+	if _, err := x.Where("repo_id = ?", repoID).Get(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdatePushPolicy inserts or updates the push policy of a repository
+func UpdatePushPolicy(policy *PushPolicy) error {
+	if policy.ID == 0 {
+		_, err := x.Insert(policy)
+		return err
+	}
+	_, err := x.ID(policy.ID).AllCols().Update(policy)
+	return err
+}
+
+// CommitMessageRegexp returns the compiled pattern commit messages have to match.
+// Conventional Commits are expected if no pattern has been configured.
+func (policy *PushPolicy) CommitMessageRegexp() (*regexp.Regexp, error) {
+	pattern := strings.TrimSpace(policy.CommitMessagePattern)
+	if pattern == "" {
+		pattern = ConventionalCommitPattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// IsCommitMessageValid checks the first line of the commit message against the pattern of the policy,
+// as compiled by CommitMessageRegexp
+func IsCommitMessageValid(re *regexp.Regexp, message string) bool {
+	subject := strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+	return re.MatchString(subject)
+}
+
+// IsBlobSizeLimited returns true if pushed blobs are limited in size
+func (policy *PushPolicy) IsBlobSizeLimited() bool {
+	return policy.MaxBlobSize > 0
+}
+
+// BlobSizeExemptionPatterns returns the patterns of the files which are exempted from the blob size limit
+func (policy *PushPolicy) BlobSizeExemptionPatterns() PathPatterns {
+	return compilePathPatterns(policy.BlobSizeExemptions)
+}
+
+// ForbiddenPathPatterns returns the patterns of the files which must not be pushed to the repository.
+// The patterns enforced by the organization owning the repository are not included.
+func (policy *PushPolicy) ForbiddenPathPatterns() PathPatterns {
+	return forbiddenPathPatterns(policy.ForbiddenPaths, policy.ForbiddenPathsUseDefaults)
+}
+
+// IsBranchNameAllowed checks the name of a new branch against the branch naming policy. The default branch
+// and the exempted names are always allowed, as is any name if no pattern has been configured.
+func (policy *PushPolicy) IsBranchNameAllowed(name, defaultBranch string) (bool, error) {
+	if !policy.BranchNameMode.IsEnabled() || name == defaultBranch {
+		return true, nil
+	}
+	patterns, err := CompileBranchNamePatterns(policy.BranchNamePatterns)
+	if err != nil || len(patterns) == 0 {
+		return err == nil, err
+	}
+	exemptions, err := CompileBranchNamePatterns(policy.BranchNameExemptions)
+	if err != nil {
+		return false, err
+	}
+	return patterns.Match(name) || exemptions.Match(name), nil
+}
+
+// BranchNamePatternList returns the patterns new branch names have to match, including the exemptions
+func (policy *PushPolicy) BranchNamePatternList() []string {
+	list := make([]string, 0, 5)
+	for _, pattern := range strings.Split(policy.BranchNamePatterns+"\n"+policy.BranchNameExemptions, "\n") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			list = append(list, pattern)
+		}
+	}
+	return list
+}
+
+// branchNamePattern matches branch names with either a glob or a regular expression
+type branchNamePattern struct {
+	glob glob.Glob
+	re   *regexp.Regexp
+}
+
+// BranchNamePatterns is a list of compiled patterns matching branch names
+type BranchNamePatterns []branchNamePattern
+
+// Match returns true if the branch name matches one of the patterns
+func (patterns BranchNamePatterns) Match(name string) bool {
+	for _, p := range patterns {
+		if p.re != nil && p.re.MatchString(name) || p.glob != nil && p.glob.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompileBranchNamePatterns compiles a newline separated list of patterns matching branch names.
+// A pattern is a glob where "*" does not cross slashes, e.g. "feature/*", or a regular expression
+// written between slashes, e.g. "/^release-[0-9.]+$/".
+func CompileBranchNamePatterns(patterns string) (BranchNamePatterns, error) {
+	compiled := make(BranchNamePatterns, 0, 5)
+	for _, expr := range strings.Split(patterns, "\n") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		if len(expr) > 2 && strings.HasPrefix(expr, "/") && strings.HasSuffix(expr, "/") {
+			re, err := regexp.Compile(expr[1 : len(expr)-1])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", expr, err)
+			}
+			compiled = append(compiled, branchNamePattern{re: re})
+			continue
+		}
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", expr, err)
+		}
+		compiled = append(compiled, branchNamePattern{glob: g})
+	}
+	return compiled, nil
+}
+
+// OrgPushPolicy represents the push checks an organization enforces on all its repositories
+type OrgPushPolicy struct {
+	ID    int64 `xorm:"pk autoincr"`
+	OrgID int64 `xorm:"UNIQUE"`
+
+	ForbiddenPaths            string `xorm:"TEXT"`
+	ForbiddenPathsUseDefaults bool   `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// GetOrgPushPolicy returns the push policy of the organization.
+// If none has been configured an empty default policy is returned.
+func GetOrgPushPolicy(orgID int64) (*OrgPushPolicy, error) {
+	policy := &OrgPushPolicy{OrgID: orgID}
+	if _, err := x.Where("org_id = ?", orgID).Get(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdateOrgPushPolicy inserts or updates the push policy of an organization
+func UpdateOrgPushPolicy(policy *OrgPushPolicy) error {
+	if policy.ID == 0 {
+		_, err := x.Insert(policy)
+		return err
+	}
+	_, err := x.ID(policy.ID).AllCols().Update(policy)
+	return err
+}
+
+// ForbiddenPathPatterns returns the patterns of the files which must not be pushed to any repository of the organization
+func (policy *OrgPushPolicy) ForbiddenPathPatterns() PathPatterns {
+	return forbiddenPathPatterns(policy.ForbiddenPaths, policy.ForbiddenPathsUseDefaults)
+}
+
+func forbiddenPathPatterns(patterns string, useDefaults bool) PathPatterns {
+	if useDefaults {
+		patterns = strings.Join(append([]string{patterns}, setting.Repository.PushPolicy.DefaultForbiddenPaths...), ";")
+	}
+	return compilePathPatterns(patterns)
+}
+
+// PathPatterns is a list of compiled glob patterns matching file paths
+type PathPatterns []glob.Glob
+
+// Match returns true if the path or its base name matches one of the patterns
+func (patterns PathPatterns) Match(path string) bool {
+	base := path
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		base = path[idx+1:]
+	}
+	for _, g := range patterns {
+		if g.Match(path) || g.Match(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePathPatterns compiles a ";" or newline separated list of glob patterns matching file paths.
+// A "*" does not cross directories, "**" does.
+func compilePathPatterns(patterns string) PathPatterns {
+	globs := make(PathPatterns, 0, 5)
+	for _, expr := range strings.FieldsFunc(patterns, func(r rune) bool { return r == ';' || r == '\n' }) {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		g, err := glob.Compile(expr, '/')
+		if err != nil {
+			log.Info("Invalid glob expression '%s' (skipped): %v", expr, err)
+			continue
+		}
+		globs = append(globs, g)
+	}
+	return globs
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCommitMessageValid(t *testing.T) {
+	policy := &PushPolicy{}
+	re, err := policy.CommitMessageRegexp()
+	assert.NoError(t, err)
+	for message, valid := range map[string]bool{
+		"feat: add dark theme":                true,
+		"fix(ui): correct padding\n\nbody":    true,
+		"refactor(models)!: drop legacy code": true,
+		"chore: ":                             false,
+		"Add dark theme":                      false,
+		"feature: add dark theme":             false,
+	} {
+		assert.Equal(t, valid, IsCommitMessageValid(re, message), message)
+	}
+
+	policy.CommitMessagePattern = `^[A-Z]+-[0-9]+ `
+	re, err = policy.CommitMessageRegexp()
+	assert.NoError(t, err)
+	assert.True(t, IsCommitMessageValid(re, "PROJ-12 fix login"))
+	assert.False(t, IsCommitMessageValid(re, "fix: login"))
+
+	policy.CommitMessagePattern = `(`
+	_, err = policy.CommitMessageRegexp()
+	assert.Error(t, err)
+}
+
+func TestPushPolicy_IsBranchNameAllowed(t *testing.T) {
+	policy := &PushPolicy{
+		BranchNamePatterns:   "feature/*\nbugfix/*\n/^release-[0-9.]+$/",
+		BranchNameExemptions: "dependabot/**",
+	}
+	// the check is disabled
+	ok, err := policy.IsBranchNameAllowed("wip", "master")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	policy.BranchNameMode = PushPolicyModeBlock
+	for name, allowed := range map[string]bool{
+		"master":                true,
+		"feature/login":         true,
+		"bugfix/crash":          true,
+		"release-1.14":          true,
+		"dependabot/npm/lodash": true,
+		"feature/login/ui":      false,
+		"release-next":          false,
+		"wip":                   false,
+	} {
+		ok, err := policy.IsBranchNameAllowed(name, "master")
+		assert.NoError(t, err)
+		assert.Equal(t, allowed, ok, name)
+	}
+
+	policy.BranchNamePatterns = "/(/"
+	_, err = policy.IsBranchNameAllowed("wip", "master")
+	assert.Error(t, err)
+}
+
+func TestGetPushPolicyByRepoID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	policy, err := GetPushPolicyByRepoID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, policy.ID)
+	assert.EqualValues(t, 1, policy.RepoID)
+	assert.False(t, policy.CommitMessageMode.IsEnabled())
+
+	policy.CommitMessageMode = PushPolicyModeWarn
+	assert.NoError(t, UpdatePushPolicy(policy))
+	assert.NotZero(t, policy.ID)
+
+	policy.CommitMessageMode = PushPolicyModeBlock
+	assert.NoError(t, UpdatePushPolicy(policy))
+
+	policy, err = GetPushPolicyByRepoID(1)
+	assert.NoError(t, err)
+	assert.True(t, policy.CommitMessageMode.IsBlocking())
+}
+
+func TestPushPolicy_BlobSizeExemptionPatterns(t *testing.T) {
+	policy := &PushPolicy{
+		MaxBlobSize:        1024,
+		BlobSizeExemptions: "*.png; vendor/**\nassets/*.bin",
+	}
+	assert.True(t, policy.IsBlobSizeLimited())
+	exemptions := policy.BlobSizeExemptionPatterns()
+	assert.True(t, exemptions.Match("logo.png"))
+	assert.True(t, exemptions.Match("docs/images/logo.png"))
+	assert.True(t, exemptions.Match("vendor/a/b/c.so"))
+	assert.True(t, exemptions.Match("assets/data.bin"))
+	assert.False(t, exemptions.Match("assets/sub/data.bin"))
+	assert.False(t, exemptions.Match("build/app.exe"))
+
+	assert.False(t, (&PushPolicy{}).IsBlobSizeLimited())
+}
+
+func TestPushPolicy_ForbiddenPathPatterns(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	policy := &PushPolicy{}
+	assert.Empty(t, policy.ForbiddenPathPatterns())
+
+	policy.ForbiddenPaths = "secrets/**"
+	patterns := policy.ForbiddenPathPatterns()
+	assert.True(t, patterns.Match("secrets/prod/db.yml"))
+	assert.False(t, patterns.Match("config/.env"))
+
+	orgPolicy, err := GetOrgPushPolicy(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, orgPolicy.ID)
+	assert.Empty(t, orgPolicy.ForbiddenPathPatterns())
+	orgPolicy.ForbiddenPathsUseDefaults = true
+	assert.NoError(t, UpdateOrgPushPolicy(orgPolicy))
+
+	orgPolicy, err = GetOrgPushPolicy(3)
+	assert.NoError(t, err)
+	assert.NotZero(t, orgPolicy.ID)
+	patterns = orgPolicy.ForbiddenPathPatterns()
+	assert.False(t, patterns.Match("secrets/prod/db.yml"))
+	assert.True(t, patterns.Match("config/.env"))
+	assert.True(t, patterns.Match(".env.production"))
+	assert.True(t, patterns.Match("deploy/id_rsa"))
+	assert.True(t, patterns.Match("certs/server.key"))
+	assert.False(t, patterns.Match("docs/environment.md"))
+
+	// The patterns of the organization are kept apart from those of the repository
+	assert.False(t, policy.ForbiddenPathPatterns().Match("config/.env"))
+	policy.ForbiddenPathsUseDefaults = true
+	assert.True(t, policy.ForbiddenPathPatterns().Match("config/.env"))
+}
@@ -6,13 +6,19 @@ package models
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"fmt"
 	"image"
 	"image/png"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -133,6 +139,47 @@ func TestGetUserFork(t *testing.T) {
 	assert.Nil(t, repo)
 }
 
+func TestFindRepoInForkNetwork(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// repo 11 of user13 is a fork of repo10 of user12, add a fork of the fork owned by user2
+	repo10 := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
+	repo11 := AssertExistsAndLoadBean(t, &Repository{ID: 11}).(*Repository)
+	forkOfFork := &Repository{OwnerID: 2, OwnerName: "user2", LowerName: "repo11", Name: "repo11", IsFork: true, ForkID: repo11.ID}
+	_, err := x.Insert(forkOfFork)
+	assert.NoError(t, err)
+
+	rootID, err := GetForkNetworkRootID(forkOfFork)
+	assert.NoError(t, err)
+	assert.EqualValues(t, repo10.ID, rootID)
+
+	same, err := IsInSameForkNetwork(forkOfFork, repo10)
+	assert.NoError(t, err)
+	assert.True(t, same)
+	same, err = IsInSameForkNetwork(forkOfFork, AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository))
+	assert.NoError(t, err)
+	assert.False(t, same)
+
+	found, has, err := FindRepoInForkNetwork(2, repo10)
+	assert.NoError(t, err)
+	if assert.True(t, has) {
+		assert.EqualValues(t, forkOfFork.ID, found.ID)
+	}
+	found, has, err = FindRepoInForkNetwork(12, forkOfFork)
+	assert.NoError(t, err)
+	if assert.True(t, has) {
+		assert.EqualValues(t, repo10.ID, found.ID)
+	}
+
+	// the repository itself is not returned
+	_, has, err = FindRepoInForkNetwork(13, repo11)
+	assert.NoError(t, err)
+	assert.False(t, has)
+	_, has, err = FindRepoInForkNetwork(3, repo10)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
 func TestRepoAPIURL(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	repo := AssertExistsAndLoadBean(t, &Repository{ID: 10}).(*Repository)
@@ -221,3 +268,33 @@ func TestRepoGetReviewerTeams(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, len(teams))
 }
+
+func TestDeleteOldRepoArchivesFromStorage(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	oldMaxCacheSize := setting.RepoArchive.MaxCacheSize
+	defer func() { setting.RepoArchive.MaxCacheSize = oldMaxCacheSize }()
+	setting.RepoArchive.MaxCacheSize = 15
+
+	now := time.Now()
+	for i, age := range []time.Duration{48 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		p := fmt.Sprintf("1/archive%d.zip", i)
+		_, err := storage.RepoArchives.Save(p, bytes.NewReader(make([]byte, 10)))
+		assert.NoError(t, err)
+		modTime := now.Add(-age)
+		assert.NoError(t, os.Chtimes(filepath.Join(setting.RepoArchive.Storage.Section.Key("PATH").String(), p), modTime, modTime))
+	}
+
+	// archive0 is expired and archive1 and archive2 exceed the size limit.
+	assert.NoError(t, deleteOldRepoArchivesFromStorage(context.Background(), 24*time.Hour))
+	archives, err := listRepoArchives(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, archives, 1) {
+		assert.Equal(t, "1/archive3.zip", archives[0].path)
+	}
+
+	assert.NoError(t, DeleteRepositoryArchives(context.Background()))
+	archives, err = listRepoArchives(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, archives)
+}
@@ -38,6 +38,24 @@ func (cfg *UnitConfig) ToDB() ([]byte, error) {
 	return json.Marshal(cfg)
 }
 
+// WikiConfig describes wiki config
+type WikiConfig struct {
+	// IncludeTOC adds a table of contents at the top of the pages without a [[TOC]] marker
+	IncludeTOC bool `json:",omitempty"`
+}
+
+// FromDB fills up a WikiConfig from serialized format.
+func (cfg *WikiConfig) FromDB(bs []byte) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.Unmarshal(bs, &cfg)
+}
+
+// ToDB exports a WikiConfig to a serialized format.
+func (cfg *WikiConfig) ToDB() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.Marshal(cfg)
+}
+
 // ExternalWikiConfig describes external wiki config
 type ExternalWikiConfig struct {
 	ExternalWikiURL string
@@ -79,6 +97,8 @@ type IssuesConfig struct {
 	EnableTimetracker                bool
 	AllowOnlyContributorsToTrackTime bool
 	EnableDependencies               bool
+	// DefaultAssigneeIDs are the users new issues are assigned to if the poster doesn't choose assignees
+	DefaultAssigneeIDs []int64 `json:",omitempty"`
 }
 
 // FromDB fills up a IssuesConfig from serialized format.
@@ -102,6 +122,8 @@ type PullRequestsConfig struct {
 	AllowSquash               bool
 	AllowManualMerge          bool
 	AutodetectManualMerge     bool
+	// DefaultReviewerIDs are the users whose reviews are requested for new pull requests
+	DefaultReviewerIDs []int64 `json:",omitempty"`
 }
 
 // FromDB fills up a PullRequestsConfig from serialized format.
@@ -148,8 +170,10 @@ func (r *RepoUnit) BeforeSet(colName string, val xorm.Cell) {
 	switch colName {
 	case "type":
 		switch UnitType(Cell2Int64(val)) {
-		case UnitTypeCode, UnitTypeReleases, UnitTypeWiki, UnitTypeProjects:
+		case UnitTypeCode, UnitTypeReleases, UnitTypeProjects:
 			r.Config = new(UnitConfig)
+		case UnitTypeWiki:
+			r.Config = new(WikiConfig)
 		case UnitTypeExternalWiki:
 			r.Config = new(ExternalWikiConfig)
 		case UnitTypeExternalTracker:
@@ -184,6 +208,11 @@ func (r *RepoUnit) ReleasesConfig() *UnitConfig {
 	return r.Config.(*UnitConfig)
 }
 
+// WikiConfig returns config for UnitTypeWiki
+func (r *RepoUnit) WikiConfig() *WikiConfig {
+	return r.Config.(*WikiConfig)
+}
+
 // ExternalWikiConfig returns config for UnitTypeExternalWiki
 func (r *RepoUnit) ExternalWikiConfig() *ExternalWikiConfig {
 	return r.Config.(*ExternalWikiConfig)
@@ -0,0 +1,173 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/auth/saml"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GetActiveSAMLLoginSourceByName returns an active SAML LoginSource by its name
+func GetActiveSAMLLoginSourceByName(name string) (*LoginSource, error) {
+	loginSource := new(LoginSource)
+	has, err := x.Where("name = ? and type = ? and is_actived = ?", name, LoginSAML, true).Get(loginSource)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrLoginSourceNotExist{}
+	}
+	return loginSource, nil
+}
+
+// GetActiveSAMLProviders returns the sorted names of the active SAML login sources
+func GetActiveSAMLProviders() ([]string, error) {
+	sources, err := ActiveLoginSources(LoginSAML)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoginViaSAML returns the user who has been authenticated by the identity provider of the given
+// SAML source, the account is created on the first sign-in if the source is configured to do so.
+// The user is added to and removed from the teams the groups of the user are mapped to.
+func LoginViaSAML(source *LoginSource, su *saml.User) (*User, error) {
+	cfg := source.SAML()
+
+	user := &User{
+		LoginType:   LoginSAML,
+		LoginSource: source.ID,
+		LoginName:   su.NameID,
+	}
+	hasUser, err := GetUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasUser {
+		if !cfg.AutoRegister {
+			return nil, ErrUserNotExist{0, su.NameID, 0}
+		}
+
+		name := su.Username
+		if idx := strings.IndexByte(name, '@'); idx > 0 {
+			name = name[:idx]
+		}
+		email := su.Email
+		if len(email) == 0 {
+			if strings.Contains(su.NameID, "@") {
+				email = su.NameID
+			} else {
+				email = fmt.Sprintf("%s@localhost", name)
+			}
+		}
+		user = &User{
+			LowerName:   strings.ToLower(name),
+			Name:        name,
+			FullName:    su.FullName,
+			Email:       email,
+			LoginType:   LoginSAML,
+			LoginSource: source.ID,
+			LoginName:   su.NameID,
+			IsActive:    true,
+		}
+		if err = CreateUser(user); err != nil {
+			return nil, err
+		}
+	} else if len(su.FullName) > 0 && user.FullName != su.FullName {
+		user.FullName = su.FullName
+		if err = UpdateUserCols(user, "full_name"); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.ProhibitLogin {
+		return nil, ErrUserProhibitLogin{user.ID, user.Name}
+	}
+
+	if len(cfg.AttributeGroups) > 0 {
+		mapping, err := cfg.GroupTeamMapping()
+		if err != nil {
+			log.Error("Invalid group team map of SAML source %s: %v", source.Name, err)
+		} else if err := syncGroupTeams(user, su.Groups, mapping, cfg.GroupTeamMapRemoval); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// syncGroupTeams adds the user to the teams the given groups are mapped to,
+// and removes the user from the mapped teams of other groups if removeUnmapped is set.
+func syncGroupTeams(user *User, groups []string, mapping map[string]map[string][]string, removeUnmapped bool) error {
+	isMember := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		isMember[group] = true
+	}
+
+	// org name -> team name -> whether the user should be a member
+	wanted := make(map[string]map[string]bool)
+	for group, orgTeams := range mapping {
+		for orgName, teamNames := range orgTeams {
+			if wanted[orgName] == nil {
+				wanted[orgName] = make(map[string]bool)
+			}
+			for _, teamName := range teamNames {
+				wanted[orgName][teamName] = wanted[orgName][teamName] || isMember[group]
+			}
+		}
+	}
+
+	for orgName, teams := range wanted {
+		org, err := GetOrgByName(orgName)
+		if err != nil {
+			if IsErrOrgNotExist(err) {
+				log.Warn("Organization %s of the group team map does not exist", orgName)
+				continue
+			}
+			return err
+		}
+		for teamName, shouldBeMember := range teams {
+			if !shouldBeMember && !removeUnmapped {
+				continue
+			}
+			team, err := org.GetTeam(teamName)
+			if err != nil {
+				if IsErrTeamNotExist(err) {
+					log.Warn("Team %s of organization %s of the group team map does not exist", teamName, orgName)
+					continue
+				}
+				return err
+			}
+			isTeamMember, err := IsTeamMember(org.ID, team.ID, user.ID)
+			if err != nil {
+				return err
+			}
+			if shouldBeMember && !isTeamMember {
+				if err := AddTeamMember(team, user.ID); err != nil {
+					return err
+				}
+			} else if !shouldBeMember && isTeamMember {
+				if err := RemoveTeamMember(team, user.ID); err != nil {
+					if IsErrLastOrgOwner(err) {
+						log.Warn("Unable to remove the last owner %s from team %s of organization %s", user.Name, teamName, orgName)
+						continue
+					}
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
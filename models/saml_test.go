@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/saml"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginViaSAML(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	source := &LoginSource{
+		Type:      LoginSAML,
+		Name:      "idp",
+		IsActived: true,
+		Cfg: &SAMLConfig{&saml.Source{
+			AttributeGroups:     "groups",
+			GroupTeamMap:        `{"developers": {"user3": ["team1", "test_team"]}, "testers": {"user3": ["test_team"]}}`,
+			GroupTeamMapRemoval: true,
+		}},
+	}
+	assert.NoError(t, CreateLoginSource(source))
+
+	su := &saml.User{
+		NameID:   "jdoe",
+		Username: "john.doe@example.com",
+		FullName: "John Doe",
+		Groups:   []string{"developers"},
+	}
+
+	// unknown users are only created if enabled
+	_, err := LoginViaSAML(source, su)
+	assert.True(t, IsErrUserNotExist(err))
+
+	source.SAML().AutoRegister = true
+	u, err := LoginViaSAML(source, su)
+	assert.NoError(t, err)
+	assert.Equal(t, "john.doe", u.Name)
+	assert.Equal(t, "John Doe", u.FullName)
+	assert.Equal(t, "john.doe@localhost", u.Email)
+	assert.True(t, u.IsActive)
+	AssertExistsAndLoadBean(t, &User{ID: u.ID, LoginType: LoginSAML, LoginSource: source.ID, LoginName: "jdoe"})
+	AssertExistsAndLoadBean(t, &TeamUser{UID: u.ID, TeamID: 2})
+	AssertExistsAndLoadBean(t, &TeamUser{UID: u.ID, TeamID: 7})
+
+	// the existing account is used on the next sign-in, and removed from the teams of groups the user left
+	su.FullName = "Jane Doe"
+	su.Groups = []string{"testers"}
+	u2, err := LoginViaSAML(source, su)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, u2.ID)
+	assert.Equal(t, "Jane Doe", AssertExistsAndLoadBean(t, &User{ID: u.ID}).(*User).FullName)
+	AssertNotExistsBean(t, &TeamUser{UID: u.ID, TeamID: 2})
+	AssertExistsAndLoadBean(t, &TeamUser{UID: u.ID, TeamID: 7})
+
+	// memberships are kept if removal is not enabled
+	source.SAML().GroupTeamMapRemoval = false
+	su.Groups = nil
+	_, err = LoginViaSAML(source, su)
+	assert.NoError(t, err)
+	AssertExistsAndLoadBean(t, &TeamUser{UID: u.ID, TeamID: 7})
+}
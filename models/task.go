@@ -170,6 +170,39 @@ func GetMigratingTaskByID(id, doerID int64) (*Task, *migration.MigrateOptions, e
 	return &task, &opts, nil
 }
 
+// GetTaskByID returns the task by its id
+func GetTaskByID(id int64) (*Task, error) {
+	task := new(Task)
+	has, err := x.ID(id).Get(task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{ID: id}
+	}
+	return task, nil
+}
+
+// GetLastRepoTask returns the most recently created task of the given type of the repository
+func GetLastRepoTask(repoID int64, taskType structs.TaskType) (*Task, error) {
+	task := new(Task)
+	has, err := x.Where("repo_id = ? AND type = ?", repoID, taskType).Desc("id").Get(task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{0, repoID, taskType}
+	}
+	return task, nil
+}
+
+// GetUnfinishedTasks returns the tasks of the given type which are queued, running or stopped, oldest first
+func GetUnfinishedTasks(taskType structs.TaskType) ([]*Task, error) {
+	tasks := make([]*Task, 0, 10)
+	return tasks, x.Where("type = ?", taskType).
+		In("status", structs.TaskStatusQueue, structs.TaskStatusRunning, structs.TaskStatusStopped).
+		Asc("id").
+		Find(&tasks)
+}
+
 // FindTaskOptions find all tasks
 type FindTaskOptions struct {
 	Status int
@@ -147,6 +147,10 @@ type User struct {
 	AllowCreateOrganization bool `xorm:"DEFAULT true"`
 	ProhibitLogin           bool `xorm:"NOT NULL DEFAULT false"`
 
+	// Lockout after consecutive failed sign-in attempts
+	LoginFailures   int                `xorm:"NOT NULL DEFAULT 0"`
+	LockedUntilUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+
 	// Avatar
 	Avatar          string `xorm:"VARCHAR(2048) NOT NULL"`
 	AvatarEmail     string `xorm:"NOT NULL"`
@@ -166,6 +170,16 @@ type User struct {
 	MembersIsPublic           map[int64]bool      `xorm:"-"`
 	Visibility                structs.VisibleType `xorm:"NOT NULL DEFAULT 0"`
 	RepoAdminChangeTeamAccess bool                `xorm:"NOT NULL DEFAULT false"`
+	// MembersCanManageProjects lets all the members of the organization create and manage its projects,
+	// otherwise only its owners can
+	MembersCanManageProjects bool `xorm:"NOT NULL DEFAULT false"`
+	// RequireTwoFactor denies members without two-factor authentication access to the organization
+	RequireTwoFactor bool `xorm:"NOT NULL DEFAULT false"`
+	// DefaultRepoVisibility overrides the instance's default visibility of the new repositories of the organization,
+	// either "private" or "public", empty follows the instance
+	DefaultRepoVisibility string `xorm:"NOT NULL DEFAULT ''"`
+	// ForceRepoPrivate makes every new repository of the organization private
+	ForceRepoPrivate bool `xorm:"NOT NULL DEFAULT false"`
 
 	// Preferences
 	DiffViewStyle       string `xorm:"NOT NULL DEFAULT ''"`
@@ -261,27 +275,63 @@ func (u *User) HasForkedRepo(repoID int64) bool {
 	return has
 }
 
-// MaxCreationLimit returns the number of repositories a user is allowed to create
+// MaxCreationLimit returns the number of repositories a user or an organization is allowed to create
 func (u *User) MaxCreationLimit() int {
 	if u.MaxRepoCreation <= -1 {
+		if u.IsOrganization() {
+			return setting.Repository.OrgMaxCreationLimit
+		}
 		return setting.Repository.MaxCreationLimit
 	}
 	return u.MaxRepoCreation
 }
 
+// IsRepoPrivateForced returns whether the new repositories of the user or organization must be private
+func (u *User) IsRepoPrivateForced() bool {
+	return setting.Repository.ForcePrivate || (u.IsOrganization() && u.ForceRepoPrivate)
+}
+
+// IsRepoPrivateByDefault returns whether the new repositories of the user or organization are private
+// unless chosen otherwise, doer is the user creating them
+func (u *User) IsRepoPrivateByDefault(doer *User) bool {
+	if u.IsRepoPrivateForced() {
+		return true
+	}
+	defaultPrivate := setting.Repository.DefaultPrivate
+	if u.IsOrganization() && len(u.DefaultRepoVisibility) > 0 {
+		defaultPrivate = u.DefaultRepoVisibility
+	}
+	switch strings.ToLower(defaultPrivate) {
+	case setting.RepoCreatingPrivate:
+		return true
+	case setting.RepoCreatingPublic:
+		return false
+	default:
+		return doer.LastRepoVisibility
+	}
+}
+
 // CanCreateRepo returns if user login can create a repository
 // NOTE: functions calling this assume a failure due to repository count limit; if new checks are added, those functions should be revised
 func (u *User) CanCreateRepo() bool {
 	if u.IsAdmin {
 		return true
 	}
-	if u.MaxRepoCreation <= -1 {
-		if setting.Repository.MaxCreationLimit <= -1 {
-			return true
-		}
-		return u.NumRepos < setting.Repository.MaxCreationLimit
+	limit := u.MaxCreationLimit()
+	return limit <= -1 || u.NumRepos < limit
+}
+
+// RemainingRepoCreations returns the number of repositories the user or organization can still create,
+// -1 means no limit
+func (u *User) RemainingRepoCreations() int {
+	limit := u.MaxCreationLimit()
+	if u.IsAdmin || limit <= -1 {
+		return -1
 	}
-	return u.NumRepos < u.MaxRepoCreation
+	if u.NumRepos >= limit {
+		return 0
+	}
+	return limit - u.NumRepos
 }
 
 // CanCreateOrganization returns true if user can create organisation.
@@ -509,7 +559,7 @@ func (u *User) GetRepositoryIDs(units ...UnitType) ([]int64, error) {
 		sess = sess.In("repo_unit.type", units)
 	}
 
-	return ids, sess.Where("owner_id = ?", u.ID).Find(&ids)
+	return ids, sess.Where("owner_id = ? AND is_deleted = ?", u.ID, false).Find(&ids)
 }
 
 // GetActiveRepositoryIDs returns non-archived repositories IDs where user owned and has unittypes
@@ -524,7 +574,7 @@ func (u *User) GetActiveRepositoryIDs(units ...UnitType) ([]int64, error) {
 		sess = sess.In("repo_unit.type", units)
 	}
 
-	sess.Where(builder.Eq{"is_archived": false})
+	sess.Where(builder.Eq{"is_archived": false, "is_deleted": false})
 
 	return ids, sess.Where("owner_id = ?", u.ID).GroupBy("repository.id").Find(&ids)
 }
@@ -539,6 +589,7 @@ func (u *User) GetOrgRepositoryIDs(units ...UnitType) ([]int64, error) {
 		Join("INNER", "team_user", "repository.owner_id = team_user.org_id").
 		Join("INNER", "team_repo", "(? != ? and repository.is_private != ?) OR (team_user.team_id = team_repo.team_id AND repository.id = team_repo.repo_id)", true, u.IsRestricted, true).
 		Where("team_user.uid = ?", u.ID).
+		Where(builder.Eq{"repository.is_deleted": false}).
 		GroupBy("repository.id").Find(&ids); err != nil {
 		return nil, err
 	}
@@ -560,7 +611,7 @@ func (u *User) GetActiveOrgRepositoryIDs(units ...UnitType) ([]int64, error) {
 		Join("INNER", "team_user", "repository.owner_id = team_user.org_id").
 		Join("INNER", "team_repo", "(? != ? and repository.is_private != ?) OR (team_user.team_id = team_repo.team_id AND repository.id = team_repo.repo_id)", true, u.IsRestricted, true).
 		Where("team_user.uid = ?", u.ID).
-		Where(builder.Eq{"is_archived": false}).
+		Where(builder.Eq{"is_archived": false, "repository.is_deleted": false}).
 		GroupBy("repository.id").Find(&ids); err != nil {
 		return nil, err
 	}
@@ -857,48 +908,88 @@ func IsUsableUsername(name string) error {
 
 // CreateUser creates record of a new user.
 func CreateUser(u *User) (err error) {
-	if err = IsUsableUsername(u.Name); err != nil {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
 		return err
 	}
 
+	if err = createUser(sess, u); err != nil {
+		return err
+	}
+
+	return sess.Commit()
+}
+
+// CreateUsers creates the users in a single transaction. If one of them cannot be created none of them
+// is, and the error is an ErrBulkCreateUser holding the index of that user.
+func CreateUsers(users []*User) (err error) {
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
 		return err
 	}
 
-	isExist, err := isUserExist(sess, 0, u.Name)
-	if err != nil {
+	for i, u := range users {
+		if err = createUser(sess, u); err != nil {
+			return ErrBulkCreateUser{Index: i, Err: err}
+		}
+	}
+
+	return sess.Commit()
+}
+
+// CheckCreateUser returns the error CreateUser would return because of the name or the email of the user,
+// without creating it
+func CheckCreateUser(u *User) error {
+	return checkCreateUser(x, u)
+}
+
+func checkCreateUser(e Engine, u *User) error {
+	if err := IsUsableUsername(u.Name); err != nil {
 		return err
-	} else if isExist {
-		return ErrUserAlreadyExist{u.Name}
 	}
 
-	if err = deleteUserRedirect(sess, u.Name); err != nil {
+	isExist, err := isUserExist(e, 0, u.Name)
+	if err != nil {
 		return err
+	} else if isExist {
+		return ErrUserAlreadyExist{u.Name}
 	}
 
-	u.Email = strings.ToLower(u.Email)
-	isExist, err = sess.
-		Where("email=?", u.Email).
+	email := strings.ToLower(u.Email)
+	isExist, err = e.
+		Where("email=?", email).
 		Get(new(User))
 	if err != nil {
 		return err
 	} else if isExist {
-		return ErrEmailAlreadyUsed{u.Email}
+		return ErrEmailAlreadyUsed{email}
 	}
 
-	if err = ValidateEmail(u.Email); err != nil {
+	if err = ValidateEmail(email); err != nil {
 		return err
 	}
 
-	isExist, err = isEmailUsed(sess, u.Email)
+	isExist, err = isEmailUsed(e, email)
 	if err != nil {
 		return err
 	} else if isExist {
-		return ErrEmailAlreadyUsed{u.Email}
+		return ErrEmailAlreadyUsed{email}
+	}
+	return nil
+}
+
+func createUser(e Engine, u *User) (err error) {
+	if err = checkCreateUser(e, u); err != nil {
+		return err
+	}
+
+	if err = deleteUserRedirect(e, u.Name); err != nil {
+		return err
 	}
 
+	u.Email = strings.ToLower(u.Email)
 	u.KeepEmailPrivate = setting.Service.DefaultKeepEmailPrivate
 
 	u.LowerName = strings.ToLower(u.Name)
@@ -914,11 +1005,8 @@ func CreateUser(u *User) (err error) {
 	u.MaxRepoCreation = -1
 	u.Theme = setting.UI.DefaultTheme
 
-	if _, err = sess.Insert(u); err != nil {
-		return err
-	}
-
-	return sess.Commit()
+	_, err = e.Insert(u)
+	return err
 }
 
 func countUsers(e Engine) int64 {
@@ -1159,6 +1247,8 @@ func deleteUser(e Engine, u *User) error {
 
 	if err = deleteBeans(e,
 		&AccessToken{UID: u.ID},
+		&RememberToken{UID: u.ID},
+		&UserSession{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Access{UserID: u.ID},
 		&Watch{UserID: u.ID},
@@ -1173,6 +1263,7 @@ func deleteUser(e Engine, u *User) error {
 		&TeamUser{UID: u.ID},
 		&Collaboration{UserID: u.ID},
 		&Stopwatch{UserID: u.ID},
+		&WebAuthnCredential{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
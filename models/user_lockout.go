@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IsLocked returns true if the account is locked after too many failed sign-in attempts
+func (u *User) IsLocked() bool {
+	return u.LockedUntilUnix > timeutil.TimeStampNow()
+}
+
+// RecordLoginFailure counts a failed sign-in attempt of the user and locks the account
+// once setting.LoginLockoutThreshold consecutive attempts failed. It returns the time
+// the account got locked until, or 0 if it is not locked.
+func RecordLoginFailure(uid int64) (timeutil.TimeStamp, error) {
+	if setting.LoginLockoutThreshold <= 0 {
+		return 0, nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return 0, err
+	}
+	if _, err := sess.ID(uid).Incr("login_failures").NoAutoTime().Update(new(User)); err != nil {
+		return 0, err
+	}
+	u, err := getUserByID(sess, uid)
+	if err != nil {
+		return 0, err
+	}
+	if u.LoginFailures < setting.LoginLockoutThreshold {
+		return 0, sess.Commit()
+	}
+
+	u.LoginFailures = 0
+	u.LockedUntilUnix = timeutil.TimeStampNow().Add(int64(setting.LoginLockoutDuration.Seconds()))
+	if _, err := sess.ID(uid).Cols("login_failures", "locked_until_unix").NoAutoTime().Update(u); err != nil {
+		return 0, err
+	}
+	if err := createNotice(sess, NoticeSecurity, "Account %s has been locked until %s after %d failed sign-in attempts",
+		u.Name, u.LockedUntilUnix.FormatLong(), setting.LoginLockoutThreshold); err != nil {
+		return 0, err
+	}
+	return u.LockedUntilUnix, sess.Commit()
+}
+
+// ResetLoginFailures forgets the failed sign-in attempts of the user after a successful sign-in
+func ResetLoginFailures(u *User) error {
+	if u.LoginFailures == 0 {
+		return nil
+	}
+	u.LoginFailures = 0
+	_, err := x.ID(u.ID).Cols("login_failures").NoAutoTime().Update(u)
+	return err
+}
+
+// UnlockUser lifts the lockout of the account
+func UnlockUser(u *User) error {
+	u.LoginFailures = 0
+	u.LockedUntilUnix = 0
+	_, err := x.ID(u.ID).Cols("login_failures", "locked_until_unix").NoAutoTime().Update(u)
+	return err
+}
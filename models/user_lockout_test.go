@@ -0,0 +1,64 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defer func(threshold int, duration time.Duration) {
+		setting.LoginLockoutThreshold = threshold
+		setting.LoginLockoutDuration = duration
+	}(setting.LoginLockoutThreshold, setting.LoginLockoutDuration)
+	setting.LoginLockoutThreshold = 3
+	setting.LoginLockoutDuration = time.Hour
+
+	for i := 0; i < 2; i++ {
+		lockedUntil, err := RecordLoginFailure(2)
+		assert.NoError(t, err)
+		assert.Zero(t, lockedUntil)
+	}
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.Equal(t, 2, user.LoginFailures)
+	assert.False(t, user.IsLocked())
+
+	// a successful sign-in resets the consecutive failures
+	assert.NoError(t, ResetLoginFailures(user))
+	AssertExistsAndLoadBean(t, &User{ID: 2}, "login_failures = 0")
+
+	for i := 0; i < 2; i++ {
+		_, err := RecordLoginFailure(2)
+		assert.NoError(t, err)
+	}
+	lockedUntil, err := RecordLoginFailure(2)
+	assert.NoError(t, err)
+	assert.NotZero(t, lockedUntil)
+
+	user = AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.True(t, user.IsLocked())
+	assert.Equal(t, lockedUntil, user.LockedUntilUnix)
+	AssertExistsAndLoadBean(t, &Notice{Type: NoticeSecurity})
+
+	_, err = UserSignIn("user2", "password")
+	assert.True(t, IsErrUserLocked(err))
+
+	assert.NoError(t, UnlockUser(user))
+	_, err = UserSignIn("user2", "password")
+	assert.NoError(t, err)
+
+	setting.LoginLockoutThreshold = 0
+	lockedUntil, err = RecordLoginFailure(2)
+	assert.NoError(t, err)
+	assert.Zero(t, lockedUntil)
+	AssertExistsAndLoadBean(t, &User{ID: 2}, "login_failures = 0")
+}
@@ -0,0 +1,216 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// rememberTokenGracePeriod is the time a rotated validator is still accepted,
+// so that concurrent requests sent with the previous cookie do not revoke the token.
+const rememberTokenGracePeriod = time.Minute
+
+// RememberToken represents a long-lived "remember me" token of a single device.
+// The cookie holds "<lookup key>:<validator>", only a hash of the validator is stored.
+type RememberToken struct {
+	ID                int64  `xorm:"pk autoincr"`
+	UID               int64  `xorm:"INDEX NOT NULL"`
+	LookupKey         string `xorm:"UNIQUE NOT NULL"`
+	ValidatorHash     string `xorm:"NOT NULL"`
+	PrevValidatorHash string
+	// DeviceHash binds the token to the device it has been issued to
+	DeviceHash string `xorm:"NOT NULL"`
+	DeviceName string
+	LastIP     string
+	// UserStamp invalidates the token when the credentials of the user change
+	UserStamp string `xorm:"NOT NULL"`
+
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	LastUsedUnix timeutil.TimeStamp
+	ExpiresUnix  timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// IsExpired returns true if the token can no longer be used
+func (t *RememberToken) IsExpired() bool {
+	return t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+func hashRememberValue(value string) string {
+	h := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(h[:])
+}
+
+// RememberDeviceHash returns the fingerprint of a device identified by its user agent
+func RememberDeviceHash(userAgent string) string {
+	return hashRememberValue(userAgent)
+}
+
+func rememberUserStamp(u *User) string {
+	return hashRememberValue(u.Rands + u.Passwd)
+}
+
+// NewRememberToken creates a remember token for the given user and device,
+// it returns the value to be stored in the cookie.
+func NewRememberToken(u *User, userAgent, ip string, ttl time.Duration) (*RememberToken, string, error) {
+	lookupKey, err := generate.GetRandomString(20)
+	if err != nil {
+		return nil, "", err
+	}
+	validator, err := generate.GetRandomString(40)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	now := timeutil.TimeStampNow()
+	t := &RememberToken{
+		UID:           u.ID,
+		LookupKey:     lookupKey,
+		ValidatorHash: hashRememberValue(validator),
+		DeviceHash:    RememberDeviceHash(userAgent),
+		DeviceName:    userAgent,
+		LastIP:        ip,
+		UserStamp:     rememberUserStamp(u),
+		LastUsedUnix:  now,
+		ExpiresUnix:   now.AddDuration(ttl),
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, "", err
+	}
+	if _, err = sess.Where("uid = ? AND expires_unix <= ?", u.ID, now).Delete(new(RememberToken)); err != nil {
+		return nil, "", err
+	}
+	if _, err = sess.Insert(t); err != nil {
+		return nil, "", err
+	}
+	return t, lookupKey + ":" + validator, sess.Commit()
+}
+
+// UseRememberToken validates the cookie value of a remember token sent by the
+// given device and rotates its validator. It returns the owner of the token and
+// the new cookie value, which is empty if the cookie must be left unchanged.
+// A token presented with a stale validator or from another device is revoked,
+// without affecting the tokens of other devices.
+func UseRememberToken(value, userAgent, ip string, ttl time.Duration) (*User, string, error) {
+	idx := strings.IndexByte(value, ':')
+	if idx <= 0 {
+		return nil, "", ErrRememberTokenInvalid{"malformed"}
+	}
+	lookupKey, validator := value[:idx], value[idx+1:]
+
+	t := &RememberToken{LookupKey: lookupKey}
+	has, err := x.Get(t)
+	if err != nil {
+		return nil, "", err
+	} else if !has {
+		return nil, "", ErrRememberTokenInvalid{"not exist"}
+	}
+
+	revoke := func(reason string) (*User, string, error) {
+		if _, err := x.ID(t.ID).Delete(new(RememberToken)); err != nil {
+			return nil, "", err
+		}
+		return nil, "", ErrRememberTokenInvalid{reason}
+	}
+
+	if t.IsExpired() {
+		return revoke("expired")
+	}
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	if subtle.ConstantTimeCompare([]byte(t.DeviceHash), []byte(RememberDeviceHash(userAgent))) != 1 {
+		return revoke("device mismatch")
+	}
+
+	u, err := GetUserByID(t.UID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return revoke("user not exist")
+		}
+		return nil, "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(t.UserStamp), []byte(rememberUserStamp(u))) != 1 {
+		return revoke("credentials changed")
+	}
+
+	validatorHash := hashRememberValue(validator)
+	if subtle.ConstantTimeCompare([]byte(t.ValidatorHash), []byte(validatorHash)) != 1 {
+		if len(t.PrevValidatorHash) > 0 &&
+			subtle.ConstantTimeCompare([]byte(t.PrevValidatorHash), []byte(validatorHash)) == 1 &&
+			t.LastUsedUnix.AddDuration(rememberTokenGracePeriod) > timeutil.TimeStampNow() {
+			return u, "", nil
+		}
+		return revoke("validator mismatch")
+	}
+
+	newValidator, err := generate.GetRandomString(40)
+	if err != nil {
+		return nil, "", err
+	}
+	now := timeutil.TimeStampNow()
+	t.PrevValidatorHash = t.ValidatorHash
+	t.ValidatorHash = hashRememberValue(newValidator)
+	t.LastIP = ip
+	t.LastUsedUnix = now
+	t.ExpiresUnix = now.AddDuration(ttl)
+	if _, err = x.ID(t.ID).Cols("validator_hash", "prev_validator_hash", "last_ip", "last_used_unix", "expires_unix").Update(t); err != nil {
+		return nil, "", err
+	}
+	return u, lookupKey + ":" + newValidator, nil
+}
+
+// GetRememberTokensByUID returns the remember tokens of all devices of the given user which have not expired yet
+func GetRememberTokensByUID(uid int64) ([]*RememberToken, error) {
+	tokens := make([]*RememberToken, 0, 5)
+	return tokens, x.
+		Where("uid = ? AND expires_unix > ?", uid, timeutil.TimeStampNow()).
+		Desc("last_used_unix").
+		Find(&tokens)
+}
+
+// DeleteRememberToken revokes the remember token with the given ID of the given user
+func DeleteRememberToken(uid, id int64) error {
+	_, err := x.Where("id = ? AND uid = ?", id, uid).Delete(new(RememberToken))
+	return err
+}
+
+// DeleteRememberTokenByValue revokes the remember token identified by the given cookie value
+func DeleteRememberTokenByValue(value string) error {
+	lookupKey := RememberLookupKey(value)
+	if lookupKey == "" {
+		return nil
+	}
+	_, err := x.Delete(&RememberToken{LookupKey: lookupKey})
+	return err
+}
+
+// RememberLookupKey returns the lookup key of the remember token in the given cookie value,
+// empty if the value is malformed
+func RememberLookupKey(value string) string {
+	idx := strings.IndexByte(value, ':')
+	if idx <= 0 {
+		return ""
+	}
+	return value[:idx]
+}
+
+// MatchesCookie returns true if the given cookie value belongs to the token
+func (t *RememberToken) MatchesCookie(value string) bool {
+	lookupKey := RememberLookupKey(value)
+	return lookupKey != "" && lookupKey == t.LookupKey
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRememberToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	const ua = "Mozilla/5.0 (X11; Linux x86_64)"
+
+	_, value, err := NewRememberToken(user, ua, "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+	_, other, err := NewRememberToken(user, "curl/7.68.0", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+
+	tokens, err := GetRememberTokensByUID(user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 2)
+
+	// using the token rotates it
+	u, rotated, err := UseRememberToken(value, ua, "127.0.0.2", time.Hour)
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.ID, u.ID)
+	assert.NotEmpty(t, rotated)
+	assert.NotEqual(t, value, rotated)
+
+	// the previous value is still accepted within the grace period, without rotation
+	u, unchanged, err := UseRememberToken(value, ua, "127.0.0.2", time.Hour)
+	assert.NoError(t, err)
+	assert.EqualValues(t, user.ID, u.ID)
+	assert.Empty(t, unchanged)
+
+	// the token is bound to its device
+	_, _, err = UseRememberToken(rotated, "Other/1.0", "127.0.0.3", time.Hour)
+	assert.True(t, IsErrRememberTokenInvalid(err))
+	_, _, err = UseRememberToken(rotated, ua, "127.0.0.2", time.Hour)
+	assert.True(t, IsErrRememberTokenInvalid(err))
+
+	// other devices are not affected
+	_, _, err = UseRememberToken(other, "curl/7.68.0", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+
+	// changing the credentials invalidates all tokens
+	_, value, err = NewRememberToken(user, ua, "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+	user.Rands = "changed"
+	assert.NoError(t, UpdateUserCols(user, "rands"))
+	_, _, err = UseRememberToken(value, ua, "127.0.0.1", time.Hour)
+	assert.True(t, IsErrRememberTokenInvalid(err))
+
+	_, _, err = UseRememberToken("malformed", ua, "127.0.0.1", time.Hour)
+	assert.True(t, IsErrRememberTokenInvalid(err))
+}
+
+func TestDeleteRememberToken(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	token, value, err := NewRememberToken(user, "ua", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+	assert.True(t, token.MatchesCookie(value))
+
+	// tokens of other users cannot be deleted
+	assert.NoError(t, DeleteRememberToken(1, token.ID))
+	AssertExistsAndLoadBean(t, &RememberToken{ID: token.ID})
+
+	// a missing ID does not delete all the tokens of the user
+	assert.NoError(t, DeleteRememberToken(user.ID, 0))
+	AssertExistsAndLoadBean(t, &RememberToken{ID: token.ID})
+
+	assert.NoError(t, DeleteRememberToken(user.ID, token.ID))
+	AssertNotExistsBean(t, &RememberToken{ID: token.ID})
+
+	token, value, err = NewRememberToken(user, "ua", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, DeleteRememberTokenByValue(value))
+	AssertNotExistsBean(t, &RememberToken{ID: token.ID})
+}
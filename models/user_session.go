@@ -0,0 +1,163 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// userSessionSeenInterval is the time the last activity of a session is not updated for,
+// to avoid writing to the database on every request.
+const userSessionSeenInterval = time.Minute
+
+// UserSession represents a signed-in session of a user, which can be listed and revoked by the user.
+// Only a hash of the session ID is stored, as the ID itself is a credential.
+type UserSession struct {
+	ID        int64  `xorm:"pk autoincr"`
+	UID       int64  `xorm:"INDEX NOT NULL"`
+	KeyHash   string `xorm:"UNIQUE NOT NULL"`
+	UserAgent string
+	LastIP    string
+	// RememberLookupKey identifies the remember token of the device the session is used on, if any
+	RememberLookupKey string `xorm:"INDEX"`
+
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	LastSeenUnix timeutil.TimeStamp `xorm:"INDEX"`
+}
+
+// HashSessionKey returns the hash a session is stored with
+func HashSessionKey(sid string) string {
+	return hashRememberValue(sid)
+}
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > 255 {
+		return userAgent[:255]
+	}
+	return userAgent
+}
+
+// CreateUserSession starts tracking the session of a user, used on the device remembered by the
+// remember token with the given lookup key if it is not empty.
+// The sessions of the user which have not been seen since the given time are removed.
+func CreateUserSession(uid int64, keyHash, rememberLookupKey, userAgent, ip string, since timeutil.TimeStamp) (*UserSession, error) {
+	s := &UserSession{
+		UID:               uid,
+		KeyHash:           keyHash,
+		UserAgent:         truncateUserAgent(userAgent),
+		LastIP:            ip,
+		RememberLookupKey: rememberLookupKey,
+		LastSeenUnix:      timeutil.TimeStampNow(),
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Where("uid = ? AND last_seen_unix < ?", uid, since).Delete(new(UserSession)); err != nil {
+		return nil, err
+	}
+	if _, err := sess.Insert(s); err != nil {
+		return nil, err
+	}
+	return s, sess.Commit()
+}
+
+// TouchUserSession records the activity of a tracked session of a user.
+// The session is identified by the hash it has been tracked with, which is
+// replaced by newKeyHash if the ID of the session has been regenerated.
+// It returns ErrUserSessionNotExist if the session has been revoked.
+func TouchUserSession(uid int64, keyHash, newKeyHash, rememberLookupKey, userAgent, ip string) error {
+	s := &UserSession{KeyHash: keyHash}
+	if has, err := x.Get(s); err != nil {
+		return err
+	} else if !has || s.UID != uid {
+		return ErrUserSessionNotExist{UID: uid}
+	}
+
+	userAgent = truncateUserAgent(userAgent)
+	now := timeutil.TimeStampNow()
+	if s.KeyHash == newKeyHash && s.UserAgent == userAgent && s.LastIP == ip &&
+		s.RememberLookupKey == rememberLookupKey && s.LastSeenUnix.AddDuration(userSessionSeenInterval) > now {
+		return nil
+	}
+
+	s.KeyHash = newKeyHash
+	s.UserAgent = userAgent
+	s.LastIP = ip
+	s.RememberLookupKey = rememberLookupKey
+	s.LastSeenUnix = now
+	_, err := x.ID(s.ID).Cols("key_hash", "user_agent", "last_ip", "remember_lookup_key", "last_seen_unix").Update(s)
+	return err
+}
+
+// GetUserSessions returns the sessions of the given user which have been seen since the given time
+func GetUserSessions(uid int64, since timeutil.TimeStamp) ([]*UserSession, error) {
+	sessions := make([]*UserSession, 0, 5)
+	return sessions, x.
+		Where("uid = ? AND last_seen_unix >= ?", uid, since).
+		Desc("last_seen_unix").
+		Find(&sessions)
+}
+
+// DeleteUserSession revokes the session with the given ID of the given user,
+// the remembered device of the session is revoked as well so that it has to sign in again.
+func DeleteUserSession(uid, id int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	s := new(UserSession)
+	if has, err := sess.Where("id = ? AND uid = ?", id, uid).Get(s); err != nil {
+		return err
+	} else if !has {
+		return ErrUserSessionNotExist{UID: uid}
+	}
+	if _, err := sess.ID(s.ID).Delete(new(UserSession)); err != nil {
+		return err
+	}
+	if s.RememberLookupKey != "" {
+		if _, err := sess.Delete(&RememberToken{UID: uid, LookupKey: s.RememberLookupKey}); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+// DeleteOtherUserSessions revokes all sessions and remembered devices of the given user,
+// except the session with the given hash and the device remembered by the given cookie value.
+func DeleteOtherUserSessions(uid int64, keepKeyHash, keepRememberCookie string) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where(builder.Eq{"uid": uid}.And(builder.Neq{"key_hash": keepKeyHash})).
+		Delete(new(UserSession)); err != nil {
+		return err
+	}
+	if _, err := sess.Where(builder.Eq{"uid": uid}.And(builder.Neq{"lookup_key": RememberLookupKey(keepRememberCookie)})).
+		Delete(new(RememberToken)); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// DeleteUserSessionByKeyHash stops tracking the session with the given hash, e.g. when it signs out
+func DeleteUserSessionByKeyHash(keyHash string) error {
+	if keyHash == "" {
+		return nil
+	}
+	_, err := x.Delete(&UserSession{KeyHash: keyHash})
+	return err
+}
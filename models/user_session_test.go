@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSession(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	const laptop = "Mozilla/5.0 (X11; Linux x86_64)"
+	since := timeutil.TimeStampNow().Add(-3600)
+
+	_, laptopCookie, err := NewRememberToken(user, laptop, "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+	// another browser of the same kind, remembered as well
+	_, otherLaptopCookie, err := NewRememberToken(user, laptop, "127.0.0.3", time.Hour)
+	assert.NoError(t, err)
+	_, phoneCookie, err := NewRememberToken(user, "Phone", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+
+	s1, err := CreateUserSession(user.ID, HashSessionKey("sid1"), RememberLookupKey(laptopCookie), laptop, "127.0.0.1", since)
+	assert.NoError(t, err)
+	_, err = CreateUserSession(user.ID, HashSessionKey("sid2"), RememberLookupKey(phoneCookie), "Phone", "127.0.0.1", since)
+	assert.NoError(t, err)
+	_, err = CreateUserSession(user.ID, HashSessionKey("sid3"), "", "Tablet", "127.0.0.1", since)
+	assert.NoError(t, err)
+
+	sessions, err := GetUserSessions(user.ID, since)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	assert.NoError(t, TouchUserSession(user.ID, HashSessionKey("sid1"), HashSessionKey("sid1"), RememberLookupKey(laptopCookie), laptop, "127.0.0.2"))
+	AssertExistsAndLoadBean(t, &UserSession{ID: s1.ID, LastIP: "127.0.0.2"})
+	assert.True(t, IsErrUserSessionNotExist(TouchUserSession(1, HashSessionKey("sid1"), HashSessionKey("sid1"), "", laptop, "127.0.0.2")))
+
+	// revoking a session revokes the remembered device of the session as well, and only it
+	assert.True(t, IsErrUserSessionNotExist(DeleteUserSession(user.ID, 0)))
+	assert.NoError(t, DeleteUserSession(user.ID, s1.ID))
+	assert.True(t, IsErrUserSessionNotExist(TouchUserSession(user.ID, HashSessionKey("sid1"), HashSessionKey("sid1"), RememberLookupKey(laptopCookie), laptop, "127.0.0.2")))
+	_, _, err = UseRememberToken(laptopCookie, laptop, "127.0.0.1", time.Hour)
+	assert.True(t, IsErrRememberTokenInvalid(err))
+	_, _, err = UseRememberToken(otherLaptopCookie, laptop, "127.0.0.3", time.Hour)
+	assert.NoError(t, err)
+
+	// revoking the other sessions keeps the current one and its remembered device
+	assert.NoError(t, DeleteOtherUserSessions(user.ID, HashSessionKey("sid2"), phoneCookie))
+	sessions, err = GetUserSessions(user.ID, since)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, HashSessionKey("sid2"), sessions[0].KeyHash)
+	}
+	_, _, err = UseRememberToken(phoneCookie, "Phone", "127.0.0.1", time.Hour)
+	assert.NoError(t, err)
+
+	assert.NoError(t, DeleteUserSessionByKeyHash(HashSessionKey("sid2")))
+	AssertNotExistsBean(t, &UserSession{UID: user.ID})
+}
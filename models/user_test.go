@@ -96,6 +96,89 @@ func TestCanCreateOrganization(t *testing.T) {
 	assert.False(t, user.CanCreateOrganization())
 }
 
+func TestCanCreateRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defer func(userLimit, orgLimit int) {
+		setting.Repository.MaxCreationLimit = userLimit
+		setting.Repository.OrgMaxCreationLimit = orgLimit
+	}(setting.Repository.MaxCreationLimit, setting.Repository.OrgMaxCreationLimit)
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+
+	setting.Repository.MaxCreationLimit = -1
+	setting.Repository.OrgMaxCreationLimit = -1
+	assert.True(t, user.CanCreateRepo())
+	assert.Equal(t, -1, user.RemainingRepoCreations())
+
+	// user2 owns 9 repositories and org3 owns 3
+	setting.Repository.MaxCreationLimit = 10
+	assert.True(t, user.CanCreateRepo())
+	assert.Equal(t, 1, user.RemainingRepoCreations())
+	assert.True(t, org.CanCreateRepo())
+	assert.Equal(t, -1, org.RemainingRepoCreations())
+
+	setting.Repository.OrgMaxCreationLimit = 3
+	assert.False(t, org.CanCreateRepo())
+	assert.Equal(t, 0, org.RemainingRepoCreations())
+	assert.Equal(t, 3, org.MaxCreationLimit())
+
+	setting.Repository.MaxCreationLimit = 0
+	assert.False(t, user.CanCreateRepo())
+	assert.True(t, admin.CanCreateRepo())
+	assert.Equal(t, -1, admin.RemainingRepoCreations())
+
+	// the limit set by an admin overrides the global one
+	user.MaxRepoCreation = 12
+	assert.True(t, user.CanCreateRepo())
+	assert.Equal(t, 3, user.RemainingRepoCreations())
+	org.MaxRepoCreation = 0
+	setting.Repository.OrgMaxCreationLimit = -1
+	assert.False(t, org.CanCreateRepo())
+}
+
+func TestIsRepoPrivateByDefault(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	defer func(forcePrivate bool, defaultPrivate string) {
+		setting.Repository.ForcePrivate = forcePrivate
+		setting.Repository.DefaultPrivate = defaultPrivate
+	}(setting.Repository.ForcePrivate, setting.Repository.DefaultPrivate)
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	org := AssertExistsAndLoadBean(t, &User{ID: 3}).(*User)
+
+	setting.Repository.ForcePrivate = false
+	setting.Repository.DefaultPrivate = setting.RepoCreatingLastUserVisibility
+	user.LastRepoVisibility = true
+	assert.True(t, user.IsRepoPrivateByDefault(user))
+	assert.True(t, org.IsRepoPrivateByDefault(user))
+	assert.False(t, org.IsRepoPrivateForced())
+
+	setting.Repository.DefaultPrivate = setting.RepoCreatingPublic
+	assert.False(t, user.IsRepoPrivateByDefault(user))
+	assert.False(t, org.IsRepoPrivateByDefault(user))
+
+	// the default of an organization overrides the instance's one
+	org.DefaultRepoVisibility = setting.RepoCreatingPrivate
+	assert.True(t, org.IsRepoPrivateByDefault(user))
+	setting.Repository.DefaultPrivate = setting.RepoCreatingPrivate
+	org.DefaultRepoVisibility = setting.RepoCreatingPublic
+	assert.False(t, org.IsRepoPrivateByDefault(user))
+
+	org.ForceRepoPrivate = true
+	assert.True(t, org.IsRepoPrivateForced())
+	assert.True(t, org.IsRepoPrivateByDefault(user))
+	assert.False(t, user.IsRepoPrivateForced())
+
+	setting.Repository.ForcePrivate = true
+	org.ForceRepoPrivate = false
+	assert.True(t, user.IsRepoPrivateForced())
+	assert.True(t, org.IsRepoPrivateForced())
+}
+
 func TestSearchUsers(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	testSuccess := func(opts *SearchUserOptions, expectedUserOrOrgIDs []int64) {
@@ -322,6 +405,30 @@ func TestCreateUser(t *testing.T) {
 	assert.NoError(t, DeleteUser(user))
 }
 
+func TestCreateUsers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	// the second user reuses the email of the first one, so none of them is created
+	err := CreateUsers([]*User{
+		{Name: "bulk1", Email: "bulk@gitea.io", Passwd: ";p['////..-++']"},
+		{Name: "bulk2", Email: "Bulk@gitea.io", Passwd: ";p['////..-++']"},
+	})
+	assert.True(t, IsErrBulkCreateUser(err))
+	assert.Equal(t, 1, err.(ErrBulkCreateUser).Index)
+	assert.True(t, IsErrEmailAlreadyUsed(err.(ErrBulkCreateUser).Err))
+	AssertNotExistsBean(t, &User{Name: "bulk1"})
+
+	assert.True(t, IsErrUserAlreadyExist(CheckCreateUser(&User{Name: "user2", Email: "bulk@gitea.io"})))
+	assert.NoError(t, CheckCreateUser(&User{Name: "bulk1", Email: "bulk@gitea.io"}))
+
+	assert.NoError(t, CreateUsers([]*User{
+		{Name: "bulk1", Email: "bulk1@gitea.io", Passwd: ";p['////..-++']"},
+		{Name: "bulk2", Email: "bulk2@gitea.io", Passwd: ";p['////..-++']"},
+	}))
+	AssertExistsAndLoadBean(t, &User{Name: "bulk1"})
+	AssertExistsAndLoadBean(t, &User{Name: "bulk2"})
+}
+
 func TestCreateUserInvalidEmail(t *testing.T) {
 	user := &User{
 		Name:               "GiteaBot",
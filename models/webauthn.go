@@ -0,0 +1,156 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// WebAuthnCredential represents a WebAuthn credential registered by a user
+type WebAuthnCredential struct {
+	ID              int64 `xorm:"pk autoincr"`
+	Name            string
+	UserID          int64  `xorm:"INDEX"`
+	CredentialID    string `xorm:"INDEX VARCHAR(410)"`
+	PublicKey       []byte
+	Algorithm       int64
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32             `xorm:"BIGINT"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName returns a better table name for WebAuthnCredential
+func (cred WebAuthnCredential) TableName() string {
+	return "webauthn_credential"
+}
+
+// ToCredential converts the db entry WebAuthnCredential to a webauthn.Credential
+func (cred *WebAuthnCredential) ToCredential() *webauthn.Credential {
+	id, _ := webauthn.DecodeString(cred.CredentialID)
+	return &webauthn.Credential{
+		ID:              id,
+		PublicKey:       cred.PublicKey,
+		Algorithm:       cred.Algorithm,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+	}
+}
+
+// IsLegacyU2F returns true if the credential was registered through the U2F API
+func (cred *WebAuthnCredential) IsLegacyU2F() bool {
+	return cred.AttestationType == "fido-u2f" && len(cred.AAGUID) == 0
+}
+
+// UpdateSignCount will update the database value of the signature counter
+func (cred *WebAuthnCredential) UpdateSignCount() error {
+	_, err := x.ID(cred.ID).Cols("sign_count").Update(cred)
+	return err
+}
+
+// WebAuthnCredentialList is a list of *WebAuthnCredential
+type WebAuthnCredentialList []*WebAuthnCredential
+
+// ToCredentials will convert all WebAuthnCredentials to webauthn.Credentials
+func (list WebAuthnCredentialList) ToCredentials() []*webauthn.Credential {
+	creds := make([]*webauthn.Credential, 0, len(list))
+	for _, cred := range list {
+		creds = append(creds, cred.ToCredential())
+	}
+	return creds
+}
+
+func getWebAuthnCredentialsByUID(e Engine, uid int64) (WebAuthnCredentialList, error) {
+	creds := make(WebAuthnCredentialList, 0)
+	return creds, e.Where("user_id = ?", uid).Find(&creds)
+}
+
+// GetWebAuthnCredentialsByUID returns all WebAuthn credentials of the given user
+func GetWebAuthnCredentialsByUID(uid int64) (WebAuthnCredentialList, error) {
+	return getWebAuthnCredentialsByUID(x, uid)
+}
+
+// HasWebAuthnCredentialsByUID returns whether the given user has registered WebAuthn credentials
+func HasWebAuthnCredentialsByUID(uid int64) (bool, error) {
+	return x.Where("user_id = ?", uid).Exist(&WebAuthnCredential{})
+}
+
+// GetWebAuthnCredentialByID returns WebAuthn credential by id
+func GetWebAuthnCredentialByID(id int64) (*WebAuthnCredential, error) {
+	cred := new(WebAuthnCredential)
+	if found, err := x.ID(id).Get(cred); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrWebAuthnCredentialNotExist{ID: id}
+	}
+	return cred, nil
+}
+
+func createWebAuthnCredential(e Engine, userID int64, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	c := &WebAuthnCredential{
+		UserID:          userID,
+		Name:            name,
+		CredentialID:    webauthn.EncodeToString(cred.ID),
+		PublicKey:       cred.PublicKey,
+		Algorithm:       cred.Algorithm,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.AAGUID,
+		SignCount:       cred.SignCount,
+	}
+	if _, err := e.InsertOne(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateWebAuthnCredential will create a new WebAuthnCredential from the given Credential
+func CreateWebAuthnCredential(user *User, name string, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	return createWebAuthnCredential(x, user.ID, name, cred)
+}
+
+// DeleteWebAuthnCredential will delete WebAuthnCredential
+func DeleteWebAuthnCredential(cred *WebAuthnCredential) error {
+	_, err := x.Delete(cred)
+	return err
+}
+
+// LegacyU2FCredentials converts the U2F registrations of a user to WebAuthn credentials,
+// so that they can be used through WebAuthn. Registrations that can not be parsed are skipped.
+func (list U2FRegistrationList) LegacyU2FCredentials() map[int64]*webauthn.Credential {
+	creds := make(map[int64]*webauthn.Credential, len(list))
+	for _, reg := range list {
+		r, err := reg.Parse()
+		if err != nil {
+			continue
+		}
+		cred, err := webauthn.CredentialFromU2F(r.KeyHandle, &r.PubKey, reg.Counter)
+		if err != nil {
+			continue
+		}
+		creds[reg.ID] = cred
+	}
+	return creds
+}
+
+// UpgradeU2FRegistration replaces a U2F registration by a WebAuthn credential
+// with the same key after it has been used through WebAuthn.
+func UpgradeU2FRegistration(reg *U2FRegistration, cred *webauthn.Credential) (*WebAuthnCredential, error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return nil, err
+	}
+	c, err := createWebAuthnCredential(sess, reg.UserID, reg.Name, cred)
+	if err != nil {
+		return nil, err
+	}
+	if err := deleteRegistration(sess, reg); err != nil {
+		return nil, err
+	}
+	return c, sess.Commit()
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/auth/webauthn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWebAuthnCredentialByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "WebAuthn Key", res.Name)
+	assert.Equal(t, []byte{1, 2, 3, 4}, res.ToCredential().ID)
+
+	_, err = GetWebAuthnCredentialByID(342432)
+	assert.Error(t, err)
+	assert.True(t, IsErrWebAuthnCredentialNotExist(err))
+}
+
+func TestGetWebAuthnCredentialsByUID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	res, err := GetWebAuthnCredentialsByUID(1)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, "WebAuthn Key", res[0].Name)
+
+	has, err := HasWebAuthnCredentialsByUID(1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = HasWebAuthnCredentialsByUID(2)
+	assert.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestWebAuthnCredential_UpdateSignCount(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	cred := AssertExistsAndLoadBean(t, &WebAuthnCredential{ID: 1}).(*WebAuthnCredential)
+	cred.SignCount = 0xffffffff
+	assert.NoError(t, cred.UpdateSignCount())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: 1, SignCount: 0xffffffff})
+}
+
+func TestCreateAndDeleteWebAuthnCredential(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	cred, err := CreateWebAuthnCredential(user, "New Key", &webauthn.Credential{ID: []byte{5, 6}, Algorithm: webauthn.AlgEdDSA, AttestationType: "none"})
+	assert.NoError(t, err)
+	assert.Equal(t, "BQY", cred.CredentialID)
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: cred.ID, UserID: 2, Name: "New Key"})
+
+	assert.NoError(t, DeleteWebAuthnCredential(cred))
+	AssertExistsIf(t, false, &WebAuthnCredential{ID: cred.ID})
+}
+
+func TestUpgradeU2FRegistration(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	reg := AssertExistsAndLoadBean(t, &U2FRegistration{ID: 1}).(*U2FRegistration)
+
+	cred, err := UpgradeU2FRegistration(reg, &webauthn.Credential{ID: []byte{7}, Algorithm: webauthn.AlgES256, AttestationType: "fido-u2f", SignCount: 3})
+	assert.NoError(t, err)
+	assert.True(t, cred.IsLegacyU2F())
+	AssertExistsIf(t, true, &WebAuthnCredential{ID: cred.ID, UserID: reg.UserID, Name: reg.Name, SignCount: 3})
+	AssertExistsIf(t, false, &U2FRegistration{ID: 1})
+}
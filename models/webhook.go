@@ -77,6 +77,26 @@ func IsValidHookContentType(name string) bool {
 	return ok
 }
 
+// HookSignatureType is the HMAC algorithm used to sign the payloads of a hook
+type HookSignatureType = string
+
+// Types of hook signatures
+const (
+	// HookSignatureDefault signs with HMAC-SHA256 in the X-Gitea-Signature header only
+	HookSignatureDefault HookSignatureType = ""
+	HookSignatureSHA1    HookSignatureType = "sha1"
+	HookSignatureSHA256  HookSignatureType = "sha256"
+)
+
+// IsValidHookSignatureType returns true if given name is a valid hook signature type.
+func IsValidHookSignatureType(name string) bool {
+	switch name {
+	case HookSignatureDefault, HookSignatureSHA1, HookSignatureSHA256:
+		return true
+	}
+	return false
+}
+
 // HookEvents is a set of web hook events
 type HookEvents struct {
 	Create               bool `json:"create"`
@@ -97,6 +117,10 @@ type HookEvents struct {
 	PullRequestSync      bool `json:"pull_request_sync"`
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
+	Status               bool `json:"status"`
+	CommitComment        bool `json:"commit_comment"`
+	Team                 bool `json:"team"`
+	Membership           bool `json:"membership"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -129,8 +153,9 @@ type Webhook struct {
 	Signature       string `xorm:"TEXT"`
 	HTTPMethod      string `xorm:"http_method"`
 	ContentType     HookContentType
-	Secret          string `xorm:"TEXT"`
-	Events          string `xorm:"TEXT"`
+	Secret          string            `xorm:"TEXT"`
+	SignatureType   HookSignatureType `xorm:"VARCHAR(16)"`
+	Events          string            `xorm:"TEXT"`
 	*HookEvent      `xorm:"-"`
 	IsSSL           bool         `xorm:"is_ssl"`
 	IsActive        bool         `xorm:"INDEX"`
@@ -285,6 +310,30 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasStatusEvent returns if hook enabled commit status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
+// HasCommitCommentEvent returns if hook enabled commit comment event.
+func (w *Webhook) HasCommitCommentEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.CommitComment)
+}
+
+// HasTeamEvent returns if hook enabled team event.
+func (w *Webhook) HasTeamEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Team)
+}
+
+// HasMembershipEvent returns if hook enabled membership event.
+func (w *Webhook) HasMembershipEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Membership)
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
@@ -314,6 +363,10 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasPullRequestSyncEvent, HookEventPullRequestSync},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasStatusEvent, HookEventStatus},
+		{w.HasCommitCommentEvent, HookEventCommitComment},
+		{w.HasTeamEvent, HookEventTeam},
+		{w.HasMembershipEvent, HookEventMembership},
 	}
 }
 
@@ -468,6 +521,13 @@ func UpdateWebhook(w *Webhook) error {
 	return err
 }
 
+// SetWebhookActive pauses or resumes the deliveries of a webhook without changing its configuration.
+func SetWebhookActive(w *Webhook, isActive bool) error {
+	w.IsActive = isActive
+	_, err := x.ID(w.ID).Cols("is_active").Update(w)
+	return err
+}
+
 // UpdateWebhookLastStatus updates last status of webhook.
 func UpdateWebhookLastStatus(w *Webhook) error {
 	_, err := x.ID(w.ID).Cols("last_status").Update(w)
@@ -563,15 +623,18 @@ type HookTaskType = string
 
 // Types of hook tasks
 const (
-	GITEA    HookTaskType = "gitea"
-	GOGS     HookTaskType = "gogs"
-	SLACK    HookTaskType = "slack"
-	DISCORD  HookTaskType = "discord"
-	DINGTALK HookTaskType = "dingtalk"
-	TELEGRAM HookTaskType = "telegram"
-	MSTEAMS  HookTaskType = "msteams"
-	FEISHU   HookTaskType = "feishu"
-	MATRIX   HookTaskType = "matrix"
+	GITEA      HookTaskType = "gitea"
+	GOGS       HookTaskType = "gogs"
+	SLACK      HookTaskType = "slack"
+	DISCORD    HookTaskType = "discord"
+	DINGTALK   HookTaskType = "dingtalk"
+	TELEGRAM   HookTaskType = "telegram"
+	MSTEAMS    HookTaskType = "msteams"
+	FEISHU     HookTaskType = "feishu"
+	MATRIX     HookTaskType = "matrix"
+	MATTERMOST HookTaskType = "mattermost"
+	GOOGLECHAT HookTaskType = "googlechat"
+	JSON       HookTaskType = "json"
 )
 
 // HookEventType is the type of an hook event
@@ -599,6 +662,10 @@ const (
 	HookEventPullRequestSync           HookEventType = "pull_request_sync"
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
+	HookEventStatus                    HookEventType = "status"
+	HookEventCommitComment             HookEventType = "commit_comment"
+	HookEventTeam                      HookEventType = "team"
+	HookEventMembership                HookEventType = "membership"
 )
 
 // Event returns the HookEventType as an event string
@@ -629,6 +696,14 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventStatus:
+		return "status"
+	case HookEventCommitComment:
+		return "commit_comment"
+	case HookEventTeam:
+		return "team"
+	case HookEventMembership:
+		return "membership"
 	}
 	return ""
 }
@@ -640,9 +715,10 @@ type HookRequest struct {
 
 // HookResponse represents hook task response information.
 type HookResponse struct {
-	Status  int               `json:"status"`
-	Headers map[string]string `json:"headers"`
-	Body    string            `json:"body"`
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body"`
+	Duration time.Duration     `json:"duration"`
 }
 
 // HookTask represents a hook task.
@@ -651,9 +727,10 @@ type HookTask struct {
 	RepoID          int64 `xorm:"INDEX"`
 	HookID          int64
 	UUID            string
-	Typ             HookTaskType `xorm:"VARCHAR(16) index"`
-	URL             string       `xorm:"TEXT"`
-	Signature       string       `xorm:"TEXT"`
+	Typ             HookTaskType      `xorm:"VARCHAR(16) index"`
+	URL             string            `xorm:"TEXT"`
+	Signature       string            `xorm:"TEXT"`
+	SignatureType   HookSignatureType `xorm:"VARCHAR(16)"`
 	api.Payloader   `xorm:"-"`
 	PayloadContent  string `xorm:"TEXT"`
 	HTTPMethod      string `xorm:"http_method"`
@@ -664,6 +741,10 @@ type HookTask struct {
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
 
+	// Retry info, each attempt to deliver an event is a task of its own.
+	Attempt       int                `xorm:"NOT NULL DEFAULT 1"`
+	ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+
 	// History info.
 	IsSucceed       bool
 	RequestContent  string        `xorm:"TEXT"`
@@ -724,6 +805,23 @@ func HookTasks(hookID int64, page int) ([]*HookTask, error) {
 		Find(&tasks)
 }
 
+// CountHookTasks returns the number of hook tasks of a webhook.
+func CountHookTasks(hookID int64) (int64, error) {
+	return x.Where("hook_id=?", hookID).Count(new(HookTask))
+}
+
+// GetHookTaskByUUID returns the hook task of a webhook by its delivery UUID.
+func GetHookTaskByUUID(hookID int64, uuid string) (*HookTask, error) {
+	t := &HookTask{HookID: hookID, UUID: uuid}
+	has, err := x.Desc("id").Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{HookID: hookID, UUID: uuid}
+	}
+	return t, nil
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -737,6 +835,9 @@ func createHookTask(e Engine, t *HookTask) error {
 	}
 	t.UUID = gouuid.New().String()
 	t.PayloadContent = string(data)
+	if t.Attempt == 0 {
+		t.Attempt = 1
+	}
 	_, err = e.Insert(t)
 	return err
 }
@@ -747,19 +848,61 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
-// FindUndeliveredHookTasks represents find the undelivered hook tasks
+// CreateHookTaskRetry creates a new undelivered task retrying the delivery
+// of the given task, which is not sent before the scheduled time.
+func CreateHookTaskRetry(t *HookTask, scheduled timeutil.TimeStamp) (*HookTask, error) {
+	retry := t.copyForDelivery()
+	retry.Attempt = t.Attempt + 1
+	retry.ScheduledUnix = scheduled
+	if _, err := x.Insert(retry); err != nil {
+		return nil, err
+	}
+	return retry, nil
+}
+
+// CreateHookTaskRedelivery creates a new undelivered task sending the
+// same payload and signature as the given task once more.
+func CreateHookTaskRedelivery(t *HookTask) (*HookTask, error) {
+	redelivery := t.copyForDelivery()
+	redelivery.Attempt = 1
+	if _, err := x.Insert(redelivery); err != nil {
+		return nil, err
+	}
+	return redelivery, nil
+}
+
+// copyForDelivery returns an undelivered copy of the task with a new delivery UUID,
+// the payload is not regenerated so that the signature stays the same.
+func (t *HookTask) copyForDelivery() *HookTask {
+	return &HookTask{
+		RepoID:         t.RepoID,
+		HookID:         t.HookID,
+		UUID:           gouuid.New().String(),
+		Typ:            t.Typ,
+		URL:            t.URL,
+		Signature:      t.Signature,
+		SignatureType:  t.SignatureType,
+		PayloadContent: t.PayloadContent,
+		HTTPMethod:     t.HTTPMethod,
+		ContentType:    t.ContentType,
+		EventType:      t.EventType,
+		IsSSL:          t.IsSSL,
+	}
+}
+
+// FindUndeliveredHookTasks represents find the undelivered hook tasks which are due
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
-	if err := x.Where("is_delivered=?", false).Find(&tasks); err != nil {
+	if err := x.Where("is_delivered=? AND scheduled_unix<=?", false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
-// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository
+// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository which are due
 func FindRepoUndeliveredHookTasks(repoID int64) ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 5)
-	if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+	if err := x.Where("repo_id=? AND is_delivered=? AND scheduled_unix<=?", repoID, false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
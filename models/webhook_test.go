@@ -10,6 +10,7 @@ import (
 	"time"
 
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
@@ -69,7 +70,8 @@ func TestWebhook_EventsArray(t *testing.T) {
 		"issues", "issue_assign", "issue_label", "issue_milestone", "issue_comment",
 		"pull_request", "pull_request_assign", "pull_request_label", "pull_request_milestone",
 		"pull_request_comment", "pull_request_review_approved", "pull_request_review_rejected",
-		"pull_request_review_comment", "pull_request_sync", "repository", "release",
+		"pull_request_review_comment", "pull_request_sync", "repository", "release", "status",
+		"commit_comment", "team", "membership",
 	},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
@@ -216,6 +218,68 @@ func TestCreateHookTask(t *testing.T) {
 	AssertExistsAndLoadBean(t, hookTask)
 }
 
+func TestCreateHookTaskRetry(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask := &HookTask{
+		RepoID:    3,
+		HookID:    3,
+		Typ:       GITEA,
+		URL:       "http://www.example.com/unit_test",
+		Payloader: &api.PushPayload{},
+	}
+	assert.NoError(t, CreateHookTask(hookTask))
+	assert.Equal(t, 1, hookTask.Attempt)
+
+	retry, err := CreateHookTaskRetry(hookTask, timeutil.TimeStampNow()+3600)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hookTask.UUID, retry.UUID)
+	assert.Equal(t, 2, retry.Attempt)
+	assert.Equal(t, hookTask.PayloadContent, retry.PayloadContent)
+	AssertExistsAndLoadBean(t, &HookTask{ID: retry.ID, HookID: 3, Attempt: 2})
+
+	// a retry is not due before its scheduled time
+	tasks, err := FindRepoUndeliveredHookTasks(3)
+	assert.NoError(t, err)
+	for _, task := range tasks {
+		assert.NotEqual(t, retry.ID, task.ID)
+	}
+}
+
+func TestCreateHookTaskRedelivery(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hookTask := &HookTask{
+		RepoID:        3,
+		HookID:        3,
+		Typ:           GITEA,
+		URL:           "http://www.example.com/unit_test",
+		Signature:     "signature",
+		SignatureType: HookSignatureSHA256,
+		Payloader:     &api.PushPayload{},
+		Attempt:       3,
+	}
+	assert.NoError(t, CreateHookTask(hookTask))
+
+	redelivery, err := CreateHookTaskRedelivery(hookTask)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hookTask.UUID, redelivery.UUID)
+	assert.Equal(t, 1, redelivery.Attempt)
+	assert.Equal(t, hookTask.PayloadContent, redelivery.PayloadContent)
+	assert.Equal(t, hookTask.Signature, redelivery.Signature)
+	assert.Equal(t, hookTask.SignatureType, redelivery.SignatureType)
+	assert.False(t, redelivery.IsDelivered)
+
+	loaded, err := GetHookTaskByUUID(3, redelivery.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, redelivery.ID, loaded.ID)
+
+	_, err = GetHookTaskByUUID(1, redelivery.UUID)
+	assert.True(t, IsErrHookTaskNotExist(err))
+
+	count, err := CountHookTasks(3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
+
 func TestUpdateHookTask(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -339,3 +403,22 @@ func TestCleanupHookTaskTable_OlderThan_LeavesTaskEarlierThanAgeToDelete(t *test
 	assert.NoError(t, CleanupHookTaskTable(context.Background(), OlderThan, 168*time.Hour, 0))
 	AssertExistsAndLoadBean(t, hookTask)
 }
+
+func TestSetWebhookActive(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.True(t, hook.IsActive)
+
+	assert.NoError(t, SetWebhookActive(hook, false))
+	hook, err := GetWebhookByID(1)
+	assert.NoError(t, err)
+	assert.False(t, hook.IsActive)
+	hooks, err := GetActiveWebhooksByRepoID(hook.RepoID)
+	assert.NoError(t, err)
+	for _, h := range hooks {
+		assert.NotEqual(t, hook.ID, h.ID)
+	}
+
+	assert.NoError(t, SetWebhookActive(hook, true))
+	AssertExistsAndLoadBean(t, &Webhook{ID: 1, IsActive: true})
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package activitypub provides read-only ActivityPub documents for users and
+// repositories.
+package activitypub
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	// ContentType is the media type of ActivityPub documents
+	ContentType = "application/activity+json"
+	// ActivityStreamsContext is the JSON-LD context of ActivityStreams
+	ActivityStreamsContext = "https://www.w3.org/ns/activitystreams"
+	// ForgeFedContext is the JSON-LD context of the ForgeFed vocabulary
+	ForgeFedContext = "https://forgefed.org/ns"
+)
+
+// Image represents an ActivityStreams image
+type Image struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Actor represents an ActivityPub actor
+type Actor struct {
+	Context           []string `json:"@context"`
+	ID                string   `json:"id"`
+	Type              string   `json:"type"`
+	PreferredUsername string   `json:"preferredUsername"`
+	Name              string   `json:"name,omitempty"`
+	Summary           string   `json:"summary,omitempty"`
+	URL               string   `json:"url"`
+	Icon              *Image   `json:"icon,omitempty"`
+	Outbox            string   `json:"outbox"`
+	AttributedTo      string   `json:"attributedTo,omitempty"`
+	Published         string   `json:"published,omitempty"`
+}
+
+// Activity represents an ActivityStreams activity
+type Activity struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Actor     string `json:"actor"`
+	Summary   string `json:"summary"`
+	Object    string `json:"object"`
+	Published string `json:"published"`
+}
+
+// OrderedCollection represents an ActivityStreams ordered collection
+type OrderedCollection struct {
+	Context      []string    `json:"@context"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	TotalItems   int         `json:"totalItems"`
+	OrderedItems []*Activity `json:"orderedItems"`
+}
+
+// IsActivityPubRequest returns whether the client asked for an ActivityPub
+// document rather than a web page.
+func IsActivityPubRequest(req *http.Request) bool {
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if mediaType == ContentType {
+			return true
+		}
+		if mediaType == "application/ld+json" && params["profile"] == ActivityStreamsContext {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes an ActivityPub document to the response.
+func Write(w http.ResponseWriter, v interface{}) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsActivityPubRequest(t *testing.T) {
+	for accept, expected := range map[string]bool{
+		"":                                false,
+		"text/html,application/xhtml+xml": false,
+		"application/json":                false,
+		"application/activity+json":       true,
+		"text/html, application/activity+json;q=0.9":                           true,
+		`application/ld+json; profile="https://www.w3.org/ns/activitystreams"`: true,
+		"application/ld+json": false,
+	} {
+		req, _ := http.NewRequest("GET", "/user2", nil)
+		req.Header.Set("Accept", accept)
+		assert.Equal(t, expected, IsActivityPubRequest(req), accept)
+	}
+}
+
+func TestActors(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	actor := UserActor(user)
+	assert.Equal(t, setting.AppURL+"user2", actor.ID)
+	assert.Equal(t, "Person", actor.Type)
+	assert.Equal(t, "user2", actor.PreferredUsername)
+	assert.Equal(t, setting.AppURL+"api/v1/activitypub/user/user2/outbox", actor.Outbox)
+
+	org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+	assert.Equal(t, "Organization", UserActor(org).Type)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	actor = RepoActor(repo)
+	assert.Equal(t, setting.AppURL+"user2/repo1", actor.ID)
+	assert.Equal(t, "Repository", actor.Type)
+	assert.Equal(t, setting.AppURL+"user2", actor.AttributedTo)
+	assert.Equal(t, setting.AppURL+"api/v1/activitypub/repo/user2/repo1/outbox", actor.Outbox)
+}
+
+func TestOutbox(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 9}).(*models.Repository)
+	actions, err := models.GetPublicRepoFeeds(repo)
+	assert.NoError(t, err)
+
+	outbox := Outbox(RepoOutboxURL(repo), actions)
+	assert.Equal(t, "OrderedCollection", outbox.Type)
+	if assert.Equal(t, 1, outbox.TotalItems) && assert.Len(t, outbox.OrderedItems, 1) {
+		activity := outbox.OrderedItems[0]
+		assert.Equal(t, RepoOutboxURL(repo)+"#3", activity.ID)
+		assert.Equal(t, "Create", activity.Type)
+		assert.Equal(t, setting.AppURL+"user11", activity.Actor)
+		assert.Equal(t, setting.AppURL+"user11/repo9", activity.Object)
+	}
+}
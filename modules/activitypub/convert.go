@@ -0,0 +1,140 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UserOutboxURL returns the URL of the outbox of a user or organization
+func UserOutboxURL(u *models.User) string {
+	return setting.AppURL + "api/v1/activitypub/user/" + u.Name + "/outbox"
+}
+
+// RepoOutboxURL returns the URL of the outbox of a repository
+func RepoOutboxURL(repo *models.Repository) string {
+	return setting.AppURL + "api/v1/activitypub/repo/" + repo.FullName() + "/outbox"
+}
+
+// UserActor converts a user or organization to an ActivityPub actor
+func UserActor(u *models.User) *Actor {
+	actorType := "Person"
+	if u.IsOrganization() {
+		actorType = "Organization"
+	}
+	return &Actor{
+		Context:           []string{ActivityStreamsContext},
+		ID:                u.HTMLURL(),
+		Type:              actorType,
+		PreferredUsername: u.Name,
+		Name:              u.FullName,
+		Summary:           u.Description,
+		URL:               u.HTMLURL(),
+		Icon:              &Image{Type: "Image", URL: u.AvatarLink()},
+		Outbox:            UserOutboxURL(u),
+		Published:         u.CreatedUnix.AsTime().UTC().Format(time.RFC3339),
+	}
+}
+
+// RepoActor converts a repository to a ForgeFed repository actor
+func RepoActor(repo *models.Repository) *Actor {
+	actor := &Actor{
+		Context:           []string{ActivityStreamsContext, ForgeFedContext},
+		ID:                repo.HTMLURL(),
+		Type:              "Repository",
+		PreferredUsername: repo.Name,
+		Name:              repo.FullName(),
+		Summary:           repo.Description,
+		URL:               repo.HTMLURL(),
+		Outbox:            RepoOutboxURL(repo),
+		AttributedTo:      setting.AppURL + repo.OwnerName,
+		Published:         repo.CreatedUnix.AsTime().UTC().Format(time.RFC3339),
+	}
+	if link := repo.AvatarLink(); link != "" {
+		actor.Icon = &Image{Type: "Image", URL: link}
+	}
+	return actor
+}
+
+// activityVerbs maps action types to the activity type and the verb used in
+// the summary. Actions that are not listed are not published.
+var activityVerbs = map[models.ActionType][2]string{
+	models.ActionCreateRepo:          {"Create", "created repository"},
+	models.ActionRenameRepo:          {"Update", "renamed repository"},
+	models.ActionCommitRepo:          {"Update", "pushed to"},
+	models.ActionCreateIssue:         {"Create", "opened an issue in"},
+	models.ActionCreatePullRequest:   {"Create", "opened a pull request in"},
+	models.ActionTransferRepo:        {"Update", "transferred repository"},
+	models.ActionPushTag:             {"Create", "pushed a tag to"},
+	models.ActionCommentIssue:        {"Create", "commented on an issue in"},
+	models.ActionMergePullRequest:    {"Update", "merged a pull request in"},
+	models.ActionCloseIssue:          {"Update", "closed an issue in"},
+	models.ActionReopenIssue:         {"Update", "reopened an issue in"},
+	models.ActionClosePullRequest:    {"Update", "closed a pull request in"},
+	models.ActionReopenPullRequest:   {"Update", "reopened a pull request in"},
+	models.ActionDeleteTag:           {"Delete", "deleted a tag in"},
+	models.ActionDeleteBranch:        {"Delete", "deleted a branch in"},
+	models.ActionMirrorSyncPush:      {"Update", "synced commits to"},
+	models.ActionMirrorSyncCreate:    {"Create", "synced a new reference to"},
+	models.ActionMirrorSyncDelete:    {"Delete", "synced a deleted reference in"},
+	models.ActionApprovePullRequest:  {"Create", "approved a pull request in"},
+	models.ActionRejectPullRequest:   {"Create", "requested changes on a pull request in"},
+	models.ActionCommentPull:         {"Create", "commented on a pull request in"},
+	models.ActionPublishRelease:      {"Create", "published a release in"},
+	models.ActionPullReviewDismissed: {"Update", "dismissed a review in"},
+}
+
+// issueActions are the action types whose object is an issue or pull request
+var issueActions = map[models.ActionType]bool{
+	models.ActionCreateIssue:         true,
+	models.ActionCreatePullRequest:   true,
+	models.ActionCommentIssue:        true,
+	models.ActionMergePullRequest:    true,
+	models.ActionCloseIssue:          true,
+	models.ActionReopenIssue:         true,
+	models.ActionClosePullRequest:    true,
+	models.ActionReopenPullRequest:   true,
+	models.ActionApprovePullRequest:  true,
+	models.ActionRejectPullRequest:   true,
+	models.ActionCommentPull:         true,
+	models.ActionPullReviewDismissed: true,
+}
+
+// Outbox converts a list of actions to an outbox collection. The actions must
+// have their attributes loaded.
+func Outbox(id string, actions []*models.Action) *OrderedCollection {
+	outbox := &OrderedCollection{
+		Context:      []string{ActivityStreamsContext},
+		ID:           id,
+		Type:         "OrderedCollection",
+		OrderedItems: make([]*Activity, 0, len(actions)),
+	}
+	for _, action := range actions {
+		verb, ok := activityVerbs[action.OpType]
+		if !ok {
+			continue
+		}
+		object := setting.AppURL + action.GetRepoPath()
+		if issueActions[action.OpType] {
+			if infos := action.GetIssueInfos(); len(infos) > 0 && infos[0] != "" {
+				object += "/issues/" + infos[0]
+			}
+		}
+		outbox.OrderedItems = append(outbox.OrderedItems, &Activity{
+			ID:        fmt.Sprintf("%s#%d", id, action.ID),
+			Type:      verb[0],
+			Actor:     setting.AppURL + action.GetActUserName(),
+			Summary:   fmt.Sprintf("%s %s %s", action.GetActUserName(), verb[1], action.GetRepoPath()),
+			Object:    object,
+			Published: action.GetCreate().UTC().Format(time.RFC3339),
+		})
+	}
+	outbox.TotalItems = len(outbox.OrderedItems)
+	return outbox
+}
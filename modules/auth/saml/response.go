@@ -0,0 +1,310 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// maxClockSkew is the tolerated difference between the clocks of the identity provider and Gitea
+const maxClockSkew = 3 * time.Minute
+
+type xmlStatusCode struct {
+	Value      string         `xml:",attr"`
+	StatusCode *xmlStatusCode `xml:"urn:oasis:names:tc:SAML:2.0:protocol StatusCode"`
+}
+
+type xmlResponse struct {
+	XMLName      xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol Response"`
+	ID           string   `xml:",attr"`
+	InResponseTo string   `xml:",attr"`
+	Destination  string   `xml:",attr"`
+	Issuer       string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Status       struct {
+		StatusCode    xmlStatusCode `xml:"urn:oasis:names:tc:SAML:2.0:protocol StatusCode"`
+		StatusMessage string        `xml:"urn:oasis:names:tc:SAML:2.0:protocol StatusMessage"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:protocol Status"`
+}
+
+type xmlSubjectConfirmation struct {
+	Method string `xml:",attr"`
+	Data   struct {
+		InResponseTo string    `xml:",attr"`
+		Recipient    string    `xml:",attr"`
+		NotOnOrAfter time.Time `xml:",attr"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion SubjectConfirmationData"`
+}
+
+type xmlAttribute struct {
+	Name   string   `xml:",attr"`
+	Values []string `xml:"urn:oasis:names:tc:SAML:2.0:assertion AttributeValue"`
+}
+
+type xmlAssertion struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	ID      string   `xml:",attr"`
+	Issuer  string   `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Subject struct {
+		NameID               string                   `xml:"urn:oasis:names:tc:SAML:2.0:assertion NameID"`
+		SubjectConfirmations []xmlSubjectConfirmation `xml:"urn:oasis:names:tc:SAML:2.0:assertion SubjectConfirmation"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion Subject"`
+	Conditions *struct {
+		NotBefore            time.Time `xml:",attr"`
+		NotOnOrAfter         time.Time `xml:",attr"`
+		AudienceRestrictions []struct {
+			Audiences []string `xml:"urn:oasis:names:tc:SAML:2.0:assertion Audience"`
+		} `xml:"urn:oasis:names:tc:SAML:2.0:assertion AudienceRestriction"`
+	} `xml:"urn:oasis:names:tc:SAML:2.0:assertion Conditions"`
+	Attributes []xmlAttribute `xml:"urn:oasis:names:tc:SAML:2.0:assertion AttributeStatement>Attribute"`
+}
+
+var (
+	usedAssertionsLock sync.Mutex
+	usedAssertions     = make(map[string]time.Time)
+)
+
+// markAssertionUsed records that the assertion with the given ID has been consumed until it expires,
+// it returns false if the assertion has been consumed before.
+func markAssertionUsed(id string, expires, now time.Time) bool {
+	usedAssertionsLock.Lock()
+	defer usedAssertionsLock.Unlock()
+
+	for usedID, usedExpires := range usedAssertions {
+		if usedExpires.Before(now) {
+			delete(usedAssertions, usedID)
+		}
+	}
+	if _, ok := usedAssertions[id]; ok {
+		return false
+	}
+	usedAssertions[id] = expires
+	return true
+}
+
+func unmarshalElement(el *etree.Element, v interface{}) error {
+	doc := etree.NewDocument()
+	doc.SetRoot(el)
+	bs, err := doc.WriteToBytes()
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(bs, v)
+}
+
+func hasSignature(el *etree.Element) bool {
+	for _, child := range el.ChildElements() {
+		if child.Tag == "Signature" && child.NamespaceURI() == dsig.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSignature returns the response and its assertion as covered by the signature of
+// the identity provider, either the response or the assertion has to be signed.
+func (source *Source) validateSignature(root *etree.Element, now time.Time) (*xmlResponse, *xmlAssertion, error) {
+	if root.Tag != "Response" || root.NamespaceURI() != ProtocolNamespace {
+		return nil, nil, ErrInvalidResponse{"not a response"}
+	}
+
+	var assertionEl *etree.Element
+	for _, child := range root.ChildElements() {
+		if child.NamespaceURI() != AssertionNamespace {
+			continue
+		}
+		switch child.Tag {
+		case "EncryptedAssertion":
+			return nil, nil, ErrInvalidResponse{"encrypted assertions are not supported"}
+		case "Assertion":
+			if assertionEl != nil {
+				return nil, nil, ErrInvalidResponse{"multiple assertions"}
+			}
+			assertionEl = child
+		}
+	}
+
+	certs, err := source.Certificates()
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: certs})
+	ctx.Clock = dsig.NewFakeClockAt(now)
+
+	resp := new(xmlResponse)
+	if hasSignature(root) {
+		validated, err := ctx.Validate(root)
+		if err != nil {
+			return nil, nil, ErrInvalidResponse{"signature: " + err.Error()}
+		}
+		if err := unmarshalElement(validated, resp); err != nil {
+			return nil, nil, err
+		}
+		assertionEl = validated.FindElement("./Assertion")
+		if assertionEl == nil || assertionEl.NamespaceURI() != AssertionNamespace {
+			return resp, nil, ErrInvalidResponse{"no assertion"}
+		}
+	} else {
+		if assertionEl == nil {
+			return nil, nil, ErrInvalidResponse{"no assertion"}
+		}
+		if !hasSignature(assertionEl) {
+			return nil, nil, ErrInvalidResponse{"neither the response nor the assertion is signed"}
+		}
+		if assertionEl, err = ctx.Validate(assertionEl); err != nil {
+			return nil, nil, ErrInvalidResponse{"signature: " + err.Error()}
+		}
+		if err := unmarshalElement(root.Copy(), resp); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	assertion := new(xmlAssertion)
+	if err := unmarshalElement(assertionEl.Copy(), assertion); err != nil {
+		return nil, nil, err
+	}
+	return resp, assertion, nil
+}
+
+// ParseResponse decodes and validates the base64 encoded response the identity provider
+// has posted to the assertion consumer service at acsURL. isRequested reports whether the
+// request with the given ID, which the response answers, has been made by Gitea and consumes it.
+func (source *Source) ParseResponse(encoded, entityID, acsURL string, isRequested func(requestID string) bool, now time.Time) (*User, error) {
+	bs, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidResponse{"not base64 encoded"}
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(bs); err != nil || doc.Root() == nil {
+		return nil, ErrInvalidResponse{"not a XML document"}
+	}
+
+	resp, assertion, err := source.validateSignature(doc.Root(), now)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Status.StatusCode.Value != StatusSuccess {
+		reason := "status " + resp.Status.StatusCode.Value
+		if resp.Status.StatusCode.StatusCode != nil {
+			reason += " (" + resp.Status.StatusCode.StatusCode.Value + ")"
+		}
+		if resp.Status.StatusMessage != "" {
+			reason += ": " + resp.Status.StatusMessage
+		}
+		return nil, ErrInvalidResponse{reason}
+	}
+	if resp.Destination != "" && resp.Destination != acsURL {
+		return nil, ErrInvalidResponse{"wrong destination " + resp.Destination}
+	}
+	if resp.Issuer != "" && source.IdentityProviderEntityID != "" && resp.Issuer != source.IdentityProviderEntityID {
+		return nil, ErrInvalidResponse{"wrong issuer " + resp.Issuer}
+	}
+
+	if source.IdentityProviderEntityID != "" && assertion.Issuer != source.IdentityProviderEntityID {
+		return nil, ErrInvalidResponse{"wrong issuer " + assertion.Issuer}
+	}
+	expires := now.Add(maxClockSkew)
+	if c := assertion.Conditions; c != nil {
+		if !c.NotBefore.IsZero() && now.Add(maxClockSkew).Before(c.NotBefore) {
+			return nil, ErrInvalidResponse{"assertion is not valid yet"}
+		}
+		if !c.NotOnOrAfter.IsZero() {
+			if !now.Add(-maxClockSkew).Before(c.NotOnOrAfter) {
+				return nil, ErrInvalidResponse{"assertion has expired"}
+			}
+			expires = c.NotOnOrAfter.Add(maxClockSkew)
+		}
+		for _, restriction := range c.AudienceRestrictions {
+			found := false
+			for _, audience := range restriction.Audiences {
+				if audience == entityID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, ErrInvalidResponse{"Gitea is not an audience of the assertion"}
+			}
+		}
+	}
+
+	confirmed := false
+	for _, confirmation := range assertion.Subject.SubjectConfirmations {
+		if confirmation.Method != bearerConfirmationMethod {
+			continue
+		}
+		data := confirmation.Data
+		if data.Recipient != "" && data.Recipient != acsURL {
+			continue
+		}
+		if data.InResponseTo != resp.InResponseTo {
+			continue
+		}
+		if !data.NotOnOrAfter.IsZero() && !now.Add(-maxClockSkew).Before(data.NotOnOrAfter) {
+			continue
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return nil, ErrInvalidResponse{"subject is not confirmed"}
+	}
+
+	nameID := strings.TrimSpace(assertion.Subject.NameID)
+	if nameID == "" {
+		return nil, ErrInvalidResponse{"no name ID"}
+	}
+	if resp.InResponseTo == "" {
+		if !source.AllowIDPInitiated {
+			return nil, ErrInvalidResponse{"responses which have not been requested are not allowed"}
+		}
+	} else if isRequested == nil || !isRequested(resp.InResponseTo) {
+		return nil, ErrInvalidResponse{"response to an unknown request"}
+	}
+	if !markAssertionUsed(assertion.ID, expires, now) {
+		return nil, ErrInvalidResponse{"assertion has already been used"}
+	}
+
+	return source.user(nameID, assertion.Attributes), nil
+}
+
+// user maps the attributes of an assertion to the information about the user
+func (source *Source) user(nameID string, attributes []xmlAttribute) *User {
+	values := make(map[string][]string, len(attributes))
+	for _, attr := range attributes {
+		for _, value := range attr.Values {
+			if value = strings.TrimSpace(value); value != "" {
+				values[attr.Name] = append(values[attr.Name], value)
+			}
+		}
+	}
+	first := func(name string) string {
+		if name == "" || len(values[name]) == 0 {
+			return ""
+		}
+		return values[name][0]
+	}
+
+	u := &User{
+		NameID:   nameID,
+		Username: first(source.AttributeUsername),
+		Email:    first(source.AttributeEmail),
+		FullName: first(source.AttributeFullName),
+	}
+	if u.Username == "" {
+		u.Username = nameID
+	}
+	if source.AttributeGroups != "" {
+		u.Groups = values[source.AttributeGroups]
+	}
+	return u
+}
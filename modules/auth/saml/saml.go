@@ -0,0 +1,237 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package saml provides the functions to sign in with a SAML 2.0 identity provider,
+// with Gitea acting as the service provider.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// XML namespaces and identifiers used by SAML 2.0
+const (
+	ProtocolNamespace  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	AssertionNamespace = "urn:oasis:names:tc:SAML:2.0:assertion"
+	MetadataNamespace  = "urn:oasis:names:tc:SAML:2.0:metadata"
+
+	HTTPPostBinding     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	HTTPRedirectBinding = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+
+	NameIDFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	StatusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+	bearerConfirmationMethod = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// Source holds the configuration of a SAML 2.0 identity provider
+type Source struct {
+	IdentityProviderEntityID    string // Entity ID of the identity provider, the expected issuer of its responses
+	IdentityProviderSSOURL      string // Single sign-on URL of the identity provider, using the HTTP-Redirect binding
+	IdentityProviderCertificate string // PEM encoded certificate the identity provider signs its responses with
+	ServiceProviderEntityID     string // Entity ID of Gitea, defaults to the URL of its metadata
+	AttributeUsername           string // Username attribute, the name ID is used if empty
+	AttributeEmail              string // E-mail attribute
+	AttributeFullName           string // Full name attribute
+	AttributeGroups             string // Groups attribute
+	GroupTeamMap                string // JSON map of groups to the teams of organizations their members belong to
+	GroupTeamMapRemoval         bool   // Remove users from the mapped teams of groups they are not member of
+	AutoRegister                bool   // Create an account for unknown users on their first sign-in
+	AllowIDPInitiated           bool   // Accept responses which have not been requested by Gitea
+}
+
+// User holds the information about a user of the identity provider
+type User struct {
+	NameID   string
+	Username string
+	Email    string
+	FullName string
+	Groups   []string
+}
+
+// Certificates returns the certificates the identity provider signs its responses with
+func (source *Source) Certificates() ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(strings.TrimSpace(source.IdentityProviderCertificate))
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+// GroupTeamMapping returns the parsed GroupTeamMap of the source:
+// a map of group names to the names of the teams of organizations (org name -> team names)
+func (source *Source) GroupTeamMapping() (map[string]map[string][]string, error) {
+	mapping := make(map[string]map[string][]string)
+	if strings.TrimSpace(source.GroupTeamMap) == "" {
+		return mapping, nil
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(source.GroupTeamMap), &mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// NewRequestID returns a random ID for an authentication request
+func NewRequestID() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// IDs must not start with a digit
+	return "id-" + hex.EncodeToString(buf), nil
+}
+
+type issuer struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Issuer"`
+	Value   string   `xml:",chardata"`
+}
+
+type nameIDPolicy struct {
+	XMLName     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol NameIDPolicy"`
+	AllowCreate bool     `xml:",attr"`
+}
+
+type authnRequest struct {
+	XMLName                     xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:protocol AuthnRequest"`
+	ID                          string   `xml:",attr"`
+	Version                     string   `xml:",attr"`
+	IssueInstant                string   `xml:",attr"`
+	Destination                 string   `xml:",attr"`
+	AssertionConsumerServiceURL string   `xml:",attr"`
+	ProtocolBinding             string   `xml:",attr"`
+	Issuer                      issuer
+	NameIDPolicy                nameIDPolicy
+}
+
+// AuthnRequestURL returns the URL of the identity provider the user is sent to
+// in order to sign in, using the HTTP-Redirect binding.
+func (source *Source) AuthnRequestURL(entityID, acsURL, requestID, relayState string, now time.Time) (string, error) {
+	req := authnRequest{
+		ID:                          requestID,
+		Version:                     "2.0",
+		IssueInstant:                now.UTC().Format(time.RFC3339),
+		Destination:                 source.IdentityProviderSSOURL,
+		AssertionConsumerServiceURL: acsURL,
+		ProtocolBinding:             HTTPPostBinding,
+		Issuer:                      issuer{Value: entityID},
+		NameIDPolicy:                nameIDPolicy{AllowCreate: true},
+	}
+	bs, err := xml.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err = w.Write(bs); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(source.IdentityProviderSSOURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+type assertionConsumerService struct {
+	Binding  string `xml:",attr"`
+	Location string `xml:",attr"`
+	Index    int    `xml:"index,attr"`
+}
+
+type spSSODescriptor struct {
+	AuthnRequestsSigned        bool   `xml:",attr"`
+	WantAssertionsSigned       bool   `xml:",attr"`
+	ProtocolSupportEnumeration string `xml:"protocolSupportEnumeration,attr"`
+	NameIDFormat               string
+	AssertionConsumerService   assertionConsumerService
+}
+
+type entityDescriptor struct {
+	XMLName         xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:metadata EntityDescriptor"`
+	EntityID        string   `xml:"entityID,attr"`
+	SPSSODescriptor spSSODescriptor
+}
+
+// Metadata returns the metadata of Gitea as a service provider, to be imported by the identity provider
+func Metadata(entityID, acsURL string) ([]byte, error) {
+	bs, err := xml.MarshalIndent(entityDescriptor{
+		EntityID: entityID,
+		SPSSODescriptor: spSSODescriptor{
+			AuthnRequestsSigned:        false,
+			WantAssertionsSigned:       true,
+			ProtocolSupportEnumeration: ProtocolNamespace,
+			NameIDFormat:               NameIDFormatUnspecified,
+			AssertionConsumerService: assertionConsumerService{
+				Binding:  HTTPPostBinding,
+				Location: acsURL,
+				Index:    1,
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), bs...), nil
+}
+
+// ErrInvalidResponse represents an error of a response of the identity provider which is not acceptable
+type ErrInvalidResponse struct {
+	Reason string
+}
+
+// IsErrInvalidResponse checks if an error is a ErrInvalidResponse.
+func IsErrInvalidResponse(err error) bool {
+	_, ok := err.(ErrInvalidResponse)
+	return ok
+}
+
+func (err ErrInvalidResponse) Error() string {
+	return fmt.Sprintf("invalid SAML response: %s", err.Reason)
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testEntityID = "https://try.gitea.io/user/saml/idp/metadata"
+	testACSURL   = "https://try.gitea.io/user/saml/idp/acs"
+	testIdP      = "https://idp.example.com"
+)
+
+func testSource(t *testing.T) (*Source, dsig.X509KeyStore) {
+	ks := dsig.RandomKeyStoreForTest()
+	_, certDER, err := ks.GetKeyPair()
+	assert.NoError(t, err)
+	return &Source{
+		IdentityProviderEntityID:    testIdP,
+		IdentityProviderSSOURL:      testIdP + "/sso?tenant=1",
+		IdentityProviderCertificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})),
+		AttributeUsername:           "uid",
+		AttributeEmail:              "mail",
+		AttributeFullName:           "displayName",
+		AttributeGroups:             "groups",
+	}, ks
+}
+
+var assertionCounter int
+
+func testResponse(inResponseTo string, issued time.Time) string {
+	assertionCounter++
+	return fmt.Sprintf(`<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="resp-%[4]d" Version="2.0" IssueInstant="%[2]s" Destination="%[3]s" InResponseTo="%[1]s">
+<saml:Issuer xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">https://idp.example.com</saml:Issuer>
+<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="assertion-%[4]d" Version="2.0" IssueInstant="%[2]s">
+<saml:Issuer>https://idp.example.com</saml:Issuer>
+<saml:Subject>
+<saml:NameID>jdoe</saml:NameID>
+<saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml:SubjectConfirmationData InResponseTo="%[1]s" Recipient="%[3]s" NotOnOrAfter="%[5]s"/></saml:SubjectConfirmation>
+</saml:Subject>
+<saml:Conditions NotBefore="%[2]s" NotOnOrAfter="%[5]s"><saml:AudienceRestriction><saml:Audience>%[6]s</saml:Audience></saml:AudienceRestriction></saml:Conditions>
+<saml:AttributeStatement>
+<saml:Attribute Name="uid"><saml:AttributeValue>john.doe</saml:AttributeValue></saml:Attribute>
+<saml:Attribute Name="mail"><saml:AttributeValue>john@example.com</saml:AttributeValue></saml:Attribute>
+<saml:Attribute Name="displayName"><saml:AttributeValue>John Doe</saml:AttributeValue></saml:Attribute>
+<saml:Attribute Name="groups"><saml:AttributeValue>developers</saml:AttributeValue><saml:AttributeValue>admins</saml:AttributeValue></saml:Attribute>
+</saml:AttributeStatement>
+</saml:Assertion>
+</samlp:Response>`, inResponseTo, issued.UTC().Format(time.RFC3339), testACSURL, assertionCounter,
+		issued.Add(5*time.Minute).UTC().Format(time.RFC3339), testEntityID)
+}
+
+// sign signs the assertion or the response itself and returns the base64 encoded response
+func sign(t *testing.T, ks dsig.X509KeyStore, response string, signAssertion bool, tamper func(string) string) string {
+	doc := etree.NewDocument()
+	assert.NoError(t, doc.ReadFromString(response))
+	ctx := dsig.NewDefaultSigningContext(ks)
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	if signAssertion {
+		el := doc.Root().FindElement("./Assertion")
+		signed, err := ctx.SignEnveloped(el)
+		assert.NoError(t, err)
+		doc.Root().RemoveChild(el)
+		doc.Root().AddChild(signed)
+	} else {
+		signed, err := ctx.SignEnveloped(doc.Root())
+		assert.NoError(t, err)
+		doc.SetRoot(signed)
+	}
+	s, err := doc.WriteToString()
+	assert.NoError(t, err)
+	if tamper != nil {
+		s = tamper(s)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func TestSource_AuthnRequestURL(t *testing.T) {
+	source, _ := testSource(t)
+	link, err := source.AuthnRequestURL(testEntityID, testACSURL, "id-123", "/explore", time.Now())
+	assert.NoError(t, err)
+
+	u, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "idp.example.com", u.Host)
+	assert.Equal(t, "1", u.Query().Get("tenant"))
+	assert.Equal(t, "/explore", u.Query().Get("RelayState"))
+
+	compressed, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	assert.NoError(t, err)
+	request, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	assert.NoError(t, err)
+	assert.Contains(t, string(request), `ID="id-123"`)
+	assert.Contains(t, string(request), `AssertionConsumerServiceURL="`+testACSURL+`"`)
+	assert.Contains(t, string(request), testEntityID+"</Issuer>")
+}
+
+func TestMetadata(t *testing.T) {
+	metadata, err := Metadata(testEntityID, testACSURL)
+	assert.NoError(t, err)
+	assert.Contains(t, string(metadata), `entityID="`+testEntityID+`"`)
+	assert.Contains(t, string(metadata), `Location="`+testACSURL+`"`)
+	assert.Contains(t, string(metadata), HTTPPostBinding)
+}
+
+func TestSource_Certificates(t *testing.T) {
+	source, ks := testSource(t)
+	certs, err := source.Certificates()
+	assert.NoError(t, err)
+	_, certDER, _ := ks.GetKeyPair()
+	expected, _ := x509.ParseCertificate(certDER)
+	assert.Len(t, certs, 1)
+	assert.True(t, certs[0].Equal(expected))
+
+	source.IdentityProviderCertificate = "not a certificate"
+	_, err = source.Certificates()
+	assert.Error(t, err)
+}
+
+func TestSource_ParseResponse(t *testing.T) {
+	source, ks := testSource(t)
+	now := time.Now()
+	requested := func(requestID string) func(string) bool {
+		return func(id string) bool {
+			return id == requestID
+		}
+	}
+
+	for _, signAssertion := range []bool{true, false} {
+		encoded := sign(t, ks, testResponse("id-1", now), signAssertion, nil)
+		u, err := source.ParseResponse(encoded, testEntityID, testACSURL, requested("id-1"), now)
+		assert.NoError(t, err)
+		assert.Equal(t, &User{
+			NameID:   "jdoe",
+			Username: "john.doe",
+			Email:    "john@example.com",
+			FullName: "John Doe",
+			Groups:   []string{"developers", "admins"},
+		}, u)
+
+		// an assertion may only be consumed once
+		_, err = source.ParseResponse(encoded, testEntityID, testACSURL, requested("id-1"), now)
+		assert.True(t, IsErrInvalidResponse(err))
+	}
+
+	assertInvalid := func(encoded, requestID string, now time.Time) {
+		_, err := source.ParseResponse(encoded, testEntityID, testACSURL, requested(requestID), now)
+		assert.True(t, IsErrInvalidResponse(err), "%v", err)
+	}
+
+	// tampered
+	assertInvalid(sign(t, ks, testResponse("id-1", now), true, func(s string) string {
+		return strings.Replace(s, "jdoe", "admin", 1)
+	}), "id-1", now)
+	// unsigned
+	assertInvalid(base64.StdEncoding.EncodeToString([]byte(testResponse("id-1", now))), "id-1", now)
+	// signed by someone else
+	assertInvalid(sign(t, dsig.RandomKeyStoreForTest(), testResponse("id-1", now), true, nil), "id-1", now)
+	// answering another request
+	assertInvalid(sign(t, ks, testResponse("id-1", now), true, nil), "id-2", now)
+	// expired
+	assertInvalid(sign(t, ks, testResponse("id-1", now), true, nil), "id-1", now.Add(time.Hour))
+	// for another service provider
+	assertInvalid(sign(t, ks, strings.Replace(testResponse("id-1", now), testEntityID, "https://other.example.com", 1), true, nil), "id-1", now)
+	// not base64
+	assertInvalid("<Response/>", "id-1", now)
+
+	// IdP-initiated
+	assertInvalid(sign(t, ks, testResponse("", now), true, nil), "", now)
+	source.AllowIDPInitiated = true
+	u, err := source.ParseResponse(sign(t, ks, testResponse("", now), true, nil), testEntityID, testACSURL, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "john.doe", u.Username)
+}
+
+func TestSource_GroupTeamMapping(t *testing.T) {
+	source := &Source{}
+	mapping, err := source.GroupTeamMapping()
+	assert.NoError(t, err)
+	assert.Empty(t, mapping)
+
+	source.GroupTeamMap = `{"developers": {"org3": ["team1", "team2"]}}`
+	mapping, err = source.GroupTeamMapping()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string][]string{"developers": {"org3": {"team1", "team2"}}}, mapping)
+
+	source.GroupTeamMap = `not json`
+	_, err = source.GroupTeamMapping()
+	assert.Error(t, err)
+}
@@ -100,11 +100,21 @@ func (b *Basic) VerifyAuthData(req *http.Request, w http.ResponseWriter, store D
 	if u == nil {
 		u, err = models.UserSignIn(uname, passwd)
 		if err != nil {
-			if !models.IsErrUserNotExist(err) {
+			if models.IsErrUserNotExist(err) {
+				// A wrong password of an existing user counts towards the lockout of the account
+				if uid := err.(models.ErrUserNotExist).UID; uid > 0 {
+					if _, err := models.RecordLoginFailure(uid); err != nil {
+						log.Error("RecordLoginFailure: %v", err)
+					}
+				}
+			} else if !models.IsErrUserLocked(err) {
 				log.Error("UserSignIn: %v", err)
 			}
 			return nil
 		}
+		if err := models.ResetLoginFailures(u); err != nil {
+			log.Error("ResetLoginFailures: %v", err)
+		}
 	} else {
 		store.GetData()["IsApiToken"] = true
 	}
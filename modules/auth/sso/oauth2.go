@@ -94,7 +94,15 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 	}
 	t, err := models.GetAccessTokenBySHA(tokenSHA)
 	if err != nil {
-		if !models.IsErrAccessTokenNotExist(err) && !models.IsErrAccessTokenEmpty(err) {
+		if models.IsErrAccessTokenNotExist(err) {
+			// It might be a read-only token of a repository, which is not bound to a user
+			repoToken, err := models.GetRepoAccessTokenBySHA(tokenSHA)
+			if err == nil {
+				store.GetData()["RepoAccessToken"] = repoToken
+			} else if !models.IsErrRepoAccessTokenNotExist(err) {
+				log.Error("GetRepoAccessTokenBySHA: %v", err)
+			}
+		} else if !models.IsErrAccessTokenEmpty(err) {
 			log.Error("GetAccessTokenBySHA: %v", err)
 		}
 		return 0
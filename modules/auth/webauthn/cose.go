@@ -0,0 +1,152 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+)
+
+// COSE algorithm identifiers of the supported credential public keys
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgES384 int64 = -35
+	AlgES512 int64 = -36
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms lists the supported algorithms in order of preference
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgES384, AlgES512, AlgRS256}
+
+// COSE key parameters, see RFC 8152
+const (
+	coseKeyType      int64 = 1
+	coseKeyAlgorithm int64 = 3
+	coseKeyCurve     int64 = -1 // crv for EC2 and OKP keys, n for RSA keys
+	coseKeyX         int64 = -2 // x for EC2 and OKP keys, e for RSA keys
+	coseKeyY         int64 = -3
+
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveP384    int64 = 2
+	coseCurveP521    int64 = 3
+	coseCurveEd25519 int64 = 6
+)
+
+// parseCOSEKey decodes the COSE_Key at the start of data and returns the public key,
+// its algorithm and the bytes following the key.
+func parseCOSEKey(data []byte) (crypto.PublicKey, int64, []byte, error) {
+	item, rest, err := decodeCBOR(data)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	key, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, nil, errors.New("COSE key is not a map")
+	}
+	kty, _ := key[coseKeyType].(int64)
+	alg, _ := key[coseKeyAlgorithm].(int64)
+
+	switch kty {
+	case coseKeyTypeEC2:
+		var curve elliptic.Curve
+		switch crv, _ := key[coseKeyCurve].(int64); {
+		case crv == coseCurveP256 && alg == AlgES256:
+			curve = elliptic.P256()
+		case crv == coseCurveP384 && alg == AlgES384:
+			curve = elliptic.P384()
+		case crv == coseCurveP521 && alg == AlgES512:
+			curve = elliptic.P521()
+		default:
+			return nil, 0, nil, fmt.Errorf("unsupported EC2 key: curve %d, algorithm %d", crv, alg)
+		}
+		x, _ := key[coseKeyX].([]byte)
+		y, _ := key[coseKeyY].([]byte)
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, 0, nil, errors.New("EC2 key is not on its curve")
+		}
+		return pub, alg, rest, nil
+	case coseKeyTypeRSA:
+		if alg != AlgRS256 {
+			return nil, 0, nil, fmt.Errorf("unsupported RSA key algorithm %d", alg)
+		}
+		n, _ := key[coseKeyCurve].([]byte)
+		e, _ := key[coseKeyX].([]byte)
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, nil, errors.New("invalid RSA key")
+		}
+		exponent := new(big.Int).SetBytes(e)
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, alg, rest, nil
+	case coseKeyTypeOKP:
+		crv, _ := key[coseKeyCurve].(int64)
+		x, _ := key[coseKeyX].([]byte)
+		if crv != coseCurveEd25519 || alg != AlgEdDSA || len(x) != ed25519.PublicKeySize {
+			return nil, 0, nil, fmt.Errorf("unsupported OKP key: curve %d, algorithm %d", crv, alg)
+		}
+		return ed25519.PublicKey(x), alg, rest, nil
+	}
+	return nil, 0, nil, fmt.Errorf("unsupported COSE key type %d", kty)
+}
+
+// verifySignature checks the signature of data made by the key of a credential
+func verifySignature(pub crypto.PublicKey, alg int64, data, sig []byte) error {
+	var h hash.Hash
+	switch alg {
+	case AlgES256, AlgRS256:
+		h = sha256.New()
+	case AlgES384:
+		h = sha512.New384()
+	case AlgES512:
+		h = sha512.New()
+	case AlgEdDSA:
+		key, ok := pub.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(key, data, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %d", alg)
+	}
+	_, _ = h.Write(data)
+	digest := h.Sum(nil)
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if alg == AlgRS256 {
+			return ErrInvalidSignature
+		}
+		var ecdsaSig struct {
+			R, S *big.Int
+		}
+		if rest, err := asn1.Unmarshal(sig, &ecdsaSig); err != nil || len(rest) != 0 {
+			return ErrInvalidSignature
+		}
+		if !ecdsa.Verify(key, digest, ecdsaSig.R, ecdsaSig.S) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case *rsa.PublicKey:
+		if alg != AlgRS256 || rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig) != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrInvalidSignature
+}
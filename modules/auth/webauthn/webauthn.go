@@ -0,0 +1,500 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Timeout is the time in milliseconds the browser waits for the authenticator
+const Timeout = 60000
+
+// Authenticator data flags
+const (
+	flagUserPresent            byte = 0x01
+	flagAttestedCredentialData byte = 0x40
+)
+
+// maxCredentialIDLength is the maximum length of a credential ID. The specification allows
+// up to 1023 bytes but authenticators use far shorter IDs, 307 bytes fit into the 410
+// base64 characters stored in the database.
+const maxCredentialIDLength = 307
+
+var (
+	// ErrInvalidSignature is returned when the signature of an assertion does not match
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrCredentialNotAllowed is returned when an assertion is made with a credential that was not requested
+	ErrCredentialNotAllowed = errors.New("credential not allowed")
+	// ErrCounterRegression is returned when the signature counter did not increase, which
+	// indicates a cloned authenticator
+	ErrCounterRegression = errors.New("signature counter did not increase")
+)
+
+// Credential represents a public key credential of an authenticator
+type Credential struct {
+	ID []byte
+	// PublicKey is stored in PKIX, ASN.1 DER form
+	PublicKey       []byte
+	Algorithm       int64
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+}
+
+// CredentialFromU2F converts the key handle and public key of a U2F registration
+// into a credential that can be used through WebAuthn with the appid extension.
+func CredentialFromU2F(keyHandle []byte, pub *ecdsa.PublicKey, counter uint32) (*Credential, error) {
+	if pub == nil || pub.Curve != elliptic.P256() {
+		return nil, errors.New("U2F keys must be P-256 keys")
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:              keyHandle,
+		PublicKey:       der,
+		Algorithm:       AlgES256,
+		AttestationType: "fido-u2f",
+		SignCount:       counter,
+	}, nil
+}
+
+// SessionData is kept in the session of the user between starting and finishing
+// a registration or an assertion
+type SessionData struct {
+	Challenge            string
+	UserID               int64
+	AllowedCredentialIDs [][]byte
+	AppID                string
+}
+
+// RelyingParty represents this Gitea instance as WebAuthn relying party
+type RelyingParty struct {
+	ID     string
+	Name   string
+	Origin string
+	// AppID is the U2F application ID accepted for credentials registered through U2F
+	AppID string
+}
+
+// NewRelyingParty returns the relying party derived from the settings: the host of ROOT_URL
+// is used as identifier and the U2F application ID allows the use of U2F security keys.
+func NewRelyingParty() (*RelyingParty, error) {
+	u, err := url.Parse(setting.AppURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RelyingParty{
+		ID:     u.Hostname(),
+		Name:   setting.AppName,
+		Origin: u.Scheme + "://" + u.Host,
+		AppID:  setting.U2F.AppID,
+	}, nil
+}
+
+// RelyingPartyEntity describes the relying party to the authenticator
+type RelyingPartyEntity struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the user account a credential is registered for
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter describes a credential type that can be created
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor identifies a credential
+type CredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// AuthenticatorSelection describes the requirements on the authenticator
+type AuthenticatorSelection struct {
+	UserVerification string `json:"userVerification"`
+}
+
+// CredentialCreationOptions are passed to navigator.credentials.create by the browser,
+// binary values are base64url encoded.
+type CredentialCreationOptions struct {
+	PublicKey PublicKeyCredentialCreationOptions `json:"publicKey"`
+}
+
+// PublicKeyCredentialCreationOptions describe the credential to be created
+type PublicKeyCredentialCreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// CredentialRequestOptions are passed to navigator.credentials.get by the browser,
+// binary values are base64url encoded.
+type CredentialRequestOptions struct {
+	PublicKey PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
+
+// PublicKeyCredentialRequestOptions describe the assertion to be made
+type PublicKeyCredentialRequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int                    `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+	Extensions       map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// CredentialCreationResponse is the credential returned by navigator.credentials.create,
+// binary values are base64url encoded.
+type CredentialCreationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// CredentialAssertionResponse is the credential returned by navigator.credentials.get,
+// binary values are base64url encoded.
+type CredentialAssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// EncodeToString encodes binary values the way they are exchanged with the browser
+func EncodeToString(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeString decodes binary values sent by the browser
+func DecodeString(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func newChallenge() (string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+	return EncodeToString(challenge), nil
+}
+
+func credentialDescriptors(credentials []*Credential) ([]CredentialDescriptor, [][]byte) {
+	descriptors := make([]CredentialDescriptor, 0, len(credentials))
+	ids := make([][]byte, 0, len(credentials))
+	for _, cred := range credentials {
+		descriptors = append(descriptors, CredentialDescriptor{Type: "public-key", ID: EncodeToString(cred.ID)})
+		ids = append(ids, cred.ID)
+	}
+	return descriptors, ids
+}
+
+// BeginRegistration creates the options to register a new credential for a user,
+// the existing credentials of the user are excluded.
+func (rp *RelyingParty) BeginRegistration(userID int64, name, displayName string, existing []*Credential) (*CredentialCreationOptions, *SessionData, error) {
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+	userHandle := make([]byte, 8)
+	binary.BigEndian.PutUint64(userHandle, uint64(userID))
+
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+	exclude, _ := credentialDescriptors(existing)
+
+	return &CredentialCreationOptions{
+		PublicKey: PublicKeyCredentialCreationOptions{
+			Challenge: challenge,
+			RP:        RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+			User: UserEntity{
+				ID:          EncodeToString(userHandle),
+				Name:        name,
+				DisplayName: displayName,
+			},
+			PubKeyCredParams:       params,
+			Timeout:                Timeout,
+			ExcludeCredentials:     exclude,
+			AuthenticatorSelection: AuthenticatorSelection{UserVerification: "discouraged"},
+			Attestation:            "none",
+		},
+	}, &SessionData{
+		Challenge: challenge,
+		UserID:    userID,
+	}, nil
+}
+
+// FinishRegistration verifies the response of the authenticator and returns the new credential.
+// As no attestation is requested the attestation statement is not verified.
+func (rp *RelyingParty) FinishRegistration(session *SessionData, response *CredentialCreationResponse) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("unexpected credential type %q", response.Type)
+	}
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.create", session.Challenge); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := DecodeString(response.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %v", err)
+	}
+	item, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %v", err)
+	}
+	attestation, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("invalid attestation object")
+	}
+	format, _ := attestation["fmt"].(string)
+	rawAuthData, _ := attestation["authData"].([]byte)
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return nil, errors.New("relying party ID mismatch")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return nil, errors.New("user not present")
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return nil, errors.New("no attested credential data")
+	}
+	if rawID, err := DecodeString(response.RawID); err != nil || !bytes.Equal(rawID, authData.CredentialID) {
+		return nil, errors.New("credential ID mismatch")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(authData.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Credential{
+		ID:              authData.CredentialID,
+		PublicKey:       der,
+		Algorithm:       authData.Algorithm,
+		AttestationType: format,
+		AAGUID:          authData.AAGUID,
+		SignCount:       authData.SignCount,
+	}, nil
+}
+
+// BeginLogin creates the options to make an assertion with one of the given credentials
+// of a user. If useAppID is set the U2F appid extension is requested so that security
+// keys registered through U2F can be used.
+func (rp *RelyingParty) BeginLogin(userID int64, credentials []*Credential, useAppID bool) (*CredentialRequestOptions, *SessionData, error) {
+	if len(credentials) == 0 {
+		return nil, nil, errors.New("no credentials registered")
+	}
+	challenge, err := newChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+	allow, ids := credentialDescriptors(credentials)
+
+	options := &CredentialRequestOptions{
+		PublicKey: PublicKeyCredentialRequestOptions{
+			Challenge:        challenge,
+			Timeout:          Timeout,
+			RPID:             rp.ID,
+			AllowCredentials: allow,
+			UserVerification: "discouraged",
+		},
+	}
+	session := &SessionData{
+		Challenge:            challenge,
+		UserID:               userID,
+		AllowedCredentialIDs: ids,
+	}
+	if useAppID && rp.AppID != "" {
+		options.PublicKey.Extensions = map[string]interface{}{"appid": rp.AppID}
+		session.AppID = rp.AppID
+	}
+	return options, session, nil
+}
+
+// FinishLogin verifies the assertion made by the authenticator and returns the
+// credential that was used with its updated signature counter.
+func (rp *RelyingParty) FinishLogin(session *SessionData, response *CredentialAssertionResponse, credentials []*Credential) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, fmt.Errorf("unexpected credential type %q", response.Type)
+	}
+	rawID, err := DecodeString(response.RawID)
+	if err != nil {
+		return nil, ErrCredentialNotAllowed
+	}
+	allowed := false
+	for _, id := range session.AllowedCredentialIDs {
+		if bytes.Equal(id, rawID) {
+			allowed = true
+			break
+		}
+	}
+	var cred *Credential
+	for _, c := range credentials {
+		if bytes.Equal(c.ID, rawID) {
+			cred = c
+			break
+		}
+	}
+	if !allowed || cred == nil {
+		return nil, ErrCredentialNotAllowed
+	}
+
+	if err := rp.verifyClientData(response.Response.ClientDataJSON, "webauthn.get", session.Challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeString(response.Response.AuthenticatorData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authenticator data: %v", err)
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	appIDHash := sha256.Sum256([]byte(session.AppID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 &&
+		(session.AppID == "" || subtle.ConstantTimeCompare(authData.RPIDHash, appIDHash[:]) != 1) {
+		return nil, errors.New("relying party ID mismatch")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return nil, errors.New("user not present")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(cred.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataJSON, _ := DecodeString(response.Response.ClientDataJSON)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signature, err := DecodeString(response.Response.Signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if err := verifySignature(pub, cred.Algorithm, signed, signature); err != nil {
+		return nil, err
+	}
+
+	// authenticators without a counter always report zero
+	if (authData.SignCount != 0 || cred.SignCount != 0) && authData.SignCount <= cred.SignCount {
+		return nil, ErrCounterRegression
+	}
+	cred.SignCount = authData.SignCount
+	return cred, nil
+}
+
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp *RelyingParty) verifyClientData(encoded, typ, challenge string) error {
+	raw, err := DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid client data: %v", err)
+	}
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("invalid client data: %v", err)
+	}
+	if clientData.Type != typ {
+		return fmt.Errorf("unexpected client data type %q", clientData.Type)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(strings.TrimRight(clientData.Challenge, "=")), []byte(challenge)) != 1 {
+		return errors.New("challenge mismatch")
+	}
+	if clientData.Origin != rp.Origin {
+		return fmt.Errorf("unexpected origin %q", clientData.Origin)
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    crypto.PublicKey
+	Algorithm    int64
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+	authData := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	data = data[37:]
+	if len(data) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	authData.AAGUID = data[:16]
+	idLength := int(binary.BigEndian.Uint16(data[16:18]))
+	data = data[18:]
+	if idLength > maxCredentialIDLength || idLength > len(data) {
+		return nil, errors.New("invalid credential ID length")
+	}
+	authData.CredentialID = data[:idLength]
+
+	var err error
+	if authData.PublicKey, authData.Algorithm, _, err = parseCOSEKey(data[idLength:]); err != nil {
+		return nil, err
+	}
+	return authData, nil
+}
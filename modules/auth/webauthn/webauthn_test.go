@@ -0,0 +1,307 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encodeCBOR is a minimal CBOR encoder to build authenticator responses in tests
+func encodeCBOR(v interface{}) []byte {
+	head := func(major byte, arg uint64) []byte {
+		switch {
+		case arg < 24:
+			return []byte{major<<5 | byte(arg)}
+		case arg < 1<<8:
+			return []byte{major<<5 | 24, byte(arg)}
+		case arg < 1<<16:
+			b := []byte{major<<5 | 25, 0, 0}
+			binary.BigEndian.PutUint16(b[1:], uint16(arg))
+			return b
+		default:
+			b := []byte{major<<5 | 26, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(b[1:], uint32(arg))
+			return b
+		}
+	}
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case map[interface{}]interface{}:
+		keys := make([]interface{}, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return string(encodeCBOR(keys[i])) < string(encodeCBOR(keys[j]))
+		})
+		b := head(5, uint64(len(v)))
+		for _, k := range keys {
+			b = append(b, encodeCBOR(k)...)
+			b = append(b, encodeCBOR(v[k])...)
+		}
+		return b
+	}
+	panic("unsupported type")
+}
+
+type testAuthenticator struct {
+	id     []byte
+	signer crypto.Signer
+	alg    int64
+	count  uint32
+}
+
+func newTestAuthenticator(t *testing.T, alg int64) *testAuthenticator {
+	a := &testAuthenticator{id: make([]byte, 32), alg: alg, count: 1}
+	_, _ = rand.Read(a.id)
+	var err error
+	switch alg {
+	case AlgES256:
+		a.signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, a.signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	assert.NoError(t, err)
+	return a
+}
+
+func (a *testAuthenticator) coseKey() []byte {
+	switch key := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		return encodeCBOR(map[interface{}]interface{}{
+			coseKeyType:      coseKeyTypeEC2,
+			coseKeyAlgorithm: AlgES256,
+			coseKeyCurve:     coseCurveP256,
+			coseKeyX:         key.X.FillBytes(make([]byte, 32)),
+			coseKeyY:         key.Y.FillBytes(make([]byte, 32)),
+		})
+	case ed25519.PublicKey:
+		return encodeCBOR(map[interface{}]interface{}{
+			coseKeyType:      coseKeyTypeOKP,
+			coseKeyAlgorithm: AlgEdDSA,
+			coseKeyCurve:     coseCurveEd25519,
+			coseKeyX:         []byte(key),
+		})
+	}
+	return nil
+}
+
+func (a *testAuthenticator) authData(rpID string, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	data := append([]byte(nil), hash[:]...)
+	flags := flagUserPresent
+	if attested {
+		flags |= flagAttestedCredentialData
+	}
+	data = append(data, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[33:], a.count)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = append(data, byte(len(a.id)>>8), byte(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientData(typ, challenge, origin string) []byte {
+	data, _ := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": origin})
+	return data
+}
+
+func (a *testAuthenticator) create(rp *RelyingParty, options *CredentialCreationOptions) *CredentialCreationResponse {
+	resp := &CredentialCreationResponse{ID: EncodeToString(a.id), RawID: EncodeToString(a.id), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeToString(clientData("webauthn.create", options.PublicKey.Challenge, rp.Origin))
+	resp.Response.AttestationObject = EncodeToString(encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": a.authData(options.PublicKey.RP.ID, true),
+	}))
+	return resp
+}
+
+func (a *testAuthenticator) get(t *testing.T, rpID, origin string, options *CredentialRequestOptions) *CredentialAssertionResponse {
+	a.count++
+	authData := a.authData(rpID, false)
+	client := clientData("webauthn.get", options.PublicKey.Challenge, origin)
+	clientHash := sha256.Sum256(client)
+	signed := append(append([]byte(nil), authData...), clientHash[:]...)
+
+	var sig []byte
+	var err error
+	if a.alg == AlgEdDSA {
+		sig, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(signed)
+		sig, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	assert.NoError(t, err)
+
+	resp := &CredentialAssertionResponse{ID: EncodeToString(a.id), RawID: EncodeToString(a.id), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeToString(client)
+	resp.Response.AuthenticatorData = EncodeToString(authData)
+	resp.Response.Signature = EncodeToString(sig)
+	return resp
+}
+
+var testRelyingParty = &RelyingParty{
+	ID:     "try.gitea.io",
+	Name:   "Gitea",
+	Origin: "https://try.gitea.io",
+	AppID:  "https://try.gitea.io",
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	rp := testRelyingParty
+	for _, alg := range []int64{AlgES256, AlgEdDSA} {
+		authenticator := newTestAuthenticator(t, alg)
+
+		options, session, err := rp.BeginRegistration(2, "user2", "User Two", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "try.gitea.io", options.PublicKey.RP.ID)
+		assert.Equal(t, "none", options.PublicKey.Attestation)
+		assert.Empty(t, options.PublicKey.ExcludeCredentials)
+
+		cred, err := rp.FinishRegistration(session, authenticator.create(rp, options))
+		assert.NoError(t, err)
+		assert.Equal(t, authenticator.id, cred.ID)
+		assert.Equal(t, alg, cred.Algorithm)
+		assert.Equal(t, "none", cred.AttestationType)
+		assert.EqualValues(t, 1, cred.SignCount)
+
+		// the registered credential must be excluded from further registrations
+		options, _, err = rp.BeginRegistration(2, "user2", "User Two", []*Credential{cred})
+		assert.NoError(t, err)
+		assert.Equal(t, []CredentialDescriptor{{Type: "public-key", ID: EncodeToString(cred.ID)}}, options.PublicKey.ExcludeCredentials)
+
+		loginOptions, loginSession, err := rp.BeginLogin(2, []*Credential{cred}, false)
+		assert.NoError(t, err)
+		assert.Nil(t, loginOptions.PublicKey.Extensions)
+		used, err := rp.FinishLogin(loginSession, authenticator.get(t, rp.ID, rp.Origin, loginOptions), []*Credential{cred})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, used.SignCount)
+
+		// replaying an older counter value must fail
+		authenticator.count = 0
+		_, err = rp.FinishLogin(loginSession, authenticator.get(t, rp.ID, rp.Origin, loginOptions), []*Credential{cred})
+		assert.Equal(t, ErrCounterRegression, err)
+	}
+}
+
+func TestFinishRegistration_Invalid(t *testing.T) {
+	rp := testRelyingParty
+	authenticator := newTestAuthenticator(t, AlgES256)
+	options, session, err := rp.BeginRegistration(2, "user2", "User Two", nil)
+	assert.NoError(t, err)
+
+	resp := authenticator.create(rp, options)
+	resp.Response.ClientDataJSON = EncodeToString(clientData("webauthn.create", options.PublicKey.Challenge, "https://evil.example.com"))
+	_, err = rp.FinishRegistration(session, resp)
+	assert.Error(t, err)
+
+	resp = authenticator.create(rp, options)
+	resp.Response.ClientDataJSON = EncodeToString(clientData("webauthn.create", "not-the-challenge", rp.Origin))
+	_, err = rp.FinishRegistration(session, resp)
+	assert.Error(t, err)
+
+	resp = authenticator.create(rp, options)
+	resp.Response.ClientDataJSON = EncodeToString(clientData("webauthn.get", options.PublicKey.Challenge, rp.Origin))
+	_, err = rp.FinishRegistration(session, resp)
+	assert.Error(t, err)
+
+	options.PublicKey.RP.ID = "evil.example.com"
+	_, err = rp.FinishRegistration(session, authenticator.create(rp, options))
+	assert.Error(t, err)
+}
+
+func TestFinishLogin_Invalid(t *testing.T) {
+	rp := testRelyingParty
+	authenticator := newTestAuthenticator(t, AlgES256)
+	other := newTestAuthenticator(t, AlgES256)
+
+	options, session, err := rp.BeginRegistration(2, "user2", "User Two", nil)
+	assert.NoError(t, err)
+	cred, err := rp.FinishRegistration(session, authenticator.create(rp, options))
+	assert.NoError(t, err)
+
+	loginOptions, loginSession, err := rp.BeginLogin(2, []*Credential{cred}, false)
+	assert.NoError(t, err)
+
+	// an unknown credential
+	_, err = rp.FinishLogin(loginSession, other.get(t, rp.ID, rp.Origin, loginOptions), []*Credential{cred})
+	assert.Equal(t, ErrCredentialNotAllowed, err)
+
+	// a signature made by another key
+	resp := authenticator.get(t, rp.ID, rp.Origin, loginOptions)
+	resp.Response.Signature = other.get(t, rp.ID, rp.Origin, loginOptions).Response.Signature
+	_, err = rp.FinishLogin(loginSession, resp, []*Credential{cred})
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	// the U2F application ID is only accepted when it was requested
+	_, err = rp.FinishLogin(loginSession, authenticator.get(t, rp.AppID, rp.Origin, loginOptions), []*Credential{cred})
+	assert.Error(t, err)
+
+	_, err = rp.FinishLogin(loginSession, authenticator.get(t, rp.ID, "https://evil.example.com", loginOptions), []*Credential{cred})
+	assert.Error(t, err)
+}
+
+func TestCredentialFromU2F(t *testing.T) {
+	rp := testRelyingParty
+	authenticator := newTestAuthenticator(t, AlgES256)
+	authenticator.count = 41
+
+	cred, err := CredentialFromU2F(authenticator.id, authenticator.signer.Public().(*ecdsa.PublicKey), 41)
+	assert.NoError(t, err)
+	assert.Equal(t, "fido-u2f", cred.AttestationType)
+
+	options, session, err := rp.BeginLogin(2, []*Credential{cred}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"appid": rp.AppID}, options.PublicKey.Extensions)
+
+	used, err := rp.FinishLogin(session, authenticator.get(t, rp.AppID, rp.Origin, options), []*Credential{cred})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42, used.SignCount)
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	item, rest, err := decodeCBOR(append(encodeCBOR(map[interface{}]interface{}{
+		"a":       int64(-300),
+		int64(1):  []byte{1, 2},
+		int64(-2): "text",
+	}), 0xff))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[interface{}]interface{}{"a": int64(-300), int64(1): []byte{1, 2}, int64(-2): "text"}, item)
+
+	for _, data := range [][]byte{
+		{},
+		{0x5a, 0xff, 0xff, 0xff, 0xff}, // byte string longer than the data
+		{0x9f},                         // indefinite length array
+		{0xa1, 0x80, 0x01},             // array as map key
+		{0xfb, 0, 0, 0, 0, 0, 0, 0, 0}, // float
+	} {
+		_, _, err := decodeCBOR(data)
+		assert.Error(t, err)
+	}
+}
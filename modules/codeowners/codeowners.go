@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+)
+
+// Locations are the paths, relative to the root of a repository, where the CODEOWNERS file is looked
+// up, in order
+var Locations = []string{"CODEOWNERS", ".gitea/CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule represents a line of a CODEOWNERS file, which assigns owners to the files matching its pattern
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	glob     glob.Glob
+	anchored bool
+	dirOnly  bool
+}
+
+// Parse parses the content of a CODEOWNERS file, the lines with an invalid pattern are skipped
+func Parse(content string) []*Rule {
+	rules := make([]*Rule, 0, 10)
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule, err := newRule(fields[0], fields[1:])
+		if err != nil {
+			log.Info("Invalid CODEOWNERS pattern '%s' (skipped): %v", fields[0], err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// newRule compiles a pattern following the syntax of the .gitignore files
+func newRule(pattern string, owners []string) (*Rule, error) {
+	rule := &Rule{
+		Pattern: pattern,
+		Owners:  owners,
+	}
+
+	expr := pattern
+	if strings.HasSuffix(expr, "/") {
+		rule.dirOnly = true
+		expr = strings.TrimSuffix(expr, "/")
+	}
+	if strings.HasPrefix(expr, "**/") {
+		// a leading **/ matches in all directories, including the root one
+		expr = strings.TrimPrefix(expr, "**/")
+		if strings.Contains(expr, "/") {
+			rule.anchored = true
+			expr = "{" + expr + ",**/" + expr + "}"
+		}
+	} else if strings.HasPrefix(expr, "/") {
+		rule.anchored = true
+		expr = expr[1:]
+	} else {
+		// a pattern with a slash in the middle is relative to the root too
+		rule.anchored = strings.Contains(expr, "/")
+	}
+
+	var err error
+	rule.glob, err = glob.Compile(expr, '/')
+	return rule, err
+}
+
+// Match returns true if the file at path matches the pattern of the rule, either itself or one of its
+// parent directories
+func (rule *Rule) Match(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(parts); i > 0; i-- {
+		if rule.dirOnly && i == len(parts) {
+			continue
+		}
+		candidate := parts[i-1]
+		if rule.anchored {
+			candidate = strings.Join(parts[:i], "/")
+		}
+		if rule.glob.Match(candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindRule returns the last rule matching the file at path, which determines its owners, or nil if
+// no rule matches it
+func FindRule(rules []*Rule, path string) *Rule {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].Match(path) {
+			return rules[i]
+		}
+	}
+	return nil
+}
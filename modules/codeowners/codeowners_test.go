@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	rules := Parse(`# the owners of everything
+*       @user1 @org3/team1
+
+/docs/  @user2 # the documentation
+*.go    @user4
+[       @user5
+/cmd/*.go
+`)
+	if assert.Len(t, rules, 4) {
+		assert.Equal(t, "*", rules[0].Pattern)
+		assert.Equal(t, []string{"@user1", "@org3/team1"}, rules[0].Owners)
+		assert.Equal(t, "/docs/", rules[1].Pattern)
+		assert.Equal(t, []string{"@user2"}, rules[1].Owners)
+		assert.Equal(t, "*.go", rules[2].Pattern)
+		assert.Equal(t, "/cmd/*.go", rules[3].Pattern)
+		assert.Empty(t, rules[3].Owners)
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	kases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*", "README.md", true},
+		{"*", "docs/README.md", true},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/web.go", true},
+		{"*.go", "main.go.txt", false},
+		{"/docs/", "docs/README.md", true},
+		{"/docs/", "docs/api/README.md", true},
+		{"/docs/", "docs", false},
+		{"/docs/", "web/docs/README.md", false},
+		{"docs/", "web/docs/README.md", true},
+		{"/docs", "docs/README.md", true},
+		{"/README.md", "README.md", true},
+		{"/README.md", "docs/README.md", false},
+		{"cmd/*.go", "cmd/web.go", true},
+		{"cmd/*.go", "cmd/web/web.go", false},
+		{"cmd/*.go", "tools/cmd/web.go", false},
+		{"docs/**", "docs/api/README.md", true},
+		{"**/api", "docs/api/README.md", true},
+		{"**/api/*.md", "api/README.md", true},
+		{"**/api/*.md", "docs/api/README.md", true},
+		{"**/api/*.md", "docs/api/README.txt", false},
+	}
+	for _, kase := range kases {
+		rule, err := newRule(kase.pattern, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, kase.match, rule.Match(kase.path), "pattern %q, path %q", kase.pattern, kase.path)
+	}
+}
+
+func TestFindRule(t *testing.T) {
+	rules := Parse(`*       @user1
+*.go    @user2
+/cmd/   @user3
+/cmd/serv.go
+`)
+	assert.Equal(t, "*", FindRule(rules, "README.md").Pattern)
+	assert.Equal(t, "*.go", FindRule(rules, "main.go").Pattern)
+	assert.Equal(t, "/cmd/", FindRule(rules, "cmd/web.go").Pattern)
+	assert.Empty(t, FindRule(rules, "cmd/serv.go").Owners)
+	assert.Nil(t, FindRule(rules[1:], "README.md"))
+}
@@ -217,6 +217,31 @@ func (ctx *APIContext) CheckForOTP() {
 	}
 }
 
+// RepoAccessToken returns the read-only repository access token the request has been authenticated with, if any
+func (ctx *APIContext) RepoAccessToken() *models.RepoAccessToken {
+	t, _ := ctx.Data["RepoAccessToken"].(*models.RepoAccessToken)
+	return t
+}
+
+// IsRepoAccessTokenRequest returns true if the request has been authenticated with
+// a repository access token and targets the repository the token belongs to.
+func (ctx *APIContext) IsRepoAccessTokenRequest() bool {
+	t := ctx.RepoAccessToken()
+	if t == nil {
+		return false
+	}
+	repo, err := models.GetRepositoryByID(t.RepoID)
+	if err != nil {
+		if !models.IsErrRepoNotExist(err) {
+			log.Error("GetRepositoryByID: %v", err)
+		}
+		return false
+	}
+	prefix := strings.ToLower(setting.AppSubURL + "/api/v1/repos/" + repo.FullName())
+	path := strings.ToLower(ctx.Req.URL.Path)
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
 // APIContexter returns apicontext as middleware
 func APIContexter() func(http.Handler) http.Handler {
 	var csrfOpts = getCsrfOpts()
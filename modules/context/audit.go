@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	session_module "code.gitea.io/gitea/modules/session"
+)
+
+// Audit records an event caused by the signed-in user in the audit log, the actor and the
+// address of the request are filled in. Failures are logged rather than returned so that
+// they do not abort the action being audited.
+func (ctx *Context) Audit(event *models.AuditEvent) {
+	if ctx.IsImpersonated() {
+		// the admin impersonating the user is responsible for the event
+		event.ActorID = session_module.ImpersonatorID(ctx.Session)
+		event.ActorName = session_module.ImpersonatorName(ctx.Session)
+		if event.Description != "" {
+			event.Description += ", "
+		}
+		event.Description += "impersonating " + ctx.User.Name
+	} else if ctx.User != nil {
+		event.ActorID = ctx.User.ID
+		event.ActorName = ctx.User.Name
+	}
+	event.IP = ctx.RemoteAddr()
+	if host, _, err := net.SplitHostPort(event.IP); err == nil {
+		event.IP = host
+	}
+	if err := models.CreateAuditEvent(event); err != nil {
+		log.Error("CreateAuditEvent[%s]: %v", event.Action, err)
+	}
+}
@@ -6,6 +6,8 @@
 package context
 
 import (
+	"net/http"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -17,7 +19,9 @@ type ToggleOptions struct {
 	SignInRequired  bool
 	SignOutRequired bool
 	AdminRequired   bool
-	DisableCSRF     bool
+	// DisableCSRF disables the CSRF check of routes which do not rely on the session,
+	// requests authenticated by a token are always exempt from it
+	DisableCSRF bool
 }
 
 // Toggle returns toggle options as middleware
@@ -37,7 +41,8 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 				return
 			}
 
-			if ctx.User.MustChangePassword {
+			// an admin impersonating the user must not change the password
+			if ctx.User.MustChangePassword && !ctx.IsImpersonated() {
 				if ctx.Req.URL.Path != "/user/settings/change_password" {
 					ctx.Data["Title"] = ctx.Tr("auth.must_change_password")
 					ctx.Data["ChangePasscodeLink"] = setting.AppSubURL + "/user/change_password"
@@ -47,7 +52,7 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 					ctx.Redirect(setting.AppSubURL + "/user/settings/change_password")
 					return
 				}
-			} else if ctx.Req.URL.Path == "/user/settings/change_password" {
+			} else if ctx.Req.URL.Path == "/user/settings/change_password" && !ctx.User.MustChangePassword {
 				// make sure that the form cannot be accessed by users who don't need this
 				ctx.Redirect(setting.AppSubURL + "/")
 				return
@@ -60,7 +65,9 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 			return
 		}
 
-		if !options.SignOutRequired && !options.DisableCSRF && ctx.Req.Method == "POST" {
+		// A token is never sent by the browser on its own, so a request authenticated
+		// by a token cannot be forged and does not need a CSRF token.
+		if !options.SignOutRequired && !options.DisableCSRF && ctx.Req.Method == "POST" && !ctx.IsTokenAuth() {
 			Validate(ctx, ctx.csrf)
 			if ctx.Written() {
 				return
@@ -101,6 +108,13 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 	}
 }
 
+// NotImpersonated forbids the request while an admin impersonates the signed-in user
+func NotImpersonated(ctx *Context) {
+	if ctx.IsImpersonated() {
+		ctx.Error(http.StatusForbidden, "not allowed while impersonating a user")
+	}
+}
+
 // ToggleAPI returns toggle options as middleware
 func ToggleAPI(options *ToggleOptions) func(ctx *APIContext) {
 	return func(ctx *APIContext) {
@@ -138,6 +152,9 @@ func ToggleAPI(options *ToggleOptions) func(ctx *APIContext) {
 
 		if options.SignInRequired {
 			if !ctx.IsSigned {
+				if ctx.IsRepoAccessTokenRequest() {
+					return
+				}
 				// Restrict API calls with error message.
 				ctx.JSON(403, map[string]string{
 					"message": "Only signed in user is allowed to call APIs.",
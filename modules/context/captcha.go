@@ -5,10 +5,14 @@
 package context
 
 import (
+	"fmt"
 	"sync"
 
 	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/hcaptcha"
+	"code.gitea.io/gitea/modules/recaptcha"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/turnstile"
 
 	"gitea.com/go-chi/captcha"
 )
@@ -26,3 +30,31 @@ func GetImageCaptcha() *captcha.Captcha {
 	})
 	return cpt
 }
+
+// SetCaptchaData sets the data needed to render the configured captcha,
+// enabled controls whether the captcha is shown at all.
+func (ctx *Context) SetCaptchaData(enabled bool) {
+	ctx.Data["EnableCaptcha"] = enabled
+	ctx.Data["RecaptchaURL"] = setting.Service.RecaptchaURL
+	ctx.Data["Captcha"] = GetImageCaptcha()
+	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
+	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
+	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
+}
+
+// VerifyCaptcha verifies the response to the configured captcha sent with the request
+func (ctx *Context) VerifyCaptcha() (bool, error) {
+	switch setting.Service.CaptchaType {
+	case setting.ImageCaptcha:
+		return GetImageCaptcha().VerifyReq(ctx.Req), nil
+	case setting.ReCaptcha:
+		return recaptcha.Verify(ctx.Req.Context(), ctx.Req.FormValue("g-recaptcha-response"))
+	case setting.HCaptcha:
+		return hcaptcha.Verify(ctx.Req.Context(), ctx.Req.FormValue("h-captcha-response"))
+	case setting.Turnstile:
+		return turnstile.Verify(ctx.Req.Context(), ctx.Req.FormValue("cf-turnstile-response"))
+	default:
+		return false, fmt.Errorf("Unknown Captcha Type: %s", setting.Service.CaptchaType)
+	}
+}
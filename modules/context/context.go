@@ -25,9 +25,11 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	mc "code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/log"
+	session_module "code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web/middleware"
 
 	"gitea.com/go-chi/cache"
@@ -73,6 +75,17 @@ func (ctx *Context) GetData() map[string]interface{} {
 	return ctx.Data
 }
 
+// IsTokenAuth returns true if the user of the request has been authenticated by an
+// access token rather than by the session or a password
+func (ctx *Context) IsTokenAuth() bool {
+	return ctx.IsSigned && ctx.Data["IsApiToken"] == true
+}
+
+// IsImpersonated returns true if the user signed in with the session is impersonated by an admin
+func (ctx *Context) IsImpersonated() bool {
+	return ctx.IsSigned && !ctx.IsBasicAuth && session_module.ImpersonatorID(ctx.Session) > 0
+}
+
 // IsUserSiteAdmin returns true if current user is a site admin
 func (ctx *Context) IsUserSiteAdmin() bool {
 	return ctx.IsSigned && ctx.User.IsAdmin
@@ -160,15 +173,12 @@ func (ctx *Context) HasValue(name string) bool {
 	return ok
 }
 
-// RedirectToFirst redirects to first not empty URL
+// RedirectToFirst redirects to the first location which is a path on this instance.
+// Absolute and protocol-relative URLs are skipped to prevent open redirects,
+// the dashboard is used if no location is acceptable.
 func (ctx *Context) RedirectToFirst(location ...string) {
 	for _, loc := range location {
-		if len(loc) == 0 {
-			continue
-		}
-
-		u, err := url.Parse(loc)
-		if err != nil || ((u.Scheme != "" || u.Host != "") && !strings.HasPrefix(strings.ToLower(loc), strings.ToLower(setting.AppURL))) {
+		if !util.IsLocalPath(loc) {
 			continue
 		}
 
@@ -186,6 +196,9 @@ func (ctx *Context) HTML(status int, name base.TplName) {
 	ctx.Data["TmplLoadTimes"] = func() string {
 		return fmt.Sprint(time.Since(startTime).Nanoseconds()/1e6) + "ms"
 	}
+	if status == http.StatusOK {
+		ctx.writeEarlyHints()
+	}
 	if err := ctx.Render.HTML(ctx.Resp, status, string(name), ctx.Data); err != nil {
 		if status == http.StatusInternalServerError && name == base.TplName("status/500") {
 			ctx.PlainText(http.StatusInternalServerError, []byte("Unable to find status/500 template"))
@@ -663,6 +676,9 @@ func Contexter() func(next http.Handler) http.Handler {
 				ctx.Data["SignedUserID"] = ctx.User.ID
 				ctx.Data["SignedUserName"] = ctx.User.Name
 				ctx.Data["IsAdmin"] = ctx.User.IsAdmin
+				if ctx.IsImpersonated() {
+					ctx.Data["ImpersonatorName"] = session_module.ImpersonatorName(ctx.Session)
+				}
 			} else {
 				ctx.Data["SignedUserID"] = int64(0)
 				ctx.Data["SignedUserName"] = ""
@@ -0,0 +1,51 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/public"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// earlyHintLinks returns the Link headers preloading the assets every page needs,
+// they must match the ones referenced by the base templates.
+func (ctx *Context) earlyHintLinks() []string {
+	links := []string{
+		"<" + public.AssetURL("css/index.css") + ">; rel=preload; as=style",
+		"<" + public.AssetURL("js/index.js") + ">; rel=preload; as=script",
+	}
+
+	theme := setting.UI.DefaultTheme
+	if ctx.IsSigned {
+		theme = ctx.User.Theme
+	}
+	if len(theme) > 0 && theme != "gitea" {
+		links = append(links, "<"+public.AssetURL("css/theme-"+theme+".css")+">; rel=preload; as=style")
+	}
+	return links
+}
+
+// writeEarlyHints sends a "103 Early Hints" informational response, so that the browser
+// can start loading the assets while the page is rendered. It does nothing unless
+// enabled or if the protocol of the request does not support informational responses reliably.
+func (ctx *Context) writeEarlyHints() {
+	if !setting.EnableEarlyHints || ctx.Req.ProtoMajor < 2 || ctx.Req.Method != http.MethodGet {
+		return
+	}
+
+	// The informational response must bypass the wrapper, which would record it as the final status
+	resp, ok := ctx.Resp.(*Response)
+	if !ok || resp.Status() != 0 {
+		return
+	}
+
+	header := resp.Header()
+	for _, link := range ctx.earlyHintLinks() {
+		header.Add("Link", link)
+	}
+	resp.ResponseWriter.WriteHeader(http.StatusEarlyHints)
+}
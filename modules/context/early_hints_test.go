@@ -0,0 +1,59 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statusRecorder struct {
+	*httptest.ResponseRecorder
+	statuses []int
+	links    [][]string
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.statuses = append(r.statuses, code)
+	r.links = append(r.links, r.Header().Values("Link"))
+}
+
+func TestWriteEarlyHints(t *testing.T) {
+	defer func(enabled bool, theme string) {
+		setting.EnableEarlyHints = enabled
+		setting.UI.DefaultTheme = theme
+	}(setting.EnableEarlyHints, setting.UI.DefaultTheme)
+	setting.UI.DefaultTheme = "arc-green"
+
+	newContext := func(protoMajor int) (*Context, *statusRecorder) {
+		rec := &statusRecorder{ResponseRecorder: httptest.NewRecorder()}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.ProtoMajor = protoMajor
+		return &Context{Resp: NewResponse(rec), Req: req}, rec
+	}
+
+	setting.EnableEarlyHints = false
+	ctx, rec := newContext(2)
+	ctx.writeEarlyHints()
+	assert.Empty(t, rec.statuses)
+
+	setting.EnableEarlyHints = true
+	ctx, rec = newContext(1)
+	ctx.writeEarlyHints()
+	assert.Empty(t, rec.statuses)
+
+	ctx, rec = newContext(2)
+	ctx.writeEarlyHints()
+	assert.Equal(t, []int{http.StatusEarlyHints}, rec.statuses)
+	assert.Len(t, rec.links[0], 3)
+	assert.Contains(t, rec.links[0][2], "/css/theme-arc-green.css")
+	// the final status has not been written yet
+	assert.Equal(t, 0, ctx.Resp.Status())
+}
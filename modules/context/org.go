@@ -74,6 +74,10 @@ func HandleOrgAssignment(ctx *Context, args ...bool) {
 		return
 	}
 
+	if !checkTwoFactorRequirement(ctx, org) {
+		return
+	}
+
 	// Admin has super access.
 	if ctx.IsSigned && ctx.User.IsAdmin {
 		ctx.Org.IsOwner = true
@@ -170,6 +174,25 @@ func HandleOrgAssignment(ctx *Context, args ...bool) {
 	}
 }
 
+// checkTwoFactorRequirement redirects a member of an organization which requires two-factor
+// authentication to the enrollment page if the member has not enrolled yet.
+// It returns false if the request has been handled.
+func checkTwoFactorRequirement(ctx *Context, org *models.User) bool {
+	if !ctx.IsSigned || !org.RequireTwoFactor {
+		return true
+	}
+	missing, err := org.IsTwoFactorMissingFor(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("IsTwoFactorMissingFor", err)
+		return false
+	} else if !missing {
+		return true
+	}
+	ctx.Flash.Error(ctx.Tr("org.settings.require_two_factor_enroll", org.DisplayName()))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security/two_factor/enroll")
+	return false
+}
+
 // OrgAssignment returns a middleware to handle organization assignment
 func OrgAssignment(args ...bool) func(ctx *Context) {
 	return func(ctx *Context) {
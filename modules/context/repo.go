@@ -338,6 +338,10 @@ func repoAssignment(ctx *Context, repo *models.Repository) {
 		return
 	}
 
+	if repo.Owner.IsOrganization() && !checkTwoFactorRequirement(ctx, repo.Owner) {
+		return
+	}
+
 	ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
 	if err != nil {
 		ctx.ServerError("GetUserRepoPermission", err)
@@ -0,0 +1,25 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToCommitComment converts a models.CommitComment to the api.CommitComment format
+func ToCommitComment(repo *models.Repository, c *models.CommitComment) *api.CommitComment {
+	return &api.CommitComment{
+		ID:       c.ID,
+		HTMLURL:  c.HTMLURL(repo),
+		CommitID: c.CommitSHA,
+		Path:     c.TreePath,
+		Line:     c.Line,
+		Body:     c.Content,
+		Poster:   ToUser(c.Poster, false, false),
+		Created:  c.CreatedUnix.AsTime(),
+		Updated:  c.UpdatedUnix.AsTime(),
+	}
+}
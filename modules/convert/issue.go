@@ -206,3 +206,38 @@ func ToAPIMilestone(m *models.Milestone) *api.Milestone {
 	}
 	return apiMilestone
 }
+
+// ToLifecycleMetrics converts IssueLifecycleStats to API format
+func ToLifecycleMetrics(stats *models.IssueLifecycleStats) *api.LifecycleMetrics {
+	average := func(total, count int64) int64 {
+		if count == 0 {
+			return 0
+		}
+		return total / count
+	}
+	return &api.LifecycleMetrics{
+		Issues:                   stats.NumIssues,
+		Pulls:                    stats.NumPulls,
+		Responded:                stats.NumFirstResponses,
+		AverageFirstResponseTime: average(stats.FirstResponseSeconds, stats.NumFirstResponses),
+		Closed:                   stats.NumClosed,
+		AverageCloseTime:         average(stats.CloseSeconds, stats.NumClosed),
+		Merged:                   stats.NumMerged,
+		AverageMergeTime:         average(stats.MergeSeconds, stats.NumMerged),
+	}
+}
+
+// ToRepoLifecycleMetrics converts RepoIssueLifecycleStats to API format
+func ToRepoLifecycleMetrics(stats *models.RepoIssueLifecycleStats) *api.RepoLifecycleMetrics {
+	result := &api.RepoLifecycleMetrics{
+		Total:  ToLifecycleMetrics(&stats.IssueLifecycleStats),
+		Labels: make([]*api.LabelLifecycleMetrics, len(stats.Labels)),
+	}
+	for i, label := range stats.Labels {
+		result.Labels[i] = &api.LabelLifecycleMetrics{
+			Label:   ToLabel(label.Label),
+			Metrics: ToLifecycleMetrics(&label.IssueLifecycleStats),
+		}
+	}
+	return result
+}
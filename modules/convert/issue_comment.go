@@ -6,6 +6,7 @@ package convert
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/references"
 	api "code.gitea.io/gitea/modules/structs"
 )
 
@@ -22,3 +23,67 @@ func ToComment(c *models.Comment) *api.Comment {
 		Updated:  c.UpdatedUnix.AsTime(),
 	}
 }
+
+var xrefActionStrings = map[references.XRefAction]string{
+	references.XRefActionNone:     "none",
+	references.XRefActionCloses:   "closes",
+	references.XRefActionReopens:  "reopens",
+	references.XRefActionNeutered: "neutered",
+}
+
+// ToTimelineComment converts a models.Comment of any type to the api.TimelineComment format,
+// the related objects of the comment have to be loaded already
+func ToTimelineComment(c *models.Comment) *api.TimelineComment {
+	comment := &api.TimelineComment{
+		ID:       c.ID,
+		Type:     c.Type.String(),
+		Poster:   ToUser(c.Poster, false, false),
+		HTMLURL:  c.HTMLURL(),
+		IssueURL: c.IssueURL(),
+		PRURL:    c.PRURL(),
+		Body:     c.Content,
+		Created:  c.CreatedUnix.AsTime(),
+		Updated:  c.UpdatedUnix.AsTime(),
+
+		OldTitle: c.OldTitle,
+		NewTitle: c.NewTitle,
+		OldRef:   c.OldRef,
+		NewRef:   c.NewRef,
+
+		RefCommitSHA: c.CommitSHA,
+		ReviewID:     c.ReviewID,
+
+		RemovedAssignee: c.RemovedAssignee,
+	}
+
+	if c.OldMilestone != nil {
+		comment.OldMilestone = ToAPIMilestone(c.OldMilestone)
+	}
+	if c.Milestone != nil {
+		comment.Milestone = ToAPIMilestone(c.Milestone)
+	}
+	if c.Label != nil {
+		comment.Label = ToLabel(c.Label)
+		comment.LabelRemoved = c.Content != "1"
+		comment.Body = ""
+	}
+	if c.Assignee != nil {
+		comment.Assignee = ToUser(c.Assignee, false, false)
+	}
+	if c.AssigneeTeam != nil {
+		comment.AssigneeTeam = ToTeam(c.AssigneeTeam)
+	}
+	if models.CommentTypeIsRef(c.Type) {
+		comment.RefAction = xrefActionStrings[c.RefAction]
+	}
+	if c.RefIssue != nil {
+		comment.RefIssue = ToAPIIssue(c.RefIssue)
+	}
+	if c.RefComment != nil {
+		comment.RefComment = ToComment(c.RefComment)
+	}
+	if c.DependentIssue != nil {
+		comment.DependentIssue = ToAPIIssue(c.DependentIssue)
+	}
+	return comment
+}
@@ -152,6 +152,7 @@ func ToAPIPullRequest(pr *models.PullRequest) *api.PullRequest {
 		}
 	}
 
+	apiPullRequest.MergeableState = pr.Status.Name()
 	if pr.Status != models.PullRequestStatusChecking {
 		mergeable := !(pr.Status == models.PullRequestStatusConflict || pr.Status == models.PullRequestStatusError) && !pr.IsWorkInProgress()
 		apiPullRequest.Mergeable = mergeable
@@ -54,3 +54,15 @@ func ToCombinedStatus(statuses []*models.CommitStatus, repo *api.Repository) *ap
 
 	return retStatus
 }
+
+// ToCommitStatusAnnotation converts models.CommitStatusAnnotation to api.CommitStatusAnnotation
+func ToCommitStatusAnnotation(annotation *models.CommitStatusAnnotation) *api.CommitStatusAnnotation {
+	return &api.CommitStatusAnnotation{
+		Context: annotation.Context,
+		Path:    annotation.Path,
+		Line:    annotation.Line,
+		Level:   annotation.Level,
+		Message: annotation.Message,
+		Created: annotation.CreatedUnix.AsTime(),
+	}
+}
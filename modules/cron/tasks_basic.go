@@ -13,6 +13,7 @@ import (
 	repository_service "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 func registerUpdateMirrorTask() {
@@ -125,6 +126,40 @@ func registerCleanupHookTaskTable() {
 	})
 }
 
+func registerCleanupAuditLog() {
+	RegisterTaskFatal("cleanup_audit_log", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		OlderThan: 365 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *models.User, config Config) error {
+		realConfig := config.(*OlderThanConfig)
+		return models.DeleteOldAuditEvents(ctx, realConfig.OlderThan)
+	})
+}
+
+func registerPurgeDeletedRepositories() {
+	RegisterTaskFatal("purge_deleted_repositories", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return repo_service.PurgeDeletedRepositories(ctx)
+	})
+}
+
+func registerCheckRepoRefCounts() {
+	RegisterTaskFatal("check_repo_ref_counts", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *models.User, _ Config) error {
+		return repo_service.CheckRefCounts(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
@@ -136,4 +171,7 @@ func initBasicTasks() {
 		registerUpdateMigrationPosterID()
 	}
 	registerCleanupHookTaskTable()
+	registerCleanupAuditLog()
+	registerCheckRepoRefCounts()
+	registerPurgeDeletedRepositories()
 }
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 func registerDeleteInactiveUsers() {
@@ -117,6 +118,20 @@ func registerRemoveRandomAvatars() {
 	})
 }
 
+func registerCloseStalePullRequests() {
+	RegisterTaskFatal("close_stale_pull_requests", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		OlderThan: 7 * 24 * time.Hour,
+	}, func(ctx context.Context, doer *models.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return pull_service.CloseStalePulls(ctx, doer, olderThanConfig.OlderThan)
+	})
+}
+
 func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
@@ -127,4 +142,5 @@ func initExtendedTasks() {
 	registerReinitMissingRepositories()
 	registerDeleteMissingRepositories()
 	registerRemoveRandomAvatars()
+	registerCloseStalePullRequests()
 }
@@ -5,6 +5,7 @@
 package forms
 
 import (
+	"mime/multipart"
 	"net/http"
 
 	"code.gitea.io/gitea/modules/context"
@@ -18,6 +19,7 @@ type AdminCreateUserForm struct {
 	LoginType          string `binding:"Required"`
 	LoginName          string
 	UserName           string `binding:"Required;AlphaDashDot;MaxSize(40)"`
+	FullName           string `binding:"MaxSize(100)"`
 	Email              string `binding:"Required;Email;MaxSize(254)"`
 	Password           string `binding:"MaxSize(255)"`
 	SendNotify         bool
@@ -30,6 +32,21 @@ func (f *AdminCreateUserForm) Validate(req *http.Request, errs binding.Errors) b
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// AdminImportUsersForm form for admin to import users from a CSV file, the content of the file
+// is sent back with the confirmation of the previewed import
+type AdminImportUsersForm struct {
+	File       *multipart.FileHeader
+	Content    string
+	SendNotify bool
+	Confirm    bool
+}
+
+// Validate validates form fields
+func (f *AdminImportUsersForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // AdminEditUserForm form for admin to create user
 type AdminEditUserForm struct {
 	LoginType               string `binding:"Required"`
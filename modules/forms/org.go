@@ -46,6 +46,10 @@ type UpdateOrgSettingForm struct {
 	Visibility                structs.VisibleType
 	MaxRepoCreation           int
 	RepoAdminChangeTeamAccess bool
+	MembersCanManageProjects  bool
+	RequireTwoFactor          bool
+	DefaultRepoVisibility     string `binding:"OmitEmpty;In(private,public)"`
+	ForceRepoPrivate          bool
 }
 
 // Validate validates the fields
@@ -54,6 +58,18 @@ func (f *UpdateOrgSettingForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// OrgPushPolicyForm form for changing the push policy of an organization
+type OrgPushPolicyForm struct {
+	ForbiddenPaths            string
+	ForbiddenPathsUseDefaults bool
+}
+
+// Validate validates the fields
+func (f *OrgPushPolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ___________
 // \__    ___/___ _____    _____
 //   |    |_/ __ \\__  \  /     \
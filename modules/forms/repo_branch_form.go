@@ -24,3 +24,14 @@ func (f *NewBranchForm) Validate(req *http.Request, errs binding.Errors) binding
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// DeleteMergedBranchesForm form for deleting the confirmed list of merged branches
+type DeleteMergedBranchesForm struct {
+	Names []string `json:"names" binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *DeleteMergedBranchesForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
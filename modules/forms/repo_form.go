@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
@@ -119,19 +120,33 @@ type RepoSettingForm struct {
 	MirrorUsername string
 	MirrorPassword string
 	Private        bool
+
+	// Mirror credentials
+	MirrorAuthType           int `binding:"Range(0,2)"`
+	MirrorCredentialHelper   string
+	MirrorHelperInput        string
+	MirrorOAuth2TokenURL     string `form:"mirror_oauth2_token_url" binding:"ValidUrl"`
+	MirrorOAuth2ClientID     string `form:"mirror_oauth2_client_id"`
+	MirrorOAuth2ClientSecret string `form:"mirror_oauth2_client_secret"`
+	MirrorOAuth2RefreshToken string `form:"mirror_oauth2_refresh_token"`
+	MirrorOAuth2Username     string `form:"mirror_oauth2_username"`
+
 	Template       bool
+	RequireGitAuth bool
 	EnablePrune    bool
 
 	// Advanced settings
 	EnableWiki                            bool
 	EnableExternalWiki                    bool
 	ExternalWikiURL                       string
+	WikiIncludeTOC                        bool
 	EnableIssues                          bool
 	EnableExternalTracker                 bool
 	ExternalTrackerURL                    string
 	TrackerURLFormat                      string
 	TrackerIssueStyle                     string
 	EnableCloseIssuesViaCommitInAnyBranch bool
+	AllowedReactions                      []string
 	EnableProjects                        bool
 	EnablePulls                           bool
 	PullsIgnoreWhitespace                 bool
@@ -141,16 +156,23 @@ type RepoSettingForm struct {
 	PullsAllowSquash                      bool
 	PullsAllowManualMerge                 bool
 	EnableAutodetectManualMerge           bool
+	DefaultReviewers                      string
 	EnableTimetracker                     bool
 	AllowOnlyContributorsToTrackTime      bool
 	EnableIssueDependencies               bool
+	DefaultAssignees                      string
 	IsArchived                            bool
 
 	// Signing Settings
 	TrustModel string
 
+	// README Settings
+	ReadmePath        string `binding:"MaxSize(255)"`
+	ShowReadmeOverlay bool
+
 	// Admin settings
 	EnableHealthCheck bool
+	NextIssueIndex    int64
 }
 
 // Validate validates the fields
@@ -188,6 +210,8 @@ type ProtectBranchForm struct {
 	DismissStaleApprovals         bool
 	RequireSignedCommits          bool
 	ProtectedFilePatterns         string
+	EnableMergeQueue              bool
+	RequireCodeOwnerReviews       bool
 }
 
 // Validate validates the fields
@@ -196,6 +220,41 @@ func (f *ProtectBranchForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// ProtectTagForm form for changing protected tag settings
+type ProtectTagForm struct {
+	NamePattern    string `binding:"Required"`
+	AllowlistUsers string
+	AllowlistTeams string
+}
+
+// Validate validates the fields
+func (f *ProtectTagForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// PushPolicyForm form for changing the push policy of a repository
+type PushPolicyForm struct {
+	CommitMessageMode         int `binding:"Range(0,2)"`
+	CommitMessagePattern      string
+	CommitMessageAdminBypass  bool
+	MaxBlobSizeMB             int64 `binding:"Min(0)"`
+	BlobSizeExemptions        string
+	ForbiddenPaths            string
+	ForbiddenPathsUseDefaults bool
+	ForbiddenPathsAdminBypass bool
+	SecretScanningMode        int `binding:"Range(0,2)"`
+	BranchNameMode            int `binding:"Range(0,2)"`
+	BranchNamePatterns        string
+	BranchNameExemptions      string
+}
+
+// Validate validates the fields
+func (f *PushPolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __      ___.   .__    .__            __
 // /  \    /  \ ____\_ |__ |  |__ |  |__   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \|  |  \ /  _ \|  |/ /
@@ -215,6 +274,10 @@ type WebhookForm struct {
 	IssueMilestone       bool
 	IssueComment         bool
 	Release              bool
+	Status               bool
+	CommitComment        bool
+	Team                 bool
+	Membership           bool
 	Push                 bool
 	PullRequest          bool
 	PullRequestAssign    bool
@@ -225,7 +288,7 @@ type WebhookForm struct {
 	PullRequestSync      bool
 	Repository           bool
 	Active               bool
-	BranchFilter         string `binding:"GlobPattern"`
+	BranchFilter         string `binding:"BranchFilter"`
 }
 
 // PushOnly if the hook will be triggered when push
@@ -245,10 +308,11 @@ func (f WebhookForm) ChooseEvents() bool {
 
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	HTTPMethod  string `binding:"Required;In(POST,GET)"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL    string `binding:"Required;ValidUrl"`
+	HTTPMethod    string `binding:"Required;In(POST,GET)"`
+	ContentType   int    `binding:"Required"`
+	Secret        string
+	SignatureType string `binding:"OmitEmpty;In(sha1,sha256)"`
 	WebhookForm
 }
 
@@ -260,9 +324,10 @@ func (f *NewWebhookForm) Validate(req *http.Request, errs binding.Errors) bindin
 
 // NewGogshookForm form for creating gogs hook
 type NewGogshookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL    string `binding:"Required;ValidUrl"`
+	ContentType   int    `binding:"Required"`
+	Secret        string
+	SignatureType string `binding:"OmitEmpty;In(sha1,sha256)"`
 	WebhookForm
 }
 
@@ -371,6 +436,50 @@ func (f *NewFeishuHookForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewMattermostHookForm form for creating mattermost hook
+type NewMattermostHookForm struct {
+	PayloadURL string `binding:"Required;ValidUrl"`
+	Channel    string
+	Username   string
+	IconURL    string
+	Color      string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewMattermostHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewGoogleChatHookForm form for creating Google Chat hook
+type NewGoogleChatHookForm struct {
+	PayloadURL string `binding:"Required;ValidUrl"`
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewGoogleChatHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewJSONHookForm form for creating generic JSON hook
+type NewJSONHookForm struct {
+	PayloadURL    string `binding:"Required;ValidUrl"`
+	ContentType   int    `binding:"Required"`
+	Secret        string
+	SignatureType string `binding:"OmitEmpty;In(sha1,sha256)"`
+	Headers       string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewJSONHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -410,6 +519,19 @@ func (f *CreateCommentForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// CreateCommitCommentForm form for creating a comment on a commit
+type CreateCommitCommentForm struct {
+	Content  string `binding:"Required"`
+	TreePath string
+	Line     int64
+}
+
+// Validate validates the fields
+func (f *CreateCommitCommentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ReactionForm form for adding and removing reaction
 type ReactionForm struct {
 	Content string `binding:"Required"`
@@ -448,6 +570,56 @@ func (i IssueLockForm) HasValidReason() bool {
 	return false
 }
 
+// PinnedIssuesOrderForm form for reordering the pinned issues or pull requests of a repository
+type PinnedIssuesOrderForm struct {
+	IsPull   bool    `json:"is_pull"`
+	IssueIDs []int64 `json:"issue_ids"`
+}
+
+// Validate validates the fields
+func (f *PinnedIssuesOrderForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ExtractCommentForm form for creating a new issue from a comment
+type ExtractCommentForm struct {
+	Title         string `binding:"Required;MaxSize(255)"`
+	InheritLabels bool
+}
+
+// Validate validates the fields
+func (f *ExtractCommentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// BulkEditIssuesForm form for applying one change to several issues or pull requests of a repository
+type BulkEditIssuesForm struct {
+	IssueIndexes []int64 `json:"issue_indexes" binding:"Required"`
+	Action       string  `json:"action" binding:"Required;In(add_label,remove_label,set_milestone,set_assignee,close,reopen)"`
+	// ID is the label, milestone or assignee of the action, zero removes the milestone or all the assignees
+	ID int64 `json:"id"`
+}
+
+// Validate validates the fields
+func (f *BulkEditIssuesForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// IssueDeadlineForm form for setting or removing the due date of an issue
+type IssueDeadlineForm struct {
+	// DueDate is nil to remove the due date
+	DueDate *time.Time `json:"due_date"`
+}
+
+// Validate validates the fields
+func (f *IssueDeadlineForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // __________                   __               __
 // \______   \_______  ____    |__| ____   _____/  |_  ______
 //  |     ___/\_  __ \/  _ \   |  |/ __ \_/ ___\   __\/  ___/
@@ -475,6 +647,8 @@ type UserCreateProjectForm struct {
 type EditProjectBoardForm struct {
 	Title   string `binding:"Required;MaxSize(100)"`
 	Sorting int8
+	// Triggers are the triggers of the active rules of the board, the rules are kept as is when nil
+	Triggers []models.ProjectBoardTrigger
 }
 
 //    _____  .__.__                   __
@@ -89,6 +89,7 @@ type RegisterForm struct {
 	Retype             string
 	GRecaptchaResponse string `form:"g-recaptcha-response"`
 	HcaptchaResponse   string `form:"h-captcha-response"`
+	TurnstileResponse  string `form:"cf-turnstile-response"`
 }
 
 // Validate validates the fields
@@ -214,6 +215,20 @@ func (f *AccessTokenForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// RevokeTokenForm for revoking access or refresh tokens (RFC 7009)
+type RevokeTokenForm struct {
+	Token         string `json:"token"`
+	TokenTypeHint string `json:"token_type_hint"`
+	ClientID      string `json:"client_id"`
+	ClientSecret  string `json:"client_secret"`
+}
+
+// Validate validates the fields
+func (f *RevokeTokenForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //   __________________________________________.___ _______    ________  _________
 //  /   _____/\_   _____/\__    ___/\__    ___/|   |\      \  /  _____/ /   _____/
 //  \_____  \  |    __)_   |    |     |    |   |   |/   |   \/   \  ___ \_____  \
@@ -399,3 +414,25 @@ func (f *U2FDeleteForm) Validate(req *http.Request, errs binding.Errors) binding
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// WebAuthnRegistrationForm for reserving a WebAuthn credential name
+type WebAuthnRegistrationForm struct {
+	Name string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates the fields
+func (f *WebAuthnRegistrationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// WebAuthnDeleteForm for deleting WebAuthn credentials
+type WebAuthnDeleteForm struct {
+	ID int64 `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *WebAuthnDeleteForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
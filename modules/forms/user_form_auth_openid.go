@@ -30,6 +30,7 @@ type SignUpOpenIDForm struct {
 	Email              string `binding:"Required;Email;MaxSize(254)"`
 	GRecaptchaResponse string `form:"g-recaptcha-response"`
 	HcaptchaResponse   string `form:"h-captcha-response"`
+	TurnstileResponse  string `form:"cf-turnstile-response"`
 }
 
 // Validate validates the fields
@@ -0,0 +1,149 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// AdvertisementFilter removes capabilities from the advertisement of upload-pack or receive-pack, in the
+// pkt-line format, written to it before writing it to the underlying writer. With the protocol versions 0
+// and 1 the capabilities follow the first ref, with version 2 each one is on its own line, possibly with
+// a list of features as value, until the first flush packet.
+type AdvertisementFilter struct {
+	w        io.Writer
+	disabled map[string]bool
+	v2       bool
+	buf      []byte
+	done     bool
+}
+
+// NewAdvertisementFilter returns a filter removing the disabled capabilities from the advertisement
+// written to w, which uses protocol version 2 if v2 is true
+func NewAdvertisementFilter(w io.Writer, disabled []string, v2 bool) *AdvertisementFilter {
+	f := &AdvertisementFilter{
+		w:        w,
+		disabled: make(map[string]bool, len(disabled)),
+		v2:       v2,
+	}
+	for _, capability := range disabled {
+		f.disabled[capability] = true
+	}
+	return f
+}
+
+// Write implements io.Writer, the packets are written once complete until the capabilities have been
+// filtered, the rest of the advertisement is written as is
+func (f *AdvertisementFilter) Write(p []byte) (int, error) {
+	if f.done {
+		return f.w.Write(p)
+	}
+
+	f.buf = append(f.buf, p...)
+	for !f.done && len(f.buf) >= 4 {
+		length, err := strconv.ParseUint(string(f.buf[:4]), 16, 16)
+		if err != nil {
+			return 0, fmt.Errorf("invalid pkt-line length %q", f.buf[:4])
+		}
+		if length < 4 {
+			// flush, delimiter or response end packet, the capabilities end with the first flush
+			if _, err := f.w.Write(f.buf[:4]); err != nil {
+				return 0, err
+			}
+			f.buf = f.buf[4:]
+			f.done = length == 0
+			continue
+		}
+		if len(f.buf) < int(length) {
+			break
+		}
+
+		line, keep := f.filterLine(string(f.buf[4:length]))
+		f.buf = f.buf[length:]
+		if keep {
+			if _, err := fmt.Fprintf(f.w, "%04x%s", len(line)+4, line); err != nil {
+				return 0, err
+			}
+		}
+		// with protocol versions 0 and 1 only the first line holds capabilities
+		f.done = !f.v2
+	}
+
+	if f.done && len(f.buf) > 0 {
+		if _, err := f.w.Write(f.buf); err != nil {
+			return 0, err
+		}
+		f.buf = nil
+	}
+	return len(p), nil
+}
+
+// Close writes what remains of an incomplete advertisement as is
+func (f *AdvertisementFilter) Close() error {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	_, err := f.w.Write(f.buf)
+	f.buf = nil
+	return err
+}
+
+// filterLine returns the line without the disabled capabilities, or false if the whole line is a
+// disabled capability of protocol version 2
+func (f *AdvertisementFilter) filterLine(line string) (string, bool) {
+	if f.v2 {
+		if strings.HasPrefix(line, "version ") {
+			return line, true
+		}
+		capability := strings.TrimSuffix(line, "\n")
+		key, value := capability, ""
+		if i := strings.IndexByte(capability, '='); i >= 0 {
+			key, value = capability[:i], capability[i+1:]
+		}
+		if f.disabled[key] {
+			return "", false
+		}
+		if value == "" {
+			return line, true
+		}
+		// e.g. fetch=shallow filter
+		features := f.filterList(value)
+		if features == "" {
+			return key + "\n", true
+		}
+		return key + "=" + features + "\n", true
+	}
+
+	i := strings.IndexByte(line, 0)
+	if i < 0 {
+		return line, true
+	}
+	capabilities := strings.TrimSuffix(line[i+1:], "\n")
+	return line[:i+1] + f.filterList(capabilities) + "\n", true
+}
+
+// filterList removes the disabled capabilities from a space separated list, where each one can have
+// a value like symref=HEAD:refs/heads/master
+func (f *AdvertisementFilter) filterList(list string) string {
+	var buf bytes.Buffer
+	for _, capability := range strings.Fields(list) {
+		name := capability
+		if i := strings.IndexByte(capability, '='); i >= 0 {
+			name = capability[:i]
+		}
+		if f.disabled[name] {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(capability)
+	}
+	return buf.String()
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pktLine(line string) string {
+	return fmt.Sprintf("%04x%s", len(line)+4, line)
+}
+
+func TestAdvertisementFilter(t *testing.T) {
+	const sha = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	refs := pktLine(sha+" refs/heads/master\n") + pktLine(sha+" refs/tags/v1.0\n") + "0000"
+
+	var buf bytes.Buffer
+	f := NewAdvertisementFilter(&buf, []string{"multi_ack", "ofs-delta", "symref"}, false)
+	input := pktLine(sha+" HEAD\x00multi_ack thin-pack ofs-delta symref=HEAD:refs/heads/master agent=git/2.30.0\n") + refs
+	// split the input to check incomplete packets are buffered
+	for i := 0; i < len(input); i += 7 {
+		end := i + 7
+		if end > len(input) {
+			end = len(input)
+		}
+		n, err := f.Write([]byte(input[i:end]))
+		assert.NoError(t, err)
+		assert.Equal(t, end-i, n)
+	}
+	assert.NoError(t, f.Close())
+	assert.Equal(t, pktLine(sha+" HEAD\x00thin-pack agent=git/2.30.0\n")+refs, buf.String())
+
+	buf.Reset()
+	f = NewAdvertisementFilter(&buf, []string{"object-format", "filter", "shallow"}, true)
+	_, err := f.Write([]byte(pktLine("version 2\n") + pktLine("agent=git/2.30.0\n") + pktLine("ls-refs\n") +
+		pktLine("fetch=shallow filter\n") + pktLine("object-format=sha1\n") + "0000"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.Equal(t, pktLine("version 2\n")+pktLine("agent=git/2.30.0\n")+pktLine("ls-refs\n")+pktLine("fetch\n")+"0000", buf.String())
+
+	_, err = NewAdvertisementFilter(&buf, nil, false).Write([]byte("zzzz"))
+	assert.Error(t, err)
+}
@@ -7,7 +7,11 @@ package git
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -20,6 +24,8 @@ const (
 	ZIP ArchiveType = iota + 1
 	// TARGZ tar gz archive type
 	TARGZ
+	// BUNDLE git bundle archive type
+	BUNDLE
 )
 
 // String converts an ArchiveType to string
@@ -29,6 +35,8 @@ func (a ArchiveType) String() string {
 		return "zip"
 	case TARGZ:
 		return "tar.gz"
+	case BUNDLE:
+		return "bundle"
 	}
 	return "unknown"
 }
@@ -37,11 +45,36 @@ func (a ArchiveType) String() string {
 type CreateArchiveOpts struct {
 	Format ArchiveType
 	Prefix bool
+	// Subdir limits the archive to this directory, keeping its path
+	Subdir string
 }
 
-// CreateArchive create archive content to the target path
+// ArchiveAttributesKey returns a short key derived from the repository-local
+// attributes file ($GIT_DIR/info/attributes). git archive honors
+// export-ignore and export-subst from both the archived tree and this file,
+// so archives generated for the same commit differ whenever it changes.
+// An empty string is returned when the repository has no such file.
+func (repo *Repository) ArchiveAttributesKey() (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(repo.Path, "info", "attributes"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if len(content) == 0 {
+		return "", nil
+	}
+	sum := sha1.Sum(content)
+	return hex.EncodeToString(sum[:])[:10], nil
+}
+
+// CreateArchive create archive content to the target path.
+// Paths marked export-ignore in .gitattributes are left out of the archive
+// and files marked export-subst have their $Format:...$ keywords expanded,
+// matching the behavior of a native git archive.
 func (c *Commit) CreateArchive(ctx context.Context, target string, opts CreateArchiveOpts) error {
-	if opts.Format.String() == "unknown" {
+	if opts.Format == BUNDLE || opts.Format.String() == "unknown" {
 		return fmt.Errorf("unknown format: %v", opts.Format)
 	}
 
@@ -58,7 +91,18 @@ func (c *Commit) CreateArchive(ctx context.Context, target string, opts CreateAr
 		target,
 		c.ID.String(),
 	)
+	if opts.Subdir != "" {
+		args = append(args, "--", ":(literal)"+opts.Subdir)
+	}
 
 	_, err := NewCommandContext(ctx, args...).RunInDir(c.repo.Path)
 	return err
 }
+
+// CreateBundle writes a git bundle containing the given ref and its full
+// history to the target path. The resulting file can be cloned from or
+// fetched into like a regular remote.
+func (repo *Repository) CreateBundle(ctx context.Context, target, refName string) error {
+	_, err := NewCommandContext(ctx, "bundle", "create", target, refName).RunInDir(repo.Path)
+	return err
+}
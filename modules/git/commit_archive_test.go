@@ -0,0 +1,123 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"archive/zip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareArchiveAttributesRepo(t *testing.T) (string, string) {
+	tmpDir, err := ioutil.TempDir("", "archive_attributes")
+	assert.NoError(t, err)
+
+	files := map[string]string{
+		".gitattributes": "secret.txt export-ignore\nignored/ export-ignore\nversion.txt export-subst\n",
+		"secret.txt":     "do not ship\n",
+		"ignored/a.txt":  "a\n",
+		"version.txt":    "$Format:%H$\n",
+		"README.md":      "readme\n",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Join(tmpDir, filepath.Dir(name)), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	assert.NoError(t, InitRepository(tmpDir, false))
+	_, err = NewCommand("add", "--all").RunInDir(tmpDir)
+	assert.NoError(t, err)
+	_, err = NewCommand("-c", "user.name=Gitea", "-c", "user.email=gitea@fake.local", "commit", "-m", "init").RunInDir(tmpDir)
+	assert.NoError(t, err)
+
+	sha, err := NewCommand("rev-parse", "HEAD").RunInDir(tmpDir)
+	assert.NoError(t, err)
+
+	// Gitea always archives from bare repositories.
+	barePath := tmpDir + ".git"
+	assert.NoError(t, Clone(tmpDir, barePath, CloneRepoOptions{Bare: true, Quiet: true}))
+	assert.NoError(t, util.RemoveAll(tmpDir))
+	return barePath, sha[:40]
+}
+
+func readZipEntries(t *testing.T, path string) map[string]string {
+	r, err := zip.OpenReader(path)
+	assert.NoError(t, err)
+	defer r.Close()
+
+	entries := make(map[string]string)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := ioutil.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		entries[f.Name] = string(content)
+	}
+	return entries
+}
+
+func TestCommit_CreateArchiveAttributes(t *testing.T) {
+	repoPath, sha := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	commit, err := repo.GetCommit(sha)
+	assert.NoError(t, err)
+
+	target := filepath.Join(repoPath, "..", filepath.Base(repoPath)+".zip")
+	defer util.Remove(target)
+	assert.NoError(t, commit.CreateArchive(context.Background(), target, CreateArchiveOpts{Format: ZIP}))
+
+	// Compare against what git itself produces for the same commit.
+	native := target + ".native.zip"
+	defer util.Remove(native)
+	_, err = NewCommand("archive", "--format=zip", "-o", native, sha).RunInDir(repoPath)
+	assert.NoError(t, err)
+
+	entries := readZipEntries(t, target)
+	assert.Equal(t, readZipEntries(t, native), entries)
+	assert.NotContains(t, entries, "secret.txt")
+	assert.NotContains(t, entries, "ignored/a.txt")
+	assert.Contains(t, entries, "README.md")
+	assert.Equal(t, sha+"\n", entries["version.txt"])
+}
+
+func TestRepository_ArchiveAttributesKey(t *testing.T) {
+	repoPath, _ := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+
+	repo, err := OpenRepository(repoPath)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	key, err := repo.ArchiveAttributesKey()
+	assert.NoError(t, err)
+	assert.Empty(t, key)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo.Path, "info"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repo.Path, "info", "attributes"), []byte("README.md export-ignore\n"), 0644))
+	key1, err := repo.ArchiveAttributesKey()
+	assert.NoError(t, err)
+	assert.Len(t, key1, 10)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repo.Path, "info", "attributes"), []byte("*.md export-ignore\n"), 0644))
+	key2, err := repo.ArchiveAttributesKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key1, key2)
+}
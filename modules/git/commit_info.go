@@ -4,9 +4,33 @@
 
 package git
 
+import "path"
+
 // CommitInfo describes the first commit with the provided entry
 type CommitInfo struct {
 	Entry         *TreeEntry
 	Commit        *Commit
 	SubModuleFile *SubModuleFile
 }
+
+// GetLastCommitForPath returns the last commit which changed the entry at the path, starting from the
+// commit. A rename counts as a change of the entry at the new path. The cache is used when it is not nil.
+func (c *Commit) GetLastCommitForPath(relpath string, cache *LastCommitCache) (*Commit, error) {
+	entry, err := c.GetTreeEntryByPath(relpath)
+	if err != nil {
+		return nil, err
+	}
+
+	treePath := path.Dir(relpath)
+	if treePath == "." {
+		treePath = ""
+	}
+	commitsInfo, _, err := Entries{entry}.GetCommitsInfo(c, treePath, cache)
+	if err != nil {
+		return nil, err
+	}
+	if len(commitsInfo) == 0 || commitsInfo[0].Commit == nil {
+		return nil, ErrNotExist{ID: relpath}
+	}
+	return commitsInfo[0].Commit, nil
+}
@@ -5,8 +5,10 @@
 package git
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -106,6 +108,80 @@ func TestEntries_GetCommitsInfo(t *testing.T) {
 	testGetCommitsInfo(t, clonedRepo1)
 }
 
+func TestCommit_GetLastCommitForPath(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	testCases := []struct {
+		CommitID   string
+		Path       string
+		ExpectedID string
+	}{
+		{"feaf4ba6bc635fec442f46ddd4512416ec43c2c2", "file1.txt", "95bb4d39648ee7e325106df01a621c530863a653"},
+		{"feaf4ba6bc635fec442f46ddd4512416ec43c2c2", "file2.txt", "8d92fc957a4d7cfd98bc375f0b7bb189a0d6c9f2"},
+		{"5c80b0245c1c6f8343fa418ec374b13b5d4ee658", "branch2/branch2.txt", "5c80b0245c1c6f8343fa418ec374b13b5d4ee658"},
+	}
+	for _, testCase := range testCases {
+		commit, err := bareRepo1.GetCommit(testCase.CommitID)
+		assert.NoError(t, err)
+		lastCommit, err := commit.GetLastCommitForPath(testCase.Path, nil)
+		assert.NoError(t, err)
+		if assert.NotNil(t, lastCommit) {
+			assert.Equal(t, testCase.ExpectedID, lastCommit.ID.String(), "path %s at %s", testCase.Path, testCase.CommitID)
+		}
+	}
+
+	commit, err := bareRepo1.GetCommit("feaf4ba6bc635fec442f46ddd4512416ec43c2c2")
+	assert.NoError(t, err)
+	_, err = commit.GetLastCommitForPath("does-not-exist.txt", nil)
+	assert.True(t, IsErrNotExist(err))
+}
+
+func TestCommit_GetLastCommitForPathRenamed(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "last_commit_renamed")
+	assert.NoError(t, err)
+	defer util.RemoveAll(tmpDir)
+	assert.NoError(t, InitRepository(tmpDir, false))
+
+	commit := func(message string, args ...string) string {
+		if len(args) > 0 {
+			_, err := NewCommand(args...).RunInDir(tmpDir)
+			assert.NoError(t, err)
+		}
+		_, err := NewCommand("add", "--all").RunInDir(tmpDir)
+		assert.NoError(t, err)
+		_, err = NewCommand("-c", "user.name=Gitea", "-c", "user.email=gitea@fake.local", "commit", "-m", message).RunInDir(tmpDir)
+		assert.NoError(t, err)
+		sha, err := NewCommand("rev-parse", "HEAD").RunInDir(tmpDir)
+		assert.NoError(t, err)
+		return strings.TrimSpace(sha)
+	}
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "old.txt"), []byte("old\n"), 0644))
+	commit("add old.txt")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "other.txt"), []byte("other\n"), 0644))
+	commit("add other.txt")
+	renamed := commit("rename old.txt", "mv", "old.txt", "new.txt")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "other.txt"), []byte("changed\n"), 0644))
+	changed := commit("change other.txt")
+
+	repo, err := OpenRepository(tmpDir)
+	assert.NoError(t, err)
+	defer repo.Close()
+	head, err := repo.GetCommit(changed)
+	assert.NoError(t, err)
+
+	lastCommit, err := head.GetLastCommitForPath("new.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, renamed, lastCommit.ID.String())
+
+	lastCommit, err = head.GetLastCommitForPath("other.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, changed, lastCommit.ID.String())
+}
+
 func BenchmarkEntries_GetCommitsInfo(b *testing.B) {
 	benchmarks := []struct {
 		url  string
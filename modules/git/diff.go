@@ -97,6 +97,31 @@ func GetRepoRawDiffForFile(repo *Repository, startCommit, endCommit string, diff
 	return nil
 }
 
+// GetRepoRawDiffForRange dumps the changes of the commits after startCommit up to endCommit to the writer,
+// either as a single diff of all of them if combined, or commit by commit.
+func GetRepoRawDiffForRange(repo *Repository, startCommit, endCommit string, diffType RawDiffType, combined bool, writer io.Writer) error {
+	var cmd *Command
+	switch {
+	case diffType == RawDiffNormal && combined:
+		cmd = NewCommand("diff", "-M", startCommit, endCommit)
+	case diffType == RawDiffNormal:
+		cmd = NewCommand("log", "--reverse", "--patch", "-M", startCommit+".."+endCommit)
+	case diffType == RawDiffPatch && combined:
+		// binary changes are kept so that the patch can be applied
+		cmd = NewCommand("diff", "-M", "--binary", startCommit, endCommit)
+	case diffType == RawDiffPatch:
+		cmd = NewCommand("format-patch", "--no-signature", "--stdout", "-M", startCommit+".."+endCommit)
+	default:
+		return fmt.Errorf("invalid diffType: %s", diffType)
+	}
+
+	stderr := new(bytes.Buffer)
+	if err := cmd.RunInDirPipeline(repo.Path, writer, stderr); err != nil {
+		return fmt.Errorf("Run: %v - %s", err, stderr)
+	}
+	return nil
+}
+
 // ParseDiffHunkString parse the diffhunk content and return
 func ParseDiffHunkString(diffhunk string) (leftLine, leftHunk, rightLine, righHunk int) {
 	ss := strings.Split(diffhunk, "@@")
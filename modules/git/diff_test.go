@@ -5,6 +5,8 @@
 package git
 
 import (
+	"bytes"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -132,3 +134,26 @@ func TestParseDiffHunkString(t *testing.T) {
 	assert.EqualValues(t, 19, rightLine)
 	assert.EqualValues(t, 5, rightHunk)
 }
+
+func TestGetRepoRawDiffForRange(t *testing.T) {
+	repo, err := OpenRepository(filepath.Join(testReposDir, "repo1_bare"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	const start, end = "8006ff9adbf0cb94da7dad9e537e53817f9fa5c0", "feaf4ba6bc635fec442f46ddd4512416ec43c2c2"
+
+	var combined, perCommit bytes.Buffer
+	assert.NoError(t, GetRepoRawDiffForRange(repo, start, end, RawDiffNormal, true, &combined))
+	assert.True(t, strings.HasPrefix(combined.String(), "diff --git "))
+	assert.NotContains(t, combined.String(), "commit ")
+
+	assert.NoError(t, GetRepoRawDiffForRange(repo, start, end, RawDiffNormal, false, &perCommit))
+	assert.Equal(t, 3, strings.Count(perCommit.String(), "\ncommit ")+1)
+
+	var patches bytes.Buffer
+	assert.NoError(t, GetRepoRawDiffForRange(repo, start, end, RawDiffPatch, false, &patches))
+	assert.Contains(t, patches.String(), "Subject: [PATCH 1/3] Added broken links")
+	assert.Contains(t, patches.String(), "Subject: [PATCH 2/3] Added short link")
+
+	assert.Error(t, GetRepoRawDiffForRange(repo, start, end, "other", false, &patches))
+}
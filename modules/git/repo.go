@@ -377,3 +377,79 @@ func GetDivergingCommits(repoPath string, baseBranch string, targetBranch string
 
 	return DivergeObject{ahead, behind}, nil
 }
+
+// GetBranchesDivergingCommits returns the number of commits each of the branches is ahead or behind a
+// baseBranch, with a single git command for all of them if git supports it and one per branch otherwise
+func GetBranchesDivergingCommits(repoPath, baseBranch string, branches []string) (map[string]DivergeObject, error) {
+	divergences := make(map[string]DivergeObject, len(branches))
+	if len(branches) == 0 {
+		return divergences, nil
+	}
+
+	if CheckGitVersionAtLeast("2.41") == nil {
+		args := []string{"for-each-ref", "--format=%(refname:lstrip=2) %(ahead-behind:" + baseBranch + ")"}
+		for _, branch := range branches {
+			args = append(args, BranchPrefix+branch)
+		}
+		stdout, err := NewCommand(args...).RunInDir(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		wanted := make(map[string]bool, len(branches))
+		for _, branch := range branches {
+			wanted[branch] = true
+		}
+		for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+			// <branch> <ahead> <behind>, the branch name cannot contain spaces
+			fields := strings.Fields(line)
+			if len(fields) != 3 || !wanted[fields[0]] {
+				continue
+			}
+			ahead, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			behind, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, err
+			}
+			divergences[fields[0]] = DivergeObject{Ahead: ahead, Behind: behind}
+		}
+		return divergences, nil
+	}
+
+	for _, branch := range branches {
+		// $(git rev-list --left-right --count master...feature) commits behind and ahead of master
+		stdout, err := NewCommand("rev-list", "--left-right", "--count", baseBranch+"..."+BranchPrefix+branch).RunInDir(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(stdout)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected output of rev-list: %q", stdout)
+		}
+		behind, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		ahead, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		divergences[branch] = DivergeObject{Ahead: ahead, Behind: behind}
+	}
+	return divergences, nil
+}
+
+// GetMergedBranches returns the names of the branches which are fully merged into the baseBranch
+func GetMergedBranches(repoPath, baseBranch string) ([]string, error) {
+	stdout, err := NewCommand("for-each-ref", "--merged="+baseBranch, "--format=%(refname:lstrip=2)", BranchPrefix).RunInDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	stdout = strings.TrimSpace(stdout)
+	if stdout == "" {
+		return []string{}, nil
+	}
+	return strings.Split(stdout, "\n"), nil
+}
@@ -142,6 +142,17 @@ func (repo *Repository) GetDiffNumChangedFiles(base, head string) (int, error) {
 	return w.numLines, nil
 }
 
+// GetFilesChangedBetween returns the names of the files changed by head since its merge base with base
+func (repo *Repository) GetFilesChangedBetween(base, head string) ([]string, error) {
+	stdout, err := NewCommand("diff", "-z", "--name-only", base+"..."+head).RunInDirBytes(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+	// git emits a terminal NUL after each file name, so the last entry of the split is always empty
+	split := strings.Split(string(stdout), "\x00")
+	return split[:len(split)-1], nil
+}
+
 // GetDiffShortStat counts number of changed files, number of additions and deletions
 func (repo *Repository) GetDiffShortStat(base, head string) (numFiles, totalAdditions, totalDeletions int, err error) {
 	numFiles, totalAdditions, totalDeletions, err = GetDiffShortStat(repo.Path, base+"..."+head)
@@ -6,7 +6,11 @@
 package git
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"io"
 	"strings"
 )
@@ -66,3 +70,70 @@ func (repo *Repository) GetRefType(ref string) ObjectType {
 	}
 	return ObjectType("invalid")
 }
+
+// GetObjectType returns the type of the object with the given ID
+func (repo *Repository) GetObjectType(id SHA1) (ObjectType, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := NewCommand("cat-file", "--batch-check=%(objecttype)").
+		RunInDirFullPipeline(repo.Path, stdout, stderr, strings.NewReader(id.String()+"\n"))
+	if err != nil {
+		return "", ConcatenateError(err, stderr.String())
+	}
+	typ := strings.TrimSpace(stdout.String())
+	if len(typ) == 0 || strings.HasSuffix(typ, " missing") {
+		return "", ErrNotExist{ID: id.String()}
+	}
+	return ObjectType(typ), nil
+}
+
+// IsObjectReachable returns whether the object is reachable from any reference of the repository.
+// Commits are looked up among the ancestors of the references, other objects require walking all the
+// objects of the history, which is expensive for large repositories so callers should cache the result
+// by the RefsHash of the repository.
+func (repo *Repository) IsObjectReachable(id SHA1, typ ObjectType) (bool, error) {
+	if typ == ObjectCommit {
+		stdout, err := NewCommand("for-each-ref", "--count=1", "--format=%(refname)", "--contains", id.String()).RunInDir(repo.Path)
+		if err != nil {
+			return false, err
+		}
+		return len(strings.TrimSpace(stdout)) > 0, nil
+	}
+
+	ctx, cancel := context.WithCancel(DefaultContext)
+	defer cancel()
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	defer stdoutReader.Close()
+
+	go func() {
+		stderr := strings.Builder{}
+		err := NewCommandContext(ctx, "rev-list", "--all", "--objects").RunInDirPipeline(repo.Path, stdoutWriter, &stderr)
+		if err != nil {
+			_ = stdoutWriter.CloseWithError(ConcatenateError(err, stderr.String()))
+		} else {
+			_ = stdoutWriter.Close()
+		}
+	}()
+
+	sha := id.String()
+	scanner := bufio.NewScanner(stdoutReader)
+	for scanner.Scan() {
+		// the lines are "<sha> <path>"
+		if strings.HasPrefix(scanner.Text(), sha) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// RefsHash returns a hash of the names and targets of all the references of the repository,
+// which changes whenever a reference is created, updated or deleted
+func (repo *Repository) RefsHash() (string, error) {
+	stdout, err := NewCommand("for-each-ref", "--format=%(objectname) %(refname)").RunInDirBytes(repo.Path)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.Sum(stdout)
+	return hex.EncodeToString(h[:]), nil
+}
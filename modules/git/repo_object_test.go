@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_GetObjectType(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	typ, err := bareRepo1.GetObjectType(MustIDFromString("e2129701f1a4d54dc44f03c93bca0a2aec7c5449"))
+	assert.NoError(t, err)
+	assert.Equal(t, ObjectBlob, typ)
+
+	typ, err = bareRepo1.GetObjectType(MustIDFromString("feaf4ba6bc635fec442f46ddd4512416ec43c2c2"))
+	assert.NoError(t, err)
+	assert.Equal(t, ObjectCommit, typ)
+
+	_, err = bareRepo1.GetObjectType(MustIDFromString("0000000000000000000000000000000000000001"))
+	assert.True(t, IsErrNotExist(err))
+}
+
+func TestRepository_IsObjectReachable(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	testCases := []struct {
+		ID        string
+		Type      ObjectType
+		Reachable bool
+	}{
+		{"feaf4ba6bc635fec442f46ddd4512416ec43c2c2", ObjectCommit, true},
+		{"37991dec2c8e592043f47155ce4808d4580f9123", ObjectCommit, true},
+		{"e2129701f1a4d54dc44f03c93bca0a2aec7c5449", ObjectBlob, true},
+		{"38441bf2c4d4c27efff94728c9eb33266f44a702", ObjectCommit, false},
+		{"0000000000000000000000000000000000000001", ObjectBlob, false},
+	}
+	for _, testCase := range testCases {
+		reachable, err := bareRepo1.IsObjectReachable(MustIDFromString(testCase.ID), testCase.Type)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.Reachable, reachable, testCase.ID)
+	}
+}
+
+func TestRepository_RefsHash(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := OpenRepository(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	hash, err := bareRepo1.RefsHash()
+	assert.NoError(t, err)
+	assert.Len(t, hash, 40)
+
+	again, err := bareRepo1.RefsHash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, again)
+}
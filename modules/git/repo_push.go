@@ -0,0 +1,255 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PushedCommit represents a commit introduced by a push which is not yet reachable from any ref
+type PushedCommit struct {
+	ID      string
+	Parents []string
+	Message string
+}
+
+// IsMerge returns true if the commit has more than one parent
+func (c *PushedCommit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+// GetPushedCommits returns the commits reachable from newCommitID which are not reachable from any
+// existing ref of the repository. It is meant to be called from a pre-receive hook, the env has to
+// contain the quarantine object directories so the incoming objects can be read.
+// At most limit commits are returned if limit is greater than zero.
+func GetPushedCommits(repoPath string, env []string, newCommitID string, limit int) ([]*PushedCommit, error) {
+	if newCommitID == EmptySHA {
+		return nil, nil
+	}
+
+	args := []string{"log", "-z", "--format=%H%n%P%n%B"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	args = append(args, newCommitID, "--not", "--all")
+
+	stdout, err := NewCommand(args...).RunInDirTimeoutEnv(env, -1, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]*PushedCommit, 0, 10)
+	for _, entry := range bytes.Split(stdout, []byte{0}) {
+		entry = bytes.TrimLeft(entry, "\n")
+		if len(entry) == 0 {
+			continue
+		}
+		fields := strings.SplitN(string(entry), "\n", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("unexpected git log output: %q", entry)
+		}
+		commit := &PushedCommit{
+			ID:      fields[0],
+			Parents: strings.Fields(fields[1]),
+		}
+		if len(fields) == 3 {
+			commit.Message = fields[2]
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// PushedFile represents a file added or modified by a pushed commit
+type PushedFile struct {
+	CommitID string
+	Path     string
+	BlobID   string
+	Size     int64
+}
+
+// GetPushedFiles returns the files added or modified by the given commits together with the size of
+// their blobs. Deleted files and submodules are skipped. Like GetPushedCommits it has to be called with
+// the quarantine environment of the pre-receive hook.
+func GetPushedFiles(repoPath string, env []string, commits []*PushedCommit) ([]*PushedFile, error) {
+	files := make([]*PushedFile, 0, len(commits))
+	if len(commits) == 0 {
+		return files, nil
+	}
+
+	// Diff all the commits in one go, the ID of every commit is printed before its entries
+	diff, err := runPushedCommitsDiffTree(repoPath, env, commits, "-r", "-z", "--root", "--no-renames")
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries have the form ":oldmode newmode oldsha newsha status\0path\0"
+	var commitID string
+	fields := bytes.Split(diff, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		if !bytes.HasPrefix(fields[i], []byte{':'}) {
+			if len(fields[i]) > 0 {
+				commitID = string(fields[i])
+			}
+			continue
+		}
+		if i+1 >= len(fields) {
+			break
+		}
+		info := strings.Fields(string(fields[i][1:]))
+		path := string(fields[i+1])
+		i++
+		if len(info) != 5 {
+			continue
+		}
+		newMode, newSHA := info[1], info[3]
+		if newSHA == EmptySHA || strings.HasPrefix(newMode, "16") {
+			continue
+		}
+		files = append(files, &PushedFile{
+			CommitID: commitID,
+			Path:     path,
+			BlobID:   newSHA,
+		})
+	}
+
+	if len(files) == 0 {
+		return files, nil
+	}
+
+	// Get the blob sizes in one go
+	stdin := new(bytes.Buffer)
+	for _, file := range files {
+		stdin.WriteString(file.BlobID)
+		stdin.WriteByte('\n')
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(strings.Builder)
+	if err := NewCommand("cat-file", "--batch-check=%(objectname) %(objectsize)").
+		RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, stdout, stderr, stdin); err != nil {
+		return nil, ConcatenateError(err, stderr.String())
+	}
+
+	sizes := make(map[string]int64, len(files))
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		sizes[fields[0]] = size
+	}
+	for _, file := range files {
+		file.Size = sizes[file.BlobID]
+	}
+	return files, nil
+}
+
+// PushedLine represents a line added by a pushed commit
+type PushedLine struct {
+	CommitID string
+	Path     string
+	Number   int
+	Content  string
+}
+
+// GetPushedAddedLines calls fn for every line added by the given commits. Binary files and merge
+// commits are skipped. Like GetPushedCommits it has to be called with the quarantine environment
+// of the pre-receive hook. Iteration stops at the first error returned by fn.
+func GetPushedAddedLines(repoPath string, env []string, commits []*PushedCommit, fn func(line *PushedLine) error) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	commitIDs := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		commitIDs[commit.ID] = true
+	}
+
+	// Diff all the commits in one go, the ID of every commit is printed on its own line before its patch
+	stdout, err := runPushedCommitsDiffTree(repoPath, env, commits, "-p", "-U0", "--no-color", "--no-renames",
+		"--no-ext-diff", "--root")
+	if err != nil {
+		return err
+	}
+
+	var commitID, path string
+	var number int
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(stdout)+1)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case commitIDs[text]:
+			commitID, path, number = text, "", 0
+		case strings.HasPrefix(text, "diff --git "):
+			path, number = "", 0
+		case strings.HasPrefix(text, "+++ "):
+			path = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			if path == "/dev/null" {
+				path = ""
+			}
+		case strings.HasPrefix(text, "@@ "):
+			// Hunk headers have the form "@@ -a[,b] +c[,d] @@"
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				continue
+			}
+			start := strings.SplitN(strings.TrimPrefix(fields[2], "+"), ",", 2)[0]
+			number, _ = strconv.Atoi(start)
+		case strings.HasPrefix(text, "+") && path != "" && number > 0:
+			if err := fn(&PushedLine{
+				CommitID: commitID,
+				Path:     path,
+				Number:   number,
+				Content:  text[1:],
+			}); err != nil {
+				return err
+			}
+			number++
+		}
+	}
+	return nil
+}
+
+// ReadPushedFile returns the content of the file at the given commit or an empty string if it does not
+// exist. Like GetPushedCommits it has to be called with the quarantine environment of the pre-receive hook.
+func ReadPushedFile(repoPath string, env []string, commitID, path string) (string, error) {
+	stdout, err := NewCommand("ls-tree", commitID, "--", path).RunInDirTimeoutEnv(env, -1, repoPath)
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return "", nil
+	}
+	stdout, err = NewCommand("cat-file", "-p", commitID+":"+path).RunInDirTimeoutEnv(env, -1, repoPath)
+	if err != nil {
+		return "", err
+	}
+	return string(stdout), nil
+}
+
+// runPushedCommitsDiffTree runs a single diff-tree for all the given commits, which are passed on stdin
+func runPushedCommitsDiffTree(repoPath string, env []string, commits []*PushedCommit, args ...string) ([]byte, error) {
+	stdin := new(bytes.Buffer)
+	for _, commit := range commits {
+		stdin.WriteString(commit.ID)
+		stdin.WriteByte('\n')
+	}
+	stdout := new(bytes.Buffer)
+	stderr := new(strings.Builder)
+	if err := NewCommand(append([]string{"diff-tree", "--stdin"}, args...)...).
+		RunInDirTimeoutEnvFullPipeline(env, -1, repoPath, stdout, stderr, stdin); err != nil {
+		return nil, ConcatenateError(err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPushedCommits(t *testing.T) {
+	repoPath, sha := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+
+	// Everything is already reachable from a ref
+	commits, err := GetPushedCommits(repoPath, nil, sha, 0)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 0)
+
+	// Create a dangling commit on top, as if it was just pushed
+	stdout, err := NewCommand("-c", "user.name=Gitea", "-c", "user.email=gitea@fake.local",
+		"commit-tree", sha+"^{tree}", "-p", sha, "-m", "feat: pushed\n\nwith body").RunInDir(repoPath)
+	assert.NoError(t, err)
+	pushed := strings.TrimSpace(stdout)
+
+	commits, err = GetPushedCommits(repoPath, nil, pushed, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, pushed, commits[0].ID)
+		assert.Equal(t, []string{sha}, commits[0].Parents)
+		assert.False(t, commits[0].IsMerge())
+		assert.Equal(t, "feat: pushed\n\nwith body\n", commits[0].Message)
+	}
+
+	commits, err = GetPushedCommits(repoPath, nil, EmptySHA, 0)
+	assert.NoError(t, err)
+	assert.Len(t, commits, 0)
+}
+
+// commitIgnoredDirAsRoot creates a commit on top of sha whose tree is the "ignored" directory,
+// so it adds a.txt and deletes everything else
+func commitIgnoredDirAsRoot(t *testing.T, repoPath, sha string) string {
+	stdout, err := NewCommand("-c", "user.name=Gitea", "-c", "user.email=gitea@fake.local",
+		"commit-tree", sha+":ignored", "-p", sha, "-m", "move a.txt").RunInDir(repoPath)
+	assert.NoError(t, err)
+	return strings.TrimSpace(stdout)
+}
+
+func TestGetPushedFiles(t *testing.T) {
+	repoPath, sha := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+	second := commitIgnoredDirAsRoot(t, repoPath, sha)
+
+	files, err := GetPushedFiles(repoPath, nil, []*PushedCommit{{ID: second}, {ID: sha}})
+	assert.NoError(t, err)
+
+	sizes := make(map[string]int64)
+	for _, file := range files {
+		assert.Len(t, file.BlobID, 40)
+		sizes[file.CommitID[:7]+":"+file.Path] = file.Size
+	}
+	assert.Equal(t, map[string]int64{
+		sha[:7] + ":.gitattributes": 73,
+		sha[:7] + ":secret.txt":     12,
+		sha[:7] + ":ignored/a.txt":  2,
+		sha[:7] + ":version.txt":    12,
+		sha[:7] + ":README.md":      7,
+		second[:7] + ":a.txt":       2,
+	}, sizes)
+
+	files, err = GetPushedFiles(repoPath, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestGetPushedAddedLines(t *testing.T) {
+	repoPath, sha := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+
+	second := commitIgnoredDirAsRoot(t, repoPath, sha)
+
+	lines := make(map[string]string)
+	err := GetPushedAddedLines(repoPath, nil, []*PushedCommit{{ID: sha}, {ID: second}}, func(line *PushedLine) error {
+		lines[fmt.Sprintf("%s:%s:%d", line.CommitID[:7], line.Path, line.Number)] = line.Content
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		sha[:7] + ":.gitattributes:1": "secret.txt export-ignore",
+		sha[:7] + ":.gitattributes:2": "ignored/ export-ignore",
+		sha[:7] + ":.gitattributes:3": "version.txt export-subst",
+		sha[:7] + ":secret.txt:1":     "do not ship",
+		sha[:7] + ":ignored/a.txt:1":  "a",
+		sha[:7] + ":version.txt:1":    "$Format:%H$",
+		sha[:7] + ":README.md:1":      "readme",
+		second[:7] + ":a.txt:1":       "a",
+	}, lines)
+}
+
+func TestReadPushedFile(t *testing.T) {
+	repoPath, sha := prepareArchiveAttributesRepo(t)
+	defer util.RemoveAll(repoPath)
+
+	content, err := ReadPushedFile(repoPath, nil, sha, "ignored/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "a\n", content)
+
+	content, err = ReadPushedFile(repoPath, nil, sha, ".gitea/secret-scanning-allow")
+	assert.NoError(t, err)
+	assert.Empty(t, content)
+}
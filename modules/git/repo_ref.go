@@ -4,6 +4,48 @@
 
 package git
 
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// PullPrefix is the prefix of the refs of the heads of pull requests
+const PullPrefix = "refs/pull/"
+
+// RefCounts holds the number of refs of a repository
+type RefCounts struct {
+	Total int64
+	// Pulls is the number of refs below PullPrefix
+	Pulls int64
+}
+
+// CountRefs counts the refs of the repository at the path, the refs are streamed rather than loaded in memory
+func CountRefs(repoPath string) (RefCounts, error) {
+	var counts RefCounts
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		stderr := strings.Builder{}
+		err := NewCommand("for-each-ref", "--format=%(refname)").RunInDirPipeline(repoPath, writer, &stderr)
+		if err != nil {
+			_ = writer.CloseWithError(ConcatenateError(err, stderr.String()))
+		} else {
+			_ = writer.Close()
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		counts.Total++
+		if strings.HasPrefix(scanner.Text(), PullPrefix) {
+			counts.Pulls++
+		}
+	}
+	return counts, scanner.Err()
+}
+
 // GetRefs returns all references of the repository.
 func (repo *Repository) GetRefs() ([]*Reference, error) {
 	return repo.GetRefsFiltered("")
@@ -50,3 +50,13 @@ func TestRepository_GetRefsFiltered(t *testing.T) {
 		assert.Equal(t, "3ad28a9149a2864384548f3d17ed7f38014c9e8a", refs[0].Object.String())
 	}
 }
+
+func TestCountRefs(t *testing.T) {
+	counts, err := CountRefs(filepath.Join(testReposDir, "repo1_bare"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, counts.Total)
+	assert.EqualValues(t, 0, counts.Pulls)
+
+	_, err = CountRefs(filepath.Join(testReposDir, "does-not-exist"))
+	assert.Error(t, err)
+}
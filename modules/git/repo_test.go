@@ -35,3 +35,24 @@ func TestRepoIsEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, isEmpty)
 }
+
+func TestGetBranchesDivergingCommits(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	divergences, err := GetBranchesDivergingCommits(bareRepo1Path, "master", []string{"branch1", "branch2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]DivergeObject{
+		"branch1": {Ahead: 2, Behind: 5},
+		"branch2": {Ahead: 1, Behind: 4},
+	}, divergences)
+}
+
+func TestGetMergedBranches(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	merged, err := GetMergedBranches(bareRepo1Path, "master")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"master"}, merged)
+
+	merged, err = GetMergedBranches(bareRepo1Path, "branch1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"branch1"}, merged)
+}
@@ -16,12 +16,14 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 )
 
-// GetCommitGraph return a list of commit (GraphItems) from all branches
-func GetCommitGraph(r *git.Repository, page int, maxAllowedColors int, hidePRRefs bool, branches, files []string) (*Graph, error) {
+// GetCommitGraph return a list of commit (GraphItems) from all branches. Only the slice of at most limit
+// commits after the first skip commits is returned, its rows are numbered from firstRow. The skipped part
+// of the graph is still parsed so that the flows and their colors match those of the previous slices.
+func GetCommitGraph(r *git.Repository, skip, limit, firstRow int, maxAllowedColors int, hidePRRefs bool, branches, files []string) (*Graph, error) {
 	format := "DATA:%D|%H|%ad|%h|%s"
 
-	if page == 0 {
-		page = 1
+	if limit <= 0 || limit > setting.UI.GraphMaxCommitNum {
+		limit = setting.UI.GraphMaxCommitNum
 	}
 
 	args := make([]string, 0, 12+len(branches)+len(files))
@@ -39,7 +41,8 @@ func GetCommitGraph(r *git.Repository, page int, maxAllowedColors int, hidePRRef
 	args = append(args,
 		"-C",
 		"-M",
-		fmt.Sprintf("-n %d", setting.UI.GraphMaxCommitNum*page),
+		// one more commit tells whether there are older ones
+		fmt.Sprintf("-n %d", skip+limit+1),
 		"--date=iso",
 		fmt.Sprintf("--pretty=format:%s", format))
 
@@ -54,13 +57,15 @@ func GetCommitGraph(r *git.Repository, page int, maxAllowedColors int, hidePRRef
 	graphCmd := git.NewCommand("log")
 	graphCmd.AddArguments(args...)
 	graph := NewGraph()
+	graph.Skip = skip
+	graph.Limit = limit
 
 	stderr := new(strings.Builder)
 	stdoutReader, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		return nil, err
 	}
-	commitsToSkip := setting.UI.GraphMaxCommitNum * (page - 1)
+	commitsToSkip := skip
 
 	scanner := bufio.NewScanner(stdoutReader)
 
@@ -91,7 +96,8 @@ func GetCommitGraph(r *git.Repository, page int, maxAllowedColors int, hidePRRef
 			parser.ParseGlyphs(line[:dataIdx])
 		}
 
-		row := 0
+		row := firstRow
+		commits := 0
 
 		// Skip initial non-commit lines
 		for scanner.Scan() {
@@ -101,14 +107,26 @@ func GetCommitGraph(r *git.Repository, page int, maxAllowedColors int, hidePRRef
 					cancel()
 					return err
 				}
+				commits++
 				break
 			}
 			parser.ParseGlyphs(line)
 		}
 
 		for scanner.Scan() {
-			row++
 			line := scanner.Bytes()
+			dataIdx := bytes.Index(line, []byte("DATA:"))
+			if dataIdx >= 0 && bytes.IndexByte(line[:dataIdx], '*') >= 0 {
+				if commits == limit {
+					graph.HasMore = true
+					// drain the rest of the output, which is at most this commit
+					for scanner.Scan() {
+					}
+					break
+				}
+				commits++
+			}
+			row++
 			if err := parser.AddLineToGraph(graph, row, line); err != nil {
 				cancel()
 				return err
@@ -33,6 +33,17 @@ type Graph struct {
 	MaxRow         int
 	MaxColumn      int
 	relationCommit *Commit
+
+	// Skip is the number of commits before this slice of the graph, Limit its maximum number of commits
+	Skip  int
+	Limit int
+	// HasMore is true if there are older commits after this slice
+	HasMore bool
+}
+
+// NextSkip returns the number of commits to skip to get the slice of the graph after this one
+func (graph *Graph) NextSkip() int {
+	return graph.Skip + graph.Limit
 }
 
 // Width returns the width of the graph
@@ -11,6 +11,8 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func BenchmarkGetCommitGraph(b *testing.B) {
@@ -22,7 +24,7 @@ func BenchmarkGetCommitGraph(b *testing.B) {
 	defer currentRepo.Close()
 
 	for i := 0; i < b.N; i++ {
-		graph, err := GetCommitGraph(currentRepo, 1, 0, false, nil, nil)
+		graph, err := GetCommitGraph(currentRepo, 0, 0, 0, 0, false, nil, nil)
 		if err != nil {
 			b.Error("Could get commit graph")
 		}
@@ -33,6 +35,35 @@ func BenchmarkGetCommitGraph(b *testing.B) {
 	}
 }
 
+func TestGetCommitGraphSlices(t *testing.T) {
+	repo, err := git.OpenRepository("../git/tests/repos/repo1_bare")
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	full, err := GetCommitGraph(repo, 0, 0, 0, 0, false, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, full.HasMore)
+
+	first, err := GetCommitGraph(repo, 0, 4, 0, 0, false, nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, 4, first.NextSkip())
+
+	second, err := GetCommitGraph(repo, first.NextSkip(), 100, first.MaxRow+1, 0, false, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, second.HasMore)
+
+	// the slices put the commits at the same rows, columns and flows as the whole graph
+	commits := append(append([]*Commit{}, first.Commits...), second.Commits...)
+	assert.Len(t, commits, len(full.Commits))
+	for i, commit := range full.Commits {
+		assert.Equal(t, commit.Rev, commits[i].Rev)
+		assert.Equal(t, commit.Row, commits[i].Row)
+		assert.Equal(t, commit.Column, commits[i].Column)
+		assert.Equal(t, commit.Flow, commits[i].Flow)
+	}
+}
+
 func BenchmarkParseCommitString(b *testing.B) {
 	testString := "* DATA:|4e61bacab44e9b4730e44a6615d04098dd3a8eaf|2016-12-20 21:10:41 +0100|4e61bac|Add route for graph"
 
@@ -21,8 +21,8 @@ import (
 	"github.com/alecthomas/chroma/styles"
 )
 
-// don't index files larger than this many bytes for performance purposes
-const sizeLimit = 1000000
+// SizeLimit is the size in bytes above which files are not highlighted for performance purposes
+const SizeLimit = 1000000
 
 var (
 	// For custom user mapping
@@ -51,7 +51,7 @@ func Code(fileName, code string) string {
 		return "\n"
 	}
 
-	if len(code) > sizeLimit {
+	if len(code) > SizeLimit {
 		return code
 	}
 	formatter := html.New(html.WithClasses(true),
@@ -98,7 +98,7 @@ func Code(fileName, code string) string {
 func File(numLines int, fileName string, code []byte) map[int]string {
 	NewContext()
 
-	if len(code) > sizeLimit {
+	if len(code) > SizeLimit {
 		return plainText(string(code), numLines)
 	}
 	formatter := html.New(html.WithClasses(true),
@@ -31,17 +31,22 @@ func generateETag(fi os.FileInfo) string {
 
 // HandleTimeCache handles time-based caching for a HTTP request
 func HandleTimeCache(req *http.Request, w http.ResponseWriter, fi os.FileInfo) (handled bool) {
+	return HandleModTimeCache(req, w, fi.ModTime())
+}
+
+// HandleModTimeCache handles time-based caching for a HTTP request of a resource last modified at the given time
+func HandleModTimeCache(req *http.Request, w http.ResponseWriter, lastModified time.Time) (handled bool) {
 	ifModifiedSince := req.Header.Get("If-Modified-Since")
 	if ifModifiedSince != "" {
 		t, err := time.Parse(http.TimeFormat, ifModifiedSince)
-		if err == nil && fi.ModTime().Unix() <= t.Unix() {
+		if err == nil && lastModified.Unix() <= t.Unix() {
 			w.WriteHeader(http.StatusNotModified)
 			return true
 		}
 	}
 
 	w.Header().Set("Cache-Control", GetCacheControl())
-	w.Header().Set("Last-Modified", fi.ModTime().Format(http.TimeFormat))
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
 	return false
 }
 
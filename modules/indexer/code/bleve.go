@@ -176,7 +176,7 @@ func NewBleveIndexer(indexDir string) (*BleveIndexer, bool, error) {
 	return indexer, created, err
 }
 
-func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, commitSha string, update fileUpdate, repo *models.Repository, batch rupture.FlushingBatch) error {
+func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, commitSha string, update fileUpdate, repoID int64, repoPath string, batch rupture.FlushingBatch) error {
 	// Ignore vendored files in code search
 	if setting.Indexer.ExcludeVendored && enry.IsVendor(update.Filename) {
 		return nil
@@ -186,7 +186,7 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 
 	if !update.Sized {
 		stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
-			RunInDir(repo.RepoPath())
+			RunInDir(repoPath)
 		if err != nil {
 			return err
 		}
@@ -196,7 +196,7 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 	}
 
 	if size > setting.Indexer.MaxIndexerFileSize {
-		return b.addDelete(update.Filename, repo, batch)
+		return b.addDelete(update.Filename, repoID, batch)
 	}
 
 	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
@@ -216,9 +216,9 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 		return nil
 	}
 
-	id := filenameIndexerID(repo.ID, update.Filename)
+	id := filenameIndexerID(repoID, update.Filename)
 	return batch.Index(id, &RepoIndexerData{
-		RepoID:    repo.ID,
+		RepoID:    repoID,
 		CommitID:  commitSha,
 		Content:   string(charset.ToUTF8DropErrors(fileContents)),
 		Language:  analyze.GetCodeLanguage(update.Filename, fileContents),
@@ -226,8 +226,8 @@ func (b *BleveIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.
 	})
 }
 
-func (b *BleveIndexer) addDelete(filename string, repo *models.Repository, batch rupture.FlushingBatch) error {
-	id := filenameIndexerID(repo.ID, filename)
+func (b *BleveIndexer) addDelete(filename string, repoID int64, batch rupture.FlushingBatch) error {
+	id := filenameIndexerID(repoID, filename)
 	return batch.Delete(id)
 }
 
@@ -263,22 +263,23 @@ func (b *BleveIndexer) Close() {
 }
 
 // Index indexes the data
-func (b *BleveIndexer) Index(repo *models.Repository, sha string, changes *repoChanges) error {
+func (b *BleveIndexer) Index(repo *models.Repository, isWiki bool, sha string, changes *repoChanges) error {
+	repoID, repoPath := indexerRepoID(repo, isWiki), indexerRepoPath(repo, isWiki)
 	batch := rupture.NewFlushingBatch(b.indexer, maxBatchSize)
 	if len(changes.Updates) > 0 {
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(repoPath)
 		defer cancel()
 
 		for _, update := range changes.Updates {
-			if err := b.addUpdate(batchWriter, batchReader, sha, update, repo, batch); err != nil {
+			if err := b.addUpdate(batchWriter, batchReader, sha, update, repoID, repoPath, batch); err != nil {
 				return err
 			}
 		}
 		cancel()
 	}
 	for _, filename := range changes.RemovedFilenames {
-		if err := b.addDelete(filename, repo, batch); err != nil {
+		if err := b.addDelete(filename, repoID, batch); err != nil {
 			return err
 		}
 	}
@@ -332,7 +333,12 @@ func (b *BleveIndexer) Search(repoIDs []int64, language, keyword string, page, p
 			keywordQuery,
 		)
 	} else {
-		indexerQuery = keywordQuery
+		// the pages of wikis are indexed under negative IDs, they are only searched explicitly
+		minRepoID := float64(1)
+		tru := true
+		reposQuery := bleve.NewNumericRangeInclusiveQuery(&minRepoID, nil, &tru, nil)
+		reposQuery.SetField("RepoID")
+		indexerQuery = bleve.NewConjunctionQuery(reposQuery, keywordQuery)
 	}
 
 	// Save for reuse without language filter
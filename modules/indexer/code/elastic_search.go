@@ -175,7 +175,7 @@ func (b *ElasticSearchIndexer) init() (bool, error) {
 	return exists, nil
 }
 
-func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, sha string, update fileUpdate, repo *models.Repository) ([]elastic.BulkableRequest, error) {
+func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader *bufio.Reader, sha string, update fileUpdate, repoID int64, repoPath string) ([]elastic.BulkableRequest, error) {
 	// Ignore vendored files in code search
 	if setting.Indexer.ExcludeVendored && enry.IsVendor(update.Filename) {
 		return nil, nil
@@ -185,7 +185,7 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 
 	if !update.Sized {
 		stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
-			RunInDir(repo.RepoPath())
+			RunInDir(repoPath)
 		if err != nil {
 			return nil, err
 		}
@@ -195,7 +195,7 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 	}
 
 	if size > setting.Indexer.MaxIndexerFileSize {
-		return []elastic.BulkableRequest{b.addDelete(update.Filename, repo)}, nil
+		return []elastic.BulkableRequest{b.addDelete(update.Filename, repoID)}, nil
 	}
 
 	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
@@ -215,14 +215,14 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 		return nil, nil
 	}
 
-	id := filenameIndexerID(repo.ID, update.Filename)
+	id := filenameIndexerID(repoID, update.Filename)
 
 	return []elastic.BulkableRequest{
 		elastic.NewBulkIndexRequest().
 			Index(b.indexerAliasName).
 			Id(id).
 			Doc(map[string]interface{}{
-				"repo_id":    repo.ID,
+				"repo_id":    repoID,
 				"content":    string(charset.ToUTF8DropErrors(fileContents)),
 				"commit_id":  sha,
 				"language":   analyze.GetCodeLanguage(update.Filename, fileContents),
@@ -231,23 +231,24 @@ func (b *ElasticSearchIndexer) addUpdate(batchWriter *io.PipeWriter, batchReader
 	}, nil
 }
 
-func (b *ElasticSearchIndexer) addDelete(filename string, repo *models.Repository) elastic.BulkableRequest {
-	id := filenameIndexerID(repo.ID, filename)
+func (b *ElasticSearchIndexer) addDelete(filename string, repoID int64) elastic.BulkableRequest {
+	id := filenameIndexerID(repoID, filename)
 	return elastic.NewBulkDeleteRequest().
 		Index(b.indexerAliasName).
 		Id(id)
 }
 
 // Index will save the index data
-func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, changes *repoChanges) error {
+func (b *ElasticSearchIndexer) Index(repo *models.Repository, isWiki bool, sha string, changes *repoChanges) error {
+	repoID, repoPath := indexerRepoID(repo, isWiki), indexerRepoPath(repo, isWiki)
 	reqs := make([]elastic.BulkableRequest, 0)
 	if len(changes.Updates) > 0 {
 
-		batchWriter, batchReader, cancel := git.CatFileBatch(repo.RepoPath())
+		batchWriter, batchReader, cancel := git.CatFileBatch(repoPath)
 		defer cancel()
 
 		for _, update := range changes.Updates {
-			updateReqs, err := b.addUpdate(batchWriter, batchReader, sha, update, repo)
+			updateReqs, err := b.addUpdate(batchWriter, batchReader, sha, update, repoID, repoPath)
 			if err != nil {
 				return err
 			}
@@ -259,7 +260,7 @@ func (b *ElasticSearchIndexer) Index(repo *models.Repository, sha string, change
 	}
 
 	for _, filename := range changes.RemovedFilenames {
-		reqs = append(reqs, b.addDelete(filename, repo))
+		reqs = append(reqs, b.addDelete(filename, repoID))
 	}
 
 	if len(reqs) > 0 {
@@ -375,6 +376,9 @@ func (b *ElasticSearchIndexer) Search(repoIDs []int64, language, keyword string,
 		}
 		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
 		query = query.Must(repoQuery)
+	} else {
+		// the pages of wikis are indexed under negative IDs, they are only searched explicitly
+		query = query.Must(elastic.NewRangeQuery("repo_id").Gte(1))
 	}
 
 	var (
@@ -27,8 +27,34 @@ type repoChanges struct {
 	RemovedFilenames []string
 }
 
-func getDefaultBranchSha(repo *models.Repository) (string, error) {
-	stdout, err := git.NewCommand("show-ref", "-s", git.BranchPrefix+repo.DefaultBranch).RunInDir(repo.RepoPath())
+// indexerRepoID returns the ID under which the files of the repository, or the pages of its wiki, are indexed
+func indexerRepoID(repo *models.Repository, isWiki bool) int64 {
+	if isWiki {
+		return -repo.ID
+	}
+	return repo.ID
+}
+
+func indexerRepoPath(repo *models.Repository, isWiki bool) string {
+	if isWiki {
+		return repo.WikiPath()
+	}
+	return repo.RepoPath()
+}
+
+func indexerType(isWiki bool) models.RepoIndexerType {
+	if isWiki {
+		return models.RepoIndexerTypeWiki
+	}
+	return models.RepoIndexerTypeCode
+}
+
+func getDefaultBranchSha(repo *models.Repository, isWiki bool) (string, error) {
+	branch := repo.DefaultBranch
+	if isWiki {
+		branch = "master"
+	}
+	stdout, err := git.NewCommand("show-ref", "-s", git.BranchPrefix+branch).RunInDir(indexerRepoPath(repo, isWiki))
 	if err != nil {
 		return "", err
 	}
@@ -36,16 +62,16 @@ func getDefaultBranchSha(repo *models.Repository) (string, error) {
 }
 
 // getRepoChanges returns changes to repo since last indexer update
-func getRepoChanges(repo *models.Repository, revision string) (*repoChanges, error) {
-	status, err := repo.GetIndexerStatus(models.RepoIndexerTypeCode)
+func getRepoChanges(repo *models.Repository, isWiki bool, revision string) (*repoChanges, error) {
+	status, err := repo.GetIndexerStatus(indexerType(isWiki))
 	if err != nil {
 		return nil, err
 	}
 
 	if len(status.CommitSha) == 0 {
-		return genesisChanges(repo, revision)
+		return genesisChanges(repo, isWiki, revision)
 	}
-	return nonGenesisChanges(repo, revision)
+	return nonGenesisChanges(repo, isWiki, status.CommitSha, revision)
 }
 
 func isIndexable(entry *git.TreeEntry) bool {
@@ -89,10 +115,10 @@ func parseGitLsTreeOutput(stdout []byte) ([]fileUpdate, error) {
 }
 
 // genesisChanges get changes to add repo to the indexer for the first time
-func genesisChanges(repo *models.Repository, revision string) (*repoChanges, error) {
+func genesisChanges(repo *models.Repository, isWiki bool, revision string) (*repoChanges, error) {
 	var changes repoChanges
 	stdout, err := git.NewCommand("ls-tree", "--full-tree", "-l", "-r", revision).
-		RunInDirBytes(repo.RepoPath())
+		RunInDirBytes(indexerRepoPath(repo, isWiki))
 	if err != nil {
 		return nil, err
 	}
@@ -101,18 +127,18 @@ func genesisChanges(repo *models.Repository, revision string) (*repoChanges, err
 }
 
 // nonGenesisChanges get changes since the previous indexer update
-func nonGenesisChanges(repo *models.Repository, revision string) (*repoChanges, error) {
+func nonGenesisChanges(repo *models.Repository, isWiki bool, indexedSha, revision string) (*repoChanges, error) {
 	diffCmd := git.NewCommand("diff", "--name-status",
-		repo.CodeIndexerStatus.CommitSha, revision)
-	stdout, err := diffCmd.RunInDir(repo.RepoPath())
+		indexedSha, revision)
+	stdout, err := diffCmd.RunInDir(indexerRepoPath(repo, isWiki))
 	if err != nil {
 		// previous commit sha may have been removed by a force push, so
 		// try rebuilding from scratch
 		log.Warn("git diff: %v", err)
-		if err = indexer.Delete(repo.ID); err != nil {
+		if err = indexer.Delete(indexerRepoID(repo, isWiki)); err != nil {
 			return nil, err
 		}
-		return genesisChanges(repo, revision)
+		return genesisChanges(repo, isWiki, revision)
 	}
 	var changes repoChanges
 	updatedFilenames := make([]string, 0, 10)
@@ -168,7 +194,7 @@ func nonGenesisChanges(repo *models.Repository, revision string) (*repoChanges,
 
 	cmd := git.NewCommand("ls-tree", "--full-tree", "-l", revision, "--")
 	cmd.AddArguments(updatedFilenames...)
-	lsTreeStdout, err := cmd.RunInDirBytes(repo.RepoPath())
+	lsTreeStdout, err := cmd.RunInDirBytes(indexerRepoPath(repo, isWiki))
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +41,7 @@ type SearchResultLanguages struct {
 
 // Indexer defines an interface to index and search code contents
 type Indexer interface {
-	Index(repo *models.Repository, sha string, changes *repoChanges) error
+	Index(repo *models.Repository, isWiki bool, sha string, changes *repoChanges) error
 	Delete(repoID int64) error
 	Search(repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error)
 	Close()
@@ -75,6 +75,7 @@ func filenameOfIndexerID(indexerID string) string {
 // IndexerData represents data stored in the code indexer
 type IndexerData struct {
 	RepoID   int64
+	IsWiki   bool
 	IsDelete bool
 }
 
@@ -82,28 +83,31 @@ var (
 	indexerQueue queue.Queue
 )
 
-func index(indexer Indexer, repoID int64) error {
+func index(indexer Indexer, repoID int64, isWiki bool) error {
 	repo, err := models.GetRepositoryByID(repoID)
 	if err != nil {
 		return err
 	}
+	if isWiki && !repo.HasWiki() {
+		return nil
+	}
 
-	sha, err := getDefaultBranchSha(repo)
+	sha, err := getDefaultBranchSha(repo, isWiki)
 	if err != nil {
 		return err
 	}
-	changes, err := getRepoChanges(repo, sha)
+	changes, err := getRepoChanges(repo, isWiki, sha)
 	if err != nil {
 		return err
 	} else if changes == nil {
 		return nil
 	}
 
-	if err := indexer.Index(repo, sha, changes); err != nil {
+	if err := indexer.Index(repo, isWiki, sha, changes); err != nil {
 		return err
 	}
 
-	return repo.UpdateIndexerStatus(models.RepoIndexerTypeCode, sha)
+	return repo.UpdateIndexerStatus(indexerType(isWiki), sha)
 }
 
 // Init initialize the repo indexer
@@ -139,14 +143,17 @@ func Init() {
 					log.Error("Unable to process provided datum: %v - not possible to cast to IndexerData", datum)
 					continue
 				}
-				log.Trace("IndexerData Process: %v %t", indexerData.RepoID, indexerData.IsDelete)
+				log.Trace("IndexerData Process: %v %t %t", indexerData.RepoID, indexerData.IsWiki, indexerData.IsDelete)
 
 				if indexerData.IsDelete {
 					if err := indexer.Delete(indexerData.RepoID); err != nil {
 						log.Error("indexer.Delete: %v", err)
 					}
+					if err := indexer.Delete(-indexerData.RepoID); err != nil {
+						log.Error("indexer.Delete: %v", err)
+					}
 				} else {
-					if err := index(indexer, indexerData.RepoID); err != nil {
+					if err := index(indexer, indexerData.RepoID, indexerData.IsWiki); err != nil {
 						log.Error("index: %v", err)
 						continue
 					}
@@ -258,7 +265,7 @@ func Init() {
 	}
 }
 
-// DeleteRepoFromIndexer remove all of a repository's entries from the indexer
+// DeleteRepoFromIndexer remove all of a repository's entries, including the pages of its wiki, from the indexer
 func DeleteRepoFromIndexer(repo *models.Repository) {
 	indexData := &IndexerData{RepoID: repo.ID, IsDelete: true}
 	if err := indexerQueue.Push(indexData); err != nil {
@@ -274,6 +281,53 @@ func UpdateRepoIndexer(repo *models.Repository) {
 	}
 }
 
+// UpdateWikiIndexer update the entries of the pages of a repository's wiki in the indexer
+func UpdateWikiIndexer(repo *models.Repository) {
+	indexData := &IndexerData{RepoID: repo.ID, IsWiki: true}
+	if err := indexerQueue.Push(indexData); err != nil {
+		log.Error("Update wiki index data %v failed: %v", indexData, err)
+	}
+}
+
+// ReindexRepo removes all of a repository's entries, including the pages of its wiki, from the indexer
+// and indexes them again from scratch. Unlike the updates going through the queue, it runs synchronously.
+func ReindexRepo(repo *models.Repository) error {
+	if err := indexer.Delete(repo.ID); err != nil {
+		return err
+	}
+	if err := indexer.Delete(-repo.ID); err != nil {
+		return err
+	}
+	for _, isWiki := range []bool{false, true} {
+		if err := repo.ResetIndexerStatus(indexerType(isWiki)); err != nil {
+			return err
+		}
+		if !isWiki && repo.IsEmpty {
+			continue
+		}
+		if err := index(indexer, repo.ID, isWiki); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsWikiIndexUpToDate returns whether the pages of the last commit of a repository's wiki are indexed
+func IsWikiIndexUpToDate(repo *models.Repository) (bool, error) {
+	status, err := repo.GetIndexerStatus(models.RepoIndexerTypeWiki)
+	if err != nil {
+		return false, err
+	}
+	if len(status.CommitSha) == 0 {
+		return false, nil
+	}
+	sha, err := getDefaultBranchSha(repo, true)
+	if err != nil {
+		return false, err
+	}
+	return status.CommitSha == sha, nil
+}
+
 // populateRepoIndexer populate the repo indexer with pre-existing data. This
 // should only be run when the indexer is created for the first time.
 func populateRepoIndexer(ctx context.Context) {
@@ -326,6 +380,10 @@ func populateRepoIndexer(ctx context.Context) {
 				log.Error("indexerQueue.Push: %v", err)
 				return
 			}
+			if err := indexerQueue.Push(&IndexerData{RepoID: id, IsWiki: true}); err != nil {
+				log.Error("indexerQueue.Push: %v", err)
+				return
+			}
 			maxRepoID = id - 1
 		}
 	}
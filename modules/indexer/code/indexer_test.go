@@ -20,7 +20,7 @@ func TestMain(m *testing.M) {
 func testIndexer(name string, t *testing.T, indexer Indexer) {
 	t.Run(name, func(t *testing.T) {
 		var repoID int64 = 1
-		err := index(indexer, repoID)
+		err := index(indexer, repoID, false)
 		assert.NoError(t, err)
 		var (
 			keywords = []struct {
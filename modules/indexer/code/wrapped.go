@@ -57,12 +57,12 @@ func (w *wrappedIndexer) get() (Indexer, error) {
 	return w.internal, nil
 }
 
-func (w *wrappedIndexer) Index(repo *models.Repository, sha string, changes *repoChanges) error {
+func (w *wrappedIndexer) Index(repo *models.Repository, isWiki bool, sha string, changes *repoChanges) error {
 	indexer, err := w.get()
 	if err != nil {
 		return err
 	}
-	return indexer.Index(repo, sha, changes)
+	return indexer.Index(repo, isWiki, sha, changes)
 }
 
 func (w *wrappedIndexer) Delete(repoID int64) error {
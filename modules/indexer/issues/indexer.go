@@ -287,21 +287,59 @@ func UpdateRepoIndexer(repo *models.Repository) {
 	}
 }
 
-// UpdateIssueIndexer add/update an issue to the issue indexer
-func UpdateIssueIndexer(issue *models.Issue) {
+// ReindexRepoIssues indexes all the issues of a repository again. Unlike the updates going
+// through the queue, it runs synchronously.
+func ReindexRepoIssues(repo *models.Repository) error {
+	indexer := holder.get()
+	if indexer == nil {
+		return fmt.Errorf("unable to get the issue indexer")
+	}
+
+	is, err := models.Issues(&models.IssuesOptions{
+		RepoIDs:  []int64{repo.ID},
+		IsClosed: util.OptionalBoolNone,
+		IsPull:   util.OptionalBoolNone,
+	})
+	if err != nil {
+		return fmt.Errorf("Issues: %v", err)
+	}
+	if err = models.IssueList(is).LoadDiscussComments(); err != nil {
+		return fmt.Errorf("LoadComments: %v", err)
+	}
+
+	batchSize := util.Max(setting.Indexer.IssueQueueBatchNumber, 1)
+	for start := 0; start < len(is); start += batchSize {
+		end := util.Min(start+batchSize, len(is))
+		data := make([]*IndexerData, 0, end-start)
+		for _, issue := range is[start:end] {
+			data = append(data, issueIndexerData(issue))
+		}
+		if err := indexer.Index(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func issueIndexerData(issue *models.Issue) *IndexerData {
 	var comments []string
 	for _, comment := range issue.Comments {
 		if comment.Type == models.CommentTypeComment {
 			comments = append(comments, comment.Content)
 		}
 	}
-	indexerData := &IndexerData{
+	return &IndexerData{
 		ID:       issue.ID,
 		RepoID:   issue.RepoID,
 		Title:    issue.Title,
 		Content:  issue.Content,
 		Comments: comments,
 	}
+}
+
+// UpdateIssueIndexer add/update an issue to the issue indexer
+func UpdateIssueIndexer(issue *models.Issue) {
+	indexerData := issueIndexerData(issue)
 	log.Debug("Adding to channel: %v", indexerData)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
 		log.Error("Unable to push to issue indexer: %v: Error: %v", indexerData, err)
@@ -329,19 +367,32 @@ func DeleteRepoIssueIndexer(repo *models.Repository) {
 	}
 }
 
-// SearchIssuesByKeyword search issue ids by keywords and repo id
+// SearchIssuesByKeyword search issue ids by keywords and repo id, the ids are ordered by relevance.
+// If the issue indexer is unavailable, the issues are searched in the database instead.
 // WARNNING: You have to ensure user have permission to visit repoIDs' issues
 func SearchIssuesByKeyword(repoIDs []int64, keyword string) ([]int64, error) {
+	return SearchIssuesByKeywordWithLimit(repoIDs, keyword, 50)
+}
+
+// SearchIssuesByKeywordWithLimit is SearchIssuesByKeyword returning at most limit issue ids
+// WARNNING: You have to ensure user have permission to visit repoIDs' issues
+func SearchIssuesByKeywordWithLimit(repoIDs []int64, keyword string, limit int) ([]int64, error) {
 	var issueIDs []int64
 	indexer := holder.get()
 
 	if indexer == nil {
-		log.Error("SearchIssuesByKeyword(): unable to get indexer!")
-		return nil, fmt.Errorf("unable to get issue indexer")
+		log.Error("SearchIssuesByKeyword(): unable to get indexer, searching the database instead")
+		indexer = &DBIndexer{}
 	}
-	res, err := indexer.Search(keyword, repoIDs, 50, 0)
+	res, err := indexer.Search(keyword, repoIDs, limit, 0)
 	if err != nil {
-		return nil, err
+		if _, ok := indexer.(*DBIndexer); ok {
+			return nil, err
+		}
+		log.Error("SearchIssuesByKeyword(): the issue indexer failed, searching the database instead: %v", err)
+		if res, err = (&DBIndexer{}).Search(keyword, repoIDs, limit, 0); err != nil {
+			return nil, err
+		}
 	}
 	for _, r := range res.Hits {
 		issueIDs = append(issueIDs, r.ID)
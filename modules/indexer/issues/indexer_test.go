@@ -5,6 +5,7 @@
 package issues
 
 import (
+	"errors"
 	"io/ioutil"
 	"path"
 	"path/filepath"
@@ -95,3 +96,26 @@ func TestDBSearchIssues(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
 }
+
+// failingIndexer is an issue indexer whose searches fail, like an unreachable external indexer
+type failingIndexer struct {
+	DBIndexer
+}
+
+func (*failingIndexer) Search(kw string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	return nil, errors.New("indexer unavailable")
+}
+
+func TestSearchIssuesFallback(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	holder.mutex.RLock()
+	oldIndexer := holder.indexer
+	holder.mutex.RUnlock()
+	holder.set(&failingIndexer{})
+	defer holder.set(oldIndexer)
+
+	ids, err := SearchIssuesByKeyword([]int64{1}, "issue2")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{2}, ids)
+}
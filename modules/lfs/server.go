@@ -609,7 +609,8 @@ func authenticate(ctx *context.Context, repository *models.Repository, authoriza
 		return false
 	}
 
-	canRead := perm.CanAccess(accessMode, models.UnitTypeCode)
+	// anonymous users must authenticate with a token if the repository requires it for git operations
+	canRead := perm.CanAccess(accessMode, models.UnitTypeCode) && (ctx.User != nil || !repository.RequireGitAuth)
 	if canRead {
 		return true
 	}
@@ -159,6 +159,18 @@ var defaultProcessors = []processor{
 	emojiShortCodeProcessor,
 }
 
+// wikiProcessorsWithoutReferences are the processors used to render wiki pages
+// when the automatic linking of references is disabled.
+var wikiProcessorsWithoutReferences = []processor{
+	fullIssuePatternProcessor,
+	fullSha1PatternProcessor,
+	shortLinkProcessor,
+	linkProcessor,
+	emailAddressProcessor,
+	emojiProcessor,
+	emojiShortCodeProcessor,
+}
+
 type postProcessCtx struct {
 	metas          map[string]string
 	urlPrefix      string
@@ -179,12 +191,17 @@ func PostProcess(
 	metas map[string]string,
 	isWikiMarkdown bool,
 ) ([]byte, error) {
+	procs := defaultProcessors
+	if isWikiMarkdown && !setting.Markdown.EnableReferenceLinksInWiki {
+		procs = wikiProcessorsWithoutReferences
+	}
+
 	// create the context from the parameters
 	ctx := &postProcessCtx{
 		metas:          metas,
 		urlPrefix:      urlPrefix,
 		isWikiMarkdown: isWikiMarkdown,
-		procs:          defaultProcessors,
+		procs:          procs,
 	}
 	return ctx.postProcess(rawHTML)
 }
@@ -74,6 +74,42 @@ func TestRender_CrossReferences(t *testing.T) {
 		`<p>/home/gitea/go-gitea/gitea#12345</p>`)
 }
 
+func TestRender_WikiReferences(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+	defer func() {
+		setting.Markdown.EnableReferenceLinksInWiki = true
+	}()
+
+	test := func(input, expected string) {
+		buffer := markdown.RenderWiki([]byte(input), setting.AppSubURL, localMetas)
+		assert.Equal(t, strings.TrimSpace(expected), strings.TrimSpace(buffer))
+	}
+
+	var sha = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	var commit = util.URLJoin(AppSubURL, "commit", sha)
+	var issue = util.URLJoin(AppURL, "gogits", "gogs", "issues", "12")
+	var crossIssue = util.URLJoin(AppURL, "go-gitea", "gitea", "issues", "12345")
+	var pull = util.URLJoin(AppURL, "gogits", "gogs", "pulls", "3")
+	var mention = util.URLJoin(AppURL, "user1")
+
+	setting.Markdown.EnableReferenceLinksInWiki = true
+	test("#12", `<p><a href="`+issue+`" class="ref-issue" rel="nofollow">#12</a></p>`)
+	test("!3", `<p><a href="`+pull+`" class="ref-issue" rel="nofollow">!3</a></p>`)
+	test("go-gitea/gitea#12345", `<p><a href="`+crossIssue+`" class="ref-issue" rel="nofollow">go-gitea/gitea#12345</a></p>`)
+	test("commit "+sha, `<p>commit <a href="`+commit+`" rel="nofollow"><code>65f1bf27bc</code></a></p>`)
+	test("@user1", `<p><a href="`+mention+`" rel="nofollow">@user1</a></p>`)
+
+	setting.Markdown.EnableReferenceLinksInWiki = false
+	test("#12", `<p>#12</p>`)
+	test("!3", `<p>!3</p>`)
+	test("go-gitea/gitea#12345", `<p>go-gitea/gitea#12345</p>`)
+	test("commit "+sha, `<p>commit `+sha+`</p>`)
+	test("@user1", `<p>@user1</p>`)
+	// full URLs are still links
+	test(commit, `<p><a href="`+commit+`" rel="nofollow"><code>65f1bf27bc</code></a></p>`)
+}
+
 func TestMisc_IsSameDomain(t *testing.T) {
 	setting.AppURL = AppURL
 	setting.AppSubURL = AppSubURL
@@ -28,6 +28,12 @@ import (
 
 var byteMailto = []byte("mailto:")
 
+var tocMarker = []byte("[[TOC]]")
+
+// IncludeTOCMetaKey is the key of the render metas requesting a table of contents
+// at the top of the document, like the include_toc front matter option does
+const IncludeTOCMetaKey = "include_toc"
+
 // Header holds the data about a header.
 type Header struct {
 	Level int
@@ -42,8 +48,10 @@ type ASTTransformer struct{}
 func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
 	metaData := meta.GetItems(pc)
 	firstChild := node.FirstChild()
-	createTOC := false
-	var toc = []Header{}
+	renderMetas := pc.Get(renderMetasKey).(map[string]string)
+	createTOC := renderMetas[IncludeTOCMetaKey] == "true"
+	var toc = make([]Header, 0, 100)
+	var tocMarkers []ast.Node
 	rc := &RenderConfig{
 		Meta: "table",
 		Icon: "table",
@@ -56,9 +64,10 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 		if metaNode != nil {
 			node.InsertBefore(node, firstChild, metaNode)
 		}
-		createTOC = rc.TOC
-		toc = make([]Header, 0, 100)
+		createTOC = createTOC || rc.TOC
 	}
+	// the [[TOC]] marker is only honoured in documents, not in comments
+	allowTOCMarker := pc.Get(isWikiKey).(bool) || renderMetas["mode"] == "document"
 
 	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
 		if !entering {
@@ -67,23 +76,25 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 
 		switch v := n.(type) {
 		case *ast.Heading:
-			if createTOC {
-				text := n.Text(reader.Source())
-				header := Header{
-					Text:  util.BytesToReadOnlyString(text),
-					Level: v.Level,
-				}
-				if id, found := v.AttributeString("id"); found {
-					header.ID = util.BytesToReadOnlyString(id.([]byte))
-				}
-				toc = append(toc, header)
-			} else {
-				for _, attr := range v.Attributes() {
-					if _, ok := attr.Value.([]byte); !ok {
-						v.SetAttribute(attr.Name, []byte(fmt.Sprintf("%v", attr.Value)))
-					}
+			for _, attr := range v.Attributes() {
+				if _, ok := attr.Value.([]byte); !ok {
+					v.SetAttribute(attr.Name, []byte(fmt.Sprintf("%v", attr.Value)))
 				}
 			}
+			text := n.Text(reader.Source())
+			header := Header{
+				Text:  util.BytesToReadOnlyString(text),
+				Level: v.Level,
+			}
+			if id, found := v.AttributeString("id"); found {
+				header.ID = util.BytesToReadOnlyString(id.([]byte))
+			}
+			toc = append(toc, header)
+		case *ast.Paragraph:
+			if allowTOCMarker && isTOCMarker(v, reader.Source()) {
+				tocMarkers = append(tocMarkers, n)
+				return ast.WalkSkipChildren, nil
+			}
 		case *ast.Image:
 			// Images need two things:
 			//
@@ -190,7 +201,6 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 			}
 		case *ast.Text:
 			if v.SoftLineBreak() && !v.HardLineBreak() {
-				renderMetas := pc.Get(renderMetasKey).(map[string]string)
 				mode := renderMetas["mode"]
 				if mode != "document" {
 					v.SetHardLineBreak(setting.Markdown.EnableHardLineBreakInComments)
@@ -202,14 +212,25 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 		return ast.WalkContinue, nil
 	})
 
-	if createTOC && len(toc) > 0 {
+	if len(tocMarkers) > 0 || (createTOC && len(toc) > 0) {
 		lang := rc.Lang
 		if len(lang) == 0 {
 			lang = setting.Langs[0]
 		}
-		tocNode := createTOCNode(toc, lang)
-		if tocNode != nil {
-			node.InsertBefore(node, firstChild, tocNode)
+		if len(tocMarkers) > 0 {
+			// the markers are replaced by the table of contents, and dropped if there are no headings
+			for _, marker := range tocMarkers {
+				if len(toc) > 0 {
+					marker.Parent().ReplaceChild(marker.Parent(), marker, createTOCNode(toc, lang))
+				} else {
+					marker.Parent().RemoveChild(marker.Parent(), marker)
+				}
+			}
+		} else {
+			tocNode := createTOCNode(toc, lang)
+			if tocNode != nil {
+				node.InsertBefore(node, firstChild, tocNode)
+			}
 		}
 	}
 
@@ -218,6 +239,11 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 	}
 }
 
+// isTOCMarker returns whether the paragraph only consists of a [[TOC]] marker
+func isTOCMarker(paragraph *ast.Paragraph, source []byte) bool {
+	return bytes.EqualFold(bytes.TrimSpace(paragraph.Text(source)), tocMarker)
+}
+
 type prefixedIDs struct {
 	values map[string]bool
 }
@@ -339,3 +339,49 @@ func TestRenderSiblingImages_Issue12925(t *testing.T) {
 	assert.Equal(t, expected, res)
 
 }
+
+func TestRender_TOC(t *testing.T) {
+	setting.AppURL = AppURL
+	setting.AppSubURL = AppSubURL
+	if len(setting.Langs) == 0 {
+		setting.Langs = []string{"en-US"}
+	}
+
+	input := `# Introduction
+
+[[TOC]]
+
+## Usage
+
+## Usage
+`
+	res := RenderWiki([]byte(input), AppSubURL, localMetas)
+	assert.NotContains(t, res, "[[TOC]]")
+	assert.Contains(t, res, `<details>`)
+	// the table of contents is placed where the marker was
+	assert.Less(t, strings.Index(res, `id="user-content-introduction"`), strings.Index(res, `<details>`))
+	// duplicate headings get unique anchors, which the table of contents links to
+	assert.Contains(t, res, `id="user-content-usage"`)
+	assert.Contains(t, res, `id="user-content-usage-1"`)
+	assert.Contains(t, res, `href="#user-content-usage"`)
+	assert.Contains(t, res, `href="#user-content-usage-1"`)
+
+	// without a marker the table of contents is only added when requested by the metas
+	input = `# Introduction
+
+## Usage
+`
+	res = RenderWiki([]byte(input), AppSubURL, localMetas)
+	assert.NotContains(t, res, `<details>`)
+	metas := map[string]string{IncludeTOCMetaKey: "true"}
+	for k, v := range localMetas {
+		metas[k] = v
+	}
+	res = RenderWiki([]byte(input), AppSubURL, metas)
+	assert.True(t, strings.HasPrefix(res, `<details>`))
+	assert.Contains(t, res, `href="#user-content-usage"`)
+
+	// the marker is ignored in comments
+	res = RenderString("# Introduction\n\n[[TOC]]\n", AppSubURL, localMetas)
+	assert.NotContains(t, res, `<details>`)
+}
@@ -5,7 +5,14 @@
 package metrics
 
 import (
+	"errors"
+	"strings"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/matchlist"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -37,10 +44,35 @@ type Collector struct {
 	Users         *prometheus.Desc
 	Watches       *prometheus.Desc
 	Webhooks      *prometheus.Desc
+
+	RepositoryOpenIssues *prometheus.Desc
+	RepositoryOpenPulls  *prometheus.Desc
+	RepositoryStars      *prometheus.Desc
+	RepositorySize       *prometheus.Desc
+
+	QueueLength    *prometheus.Desc
+	QueueWorkers   *prometheus.Desc
+	QueueProcessed *prometheus.Desc
+
+	// repositories matches the full names of the repositories to collect
+	// per-repository metrics for, it is nil if they are disabled
+	repositories *matchlist.Matchlist
 }
 
 // NewCollector returns a new Collector with all prometheus.Desc initialized
 func NewCollector() Collector {
+	var repositories *matchlist.Matchlist
+	if setting.Metrics.EnableRepositoryMetrics {
+		var err error
+		repositories, err = matchlist.NewMatchlist(setting.Metrics.RepositoryAllowlist...)
+		if err != nil {
+			log.Error("Invalid metrics repository allowlist, per-repository metrics are disabled: %v", err)
+			repositories = nil
+		}
+	}
+
+	repoLabels := []string{"repository"}
+	queueLabels := []string{"queue"}
 	return Collector{
 		Accesses: prometheus.NewDesc(
 			namespace+"accesses",
@@ -152,6 +184,42 @@ func NewCollector() Collector {
 			"Number of Webhooks",
 			nil, nil,
 		),
+		RepositoryOpenIssues: prometheus.NewDesc(
+			namespace+"repository_open_issues",
+			"Number of open Issues of a Repository",
+			repoLabels, nil,
+		),
+		RepositoryOpenPulls: prometheus.NewDesc(
+			namespace+"repository_open_pulls",
+			"Number of open Pull Requests of a Repository",
+			repoLabels, nil,
+		),
+		RepositoryStars: prometheus.NewDesc(
+			namespace+"repository_stars",
+			"Number of Stars of a Repository",
+			repoLabels, nil,
+		),
+		RepositorySize: prometheus.NewDesc(
+			namespace+"repository_size_bytes",
+			"Size of a Repository in bytes",
+			repoLabels, nil,
+		),
+		QueueLength: prometheus.NewDesc(
+			namespace+"queue_length",
+			"Number of items waiting in a Queue",
+			queueLabels, nil,
+		),
+		QueueWorkers: prometheus.NewDesc(
+			namespace+"queue_workers",
+			"Number of Workers of a Queue",
+			queueLabels, nil,
+		),
+		QueueProcessed: prometheus.NewDesc(
+			namespace+"queue_processed_total",
+			"Number of items processed by a Queue",
+			queueLabels, nil,
+		),
+		repositories: repositories,
 	}
 
 }
@@ -180,6 +248,16 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Users
 	ch <- c.Watches
 	ch <- c.Webhooks
+	ch <- c.QueueLength
+	ch <- c.QueueWorkers
+	ch <- c.QueueProcessed
+
+	if c.repositories != nil {
+		ch <- c.RepositoryOpenIssues
+		ch <- c.RepositoryOpenPulls
+		ch <- c.RepositoryStars
+		ch <- c.RepositorySize
+	}
 }
 
 // Collect returns the metrics with values
@@ -296,4 +374,98 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Webhook),
 	)
+
+	c.collectQueues(ch)
+
+	if c.repositories != nil {
+		c.collectRepositories(ch)
+	}
+}
+
+// collectQueues returns the metrics of the queues known to the queue manager, metrics a
+// queue cannot provide are omitted. Queues may be added or removed concurrently, the
+// manager returns a snapshot of them.
+func (c Collector) collectQueues(ch chan<- prometheus.Metric) {
+	seen := make(map[string]bool)
+	for _, mq := range queue.GetManager().ManagedQueues() {
+		// the label values of a metric have to be unique
+		if seen[mq.Name] {
+			continue
+		}
+		seen[mq.Name] = true
+
+		if length := mq.NumberInQueue(); length >= 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.QueueLength,
+				prometheus.GaugeValue,
+				float64(length),
+				mq.Name,
+			)
+		}
+		if workers := mq.NumberOfWorkers(); workers >= 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.QueueWorkers,
+				prometheus.GaugeValue,
+				float64(workers),
+				mq.Name,
+			)
+		}
+		if processed := mq.NumberProcessed(); processed >= 0 {
+			ch <- prometheus.MustNewConstMetric(
+				c.QueueProcessed,
+				prometheus.CounterValue,
+				float64(processed),
+				mq.Name,
+			)
+		}
+	}
+}
+
+var errMaxRepositories = errors.New("maximum number of repositories reached")
+
+// collectRepositories returns the metrics of the repositories matching the allowlist,
+// up to the configured maximum number of repositories
+func (c Collector) collectRepositories(ch chan<- prometheus.Metric) {
+	count := 0
+	err := models.IterateRepository(func(repo *models.Repository) error {
+		fullName := repo.FullName()
+		if !c.repositories.Match(strings.ToLower(fullName)) {
+			return nil
+		}
+		if count >= setting.Metrics.MaxRepositories {
+			return errMaxRepositories
+		}
+		count++
+
+		ch <- prometheus.MustNewConstMetric(
+			c.RepositoryOpenIssues,
+			prometheus.GaugeValue,
+			float64(repo.NumOpenIssues),
+			fullName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.RepositoryOpenPulls,
+			prometheus.GaugeValue,
+			float64(repo.NumOpenPulls),
+			fullName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.RepositoryStars,
+			prometheus.GaugeValue,
+			float64(repo.NumStars),
+			fullName,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.RepositorySize,
+			prometheus.GaugeValue,
+			float64(repo.Size),
+			fullName,
+		)
+		return nil
+	})
+	if err == errMaxRepositories {
+		log.Warn("More than %d repositories match the metrics repository allowlist, the metrics of the others are omitted", setting.Metrics.MaxRepositories)
+	} else if err != nil {
+		log.Error("Unable to collect repository metrics: %v", err)
+	}
 }
@@ -0,0 +1,116 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatherLabeledMetrics returns the values of the metrics with the given label by name and label value
+func gatherLabeledMetrics(t *testing.T, labelName string) map[string]map[string]float64 {
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(NewCollector()))
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	values := make(map[string]map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() != labelName {
+					continue
+				}
+				if values[family.GetName()] == nil {
+					values[family.GetName()] = make(map[string]float64)
+				}
+				if metric.GetCounter() != nil {
+					values[family.GetName()][label.GetValue()] = metric.GetCounter().GetValue()
+				} else {
+					values[family.GetName()][label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestCollector_Repositories(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(enabled bool, allowlist []string, max int) {
+		setting.Metrics.EnableRepositoryMetrics = enabled
+		setting.Metrics.RepositoryAllowlist = allowlist
+		setting.Metrics.MaxRepositories = max
+	}(setting.Metrics.EnableRepositoryMetrics, setting.Metrics.RepositoryAllowlist, setting.Metrics.MaxRepositories)
+
+	setting.Metrics.EnableRepositoryMetrics = false
+	setting.Metrics.RepositoryAllowlist = []string{"user2/*"}
+	setting.Metrics.MaxRepositories = 100
+	assert.Empty(t, gatherLabeledMetrics(t, "repository"))
+
+	setting.Metrics.EnableRepositoryMetrics = true
+	values := gatherLabeledMetrics(t, "repository")
+	assert.EqualValues(t, 1, values["gitea_repository_open_issues"]["user2/repo1"])
+	assert.EqualValues(t, 3, values["gitea_repository_open_pulls"]["user2/repo1"])
+	assert.EqualValues(t, 1, values["gitea_repository_stars"]["user2/repo2"])
+	assert.Contains(t, values["gitea_repository_size_bytes"], "user2/repo1")
+	for name := range values["gitea_repository_stars"] {
+		assert.Regexp(t, "^user2/", name)
+	}
+
+	setting.Metrics.RepositoryAllowlist = []string{"user2/repo1", "USER3/*"}
+	values = gatherLabeledMetrics(t, "repository")
+	assert.Contains(t, values["gitea_repository_stars"], "user2/repo1")
+	assert.Contains(t, values["gitea_repository_stars"], "user3/repo3")
+	assert.NotContains(t, values["gitea_repository_stars"], "user2/repo2")
+
+	setting.Metrics.RepositoryAllowlist = []string{"*"}
+	setting.Metrics.MaxRepositories = 2
+	values = gatherLabeledMetrics(t, "repository")
+	assert.Len(t, values["gitea_repository_stars"], 2)
+}
+
+func TestCollector_Queues(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	q, err := queue.NewChannelQueue(func(data ...queue.Data) {}, queue.ChannelQueueConfiguration{
+		WorkerPoolConfiguration: queue.WorkerPoolConfiguration{
+			QueueLength: 10,
+			BatchLength: 1,
+			MaxWorkers:  1,
+		},
+		Name: "TestCollectorQueue",
+	}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, q.Push("a"))
+	assert.NoError(t, q.Push("b"))
+
+	values := gatherLabeledMetrics(t, "queue")
+	assert.EqualValues(t, 2, values["gitea_queue_length"]["TestCollectorQueue"])
+	assert.EqualValues(t, 0, values["gitea_queue_workers"]["TestCollectorQueue"])
+	assert.EqualValues(t, 0, values["gitea_queue_processed_total"]["TestCollectorQueue"])
+
+	assert.NoError(t, q.(queue.Flushable).FlushWithContext(context.Background()))
+	values = gatherLabeledMetrics(t, "queue")
+	assert.EqualValues(t, 0, values["gitea_queue_length"]["TestCollectorQueue"])
+	assert.EqualValues(t, 2, values["gitea_queue_processed_total"]["TestCollectorQueue"])
+
+	// removed queues are no longer exported
+	for _, mq := range queue.GetManager().ManagedQueues() {
+		if mq.Name == "TestCollectorQueue" {
+			queue.GetManager().Remove(mq.QID)
+		}
+	}
+	values = gatherLabeledMetrics(t, "queue")
+	assert.NotContains(t, values["gitea_queue_length"], "TestCollectorQueue")
+}
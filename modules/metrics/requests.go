@@ -0,0 +1,78 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RequestMetrics records the durations and the status classes of the responses of HTTP requests
+type RequestMetrics struct {
+	Durations *prometheus.HistogramVec
+	Responses *prometheus.CounterVec
+}
+
+// NewRequestMetrics returns new RequestMetrics with the given histogram buckets in seconds
+func NewRequestMetrics(buckets []float64) *RequestMetrics {
+	return &RequestMetrics{
+		Durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    namespace + "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by method and route",
+			Buckets: buckets,
+		}, []string{"method", "route"}),
+		Responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: namespace + "http_responses_total",
+			Help: "Number of HTTP responses by method and status class",
+		}, []string{"method", "status"}),
+	}
+}
+
+// Register registers the metrics with the given registerer,
+// reusing the metrics which have been registered before.
+func (m *RequestMetrics) Register(registerer prometheus.Registerer) error {
+	if err := registerer.Register(m.Durations); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		m.Durations = are.ExistingCollector.(*prometheus.HistogramVec)
+	}
+	if err := registerer.Register(m.Responses); err != nil {
+		are, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return err
+		}
+		m.Responses = are.ExistingCollector.(*prometheus.CounterVec)
+	}
+	return nil
+}
+
+// Observe records a request of the given route template, which has been answered with status after duration
+func (m *RequestMetrics) Observe(method, route string, status int, duration time.Duration) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+	default:
+		// clients can send arbitrary methods, which must not create new series
+		method = "OTHER"
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	m.Durations.WithLabelValues(method, route).Observe(duration.Seconds())
+	m.Responses.WithLabelValues(method, statusClass(status)).Inc()
+}
+
+// statusClass returns the class of the status code, e.g. 2xx
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
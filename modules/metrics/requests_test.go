@@ -0,0 +1,63 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	m := NewRequestMetrics([]float64{0.1, 1})
+	assert.NoError(t, m.Register(registry))
+
+	m.Observe("GET", "/{username}/{reponame}", 200, 50*time.Millisecond)
+	m.Observe("GET", "/{username}/{reponame}", 404, 500*time.Millisecond)
+	m.Observe("BREW", "", 405, time.Millisecond)
+
+	// registering again reuses the registered metrics
+	m2 := NewRequestMetrics([]float64{0.1, 1})
+	assert.NoError(t, m2.Register(registry))
+	m2.Observe("POST", "/user/login", 303, 2*time.Second)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	durations := make(map[string]uint64)
+	responses := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := ""
+			for _, label := range metric.GetLabel() {
+				key += label.GetName() + "=" + label.GetValue() + " "
+			}
+			switch family.GetName() {
+			case "gitea_http_request_duration_seconds":
+				durations[key] = metric.GetHistogram().GetSampleCount()
+				if key == "method=GET route=/{username}/{reponame} " {
+					assert.EqualValues(t, 1, metric.GetHistogram().GetBucket()[0].GetCumulativeCount())
+					assert.EqualValues(t, 2, metric.GetHistogram().GetBucket()[1].GetCumulativeCount())
+				}
+			case "gitea_http_responses_total":
+				responses[key] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]uint64{
+		"method=GET route=/{username}/{reponame} ": 2,
+		"method=OTHER route=unmatched ":            1,
+		"method=POST route=/user/login ":           1,
+	}, durations)
+	assert.Equal(t, map[string]float64{
+		"method=GET status=2xx ":   1,
+		"method=GET status=4xx ":   1,
+		"method=OTHER status=4xx ": 1,
+		"method=POST status=3xx ":  1,
+	}, responses)
+}
@@ -7,6 +7,7 @@ package base
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // Notifier defines an interface to notify receiver
@@ -23,6 +24,8 @@ type Notifier interface {
 	NotifyNewIssue(issue *models.Issue, mentions []*models.User)
 	NotifyIssueChangeStatus(*models.User, *models.Issue, *models.Comment, bool)
 	NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64)
+	NotifyIssueChangeDeadline(doer *models.User, issue *models.Issue, oldDeadlineUnix timeutil.TimeStamp, comment *models.Comment)
+	NotifyIssueChangeDependency(doer *models.User, issue, dependency *models.Issue, removed bool)
 	NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool, comment *models.Comment)
 	NotifyPullReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool, comment *models.Comment)
 	NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string)
@@ -37,6 +40,7 @@ type Notifier interface {
 	NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest)
 	NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User)
 	NotifyPullRequestCodeComment(pr *models.PullRequest, comment *models.Comment, mentions []*models.User)
+	NotifyPullRequestChangeMergeable(pr *models.PullRequest, oldStatus models.PullRequestStatus)
 	NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string)
 	NotifyPullRequestPushCommits(doer *models.User, pr *models.PullRequest, comment *models.Comment)
 	NotifyPullRevieweDismiss(doer *models.User, review *models.Review, comment *models.Comment)
@@ -50,6 +54,10 @@ type Notifier interface {
 	NotifyUpdateRelease(doer *models.User, rel *models.Release)
 	NotifyDeleteRelease(doer *models.User, rel *models.Release)
 
+	NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus)
+	NotifyCreateCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment)
+	NotifyDeleteCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment)
+
 	NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits)
 	NotifyCreateRef(doer *models.User, repo *models.Repository, refType, refFullName string)
 	NotifyDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
@@ -59,4 +67,11 @@ type Notifier interface {
 	NotifySyncDeleteRef(doer *models.User, repo *models.Repository, refType, refFullName string)
 
 	NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository)
+
+	NotifyCreateTeam(doer, org *models.User, team *models.Team)
+	NotifyUpdateTeam(doer, org *models.User, team *models.Team)
+	NotifyDeleteTeam(doer, org *models.User, team *models.Team)
+	NotifyAddTeamMember(doer, org *models.User, team *models.Team, member *models.User)
+	NotifyRemoveTeamMember(doer, org *models.User, team *models.Team, member *models.User)
+	NotifyRemoveOrgMember(doer, org, member *models.User)
 }
@@ -7,6 +7,7 @@ package base
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // NullNotifier implements a blank notifier
@@ -54,6 +55,10 @@ func (*NullNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models
 func (*NullNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
 }
 
+// NotifyPullRequestChangeMergeable places a place holder function
+func (*NullNotifier) NotifyPullRequestChangeMergeable(pr *models.PullRequest, oldStatus models.PullRequestStatus) {
+}
+
 // NotifyPullRequestChangeTargetBranch places a place holder function
 func (*NullNotifier) NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string) {
 }
@@ -86,10 +91,30 @@ func (*NullNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release)
 func (*NullNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 }
 
+// NotifyCreateCommitStatus places a place holder function
+func (*NullNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+}
+
+// NotifyCreateCommitComment places a place holder function
+func (*NullNotifier) NotifyCreateCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+}
+
+// NotifyDeleteCommitComment places a place holder function
+func (*NullNotifier) NotifyDeleteCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+}
+
 // NotifyIssueChangeMilestone places a place holder function
 func (*NullNotifier) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 }
 
+// NotifyIssueChangeDependency places a place holder function
+func (*NullNotifier) NotifyIssueChangeDependency(doer *models.User, issue, dependency *models.Issue, removed bool) {
+}
+
+// NotifyIssueChangeDeadline places a place holder function
+func (*NullNotifier) NotifyIssueChangeDeadline(doer *models.User, issue *models.Issue, oldDeadlineUnix timeutil.TimeStamp, comment *models.Comment) {
+}
+
 // NotifyIssueChangeContent places a place holder function
 func (*NullNotifier) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 }
@@ -170,3 +195,27 @@ func (*NullNotifier) NotifySyncDeleteRef(doer *models.User, repo *models.Reposit
 // NotifyRepoPendingTransfer places a place holder function
 func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Repository) {
 }
+
+// NotifyCreateTeam places a place holder function
+func (*NullNotifier) NotifyCreateTeam(doer, org *models.User, team *models.Team) {
+}
+
+// NotifyUpdateTeam places a place holder function
+func (*NullNotifier) NotifyUpdateTeam(doer, org *models.User, team *models.Team) {
+}
+
+// NotifyDeleteTeam places a place holder function
+func (*NullNotifier) NotifyDeleteTeam(doer, org *models.User, team *models.Team) {
+}
+
+// NotifyAddTeamMember places a place holder function
+func (*NullNotifier) NotifyAddTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+}
+
+// NotifyRemoveTeamMember places a place holder function
+func (*NullNotifier) NotifyRemoveTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+}
+
+// NotifyRemoveOrgMember places a place holder function
+func (*NullNotifier) NotifyRemoveOrgMember(doer, org, member *models.User) {
+}
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/notification/webhook"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 var (
@@ -94,6 +95,14 @@ func NotifyPullRequestCodeComment(pr *models.PullRequest, comment *models.Commen
 	}
 }
 
+// NotifyPullRequestChangeMergeable notifies when the check of a pull request against its base branch
+// changed its mergeable status from oldStatus
+func NotifyPullRequestChangeMergeable(pr *models.PullRequest, oldStatus models.PullRequestStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestChangeMergeable(pr, oldStatus)
+	}
+}
+
 // NotifyPullRequestChangeTargetBranch notifies when a pull request's target branch was changed
 func NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string) {
 	for _, notifier := range notifiers {
@@ -150,6 +159,27 @@ func NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 	}
 }
 
+// NotifyCreateCommitStatus notifies a new status of a commit to notifiers
+func NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(doer, repo, sha, status)
+	}
+}
+
+// NotifyCreateCommitComment notifies a new comment on a commit to notifiers
+func NotifyCreateCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitComment(doer, repo, comment)
+	}
+}
+
+// NotifyDeleteCommitComment notifies the deletion of a comment on a commit to notifiers
+func NotifyDeleteCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteCommitComment(doer, repo, comment)
+	}
+}
+
 // NotifyIssueChangeMilestone notifies change milestone to notifiers
 func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 	for _, notifier := range notifiers {
@@ -157,6 +187,21 @@ func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMiles
 	}
 }
 
+// NotifyIssueChangeDependency notifies that the issue is blocked by the dependency, or is not anymore
+// if removed, the issues can be in different repositories
+func NotifyIssueChangeDependency(doer *models.User, issue, dependency *models.Issue, removed bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueChangeDependency(doer, issue, dependency, removed)
+	}
+}
+
+// NotifyIssueChangeDeadline notifies change of the due date to notifiers
+func NotifyIssueChangeDeadline(doer *models.User, issue *models.Issue, oldDeadlineUnix timeutil.TimeStamp, comment *models.Comment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyIssueChangeDeadline(doer, issue, oldDeadlineUnix, comment)
+	}
+}
+
 // NotifyIssueChangeContent notifies change content to notifiers
 func NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 	for _, notifier := range notifiers {
@@ -297,3 +342,45 @@ func NotifyRepoPendingTransfer(doer, newOwner *models.User, repo *models.Reposit
 		notifier.NotifyRepoPendingTransfer(doer, newOwner, repo)
 	}
 }
+
+// NotifyCreateTeam notifies creation of a team to notifiers
+func NotifyCreateTeam(doer, org *models.User, team *models.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateTeam(doer, org, team)
+	}
+}
+
+// NotifyUpdateTeam notifies change of a team to notifiers
+func NotifyUpdateTeam(doer, org *models.User, team *models.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateTeam(doer, org, team)
+	}
+}
+
+// NotifyDeleteTeam notifies deletion of a team to notifiers
+func NotifyDeleteTeam(doer, org *models.User, team *models.Team) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteTeam(doer, org, team)
+	}
+}
+
+// NotifyAddTeamMember notifies addition of a member to a team to notifiers
+func NotifyAddTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyAddTeamMember(doer, org, team, member)
+	}
+}
+
+// NotifyRemoveTeamMember notifies removal of a member from a team to notifiers
+func NotifyRemoveTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRemoveTeamMember(doer, org, team, member)
+	}
+}
+
+// NotifyRemoveOrgMember notifies removal of a member from an organization to notifiers
+func NotifyRemoveOrgMember(doer, org, member *models.User) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRemoveOrgMember(doer, org, member)
+	}
+}
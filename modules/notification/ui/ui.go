@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 type (
@@ -94,6 +95,26 @@ func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue
 	})
 }
 
+func (ns *notificationService) NotifyIssueChangeDependency(doer *models.User, issue, dependency *models.Issue, removed bool) {
+	// the watchers of both repositories are notified
+	_ = ns.issueQueue.Push(issueNotificationOpts{
+		IssueID:              issue.ID,
+		NotificationAuthorID: doer.ID,
+	})
+	_ = ns.issueQueue.Push(issueNotificationOpts{
+		IssueID:              dependency.ID,
+		NotificationAuthorID: doer.ID,
+	})
+}
+
+func (ns *notificationService) NotifyIssueChangeDeadline(doer *models.User, issue *models.Issue, oldDeadlineUnix timeutil.TimeStamp, comment *models.Comment) {
+	_ = ns.issueQueue.Push(issueNotificationOpts{
+		IssueID:              issue.ID,
+		CommentID:            comment.ID,
+		NotificationAuthorID: doer.ID,
+	})
+}
+
 func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User) {
 	_ = ns.issueQueue.Push(issueNotificationOpts{
 		IssueID:              pr.Issue.ID,
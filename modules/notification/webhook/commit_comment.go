@@ -0,0 +1,33 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_services "code.gitea.io/gitea/services/webhook"
+)
+
+func sendCommitCommentHook(doer *models.User, repo *models.Repository, comment *models.CommitComment, action api.HookCommitCommentAction) {
+	mode, _ := models.AccessLevel(doer, repo)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventCommitComment, &api.CommitCommentPayload{
+		Action:     action,
+		Comment:    convert.ToCommitComment(repo, comment),
+		Repository: convert.ToRepo(repo, mode),
+		Sender:     convert.ToUser(doer, false, false),
+	}); err != nil {
+		log.Error("PrepareWebhooks [commit_comment: %d]: %v", comment.ID, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+	sendCommitCommentHook(doer, repo, comment, api.HookCommitCommentCreated)
+}
+
+func (m *webhookNotifier) NotifyDeleteCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) {
+	sendCommitCommentHook(doer, repo, comment, api.HookCommitCommentDeleted)
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_services "code.gitea.io/gitea/services/webhook"
+)
+
+// pendingStatus is the latest status of a commit whose status event is delayed to coalesce it with following ones
+type pendingStatus struct {
+	doer   *models.User
+	repo   *models.Repository
+	sha    string
+	status *models.CommitStatus
+}
+
+var (
+	pendingStatusesLock sync.Mutex
+	pendingStatuses     = make(map[string]*pendingStatus)
+)
+
+func sendStatusHook(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	statuses, err := models.GetLatestCommitStatus(repo.ID, sha, models.ListOptions{})
+	if err != nil {
+		log.Error("GetLatestCommitStatus: %v", err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(doer, repo)
+	apiRepo := convert.ToRepo(repo, mode)
+	if err := webhook_services.PrepareWebhooks(repo, models.HookEventStatus, &api.CommitStatusPayload{
+		SHA:            sha,
+		Status:         convert.ToCommitStatus(status),
+		CombinedStatus: convert.ToCombinedStatus(statuses, apiRepo),
+		Repository:     apiRepo,
+		Sender:         convert.ToUser(doer, false, false),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
+
+// NotifyCreateCommitStatus sends a status event for the new status of a commit. If STATUS_DEBOUNCE is set,
+// the event is delayed by it, and the statuses of the commit created meanwhile are sent in the same event.
+func (m *webhookNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	debounce := setting.Webhook.StatusDebounce
+	if debounce <= 0 {
+		sendStatusHook(doer, repo, sha, status)
+		return
+	}
+
+	key := fmt.Sprintf("%d/%s", repo.ID, sha)
+	pendingStatusesLock.Lock()
+	defer pendingStatusesLock.Unlock()
+	if pending, ok := pendingStatuses[key]; ok {
+		pending.doer = doer
+		pending.status = status
+		return
+	}
+	pendingStatuses[key] = &pendingStatus{doer: doer, repo: repo, sha: sha, status: status}
+
+	time.AfterFunc(debounce, func() {
+		pendingStatusesLock.Lock()
+		pending := pendingStatuses[key]
+		delete(pendingStatuses, key)
+		pendingStatusesLock.Unlock()
+
+		sendStatusHook(pending.doer, pending.repo, pending.sha, pending.status)
+	})
+}
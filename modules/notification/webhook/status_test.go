@@ -0,0 +1,75 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_NotifyCreateCommitStatus(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(debounce time.Duration) {
+		setting.Webhook.StatusDebounce = debounce
+	}(setting.Webhook.StatusDebounce)
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	hook := &models.Webhook{
+		RepoID:      repo.ID,
+		URL:         "http://www.example.com/status",
+		ContentType: models.ContentTypeJSON,
+		HookEvent:   &models.HookEvent{ChooseEvents: true, HookEvents: models.HookEvents{Status: true}},
+		IsActive:    true,
+		Type:        models.GITEA,
+	}
+	assert.NoError(t, hook.UpdateEvent())
+	assert.NoError(t, models.CreateWebhook(hook))
+
+	const sha = "1234123412341234123412341234123412341234"
+	statuses, err := models.GetLatestCommitStatus(repo.ID, sha, models.ListOptions{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, statuses)
+	countTasks := func() int {
+		tasks, err := hook.History(1)
+		assert.NoError(t, err)
+		return len(tasks)
+	}
+
+	notifier := NewNotifier()
+	setting.Webhook.StatusDebounce = 0
+	notifier.NotifyCreateCommitStatus(doer, repo, sha, statuses[0])
+	notifier.NotifyCreateCommitStatus(doer, repo, sha, statuses[0])
+	assert.Equal(t, 2, countTasks())
+
+	// the statuses created within the window are sent once with the latest state
+	setting.Webhook.StatusDebounce = 100 * time.Millisecond
+	for _, status := range statuses {
+		notifier.NotifyCreateCommitStatus(doer, repo, sha, status)
+	}
+	assert.Equal(t, 2, countTasks())
+	assert.Eventually(t, func() bool {
+		return countTasks() == 3
+	}, 5*time.Second, 20*time.Millisecond)
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 3, countTasks())
+
+	tasks, err := hook.History(1)
+	assert.NoError(t, err)
+	var payload api.CommitStatusPayload
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	assert.NoError(t, json.Unmarshal([]byte(tasks[0].PayloadContent), &payload))
+	assert.Equal(t, sha, payload.SHA)
+	assert.Equal(t, statuses[len(statuses)-1].Context, payload.Status.Context)
+	assert.Len(t, payload.CombinedStatus.Statuses, len(statuses))
+}
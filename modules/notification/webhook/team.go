@@ -0,0 +1,70 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_services "code.gitea.io/gitea/services/webhook"
+)
+
+func sendTeamHook(doer, org *models.User, team *models.Team, action api.HookTeamAction) {
+	log.Trace("Team %s: %s/%s by %s", action, org.Name, team.Name, doer.Name)
+	if err := webhook_services.PrepareOrgWebhooks(org, models.HookEventTeam, &api.TeamPayload{
+		Action:       action,
+		Team:         convert.ToTeam(team),
+		Organization: convert.ToOrganization(org),
+		Sender:       convert.ToUser(doer, false, false),
+	}); err != nil {
+		log.Error("PrepareOrgWebhooks: %v", err)
+	}
+}
+
+// sendMembershipHook sends a membership event for the member of the organization, of its team if team is not nil
+func sendMembershipHook(doer, org *models.User, team *models.Team, member *models.User, action api.HookMembershipAction) {
+	scope := api.HookMembershipScopeOrganization
+	if team != nil {
+		scope = api.HookMembershipScopeTeam
+		log.Trace("Team member %s: %s/%s %s by %s", action, org.Name, team.Name, member.Name, doer.Name)
+	} else {
+		log.Trace("Organization member %s: %s %s by %s", action, org.Name, member.Name, doer.Name)
+	}
+	if err := webhook_services.PrepareOrgWebhooks(org, models.HookEventMembership, &api.MembershipPayload{
+		Action:       action,
+		Scope:        scope,
+		Member:       convert.ToUser(member, false, false),
+		Team:         convert.ToTeam(team),
+		Organization: convert.ToOrganization(org),
+		Sender:       convert.ToUser(doer, false, false),
+	}); err != nil {
+		log.Error("PrepareOrgWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateTeam(doer, org *models.User, team *models.Team) {
+	sendTeamHook(doer, org, team, api.HookTeamCreated)
+}
+
+func (m *webhookNotifier) NotifyUpdateTeam(doer, org *models.User, team *models.Team) {
+	sendTeamHook(doer, org, team, api.HookTeamEdited)
+}
+
+func (m *webhookNotifier) NotifyDeleteTeam(doer, org *models.User, team *models.Team) {
+	sendTeamHook(doer, org, team, api.HookTeamDeleted)
+}
+
+func (m *webhookNotifier) NotifyAddTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+	sendMembershipHook(doer, org, team, member, api.HookMembershipAdded)
+}
+
+func (m *webhookNotifier) NotifyRemoveTeamMember(doer, org *models.User, team *models.Team, member *models.User) {
+	sendMembershipHook(doer, org, team, member, api.HookMembershipRemoved)
+}
+
+func (m *webhookNotifier) NotifyRemoveOrgMember(doer, org, member *models.User) {
+	sendMembershipHook(doer, org, nil, member, api.HookMembershipRemoved)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier_TeamAndMembership(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	org := models.AssertExistsAndLoadBean(t, &models.User{ID: 3}).(*models.User)
+	team := models.AssertExistsAndLoadBean(t, &models.Team{ID: 2}).(*models.Team)
+	member := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	hook := &models.Webhook{
+		OrgID:       org.ID,
+		URL:         "http://www.example.com/org",
+		ContentType: models.ContentTypeJSON,
+		HookEvent:   &models.HookEvent{ChooseEvents: true, HookEvents: models.HookEvents{Team: true, Membership: true}},
+		IsActive:    true,
+		Type:        models.GITEA,
+	}
+	assert.NoError(t, hook.UpdateEvent())
+	assert.NoError(t, models.CreateWebhook(hook))
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	lastTask := func(count int) *models.HookTask {
+		tasks, err := hook.History(1)
+		assert.NoError(t, err)
+		if !assert.Len(t, tasks, count) {
+			return nil
+		}
+		assert.EqualValues(t, 0, tasks[0].RepoID)
+		return tasks[0]
+	}
+
+	notifier := NewNotifier()
+	notifier.NotifyCreateTeam(doer, org, team)
+	task := lastTask(1)
+	assert.Equal(t, models.HookEventTeam, task.EventType)
+	var teamPayload api.TeamPayload
+	assert.NoError(t, json.Unmarshal([]byte(task.PayloadContent), &teamPayload))
+	assert.Equal(t, api.HookTeamCreated, teamPayload.Action)
+	assert.Equal(t, team.Name, teamPayload.Team.Name)
+	assert.Equal(t, org.Name, teamPayload.Organization.UserName)
+	assert.Equal(t, doer.Name, teamPayload.Sender.UserName)
+
+	notifier.NotifyAddTeamMember(doer, org, team, member)
+	task = lastTask(2)
+	assert.Equal(t, models.HookEventMembership, task.EventType)
+	var membershipPayload api.MembershipPayload
+	assert.NoError(t, json.Unmarshal([]byte(task.PayloadContent), &membershipPayload))
+	assert.Equal(t, api.HookMembershipAdded, membershipPayload.Action)
+	assert.Equal(t, api.HookMembershipScopeTeam, membershipPayload.Scope)
+	assert.Equal(t, member.Name, membershipPayload.Member.UserName)
+	assert.Equal(t, team.Name, membershipPayload.Team.Name)
+
+	notifier.NotifyRemoveOrgMember(doer, org, member)
+	task = lastTask(3)
+	membershipPayload = api.MembershipPayload{}
+	assert.NoError(t, json.Unmarshal([]byte(task.PayloadContent), &membershipPayload))
+	assert.Equal(t, api.HookMembershipRemoved, membershipPayload.Action)
+	assert.Equal(t, api.HookMembershipScopeOrganization, membershipPayload.Scope)
+	assert.Nil(t, membershipPayload.Team)
+
+	// the events are not sent to webhooks which have not chosen them
+	hook.Team = false
+	assert.NoError(t, hook.UpdateEvent())
+	assert.NoError(t, models.UpdateWebhook(hook))
+	notifier.NotifyDeleteTeam(doer, org, team)
+	lastTask(3)
+}
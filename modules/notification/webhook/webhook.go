@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	webhook_services "code.gitea.io/gitea/services/webhook"
 )
 
@@ -221,6 +222,93 @@ func (m *webhookNotifier) NotifyIssueChangeTitle(doer *models.User, issue *model
 	}
 }
 
+func (m *webhookNotifier) NotifyIssueChangeDeadline(doer *models.User, issue *models.Issue, oldDeadlineUnix timeutil.TimeStamp, comment *models.Comment) {
+	if err := issue.LoadAttributes(); err != nil {
+		log.Error("issue.LoadAttributes failed: %v", err)
+		return
+	}
+
+	changes := &api.ChangesPayload{
+		DueDate: &api.ChangesFromPayload{},
+	}
+	if oldDeadlineUnix > 0 {
+		changes.DueDate.From = oldDeadlineUnix.Format("2006-01-02")
+	}
+
+	mode, _ := models.AccessLevel(doer, issue.Repo)
+	var err error
+	if issue.IsPull {
+		issue.PullRequest.Issue = issue
+		err = webhook_services.PrepareWebhooks(issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+			Action:      api.HookIssueEdited,
+			Index:       issue.Index,
+			Changes:     changes,
+			PullRequest: convert.ToAPIPullRequest(issue.PullRequest),
+			Repository:  convert.ToRepo(issue.Repo, mode),
+			Sender:      convert.ToUser(doer, false, false),
+		})
+	} else {
+		err = webhook_services.PrepareWebhooks(issue.Repo, models.HookEventIssues, &api.IssuePayload{
+			Action:     api.HookIssueEdited,
+			Index:      issue.Index,
+			Changes:    changes,
+			Issue:      convert.ToAPIIssue(issue),
+			Repository: convert.ToRepo(issue.Repo, mode),
+			Sender:     convert.ToUser(doer, false, false),
+		})
+	}
+	if err != nil {
+		log.Error("PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
+	}
+}
+
+func (m *webhookNotifier) NotifyIssueChangeDependency(doer *models.User, issue, dependency *models.Issue, removed bool) {
+	for _, i := range []*models.Issue{issue, dependency} {
+		if err := i.LoadAttributes(); err != nil {
+			log.Error("issue.LoadAttributes failed: %v", err)
+			return
+		}
+	}
+
+	action := api.HookIssueDependencyAdded
+	if removed {
+		action = api.HookIssueDependencyRemoved
+	}
+	payload := &api.IssueDependencyPayload{
+		Blocked:  convert.ToAPIIssue(issue),
+		Blocking: convert.ToAPIIssue(dependency),
+	}
+
+	// the hooks of the repositories of both issues are triggered, each for its own issue
+	for _, i := range []*models.Issue{issue, dependency} {
+		mode, _ := models.AccessLevel(doer, i.Repo)
+		var err error
+		if i.IsPull {
+			i.PullRequest.Issue = i
+			err = webhook_services.PrepareWebhooks(i.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+				Action:      action,
+				Index:       i.Index,
+				PullRequest: convert.ToAPIPullRequest(i.PullRequest),
+				Repository:  convert.ToRepo(i.Repo, mode),
+				Sender:      convert.ToUser(doer, false, false),
+				Dependency:  payload,
+			})
+		} else {
+			err = webhook_services.PrepareWebhooks(i.Repo, models.HookEventIssues, &api.IssuePayload{
+				Action:     action,
+				Index:      i.Index,
+				Issue:      convert.ToAPIIssue(i),
+				Repository: convert.ToRepo(i.Repo, mode),
+				Sender:     convert.ToUser(doer, false, false),
+				Dependency: payload,
+			})
+		}
+		if err != nil {
+			log.Error("PrepareWebhooks [is_pull: %v]: %v", i.IsPull, err)
+		}
+	}
+}
+
 func (m *webhookNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, isClosed bool) {
 	mode, _ := models.AccessLevel(issue.Poster, issue.Repo)
 	var err error
@@ -620,6 +708,37 @@ func (*webhookNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *mod
 	}
 }
 
+func (m *webhookNotifier) NotifyPullRequestChangeMergeable(pr *models.PullRequest, oldStatus models.PullRequestStatus) {
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue failed: %v", err)
+		return
+	}
+	issue := pr.Issue
+	if err := issue.LoadAttributes(); err != nil {
+		log.Error("LoadAttributes failed: %v", err)
+		return
+	}
+	issue.PullRequest = pr
+	pr.Issue = issue
+
+	// the check runs in the background, the poster of the pull request is the sender
+	mode, _ := models.AccessLevel(issue.Poster, issue.Repo)
+	if err := webhook_services.PrepareWebhooks(issue.Repo, models.HookEventPullRequest, &api.PullRequestPayload{
+		Action: api.HookIssueMergeableChanged,
+		Index:  issue.Index,
+		Changes: &api.ChangesPayload{
+			MergeableState: &api.ChangesFromPayload{
+				From: oldStatus.Name(),
+			},
+		},
+		PullRequest: convert.ToAPIPullRequest(pr),
+		Repository:  convert.ToRepo(issue.Repo, mode),
+		Sender:      convert.ToUser(issue.Poster, false, false),
+	}); err != nil {
+		log.Error("PrepareWebhooks [pull_id: %v]: %v", pr.ID, err)
+	}
+}
+
 func (m *webhookNotifier) NotifyPullRequestChangeTargetBranch(doer *models.User, pr *models.PullRequest, oldBranch string) {
 	issue := pr.Issue
 	if !issue.IsPull {
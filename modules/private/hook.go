@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/modules/setting"
@@ -57,6 +58,11 @@ type HookOptions struct {
 	IsDeployKey                     bool
 }
 
+// HookPreReceiveResult represents the result of a successful PreReceive
+type HookPreReceiveResult struct {
+	Warnings []string
+}
+
 // HookPostReceiveResult represents an individual result from PostReceive
 type HookPostReceiveResult struct {
 	Results      []HookPostReceiveBranchResult
@@ -72,7 +78,8 @@ type HookPostReceiveBranchResult struct {
 	URL     string
 }
 
-// HookPreReceive check whether the provided commits are allowed
+// HookPreReceive check whether the provided commits are allowed.
+// If the push is allowed the returned message contains any warnings which should be shown to the pusher.
 func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string) {
 	reqURL := setting.LocalURL + fmt.Sprintf("api/internal/hook/pre-receive/%s/%s",
 		url.PathEscape(ownerName),
@@ -94,7 +101,10 @@ func HookPreReceive(ownerName, repoName string, opts HookOptions) (int, string)
 		return resp.StatusCode, decodeJSONError(resp).Err
 	}
 
-	return http.StatusOK, ""
+	res := &HookPreReceiveResult{}
+	_ = json.NewDecoder(resp.Body).Decode(res)
+
+	return http.StatusOK, strings.Join(res.Warnings, "\n")
 }
 
 // HookPostReceive updates services and users
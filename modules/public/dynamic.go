@@ -1,3 +1,4 @@
+//go:build !bindata
 // +build !bindata
 
 // Copyright 2016 The Gitea Authors. All rights reserved.
@@ -10,7 +11,10 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"time"
+
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // Static implements the static handler for serving assets.
@@ -22,3 +26,8 @@ func Static(opts *Options) func(next http.Handler) http.Handler {
 func ServeContent(w http.ResponseWriter, req *http.Request, fi os.FileInfo, modtime time.Time, content io.ReadSeeker) {
 	http.ServeContent(w, req, fi.Name(), modtime, content)
 }
+
+// staticAssets returns the file system of the assets shipped with Gitea
+func staticAssets() http.FileSystem {
+	return newStaticFileSystem(path.Join(setting.StaticRootPath, "public"))
+}
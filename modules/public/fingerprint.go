@@ -0,0 +1,96 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package public
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// fingerprintPrefix is the path prefix of fingerprinted asset URLs: /assets/<hash>/<name>
+const fingerprintPrefix = "/assets/"
+
+// immutableCacheControl is sent for fingerprinted assets, their URL changes with their content
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+var assetHashes sync.Map
+
+// openAsset opens an asset the same way it is served, custom assets take precedence
+func openAsset(name string) (http.File, error) {
+	f, err := newStaticFileSystem(path.Join(setting.CustomPath, "public")).Open("/" + name)
+	if err == nil {
+		return f, nil
+	}
+	return staticAssets().Open("/" + name)
+}
+
+// assetHash returns the hash of the content of an asset, it is cached in production
+func assetHash(name string) (string, error) {
+	if setting.IsProd() {
+		if hash, ok := assetHashes.Load(name); ok {
+			return hash.(string), nil
+		}
+	}
+
+	f, err := openAsset(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err != nil {
+		return "", err
+	} else if fi.IsDir() {
+		return "", fmt.Errorf("%s is a directory", name)
+	}
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))[:16]
+	assetHashes.Store(name, hash)
+	return hash, nil
+}
+
+// AssetURL returns the URL of a static asset. If fingerprinting is enabled, the URL
+// contains the hash of the content of the asset and can be cached forever.
+func AssetURL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if setting.EnableFingerprints {
+		hash, err := assetHash(name)
+		if err == nil {
+			return setting.StaticURLPrefix + fingerprintPrefix + hash + "/" + name
+		}
+		log.Error("Unable to fingerprint asset %s: %v", name, err)
+	}
+	return setting.StaticURLPrefix + "/" + name + "?v=" + base.EncodeMD5(setting.AppVer)
+}
+
+// parseFingerprintedPath returns the path of the asset requested by a fingerprinted URL,
+// and whether the hash of the URL matches the current content of the asset.
+// Assets referenced with a relative path by a fingerprinted asset (e.g. images of a stylesheet)
+// share its hash, they are served but not cached forever.
+func parseFingerprintedPath(file string) (name string, matches, ok bool) {
+	if !strings.HasPrefix(file, fingerprintPrefix) {
+		return "", false, false
+	}
+	parts := strings.SplitN(file[len(fingerprintPrefix):], "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", false, false
+	}
+	name = path.Clean("/" + parts[1])
+	hash, err := assetHash(name[1:])
+	return name, err == nil && hash == parts[0], true
+}
@@ -115,6 +115,12 @@ func (opts *Options) handle(w http.ResponseWriter, req *http.Request, opt *Optio
 		}
 	}
 
+	// Fingerprinted URLs serve the same files as the plain ones
+	name, immutable, fingerprinted := parseFingerprintedPath(file)
+	if fingerprinted {
+		file = name
+	}
+
 	f, err := opt.FileSystem.Open(file)
 	if err != nil {
 		// 404 requests to any known entries in `public`
@@ -167,6 +173,9 @@ func (opts *Options) handle(w http.ResponseWriter, req *http.Request, opt *Optio
 	if httpcache.HandleEtagCache(req, w, fi) {
 		return true
 	}
+	if immutable && setting.IsProd() {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	}
 
 	ServeContent(w, req, fi, fi.ModTime(), f)
 	return true
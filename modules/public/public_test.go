@@ -5,8 +5,16 @@
 package public
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +46,54 @@ func TestParseAcceptEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestFingerprintedAssets(t *testing.T) {
+	defer func(customPath, runMode string, enabled bool) {
+		setting.CustomPath = customPath
+		setting.RunMode = runMode
+		setting.EnableFingerprints = enabled
+	}(setting.CustomPath, setting.RunMode, setting.EnableFingerprints)
+
+	tmpDir, err := ioutil.TempDir("", "fingerprint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "public", "css"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "public", "css", "custom.css"), []byte("body {}"), 0644))
+	setting.CustomPath = tmpDir
+	setting.RunMode = "dev"
+
+	setting.EnableFingerprints = false
+	assert.True(t, strings.HasPrefix(AssetURL("css/custom.css"), setting.StaticURLPrefix+"/css/custom.css?v="))
+
+	setting.EnableFingerprints = true
+	url := AssetURL("/css/custom.css")
+	assert.Regexp(t, `/assets/[0-9a-f]{16}/css/custom\.css$`, url)
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		handler := Custom(&Options{SkipLogging: true})(http.NotFoundHandler())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		return rec
+	}
+	setting.RunMode = "prod"
+
+	rec := serve(strings.TrimPrefix(url, setting.StaticURLPrefix))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body {}", rec.Body.String())
+	assert.Equal(t, immutableCacheControl, rec.Header().Get("Cache-Control"))
+
+	// a stale hash is still served, but not cached forever
+	rec = serve("/assets/0123456789abcdef/css/custom.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, immutableCacheControl, rec.Header().Get("Cache-Control"))
+
+	// the plain URL keeps working
+	rec = serve("/css/custom.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "body {}", rec.Body.String())
+
+	// the hash changes with the content
+	assetHashes.Delete("css/custom.css")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "public", "css", "custom.css"), []byte("body { margin: 0 }"), 0644))
+	assert.NotEqual(t, url, AssetURL("css/custom.css"))
+}
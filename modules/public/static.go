@@ -1,3 +1,4 @@
+//go:build bindata
 // +build bindata
 
 // Copyright 2016 The Gitea Authors. All rights reserved.
@@ -89,3 +90,8 @@ func ServeContent(w http.ResponseWriter, req *http.Request, fi os.FileInfo, modt
 	http.ServeContent(w, req, fi.Name(), modtime, content)
 	return
 }
+
+// staticAssets returns the file system of the assets shipped with Gitea
+func staticAssets() http.FileSystem {
+	return Assets
+}
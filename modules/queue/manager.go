@@ -53,6 +53,14 @@ type Flushable interface {
 	IsEmpty() bool
 }
 
+// Measurable represents a pool or queue that counts the data it holds and has handled
+type Measurable interface {
+	// NumberInQueue returns the number of data waiting to be handled
+	NumberInQueue() int64
+	// NumberProcessed returns the number of data which have been handled
+	NumberProcessed() int64
+}
+
 // ManagedPool is a simple interface to get certain details from a worker pool
 type ManagedPool interface {
 	// AddWorkers adds a number of worker as group to the pool with the provided timeout. A CancelFunc is provided to cancel the group
@@ -298,6 +306,22 @@ func (q *ManagedQueue) IsEmpty() bool {
 	return true
 }
 
+// NumberInQueue returns the number of data waiting in the queue or -1 if it is unknown
+func (q *ManagedQueue) NumberInQueue() int64 {
+	if measurable, ok := q.Managed.(Measurable); ok {
+		return measurable.NumberInQueue()
+	}
+	return -1
+}
+
+// NumberProcessed returns the number of data the queue has handled or -1 if it is unknown
+func (q *ManagedQueue) NumberProcessed() int64 {
+	if measurable, ok := q.Managed.(Measurable); ok {
+		return measurable.NumberProcessed()
+	}
+	return -1
+}
+
 // NumberOfWorkers returns the number of workers in the queue
 func (q *ManagedQueue) NumberOfWorkers() int {
 	if pool, ok := q.Managed.(ManagedPool); ok {
@@ -89,6 +89,11 @@ func (q *ByteFIFOQueue) IsEmpty() bool {
 	return q.byteFIFO.Len() == 0
 }
 
+// NumberInQueue returns the number of data waiting in the fifo and in the worker pool
+func (q *ByteFIFOQueue) NumberInQueue() int64 {
+	return q.WorkerPool.NumberInQueue() + q.byteFIFO.Len()
+}
+
 // Run runs the bytefifo queue
 func (q *ByteFIFOQueue) Run(atShutdown, atTerminate func(context.Context, func())) {
 	atShutdown(context.Background(), q.Shutdown)
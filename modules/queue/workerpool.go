@@ -33,6 +33,7 @@ type WorkerPool struct {
 	boostTimeout       time.Duration
 	boostWorkers       int
 	numInQueue         int64
+	numProcessed       int64
 }
 
 // WorkerPoolConfiguration is the basic configuration for a WorkerPool
@@ -272,7 +273,7 @@ func (p *WorkerPool) CleanUp(ctx context.Context) {
 	close(p.dataChan)
 	for data := range p.dataChan {
 		p.handle(data)
-		atomic.AddInt64(&p.numInQueue, -1)
+		p.handled(1)
 		select {
 		case <-ctx.Done():
 			log.Warn("WorkerPool: %d Cleanup context closed before finishing clean-up", p.qid)
@@ -295,6 +296,22 @@ func (p *WorkerPool) IsEmpty() bool {
 	return atomic.LoadInt64(&p.numInQueue) == 0
 }
 
+// NumberInQueue returns the number of data pushed to the pool which have not been handled yet
+func (p *WorkerPool) NumberInQueue() int64 {
+	return atomic.LoadInt64(&p.numInQueue)
+}
+
+// NumberProcessed returns the number of data the pool has handled
+func (p *WorkerPool) NumberProcessed() int64 {
+	return atomic.LoadInt64(&p.numProcessed)
+}
+
+// handled records that the given number of data have been handled
+func (p *WorkerPool) handled(number int) {
+	atomic.AddInt64(&p.numInQueue, -int64(number))
+	atomic.AddInt64(&p.numProcessed, int64(number))
+}
+
 // FlushWithContext is very similar to CleanUp but it will return as soon as the dataChan is empty
 // NB: The worker will not be registered with the manager.
 func (p *WorkerPool) FlushWithContext(ctx context.Context) error {
@@ -303,7 +320,7 @@ func (p *WorkerPool) FlushWithContext(ctx context.Context) error {
 		select {
 		case data := <-p.dataChan:
 			p.handle(data)
-			atomic.AddInt64(&p.numInQueue, -1)
+			p.handled(1)
 		case <-p.baseCtx.Done():
 			return p.baseCtx.Err()
 		case <-ctx.Done():
@@ -323,7 +340,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 			if len(data) > 0 {
 				log.Trace("Handling: %d data, %v", len(data), data)
 				p.handle(data...)
-				atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+				p.handled(len(data))
 			}
 			log.Trace("Worker shutting down")
 			return
@@ -333,7 +350,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 				if len(data) > 0 {
 					log.Trace("Handling: %d data, %v", len(data), data)
 					p.handle(data...)
-					atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+					p.handled(len(data))
 				}
 				log.Trace("Worker shutting down")
 				return
@@ -342,7 +359,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 			if len(data) >= p.batchLength {
 				log.Trace("Handling: %d data, %v", len(data), data)
 				p.handle(data...)
-				atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+				p.handled(len(data))
 				data = make([]Data, 0, p.batchLength)
 			}
 		default:
@@ -353,7 +370,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 				if len(data) > 0 {
 					log.Trace("Handling: %d data, %v", len(data), data)
 					p.handle(data...)
-					atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+					p.handled(len(data))
 				}
 				log.Trace("Worker shutting down")
 				return
@@ -364,7 +381,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 					if len(data) > 0 {
 						log.Trace("Handling: %d data, %v", len(data), data)
 						p.handle(data...)
-						atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+						p.handled(len(data))
 					}
 					log.Trace("Worker shutting down")
 					return
@@ -373,7 +390,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 				if len(data) >= p.batchLength {
 					log.Trace("Handling: %d data, %v", len(data), data)
 					p.handle(data...)
-					atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+					p.handled(len(data))
 					data = make([]Data, 0, p.batchLength)
 				}
 			case <-timer.C:
@@ -381,7 +398,7 @@ func (p *WorkerPool) doWork(ctx context.Context) {
 				if len(data) > 0 {
 					log.Trace("Handling: %d data, %v", len(data), data)
 					p.handle(data...)
-					atomic.AddInt64(&p.numInQueue, -1*int64(len(data)))
+					p.handled(len(data))
 					data = make([]Data, 0, p.batchLength)
 				}
 
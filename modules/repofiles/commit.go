@@ -5,8 +5,13 @@
 package repofiles
 
 import (
+	"fmt"
+
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // CountDivergingCommits determines how many commits a branch is ahead or behind the repository's base branch
@@ -17,3 +22,46 @@ func CountDivergingCommits(repo *models.Repository, branch string) (*git.Diverge
 	}
 	return &divergence, nil
 }
+
+func divergenceCacheKey(baseCommitID, headCommitID string) string {
+	return fmt.Sprintf("divergence:%s:%s", baseCommitID, headCommitID)
+}
+
+// CountBranchesDivergingCommits determines how many commits each of the branches, given with their commit ids,
+// is ahead or behind the base commit of the repository. The counts are cached by the commit ids, so only the
+// branches which changed since they were last counted are looked up, all at once.
+func CountBranchesDivergingCommits(repo *models.Repository, baseCommitID string, branchCommitIDs map[string]string) (map[string]*git.DivergeObject, error) {
+	divergences := make(map[string]*git.DivergeObject, len(branchCommitIDs))
+	c := cache.GetCache()
+	useCache := c != nil && setting.CacheService.TTL > 0
+
+	uncounted := make([]string, 0, len(branchCommitIDs))
+	for branch, commitID := range branchCommitIDs {
+		if useCache {
+			if cached := c.Get(divergenceCacheKey(baseCommitID, commitID)); cached != nil {
+				divergence := new(git.DivergeObject)
+				if _, err := fmt.Sscanf(fmt.Sprint(cached), "%d %d", &divergence.Ahead, &divergence.Behind); err == nil {
+					divergences[branch] = divergence
+					continue
+				}
+			}
+		}
+		uncounted = append(uncounted, branch)
+	}
+
+	counted, err := git.GetBranchesDivergingCommits(repo.RepoPath(), baseCommitID, uncounted)
+	if err != nil {
+		return nil, err
+	}
+	for branch, divergence := range counted {
+		divergence := divergence
+		divergences[branch] = &divergence
+		if useCache {
+			key := divergenceCacheKey(baseCommitID, branchCommitIDs[branch])
+			if err := c.Put(key, fmt.Sprintf("%d %d", divergence.Ahead, divergence.Behind), setting.CacheService.TTLSeconds()); err != nil {
+				log.Error("Unable to cache the diverging commits of %s: %v", branch, err)
+			}
+		}
+	}
+	return divergences, nil
+}
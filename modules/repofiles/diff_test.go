@@ -33,18 +33,19 @@ func TestGetDiffPreview(t *testing.T) {
 		TotalDeletion: 1,
 		Files: []*gitdiff.DiffFile{
 			{
-				Name:        "README.md",
-				OldName:     "README.md",
-				Index:       1,
-				Addition:    2,
-				Deletion:    1,
-				Type:        2,
-				IsCreated:   false,
-				IsDeleted:   false,
-				IsBin:       false,
-				IsLFSFile:   false,
-				IsRenamed:   false,
-				IsSubmodule: false,
+				Name:           "README.md",
+				OldName:        "README.md",
+				Index:          1,
+				Addition:       2,
+				Deletion:       1,
+				Type:           2,
+				IsCreated:      false,
+				IsDeleted:      false,
+				IsBin:          false,
+				IsLFSFile:      false,
+				IsRenamed:      false,
+				IsSubmodule:    false,
+				ShownLineCount: 5,
 				Sections: []*gitdiff.DiffSection{
 					{
 						FileName: "README.md",
@@ -22,7 +22,7 @@ import (
 func AdoptRepository(doer, u *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
 	if !doer.IsAdmin && !u.CanCreateRepo() {
 		return nil, models.ErrReachLimitOfRepo{
-			Limit: u.MaxRepoCreation,
+			Limit: u.MaxCreationLimit(),
 		}
 	}
 
@@ -7,6 +7,8 @@ package repository
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -33,14 +35,7 @@ func GitFsck(ctx context.Context, timeout time.Duration, args []string) error {
 				return models.ErrCancelledf("before fsck of %s", repo.FullName())
 			default:
 			}
-			log.Trace("Running health check on repository %v", repo)
-			repoPath := repo.RepoPath()
-			if err := git.Fsck(ctx, repoPath, timeout, args...); err != nil {
-				log.Warn("Failed to health check repository (%v): %v", repo, err)
-				if err = models.CreateRepositoryNotice("Failed to health check repository (%s): %v", repo.FullName(), err); err != nil {
-					log.Error("CreateRepositoryNotice: %v", err)
-				}
-			}
+			_ = GitFsckRepo(ctx, repo, timeout, args)
 			return nil
 		},
 	); err != nil {
@@ -55,7 +50,6 @@ func GitFsck(ctx context.Context, timeout time.Duration, args []string) error {
 // GitGcRepos calls 'git gc' to remove unnecessary files and optimize the local repository
 func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) error {
 	log.Trace("Doing: GitGcRepos")
-	args = append([]string{"gc"}, args...)
 
 	if err := models.Iterate(
 		models.DefaultDBContext(),
@@ -68,30 +62,7 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 				return models.ErrCancelledf("before GC of %s", repo.FullName())
 			default:
 			}
-			log.Trace("Running git gc on %v", repo)
-			command := git.NewCommandContext(ctx, args...).
-				SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
-			var stdout string
-			var err error
-			if timeout > 0 {
-				var stdoutBytes []byte
-				stdoutBytes, err = command.RunInDirTimeout(
-					timeout,
-					repo.RepoPath())
-				stdout = string(stdoutBytes)
-			} else {
-				stdout, err = command.RunInDir(repo.RepoPath())
-			}
-
-			if err != nil {
-				log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
-				desc := fmt.Sprintf("Repository garbage collection failed for %s. Stdout: %s\nError: %v", repo.RepoPath(), stdout, err)
-				if err = models.CreateRepositoryNotice(desc); err != nil {
-					log.Error("CreateRepositoryNotice: %v", err)
-				}
-				return fmt.Errorf("Repository garbage collection failed in repo: %s: Error: %v", repo.FullName(), err)
-			}
-			return nil
+			return GitGcRepo(ctx, repo, timeout, args...)
 		},
 	); err != nil {
 		return err
@@ -101,6 +72,66 @@ func GitGcRepos(ctx context.Context, timeout time.Duration, args ...string) erro
 	return nil
 }
 
+// GitFsckRepo calls 'git fsck' to check the health of the given repository,
+// failures are recorded as repository notices.
+func GitFsckRepo(ctx context.Context, repo *models.Repository, timeout time.Duration, args []string) error {
+	log.Trace("Running health check on repository %v", repo)
+	if err := git.Fsck(ctx, repo.RepoPath(), timeout, args...); err != nil {
+		log.Warn("Failed to health check repository (%v): %v", repo, err)
+		if err := models.CreateRepositoryNotice("Failed to health check repository (%s): %v", repo.FullName(), err); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GitGcRepo calls 'git gc' on the given repository, failures are recorded as repository notices.
+func GitGcRepo(ctx context.Context, repo *models.Repository, timeout time.Duration, args ...string) error {
+	log.Trace("Running git gc on %v", repo)
+	command := git.NewCommandContext(ctx, append([]string{"gc"}, args...)...).
+		SetDescription(fmt.Sprintf("Repository Garbage Collection: %s", repo.FullName()))
+	var stdout string
+	var err error
+	if timeout > 0 {
+		var stdoutBytes []byte
+		stdoutBytes, err = command.RunInDirTimeout(
+			timeout,
+			repo.RepoPath())
+		stdout = string(stdoutBytes)
+	} else {
+		stdout, err = command.RunInDir(repo.RepoPath())
+	}
+
+	if err != nil {
+		log.Error("Repository garbage collection failed for %v. Stdout: %s\nError: %v", repo, stdout, err)
+		desc := fmt.Sprintf("Repository garbage collection failed for %s. Stdout: %s\nError: %v", repo.RepoPath(), stdout, err)
+		if err = models.CreateRepositoryNotice(desc); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+		return fmt.Errorf("Repository garbage collection failed in repo: %s: Error: %v", repo.FullName(), err)
+	}
+	return nil
+}
+
+// PackSize returns the total size in bytes of the pack files of the given repository
+func PackSize(repo *models.Repository) (int64, error) {
+	var size int64
+	err := filepath.Walk(filepath.Join(repo.RepoPath(), "objects", "pack"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 func gatherMissingRepoRecords(ctx context.Context) ([]*models.Repository, error) {
 	repos := make([]*models.Repository, 0, 10)
 	if err := models.Iterate(
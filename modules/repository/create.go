@@ -19,7 +19,7 @@ import (
 func CreateRepository(doer, u *models.User, opts models.CreateRepoOptions) (*models.Repository, error) {
 	if !doer.IsAdmin && !u.CanCreateRepo() {
 		return nil, models.ErrReachLimitOfRepo{
-			Limit: u.MaxRepoCreation,
+			Limit: u.MaxCreationLimit(),
 		}
 	}
 
@@ -17,6 +17,12 @@ import (
 
 // ForkRepository forks a repository
 func ForkRepository(doer, owner *models.User, oldRepo *models.Repository, name, desc string) (_ *models.Repository, err error) {
+	if !doer.IsAdmin && !owner.CanCreateRepo() {
+		return nil, models.ErrReachLimitOfRepo{
+			Limit: owner.MaxCreationLimit(),
+		}
+	}
+
 	forkedRepo, err := oldRepo.GetUserFork(owner.ID)
 	if err != nil {
 		return nil, err
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,3 +25,19 @@ func TestForkRepository(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, models.IsErrForkAlreadyExist(err))
 }
+
+func TestForkRepository_ReachLimit(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	defer func(limit int) {
+		setting.Repository.MaxCreationLimit = limit
+	}(setting.Repository.MaxCreationLimit)
+	setting.Repository.MaxCreationLimit = 0
+
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	fork, err := ForkRepository(user, user, repo, "test", "test")
+	assert.Nil(t, fork)
+	assert.True(t, models.IsErrReachLimitOfRepo(err))
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secretscan
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+)
+
+// AllowlistPath is the path of the file listing known false positives in a repository
+const AllowlistPath = ".gitea/secret-scanning-allow"
+
+// maxLineLength lines longer than this, e.g. minified files, are not scanned
+const maxLineLength = 4096
+
+// Pattern is a named regular expression matching a kind of secret
+type Pattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// Finding is a possible secret found in a line
+type Finding struct {
+	Pattern string
+	Match   string
+}
+
+// Masked returns the match with all but the first characters hidden
+func (f *Finding) Masked() string {
+	if len(f.Match) <= 8 {
+		return strings.Repeat("*", len(f.Match))
+	}
+	return f.Match[:4] + strings.Repeat("*", len(f.Match)-4)
+}
+
+// Scanner finds secrets in lines of text
+type Scanner struct {
+	patterns     []*Pattern
+	allowed      []*regexp.Regexp
+	allowedPaths []glob.Glob
+}
+
+// NewScanner compiles the given name to regular expression pairs, invalid expressions are skipped
+func NewScanner(patterns map[string]string) *Scanner {
+	s := &Scanner{patterns: make([]*Pattern, 0, len(patterns))}
+	for name, expr := range patterns {
+		if expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			log.Error("Invalid secret pattern %s '%s' (skipped): %v", name, expr, err)
+			continue
+		}
+		s.patterns = append(s.patterns, &Pattern{Name: name, Regexp: re})
+	}
+	sort.Slice(s.patterns, func(i, j int) bool { return s.patterns[i].Name < s.patterns[j].Name })
+	return s
+}
+
+// HasPatterns returns true if the scanner has at least one pattern
+func (s *Scanner) HasPatterns() bool {
+	return len(s.patterns) > 0
+}
+
+// LoadAllowlist parses the content of an allowlist file. Every line is a regular expression matched
+// against detected secrets, lines starting with "path:" contain a glob of files which are not scanned.
+// Empty lines and lines starting with "#" are ignored.
+func (s *Scanner) LoadAllowlist(content string) error {
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "path:") {
+			g, err := glob.Compile(strings.TrimSpace(strings.TrimPrefix(line, "path:")), '/')
+			if err != nil {
+				return fmt.Errorf("%s line %d: %v", AllowlistPath, i+1, err)
+			}
+			s.allowedPaths = append(s.allowedPaths, g)
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %v", AllowlistPath, i+1, err)
+		}
+		s.allowed = append(s.allowed, re)
+	}
+	return nil
+}
+
+// IsPathAllowed returns true if the file is excluded from scanning
+func (s *Scanner) IsPathAllowed(path string) bool {
+	for _, g := range s.allowedPaths {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanLine returns the secrets found in a line of the file
+func (s *Scanner) ScanLine(path, line string) []*Finding {
+	if len(line) > maxLineLength || s.IsPathAllowed(path) {
+		return nil
+	}
+
+	var findings []*Finding
+	for _, pattern := range s.patterns {
+		for _, match := range pattern.Regexp.FindAllString(line, -1) {
+			if s.isAllowed(match) {
+				continue
+			}
+			findings = append(findings, &Finding{Pattern: pattern.Name, Match: match})
+		}
+	}
+	return findings
+}
+
+func (s *Scanner) isAllowed(match string) bool {
+	for _, re := range s.allowed {
+		if re.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
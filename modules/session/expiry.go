@@ -0,0 +1,67 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	"gitea.com/go-chi/session"
+)
+
+const (
+	createdUnixKey    = "_created_unix"
+	lastActiveUnixKey = "_last_active_unix"
+)
+
+// Expirer returns a middleware which expires signed-in sessions that have been
+// inactive for longer than idleTimeout seconds or exist for longer than
+// maxLifetime seconds. Every request touching a session refreshes its idle clock.
+// An idleTimeout of zero disables the middleware.
+func Expirer(idleTimeout, maxLifetime int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if idleTimeout > 0 {
+				if sess := session.GetSession(req); sess != nil {
+					if checkExpiry(sess, time.Now().Unix(), idleTimeout, maxLifetime) {
+						log.Trace("Session %s expired", sess.ID())
+					}
+				}
+			}
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+// checkExpiry flushes the session if it has expired and otherwise refreshes its
+// idle clock. Anonymous sessions are left untouched so that they are not
+// persisted. It returns true if the session was expired.
+func checkExpiry(sess session.RawStore, now, idleTimeout, maxLifetime int64) bool {
+	if sess.Get("uid") == nil {
+		return false
+	}
+
+	created, _ := sess.Get(createdUnixKey).(int64)
+	lastActive, _ := sess.Get(lastActiveUnixKey).(int64)
+	if (created > 0 && maxLifetime > 0 && now-created > maxLifetime) ||
+		(lastActive > 0 && now-lastActive > idleTimeout) {
+		if err := models.DeleteUserSessionByKeyHash(TrackedKeyHash(sess)); err != nil {
+			log.Error("DeleteUserSessionByKeyHash: %v", err)
+		}
+		if err := sess.Flush(); err != nil {
+			log.Error("Unable to flush expired session %s: %v", sess.ID(), err)
+		}
+		return true
+	}
+
+	if created == 0 {
+		_ = sess.Set(createdUnixKey, now)
+	}
+	_ = sess.Set(lastActiveUnixKey, now)
+	return false
+}
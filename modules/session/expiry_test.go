@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExpiry(t *testing.T) {
+	newStore := func(kv map[interface{}]interface{}) *VirtualStore {
+		return NewVirtualStore(nil, "sid", kv)
+	}
+
+	// anonymous sessions are never touched
+	sess := newStore(map[interface{}]interface{}{})
+	assert.False(t, checkExpiry(sess, 1000, 60, 3600))
+	assert.Nil(t, sess.Get(lastActiveUnixKey))
+
+	// first request starts both clocks
+	sess = newStore(map[interface{}]interface{}{"uid": int64(1)})
+	assert.False(t, checkExpiry(sess, 1000, 60, 3600))
+	assert.EqualValues(t, 1000, sess.Get(createdUnixKey))
+	assert.EqualValues(t, 1000, sess.Get(lastActiveUnixKey))
+
+	// activity refreshes the idle clock only
+	assert.False(t, checkExpiry(sess, 1050, 60, 3600))
+	assert.False(t, checkExpiry(sess, 1100, 60, 3600))
+	assert.EqualValues(t, 1000, sess.Get(createdUnixKey))
+	assert.EqualValues(t, 1100, sess.Get(lastActiveUnixKey))
+
+	// idle for too long
+	assert.True(t, checkExpiry(sess, 1161, 60, 3600))
+	assert.Nil(t, sess.Get("uid"))
+
+	// active but past the absolute lifetime
+	sess = newStore(map[interface{}]interface{}{"uid": int64(1), createdUnixKey: int64(1000), lastActiveUnixKey: int64(4590)})
+	assert.True(t, checkExpiry(sess, 4601, 60, 3600))
+	assert.Nil(t, sess.Get("uid"))
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"errors"
+
+	"code.gitea.io/gitea/models"
+)
+
+const (
+	impersonatorIDKey   = "impersonatorUid"
+	impersonatorNameKey = "impersonatorUname"
+)
+
+// ErrNestedImpersonation is returned when an impersonated session tries to impersonate another user
+var ErrNestedImpersonation = errors.New("the session already impersonates a user")
+
+// Impersonate signs the session in as the target user on behalf of the admin. The session stays tracked
+// as a session of the admin, who can return to their own account with StopImpersonation.
+func Impersonate(sess Store, admin, target *models.User) error {
+	if ImpersonatorID(sess) > 0 {
+		return ErrNestedImpersonation
+	}
+	if err := sess.Set(impersonatorIDKey, admin.ID); err != nil {
+		return err
+	}
+	if err := sess.Set(impersonatorNameKey, admin.Name); err != nil {
+		return err
+	}
+	if err := sess.Set("uid", target.ID); err != nil {
+		return err
+	}
+	return sess.Set("uname", target.Name)
+}
+
+// StopImpersonation signs the session back in as the admin who impersonated the user and returns the id of
+// the admin, zero if the session is not impersonated
+func StopImpersonation(sess Store) (int64, error) {
+	adminID := ImpersonatorID(sess)
+	if adminID == 0 {
+		return 0, nil
+	}
+	if err := sess.Set("uid", adminID); err != nil {
+		return 0, err
+	}
+	if err := sess.Set("uname", ImpersonatorName(sess)); err != nil {
+		return 0, err
+	}
+	if err := sess.Delete(impersonatorIDKey); err != nil {
+		return 0, err
+	}
+	return adminID, sess.Delete(impersonatorNameKey)
+}
+
+// ImpersonatorID returns the id of the admin who impersonates the user signed in with the session,
+// zero if the session is not impersonated
+func ImpersonatorID(sess Store) int64 {
+	id, _ := sess.Get(impersonatorIDKey).(int64)
+	return id
+}
+
+// ImpersonatorName returns the name of the admin who impersonates the user signed in with the session
+func ImpersonatorName(sess Store) string {
+	name, _ := sess.Get(impersonatorNameKey).(string)
+	return name
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonate(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	admin := models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	user := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	other := models.AssertExistsAndLoadBean(t, &models.User{ID: 4}).(*models.User)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	sess := NewVirtualStore(nil, "sid1", map[interface{}]interface{}{"uid": admin.ID, "uname": admin.Name})
+	assert.True(t, trackSession(sess, req, 3600))
+
+	assert.NoError(t, Impersonate(sess, admin, user))
+	assert.Equal(t, user.ID, sess.Get("uid"))
+	assert.Equal(t, admin.ID, ImpersonatorID(sess))
+	assert.Equal(t, admin.Name, ImpersonatorName(sess))
+
+	// the session stays tracked as a session of the admin
+	assert.True(t, trackSession(sess, req, 3600))
+	models.AssertExistsAndLoadBean(t, &models.UserSession{UID: admin.ID, KeyHash: TrackedKeyHash(sess)})
+
+	assert.Equal(t, ErrNestedImpersonation, Impersonate(sess, user, other))
+
+	adminID, err := StopImpersonation(sess)
+	assert.NoError(t, err)
+	assert.Equal(t, admin.ID, adminID)
+	assert.Equal(t, admin.ID, sess.Get("uid"))
+	assert.Equal(t, admin.Name, sess.Get("uname"))
+	assert.Zero(t, ImpersonatorID(sess))
+
+	adminID, err = StopImpersonation(sess)
+	assert.NoError(t, err)
+	assert.Zero(t, adminID)
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"net"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web/middleware"
+
+	"gitea.com/go-chi/session"
+)
+
+const trackedKeyHashKey = "_tracked_key_hash"
+
+// Tracker returns a middleware which records the signed-in sessions of users, so that they
+// can list and revoke them. A revoked session is signed out on its next request, only the time
+// the session has last been seen is written to the database at most once a minute.
+// Sessions which have not been seen for longer than maxLifetime seconds are no longer listed.
+func Tracker(maxLifetime int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			if sess := session.GetSession(req); sess != nil {
+				trackSession(sess, req, maxLifetime)
+			}
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+// trackSession records the activity of a signed-in session and flushes it if it has been
+// revoked. It returns false if the session has been revoked.
+func trackSession(sess session.RawStore, req *http.Request, maxLifetime int64) bool {
+	uid, ok := sess.Get("uid").(int64)
+	if !ok {
+		return true
+	}
+	// an impersonated session belongs to the admin
+	if adminID := ImpersonatorID(sess); adminID > 0 {
+		uid = adminID
+	}
+
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		ip = req.RemoteAddr
+	}
+	keyHash := models.HashSessionKey(sess.ID())
+	trackedKeyHash, _ := sess.Get(trackedKeyHashKey).(string)
+	rememberLookupKey := models.RememberLookupKey(middleware.GetCookie(req, setting.CookieRememberName))
+
+	if trackedKeyHash == "" {
+		since := timeutil.TimeStampNow().Add(-maxLifetime)
+		if _, err := models.CreateUserSession(uid, keyHash, rememberLookupKey, req.UserAgent(), ip, since); err != nil {
+			log.Error("CreateUserSession: %v", err)
+			return true
+		}
+		_ = sess.Set(trackedKeyHashKey, keyHash)
+		return true
+	}
+
+	if err := models.TouchUserSession(uid, trackedKeyHash, keyHash, rememberLookupKey, req.UserAgent(), ip); err != nil {
+		if !models.IsErrUserSessionNotExist(err) {
+			log.Error("TouchUserSession: %v", err)
+			return true
+		}
+		log.Trace("Session %s of user %d has been revoked", sess.ID(), uid)
+		if err := sess.Flush(); err != nil {
+			log.Error("Unable to flush revoked session %s: %v", sess.ID(), err)
+		}
+		return false
+	}
+	if trackedKeyHash != keyHash {
+		_ = sess.Set(trackedKeyHashKey, keyHash)
+	}
+	return true
+}
+
+// TrackedKeyHash returns the hash the given session is tracked with, empty if it is not tracked
+func TrackedKeyHash(sess session.RawStore) string {
+	keyHash, _ := sess.Get(trackedKeyHashKey).(string)
+	return keyHash
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package session
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackSession(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "Laptop")
+
+	// anonymous sessions are not tracked
+	sess := NewVirtualStore(nil, "sid1", map[interface{}]interface{}{})
+	assert.True(t, trackSession(sess, req, 3600))
+	assert.Empty(t, TrackedKeyHash(sess))
+
+	sess = NewVirtualStore(nil, "sid1", map[interface{}]interface{}{"uid": int64(2)})
+	assert.True(t, trackSession(sess, req, 3600))
+	assert.Equal(t, models.HashSessionKey("sid1"), TrackedKeyHash(sess))
+	s := models.AssertExistsAndLoadBean(t, &models.UserSession{KeyHash: models.HashSessionKey("sid1")}).(*models.UserSession)
+	assert.EqualValues(t, 2, s.UID)
+	assert.Equal(t, "Laptop", s.UserAgent)
+	assert.Equal(t, "192.0.2.1", s.LastIP)
+
+	// a regenerated session ID keeps the session tracked
+	other := NewVirtualStore(nil, "sid2", map[interface{}]interface{}{"uid": int64(2), trackedKeyHashKey: TrackedKeyHash(sess)})
+	req.RemoteAddr = "192.0.2.2:1234"
+	assert.True(t, trackSession(other, req, 3600))
+	assert.Equal(t, models.HashSessionKey("sid2"), TrackedKeyHash(other))
+	s = models.AssertExistsAndLoadBean(t, &models.UserSession{ID: s.ID}).(*models.UserSession)
+	assert.Equal(t, models.HashSessionKey("sid2"), s.KeyHash)
+	assert.Equal(t, "192.0.2.2", s.LastIP)
+
+	// a revoked session is signed out on its next request, even if it has just been seen
+	assert.True(t, trackSession(other, req, 3600))
+	assert.NoError(t, models.DeleteUserSession(2, s.ID))
+	assert.False(t, trackSession(other, req, 3600))
+	assert.Nil(t, other.Get("uid"))
+}
@@ -26,6 +26,9 @@ var (
 		GCArgs                    []string `ini:"GC_ARGS" delim:" "`
 		EnableAutoGitWireProtocol bool
 		PullRequestPushMessage    bool
+		MaxAdvertisedRefs         int64
+		HidePullRefsOverLimit     bool
+		DisabledCapabilities      []string
 		Timeout                   struct {
 			Default int
 			Migrate int
@@ -34,6 +37,13 @@ var (
 			Pull    int
 			GC      int `ini:"GC"`
 		} `ini:"git.timeout"`
+		AutoGC struct {
+			Enabled      bool
+			LooseObjects int64
+			LooseSize    int64
+			PackFiles    int64
+			MinInterval  time.Duration
+		} `ini:"git.auto_gc"`
 	}{
 		DisableDiffHighlight:      false,
 		MaxGitDiffLines:           1000,
@@ -46,6 +56,9 @@ var (
 		GCArgs:                    []string{},
 		EnableAutoGitWireProtocol: true,
 		PullRequestPushMessage:    true,
+		MaxAdvertisedRefs:         0,
+		HidePullRefsOverLimit:     true,
+		DisabledCapabilities:      []string{},
 		Timeout: struct {
 			Default int
 			Migrate int
@@ -61,6 +74,19 @@ var (
 			Pull:    300,
 			GC:      60,
 		},
+		AutoGC: struct {
+			Enabled      bool
+			LooseObjects int64
+			LooseSize    int64
+			PackFiles    int64
+			MinInterval  time.Duration
+		}{
+			Enabled:      false,
+			LooseObjects: 6700,
+			LooseSize:    50 * 1024 * 1024,
+			PackFiles:    50,
+			MinInterval:  24 * time.Hour,
+		},
 	}
 )
 
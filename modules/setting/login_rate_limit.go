@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// LoginRateLimit settings for throttling sign-in, two-factor and account recovery attempts
+var LoginRateLimit = struct {
+	Enabled            bool
+	Window             time.Duration
+	MaxAttemptsPerIP   int `ini:"MAX_ATTEMPTS_PER_IP"`
+	MaxAttemptsPerUser int
+	Store              string
+}{
+	Enabled:            true,
+	Window:             5 * time.Minute,
+	MaxAttemptsPerIP:   50,
+	MaxAttemptsPerUser: 10,
+	Store:              "cache",
+}
+
+func newLoginRateLimitService() {
+	sec := Cfg.Section("security.login_rate_limit")
+	if err := sec.MapTo(&LoginRateLimit); err != nil {
+		log.Fatal("Failed to map security.login_rate_limit settings: %v", err)
+	}
+	LoginRateLimit.Store = sec.Key("STORE").In("cache", []string{"cache", "memory"})
+	if LoginRateLimit.Window < time.Second {
+		LoginRateLimit.Window = time.Second
+	}
+	if LoginRateLimit.Enabled && LoginRateLimit.Store == "cache" && !CacheService.Enabled {
+		log.Warn("Cache Service Disabled so that the login rate limit falls back to an in-memory store")
+		LoginRateLimit.Store = "memory"
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -29,6 +30,7 @@ var (
 		DefaultPrivate                          string
 		DefaultPushCreatePrivate                bool
 		MaxCreationLimit                        int
+		OrgMaxCreationLimit                     int
 		MirrorQueueLength                       int
 		PullRequestQueueLength                  int
 		PreferredLicenses                       []string
@@ -46,6 +48,11 @@ var (
 		DefaultBranch                           string
 		AllowAdoptionOfUnadoptedRepositories    bool
 		AllowDeleteOfUnadoptedRepositories      bool
+		GitTokenDefaultTTL                      time.Duration `ini:"-"`
+		GitTokenMaxTTL                          time.Duration `ini:"-"`
+		DeletedRetention                        time.Duration `ini:"-"`
+		EnablePartialClone                      bool
+		MaxAnnotationsPerCheck                  int
 
 		// Repository editor settings
 		Editor struct {
@@ -81,13 +88,20 @@ var (
 
 		// Issue Setting
 		Issue struct {
-			LockReasons []string
+			LockReasons        []string
+			MaxPinned          int
+			MaxCommentVersions int
 		} `ini:"repository.issue"`
 
 		Release struct {
 			AllowedTypes string
 		} `ini:"repository.release"`
 
+		// Wiki settings
+		Wiki struct {
+			PDFRenderCommand string `ini:"PDF_RENDER_COMMAND"`
+		} `ini:"repository.wiki"`
+
 		Signing struct {
 			SigningKey        string
 			SigningName       string
@@ -98,6 +112,12 @@ var (
 			Wiki              []string
 			DefaultTrustModel string
 		} `ini:"repository.signing"`
+
+		// Push policy settings
+		PushPolicy struct {
+			DefaultForbiddenPaths []string
+			SecretPatterns        map[string]string `ini:"-"`
+		} `ini:"repository.push-policy"`
 	}{
 		DetectedCharsetsOrder: []string{
 			"UTF-8",
@@ -140,6 +160,7 @@ var (
 		DefaultPrivate:                          RepoCreatingLastUserVisibility,
 		DefaultPushCreatePrivate:                true,
 		MaxCreationLimit:                        -1,
+		OrgMaxCreationLimit:                     -1,
 		MirrorQueueLength:                       1000,
 		PullRequestQueueLength:                  1000,
 		PreferredLicenses:                       []string{"Apache License 2.0", "MIT License"},
@@ -155,6 +176,8 @@ var (
 		DisableMirrors:                          false,
 		DisableMigrations:                       false,
 		DefaultBranch:                           "master",
+		EnablePartialClone:                      true,
+		MaxAnnotationsPerCheck:                  100,
 
 		// Repository editor settings
 		Editor: struct {
@@ -212,9 +235,13 @@ var (
 
 		// Issue settings
 		Issue: struct {
-			LockReasons []string
+			LockReasons        []string
+			MaxPinned          int
+			MaxCommentVersions int
 		}{
-			LockReasons: strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			LockReasons:        strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			MaxPinned:          3,
+			MaxCommentVersions: 20,
 		},
 
 		Release: struct {
@@ -223,6 +250,13 @@ var (
 			AllowedTypes: "",
 		},
 
+		// Wiki settings
+		Wiki: struct {
+			PDFRenderCommand string `ini:"PDF_RENDER_COMMAND"`
+		}{
+			PDFRenderCommand: "",
+		},
+
 		// Signing settings
 		Signing: struct {
 			SigningKey        string
@@ -243,6 +277,28 @@ var (
 			Wiki:              []string{"never"},
 			DefaultTrustModel: "collaborator",
 		},
+
+		// Push policy settings
+		PushPolicy: struct {
+			DefaultForbiddenPaths []string
+			SecretPatterns        map[string]string `ini:"-"`
+		}{
+			DefaultForbiddenPaths: []string{
+				".env", ".env.*", ".htpasswd", ".netrc", ".pgpass", "credentials.json",
+				"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+				"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore", "*.kdbx",
+			},
+			SecretPatterns: map[string]string{
+				"AWS_ACCESS_KEY_ID":     `\b(AKIA|ASIA)[0-9A-Z]{16}\b`,
+				"AWS_SECRET_ACCESS_KEY": `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}\b`,
+				"GITHUB_TOKEN":          `\bgh[pousr]_[A-Za-z0-9]{36}\b`,
+				"GITLAB_TOKEN":          `\bglpat-[A-Za-z0-9_\-]{20}\b`,
+				"SLACK_TOKEN":           `\bxox[abprs]-[A-Za-z0-9\-]{10,}`,
+				"GOOGLE_API_KEY":        `\bAIza[0-9A-Za-z_\-]{35}\b`,
+				"STRIPE_SECRET_KEY":     `\b[rs]k_live_[0-9A-Za-z]{24,}\b`,
+				"PRIVATE_KEY":           `-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`,
+			},
+		},
 	}
 	RepoRootPath string
 	ScriptType   = "bash"
@@ -255,7 +311,11 @@ func newRepository() {
 	Repository.DisableHTTPGit = sec.Key("DISABLE_HTTP_GIT").MustBool()
 	Repository.UseCompatSSHURI = sec.Key("USE_COMPAT_SSH_URI").MustBool()
 	Repository.MaxCreationLimit = sec.Key("MAX_CREATION_LIMIT").MustInt(-1)
+	Repository.OrgMaxCreationLimit = sec.Key("ORG_MAX_CREATION_LIMIT").MustInt(Repository.MaxCreationLimit)
 	Repository.DefaultBranch = sec.Key("DEFAULT_BRANCH").MustString(Repository.DefaultBranch)
+	Repository.GitTokenDefaultTTL = sec.Key("GIT_TOKEN_DEFAULT_TTL").MustDuration(5 * time.Minute)
+	Repository.GitTokenMaxTTL = sec.Key("GIT_TOKEN_MAX_TTL").MustDuration(time.Hour)
+	Repository.DeletedRetention = sec.Key("DELETED_RETENTION").MustDuration(0)
 	RepoRootPath = sec.Key("ROOT").MustString(path.Join(AppDataPath, "gitea-repositories"))
 	forcePathSeparator(RepoRootPath)
 	if !filepath.IsAbs(RepoRootPath) {
@@ -281,6 +341,11 @@ func newRepository() {
 		log.Fatal("Failed to map Repository.PullRequest settings: %v", err)
 	}
 
+	// Secret patterns can be overridden by name or disabled with an empty value
+	for _, key := range Cfg.Section("repository.push-policy.secret-patterns").Keys() {
+		Repository.PushPolicy.SecretPatterns[key.Name()] = key.Value()
+	}
+
 	// Handle default trustmodel settings
 	Repository.Signing.DefaultTrustModel = strings.ToLower(strings.TrimSpace(Repository.Signing.DefaultTrustModel))
 	if Repository.Signing.DefaultTrustModel == "default" {
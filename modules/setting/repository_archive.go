@@ -0,0 +1,29 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import "time"
+
+// RepoArchive settings for the cache of generated repository archives
+var RepoArchive = struct {
+	Storage
+	EnableCache  bool
+	CacheTTL     time.Duration
+	MaxCacheSize int64
+}{
+	EnableCache: true,
+	CacheTTL:    24 * time.Hour,
+}
+
+func newRepoArchiveService() {
+	sec := Cfg.Section("repo-archive")
+	storageType := sec.Key("STORAGE_TYPE").MustString("")
+
+	RepoArchive.Storage = getStorage("repo-archive", storageType, sec)
+
+	RepoArchive.EnableCache = sec.Key("ENABLE_CACHE").MustBool(true)
+	RepoArchive.CacheTTL = sec.Key("CACHE_TTL").MustDuration(24 * time.Hour)
+	RepoArchive.MaxCacheSize = sec.Key("MAX_CACHE_SIZE").MustInt64(0) * 1024 * 1024
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// SecurityTxt settings for /.well-known/security.txt (RFC 9116)
+var SecurityTxt = struct {
+	Enabled            bool
+	HasCustomFile      bool
+	Contact            []string
+	Expires            time.Time
+	Encryption         string
+	Policy             string
+	PreferredLanguages string
+}{}
+
+func newSecurityTxt() {
+	var err error
+	customFile := path.Join(CustomPath, "security.txt")
+	SecurityTxt.HasCustomFile, err = util.IsFile(customFile)
+	if err != nil {
+		log.Error("Unable to check if %s is a file. Error: %v", customFile, err)
+	}
+	if SecurityTxt.HasCustomFile {
+		SecurityTxt.Enabled = true
+		return
+	}
+
+	sec := Cfg.Section("security_txt")
+	SecurityTxt.Contact = SecurityTxt.Contact[:0]
+	for _, contact := range sec.Key("CONTACT").Strings(",") {
+		if contact = strings.TrimSpace(contact); contact != "" {
+			SecurityTxt.Contact = append(SecurityTxt.Contact, contact)
+		}
+	}
+	SecurityTxt.Encryption = sec.Key("ENCRYPTION").String()
+	SecurityTxt.Policy = sec.Key("POLICY").String()
+	SecurityTxt.PreferredLanguages = sec.Key("PREFERRED_LANGUAGES").String()
+	SecurityTxt.Enabled = false
+	if len(SecurityTxt.Contact) == 0 {
+		return
+	}
+
+	expires := sec.Key("EXPIRES").String()
+	if expires == "" {
+		log.Error("[security_txt] CONTACT is set but EXPIRES is missing, /.well-known/security.txt will not be served")
+		return
+	}
+	SecurityTxt.Expires, err = time.Parse(time.RFC3339, expires)
+	if err != nil {
+		log.Error("[security_txt] EXPIRES %q is not a RFC 3339 date, /.well-known/security.txt will not be served: %v", expires, err)
+		return
+	}
+	if SecurityTxt.Expires.Before(time.Now()) {
+		log.Warn("[security_txt] EXPIRES %s is in the past, security.txt should be updated", expires)
+	}
+	SecurityTxt.Enabled = true
+}
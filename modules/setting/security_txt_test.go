@@ -0,0 +1,43 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
+)
+
+func Test_newSecurityTxt(t *testing.T) {
+	oldCustomPath := CustomPath
+	defer func() { CustomPath = oldCustomPath }()
+	CustomPath = t.TempDir()
+
+	Cfg, _ = ini.Load([]byte(``))
+	newSecurityTxt()
+	assert.False(t, SecurityTxt.Enabled)
+
+	Cfg, _ = ini.Load([]byte(`
+[security_txt]
+CONTACT = mailto:security@example.com
+`))
+	newSecurityTxt()
+	assert.False(t, SecurityTxt.Enabled)
+
+	Cfg, _ = ini.Load([]byte(`
+[security_txt]
+CONTACT = mailto:security@example.com, https://example.com/security
+EXPIRES = 2030-01-02T03:04:05Z
+POLICY = https://example.com/policy
+`))
+	newSecurityTxt()
+	assert.True(t, SecurityTxt.Enabled)
+	assert.False(t, SecurityTxt.HasCustomFile)
+	assert.Equal(t, []string{"mailto:security@example.com", "https://example.com/security"}, SecurityTxt.Contact)
+	assert.Equal(t, time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC), SecurityTxt.Expires.UTC())
+	assert.Equal(t, "https://example.com/policy", SecurityTxt.Policy)
+}
@@ -16,6 +16,7 @@ import (
 var Service struct {
 	DefaultOrgVisibility                    string
 	DefaultOrgVisibilityMode                structs.VisibleType
+	ForceOrgVisibility                      bool
 	ActiveCodeLives                         int
 	ResetPwdCodeLives                       int
 	RegisterEmailConfirm                    bool
@@ -34,6 +35,9 @@ var Service struct {
 	EnableReverseProxyEmail                 bool
 	EnableCaptcha                           bool
 	RequireExternalRegistrationCaptcha      bool
+	RequireCaptchaForForgotPassword         bool
+	RequireCaptchaForSignInAfterFailures    int
+	RequireCaptchaForIssueCreation          bool
 	RequireExternalRegistrationPassword     bool
 	CaptchaType                             string
 	RecaptchaSecret                         string
@@ -41,6 +45,8 @@ var Service struct {
 	RecaptchaURL                            string
 	HcaptchaSecret                          string
 	HcaptchaSitekey                         string
+	TurnstileSecret                         string
+	TurnstileSitekey                        string
 	DefaultKeepEmailPrivate                 bool
 	DefaultAllowCreateOrganization          bool
 	EnableTimetracking                      bool
@@ -90,13 +96,18 @@ func newService() {
 	Service.EnableReverseProxyEmail = sec.Key("ENABLE_REVERSE_PROXY_EMAIL").MustBool()
 	Service.EnableCaptcha = sec.Key("ENABLE_CAPTCHA").MustBool(false)
 	Service.RequireExternalRegistrationCaptcha = sec.Key("REQUIRE_EXTERNAL_REGISTRATION_CAPTCHA").MustBool(Service.EnableCaptcha)
+	Service.RequireCaptchaForForgotPassword = sec.Key("REQUIRE_CAPTCHA_FOR_FORGOT_PASSWORD").MustBool()
+	Service.RequireCaptchaForSignInAfterFailures = sec.Key("REQUIRE_CAPTCHA_FOR_SIGNIN_AFTER_FAILURES").MustInt(0)
+	Service.RequireCaptchaForIssueCreation = sec.Key("REQUIRE_CAPTCHA_FOR_ISSUE_CREATION").MustBool()
 	Service.RequireExternalRegistrationPassword = sec.Key("REQUIRE_EXTERNAL_REGISTRATION_PASSWORD").MustBool()
-	Service.CaptchaType = sec.Key("CAPTCHA_TYPE").MustString(ImageCaptcha)
+	Service.CaptchaType = sec.Key("CAPTCHA_TYPE").In(ImageCaptcha, []string{ImageCaptcha, ReCaptcha, HCaptcha, Turnstile})
 	Service.RecaptchaSecret = sec.Key("RECAPTCHA_SECRET").MustString("")
 	Service.RecaptchaSitekey = sec.Key("RECAPTCHA_SITEKEY").MustString("")
 	Service.RecaptchaURL = sec.Key("RECAPTCHA_URL").MustString("https://www.google.com/recaptcha/")
 	Service.HcaptchaSecret = sec.Key("HCAPTCHA_SECRET").MustString("")
 	Service.HcaptchaSitekey = sec.Key("HCAPTCHA_SITEKEY").MustString("")
+	Service.TurnstileSecret = sec.Key("TURNSTILE_SECRET").MustString("")
+	Service.TurnstileSitekey = sec.Key("TURNSTILE_SITEKEY").MustString("")
 	Service.DefaultKeepEmailPrivate = sec.Key("DEFAULT_KEEP_EMAIL_PRIVATE").MustBool()
 	Service.DefaultAllowCreateOrganization = sec.Key("DEFAULT_ALLOW_CREATE_ORGANIZATION").MustBool(true)
 	Service.EnableTimetracking = sec.Key("ENABLE_TIMETRACKING").MustBool(true)
@@ -112,6 +123,7 @@ func newService() {
 	Service.AutoWatchOnChanges = sec.Key("AUTO_WATCH_ON_CHANGES").MustBool(false)
 	Service.DefaultOrgVisibility = sec.Key("DEFAULT_ORG_VISIBILITY").In("public", structs.ExtractKeysFromMapString(structs.VisibilityModes))
 	Service.DefaultOrgVisibilityMode = structs.VisibilityModes[Service.DefaultOrgVisibility]
+	Service.ForceOrgVisibility = sec.Key("FORCE_ORG_VISIBILITY").MustBool()
 	Service.DefaultOrgMemberVisible = sec.Key("DEFAULT_ORG_MEMBER_VISIBLE").MustBool()
 	Service.UserDeleteWithCommentsMaxTime = sec.Key("USER_DELETE_WITH_COMMENTS_MAX_TIME").MustDuration(0)
 
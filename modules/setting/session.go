@@ -28,6 +28,8 @@ var (
 		Gclifetime int64
 		// Max life time in seconds. Default is whatever GC interval time is.
 		Maxlifetime int64
+		// Idle timeout in seconds. Default is 0, which disables it.
+		IdleTimeout int64
 		// Use HTTPS only. Default is false.
 		Secure bool
 		// Cookie domain name. Default is empty.
@@ -55,6 +57,13 @@ func newSessionService() {
 	SessionConfig.Secure = sec.Key("COOKIE_SECURE").MustBool(false)
 	SessionConfig.Gclifetime = sec.Key("GC_INTERVAL_TIME").MustInt64(86400)
 	SessionConfig.Maxlifetime = sec.Key("SESSION_LIFE_TIME").MustInt64(86400)
+	SessionConfig.IdleTimeout = sec.Key("SESSION_IDLE_TIMEOUT").MustInt64(0)
+	if SessionConfig.IdleTimeout < 0 {
+		SessionConfig.IdleTimeout = 0
+	} else if SessionConfig.IdleTimeout > SessionConfig.Maxlifetime {
+		log.Warn("SESSION_IDLE_TIMEOUT (%d) is longer than SESSION_LIFE_TIME (%d), using the latter", SessionConfig.IdleTimeout, SessionConfig.Maxlifetime)
+		SessionConfig.IdleTimeout = SessionConfig.Maxlifetime
+	}
 	SessionConfig.Domain = sec.Key("DOMAIN").String()
 	samesiteString := sec.Key("SAME_SITE").In("lax", []string{"none", "lax", "strict"})
 	switch strings.ToLower(samesiteString) {
@@ -18,6 +18,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -55,13 +56,43 @@ const (
 	LandingPageExplore       LandingPage = "/explore"
 	LandingPageOrganizations LandingPage = "/explore/organizations"
 	LandingPageLogin         LandingPage = "/user/login"
+	// LandingPageLastRepo sends users to the repository they visited last, it is only valid after signing in
+	LandingPageLastRepo LandingPage = "last_repo"
 )
 
+// parseLandingPage parses a landing page setting, which is either one of the predefined
+// pages or a custom path on this instance like "/org/-/projects"
+func parseLandingPage(key, value string, signedIn bool) LandingPage {
+	switch value {
+	case "explore":
+		return LandingPageExplore
+	case "organizations":
+		return LandingPageOrganizations
+	case "login":
+		if !signedIn {
+			return LandingPageLogin
+		}
+	case "last_repo":
+		if signedIn {
+			return LandingPageLastRepo
+		}
+	case "home", "dashboard":
+		return LandingPageHome
+	default:
+		if util.IsLocalPath(value) {
+			return LandingPage(value)
+		}
+	}
+	log.Error("Invalid %s %q, the home page is used instead", key, value)
+	return LandingPageHome
+}
+
 // enumerates all the types of captchas
 const (
 	ImageCaptcha = "image"
 	ReCaptcha    = "recaptcha"
 	HCaptcha     = "hcaptcha"
+	Turnstile    = "turnstile"
 )
 
 // settings
@@ -106,7 +137,10 @@ var (
 	StaticRootPath       string
 	StaticCacheTime      time.Duration
 	EnableGzip           bool
+	EnableEarlyHints     bool
+	EnableFingerprints   bool
 	LandingPageURL       LandingPage
+	PostLoginRedirectURL LandingPage
 	UnixSocketPermission uint32
 	EnablePprof          bool
 	PprofDataPath        string
@@ -179,6 +213,8 @@ var (
 	PasswordComplexity                 []string
 	PasswordHashAlgo                   string
 	PasswordCheckPwn                   bool
+	LoginLockoutThreshold              int
+	LoginLockoutDuration               time.Duration
 
 	// UI settings
 	UI = struct {
@@ -289,11 +325,13 @@ var (
 	Markdown = struct {
 		EnableHardLineBreakInComments  bool
 		EnableHardLineBreakInDocuments bool
+		EnableReferenceLinksInWiki     bool
 		CustomURLSchemes               []string `ini:"CUSTOM_URL_SCHEMES"`
 		FileExtensions                 []string
 	}{
 		EnableHardLineBreakInComments:  true,
 		EnableHardLineBreakInDocuments: false,
+		EnableReferenceLinksInWiki:     true,
 		FileExtensions:                 strings.Split(".md,.markdown,.mdown,.mkd", ","),
 	}
 
@@ -326,8 +364,9 @@ var (
 
 	// Mirror settings
 	Mirror struct {
-		DefaultInterval time.Duration
-		MinInterval     time.Duration
+		DefaultInterval   time.Duration
+		MinInterval       time.Duration
+		CredentialHelpers map[string]string
 	}
 
 	// API settings
@@ -359,7 +398,7 @@ var (
 		Enable:                     true,
 		AccessTokenExpirationTime:  3600,
 		RefreshTokenExpirationTime: 730,
-		InvalidateRefreshTokens:    false,
+		InvalidateRefreshTokens:    true,
 		MaxTokenLength:             math.MaxInt16,
 	}
 
@@ -370,11 +409,21 @@ var (
 
 	// Metrics settings
 	Metrics = struct {
-		Enabled bool
-		Token   string
+		Enabled                 bool
+		Token                   string
+		EnableRepositoryMetrics bool
+		RepositoryAllowlist     []string
+		MaxRepositories         int
+		EnableRequestMetrics    bool
+		RequestDurationBuckets  []float64
 	}{
-		Enabled: false,
-		Token:   "",
+		Enabled:                 false,
+		Token:                   "",
+		EnableRepositoryMetrics: false,
+		RepositoryAllowlist:     []string{},
+		MaxRepositories:         100,
+		EnableRequestMetrics:    false,
+		RequestDurationBuckets:  []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
 	}
 
 	// I18n settings
@@ -665,22 +714,16 @@ func NewContext() {
 	StaticCacheTime = sec.Key("STATIC_CACHE_TIME").MustDuration(6 * time.Hour)
 	AppDataPath = sec.Key("APP_DATA_PATH").MustString(path.Join(AppWorkPath, "data"))
 	EnableGzip = sec.Key("ENABLE_GZIP").MustBool()
+	EnableEarlyHints = sec.Key("ENABLE_EARLY_HINTS").MustBool(false)
+	EnableFingerprints = sec.Key("ENABLE_ASSET_FINGERPRINTS").MustBool(true)
 	EnablePprof = sec.Key("ENABLE_PPROF").MustBool(false)
 	PprofDataPath = sec.Key("PPROF_DATA_PATH").MustString(path.Join(AppWorkPath, "data/tmp/pprof"))
 	if !filepath.IsAbs(PprofDataPath) {
 		PprofDataPath = filepath.Join(AppWorkPath, PprofDataPath)
 	}
 
-	switch sec.Key("LANDING_PAGE").MustString("home") {
-	case "explore":
-		LandingPageURL = LandingPageExplore
-	case "organizations":
-		LandingPageURL = LandingPageOrganizations
-	case "login":
-		LandingPageURL = LandingPageLogin
-	default:
-		LandingPageURL = LandingPageHome
-	}
+	LandingPageURL = parseLandingPage("LANDING_PAGE", sec.Key("LANDING_PAGE").MustString("home"), false)
+	PostLoginRedirectURL = parseLandingPage("POST_LOGIN_REDIRECT", sec.Key("POST_LOGIN_REDIRECT").MustString("dashboard"), true)
 
 	if len(SSH.Domain) == 0 {
 		SSH.Domain = Domain
@@ -839,6 +882,8 @@ func NewContext() {
 	PasswordHashAlgo = sec.Key("PASSWORD_HASH_ALGO").MustString("pbkdf2")
 	CSRFCookieHTTPOnly = sec.Key("CSRF_COOKIE_HTTP_ONLY").MustBool(true)
 	PasswordCheckPwn = sec.Key("PASSWORD_CHECK_PWN").MustBool(false)
+	LoginLockoutThreshold = sec.Key("LOGIN_LOCKOUT_THRESHOLD").MustInt(10)
+	LoginLockoutDuration = sec.Key("LOGIN_LOCKOUT_DURATION").MustDuration(15 * time.Minute)
 
 	InternalToken = loadInternalToken(sec)
 
@@ -856,6 +901,7 @@ func NewContext() {
 
 	newAttachmentService()
 	newLFSService()
+	newRepoArchiveService()
 
 	timeFormatKey := Cfg.Section("time").Key("FORMAT").MustString("")
 	if timeFormatKey != "" {
@@ -928,6 +974,18 @@ func NewContext() {
 	} else if err = Cfg.Section("metrics").MapTo(&Metrics); err != nil {
 		log.Fatal("Failed to map Metrics settings: %v", err)
 	}
+	if Metrics.Token, err = loadMetricsToken(Cfg.Section("metrics")); err != nil {
+		log.Fatal("Failed to load Metrics token: %v", err)
+	}
+	// histogram buckets have to be increasing
+	sort.Float64s(Metrics.RequestDurationBuckets)
+	buckets := Metrics.RequestDurationBuckets[:0]
+	for i, bucket := range Metrics.RequestDurationBuckets {
+		if i == 0 || bucket != Metrics.RequestDurationBuckets[i-1] {
+			buckets = append(buckets, bucket)
+		}
+	}
+	Metrics.RequestDurationBuckets = buckets
 
 	u := *appURL
 	u.Path = path.Join(u.Path, "api", "swagger")
@@ -946,6 +1004,10 @@ func NewContext() {
 		log.Warn("Mirror.DefaultInterval is less than Mirror.MinInterval")
 		Mirror.DefaultInterval = time.Hour * 8
 	}
+	Mirror.CredentialHelpers = make(map[string]string)
+	for _, key := range Cfg.Section("mirror.credential-helpers").Keys() {
+		Mirror.CredentialHelpers[key.Name()] = key.Value()
+	}
 
 	Langs = Cfg.Section("i18n").Key("LANGS").Strings(",")
 	if len(Langs) == 0 {
@@ -976,6 +1038,8 @@ func NewContext() {
 		log.Error("Unable to check if %s is a file. Error: %v", path.Join(CustomPath, "robots.txt"), err)
 	}
 
+	newSecurityTxt()
+
 	newMarkup()
 
 	sec = Cfg.Section("U2F")
@@ -1060,6 +1124,32 @@ func loadInternalToken(sec *ini.Section) string {
 	return ""
 }
 
+// loadMetricsToken returns the bearer token required to access the metrics, which is read from
+// the file of TOKEN_URI if it is set, or an empty string if no token is required
+func loadMetricsToken(sec *ini.Section) (string, error) {
+	uri := sec.Key("TOKEN_URI").String()
+	if len(uri) == 0 {
+		return sec.Key("TOKEN").String(), nil
+	}
+	tempURI, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse TOKEN_URI (%s): %v", uri, err)
+	}
+	if tempURI.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI-Scheme %q (TOKEN_URI = %q)", tempURI.Scheme, uri)
+	}
+	buf, err := ioutil.ReadFile(tempURI.RequestURI())
+	if err != nil {
+		return "", fmt.Errorf("failed to read TOKEN_URI (%s): %v", uri, err)
+	}
+	token := strings.TrimSpace(string(buf))
+	// An empty file must not disable the authorization the administrator asked for
+	if len(token) == 0 {
+		return "", fmt.Errorf("no token in TOKEN_URI (%s)", uri)
+	}
+	return token, nil
+}
+
 func loadOrGenerateInternalToken(sec *ini.Section) string {
 	var err error
 	token := sec.Key("INTERNAL_TOKEN").String()
@@ -1161,6 +1251,7 @@ func NewServices() {
 	newService()
 	NewLogServices(false)
 	newCacheService()
+	newLoginRateLimitService()
 	newSessionService()
 	newCORSService()
 	newMailService()
@@ -1172,4 +1263,5 @@ func NewServices() {
 	newTaskService()
 	NewQueueService()
 	newProject()
+	newFederationService()
 }
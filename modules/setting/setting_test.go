@@ -5,10 +5,13 @@
 package setting
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
 )
 
 func TestMakeAbsoluteAssetURL(t *testing.T) {
@@ -31,3 +34,45 @@ func TestMakeManifestData(t *testing.T) {
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	assert.True(t, json.Valid(jsonBytes))
 }
+
+func TestParseLandingPage(t *testing.T) {
+	assert.Equal(t, LandingPageExplore, parseLandingPage("LANDING_PAGE", "explore", false))
+	assert.Equal(t, LandingPageLogin, parseLandingPage("LANDING_PAGE", "login", false))
+	assert.Equal(t, LandingPage("/org/-/projects"), parseLandingPage("LANDING_PAGE", "/org/-/projects", false))
+	assert.Equal(t, LandingPageHome, parseLandingPage("LANDING_PAGE", "last_repo", false))
+	assert.Equal(t, LandingPageHome, parseLandingPage("LANDING_PAGE", "//example.com", false))
+	assert.Equal(t, LandingPageHome, parseLandingPage("LANDING_PAGE", "https://example.com", false))
+
+	assert.Equal(t, LandingPageHome, parseLandingPage("POST_LOGIN_REDIRECT", "dashboard", true))
+	assert.Equal(t, LandingPageLastRepo, parseLandingPage("POST_LOGIN_REDIRECT", "last_repo", true))
+	assert.Equal(t, LandingPageHome, parseLandingPage("POST_LOGIN_REDIRECT", "login", true))
+}
+
+func TestLoadMetricsToken(t *testing.T) {
+	load := func(config string) (string, error) {
+		cfg, err := ini.Load([]byte(config))
+		assert.NoError(t, err)
+		return loadMetricsToken(cfg.Section("metrics"))
+	}
+
+	token, err := load("[metrics]\n")
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	token, err = load("[metrics]\nTOKEN = inline\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "inline", token)
+
+	path := filepath.Join(t.TempDir(), "metrics_token")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("from-file\n"), 0600))
+	token, err = load("[metrics]\nTOKEN = inline\nTOKEN_URI = file://" + filepath.ToSlash(path) + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", token)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("  \n"), 0600))
+	_, err = load("[metrics]\nTOKEN_URI = file://" + filepath.ToSlash(path) + "\n")
+	assert.Error(t, err)
+
+	_, err = load("[metrics]\nTOKEN_URI = https://example.com/token\n")
+	assert.Error(t, err)
+}
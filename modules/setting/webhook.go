@@ -6,6 +6,7 @@ package setting
 
 import (
 	"net/url"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -21,6 +22,11 @@ var (
 		ProxyURL       string
 		ProxyURLFixed  *url.URL
 		ProxyHosts     []string
+		MaxAttempts    int
+		RetryBackoff   time.Duration
+		MaxBackoff     time.Duration
+		AutoDisable    bool
+		StatusDebounce time.Duration
 	}{
 		QueueLength:    1000,
 		DeliverTimeout: 5,
@@ -28,6 +34,11 @@ var (
 		PagingNum:      10,
 		ProxyURL:       "",
 		ProxyHosts:     []string{},
+		MaxAttempts:    1,
+		RetryBackoff:   10 * time.Second,
+		MaxBackoff:     time.Hour,
+		AutoDisable:    false,
+		StatusDebounce: 0,
 	}
 )
 
@@ -36,7 +47,7 @@ func newWebhookService() {
 	Webhook.QueueLength = sec.Key("QUEUE_LENGTH").MustInt(1000)
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
-	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix"}
+	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "mattermost", "googlechat", "json"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
 	Webhook.ProxyURL = sec.Key("PROXY_URL").MustString("")
 	if Webhook.ProxyURL != "" {
@@ -48,4 +59,15 @@ func newWebhookService() {
 		}
 	}
 	Webhook.ProxyHosts = sec.Key("PROXY_HOSTS").Strings(",")
+	Webhook.MaxAttempts = sec.Key("MAX_ATTEMPTS").MustInt(1)
+	if Webhook.MaxAttempts < 1 {
+		Webhook.MaxAttempts = 1
+	}
+	Webhook.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(10 * time.Second)
+	Webhook.MaxBackoff = sec.Key("MAX_RETRY_BACKOFF").MustDuration(time.Hour)
+	if Webhook.MaxBackoff < Webhook.RetryBackoff {
+		Webhook.MaxBackoff = Webhook.RetryBackoff
+	}
+	Webhook.AutoDisable = sec.Key("AUTO_DISABLE").MustBool(false)
+	Webhook.StatusDebounce = sec.Key("STATUS_DEBOUNCE").MustDuration(0)
 }
@@ -0,0 +1,37 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// ReindexOption options for reindexing an indexer
+type ReindexOption struct {
+	// full name of the repository to reindex, all the repositories are reindexed if empty
+	Repo string `json:"repo"`
+}
+
+// Reindex represents a reindex of an indexer
+type Reindex struct {
+	ID int64 `json:"id"`
+	// enum: code,issues
+	Indexer string `json:"indexer"`
+	// full name of the reindexed repository, empty if all the repositories are reindexed
+	Repo string `json:"repo"`
+	// enum: queued,running,stopped,failed,finished
+	Status string `json:"status"`
+	// number of repositories to reindex
+	Total int64 `json:"total"`
+	// number of repositories reindexed
+	Done int64 `json:"done"`
+	// number of repositories which failed to be reindexed
+	Failed int64  `json:"failed"`
+	Errors string `json:"errors"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Finished *time.Time `json:"finished_at"`
+}
@@ -0,0 +1,13 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RepoMaintenanceOption options for running a maintenance operation on repositories
+type RepoMaintenanceOption struct {
+	// only report the repositories the operation would run on, with the size of their pack files
+	DryRun bool `json:"dry_run"`
+	// skip repositories whose pack files are smaller than this number of bytes
+	MinPackSize int64 `json:"min_pack_size"`
+}
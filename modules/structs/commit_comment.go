@@ -0,0 +1,25 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// CommitComment represents a comment on a commit, independent of any pull request
+type CommitComment struct {
+	ID       int64  `json:"id"`
+	HTMLURL  string `json:"html_url"`
+	CommitID string `json:"commit_id"`
+	// Path and Line are empty for a comment on the whole commit, a negative line refers to the previous version of the file
+	Path   string `json:"path"`
+	Line   int64  `json:"line"`
+	Body   string `json:"body"`
+	Poster *User  `json:"user"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
@@ -61,3 +61,25 @@ func (css CommitStatusState) IsFailure() bool {
 func (css CommitStatusState) IsWarning() bool {
 	return css == CommitStatusWarning
 }
+
+// CommitStatusAnnotationLevel holds the level of a CommitStatusAnnotation
+// It can be "notice", "warning" and "failure"
+type CommitStatusAnnotationLevel string
+
+const (
+	// CommitStatusAnnotationNotice is for annotations which are informational only
+	CommitStatusAnnotationNotice CommitStatusAnnotationLevel = "notice"
+	// CommitStatusAnnotationWarning is for annotations about possible problems
+	CommitStatusAnnotationWarning CommitStatusAnnotationLevel = "warning"
+	// CommitStatusAnnotationFailure is for annotations about problems which fail the check
+	CommitStatusAnnotationFailure CommitStatusAnnotationLevel = "failure"
+)
+
+// IsValid returns true if the level is one of the known levels
+func (level CommitStatusAnnotationLevel) IsValid() bool {
+	switch level {
+	case CommitStatusAnnotationNotice, CommitStatusAnnotationWarning, CommitStatusAnnotationFailure:
+		return true
+	}
+	return false
+}
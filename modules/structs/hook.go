@@ -42,12 +42,12 @@ type CreateHookOptionConfig map[string]string
 // CreateHookOption options when create a hook
 type CreateHookOption struct {
 	// required: true
-	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu
+	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,mattermost,googlechat,json
 	Type string `json:"type" binding:"Required"`
 	// required: true
 	Config       CreateHookOptionConfig `json:"config" binding:"Required"`
 	Events       []string               `json:"events"`
-	BranchFilter string                 `json:"branch_filter" binding:"GlobPattern"`
+	BranchFilter string                 `json:"branch_filter" binding:"BranchFilter"`
 	// default: false
 	Active bool `json:"active"`
 }
@@ -56,7 +56,7 @@ type CreateHookOption struct {
 type EditHookOption struct {
 	Config       map[string]string `json:"config"`
 	Events       []string          `json:"events"`
-	BranchFilter string            `json:"branch_filter" binding:"GlobPattern"`
+	BranchFilter string            `json:"branch_filter" binding:"BranchFilter"`
 	Active       *bool             `json:"active"`
 }
 
@@ -113,6 +113,10 @@ var (
 	_ Payloader = &PullRequestPayload{}
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
+	_ Payloader = &CommitStatusPayload{}
+	_ Payloader = &CommitCommentPayload{}
+	_ Payloader = &TeamPayload{}
+	_ Payloader = &MembershipPayload{}
 )
 
 // _________                        __
@@ -385,17 +389,24 @@ const (
 	HookIssueDemilestoned HookIssueAction = "demilestoned"
 	// HookIssueReviewed is an issue action for when a pull request is reviewed
 	HookIssueReviewed HookIssueAction = "reviewed"
+	// HookIssueDependencyAdded is an issue action for when an issue starts blocking another one
+	HookIssueDependencyAdded HookIssueAction = "dependency_added"
+	// HookIssueDependencyRemoved is an issue action for when an issue stops blocking another one
+	HookIssueDependencyRemoved HookIssueAction = "dependency_removed"
+	// HookIssueMergeableChanged is an issue action for when the mergeable state of a pull request changes
+	HookIssueMergeableChanged HookIssueAction = "mergeable_changed"
 )
 
 // IssuePayload represents the payload information that is sent along with an issue event.
 type IssuePayload struct {
-	Secret     string          `json:"secret"`
-	Action     HookIssueAction `json:"action"`
-	Index      int64           `json:"number"`
-	Changes    *ChangesPayload `json:"changes,omitempty"`
-	Issue      *Issue          `json:"issue"`
-	Repository *Repository     `json:"repository"`
-	Sender     *User           `json:"sender"`
+	Secret     string                  `json:"secret"`
+	Action     HookIssueAction         `json:"action"`
+	Index      int64                   `json:"number"`
+	Changes    *ChangesPayload         `json:"changes,omitempty"`
+	Issue      *Issue                  `json:"issue"`
+	Repository *Repository             `json:"repository"`
+	Sender     *User                   `json:"sender"`
+	Dependency *IssueDependencyPayload `json:"dependency,omitempty"`
 }
 
 // SetSecret modifies the secret of the IssuePayload.
@@ -419,6 +430,18 @@ type ChangesPayload struct {
 	Title *ChangesFromPayload `json:"title,omitempty"`
 	Body  *ChangesFromPayload `json:"body,omitempty"`
 	Ref   *ChangesFromPayload `json:"ref,omitempty"`
+	// DueDate is the previous due date as YYYY-MM-DD, empty if there was none
+	DueDate *ChangesFromPayload `json:"due_date,omitempty"`
+	// MergeableState is the previous mergeable state of a pull request
+	MergeableState *ChangesFromPayload `json:"mergeable_state,omitempty"`
+}
+
+// IssueDependencyPayload represents the dependency added or removed by a dependency action,
+// the issues can be in different repositories
+type IssueDependencyPayload struct {
+	// Blocked is the issue which cannot be closed while Blocking is open
+	Blocked  *Issue `json:"blocked"`
+	Blocking *Issue `json:"blocking"`
 }
 
 // __________      .__  .__    __________                                     __
@@ -430,14 +453,15 @@ type ChangesPayload struct {
 
 // PullRequestPayload represents a payload information of pull request event.
 type PullRequestPayload struct {
-	Secret      string          `json:"secret"`
-	Action      HookIssueAction `json:"action"`
-	Index       int64           `json:"number"`
-	Changes     *ChangesPayload `json:"changes,omitempty"`
-	PullRequest *PullRequest    `json:"pull_request"`
-	Repository  *Repository     `json:"repository"`
-	Sender      *User           `json:"sender"`
-	Review      *ReviewPayload  `json:"review"`
+	Secret      string                  `json:"secret"`
+	Action      HookIssueAction         `json:"action"`
+	Index       int64                   `json:"number"`
+	Changes     *ChangesPayload         `json:"changes,omitempty"`
+	PullRequest *PullRequest            `json:"pull_request"`
+	Repository  *Repository             `json:"repository"`
+	Sender      *User                   `json:"sender"`
+	Review      *ReviewPayload          `json:"review"`
+	Dependency  *IssueDependencyPayload `json:"dependency,omitempty"`
 }
 
 // SetSecret modifies the secret of the PullRequestPayload.
@@ -493,3 +517,129 @@ func (p *RepositoryPayload) JSONPayload() ([]byte, error) {
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
 	return json.MarshalIndent(p, "", " ")
 }
+
+// CommitStatusPayload represents a payload information of commit status event.
+type CommitStatusPayload struct {
+	Secret string `json:"secret"`
+	SHA    string `json:"sha"`
+	// Status is the latest status which has been created
+	Status *CommitStatus `json:"status"`
+	// CombinedStatus is the combined state of the latest status of each context of the commit
+	CombinedStatus *CombinedStatus `json:"combined_status"`
+	Repository     *Repository     `json:"repository"`
+	Sender         *User           `json:"sender"`
+}
+
+// SetSecret modifies the secret of the CommitStatusPayload
+func (p *CommitStatusPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *CommitStatusPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookCommitCommentAction defines hook commit comment action
+type HookCommitCommentAction string
+
+// all commit comment actions
+const (
+	HookCommitCommentCreated HookCommitCommentAction = "created"
+	HookCommitCommentDeleted HookCommitCommentAction = "deleted"
+)
+
+// CommitCommentPayload represents a payload information of commit comment event.
+type CommitCommentPayload struct {
+	Secret     string                  `json:"secret"`
+	Action     HookCommitCommentAction `json:"action"`
+	Comment    *CommitComment          `json:"comment"`
+	Repository *Repository             `json:"repository"`
+	Sender     *User                   `json:"sender"`
+}
+
+// SetSecret modifies the secret of the CommitCommentPayload
+func (p *CommitCommentPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *CommitCommentPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookTeamAction an action that happens to a team
+type HookTeamAction string
+
+const (
+	// HookTeamCreated created
+	HookTeamCreated HookTeamAction = "created"
+	// HookTeamEdited edited
+	HookTeamEdited HookTeamAction = "edited"
+	// HookTeamDeleted deleted
+	HookTeamDeleted HookTeamAction = "deleted"
+)
+
+// TeamPayload represents a payload information of team event.
+type TeamPayload struct {
+	Secret       string         `json:"secret"`
+	Action       HookTeamAction `json:"action"`
+	Team         *Team          `json:"team"`
+	Organization *Organization  `json:"organization"`
+	Sender       *User          `json:"sender"`
+}
+
+// SetSecret modifies the secret of the TeamPayload
+func (p *TeamPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *TeamPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookMembershipAction an action that happens to the membership of a user
+type HookMembershipAction string
+
+const (
+	// HookMembershipAdded added
+	HookMembershipAdded HookMembershipAction = "added"
+	// HookMembershipRemoved removed
+	HookMembershipRemoved HookMembershipAction = "removed"
+)
+
+// The scopes of a membership event
+const (
+	// HookMembershipScopeTeam the membership of a team changed
+	HookMembershipScopeTeam = "team"
+	// HookMembershipScopeOrganization the membership of the organization changed
+	HookMembershipScopeOrganization = "organization"
+)
+
+// MembershipPayload represents a payload information of membership event.
+type MembershipPayload struct {
+	Secret string               `json:"secret"`
+	Action HookMembershipAction `json:"action"`
+	// Scope is "team" when a member was added to or removed from a team and "organization" when
+	// a member was removed from the organization
+	Scope        string        `json:"scope"`
+	Member       *User         `json:"member"`
+	Team         *Team         `json:"team,omitempty"`
+	Organization *Organization `json:"organization"`
+	Sender       *User         `json:"sender"`
+}
+
+// SetSecret modifies the secret of the MembershipPayload
+func (p *MembershipPayload) SetSecret(secret string) {
+	p.Secret = secret
+}
+
+// JSONPayload JSON representation of the payload
+func (p *MembershipPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return json.MarshalIndent(p, "", "  ")
+}
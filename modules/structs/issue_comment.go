@@ -35,3 +35,43 @@ type EditIssueCommentOption struct {
 	// required: true
 	Body string `json:"body" binding:"Required"`
 }
+
+// TimelineComment represents a timeline comment (comment of any type) on a commit or issue
+type TimelineComment struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+
+	HTMLURL  string `json:"html_url"`
+	PRURL    string `json:"pull_request_url"`
+	IssueURL string `json:"issue_url"`
+	Poster   *User  `json:"user"`
+	Body     string `json:"body"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+
+	OldMilestone *Milestone `json:"old_milestone"`
+	Milestone    *Milestone `json:"milestone"`
+	OldTitle     string     `json:"old_title"`
+	NewTitle     string     `json:"new_title"`
+	OldRef       string     `json:"old_ref"`
+	NewRef       string     `json:"new_ref"`
+
+	RefIssue     *Issue   `json:"ref_issue"`
+	RefComment   *Comment `json:"ref_comment"`
+	RefAction    string   `json:"ref_action"`
+	RefCommitSHA string   `json:"ref_commit_sha"`
+
+	ReviewID int64 `json:"review_id"`
+
+	Label *Label `json:"label"`
+	// LabelRemoved is true if the label has been removed from the issue
+	LabelRemoved bool `json:"label_removed"`
+
+	Assignee        *User `json:"assignee"`
+	AssigneeTeam    *Team `json:"assignee_team"`
+	RemovedAssignee bool  `json:"removed_assignee"`
+
+	DependentIssue *Issue `json:"dependent_issue"`
+}
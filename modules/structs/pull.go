@@ -29,7 +29,10 @@ type PullRequest struct {
 	PatchURL string `json:"patch_url"`
 
 	Mergeable bool `json:"mergeable"`
-	HasMerged bool `json:"merged"`
+	// MergeableState is the result of the last check of the pull request against its base branch
+	// enum: checking,mergeable,conflict,empty,manually_merged,error
+	MergeableState string `json:"mergeable_state"`
+	HasMerged      bool   `json:"merged"`
 	// swagger:strfmt date-time
 	Merged         *time.Time `json:"merged_at"`
 	MergedCommitID *string    `json:"merge_commit_sha"`
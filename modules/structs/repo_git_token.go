@@ -0,0 +1,30 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// GitToken a one-time git credential of a repository
+type GitToken struct {
+	// The token, to be used as username or password for a single clone, fetch or push over HTTP
+	Token string `json:"token"`
+	// enum: read,write
+	Mode string `json:"mode"`
+	// swagger:strfmt date-time
+	Expires time.Time `json:"expires_at"`
+}
+
+// CreateGitTokenOption options when creating a one-time git token
+type CreateGitTokenOption struct {
+	// Access granted by the token, "read" to clone or fetch and "write" to push
+	//
+	// required: true
+	// enum: read,write
+	Mode string `json:"mode" binding:"Required;In(read,write)"`
+	// Lifetime of the token in seconds, defaults to the configured default lifetime
+	TTL int64 `json:"ttl"`
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// LifecycleMetrics represents the average times from the creation of issues and pull requests
+// to their first response, closing and merging, the averages are in seconds
+type LifecycleMetrics struct {
+	Issues int64 `json:"issues"`
+	Pulls  int64 `json:"pulls"`
+	// number of issues and pull requests which got a response of another user than the poster
+	Responded                int64 `json:"responded"`
+	AverageFirstResponseTime int64 `json:"average_first_response_time"`
+	Closed                   int64 `json:"closed"`
+	AverageCloseTime         int64 `json:"average_close_time"`
+	Merged                   int64 `json:"merged"`
+	AverageMergeTime         int64 `json:"average_merge_time"`
+}
+
+// LabelLifecycleMetrics represents the lifecycle metrics of the issues and pull requests with a label
+type LabelLifecycleMetrics struct {
+	Label   *Label            `json:"label"`
+	Metrics *LifecycleMetrics `json:"metrics"`
+}
+
+// RepoLifecycleMetrics represents the lifecycle metrics of the issues and pull requests
+// of a repository created in a time range
+type RepoLifecycleMetrics struct {
+	// swagger:strfmt date-time
+	Since *time.Time `json:"since,omitempty"`
+	// swagger:strfmt date-time
+	Before *time.Time               `json:"before,omitempty"`
+	Total  *LifecycleMetrics        `json:"total"`
+	Labels []*LabelLifecycleMetrics `json:"labels"`
+}
@@ -41,3 +41,33 @@ type CreateStatusOption struct {
 	Description string            `json:"description"`
 	Context     string            `json:"context"`
 }
+
+// CommitStatusAnnotation holds a message of a check about a line of a file of a Commit
+type CommitStatusAnnotation struct {
+	// context of the commit statuses of the check
+	Context string                      `json:"context"`
+	Path    string                      `json:"path"`
+	Line    int64                       `json:"line"`
+	Level   CommitStatusAnnotationLevel `json:"level"`
+	Message string                      `json:"message"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateStatusAnnotationOption holds a message about a line of a file
+type CreateStatusAnnotationOption struct {
+	// path of the file in the commit
+	Path string `json:"path"`
+	// line of the file, starting at 1
+	Line int64 `json:"line"`
+	// can be "notice", "warning" or "failure"
+	Level   CommitStatusAnnotationLevel `json:"level"`
+	Message string                      `json:"message"`
+}
+
+// CreateStatusAnnotationsOption holds the annotations of a check, which replace its previous annotations of the Commit
+type CreateStatusAnnotationsOption struct {
+	// context of the commit statuses of the check, a status with this context has to exist for the commit
+	Context     string                          `json:"context" binding:"Required"`
+	Annotations []*CreateStatusAnnotationOption `json:"annotations"`
+}
@@ -9,7 +9,10 @@ type TaskType int
 
 // all kinds of task types
 const (
-	TaskTypeMigrateRepo TaskType = iota // migrate repository from external or local disk
+	TaskTypeMigrateRepo        TaskType = iota // migrate repository from external or local disk
+	TaskTypeGarbageCollectRepo                 // run git gc on a repository
+	TaskTypeReindexCode                        // reindex the code of all repositories or of one
+	TaskTypeReindexIssues                      // reindex the issues of all repositories or of one
 )
 
 // Name returns the task type name
@@ -17,6 +20,12 @@ func (taskType TaskType) Name() string {
 	switch taskType {
 	case TaskTypeMigrateRepo:
 		return "Migrate Repository"
+	case TaskTypeGarbageCollectRepo:
+		return "Garbage Collect Repository"
+	case TaskTypeReindexCode:
+		return "Reindex Code"
+	case TaskTypeReindexIssues:
+		return "Reindex Issues"
 	}
 	return ""
 }
@@ -29,6 +29,7 @@ import (
 	"code.gitea.io/gitea/modules/emoji"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/public"
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/svg"
@@ -63,6 +64,7 @@ func NewFuncMap() []template.FuncMap {
 		"StaticUrlPrefix": func() string {
 			return setting.StaticURLPrefix
 		},
+		"AssetUrl": public.AssetURL,
 		"AppUrl": func() string {
 			return setting.AppURL
 		},
@@ -35,6 +35,7 @@ func MockContext(t *testing.T, path string) *context.Context {
 		Resp:   context.NewResponse(resp),
 		Locale: &mockLocale{},
 	}
+	ctx.Flash.DataStore = &ctx
 
 	requestURL, err := url.Parse(path)
 	assert.NoError(t, err)
@@ -0,0 +1,44 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package turnstile
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	defer func(u, secret string) {
+		apiURL = u
+		setting.Service.TurnstileSecret = secret
+	}(apiURL, setting.Service.TurnstileSecret)
+	setting.Service.TurnstileSecret = "secret"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		if r.PostForm.Get("response") == "valid" {
+			_, _ = w.Write([]byte(`{"success":true,"error-codes":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer srv.Close()
+	apiURL = srv.URL
+
+	valid, err := Verify(context.Background(), "valid")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = Verify(context.Background(), "invalid")
+	assert.Equal(t, ErrorCode("invalid-input-response"), err)
+	assert.False(t, valid)
+}
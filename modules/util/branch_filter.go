@@ -0,0 +1,120 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// BranchFilter matches branch names against a comma-separated list of glob
+// patterns. Patterns prefixed with "!" exclude branches. A branch matches if it
+// matches none of the excluding patterns and any of the including ones, or if
+// there are no including patterns at all.
+type BranchFilter struct {
+	Include []string
+	Exclude []string
+
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+// splitBranchFilter splits a filter on the commas which are not part of a
+// "{a,b}" alternation or a character class, so older single-glob filters
+// like "{master,release*}" keep their meaning.
+func splitBranchFilter(filter string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inClass, escaped := false, false
+	for i, c := range filter {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth > 0 {
+				depth--
+			}
+		case c == ',' && depth == 0:
+			parts = append(parts, filter[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, filter[start:])
+}
+
+// ParseBranchFilter parses a branch filter, an empty filter matches all branches
+func ParseBranchFilter(filter string) (*BranchFilter, error) {
+	f := &BranchFilter{}
+	for _, pattern := range splitBranchFilter(filter) {
+		pattern = strings.TrimSpace(pattern)
+		exclude := strings.HasPrefix(pattern, "!")
+		if exclude {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		if len(pattern) == 0 {
+			continue
+		}
+
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if exclude {
+			f.Exclude = append(f.Exclude, pattern)
+			f.exclude = append(f.exclude, g)
+		} else {
+			f.Include = append(f.Include, pattern)
+			f.include = append(f.include, g)
+		}
+	}
+	return f, nil
+}
+
+// IsEmpty returns true if the filter does not restrict branches at all
+func (f *BranchFilter) IsEmpty() bool {
+	return len(f.Exclude) == 0 && (len(f.Include) == 0 || (len(f.Include) == 1 && f.Include[0] == "*"))
+}
+
+// Match returns true if the branch passes the filter
+func (f *BranchFilter) Match(branch string) bool {
+	for _, g := range f.exclude {
+		if g.Match(branch) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, g := range f.include {
+		if g.Match(branch) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the normalized filter, "*" if it does not restrict branches
+func (f *BranchFilter) String() string {
+	if f.IsEmpty() {
+		return "*"
+	}
+	patterns := make([]string, 0, len(f.Include)+len(f.Exclude))
+	patterns = append(patterns, f.Include...)
+	for _, pattern := range f.Exclude {
+		patterns = append(patterns, "!"+pattern)
+	}
+	return strings.Join(patterns, ",")
+}
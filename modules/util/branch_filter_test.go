@@ -0,0 +1,54 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchFilter(t *testing.T) {
+	kases := []struct {
+		filter  string
+		matches []string
+		skips   []string
+	}{
+		{"", []string{"master", "feature/x"}, nil},
+		{"*", []string{"master", "feature/x"}, nil},
+		{"master", []string{"master"}, []string{"main", "master2"}},
+		{"{master,release*}", []string{"master", "release/1.14"}, []string{"main"}},
+		{"main, release/*", []string{"main", "release/1.14"}, []string{"feature/x", "release"}},
+		{"!wip/*", []string{"main", "feature/x"}, []string{"wip/x"}},
+		{"*,!wip/*,!dependabot/**", []string{"main"}, []string{"wip/x", "dependabot/npm/x"}},
+		{"release/*,!release/*-rc", []string{"release/1.14"}, []string{"release/1.14-rc", "main"}},
+		{"[ab]*,c", []string{"alpha", "beta", "c"}, []string{"gamma"}},
+	}
+	for _, kase := range kases {
+		f, err := ParseBranchFilter(kase.filter)
+		assert.NoError(t, err, kase.filter)
+		for _, branch := range kase.matches {
+			assert.True(t, f.Match(branch), "%q should match %q", kase.filter, branch)
+		}
+		for _, branch := range kase.skips {
+			assert.False(t, f.Match(branch), "%q should not match %q", kase.filter, branch)
+		}
+	}
+
+	f, err := ParseBranchFilter("main, !wip/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main"}, f.Include)
+	assert.Equal(t, []string{"wip/*"}, f.Exclude)
+	assert.False(t, f.IsEmpty())
+	assert.Equal(t, "main,!wip/*", f.String())
+
+	f, err = ParseBranchFilter(" * ")
+	assert.NoError(t, err)
+	assert.True(t, f.IsEmpty())
+	assert.Equal(t, "*", f.String())
+
+	_, err = ParseBranchFilter("main,[a-")
+	assert.Error(t, err)
+}
@@ -40,3 +40,17 @@ func URLJoin(base string, elems ...string) string {
 	}
 	return joinedURL
 }
+
+// IsLocalPath returns true if the link is an absolute path on the same host, e.g. "/explore".
+// Protocol-relative links like "//example.com" and "/\example.com", which browsers treat as
+// links to another host, are rejected.
+func IsLocalPath(link string) bool {
+	if !strings.HasPrefix(link, "/") || strings.HasPrefix(link, "//") || strings.HasPrefix(link, "/\\") {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" && u.Host == "" && u.User == nil
+}
@@ -11,6 +11,22 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestIsLocalPath(t *testing.T) {
+	for link, expected := range map[string]bool{
+		"/":                     true,
+		"/explore/repos?q=test": true,
+		"/user2/repo1":          true,
+		"":                      false,
+		"explore":               false,
+		"//example.com":         false,
+		"/\\example.com":        false,
+		"https://example.com/":  false,
+		"javascript:alert(1)":   false,
+	} {
+		assert.Equal(t, expected, IsLocalPath(link), link)
+	}
+}
+
 func TestURLJoin(t *testing.T) {
 	type test struct {
 		Expected string
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 
+	"code.gitea.io/gitea/modules/util"
+
 	"gitea.com/go-chi/binding"
 	"github.com/gobwas/glob"
 )
@@ -19,6 +21,9 @@ const (
 
 	// ErrGlobPattern is returned when glob pattern is invalid
 	ErrGlobPattern = "GlobPattern"
+
+	// ErrBranchFilter is returned when a branch filter is invalid
+	ErrBranchFilter = "BranchFilter"
 )
 
 var (
@@ -53,6 +58,7 @@ func AddBindingRules() {
 	addGitRefNameBindingRule()
 	addValidURLBindingRule()
 	addGlobPatternRule()
+	addBranchFilterRule()
 }
 
 func addGitRefNameBindingRule() {
@@ -117,6 +123,24 @@ func addGlobPatternRule() {
 	})
 }
 
+func addBranchFilterRule() {
+	binding.AddRule(&binding.Rule{
+		IsMatch: func(rule string) bool {
+			return rule == "BranchFilter"
+		},
+		IsValid: func(errs binding.Errors, name string, val interface{}) (bool, binding.Errors) {
+			str := fmt.Sprintf("%v", val)
+
+			if _, err := util.ParseBranchFilter(str); err != nil {
+				errs.Add([]string{name}, ErrBranchFilter, err.Error())
+				return false, errs
+			}
+
+			return true, errs
+		},
+	})
+}
+
 func portOnly(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
 	if colon == -1 {
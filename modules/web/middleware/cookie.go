@@ -98,6 +98,20 @@ func DeleteRedirectToCookie(resp http.ResponseWriter) {
 		SameSite(setting.SessionConfig.SameSite))
 }
 
+// LastVisitedRepoCookieName is the cookie remembering the repository visited last
+const LastVisitedRepoCookieName = "last_visited_repo"
+
+// SetLastVisitedRepoCookie remembers the link of the repository visited last
+func SetLastVisitedRepoCookie(resp http.ResponseWriter, link string) {
+	SetCookie(resp, LastVisitedRepoCookieName, link,
+		setting.LogInRememberDays*86400,
+		setting.AppSubURL,
+		"",
+		setting.SessionConfig.Secure,
+		true,
+		SameSite(setting.SessionConfig.SameSite))
+}
+
 // DeleteSesionConfigPathCookie convenience function to delete SessionConfigPath cookies consistently
 func DeleteSesionConfigPathCookie(resp http.ResponseWriter, name string) {
 	SetCookie(resp, name, "",
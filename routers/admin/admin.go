@@ -24,8 +24,10 @@ import (
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
+	indexer_service "code.gitea.io/gitea/services/indexer"
 	"code.gitea.io/gitea/services/mailer"
 	jsoniter "github.com/json-iterator/go"
 
@@ -128,9 +130,65 @@ func Dashboard(ctx *context.Context) {
 	updateSystemStatus()
 	ctx.Data["SysStatus"] = sysStatus
 	ctx.Data["SSH"] = setting.SSH
+
+	reindexes, err := lastReindexes()
+	if err != nil {
+		ctx.ServerError("lastReindexes", err)
+		return
+	}
+	ctx.Data["Reindexes"] = reindexes
 	ctx.HTML(200, tplDashboard)
 }
 
+// indexerReindex is the last reindex of all the repositories of an indexer
+type indexerReindex struct {
+	Indexer  string
+	Task     *models.Task
+	Progress *indexer_service.ReindexProgress
+	Pending  bool
+	Finished bool
+}
+
+// lastReindexes returns the last reindexes of the enabled indexers
+func lastReindexes() ([]*indexerReindex, error) {
+	reindexes := make([]*indexerReindex, 0, len(indexer_service.Indexers))
+	for _, indexer := range indexer_service.Indexers {
+		if !indexer_service.IsIndexerEnabled(indexer) {
+			continue
+		}
+		reindex := &indexerReindex{Indexer: indexer}
+		task, err := indexer_service.GetLastReindex(indexer, nil)
+		if err == nil {
+			reindex.Task = task
+			reindex.Pending = indexer_service.IsReindexPending(task)
+			reindex.Finished = task.Status == structs.TaskStatusFinished
+			if reindex.Progress, err = indexer_service.GetReindexProgress(task); err != nil {
+				return nil, err
+			}
+		} else if !models.IsErrTaskDoesNotExist(err) {
+			return nil, err
+		}
+		reindexes = append(reindexes, reindex)
+	}
+	return reindexes, nil
+}
+
+// ReindexPost queues a reindex of all the repositories of an indexer, its progress is shown in the dashboard
+func ReindexPost(ctx *context.Context) {
+	indexer := ctx.Params(":indexer")
+	if !indexer_service.IsValidIndexer(indexer) || !indexer_service.IsIndexerEnabled(indexer) {
+		ctx.NotFound("ReindexPost", nil)
+		return
+	}
+	if _, err := indexer_service.Reindex(ctx.User, indexer, nil); err != nil {
+		ctx.ServerError("Reindex", err)
+		return
+	}
+
+	ctx.Flash.Info(ctx.Tr("admin.dashboard.reindex_queued"))
+	ctx.Redirect(setting.AppSubURL + "/admin")
+}
+
 // DashboardPost run an admin operation
 func DashboardPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.AdminDashboardForm)
@@ -0,0 +1,122 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplAuditLog base.TplName = "admin/audit"
+)
+
+// auditLogOptions parses the filters of the audit log from the query, the dates are days in
+// the format YYYY-MM-DD and both are inclusive
+func auditLogOptions(ctx *context.Context) *models.FindAuditEventsOptions {
+	opts := &models.FindAuditEventsOptions{
+		ActorName: ctx.QueryTrim("actor"),
+		Action:    models.AuditAction(ctx.QueryTrim("action")),
+	}
+	ctx.Data["Actor"] = opts.ActorName
+	ctx.Data["Action"] = string(opts.Action)
+
+	if since := ctx.QueryTrim("since"); since != "" {
+		if t, err := time.ParseInLocation("2006-01-02", since, setting.DefaultUILocation); err == nil {
+			opts.Since = t.Unix()
+			ctx.Data["Since"] = since
+		}
+	}
+	if before := ctx.QueryTrim("before"); before != "" {
+		if t, err := time.ParseInLocation("2006-01-02", before, setting.DefaultUILocation); err == nil {
+			opts.Before = t.AddDate(0, 0, 1).Unix()
+			ctx.Data["Before"] = before
+		}
+	}
+	return opts
+}
+
+// AuditLog shows the audit log for admin
+func AuditLog(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAudit"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := auditLogOptions(ctx)
+	opts.ListOptions = models.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.NoticePagingNum,
+	}
+	events, total, err := models.FindAuditEvents(opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["Events"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["Actions"] = models.AuditActions
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParam(ctx, "actor", "Actor")
+	pager.AddParam(ctx, "action", "Action")
+	pager.AddParam(ctx, "since", "Since")
+	pager.AddParam(ctx, "before", "Before")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplAuditLog)
+}
+
+// ExportAuditLog exports the events of the audit log matching the filters as CSV
+func ExportAuditLog(ctx *context.Context) {
+	opts := auditLogOptions(ctx)
+
+	ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	ctx.Resp.Header().Set("Content-Disposition", "attachment; filename=audit-log-"+time.Now().Format("20060102")+".csv")
+
+	w := csv.NewWriter(ctx.Resp)
+	if err := w.Write([]string{"id", "time", "action", "actor_id", "actor", "target_type", "target_id", "target", "description", "ip"}); err != nil {
+		ctx.ServerError("Write", err)
+		return
+	}
+	if err := models.IterateAuditEvents(opts, func(event *models.AuditEvent) error {
+		return w.Write([]string{
+			strconv.FormatInt(event.ID, 10),
+			event.CreatedUnix.AsTime().UTC().Format(time.RFC3339),
+			string(event.Action),
+			strconv.FormatInt(event.ActorID, 10),
+			escapeCSVCell(event.ActorName),
+			escapeCSVCell(event.TargetType),
+			strconv.FormatInt(event.TargetID, 10),
+			escapeCSVCell(event.TargetName),
+			escapeCSVCell(event.Description),
+			escapeCSVCell(event.IP),
+		})
+	}); err != nil {
+		// the response has been started already, so the error can only be logged
+		log.Error("IterateAuditEvents: %v", err)
+	}
+	w.Flush()
+}
+
+// escapeCSVCell prevents spreadsheet applications from evaluating a user provided cell as a formula
+func escapeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
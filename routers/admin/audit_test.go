@@ -0,0 +1,27 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	for cell, expected := range map[string]string{
+		"":                     "",
+		"user2":                "user2",
+		"127.0.0.1":            "127.0.0.1",
+		"=HYPERLINK(\"x\")":    "'=HYPERLINK(\"x\")",
+		"+1":                   "'+1",
+		"-1+cmd|' /C calc'!A0": "'-1+cmd|' /C calc'!A0",
+		"@SUM(A1:A2)":          "'@SUM(A1:A2)",
+		"\t=1":                 "'\t=1",
+		"a=b":                  "a=b",
+	} {
+		assert.Equal(t, expected, escapeCSVCell(cell))
+	}
+}
@@ -7,12 +7,15 @@ package admin
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/ldap"
 	"code.gitea.io/gitea/modules/auth/oauth2"
 	"code.gitea.io/gitea/modules/auth/pam"
+	"code.gitea.io/gitea/modules/auth/saml"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
@@ -65,6 +68,7 @@ var (
 			{models.LoginNames[models.LoginSMTP], models.LoginSMTP},
 			{models.LoginNames[models.LoginOAuth2], models.LoginOAuth2},
 			{models.LoginNames[models.LoginSSPI], models.LoginSSPI},
+			{models.LoginNames[models.LoginSAML], models.LoginSAML},
 		}
 		if pam.Supported {
 			items = append(items, dropdownItem{models.LoginNames[models.LoginPAM], models.LoginPAM})
@@ -206,6 +210,38 @@ func parseSSPIConfig(ctx *context.Context, form auth.AuthenticationForm) (*model
 	}, nil
 }
 
+func parseSAMLConfig(ctx *context.Context, form auth.AuthenticationForm) (*models.SAMLConfig, error) {
+	if u, err := url.Parse(form.SAMLIdentityProviderURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		ctx.Data["Err_SAMLIdentityProviderURL"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.saml_identity_provider_url_invalid"))
+	}
+	cfg := &models.SAMLConfig{
+		Source: &saml.Source{
+			IdentityProviderEntityID:    strings.TrimSpace(form.SAMLIdentityProviderEntityID),
+			IdentityProviderSSOURL:      form.SAMLIdentityProviderURL,
+			IdentityProviderCertificate: strings.TrimSpace(form.SAMLIdentityProviderCertificate),
+			ServiceProviderEntityID:     strings.TrimSpace(form.SAMLServiceProviderEntityID),
+			AttributeUsername:           strings.TrimSpace(form.SAMLAttributeUsername),
+			AttributeEmail:              strings.TrimSpace(form.SAMLAttributeEmail),
+			AttributeFullName:           strings.TrimSpace(form.SAMLAttributeFullName),
+			AttributeGroups:             strings.TrimSpace(form.SAMLAttributeGroups),
+			GroupTeamMap:                strings.TrimSpace(form.SAMLGroupTeamMap),
+			GroupTeamMapRemoval:         form.SAMLGroupTeamMapRemoval,
+			AutoRegister:                form.SAMLAutoRegister,
+			AllowIDPInitiated:           form.SAMLAllowIDPInitiated,
+		},
+	}
+	if _, err := cfg.Certificates(); err != nil {
+		ctx.Data["Err_SAMLIdentityProviderCertificate"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.saml_identity_provider_certificate_invalid", err.Error()))
+	}
+	if _, err := cfg.GroupTeamMapping(); err != nil {
+		ctx.Data["Err_SAMLGroupTeamMap"] = true
+		return nil, errors.New(ctx.Tr("admin.auths.saml_group_team_map_invalid", err.Error()))
+	}
+	return cfg, nil
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*auth.AuthenticationForm)
@@ -255,6 +291,13 @@ func NewAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_of_type_exist"), tplAuthNew, form)
 			return
 		}
+	case models.LoginSAML:
+		var err error
+		config, err = parseSAMLConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthNew, form)
+			return
+		}
 	default:
 		ctx.Error(400)
 		return
@@ -266,13 +309,14 @@ func NewAuthSourcePost(ctx *context.Context) {
 		return
 	}
 
-	if err := models.CreateLoginSource(&models.LoginSource{
+	source := &models.LoginSource{
 		Type:          models.LoginType(form.Type),
 		Name:          form.Name,
 		IsActived:     form.IsActive,
 		IsSyncEnabled: form.IsSyncEnabled,
 		Cfg:           config,
-	}); err != nil {
+	}
+	if err := models.CreateLoginSource(source); err != nil {
 		if models.IsErrLoginSourceAlreadyExist(err) {
 			ctx.Data["Err_Name"] = true
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_exist", err.(models.ErrLoginSourceAlreadyExist).Name), tplAuthNew, form)
@@ -283,6 +327,13 @@ func NewAuthSourcePost(ctx *context.Context) {
 	}
 
 	log.Trace("Authentication created by admin(%s): %s", ctx.User.Name, form.Name)
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditAuthSourceCreate,
+		TargetType:  models.AuditTargetAuthSource,
+		TargetID:    source.ID,
+		TargetName:  source.Name,
+		Description: fmt.Sprintf("type: %s, active: %t", source.TypeName(), source.IsActived),
+	})
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.new_success", form.Name))
 	ctx.Redirect(setting.AppSubURL + "/admin/auths")
@@ -310,6 +361,9 @@ func EditAuthSource(ctx *context.Context) {
 	if source.IsOAuth2() {
 		ctx.Data["CurrentOAuth2Provider"] = models.OAuth2Providers[source.OAuth2().Provider]
 	}
+	if source.IsSAML() {
+		ctx.Data["SAMLMetadataURL"] = setting.AppURL + "user/saml/" + url.PathEscape(source.Name) + "/metadata"
+	}
 	ctx.HTML(200, tplAuthEdit)
 }
 
@@ -355,6 +409,12 @@ func EditAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
 			return
 		}
+	case models.LoginSAML:
+		config, err = parseSAMLConfig(ctx, form)
+		if err != nil {
+			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
+			return
+		}
 	default:
 		ctx.Error(400)
 		return
@@ -374,6 +434,13 @@ func EditAuthSourcePost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Authentication changed by admin(%s): %d", ctx.User.Name, source.ID)
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditAuthSourceUpdate,
+		TargetType:  models.AuditTargetAuthSource,
+		TargetID:    source.ID,
+		TargetName:  source.Name,
+		Description: fmt.Sprintf("type: %s, active: %t", source.TypeName(), source.IsActived),
+	})
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.update_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/auths/" + fmt.Sprint(form.ID))
@@ -399,6 +466,12 @@ func DeleteAuthSource(ctx *context.Context) {
 		return
 	}
 	log.Trace("Authentication deleted by admin(%s): %d", ctx.User.Name, source.ID)
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditAuthSourceDelete,
+		TargetType: models.AuditTargetAuthSource,
+		TargetID:   source.ID,
+		TargetName: source.Name,
+	})
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.deletion_success"))
 	ctx.JSON(200, map[string]interface{}{
@@ -58,9 +58,20 @@ func DefaultOrSystemWebhooks(ctx *context.Context) {
 
 // DeleteDefaultOrSystemWebhook handler to delete an admin-defined system or default webhook
 func DeleteDefaultOrSystemWebhook(ctx *context.Context) {
+	owner := "default"
+	if w, err := models.GetSystemOrDefaultWebhook(ctx.QueryInt64("id")); err == nil && w.IsSystemWebhook {
+		owner = "system"
+	}
+
 	if err := models.DeleteDefaultSystemWebhook(ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteDefaultWebhook: " + err.Error())
 	} else {
+		ctx.Audit(&models.AuditEvent{
+			Action:     models.AuditWebhookDelete,
+			TargetType: models.AuditTargetWebhook,
+			TargetID:   ctx.QueryInt64("id"),
+			TargetName: owner,
+		})
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -23,6 +23,7 @@ import (
 const (
 	tplRepos          base.TplName = "admin/repo/list"
 	tplUnadoptedRepos base.TplName = "admin/repo/unadopted"
+	tplDeletedRepos   base.TplName = "admin/repo/deleted"
 )
 
 // Repos show all the repositories
@@ -51,13 +52,106 @@ func DeleteRepo(ctx *context.Context) {
 		return
 	}
 	log.Trace("Repository deleted: %s", repo.FullName())
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditRepoDelete,
+		TargetType: models.AuditTargetRepository,
+		TargetID:   repo.ID,
+		TargetName: repo.FullName(),
+	})
 
-	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	if repo.IsDeleted {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_pending", repo.PurgeUnix().FormatDate()))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	}
 	ctx.JSON(200, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/admin/repos?page=" + ctx.Query("page") + "&sort=" + ctx.Query("sort"),
 	})
 }
 
+// DeletedRepos lists the repositories pending deletion
+func DeletedRepos(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repositories")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	repos, count, err := models.FindDeletedRepositories(&models.FindDeletedRepoOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.RepoPagingNum,
+		},
+	})
+	if err != nil {
+		ctx.ServerError("FindDeletedRepositories", err)
+		return
+	}
+
+	ctx.Data["Repos"] = repos
+	ctx.Data["Total"] = count
+	pager := context.NewPagination(int(count), setting.UI.Admin.RepoPagingNum, page, 5)
+	ctx.Data["Page"] = pager
+	ctx.HTML(200, tplDeletedRepos)
+}
+
+func getDeletedRepo(ctx *context.Context) *models.Repository {
+	repo, err := models.GetDeletedRepositoryByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetDeletedRepositoryByID", models.IsErrRepoNotExist, err)
+		return nil
+	}
+	return repo
+}
+
+// RestoreRepo restores a repository pending deletion
+func RestoreRepo(ctx *context.Context) {
+	repo := getDeletedRepo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.RestoreRepository(repo); err != nil {
+		ctx.ServerError("RestoreRepository", err)
+		return
+	}
+	log.Trace("Repository restored: %s", repo.FullName())
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditRepoRestore,
+		TargetType: models.AuditTargetRepository,
+		TargetID:   repo.ID,
+		TargetName: repo.FullName(),
+	})
+
+	ctx.Flash.Success(ctx.Tr("admin.repos.restore_success", repo.FullName()))
+	ctx.Redirect(setting.AppSubURL + "/admin/repos/deleted?page=" + url.QueryEscape(ctx.Query("page")))
+}
+
+// PurgeRepo deletes a repository pending deletion without waiting for the end of the retention
+func PurgeRepo(ctx *context.Context) {
+	repo := getDeletedRepo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.PurgeRepository(ctx.User, repo); err != nil {
+		ctx.ServerError("PurgeRepository", err)
+		return
+	}
+	log.Trace("Repository purged: %s", repo.FullName())
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditRepoPurge,
+		TargetType: models.AuditTargetRepository,
+		TargetID:   repo.ID,
+		TargetName: repo.FullName(),
+	})
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/repos/deleted?page=" + url.QueryEscape(ctx.Query("page")))
+}
+
 // UnadoptedRepos lists the unadopted repositories
 func UnadoptedRepos(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.repositories")
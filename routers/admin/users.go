@@ -16,6 +16,7 @@ import (
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/password"
+	"code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers"
@@ -86,6 +87,7 @@ func NewUserPost(ctx *context.Context) {
 
 	u := &models.User{
 		Name:      form.UserName,
+		FullName:  form.FullName,
 		Email:     form.Email,
 		Passwd:    form.Password,
 		IsActive:  true,
@@ -102,55 +104,25 @@ func NewUserPost(ctx *context.Context) {
 		}
 	}
 	if u.LoginType == models.LoginNoType || u.LoginType == models.LoginPlain {
-		if len(form.Password) < setting.MinPasswordLength {
-			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplUserNew, &form)
-			return
-		}
-		if !password.IsComplexEnough(form.Password) {
-			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(password.BuildComplexityError(ctx), tplUserNew, &form)
-			return
-		}
-		pwned, err := password.IsPwned(ctx.Req.Context(), form.Password)
-		if pwned {
+		if errMsg := checkNewUserPassword(ctx, form.Password); errMsg != "" {
 			ctx.Data["Err_Password"] = true
-			errMsg := ctx.Tr("auth.password_pwned")
-			if err != nil {
-				log.Error(err.Error())
-				errMsg = ctx.Tr("auth.password_pwned_err")
-			}
 			ctx.RenderWithErr(errMsg, tplUserNew, &form)
 			return
 		}
 		u.MustChangePassword = form.MustChangePassword
 	}
 	if err := models.CreateUser(u); err != nil {
-		switch {
-		case models.IsErrUserAlreadyExist(err):
-			ctx.Data["Err_UserName"] = true
-			ctx.RenderWithErr(ctx.Tr("form.username_been_taken"), tplUserNew, &form)
-		case models.IsErrEmailAlreadyUsed(err):
-			ctx.Data["Err_Email"] = true
-			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplUserNew, &form)
-		case models.IsErrEmailInvalid(err):
-			ctx.Data["Err_Email"] = true
-			ctx.RenderWithErr(ctx.Tr("form.email_invalid"), tplUserNew, &form)
-		case models.IsErrNameReserved(err):
-			ctx.Data["Err_UserName"] = true
-			ctx.RenderWithErr(ctx.Tr("user.form.name_reserved", err.(models.ErrNameReserved).Name), tplUserNew, &form)
-		case models.IsErrNamePatternNotAllowed(err):
-			ctx.Data["Err_UserName"] = true
-			ctx.RenderWithErr(ctx.Tr("user.form.name_pattern_not_allowed", err.(models.ErrNamePatternNotAllowed).Pattern), tplUserNew, &form)
-		case models.IsErrNameCharsNotAllowed(err):
-			ctx.Data["Err_UserName"] = true
-			ctx.RenderWithErr(ctx.Tr("user.form.name_chars_not_allowed", err.(models.ErrNameCharsNotAllowed).Name), tplUserNew, &form)
-		default:
+		errMsg, errField := createUserErrorMessage(ctx, err)
+		if errMsg == "" {
 			ctx.ServerError("CreateUser", err)
+			return
 		}
+		ctx.Data[errField] = true
+		ctx.RenderWithErr(errMsg, tplUserNew, &form)
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.User.Name, u.Name)
+	auditUserCreate(ctx, u)
 
 	// Send email notification.
 	if form.SendNotify {
@@ -161,6 +133,55 @@ func NewUserPost(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + fmt.Sprint(u.ID))
 }
 
+func auditUserCreate(ctx *context.Context, u *models.User) {
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditUserCreate,
+		TargetType:  models.AuditTargetUser,
+		TargetID:    u.ID,
+		TargetName:  u.Name,
+		Description: fmt.Sprintf("email: %s, login source: %d", u.Email, u.LoginSource),
+	})
+}
+
+// checkNewUserPassword returns why the password cannot be used for a new local user, an empty string if it can
+func checkNewUserPassword(ctx *context.Context, passwd string) string {
+	if len(passwd) < setting.MinPasswordLength {
+		return ctx.Tr("auth.password_too_short", setting.MinPasswordLength)
+	}
+	if !password.IsComplexEnough(passwd) {
+		return password.BuildComplexityError(ctx)
+	}
+	pwned, err := password.IsPwned(ctx.Req.Context(), passwd)
+	if pwned {
+		if err != nil {
+			log.Error(err.Error())
+			return ctx.Tr("auth.password_pwned_err")
+		}
+		return ctx.Tr("auth.password_pwned")
+	}
+	return ""
+}
+
+// createUserErrorMessage returns the message explaining why a user could not be created and the data key
+// marking the form field at fault, the message is empty if the error is not caused by the input
+func createUserErrorMessage(ctx *context.Context, err error) (string, string) {
+	switch {
+	case models.IsErrUserAlreadyExist(err):
+		return ctx.Tr("form.username_been_taken"), "Err_UserName"
+	case models.IsErrEmailAlreadyUsed(err):
+		return ctx.Tr("form.email_been_used"), "Err_Email"
+	case models.IsErrEmailInvalid(err):
+		return ctx.Tr("form.email_invalid"), "Err_Email"
+	case models.IsErrNameReserved(err):
+		return ctx.Tr("user.form.name_reserved", err.(models.ErrNameReserved).Name), "Err_UserName"
+	case models.IsErrNamePatternNotAllowed(err):
+		return ctx.Tr("user.form.name_pattern_not_allowed", err.(models.ErrNamePatternNotAllowed).Pattern), "Err_UserName"
+	case models.IsErrNameCharsNotAllowed(err):
+		return ctx.Tr("user.form.name_chars_not_allowed", err.(models.ErrNameCharsNotAllowed).Name), "Err_UserName"
+	}
+	return "", ""
+}
+
 func prepareUserInfo(ctx *context.Context) *models.User {
 	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
 	if err != nil {
@@ -227,6 +248,7 @@ func EditUserPost(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
+	before := *u
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplUserEdit)
@@ -331,11 +353,55 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.User.Name, u.Name)
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditUserUpdate,
+		TargetType:  models.AuditTargetUser,
+		TargetID:    u.ID,
+		TargetName:  u.Name,
+		Description: describeUserChanges(&before, u, len(form.Password) > 0 && (u.IsLocal() || u.IsOAuth2()), form.Reset2FA),
+	})
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"))
 }
 
+// describeUserChanges describes the changes of the login and permissions of a user for the audit log
+func describeUserChanges(before, after *models.User, passwordChanged, reset2FA bool) string {
+	var changes []string
+	if before.Name != after.Name {
+		changes = append(changes, fmt.Sprintf("name: %s -> %s", before.Name, after.Name))
+	}
+	if before.Email != after.Email {
+		changes = append(changes, fmt.Sprintf("email: %s -> %s", before.Email, after.Email))
+	}
+	if before.LoginSource != after.LoginSource {
+		changes = append(changes, fmt.Sprintf("login source: %d -> %d", before.LoginSource, after.LoginSource))
+	}
+	if passwordChanged {
+		changes = append(changes, "password changed")
+	}
+	if reset2FA {
+		changes = append(changes, "two-factor authentication reset")
+	}
+	for _, flag := range []struct {
+		name          string
+		before, after bool
+	}{
+		{"active", before.IsActive, after.IsActive},
+		{"admin", before.IsAdmin, after.IsAdmin},
+		{"restricted", before.IsRestricted, after.IsRestricted},
+		{"prohibit login", before.ProhibitLogin, after.ProhibitLogin},
+		{"allow git hooks", before.AllowGitHook, after.AllowGitHook},
+		{"allow import local", before.AllowImportLocal, after.AllowImportLocal},
+		{"allow create organization", before.AllowCreateOrganization, after.AllowCreateOrganization},
+	} {
+		if flag.before != flag.after {
+			changes = append(changes, fmt.Sprintf("%s: %t -> %t", flag.name, flag.before, flag.after))
+		}
+	}
+	return strings.Join(changes, ", ")
+}
+
 // DeleteUser response for deleting a user
 func DeleteUser(ctx *context.Context) {
 	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
@@ -362,9 +428,72 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.User.Name, u.Name)
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditUserDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   u.ID,
+		TargetName: u.Name,
+	})
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.JSON(200, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/admin/users",
 	})
 }
+
+// UnlockUser lifts the lockout of a user account after too many failed sign-in attempts
+func UnlockUser(ctx *context.Context) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetUserByID", models.IsErrUserNotExist, err)
+		return
+	}
+
+	if err = models.UnlockUser(u); err != nil {
+		ctx.ServerError("UnlockUser", err)
+		return
+	}
+	log.Trace("Account unlocked by admin (%s): %s", ctx.User.Name, u.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.unlock_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"))
+}
+
+// ImpersonateUser signs the admin in as the user to see what the user sees, the admin can return to
+// their own account from the banner shown while impersonating
+func ImpersonateUser(ctx *context.Context) {
+	u, err := models.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetUserByID", models.IsErrUserNotExist, err)
+		return
+	}
+
+	var errKey string
+	switch {
+	case ctx.IsImpersonated():
+		errKey = "admin.users.impersonate_nested"
+	case u.ID == ctx.User.ID || u.IsAdmin || u.IsOrganization():
+		errKey = "admin.users.impersonate_not_allowed"
+	case !u.IsActive || u.ProhibitLogin:
+		errKey = "admin.users.impersonate_inactive"
+	}
+	if errKey != "" {
+		ctx.Flash.Error(ctx.Tr(errKey))
+		ctx.Redirect(setting.AppSubURL + "/admin/users/" + ctx.Params(":userid"))
+		return
+	}
+
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditUserImpersonate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   u.ID,
+		TargetName: u.Name,
+	})
+	if err := session.Impersonate(ctx.Session, ctx.User, u); err != nil {
+		ctx.ServerError("Impersonate", err)
+		return
+	}
+	log.Info("Admin %s started impersonating %s", ctx.User.Name, u.Name)
+
+	ctx.Redirect(setting.AppSubURL + "/")
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/password"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/mailer"
+
+	"gitea.com/go-chi/binding"
+)
+
+const (
+	tplUserImport base.TplName = "admin/user/import"
+
+	// maxUserImportSize is the maximum size of an uploaded CSV file of users
+	maxUserImportSize = 1024 * 1024
+	// minRandomPasswordLength is the minimum length of the passwords generated for imported users
+	minRandomPasswordLength = 16
+)
+
+// userImportRow is a row of a CSV file of users with the result of its validation
+type userImportRow struct {
+	Row                int
+	UserName           string
+	FullName           string
+	Email              string
+	Password           string
+	GeneratePassword   bool
+	MustChangePassword bool
+	Error              string
+}
+
+// parseUserImport parses a CSV file of users with the columns username, email, full name, password and
+// must change password. The header row and the last three columns are optional, a user without password
+// gets a random one which must be changed.
+func parseUserImport(ctx *context.Context, content string) ([]*userImportRow, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var rows []*userImportRow
+	for i := 1; ; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if i == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "username") {
+			continue
+		}
+
+		row := &userImportRow{Row: i, MustChangePassword: true}
+		rows = append(rows, row)
+		if len(record) < 2 || len(record) > 5 {
+			row.Error = ctx.Tr("admin.users.import.columns_error")
+			continue
+		}
+		row.UserName = strings.TrimSpace(record[0])
+		row.Email = strings.TrimSpace(record[1])
+		if len(record) > 2 {
+			row.FullName = strings.TrimSpace(record[2])
+		}
+		if len(record) > 3 {
+			row.Password = record[3]
+		}
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			if row.MustChangePassword, err = strconv.ParseBool(strings.TrimSpace(record[4])); err != nil {
+				row.Error = ctx.Tr("admin.users.import.must_change_password_error")
+				continue
+			}
+		}
+		if row.Password == "" {
+			row.GeneratePassword = true
+			row.MustChangePassword = true
+		}
+	}
+	return rows, nil
+}
+
+// validateUserImport validates the rows the same way as a user created with the form, and checks that
+// the rows do not share a username or an email address. The errors are set on the rows.
+func validateUserImport(ctx *context.Context, rows []*userImportRow) error {
+	names := make(map[string]int, len(rows))
+	emails := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if row.Error != "" {
+			continue
+		}
+
+		form := &auth.AdminCreateUserForm{
+			LoginType: "0-0",
+			UserName:  row.UserName,
+			FullName:  row.FullName,
+			Email:     row.Email,
+			Password:  row.Password,
+		}
+		if errs := binding.RawValidate(form); len(errs) > 0 {
+			data := make(map[string]interface{})
+			middleware.Validate(errs, data, form, ctx.Locale)
+			row.Error = data["ErrorMsg"].(string)
+			continue
+		}
+		if !row.GeneratePassword {
+			if errMsg := checkNewUserPassword(ctx, row.Password); errMsg != "" {
+				row.Error = errMsg
+				continue
+			}
+		}
+
+		lowerName, lowerEmail := strings.ToLower(row.UserName), strings.ToLower(row.Email)
+		if other, ok := names[lowerName]; ok {
+			row.Error = ctx.Tr("admin.users.import.duplicate_name", other)
+			continue
+		}
+		if other, ok := emails[lowerEmail]; ok {
+			row.Error = ctx.Tr("admin.users.import.duplicate_email", other)
+			continue
+		}
+		names[lowerName] = row.Row
+		emails[lowerEmail] = row.Row
+
+		if err := models.CheckCreateUser(&models.User{Name: row.UserName, Email: row.Email}); err != nil {
+			errMsg, _ := createUserErrorMessage(ctx, err)
+			if errMsg == "" {
+				return err
+			}
+			row.Error = errMsg
+		}
+	}
+	return nil
+}
+
+// ImportUsers render the page to import users from a CSV file
+func ImportUsers(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.users.import")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminUsers"] = true
+	ctx.Data["CanSendEmail"] = setting.MailService != nil
+	ctx.Data["RequireActivation"] = setting.Service.RegisterEmailConfirm
+
+	ctx.HTML(200, tplUserImport)
+}
+
+// ImportUsersPost previews the import of the users of an uploaded CSV file and, once confirmed,
+// creates all of them or none
+func ImportUsersPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.AdminImportUsersForm)
+	ctx.Data["Title"] = ctx.Tr("admin.users.import")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminUsers"] = true
+	ctx.Data["CanSendEmail"] = setting.MailService != nil
+	ctx.Data["RequireActivation"] = setting.Service.RegisterEmailConfirm
+
+	if ctx.HasError() {
+		ctx.HTML(200, tplUserImport)
+		return
+	}
+
+	content := form.Content
+	if form.File != nil {
+		if form.File.Size > maxUserImportSize {
+			ctx.RenderWithErr(ctx.Tr("admin.users.import.too_large", base.FileSize(maxUserImportSize)), tplUserImport, form)
+			return
+		}
+		fr, err := form.File.Open()
+		if err != nil {
+			ctx.ServerError("Open", err)
+			return
+		}
+		defer fr.Close()
+		data, err := ioutil.ReadAll(io.LimitReader(fr, maxUserImportSize))
+		if err != nil {
+			ctx.ServerError("ReadAll", err)
+			return
+		}
+		content = string(data)
+	}
+
+	rows, err := parseUserImport(ctx, content)
+	if err != nil {
+		ctx.RenderWithErr(ctx.Tr("admin.users.import.parse_error", err.Error()), tplUserImport, form)
+		return
+	}
+	if len(rows) == 0 {
+		ctx.RenderWithErr(ctx.Tr("admin.users.import.empty"), tplUserImport, form)
+		return
+	}
+	if err := validateUserImport(ctx, rows); err != nil {
+		ctx.ServerError("validateUserImport", err)
+		return
+	}
+
+	numErrors := 0
+	for _, row := range rows {
+		if row.Error != "" {
+			numErrors++
+		}
+	}
+	ctx.Data["Content"] = content
+	ctx.Data["SendNotify"] = form.SendNotify
+	ctx.Data["Rows"] = rows
+	ctx.Data["NumErrors"] = numErrors
+	if numErrors > 0 {
+		ctx.Flash.Error(ctx.Tr("admin.users.import.has_errors", numErrors), true)
+	}
+	if !form.Confirm || numErrors > 0 {
+		ctx.HTML(200, tplUserImport)
+		return
+	}
+
+	users := make([]*models.User, len(rows))
+	for i, row := range rows {
+		if row.GeneratePassword {
+			if row.Password, err = password.Generate(util.Max(setting.MinPasswordLength, minRandomPasswordLength)); err != nil {
+				ctx.ServerError("Generate", err)
+				return
+			}
+		}
+		users[i] = &models.User{
+			Name:               row.UserName,
+			FullName:           row.FullName,
+			Email:              row.Email,
+			Passwd:             row.Password,
+			IsActive:           !setting.Service.RegisterEmailConfirm,
+			LoginType:          models.LoginPlain,
+			MustChangePassword: row.MustChangePassword,
+		}
+	}
+	if err := models.CreateUsers(users); err != nil {
+		if bulkErr, ok := err.(models.ErrBulkCreateUser); ok {
+			// the users have changed since the preview
+			if errMsg, _ := createUserErrorMessage(ctx, bulkErr.Err); errMsg != "" {
+				rows[bulkErr.Index].Error = errMsg
+				ctx.Data["NumErrors"] = 1
+				ctx.Flash.Error(ctx.Tr("admin.users.import.has_errors", 1), true)
+				ctx.HTML(200, tplUserImport)
+				return
+			}
+		}
+		ctx.ServerError("CreateUsers", err)
+		return
+	}
+
+	for _, u := range users {
+		log.Trace("Account imported by admin (%s): %s", ctx.User.Name, u.Name)
+		auditUserCreate(ctx, u)
+		// the users have to confirm their email address like the users who register themselves
+		if setting.Service.RegisterEmailConfirm {
+			mailer.SendActivateAccountMail(ctx.Locale, u)
+			if err := ctx.Cache.Put("MailResendLimit_"+u.LowerName, u.LowerName, 180); err != nil {
+				log.Error("Set cache(MailResendLimit) fail: %v", err)
+			}
+		} else if form.SendNotify {
+			mailer.SendRegisterNotifyMail(ctx.Locale, u)
+		}
+	}
+
+	ctx.Data["Imported"] = true
+	ctx.Flash.Success(ctx.Tr("admin.users.import.success", len(users)), true)
+	ctx.HTML(200, tplUserImport)
+}
@@ -121,3 +121,70 @@ func TestNewUserPost_InvalidEmail(t *testing.T) {
 
 	assert.NotEmpty(t, ctx.Flash.ErrorMsg)
 }
+
+func TestImportUsersPost(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "admin/users/import")
+	ctx.User = models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+
+	content := "username,email,full_name,password,must_change_password\n" +
+		"import1,import1@example.com,Import One,abc123ABC!=$,false\n" +
+		"import2,import2@example.com\n"
+
+	web.SetForm(ctx, &auth.AdminImportUsersForm{Content: content})
+	ImportUsersPost(ctx)
+
+	// the preview does not create any user
+	assert.EqualValues(t, 0, ctx.Data["NumErrors"])
+	assert.Len(t, ctx.Data["Rows"], 2)
+	models.AssertNotExistsBean(t, &models.User{Name: "import1"})
+
+	ctx = test.MockContext(t, "admin/users/import")
+	ctx.User = models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+	web.SetForm(ctx, &auth.AdminImportUsersForm{Content: content, Confirm: true})
+	ImportUsersPost(ctx)
+
+	assert.NotEmpty(t, ctx.Flash.SuccessMsg)
+	u := models.AssertExistsAndLoadBean(t, &models.User{Name: "import1"}).(*models.User)
+	assert.Equal(t, "Import One", u.FullName)
+	assert.False(t, u.MustChangePassword)
+	assert.True(t, u.ValidatePassword("abc123ABC!=$"))
+	u = models.AssertExistsAndLoadBean(t, &models.User{Name: "import2"}).(*models.User)
+	assert.True(t, u.MustChangePassword)
+	rows := ctx.Data["Rows"].([]*userImportRow)
+	assert.True(t, rows[1].GeneratePassword)
+	assert.True(t, u.ValidatePassword(rows[1].Password))
+}
+
+func TestImportUsersPost_Errors(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "admin/users/import")
+	ctx.User = models.AssertExistsAndLoadBean(t, &models.User{ID: 1}).(*models.User)
+
+	content := "import1,import1@example.com\n" +
+		"user2,import2@example.com\n" +
+		"import3,user2@example.com\n" +
+		"import1,import4@example.com\n" +
+		"import5,not-an-email\n" +
+		"import6,import6@example.com,,short\n" +
+		"import7,import7@example.com,,,maybe\n" +
+		"import8\n"
+
+	web.SetForm(ctx, &auth.AdminImportUsersForm{Content: content, Confirm: true})
+	ImportUsersPost(ctx)
+
+	assert.EqualValues(t, 7, ctx.Data["NumErrors"])
+	rows := ctx.Data["Rows"].([]*userImportRow)
+	if assert.Len(t, rows, 8) {
+		assert.Empty(t, rows[0].Error)
+		assert.Equal(t, "form.username_been_taken", rows[1].Error)
+		assert.Equal(t, "form.email_been_used", rows[2].Error)
+		assert.Equal(t, "admin.users.import.duplicate_name", rows[3].Error)
+		assert.NotEmpty(t, rows[4].Error)
+		assert.NotEmpty(t, rows[5].Error)
+		assert.Equal(t, "admin.users.import.must_change_password_error", rows[6].Error)
+		assert.Equal(t, "admin.users.import.columns_error", rows[7].Error)
+	}
+	// nothing is created while a row has errors
+	models.AssertNotExistsBean(t, &models.User{Name: "import1"})
+}
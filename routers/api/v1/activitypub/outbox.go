@@ -0,0 +1,99 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/activitypub"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/routers/api/v1/user"
+)
+
+// UserOutbox returns the public activity of a user or organization
+func UserOutbox(ctx *context.APIContext) {
+	// swagger:operation GET /activitypub/user/{username}/outbox activitypub activitypubUserOutbox
+	// ---
+	// summary: Get the ActivityPub outbox of a user or organization
+	// produces:
+	// - application/activity+json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user or organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: ActivityStreams OrderedCollection of the latest public activities
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	u := user.GetUserByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !u.Visibility.IsPublic() {
+		ctx.NotFound()
+		return
+	}
+
+	actions, err := models.GetFeeds(models.GetFeedsOptions{
+		RequestedUser:   u,
+		OnlyPerformedBy: !u.IsOrganization(),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetFeeds", err)
+		return
+	}
+
+	writeOutbox(ctx, activitypub.UserOutboxURL(u), actions)
+}
+
+// RepoOutbox returns the public activity of a repository
+func RepoOutbox(ctx *context.APIContext) {
+	// swagger:operation GET /activitypub/repo/{owner}/{repo}/outbox activitypub activitypubRepoOutbox
+	// ---
+	// summary: Get the ActivityPub outbox of a repository
+	// produces:
+	// - application/activity+json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: ActivityStreams OrderedCollection of the latest public activities
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsPrivate || !ctx.Repo.Owner.Visibility.IsPublic() {
+		ctx.NotFound()
+		return
+	}
+
+	actions, err := models.GetPublicRepoFeeds(ctx.Repo.Repository)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPublicRepoFeeds", err)
+		return
+	}
+
+	writeOutbox(ctx, activitypub.RepoOutboxURL(ctx.Repo.Repository), actions)
+}
+
+func writeOutbox(ctx *context.APIContext, id string, actions []*models.Action) {
+	if err := activitypub.Write(ctx.Resp, activitypub.Outbox(id, actions)); err != nil {
+		log.Error("Unable to write outbox %s: %v", id, err)
+	}
+}
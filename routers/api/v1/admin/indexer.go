@@ -0,0 +1,199 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	indexer_service "code.gitea.io/gitea/services/indexer"
+)
+
+var reindexStatuses = map[api.TaskStatus]string{
+	api.TaskStatusQueue:    "queued",
+	api.TaskStatusRunning:  "running",
+	api.TaskStatusStopped:  "stopped",
+	api.TaskStatusFailed:   "failed",
+	api.TaskStatusFinished: "finished",
+}
+
+func toReindex(task *models.Task) (*api.Reindex, error) {
+	progress, err := indexer_service.GetReindexProgress(task)
+	if err != nil {
+		return nil, err
+	}
+	reindex := &api.Reindex{
+		ID:      task.ID,
+		Indexer: indexer_service.TaskIndexer(task),
+		Status:  reindexStatuses[task.Status],
+		Total:   progress.Total,
+		Done:    progress.Done,
+		Failed:  progress.Failed,
+		Errors:  task.Errors,
+		Created: task.Created.AsTime(),
+	}
+	if task.RepoID > 0 {
+		if err := task.LoadRepo(); err != nil {
+			return nil, err
+		}
+		reindex.Repo = task.Repo.FullName()
+	}
+	if task.StartTime > 0 {
+		reindex.Started = task.StartTime.AsTimePtr()
+	}
+	if task.EndTime > 0 {
+		reindex.Finished = task.EndTime.AsTimePtr()
+	}
+	return reindex, nil
+}
+
+// getReindexRepo returns the repository whose full name is given, or nil if the name is empty
+func getReindexRepo(ctx *context.APIContext, fullName string) *models.Repository {
+	if fullName == "" {
+		return nil
+	}
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repo must be the full name of a repository")
+		return nil
+	}
+	repo, err := models.GetRepositoryByOwnerAndName(parts[0], parts[1])
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return nil
+	}
+	return repo
+}
+
+// getReindexIndexer returns the indexer given in the path, which must be enabled
+func getReindexIndexer(ctx *context.APIContext) string {
+	indexer := ctx.Params(":indexer")
+	if !indexer_service.IsValidIndexer(indexer) {
+		ctx.NotFound()
+		return ""
+	}
+	if !indexer_service.IsIndexerEnabled(indexer) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the "+indexer+" indexer is not enabled")
+		return ""
+	}
+	return indexer
+}
+
+// GetReindex returns the last reindex of an indexer
+func GetReindex(ctx *context.APIContext) {
+	// swagger:operation GET /admin/indexers/{indexer}/reindex admin adminGetReindex
+	// ---
+	// summary: Get the progress of the last reindex of an indexer
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: indexer
+	//   in: path
+	//   description: indexer to reindex
+	//   type: string
+	//   enum: [code, issues]
+	//   required: true
+	// - name: repo
+	//   in: query
+	//   description: full name of the repository whose last reindex to get, the last reindex of all the repositories if empty
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Reindex"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	indexer := getReindexIndexer(ctx)
+	if ctx.Written() {
+		return
+	}
+	repo := getReindexRepo(ctx, ctx.Query("repo"))
+	if ctx.Written() {
+		return
+	}
+
+	task, err := indexer_service.GetLastReindex(indexer, repo)
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return
+	}
+	reindex, err := toReindex(task)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reindex)
+}
+
+// Reindex queues a reindex of an indexer
+func Reindex(ctx *context.APIContext) {
+	// swagger:operation POST /admin/indexers/{indexer}/reindex admin adminReindex
+	// ---
+	// summary: Queue a reindex of an indexer, which runs in the background and resumes after a restart
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: indexer
+	//   in: path
+	//   description: indexer to reindex
+	//   type: string
+	//   enum: [code, issues]
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/ReindexOption"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/Reindex"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.ReindexOption)
+	indexer := getReindexIndexer(ctx)
+	if ctx.Written() {
+		return
+	}
+	repo := getReindexRepo(ctx, form.Repo)
+	if ctx.Written() {
+		return
+	}
+
+	task, err := indexer_service.Reindex(ctx.User, indexer, repo)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	log.Trace("Reindex of the %s indexer queued by admin(%s)", indexer, ctx.User.Name)
+	reindex, err := toReindex(task)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, reindex)
+}
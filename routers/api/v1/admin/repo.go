@@ -5,11 +5,16 @@
 package admin
 
 import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/user"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // CreateRepo api for creating a repository
@@ -50,3 +55,114 @@ func CreateRepo(ctx *context.APIContext) {
 
 	repo.CreateUserRepo(ctx, owner, *form)
 }
+
+func queueRepoMaintenance(ctx *context.APIContext, repoID int64) {
+	form := web.GetForm(ctx).(*api.RepoMaintenanceOption)
+	operation := repo_service.MaintenanceOperation(ctx.Params(":operation"))
+	if operation != repo_service.MaintenanceGC && operation != repo_service.MaintenanceFsck {
+		ctx.NotFound()
+		return
+	}
+	if form.MinPackSize < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "min_pack_size must not be negative")
+		return
+	}
+
+	if err := repo_service.QueueMaintenance(&repo_service.MaintenanceTask{
+		Operation:   operation,
+		RepoID:      repoID,
+		DryRun:      form.DryRun,
+		MinPackSize: form.MinPackSize,
+		DoerName:    ctx.User.Name,
+	}); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	log.Trace("Repository maintenance %s of repository %d queued by admin(%s)", operation, repoID, ctx.User.Name)
+	ctx.Status(http.StatusAccepted)
+}
+
+// RunRepositoriesMaintenance queues a maintenance operation on all repositories
+func RunRepositoriesMaintenance(ctx *context.APIContext) {
+	// swagger:operation POST /admin/repos/{operation} admin adminRunRepositoriesMaintenance
+	// ---
+	// summary: Queue git gc or git fsck of all repositories, the results are recorded as system notices
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: operation
+	//   in: path
+	//   description: operation to run
+	//   type: string
+	//   enum: [gc, fsck]
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RepoMaintenanceOption"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	queueRepoMaintenance(ctx, 0)
+}
+
+// RunRepositoryMaintenance queues a maintenance operation on a repository
+func RunRepositoryMaintenance(ctx *context.APIContext) {
+	// swagger:operation POST /admin/repos/{owner}/{repo}/{operation} admin adminRunRepositoryMaintenance
+	// ---
+	// summary: Queue git gc or git fsck of a repository, the results are recorded as system notices
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: operation
+	//   in: path
+	//   description: operation to run
+	//   type: string
+	//   enum: [gc, fsck]
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RepoMaintenanceOption"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	repo, err := models.GetRepositoryByOwnerAndName(ctx.Params(":username"), ctx.Params(":reponame"))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.InternalServerError(err)
+		}
+		return
+	}
+	queueRepoMaintenance(ctx, repo.ID)
+}
@@ -65,6 +65,7 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
@@ -73,9 +74,11 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
+	session_module "code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/activitypub"
 	"code.gitea.io/gitea/routers/api/v1/admin"
 	"code.gitea.io/gitea/routers/api/v1/misc"
 	"code.gitea.io/gitea/routers/api/v1/notify"
@@ -173,12 +176,27 @@ func repoAssignment() func(ctx *context.APIContext) {
 		repo.Owner = owner
 		ctx.Repo.Repository = repo
 
-		ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		if owner.IsOrganization() && !checkTwoFactorRequirement(ctx, owner) {
 			return
 		}
 
+		if repoToken := ctx.RepoAccessToken(); !ctx.IsSigned && repoToken != nil && repoToken.RepoID == repo.ID {
+			ctx.Repo.Permission, err = repoToken.Permission(repo)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "Permission", err)
+				return
+			}
+			if err = repoToken.UpdateLastUsed(); err != nil {
+				log.Error("UpdateLastUsed: %v", err)
+			}
+		} else {
+			ctx.Repo.Permission, err = models.GetUserRepoPermission(repo, ctx.User)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+				return
+			}
+		}
+
 		if !ctx.Repo.HasAccess() {
 			ctx.NotFound()
 			return
@@ -401,6 +419,23 @@ func reqWebhooksEnabled() func(ctx *context.APIContext) {
 	}
 }
 
+// checkTwoFactorRequirement denies members without two-factor authentication access to an
+// organization which requires it. It returns false if the request has been handled.
+func checkTwoFactorRequirement(ctx *context.APIContext, org *models.User) bool {
+	if !ctx.IsSigned {
+		return true
+	}
+	missing, err := org.IsTwoFactorMissingFor(ctx.User.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsTwoFactorMissingFor", err)
+		return false
+	} else if missing {
+		ctx.Error(http.StatusForbidden, "", fmt.Sprintf("organization %s requires two-factor authentication, enroll it first", org.Name))
+		return false
+	}
+	return true
+}
+
 func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 	var (
 		assignOrg  bool
@@ -433,6 +468,9 @@ func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 				}
 				return
 			}
+			if !checkTwoFactorRequirement(ctx, ctx.Org.Organization) {
+				return
+			}
 		}
 
 		if assignTeam {
@@ -559,6 +597,8 @@ func Routes() *web.Route {
 		Secure:         setting.SessionConfig.Secure,
 		Domain:         setting.SessionConfig.Domain,
 	}))
+	m.Use(session_module.Expirer(setting.SessionConfig.IdleTimeout, setting.SessionConfig.Maxlifetime))
+	m.Use(session_module.Tracker(setting.SessionConfig.Maxlifetime))
 	m.Use(securityHeaders())
 	if setting.CORSConfig.Enabled {
 		m.Use(cors.Handler(cors.Options{
@@ -589,6 +629,7 @@ func Routes() *web.Route {
 		}
 		m.Get("/version", misc.Version)
 		m.Get("/signing-key.gpg", misc.SigningKey)
+		m.Get("/webhook/schema/:event", misc.WebhookSchema)
 		m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 		m.Post("/markdown/raw", misc.MarkdownRaw)
 		m.Group("/settings", func() {
@@ -598,6 +639,13 @@ func Routes() *web.Route {
 			m.Get("/repository", settings.GetGeneralRepoSettings)
 		})
 
+		if setting.Federation.Enabled {
+			m.Group("/activitypub", func() {
+				m.Get("/user/{username}/outbox", activitypub.UserOutbox)
+				m.Get("/repo/{username}/{reponame}/outbox", repoAssignment(), activitypub.RepoOutbox)
+			})
+		}
+
 		// Notifications
 		m.Group("/notifications", func() {
 			m.Combo("").
@@ -809,6 +857,7 @@ func Routes() *web.Route {
 							m.Combo("/{id}", reqToken()).Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueCommentDeprecated).
 								Delete(repo.DeleteIssueCommentDeprecated)
 						})
+						m.Get("/timeline", repo.ListIssueCommentsAndTimeline)
 						m.Group("/labels", func() {
 							m.Combo("").Get(repo.ListIssueLabels).
 								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
@@ -921,6 +970,8 @@ func Routes() *web.Route {
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
+					m.Combo("/{sha}/annotations").Get(repo.GetCommitStatusAnnotations).
+						Post(reqToken(), bind(api.CreateStatusAnnotationsOption{}), repo.CreateCommitStatusAnnotations)
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/commits", func() {
 					m.Get("", repo.GetAllCommits)
@@ -938,6 +989,7 @@ func Routes() *web.Route {
 					m.Get("/trees/{sha}", context.RepoRefForAPI, repo.GetTree)
 					m.Get("/blobs/{sha}", context.RepoRefForAPI, repo.GetBlob)
 					m.Get("/tags/{sha}", context.RepoRefForAPI, repo.GetTag)
+					m.Post("/tokens", reqToken(), bind(api.CreateGitTokenOption{}), repo.CreateGitToken)
 				}, reqRepoReader(models.UnitTypeCode))
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContentsList)
@@ -959,6 +1011,7 @@ func Routes() *web.Route {
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(false), repo.GetIssueTemplates)
 				m.Get("/languages", reqRepoReader(models.UnitTypeCode), repo.GetLanguages)
+				m.Get("/insights/lifecycle", reqAnyRepoReader(), repo.GetLifecycleMetrics)
 			}, repoAssignment())
 		})
 
@@ -1044,6 +1097,12 @@ func Routes() *web.Route {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
+			m.Group("/repos", func() {
+				m.Post("/{operation}", bind(api.RepoMaintenanceOption{}), admin.RunRepositoriesMaintenance)
+				m.Post("/{username}/{reponame}/{operation}", bind(api.RepoMaintenanceOption{}), admin.RunRepositoryMaintenance)
+			})
+			m.Combo("/indexers/{indexer}/reindex").Get(admin.GetReindex).
+				Post(bind(api.ReindexOption{}), admin.Reindex)
 			m.Group("/unadopted", func() {
 				m.Get("", admin.ListUnadoptedRepositories)
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
@@ -0,0 +1,40 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/webhook"
+)
+
+// WebhookSchema returns the JSON schema of the payload of a webhook event
+func WebhookSchema(ctx *context.APIContext) {
+	// swagger:operation GET /webhook/schema/{event} miscellaneous getWebhookSchema
+	// ---
+	// summary: Get the JSON schema of the payload of a webhook event
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: event
+	//   in: path
+	//   description: name of the webhook event, e.g. "push" or "pull_request_sync"
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WebhookSchema"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	schema := webhook.PayloadSchema(models.HookEventType(ctx.Params(":event")))
+	if schema == nil {
+		ctx.NotFound()
+		return
+	}
+	ctx.JSON(http.StatusOK, schema)
+}
@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
@@ -181,6 +182,9 @@ func Create(ctx *context.APIContext) {
 	if form.Visibility != "" {
 		visibility = api.VisibilityModes[form.Visibility]
 	}
+	if setting.Service.ForceOrgVisibility {
+		visibility = setting.Service.DefaultOrgVisibilityMode
+	}
 
 	org := &models.User{
 		Name:                      form.UserName,
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
@@ -186,6 +187,7 @@ func CreateTeam(ctx *context.APIContext) {
 		}
 		return
 	}
+	notification.NotifyCreateTeam(ctx.User, ctx.Org.Organization, team)
 
 	ctx.JSON(http.StatusCreated, convert.ToTeam(team))
 }
@@ -264,10 +266,15 @@ func EditTeam(ctx *context.APIContext) {
 		}
 	}
 
+	loadTeamOrg(ctx)
+	if ctx.Written() {
+		return
+	}
 	if err := models.UpdateTeam(team, isAuthChanged, isIncludeAllChanged); err != nil {
 		ctx.Error(http.StatusInternalServerError, "EditTeam", err)
 		return
 	}
+	notification.NotifyUpdateTeam(ctx.User, ctx.Org.Organization, team)
 	ctx.JSON(http.StatusOK, convert.ToTeam(team))
 }
 
@@ -287,13 +294,31 @@ func DeleteTeam(ctx *context.APIContext) {
 	//   "204":
 	//     description: team deleted
 
+	loadTeamOrg(ctx)
+	if ctx.Written() {
+		return
+	}
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteTeam", err)
 		return
 	}
+	notification.NotifyDeleteTeam(ctx.User, ctx.Org.Organization, ctx.Org.Team)
 	ctx.Status(http.StatusNoContent)
 }
 
+// loadTeamOrg loads the organization of the team of the request, whose routes do not assign it
+func loadTeamOrg(ctx *context.APIContext) {
+	if ctx.Org.Organization != nil {
+		return
+	}
+	org, err := models.GetUserByID(ctx.Org.Team.OrgID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+		return
+	}
+	ctx.Org.Organization = org
+}
+
 // GetTeamMembers api for get a team's members
 func GetTeamMembers(ctx *context.APIContext) {
 	// swagger:operation GET /teams/{id}/members organization orgListTeamMembers
@@ -412,10 +437,15 @@ func AddTeamMember(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	loadTeamOrg(ctx)
+	if ctx.Written() {
+		return
+	}
 	if err := ctx.Org.Team.AddMember(u.ID); err != nil {
 		ctx.Error(http.StatusInternalServerError, "AddMember", err)
 		return
 	}
+	notification.NotifyAddTeamMember(ctx.User, ctx.Org.Organization, ctx.Org.Team, u)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -449,10 +479,15 @@ func RemoveTeamMember(ctx *context.APIContext) {
 		return
 	}
 
+	loadTeamOrg(ctx)
+	if ctx.Written() {
+		return
+	}
 	if err := ctx.Org.Team.RemoveMember(u.ID); err != nil {
 		ctx.Error(http.StatusInternalServerError, "RemoveMember", err)
 		return
 	}
+	notification.NotifyRemoveTeamMember(ctx.User, ctx.Org.Organization, ctx.Org.Team, u)
 	ctx.Status(http.StatusNoContent)
 }
 
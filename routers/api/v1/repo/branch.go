@@ -8,6 +8,7 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
@@ -207,6 +208,8 @@ func CreateBranch(ctx *context.APIContext) {
 	//     description: The old branch does not exist.
 	//   "409":
 	//     description: The branch with the same name already exists.
+	//   "422":
+	//     description: The branch name does not follow the naming convention of the repository.
 
 	opt := web.GetForm(ctx).(*api.CreateBranchRepoOption)
 	if ctx.Repo.Repository.IsEmpty {
@@ -218,7 +221,20 @@ func CreateBranch(ctx *context.APIContext) {
 		opt.OldBranchName = ctx.Repo.Repository.DefaultBranch
 	}
 
-	err := repo_module.CreateNewBranch(ctx.User, ctx.Repo.Repository, opt.OldBranchName, opt.BranchName)
+	policy, err := models.GetPushPolicyByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPushPolicyByRepoID", err)
+		return
+	}
+	if allowed, err := policy.IsBranchNameAllowed(opt.BranchName, ctx.Repo.Repository.DefaultBranch); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsBranchNameAllowed", err)
+		return
+	} else if !allowed && policy.BranchNameMode.IsBlocking() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Branch name does not follow the naming convention of the repository, it has to match one of: %s", strings.Join(policy.BranchNamePatternList(), ", ")))
+		return
+	}
+
+	err = repo_module.CreateNewBranch(ctx.User, ctx.Repo.Repository, opt.OldBranchName, opt.BranchName)
 
 	if err != nil {
 		if models.IsErrBranchDoesNotExist(err) {
@@ -542,6 +558,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		RequireSignedCommits:          form.RequireSignedCommits,
 		ProtectedFilePatterns:         form.ProtectedFilePatterns,
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
+		EnableMergeQueue:              form.EnableMergeQueue,
+		RequireCodeOwnerReviews:       form.RequireCodeOwnerReviews,
 	}
 
 	err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
@@ -690,6 +708,14 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
+	if form.RequireCodeOwnerReviews != nil {
+		protectBranch.RequireCodeOwnerReviews = *form.RequireCodeOwnerReviews
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = models.GetUserIDsByNames(form.PushWhitelistUsernames, false)
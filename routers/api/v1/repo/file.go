@@ -51,6 +51,8 @@ func GetRawFile(ctx *context.APIContext) {
 	// responses:
 	//   200:
 	//     description: success
+	//   "304":
+	//     description: the file has not been modified since the time given by If-Modified-Since
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 
@@ -83,6 +85,9 @@ func GetRawFile(ctx *context.APIContext) {
 		}
 		return
 	}
+	if repo.HandleLastModified(ctx.Context, commit, ctx.Repo.TreePath) {
+		return
+	}
 	if err = repo.ServeBlob(ctx.Context, blob); err != nil {
 		ctx.Error(http.StatusInternalServerError, "ServeBlob", err)
 	}
@@ -108,9 +113,13 @@ func GetArchive(ctx *context.APIContext) {
 	//   required: true
 	// - name: archive
 	//   in: path
-	//   description: the git reference for download with attached archive format (e.g. master.zip)
+	//   description: the git reference for download with attached archive format: .zip, .tar.gz or .bundle (e.g. master.zip)
 	//   type: string
 	//   required: true
+	// - name: subdir
+	//   in: query
+	//   description: only archive this directory, keeping its path inside the archive (not available for bundles)
+	//   type: string
 	// responses:
 	//   200:
 	//     description: success
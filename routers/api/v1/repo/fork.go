@@ -123,7 +123,11 @@ func CreateFork(ctx *context.APIContext) {
 
 	fork, err := repo_service.ForkRepository(ctx.User, forker, repo, repo.Name, repo.Description)
 	if err != nil {
-		ctx.Error(http.StatusInternalServerError, "ForkRepository", err)
+		if models.IsErrReachLimitOfRepo(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("You have already reached your limit of %d repositories.", forker.MaxCreationLimit()))
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ForkRepository", err)
+		}
 		return
 	}
 
@@ -0,0 +1,86 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// CreateGitToken creates a one-time git token for a repository
+func CreateGitToken(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/tokens repository repoCreateGitToken
+	// ---
+	// summary: Create a short-lived git credential which can be used for a single clone, fetch or push over HTTP
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateGitTokenOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/GitToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateGitTokenOption)
+
+	ttl := setting.Repository.GitTokenDefaultTTL
+	if form.TTL != 0 {
+		ttl = time.Duration(form.TTL) * time.Second
+	}
+	if ttl <= 0 || ttl > setting.Repository.GitTokenMaxTTL {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("ttl must be between 1 and %d seconds", int64(setting.Repository.GitTokenMaxTTL/time.Second)))
+		return
+	}
+
+	mode := models.AccessModeRead
+	if form.Mode == "write" {
+		if !ctx.Repo.CanWrite(models.UnitTypeCode) {
+			ctx.Error(http.StatusForbidden, "", "user should have write permission to create a write token")
+			return
+		}
+		if ctx.Repo.Repository.IsArchived || ctx.Repo.Repository.IsMirror {
+			ctx.Error(http.StatusForbidden, "", "the repository is read-only")
+			return
+		}
+		mode = models.AccessModeWrite
+	}
+
+	t, token, err := models.NewGitOneTimeToken(ctx.Repo.Repository.ID, ctx.User.ID, mode, ttl)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "NewGitOneTimeToken", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &api.GitToken{
+		Token:   token,
+		Mode:    form.Mode,
+		Expires: t.ExpiresUnix.AsTime(),
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// GetLifecycleMetrics returns the average times to the first response, closing and merging
+// of the issues and pull requests of a repository
+func GetLifecycleMetrics(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/insights/lifecycle repository repoGetLifecycleMetrics
+	// ---
+	// summary: Get the average times to the first response, closing and merging of the issues and pull requests of a repository
+	// produces:
+	//   - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: type
+	//   in: query
+	//   description: filter by type (issues / pulls), only the ones readable by the user are included
+	//   type: string
+	//   enum: [issues, pulls]
+	// - name: since
+	//   in: query
+	//   description: Only include issues and pull requests created after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	//   required: false
+	// - name: before
+	//   in: query
+	//   description: Only include issues and pull requests created before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoLifecycleMetrics"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	before, since, err := utils.GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	opts := &models.IssueLifecycleOptions{
+		RepoID:        ctx.Repo.Repository.ID,
+		Since:         since,
+		Before:        before,
+		IncludeIssues: ctx.Repo.CanRead(models.UnitTypeIssues),
+		IncludePulls:  ctx.Repo.CanRead(models.UnitTypePullRequests),
+	}
+	switch ctx.Query("type") {
+	case "":
+	case "issues":
+		opts.IncludePulls = false
+	case "pulls":
+		opts.IncludeIssues = false
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid type")
+		return
+	}
+	if !opts.IncludeIssues && !opts.IncludePulls {
+		ctx.NotFound()
+		return
+	}
+
+	stats, err := models.GetRepoIssueLifecycleStats(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepoIssueLifecycleStats", err)
+		return
+	}
+
+	metrics := convert.ToRepoLifecycleMetrics(stats)
+	if since > 0 {
+		metrics.Since = timeutil.TimeStamp(since).AsTimePtr()
+	}
+	if before > 0 {
+		metrics.Before = timeutil.TimeStamp(before).AsTimePtr()
+	}
+	ctx.JSON(http.StatusOK, metrics)
+}
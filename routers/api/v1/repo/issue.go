@@ -16,7 +16,6 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
-	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
@@ -47,6 +46,15 @@ func SearchIssues(ctx *context.APIContext) {
 	//   in: query
 	//   description: search string
 	//   type: string
+	// - name: author
+	//   in: query
+	//   description: filter (issues / pulls) created by the user with this username
+	//   type: string
+	// - name: sort
+	//   in: query
+	//   description: sort order of the results, the issues of priority_repo_id coming first if not set
+	//   type: string
+	//   enum: [relevance, recentupdate, newest, oldest, leastupdate, mostcomment, leastcomment]
 	// - name: priority_repo_id
 	//   in: query
 	//   description: repository to prioritize in the results
@@ -112,58 +120,10 @@ func SearchIssues(ctx *context.APIContext) {
 		isClosed = util.OptionalBoolFalse
 	}
 
-	// find repos user can access (for issue search)
-	repoIDs := make([]int64, 0)
-	opts := &models.SearchRepoOptions{
-		ListOptions: models.ListOptions{
-			PageSize: 15,
-		},
-		Private:     false,
-		AllPublic:   true,
-		TopicOnly:   false,
-		Collaborate: util.OptionalBoolNone,
-		// This needs to be a column that is not nil in fixtures or
-		// MySQL will return different results when sorting by null in some cases
-		OrderBy: models.SearchOrderByAlphabetically,
-		Actor:   ctx.User,
-	}
-	if ctx.IsSigned {
-		opts.Private = true
-		opts.AllLimited = true
-	}
-
-	for page := 1; ; page++ {
-		opts.Page = page
-		repos, count, err := models.SearchRepositoryByName(opts)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, "SearchRepositoryByName", err)
-			return
-		}
-
-		if len(repos) == 0 {
-			break
-		}
-		log.Trace("Processing next %d repos of %d", len(repos), count)
-		for _, repo := range repos {
-			repoIDs = append(repoIDs, repo.ID)
-		}
-	}
-
-	var issues []*models.Issue
-	var filteredCount int64
-
 	keyword := strings.Trim(ctx.Query("q"), " ")
 	if strings.IndexByte(keyword, 0) >= 0 {
 		keyword = ""
 	}
-	var issueIDs []int64
-	var labelIDs []int64
-	if len(keyword) > 0 && len(repoIDs) > 0 {
-		if issueIDs, err = issue_indexer.SearchIssuesByKeyword(repoIDs, keyword); err != nil {
-			ctx.Error(http.StatusInternalServerError, "SearchIssuesByKeyword", err)
-			return
-		}
-	}
 
 	var isPull util.OptionalBool
 	switch ctx.Query("type") {
@@ -190,54 +150,68 @@ func SearchIssues(ctx *context.APIContext) {
 		limit = setting.API.MaxResponseItems
 	}
 
-	// Only fetch the issues if we either don't have a keyword or the search returned issues
-	// This would otherwise return all issues if no issues were found by the search.
-	if len(keyword) == 0 || len(issueIDs) > 0 || len(labelIDs) > 0 {
-		issuesOpt := &models.IssuesOptions{
-			ListOptions: models.ListOptions{
-				Page:     ctx.QueryInt("page"),
-				PageSize: limit,
-			},
-			RepoIDs:            repoIDs,
-			IsClosed:           isClosed,
-			IssueIDs:           issueIDs,
-			IncludedLabelNames: includedLabelNames,
-			SortType:           "priorityrepo",
-			PriorityRepoID:     ctx.QueryInt64("priority_repo_id"),
-			IsPull:             isPull,
-			UpdatedBeforeUnix:  before,
-			UpdatedAfterUnix:   since,
+	sortType := ctx.Query("sort")
+	switch sortType {
+	case "relevance", "recentupdate", "newest", "oldest", "leastupdate", "mostcomment", "leastcomment":
+	case "":
+		// without a sort, the issues of the prioritized repository come first
+		sortType = "priorityrepo"
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("unknown sort type: %s", sortType))
+		return
+	}
+
+	searchOpts := &issue_service.SearchOptions{
+		ListOptions: models.ListOptions{
+			Page:     ctx.QueryInt("page"),
+			PageSize: limit,
+		},
+		Keyword:            keyword,
+		IsClosed:           isClosed,
+		IsPull:             isPull,
+		IncludedLabelNames: includedLabelNames,
+		UpdatedBeforeUnix:  before,
+		UpdatedAfterUnix:   since,
+		SortType:           sortType,
+		PriorityRepoID:     ctx.QueryInt64("priority_repo_id"),
+	}
+
+	if author := ctx.Query("author"); len(author) > 0 {
+		poster, err := models.GetUserByName(author)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
 		}
+		searchOpts.PosterID = poster.ID
+	}
 
-		// Filter for: Created by User, Assigned to User, Mentioning User, Review of User Requested
+	// Filter for: Created by User, Assigned to User, Mentioning User, Review of User Requested
+	if ctx.IsSigned {
 		if ctx.QueryBool("created") {
-			issuesOpt.PosterID = ctx.User.ID
+			searchOpts.PosterID = ctx.User.ID
 		}
 		if ctx.QueryBool("assigned") {
-			issuesOpt.AssigneeID = ctx.User.ID
+			searchOpts.AssigneeID = ctx.User.ID
 		}
 		if ctx.QueryBool("mentioned") {
-			issuesOpt.MentionedID = ctx.User.ID
+			searchOpts.MentionedID = ctx.User.ID
 		}
 		if ctx.QueryBool("review_requested") {
-			issuesOpt.ReviewRequestedID = ctx.User.ID
-		}
-
-		if issues, err = models.Issues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "Issues", err)
-			return
+			searchOpts.ReviewRequestedID = ctx.User.ID
 		}
+	}
 
-		issuesOpt.ListOptions = models.ListOptions{
-			Page: -1,
-		}
-		if filteredCount, err = models.CountIssues(issuesOpt); err != nil {
-			ctx.Error(http.StatusInternalServerError, "CountIssues", err)
-			return
-		}
+	issues, filteredCount, err := issue_service.SearchIssues(ctx.User, searchOpts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchIssues", err)
+		return
 	}
 
-	ctx.SetLinkHeader(int(filteredCount), setting.UI.IssuePagingNum)
+	ctx.SetLinkHeader(int(filteredCount), limit)
 	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", filteredCount))
 	ctx.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, Link")
 	ctx.JSON(http.StatusOK, convert.ToAPIIssueList(issues))
@@ -535,6 +509,13 @@ func CreateIssue(ctx *context.APIContext) {
 		form.Labels = make([]int64, 0)
 	}
 
+	if len(assigneeIDs) == 0 {
+		if assigneeIDs, err = issue_service.DefaultAssigneeIDs(ctx.Repo.Repository); err != nil {
+			ctx.Error(http.StatusInternalServerError, "DefaultAssigneeIDs", err)
+			return
+		}
+	}
+
 	if err := issue_service.NewIssue(ctx.Repo.Repository, issue, form.Labels, nil, assigneeIDs); err != nil {
 		if models.IsErrUserDoesNotHaveAccessToRepo(err) {
 			ctx.Error(http.StatusBadRequest, "UserDoesNotHaveAccessToRepo", err)
@@ -653,11 +634,10 @@ func EditIssue(ctx *context.APIContext) {
 			deadlineUnix = timeutil.TimeStamp(deadline.Unix())
 		}
 
-		if err := models.UpdateIssueDeadline(issue, deadlineUnix, ctx.User); err != nil {
-			ctx.Error(http.StatusInternalServerError, "UpdateIssueDeadline", err)
+		if err := issue_service.ChangeDeadline(issue, ctx.User, deadlineUnix); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeDeadline", err)
 			return
 		}
-		issue.DeadlineUnix = deadlineUnix
 	}
 
 	// Add/delete assignees
@@ -785,8 +765,8 @@ func UpdateIssueDeadline(ctx *context.APIContext) {
 		deadlineUnix = timeutil.TimeStamp(deadline.Unix())
 	}
 
-	if err := models.UpdateIssueDeadline(issue, deadlineUnix, ctx.User); err != nil {
-		ctx.Error(http.StatusInternalServerError, "UpdateIssueDeadline", err)
+	if err := issue_service.ChangeDeadline(issue, ctx.User, deadlineUnix); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ChangeDeadline", err)
 		return
 	}
 
@@ -92,6 +92,160 @@ func ListIssueComments(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, &apiComments)
 }
 
+// ListIssueCommentsAndTimeline list all the comments and events of an issue
+func ListIssueCommentsAndTimeline(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/timeline issue issueGetCommentsAndTimeline
+	// ---
+	// summary: List all comments and events on an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: since
+	//   in: query
+	//   description: if provided, only comments updated since the specified time are returned.
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// - name: before
+	//   in: query
+	//   description: if provided, only comments updated before the provided time are returned.
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TimelineList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	before, since, err := utils.GetQueryBeforeSince(ctx)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+	issue.Repo = ctx.Repo.Repository
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		IssueID:     issue.ID,
+		Since:       since,
+		Before:      before,
+		Type:        models.CommentTypeUnknown,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindComments", err)
+		return
+	}
+
+	if err := models.CommentList(comments).LoadPosters(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadPosters", err)
+		return
+	}
+
+	apiComments := make([]*api.TimelineComment, 0, len(comments))
+	for _, comment := range comments {
+		// code comments are listed with the reviews they belong to
+		if comment.Type == models.CommentTypeCode {
+			continue
+		}
+		comment.Issue = issue
+		visible, err := loadTimelineComment(ctx, issue, comment)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "loadTimelineComment", err)
+			return
+		} else if visible {
+			apiComments = append(apiComments, convert.ToTimelineComment(comment))
+		}
+	}
+	ctx.JSON(http.StatusOK, &apiComments)
+}
+
+// loadTimelineComment loads the objects related to an event of the timeline of an issue.
+// It returns false if the event refers to an issue of another repository the doer cannot read.
+func loadTimelineComment(ctx *context.APIContext, issue *models.Issue, comment *models.Comment) (bool, error) {
+	canRead := func(repoID int64, isPull bool) (bool, error) {
+		if repoID == issue.RepoID {
+			return true, nil
+		}
+		repo, err := models.GetRepositoryByID(repoID)
+		if err != nil {
+			return false, err
+		}
+		perm, err := models.GetUserRepoPermission(repo, ctx.User)
+		if err != nil {
+			return false, err
+		}
+		return perm.CanReadIssuesOrPulls(isPull), nil
+	}
+
+	switch comment.Type {
+	case models.CommentTypeLabel:
+		return true, comment.LoadLabel()
+	case models.CommentTypeMilestone:
+		return true, comment.LoadMilestone()
+	case models.CommentTypeAssignees, models.CommentTypeReviewRequest:
+		return true, comment.LoadAssigneeUserAndTeam()
+	case models.CommentTypeAddDependency, models.CommentTypeRemoveDependency:
+		if err := comment.LoadDepIssueDetails(); err != nil {
+			if models.IsErrIssueNotExist(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		return canRead(comment.DependentIssue.RepoID, comment.DependentIssue.IsPull)
+	}
+
+	if models.CommentTypeIsRef(comment.Type) && comment.RefRepoID != 0 {
+		if visible, err := canRead(comment.RefRepoID, comment.RefIsPull); err != nil || !visible {
+			return false, err
+		}
+		if err := comment.LoadRefIssue(); err != nil {
+			return false, err
+		}
+		if comment.RefCommentID > 0 {
+			if err := comment.LoadRefComment(); err != nil && !models.IsErrCommentNotExist(err) {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
 // ListRepoIssueComments returns all issue-comments for a repo
 func ListRepoIssueComments(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/issues/comments issue issueGetRepoComments
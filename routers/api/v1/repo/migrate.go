@@ -141,7 +141,7 @@ func Migrate(ctx *context.APIContext) {
 		CloneAddr:      remoteAddr,
 		RepoName:       form.RepoName,
 		Description:    form.Description,
-		Private:        form.Private || setting.Repository.ForcePrivate,
+		Private:        form.Private || repoOwner.IsRepoPrivateForced(),
 		Mirror:         form.Mirror,
 		AuthUsername:   form.AuthUsername,
 		AuthPassword:   form.AuthPassword,
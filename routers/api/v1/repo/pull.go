@@ -22,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	issue_service "code.gitea.io/gitea/services/issue"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -519,11 +520,10 @@ func EditPullRequest(ctx *context.APIContext) {
 			deadlineUnix = timeutil.TimeStamp(deadline.Unix())
 		}
 
-		if err := models.UpdateIssueDeadline(issue, deadlineUnix, ctx.User); err != nil {
-			ctx.Error(http.StatusInternalServerError, "UpdateIssueDeadline", err)
+		if err := issue_service.ChangeDeadline(issue, ctx.User, deadlineUnix); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeDeadline", err)
 			return
 		}
-		issue.DeadlineUnix = deadlineUnix
 	}
 
 	// Add/delete assignees
@@ -716,6 +716,8 @@ func MergePullRequest(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     description: The pull request has been added to the merge queue of its base branch.
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -800,6 +802,14 @@ func MergePullRequest(ctx *context.APIContext) {
 		return
 	}
 
+	if isQueueEnabled, err := mergequeue.IsEnabled(pr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsMergeQueueEnabled", err)
+		return
+	} else if isQueueEnabled {
+		addToMergeQueue(ctx, form, pr)
+		return
+	}
+
 	if err := pull_service.CheckPRReadyToMerge(pr, false); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.Error(http.StatusInternalServerError, "CheckPRReadyToMerge", err)
@@ -879,6 +889,59 @@ func MergePullRequest(ctx *context.APIContext) {
 	ctx.Status(http.StatusOK)
 }
 
+// addToMergeQueue adds a pull request to the merge queue of its base branch instead of merging it
+func addToMergeQueue(ctx *context.APIContext, form *auth.MergePullRequestForm, pr *models.PullRequest) {
+	if err := pull_service.CheckPRReadyToQueue(pr); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			ctx.Error(http.StatusInternalServerError, "CheckPRReadyToQueue", err)
+			return
+		}
+		ctx.Error(http.StatusMethodNotAllowed, "PR is not ready to be merged", err)
+		return
+	}
+
+	style := models.MergeStyle(form.Do)
+	if len(style) == 0 {
+		style = models.MergeStyleMerge
+	}
+	prUnit, err := ctx.Repo.Repository.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUnit", err)
+		return
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", style))
+		return
+	}
+
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IssueNoDependenciesLeft", err)
+		return
+	} else if !noDeps {
+		ctx.Error(http.StatusMethodNotAllowed, "PR is not ready to be merged", "The pull request has open dependencies")
+		return
+	}
+
+	// an empty message is replaced by the default message of the style when merging
+	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(message) > 0 && len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if err := mergequeue.Add(ctx.User, pr, style, message); err != nil {
+		if models.IsErrPullAlreadyInMergeQueue(err) {
+			ctx.Error(http.StatusConflict, "AddToMergeQueue", "The pull request is already in the merge queue")
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+		return
+	}
+
+	log.Trace("Pull request added to the merge queue: %d", pr.ID)
+	ctx.Status(http.StatusAccepted)
+}
+
 func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption) (*models.User, *models.Repository, *git.Repository, *git.CompareInfo, string, string) {
 	baseRepo := ctx.Repo.Repository
 
@@ -933,6 +996,14 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 
 	// Check if current user has fork of repository or in the same repository.
 	headRepo, has := models.HasForkedRepo(headUser.ID, baseRepo.ID)
+	if !has && !isSameRepo {
+		// the head may be further in the fork network, like a fork of a fork
+		headRepo, has, err = models.FindRepoInForkNetwork(headUser.ID, baseRepo)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "FindRepoInForkNetwork", err)
+			return nil, nil, nil, nil, "", ""
+		}
+	}
 	if !has && !isSameRepo {
 		log.Trace("parseCompareInfo[%d]: does not have fork or in same repository", baseRepo.ID)
 		ctx.NotFound("HasForkedRepo")
@@ -944,7 +1015,7 @@ func parseCompareInfo(ctx *context.APIContext, form api.CreatePullRequestOption)
 		headRepo = ctx.Repo.Repository
 		headGitRepo = ctx.Repo.GitRepo
 	} else {
-		headGitRepo, err = git.OpenRepository(models.RepoPath(headUser.Name, headRepo.Name))
+		headGitRepo, err = git.OpenRepository(headRepo.RepoPath())
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
 			return nil, nil, nil, nil, "", ""
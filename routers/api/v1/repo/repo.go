@@ -250,7 +250,7 @@ func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateR
 		Gitignores:    opt.Gitignores,
 		License:       opt.License,
 		Readme:        opt.Readme,
-		IsPrivate:     opt.Private,
+		IsPrivate:     opt.Private || owner.IsRepoPrivateForced(),
 		AutoInit:      opt.AutoInit,
 		DefaultBranch: opt.DefaultBranch,
 		TrustModel:    models.ToTrustModel(opt.TrustModel),
@@ -259,6 +259,8 @@ func CreateUserRepo(ctx *context.APIContext, owner *models.User, opt api.CreateR
 	if err != nil {
 		if models.IsErrRepoAlreadyExist(err) {
 			ctx.Error(http.StatusConflict, "", "The repository with the same name already exists.")
+		} else if models.IsErrReachLimitOfRepo(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("You have already reached your limit of %d repositories.", owner.MaxCreationLimit()))
 		} else if models.IsErrNameReserved(err) ||
 			models.IsErrNamePatternNotAllowed(err) {
 			ctx.Error(http.StatusUnprocessableEntity, "", err)
@@ -697,7 +699,11 @@ func updateRepoUnits(ctx *context.APIContext, opts api.EditRepoOption) error {
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeWiki)
 		} else if *opts.HasWiki && opts.ExternalWiki == nil && !models.UnitTypeWiki.UnitGlobalDisabled() {
-			config := &models.UnitConfig{}
+			config := &models.WikiConfig{}
+			if unit, err := repo.GetUnit(models.UnitTypeWiki); err == nil {
+				// keep the settings of the wiki when it is already enabled
+				config = unit.WikiConfig()
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypeWiki,
@@ -891,6 +897,12 @@ func Delete(ctx *context.APIContext) {
 	}
 
 	log.Trace("Repository deleted: %s/%s", owner.Name, repo.Name)
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditRepoDelete,
+		TargetType: models.AuditTargetRepository,
+		TargetID:   repo.ID,
+		TargetName: repo.FullName(),
+	})
 	ctx.Status(http.StatusNoContent)
 }
 
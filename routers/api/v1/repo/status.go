@@ -294,3 +294,110 @@ func GetCombinedCommitStatusByRef(ctx *context.APIContext) {
 
 	ctx.JSON(http.StatusOK, combiStatus)
 }
+
+// CreateCommitStatusAnnotations replaces the annotations of a check for a commit
+func CreateCommitStatusAnnotations(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/statuses/{sha}/annotations repository repoCreateStatusAnnotations
+	// ---
+	// summary: Create the file annotations of a check, replacing its previous annotations of the commit
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: path
+	//   description: sha of the commit
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateStatusAnnotationsOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CommitStatusAnnotationList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateStatusAnnotationsOption)
+	sha := ctx.Params("sha")
+	annotations := make([]*models.CommitStatusAnnotation, 0, len(form.Annotations))
+	for _, option := range form.Annotations {
+		if option == nil {
+			continue
+		}
+		annotations = append(annotations, &models.CommitStatusAnnotation{
+			Path:    option.Path,
+			Line:    option.Line,
+			Level:   option.Level,
+			Message: option.Message,
+		})
+	}
+	if err := models.ReplaceCommitStatusAnnotations(ctx.Repo.Repository, sha, form.Context, annotations); err != nil {
+		if models.IsErrCommitStatusNotExist(err) {
+			ctx.NotFound(err)
+		} else if models.IsErrInvalidCommitStatusAnnotations(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ReplaceCommitStatusAnnotations", err)
+		}
+		return
+	}
+
+	apiAnnotations := make([]*api.CommitStatusAnnotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		apiAnnotations = append(apiAnnotations, convert.ToCommitStatusAnnotation(annotation))
+	}
+	ctx.JSON(http.StatusCreated, apiAnnotations)
+}
+
+// GetCommitStatusAnnotations returns the file annotations of all checks for a commit
+func GetCommitStatusAnnotations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/statuses/{sha}/annotations repository repoListStatusAnnotations
+	// ---
+	// summary: Get the file annotations of the checks of a commit
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: path
+	//   description: sha of the commit
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CommitStatusAnnotationList"
+
+	annotations, err := models.GetCommitStatusAnnotations(ctx.Repo.Repository.ID, ctx.Params("sha"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommitStatusAnnotations", err)
+		return
+	}
+
+	apiAnnotations := make([]*api.CommitStatusAnnotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		apiAnnotations = append(apiAnnotations, convert.ToCommitStatusAnnotation(annotation))
+	}
+	ctx.JSON(http.StatusOK, apiAnnotations)
+}
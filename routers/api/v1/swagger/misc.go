@@ -21,3 +21,10 @@ type swaggerResponseStringSlice struct {
 	// in:body
 	Body []string `json:"body"`
 }
+
+// WebhookSchema
+// swagger:response WebhookSchema
+type swaggerResponseWebhookSchema struct {
+	// in:body
+	Body map[string]interface{} `json:"body"`
+}
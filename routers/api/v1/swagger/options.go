@@ -90,6 +90,12 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateStatusOption api.CreateStatusOption
+	// in:body
+	RepoMaintenanceOption api.RepoMaintenanceOption
+	// in:body
+	ReindexOption api.ReindexOption
+	// in:body
+	CreateStatusAnnotationsOption api.CreateStatusAnnotationsOption
 
 	// in:body
 	CreateTeamOption api.CreateTeamOption
@@ -158,4 +164,7 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	PullReviewRequestOptions api.PullReviewRequestOptions
+
+	// in:body
+	CreateGitTokenOption api.CreateGitTokenOption
 }
@@ -183,6 +183,13 @@ type swaggerResponseCommitStatusList struct {
 	Body []api.CommitStatus `json:"body"`
 }
 
+// CommitStatusAnnotationList
+// swagger:response CommitStatusAnnotationList
+type swaggerResponseCommitStatusAnnotationList struct {
+	// in:body
+	Body []api.CommitStatusAnnotation `json:"body"`
+}
+
 // WatchInfo
 // swagger:response WatchInfo
 type swaggerResponseWatchInfo struct {
@@ -225,6 +232,13 @@ type swaggerGitBlobResponse struct {
 	Body api.GitBlobResponse `json:"body"`
 }
 
+// GitToken
+// swagger:response GitToken
+type swaggerGitToken struct {
+	// in: body
+	Body api.GitToken `json:"body"`
+}
+
 // Commit
 // swagger:response Commit
 type swaggerCommit struct {
@@ -310,6 +324,13 @@ type swaggerLanguageStatistics struct {
 	Body map[string]int64 `json:"body"`
 }
 
+// RepoLifecycleMetrics
+// swagger:response RepoLifecycleMetrics
+type swaggerRepoLifecycleMetrics struct {
+	// in: body
+	Body api.RepoLifecycleMetrics `json:"body"`
+}
+
 // CombinedStatus
 // swagger:response CombinedStatus
 type swaggerCombinedStatus struct {
@@ -66,6 +66,16 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
 		return false
 	}
+	if !models.IsValidHookSignatureType(form.Config["signature_type"]) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature type")
+		return false
+	}
+	if form.Type == models.GOOGLECHAT {
+		if err := webhook.ValidateGoogleChatURL(form.Config["url"]); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "Invalid url: "+err.Error())
+			return false
+		}
+	}
 	return true
 }
 
@@ -102,12 +112,13 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		form.Events = []string{"push"}
 	}
 	w := &models.Webhook{
-		OrgID:       orgID,
-		RepoID:      repoID,
-		URL:         form.Config["url"],
-		ContentType: models.ToHookContentType(form.Config["content_type"]),
-		Secret:      form.Config["secret"],
-		HTTPMethod:  "POST",
+		OrgID:         orgID,
+		RepoID:        repoID,
+		URL:           form.Config["url"],
+		ContentType:   models.ToHookContentType(form.Config["content_type"]),
+		Secret:        form.Config["secret"],
+		SignatureType: form.Config["signature_type"],
+		HTTPMethod:    "POST",
 		HookEvent: &models.HookEvent{
 			ChooseEvents: true,
 			HookEvents: models.HookEvents{
@@ -129,6 +140,10 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(models.HookEventPullRequestSync)),
 				Repository:           util.IsStringInSlice(string(models.HookEventRepository), form.Events, true),
 				Release:              util.IsStringInSlice(string(models.HookEventRelease), form.Events, true),
+				Status:               util.IsStringInSlice(string(models.HookEventStatus), form.Events, true),
+				CommitComment:        util.IsStringInSlice(string(models.HookEventCommitComment), form.Events, true),
+				Team:                 util.IsStringInSlice(string(models.HookEventTeam), form.Events, true),
+				Membership:           util.IsStringInSlice(string(models.HookEventMembership), form.Events, true),
 			},
 			BranchFilter: form.BranchFilter,
 		},
@@ -210,6 +225,12 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webhook) bool {
 	if form.Config != nil {
 		if url, ok := form.Config["url"]; ok {
+			if w.Type == models.GOOGLECHAT {
+				if err := webhook.ValidateGoogleChatURL(url); err != nil {
+					ctx.Error(http.StatusUnprocessableEntity, "", "Invalid url: "+err.Error())
+					return false
+				}
+			}
 			w.URL = url
 		}
 		if ct, ok := form.Config["content_type"]; ok {
@@ -219,6 +240,13 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 			}
 			w.ContentType = models.ToHookContentType(ct)
 		}
+		if st, ok := form.Config["signature_type"]; ok {
+			if !models.IsValidHookSignatureType(st) {
+				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid signature type")
+				return false
+			}
+			w.SignatureType = st
+		}
 
 		if w.Type == models.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -257,6 +285,10 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.PullRequest = util.IsStringInSlice(string(models.HookEventPullRequest), form.Events, true)
 	w.Repository = util.IsStringInSlice(string(models.HookEventRepository), form.Events, true)
 	w.Release = util.IsStringInSlice(string(models.HookEventRelease), form.Events, true)
+	w.Status = util.IsStringInSlice(string(models.HookEventStatus), form.Events, true)
+	w.CommitComment = util.IsStringInSlice(string(models.HookEventCommitComment), form.Events, true)
+	w.Team = util.IsStringInSlice(string(models.HookEventTeam), form.Events, true)
+	w.Membership = util.IsStringInSlice(string(models.HookEventMembership), form.Events, true)
 	w.BranchFilter = form.BranchFilter
 
 	if err := w.UpdateEvent(); err != nil {
@@ -19,6 +19,8 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/user"
+	issue_service "code.gitea.io/gitea/services/issue"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 const (
@@ -32,6 +34,8 @@ const (
 	tplExploreOrganizations base.TplName = "explore/organizations"
 	// tplExploreCode explore code page template
 	tplExploreCode base.TplName = "explore/code"
+	// tplExploreIssues explore issues page template
+	tplExploreIssues base.TplName = "explore/issues"
 )
 
 // Home render home page
@@ -405,6 +409,146 @@ func ExploreCode(ctx *context.Context) {
 	ctx.HTML(200, tplExploreCode)
 }
 
+// ExploreIssues render explore issues page, searching for issues or pull requests across all
+// the repositories the user can read them in
+func ExploreIssues(ctx *context.Context) {
+	ctx.Data["UsersIsDisabled"] = setting.Service.Explore.DisableUsersPage
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["Title"] = ctx.Tr("explore")
+	ctx.Data["PageIsExplore"] = true
+	ctx.Data["PageIsExploreIssues"] = true
+
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	if strings.IndexByte(keyword, 0) >= 0 {
+		keyword = ""
+	}
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	viewType := ctx.Query("type")
+	if viewType != "pulls" {
+		viewType = "issues"
+	}
+	isPull := util.OptionalBoolOf(viewType == "pulls")
+
+	isShowClosed := ctx.Query("state") == "closed"
+	isClosed := util.OptionalBoolOf(isShowClosed)
+	state := "open"
+	if isShowClosed {
+		state = "closed"
+	}
+
+	sortType := ctx.Query("sort")
+	switch sortType {
+	case "relevance", "latest", "oldest", "recentupdate", "leastupdate", "mostcomment", "leastcomment":
+	default:
+		if len(keyword) > 0 {
+			sortType = "relevance"
+		} else {
+			sortType = "recentupdate"
+		}
+	}
+
+	labels := strings.TrimSpace(ctx.Query("labels"))
+	var includedLabelNames []string
+	if len(labels) > 0 {
+		includedLabelNames = strings.Split(labels, ",")
+	}
+
+	opts := &issue_service.SearchOptions{
+		ListOptions: models.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		Keyword:            keyword,
+		IsPull:             isPull,
+		IsClosed:           isClosed,
+		IncludedLabelNames: includedLabelNames,
+		SortType:           sortType,
+	}
+
+	var (
+		issues []*models.Issue
+		total  int64
+		err    error
+	)
+	author := strings.TrimSpace(ctx.Query("author"))
+	if len(author) > 0 {
+		poster, err := models.GetUserByName(author)
+		if err != nil && !models.IsErrUserNotExist(err) {
+			ctx.ServerError("GetUserByName", err)
+			return
+		}
+		if poster != nil {
+			opts.PosterID = poster.ID
+		}
+	}
+	// an unknown author has no issues
+	if len(author) == 0 || opts.PosterID > 0 {
+		issues, total, err = issue_service.SearchIssues(ctx.User, opts)
+		if err != nil {
+			ctx.ServerError("SearchIssues", err)
+			return
+		}
+	}
+
+	commitStatus := make(map[int64]*models.CommitStatus, len(issues))
+	if isPull.IsTrue() {
+		for _, issue := range issues {
+			statuses, _ := pull_service.GetLastCommitStatus(issue.PullRequest)
+			commitStatus[issue.PullRequest.ID] = models.CalcCommitStatus(statuses)
+		}
+	}
+
+	approvalCounts, err := models.IssueList(issues).GetApprovalCounts()
+	if err != nil {
+		ctx.ServerError("ApprovalCounts", err)
+		return
+	}
+	ctx.Data["ApprovalCounts"] = func(issueID int64, typ string) int64 {
+		counts, ok := approvalCounts[issueID]
+		if !ok || len(counts) == 0 {
+			return 0
+		}
+		reviewTyp := models.ReviewTypeApprove
+		if typ == "reject" {
+			reviewTyp = models.ReviewTypeReject
+		} else if typ == "waiting" {
+			reviewTyp = models.ReviewTypeRequest
+		}
+		for _, count := range counts {
+			if count.Type == reviewTyp {
+				return count.Count
+			}
+		}
+		return 0
+	}
+
+	ctx.Data["IssueRefEndNames"], ctx.Data["IssueRefURLs"] = issue_service.GetRefEndNamesAndURLs(issues, "")
+	ctx.Data["Issues"] = issues
+	ctx.Data["CommitStatus"] = commitStatus
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["ViewType"] = viewType
+	ctx.Data["State"] = state
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["SortType"] = sortType
+	ctx.Data["Labels"] = labels
+	ctx.Data["Author"] = author
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "q", "Keyword")
+	pager.AddParam(ctx, "type", "ViewType")
+	pager.AddParam(ctx, "state", "State")
+	pager.AddParam(ctx, "sort", "SortType")
+	pager.AddParam(ctx, "labels", "Labels")
+	pager.AddParam(ctx, "author", "Author")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplExploreIssues)
+}
+
 // NotFound render 404 page
 func NotFound(ctx *context.Context) {
 	ctx.Data["Title"] = "Page Not Found"
@@ -32,11 +32,15 @@ import (
 	"code.gitea.io/gitea/modules/svg"
 	"code.gitea.io/gitea/modules/task"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/services/automerge"
+	indexer_service "code.gitea.io/gitea/services/indexer"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/mergequeue"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/webhook"
+	wiki_service "code.gitea.io/gitea/services/wiki"
 )
 
 func checkRunMode() {
@@ -175,12 +179,24 @@ func GlobalInit(ctx context.Context) {
 	if err := pull_service.Init(); err != nil {
 		log.Fatal("Failed to initialize test pull requests queue: %v", err)
 	}
+	if err := automerge.Init(); err != nil {
+		log.Fatal("Failed to initialize pull requests auto merge queue: %v", err)
+	}
+	if err := mergequeue.Init(); err != nil {
+		log.Fatal("Failed to initialize pull requests merge queue: %v", err)
+	}
 	if err := task.Init(); err != nil {
 		log.Fatal("Failed to initialize task scheduler: %v", err)
 	}
 	if err := repo_migrations.Init(); err != nil {
 		log.Fatal("Failed to initialize repository migrations: %v", err)
 	}
+	if err := wiki_service.InitExport(); err != nil {
+		log.Fatal("Failed to initialize wiki export queue: %v", err)
+	}
+	if err := indexer_service.Init(); err != nil {
+		log.Fatal("Failed to initialize indexer reindex queue: %v", err)
+	}
 	eventsource.GetManager().Init()
 
 	if setting.SSH.StartBuiltinServer {
@@ -26,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
+	router_user "code.gitea.io/gitea/routers/user"
 
 	"gitea.com/go-chi/session"
 	"gopkg.in/ini.v1"
@@ -423,11 +424,7 @@ func InstallPost(ctx *context.Context) {
 			u, _ = models.GetUserByName(u.Name)
 		}
 
-		days := 86400 * setting.LogInRememberDays
-		ctx.SetCookie(setting.CookieUserName, u.Name, days)
-
-		ctx.SetSuperSecureCookie(base.EncodeMD5(u.Rands+u.Passwd),
-			setting.CookieRememberName, u.Name, days)
+		router_user.SetRememberCookie(ctx, u)
 
 		// Auto-login for admin
 		if err = ctx.Session.Set("uid", u.ID); err != nil {
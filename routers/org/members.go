@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -76,6 +77,7 @@ func MembersAction(ctx *context.Context) {
 
 	org := ctx.Org.Organization
 	var err error
+	var removed *models.User
 	switch ctx.Params(":action") {
 	case "private":
 		if ctx.User.ID != uid && !ctx.Org.IsOwner {
@@ -94,6 +96,15 @@ func MembersAction(ctx *context.Context) {
 			ctx.Error(404)
 			return
 		}
+		removed, err = models.GetUserByID(uid)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Redirect(ctx.Org.OrgLink + "/members")
+			} else {
+				ctx.ServerError("GetUserByID", err)
+			}
+			return
+		}
 		err = org.RemoveMember(uid)
 		if models.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
@@ -101,6 +112,7 @@ func MembersAction(ctx *context.Context) {
 			return
 		}
 	case "leave":
+		removed = ctx.User
 		err = org.RemoveMember(ctx.User.ID)
 		if models.IsErrLastOrgOwner(err) {
 			ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
@@ -118,6 +130,17 @@ func MembersAction(ctx *context.Context) {
 		return
 	}
 
+	if removed != nil {
+		ctx.Audit(&models.AuditEvent{
+			Action:      models.AuditOrgMemberRemove,
+			TargetType:  models.AuditTargetOrg,
+			TargetID:    org.ID,
+			TargetName:  org.Name,
+			Description: "member: " + removed.Name,
+		})
+		notification.NotifyRemoveOrgMember(ctx.User, org, removed)
+	}
+
 	if ctx.Params(":action") != "leave" {
 		ctx.Redirect(ctx.Org.OrgLink + "/members")
 	} else {
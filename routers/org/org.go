@@ -26,6 +26,7 @@ const (
 func Create(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("new_org")
 	ctx.Data["DefaultOrgVisibilityMode"] = setting.Service.DefaultOrgVisibilityMode
+	ctx.Data["IsForcedOrgVisibility"] = setting.Service.ForceOrgVisibility
 	if !ctx.User.CanCreateOrganization() {
 		ctx.ServerError("Not allowed", errors.New(ctx.Tr("org.form.create_org_not_allowed")))
 		return
@@ -37,6 +38,8 @@ func Create(ctx *context.Context) {
 func CreatePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*auth.CreateOrgForm)
 	ctx.Data["Title"] = ctx.Tr("new_org")
+	ctx.Data["DefaultOrgVisibilityMode"] = setting.Service.DefaultOrgVisibilityMode
+	ctx.Data["IsForcedOrgVisibility"] = setting.Service.ForceOrgVisibility
 
 	if !ctx.User.CanCreateOrganization() {
 		ctx.ServerError("Not allowed", errors.New(ctx.Tr("org.form.create_org_not_allowed")))
@@ -48,6 +51,10 @@ func CreatePost(ctx *context.Context) {
 		return
 	}
 
+	if setting.Service.ForceOrgVisibility {
+		form.Visibility = setting.Service.DefaultOrgVisibilityMode
+	}
+
 	org := &models.User{
 		Name:                      form.OrgName,
 		IsActive:                  true,
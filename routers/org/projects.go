@@ -0,0 +1,531 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplProjects     base.TplName = "org/projects/list"
+	tplProjectsNew  base.TplName = "org/projects/new"
+	tplProjectsView base.TplName = "org/projects/view"
+)
+
+// canManageProjects returns true if the signed in user can create and manage the projects of the
+// organization, i.e. is an owner or a member of an organization letting its members do so
+func canManageProjects(ctx *context.Context) bool {
+	return ctx.Org.IsOwner || (ctx.Org.IsMember && ctx.Org.Organization.MembersCanManageProjects)
+}
+
+// MustManageProjects checks the signed in user can create and manage the projects of the organization
+func MustManageProjects(ctx *context.Context) {
+	if !canManageProjects(ctx) {
+		ctx.NotFound("MustManageProjects", nil)
+	}
+}
+
+// getProject returns the project of the organization given by the id parameter
+func getProject(ctx *context.Context) *models.Project {
+	p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectByID", err)
+		}
+		return nil
+	}
+	if p.Type != models.ProjectTypeOrganization || p.OwnerID != ctx.Org.Organization.ID {
+		ctx.NotFound("", nil)
+		return nil
+	}
+	return p
+}
+
+// getProjectBoard returns the board, given by the boardID parameter, of the project
+func getProjectBoard(ctx *context.Context, project *models.Project) *models.ProjectBoard {
+	board, err := models.GetProjectBoard(ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetProjectBoard", err)
+		}
+		return nil
+	}
+	if board.ProjectID != project.ID {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"message": fmt.Sprintf("ProjectBoard[%d] is not in Project[%d] as expected", board.ID, project.ID),
+		})
+		return nil
+	}
+	return board
+}
+
+// Projects renders the home page of the projects of an organization
+func Projects(ctx *context.Context) {
+	org := ctx.Org.Organization
+	ctx.Data["Title"] = ctx.Tr("repo.project_board")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	sortType := ctx.QueryTrim("sort")
+	isShowClosed := strings.ToLower(ctx.QueryTrim("state")) == "closed"
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	openCount, err := models.CountProjects(models.ProjectSearchOptions{
+		OwnerID:  org.ID,
+		IsClosed: util.OptionalBoolFalse,
+		Type:     models.ProjectTypeOrganization,
+	})
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	closedCount, err := models.CountProjects(models.ProjectSearchOptions{
+		OwnerID:  org.ID,
+		IsClosed: util.OptionalBoolTrue,
+		Type:     models.ProjectTypeOrganization,
+	})
+	if err != nil {
+		ctx.ServerError("CountProjects", err)
+		return
+	}
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+
+	projects, count, err := models.GetProjects(models.ProjectSearchOptions{
+		OwnerID:  org.ID,
+		Page:     page,
+		IsClosed: util.OptionalBoolOf(isShowClosed),
+		SortType: sortType,
+		Type:     models.ProjectTypeOrganization,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	for i := range projects {
+		projects[i].RenderedContent = string(markdown.Render([]byte(projects[i].Description), ctx.Org.OrgLink, nil))
+	}
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+
+	pager := context.NewPagination(int(count), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "state", "State")
+	ctx.Data["Page"] = pager
+
+	ctx.Data["CanManageProjects"] = canManageProjects(ctx)
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.Data["SortType"] = sortType
+
+	ctx.HTML(http.StatusOK, tplProjects)
+}
+
+// NewProject renders the page creating a project of an organization
+func NewProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// NewProjectPost creates a project of an organization
+func NewProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.new")
+	ctx.Data["PageIsOrgProjects"] = true
+
+	if ctx.HasError() {
+		ctx.Data["ProjectTypes"] = models.GetProjectsConfig()
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	if err := models.NewProject(&models.Project{
+		OwnerID:     ctx.Org.Organization.ID,
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.User.ID,
+		BoardType:   form.BoardType,
+		Type:        models.ProjectTypeOrganization,
+	}); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(ctx.Org.OrgLink + "/projects")
+}
+
+// ChangeProjectStatus updates the status of a project of an organization between "open" and "close"
+func ChangeProjectStatus(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.ChangeProjectStatus(p, ctx.Params(":action") == "close"); err != nil {
+		ctx.ServerError("ChangeProjectStatus", err)
+		return
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/projects?state=" + ctx.Params(":action"))
+}
+
+// DeleteProject deletes a project of an organization
+func DeleteProject(ctx *context.Context) {
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/projects",
+	})
+}
+
+// EditProject renders the page editing a project of an organization
+func EditProject(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProjects"] = true
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+
+	ctx.HTML(http.StatusOK, tplProjectsNew)
+}
+
+// EditProjectPost edits a project of an organization
+func EditProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.CreateProjectForm)
+	ctx.Data["Title"] = ctx.Tr("repo.projects.edit")
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsEditProjects"] = true
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplProjectsNew)
+		return
+	}
+
+	p := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Content
+	if err := models.UpdateProject(p); err != nil {
+		ctx.ServerError("UpdateProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(ctx.Org.OrgLink + "/projects")
+}
+
+// ViewProject renders the board of a project of an organization, with the cards of the issues and
+// pull requests the signed in user can read in their repositories
+func ViewProject(ctx *context.Context) {
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	boards, err := models.GetProjectBoards(project.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+
+	if boards[0].ID == 0 {
+		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
+	}
+
+	if err = boards.LoadRules(); err != nil {
+		ctx.ServerError("LoadRulesOfBoards", err)
+		return
+	}
+
+	if _, err = boards.LoadIssues(); err != nil {
+		ctx.ServerError("LoadIssuesOfBoards", err)
+		return
+	}
+
+	perms := make(map[int64]models.Permission)
+	issueList := make([]*models.Issue, 0, 10)
+	for _, board := range boards {
+		if board.Issues, err = filterReadableIssues(ctx.User, board.Issues, perms); err != nil {
+			ctx.ServerError("filterReadableIssues", err)
+			return
+		}
+		issueList = append(issueList, board.Issues...)
+	}
+	ctx.Data["Issues"] = issueList
+
+	linkedPrsMap := make(map[int64][]*models.Issue)
+	for _, issue := range issueList {
+		var referencedIds []int64
+		for _, comment := range issue.Comments {
+			if comment.RefIssueID != 0 && comment.RefIsPull {
+				referencedIds = append(referencedIds, comment.RefIssueID)
+			}
+		}
+
+		if len(referencedIds) > 0 {
+			if linkedPrs, err := models.Issues(&models.IssuesOptions{
+				IssueIDs: referencedIds,
+				IsPull:   util.OptionalBoolTrue,
+			}); err == nil {
+				if linkedPrs, err = filterReadableIssues(ctx.User, linkedPrs, perms); err == nil {
+					linkedPrsMap[issue.ID] = linkedPrs
+				}
+			}
+		}
+	}
+	ctx.Data["LinkedPRs"] = linkedPrsMap
+
+	movedCards, err := models.GetAutomaticallyMovedProjectIssues(project.ID)
+	if err != nil {
+		ctx.ServerError("GetAutomaticallyMovedProjectIssues", err)
+		return
+	}
+	ctx.Data["MovedCards"] = movedCards
+
+	project.RenderedContent = string(markdown.Render([]byte(project.Description), ctx.Org.OrgLink, nil))
+
+	ctx.Data["Title"] = project.Title
+	ctx.Data["CanManageProjects"] = canManageProjects(ctx)
+	ctx.Data["Project"] = project
+	ctx.Data["Boards"] = boards
+	ctx.Data["ProjectBoardTriggers"] = models.ProjectBoardTriggers
+	ctx.Data["PageIsOrgProjects"] = true
+	ctx.Data["PageIsProjects"] = true
+	ctx.Data["RequiresDraggable"] = true
+
+	ctx.HTML(http.StatusOK, tplProjectsView)
+}
+
+// filterReadableIssues returns the issues the user can read, the permissions are evaluated in the
+// repository of each issue and cached in perms
+func filterReadableIssues(user *models.User, issues []*models.Issue, perms map[int64]models.Permission) ([]*models.Issue, error) {
+	readable := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		perm, err := getIssueRepoPermission(user, issue, perms)
+		if err != nil {
+			return nil, err
+		}
+		if perm.CanReadIssuesOrPulls(issue.IsPull) {
+			readable = append(readable, issue)
+		}
+	}
+	return readable, nil
+}
+
+// getIssueRepoPermission returns the permission of the user in the repository of the issue
+func getIssueRepoPermission(user *models.User, issue *models.Issue, perms map[int64]models.Permission) (models.Permission, error) {
+	if err := issue.LoadRepo(); err != nil {
+		return models.Permission{}, err
+	}
+	perm, has := perms[issue.RepoID]
+	if !has {
+		var err error
+		if perm, err = models.GetUserRepoPermission(issue.Repo, user); err != nil {
+			return models.Permission{}, err
+		}
+		perms[issue.RepoID] = perm
+	}
+	return perm, nil
+}
+
+// AddBoardToProjectPost adds a board to a project of an organization
+func AddBoardToProjectPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.EditProjectBoardForm)
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.NewProjectBoard(&models.ProjectBoard{
+		ProjectID: project.ID,
+		Title:     form.Title,
+		CreatorID: ctx.User.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// EditProjectBoard updates a board of a project of an organization
+func EditProjectBoard(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.EditProjectBoardForm)
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, project)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != "" {
+		board.Title = form.Title
+	}
+
+	if form.Sorting != 0 {
+		board.Sorting = form.Sorting
+	}
+
+	if err := models.UpdateProjectBoard(board); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+
+	if form.Triggers != nil {
+		if err := models.UpdateProjectBoardRules(board, form.Triggers); err != nil {
+			ctx.ServerError("UpdateProjectBoardRules", err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// DeleteProjectBoard deletes a board of a project of an organization
+func DeleteProjectBoard(ctx *context.Context) {
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, project)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoardByID(board.ID); err != nil {
+		ctx.ServerError("DeleteProjectBoardByID", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// SetDefaultProjectBoard sets the board of the uncategorized cards of a project of an organization
+func SetDefaultProjectBoard(ctx *context.Context) {
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+	board := getProjectBoard(ctx, project)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.SetDefaultBoard(project.ID, board.ID); err != nil {
+		ctx.ServerError("SetDefaultBoard", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// MoveIssueAcrossBoards moves a card from one board to another in a project of an organization,
+// the user must be allowed to write the issue or pull request in its repository
+func MoveIssueAcrossBoards(ctx *context.Context) {
+	project := getProject(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var board *models.ProjectBoard
+	if ctx.ParamsInt64(":boardID") == 0 {
+		board = &models.ProjectBoard{
+			ID:        0,
+			ProjectID: 0,
+			Title:     ctx.Tr("repo.projects.type.uncategorized"),
+		}
+	} else {
+		board = getProjectBoard(ctx, project)
+		if ctx.Written() {
+			return
+		}
+	}
+
+	issue, err := models.GetIssueByID(ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound("", nil)
+		} else {
+			ctx.ServerError("GetIssueByID", err)
+		}
+		return
+	}
+	if issue.ProjectID() != project.ID {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	perm, err := getIssueRepoPermission(ctx.User, issue, make(map[int64]models.Permission))
+	if err != nil {
+		ctx.ServerError("getIssueRepoPermission", err)
+		return
+	}
+	if !perm.CanWriteIssuesOrPulls(issue.IsPull) || issue.Repo.IsArchived {
+		ctx.JSON(http.StatusForbidden, map[string]string{
+			"message": "Only authorized users are allowed to perform this action.",
+		})
+		return
+	}
+
+	if err := models.MoveIssueAcrossProjectBoards(issue, board); err != nil {
+		ctx.ServerError("MoveIssueAcrossProjectBoards", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -27,6 +27,8 @@ const (
 	tplSettingsHooks base.TplName = "org/settings/hooks"
 	// tplSettingsLabels template path for render labels settings
 	tplSettingsLabels base.TplName = "org/settings/labels"
+	// tplSettingsPushPolicy template path for render push policy settings
+	tplSettingsPushPolicy base.TplName = "org/settings/push_policy"
 )
 
 // Settings render the main settings page
@@ -35,9 +37,32 @@ func Settings(ctx *context.Context) {
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["CurrentVisibility"] = ctx.Org.Organization.Visibility
 	ctx.Data["RepoAdminChangeTeamAccess"] = ctx.Org.Organization.RepoAdminChangeTeamAccess
+	ctx.Data["MembersCanManageProjects"] = ctx.Org.Organization.MembersCanManageProjects
+	ctx.Data["DefaultRepoVisibility"] = ctx.Org.Organization.DefaultRepoVisibility
+	ctx.Data["ForceRepoPrivate"] = ctx.Org.Organization.ForceRepoPrivate
+	ctx.Data["IsInstanceForcedPrivate"] = setting.Repository.ForcePrivate
+	loadTwoFactorRequirementData(ctx)
+	if ctx.Written() {
+		return
+	}
 	ctx.HTML(200, tplSettingsOptions)
 }
 
+// loadTwoFactorRequirementData lists the members who do not comply with the two-factor requirement of the organization
+func loadTwoFactorRequirementData(ctx *context.Context) {
+	org := ctx.Org.Organization
+	ctx.Data["RequireTwoFactor"] = org.RequireTwoFactor
+	if !org.RequireTwoFactor {
+		return
+	}
+	members, err := org.GetMembersWithoutTwoFactor()
+	if err != nil {
+		ctx.ServerError("GetMembersWithoutTwoFactor", err)
+		return
+	}
+	ctx.Data["MembersWithoutTwoFactor"] = members
+}
+
 // SettingsPost response for settings change submited
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.UpdateOrgSettingForm)
@@ -52,6 +77,22 @@ func SettingsPost(ctx *context.Context) {
 
 	org := ctx.Org.Organization
 
+	// Members could no longer access the organization if they enabled the requirement without complying with it
+	if form.RequireTwoFactor && !org.RequireTwoFactor {
+		if _, err := models.GetTwoFactorByUID(ctx.User.ID); err != nil {
+			if !models.IsErrTwoFactorNotEnrolled(err) {
+				ctx.ServerError("GetTwoFactorByUID", err)
+				return
+			}
+			loadTwoFactorRequirementData(ctx)
+			if ctx.Written() {
+				return
+			}
+			ctx.RenderWithErr(ctx.Tr("org.settings.require_two_factor_not_enrolled"), tplSettingsOptions, &form)
+			return
+		}
+	}
+
 	// Check if organization name has been changed.
 	if org.LowerName != strings.ToLower(form.Name) {
 		isExist, err := models.IsUserExist(org.ID, form.Name)
@@ -88,6 +129,10 @@ func SettingsPost(ctx *context.Context) {
 	org.Website = form.Website
 	org.Location = form.Location
 	org.RepoAdminChangeTeamAccess = form.RepoAdminChangeTeamAccess
+	org.MembersCanManageProjects = form.MembersCanManageProjects
+	org.RequireTwoFactor = form.RequireTwoFactor
+	org.DefaultRepoVisibility = form.DefaultRepoVisibility
+	org.ForceRepoPrivate = form.ForceRepoPrivate
 
 	visibilityChanged := form.Visibility != org.Visibility
 	org.Visibility = form.Visibility
@@ -191,6 +236,12 @@ func DeleteWebhook(ctx *context.Context) {
 	if err := models.DeleteWebhookByOrgID(ctx.Org.Organization.ID, ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteWebhookByOrgID: " + err.Error())
 	} else {
+		ctx.Audit(&models.AuditEvent{
+			Action:     models.AuditWebhookDelete,
+			TargetType: models.AuditTargetWebhook,
+			TargetID:   ctx.QueryInt64("id"),
+			TargetName: ctx.Org.Organization.Name,
+		})
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -207,3 +258,40 @@ func Labels(ctx *context.Context) {
 	ctx.Data["LabelTemplates"] = models.LabelTemplates
 	ctx.HTML(200, tplSettingsLabels)
 }
+
+// PushPolicy render the push policy settings page
+func PushPolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings.push_policy")
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+	ctx.Data["DefaultForbiddenPaths"] = setting.Repository.PushPolicy.DefaultForbiddenPaths
+
+	policy, err := models.GetOrgPushPolicy(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgPushPolicy", err)
+		return
+	}
+	ctx.Data["PushPolicy"] = policy
+
+	ctx.HTML(200, tplSettingsPushPolicy)
+}
+
+// PushPolicyPost updates the push policy of an organization
+func PushPolicyPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.OrgPushPolicyForm)
+
+	policy, err := models.GetOrgPushPolicy(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgPushPolicy", err)
+		return
+	}
+	policy.ForbiddenPaths = strings.TrimSpace(form.ForbiddenPaths)
+	policy.ForbiddenPathsUseDefaults = form.ForbiddenPathsUseDefaults
+	if err := models.UpdateOrgPushPolicy(policy); err != nil {
+		ctx.ServerError("UpdateOrgPushPolicy", err)
+		return
+	}
+	log.Trace("Organization push policy updated: %s", ctx.Org.Organization.Name)
+
+	ctx.Flash.Success(ctx.Tr("org.settings.push_policy_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/push_policy")
+}
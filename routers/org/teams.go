@@ -6,6 +6,7 @@
 package org
 
 import (
+	"fmt"
 	"net/http"
 	"path"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 )
@@ -57,6 +59,8 @@ func TeamsAction(ctx *context.Context) {
 
 	page := ctx.Query("page")
 	var err error
+	var auditAction models.AuditAction
+	member := ctx.User
 	switch ctx.Params(":action") {
 	case "join":
 		if !ctx.Org.IsOwner {
@@ -64,14 +68,26 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = ctx.Org.Team.AddMember(ctx.User.ID)
+		auditAction = models.AuditTeamMemberAdd
 	case "leave":
 		err = ctx.Org.Team.RemoveMember(ctx.User.ID)
+		auditAction = models.AuditTeamMemberRemove
 	case "remove":
 		if !ctx.Org.IsOwner {
 			ctx.Error(404)
 			return
 		}
+		member, err = models.GetUserByID(uid)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Redirect(ctx.Org.OrgLink + "/teams/" + ctx.Org.Team.LowerName)
+			} else {
+				ctx.ServerError("GetUserByID", err)
+			}
+			return
+		}
 		err = ctx.Org.Team.RemoveMember(uid)
+		auditAction = models.AuditTeamMemberRemove
 		page = "team"
 	case "add":
 		if !ctx.Org.IsOwner {
@@ -101,6 +117,8 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("org.teams.add_duplicate_users"))
 		} else {
 			err = ctx.Org.Team.AddMember(u.ID)
+			auditAction = models.AuditTeamMemberAdd
+			member = u
 		}
 
 		page = "team"
@@ -117,6 +135,13 @@ func TeamsAction(ctx *context.Context) {
 			})
 			return
 		}
+	} else if auditAction != "" {
+		auditTeam(ctx, ctx.Org.Team, auditAction, "member: "+member.Name)
+		if auditAction == models.AuditTeamMemberAdd {
+			notification.NotifyAddTeamMember(ctx.User, ctx.Org.Organization, ctx.Org.Team, member)
+		} else {
+			notification.NotifyRemoveTeamMember(ctx.User, ctx.Org.Organization, ctx.Org.Team, member)
+		}
 	}
 
 	switch page {
@@ -129,6 +154,17 @@ func TeamsAction(ctx *context.Context) {
 	}
 }
 
+// auditTeam records a change of a team of the current organization in the audit log
+func auditTeam(ctx *context.Context, team *models.Team, action models.AuditAction, desc string) {
+	ctx.Audit(&models.AuditEvent{
+		Action:      action,
+		TargetType:  models.AuditTargetTeam,
+		TargetID:    team.ID,
+		TargetName:  ctx.Org.Organization.Name + "/" + team.Name,
+		Description: desc,
+	})
+}
+
 // TeamsRepoAction operate team's repository
 func TeamsRepoAction(ctx *context.Context) {
 	if !ctx.Org.IsOwner {
@@ -137,6 +173,7 @@ func TeamsRepoAction(ctx *context.Context) {
 	}
 
 	var err error
+	var auditDesc string
 	action := ctx.Params(":action")
 	switch action {
 	case "add":
@@ -153,12 +190,16 @@ func TeamsRepoAction(ctx *context.Context) {
 			return
 		}
 		err = ctx.Org.Team.AddRepository(repo)
+		auditDesc = "repository added: " + repo.Name
 	case "remove":
 		err = ctx.Org.Team.RemoveRepository(ctx.QueryInt64("repoid"))
+		auditDesc = fmt.Sprintf("repository removed: %d", ctx.QueryInt64("repoid"))
 	case "addall":
 		err = ctx.Org.Team.AddAllRepositories()
+		auditDesc = "all repositories added"
 	case "removeall":
 		err = ctx.Org.Team.RemoveAllRepositories()
+		auditDesc = "all repositories removed"
 	}
 
 	if err != nil {
@@ -166,6 +207,7 @@ func TeamsRepoAction(ctx *context.Context) {
 		ctx.ServerError("TeamsRepoAction", err)
 		return
 	}
+	auditTeam(ctx, ctx.Org.Team, models.AuditTeamUpdate, auditDesc)
 
 	if action == "addall" || action == "removeall" {
 		ctx.JSON(200, map[string]interface{}{
@@ -238,6 +280,8 @@ func NewTeamPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Team created: %s/%s", ctx.Org.Organization.Name, t.Name)
+	auditTeam(ctx, t, models.AuditTeamCreate, "permission: "+t.Authorize.String())
+	notification.NotifyCreateTeam(ctx.User, ctx.Org.Organization, t)
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
 
@@ -340,6 +384,8 @@ func EditTeamPost(ctx *context.Context) {
 		}
 		return
 	}
+	auditTeam(ctx, ctx.Org.Team, models.AuditTeamUpdate, fmt.Sprintf("permission: %s, all repositories: %t", t.Authorize, t.IncludesAllRepositories))
+	notification.NotifyUpdateTeam(ctx.User, ctx.Org.Organization, t)
 	ctx.Redirect(ctx.Org.OrgLink + "/teams/" + t.LowerName)
 }
 
@@ -348,6 +394,8 @@ func DeleteTeam(ctx *context.Context) {
 	if err := models.DeleteTeam(ctx.Org.Team); err != nil {
 		ctx.Flash.Error("DeleteTeam: " + err.Error())
 	} else {
+		auditTeam(ctx, ctx.Org.Team, models.AuditTeamDelete, "")
+		notification.NotifyDeleteTeam(ctx.User, ctx.Org.Organization, ctx.Org.Team)
 		ctx.Flash.Success(ctx.Tr("org.teams.delete_team_success"))
 	}
 
@@ -155,12 +155,88 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 			private.GitQuarantinePath+"="+opts.GitQuarantinePath)
 	}
 
+	pushPolicy, err := newPushPolicyChecker(repo, opts, env)
+	if err != nil {
+		log.Error("Unable to get push policy for %-v Error: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"err": err.Error(),
+		})
+		return
+	}
+
+	var protectedTags []*models.ProtectedTag
+	gotProtectedTags := false
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
 		newCommitID := opts.NewCommitIDs[i]
 		refFullName := opts.RefFullNames[i]
 
+		if err := pushPolicy.check(oldCommitID, newCommitID, refFullName); err != nil {
+			if isErrPushPolicyViolation(err) {
+				ctx.JSON(http.StatusForbidden, map[string]interface{}{
+					"err": err.Error(),
+				})
+				return
+			}
+			log.Error("Unable to check push policy for %s in %-v Error: %v", refFullName, repo, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"err": fmt.Sprintf("Unable to check push policy: %v", err),
+			})
+			return
+		}
+
+		if strings.HasPrefix(refFullName, git.TagPrefix) {
+			// Only deletion and overwriting of existing tags is protected
+			if oldCommitID == git.EmptySHA {
+				continue
+			}
+
+			if !gotProtectedTags {
+				protectedTags, err = repo.GetProtectedTags()
+				if err != nil {
+					log.Error("Unable to get protected tags for %-v Error: %v", repo, err)
+					ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+						"err": err.Error(),
+					})
+					return
+				}
+				gotProtectedTags = true
+			}
+
+			tagName := strings.TrimPrefix(refFullName, git.TagPrefix)
+			// Deploy keys are never on a tag allowlist
+			userID := opts.UserID
+			if opts.IsDeployKey {
+				userID = 0
+			}
+			isAllowed, err := models.IsUserAllowedToControlTag(protectedTags, tagName, userID)
+			if err != nil {
+				log.Error("Unable to check protection of tag %s in %-v Error: %v", tagName, repo, err)
+				ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+					"err": err.Error(),
+				})
+				return
+			}
+
+			if !isAllowed {
+				action := "overwrite"
+				if newCommitID == git.EmptySHA {
+					action = "delete"
+				}
+				log.Warn("Forbidden: Tag: %s in %-v is protected, user %d may not %s it", tagName, repo, opts.UserID, action)
+				if err := models.CreateRepositoryNotice("Rejected attempt by user %d to %s protected tag %s in %s", opts.UserID, action, tagName, repo.FullName()); err != nil {
+					log.Error("CreateRepositoryNotice: %v", err)
+				}
+				ctx.JSON(http.StatusForbidden, map[string]interface{}{
+					"err": fmt.Sprintf("tag %s is protected", tagName),
+				})
+				return
+			}
+			continue
+		}
+
 		branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 		if branchName == repo.DefaultBranch && newCommitID == git.EmptySHA {
 			log.Warn("Forbidden: Branch: %s is the default branch in %-v and cannot be deleted", branchName, repo)
@@ -367,7 +443,9 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
-	ctx.PlainText(http.StatusOK, []byte("ok"))
+	ctx.JSON(http.StatusOK, private.HookPreReceiveResult{
+		Warnings: pushPolicy.warnings,
+	})
 }
 
 // HookPostReceive updates services and users
@@ -0,0 +1,321 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package private
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/secretscan"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	// lfsPointerMaxSize is the maximum size of a Git LFS pointer file
+	lfsPointerMaxSize = 1024
+	// maxSecretWarnings is the maximum number of secrets reported per ref in warn mode
+	maxSecretWarnings = 20
+)
+
+// errPushPolicyViolation is returned when a push is rejected by the repository push policy
+type errPushPolicyViolation struct {
+	message string
+}
+
+func (e *errPushPolicyViolation) Error() string {
+	return e.message
+}
+
+func isErrPushPolicyViolation(err error) bool {
+	_, ok := err.(*errPushPolicyViolation)
+	return ok
+}
+
+// pushPolicyChecker runs the checks configured in the repository push policy against pushed refs
+type pushPolicyChecker struct {
+	repo               *models.Repository
+	policy             *models.PushPolicy
+	commitMessage      *regexp.Regexp
+	blobSizeExemptions models.PathPatterns
+	forbiddenPaths     models.PathPatterns
+	orgForbiddenPaths  models.PathPatterns
+	opts               *private.HookOptions
+	env                []string
+	isAdmin            *bool
+	warnings           []string
+}
+
+func newPushPolicyChecker(repo *models.Repository, opts *private.HookOptions, env []string) (*pushPolicyChecker, error) {
+	policy, err := models.GetPushPolicyByRepoID(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Organizations may forbid paths in all their repositories
+	orgPolicy, err := models.GetOrgPushPolicy(repo.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	// The commit message pattern is compiled once for all the commits of the push
+	var commitMessage *regexp.Regexp
+	if policy.CommitMessageMode.IsEnabled() {
+		if commitMessage, err = policy.CommitMessageRegexp(); err != nil {
+			return nil, fmt.Errorf("invalid commit message pattern: %v", err)
+		}
+	}
+	return &pushPolicyChecker{
+		repo:               repo,
+		policy:             policy,
+		commitMessage:      commitMessage,
+		blobSizeExemptions: policy.BlobSizeExemptionPatterns(),
+		forbiddenPaths:     policy.ForbiddenPathPatterns(),
+		orgForbiddenPaths:  orgPolicy.ForbiddenPathPatterns(),
+		opts:               opts,
+		env:                env,
+	}, nil
+}
+
+// canBypass returns true if the pusher is a repository admin and admins may bypass the check
+func (c *pushPolicyChecker) canBypass(adminBypass bool) (bool, error) {
+	if !adminBypass || c.opts.IsDeployKey {
+		return false, nil
+	}
+	if c.isAdmin == nil {
+		user, err := models.GetUserByID(c.opts.UserID)
+		if err != nil {
+			return false, err
+		}
+		perm, err := models.GetUserRepoPermission(c.repo, user)
+		if err != nil {
+			return false, err
+		}
+		isAdmin := perm.IsAdmin()
+		c.isAdmin = &isAdmin
+	}
+	return *c.isAdmin, nil
+}
+
+// violation records a warning or returns a blocking error depending on the mode
+func (c *pushPolicyChecker) violation(mode models.PushPolicyMode, message string) error {
+	if mode.IsBlocking() {
+		log.Warn("Forbidden: push to %-v rejected by push policy: %s", c.repo, message)
+		return &errPushPolicyViolation{message: message}
+	}
+	c.warnings = append(c.warnings, "warning: "+message)
+	return nil
+}
+
+// check runs all enabled checks on a single pushed ref
+func (c *pushPolicyChecker) check(oldCommitID, newCommitID, refFullName string) error {
+	if newCommitID == git.EmptySHA {
+		return nil
+	}
+
+	if oldCommitID == git.EmptySHA && strings.HasPrefix(refFullName, git.BranchPrefix) {
+		if err := c.checkBranchName(strings.TrimPrefix(refFullName, git.BranchPrefix)); err != nil {
+			return err
+		}
+	}
+
+	if !c.policy.CommitMessageMode.IsEnabled() && !c.policy.IsBlobSizeLimited() && !c.hasForbiddenPaths() &&
+		!c.policy.SecretScanningMode.IsEnabled() {
+		return nil
+	}
+
+	commits, err := git.GetPushedCommits(c.repo.RepoPath(), c.env, newCommitID, 0)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkCommitMessages(commits, refFullName); err != nil {
+		return err
+	}
+	if err := c.checkSecrets(commits, newCommitID, refFullName); err != nil {
+		return err
+	}
+
+	if len(commits) == 0 || (!c.policy.IsBlobSizeLimited() && !c.hasForbiddenPaths()) {
+		return nil
+	}
+	files, err := git.GetPushedFiles(c.repo.RepoPath(), c.env, commits)
+	if err != nil {
+		return err
+	}
+	if err := c.checkForbiddenPaths(files, refFullName); err != nil {
+		return err
+	}
+	return c.checkBlobSizes(files, refFullName)
+}
+
+// checkBranchName checks the name of a new branch against the branch naming policy
+func (c *pushPolicyChecker) checkBranchName(branchName string) error {
+	ok, err := c.policy.IsBranchNameAllowed(branchName, c.repo.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("invalid branch name pattern: %v", err)
+	} else if ok {
+		return nil
+	}
+	return c.violation(c.policy.BranchNameMode, fmt.Sprintf("branch %s does not follow the naming convention of the repository, "+
+		"its name has to match one of: %s", branchName, strings.Join(c.policy.BranchNamePatternList(), ", ")))
+}
+
+func (c *pushPolicyChecker) checkCommitMessages(commits []*git.PushedCommit, refFullName string) error {
+	mode := c.policy.CommitMessageMode
+	if !mode.IsEnabled() {
+		return nil
+	}
+	if bypass, err := c.canBypass(c.policy.CommitMessageAdminBypass); err != nil || bypass {
+		return err
+	}
+
+	expected := "Conventional Commits, e.g. \"feat(scope): description\""
+	if c.policy.CommitMessagePattern != "" {
+		expected = fmt.Sprintf("the pattern %q", c.policy.CommitMessagePattern)
+	}
+
+	for _, commit := range commits {
+		// Merge commits are usually generated and cannot be reworded easily
+		if commit.IsMerge() {
+			continue
+		}
+		if models.IsCommitMessageValid(c.commitMessage, commit.Message) {
+			continue
+		}
+		if err := c.violation(mode, fmt.Sprintf("commit %s on %s has a message not matching %s: %q",
+			base.ShortSha(commit.ID), refFullName, expected, firstLine(commit.Message))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *pushPolicyChecker) checkSecrets(commits []*git.PushedCommit, newCommitID, refFullName string) error {
+	mode := c.policy.SecretScanningMode
+	if !mode.IsEnabled() || len(commits) == 0 {
+		return nil
+	}
+
+	scanner := secretscan.NewScanner(setting.Repository.PushPolicy.SecretPatterns)
+	if !scanner.HasPatterns() {
+		return nil
+	}
+	// Known false positives are listed in the pushed revision itself
+	allowlist, err := git.ReadPushedFile(c.repo.RepoPath(), c.env, newCommitID, secretscan.AllowlistPath)
+	if err != nil {
+		return err
+	}
+	if err := scanner.LoadAllowlist(allowlist); err != nil {
+		return c.violation(mode, fmt.Sprintf("invalid secret scanning allowlist in commit %s on %s: %v",
+			base.ShortSha(newCommitID), refFullName, err))
+	}
+
+	found := 0
+	errStop := errors.New("stop scanning")
+	err = git.GetPushedAddedLines(c.repo.RepoPath(), c.env, commits, func(line *git.PushedLine) error {
+		for _, finding := range scanner.ScanLine(line.Path, line.Content) {
+			message := fmt.Sprintf("possible secret (%s: %s) in file %s line %d of commit %s on %s. "+
+				"If this is a false positive add it to %s.",
+				finding.Pattern, finding.Masked(), line.Path, line.Number, base.ShortSha(line.CommitID), refFullName,
+				secretscan.AllowlistPath)
+			if err := c.violation(mode, message); err != nil {
+				return err
+			}
+			if found++; found >= maxSecretWarnings {
+				return errStop
+			}
+		}
+		return nil
+	})
+	if err == errStop {
+		return nil
+	}
+	return err
+}
+
+// hasForbiddenPaths returns true if the repository or its organization forbid any path
+func (c *pushPolicyChecker) hasForbiddenPaths() bool {
+	return len(c.forbiddenPaths) > 0 || len(c.orgForbiddenPaths) > 0
+}
+
+func (c *pushPolicyChecker) checkForbiddenPaths(files []*git.PushedFile, refFullName string) error {
+	if !c.hasForbiddenPaths() {
+		return nil
+	}
+	// Repository admins may only bypass the paths forbidden by the repository, never those of the organization
+	forbiddenPaths := c.forbiddenPaths
+	if len(forbiddenPaths) > 0 {
+		bypass, err := c.canBypass(c.policy.ForbiddenPathsAdminBypass)
+		if err != nil {
+			return err
+		}
+		if bypass {
+			forbiddenPaths = nil
+		}
+	}
+
+	for _, file := range files {
+		if !forbiddenPaths.Match(file.Path) && !c.orgForbiddenPaths.Match(file.Path) {
+			continue
+		}
+		message := fmt.Sprintf("file %s in commit %s on %s matches a forbidden path. "+
+			"Files like this usually contain secrets, please remove it from the history and rotate any leaked credentials.",
+			file.Path, base.ShortSha(file.CommitID), refFullName)
+		log.Warn("Forbidden: push to %-v rejected by push policy: %s", c.repo, message)
+		return &errPushPolicyViolation{message: message}
+	}
+	return nil
+}
+
+func (c *pushPolicyChecker) checkBlobSizes(files []*git.PushedFile, refFullName string) error {
+	if !c.policy.IsBlobSizeLimited() {
+		return nil
+	}
+
+	for _, file := range files {
+		if file.Size <= c.policy.MaxBlobSize || c.blobSizeExemptions.Match(file.Path) {
+			continue
+		}
+		if isLFSPointer, err := c.isLFSPointer(file); err != nil {
+			return err
+		} else if isLFSPointer {
+			continue
+		}
+
+		trackPattern := file.Path
+		if ext := path.Ext(file.Path); ext != "" {
+			trackPattern = "*" + ext
+		}
+		message := fmt.Sprintf("file %s in commit %s on %s is %s which exceeds the limit of %s. "+
+			"Please track large files with Git LFS (git lfs track %q) and rewrite the commit.",
+			file.Path, base.ShortSha(file.CommitID), refFullName,
+			base.FileSize(file.Size), base.FileSize(c.policy.MaxBlobSize), trackPattern)
+		log.Warn("Forbidden: push to %-v rejected by push policy: %s", c.repo, message)
+		return &errPushPolicyViolation{message: message}
+	}
+	return nil
+}
+
+// isLFSPointer returns true if the blob is a Git LFS pointer file, these are always accepted
+func (c *pushPolicyChecker) isLFSPointer(file *git.PushedFile) (bool, error) {
+	if file.Size > lfsPointerMaxSize {
+		return false, nil
+	}
+	content, err := git.NewCommand("cat-file", "blob", file.BlobID).RunInDirTimeoutEnv(c.env, -1, c.repo.RepoPath())
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(string(content), models.LFSMetaFileIdentifier), nil
+}
+
+func firstLine(message string) string {
+	return strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+}
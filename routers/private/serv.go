@@ -275,6 +275,24 @@ func ServCommand(ctx *context.PrivateContext) {
 			return
 		}
 
+		// the members of an organization requiring two-factor authentication must enroll it first
+		if missing, err := owner.IsTwoFactorMissingFor(user.ID); err != nil {
+			log.Error("Unable to check the two-factor authentication of %-v for %s Error: %v", user, owner.Name, err)
+			ctx.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"results": results,
+				"type":    "InternalServerError",
+				"err":     fmt.Sprintf("Unable to check the two-factor authentication of user %d:%s for %s %v", user.ID, user.Name, owner.Name, err),
+			})
+			return
+		} else if missing {
+			ctx.JSON(http.StatusForbidden, map[string]interface{}{
+				"results": results,
+				"type":    "ErrTwoFactorRequired",
+				"err":     fmt.Sprintf("Organization %s requires two-factor authentication, enroll it first", owner.Name),
+			})
+			return
+		}
+
 		results.UserName = user.Name
 		if !user.KeepEmailPrivate {
 			results.UserEmail = user.Email
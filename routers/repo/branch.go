@@ -7,6 +7,7 @@ package repo
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -54,6 +55,9 @@ func Branches(ctx *context.Context) {
 	ctx.Data["PageIsViewCode"] = true
 	ctx.Data["PageIsBranches"] = true
 
+	onlyMerged := ctx.QueryBool("merged")
+	ctx.Data["OnlyMerged"] = onlyMerged
+
 	page := ctx.QueryInt("page")
 	if page <= 1 {
 		page = 1
@@ -66,54 +70,173 @@ func Branches(ctx *context.Context) {
 
 	skip := (page - 1) * limit
 	log.Debug("Branches: skip: %d limit: %d", skip, limit)
-	branches, branchesCount := loadBranches(ctx, skip, limit)
+	branches, branchesCount := loadBranches(ctx, skip, limit, onlyMerged)
 	if ctx.Written() {
 		return
 	}
 	ctx.Data["Branches"] = branches
 	pager := context.NewPagination(int(branchesCount), git.BranchesRangeSize, page, 5)
 	pager.SetDefaultParams(ctx)
+	if onlyMerged {
+		pager.AddParamString("merged", "true")
+	}
 	ctx.Data["Page"] = pager
 
+	if onlyMerged && ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsMirror && !ctx.Repo.Repository.IsArchived {
+		deletable, err := getDeletableMergedBranchNames(ctx)
+		if err != nil {
+			ctx.ServerError("getDeletableMergedBranchNames", err)
+			return
+		}
+		ctx.Data["DeletableMergedBranches"] = deletable
+	}
+
 	ctx.HTML(200, tplBranch)
 }
 
+// getDeletableMergedBranchNames returns the names of all the unprotected branches which are fully merged into
+// the default branch, to be confirmed before deleting them at once
+func getDeletableMergedBranchNames(ctx *context.Context) ([]string, error) {
+	defaultCommitID, err := ctx.Repo.GitRepo.GetBranchCommitID(ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := getMergedBranchNames(ctx, defaultCommitID)
+	if err != nil {
+		return nil, err
+	}
+	protectedBranches, err := ctx.Repo.Repository.GetProtectedBranches()
+	if err != nil {
+		return nil, err
+	}
+	protected := make(map[string]bool, len(protectedBranches))
+	for _, b := range protectedBranches {
+		protected[b.BranchName] = true
+	}
+
+	deletable := make([]string, 0, len(merged))
+	for _, name := range merged {
+		if !protected[name] {
+			deletable = append(deletable, name)
+		}
+	}
+	return deletable, nil
+}
+
 // DeleteBranchPost responses for delete merged branch
 func DeleteBranchPost(ctx *context.Context) {
 	defer redirect(ctx)
 	branchName := ctx.Query("name")
+	if message := deleteBranchIfAllowed(ctx, branchName); message != "" {
+		ctx.Flash.Error(message)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.branch.deletion_success", branchName))
+}
+
+// deleteBranchIfAllowed deletes the branch unless it is the default or a protected branch,
+// it returns the message of the failure otherwise
+func deleteBranchIfAllowed(ctx *context.Context, branchName string) string {
 	if branchName == ctx.Repo.Repository.DefaultBranch {
 		log.Debug("DeleteBranch: Can't delete default branch '%s'", branchName)
-		ctx.Flash.Error(ctx.Tr("repo.branch.default_deletion_failed", branchName))
-		return
+		return ctx.Tr("repo.branch.default_deletion_failed", branchName)
 	}
 
 	isProtected, err := ctx.Repo.Repository.IsProtectedBranch(branchName, ctx.User)
 	if err != nil {
 		log.Error("DeleteBranch: %v", err)
-		ctx.Flash.Error(ctx.Tr("repo.branch.deletion_failed", branchName))
-		return
+		return ctx.Tr("repo.branch.deletion_failed", branchName)
 	}
 
 	if isProtected {
 		log.Debug("DeleteBranch: Can't delete protected branch '%s'", branchName)
-		ctx.Flash.Error(ctx.Tr("repo.branch.protected_deletion_failed", branchName))
-		return
+		return ctx.Tr("repo.branch.protected_deletion_failed", branchName)
 	}
 
 	if !ctx.Repo.GitRepo.IsBranchExist(branchName) {
 		log.Debug("DeleteBranch: Can't delete non existing branch '%s'", branchName)
-		ctx.Flash.Error(ctx.Tr("repo.branch.deletion_failed", branchName))
-		return
+		return ctx.Tr("repo.branch.deletion_failed", branchName)
 	}
 
 	if err := deleteBranch(ctx, branchName); err != nil {
 		log.Error("DeleteBranch: %v", err)
-		ctx.Flash.Error(ctx.Tr("repo.branch.deletion_failed", branchName))
+		return ctx.Tr("repo.branch.deletion_failed", branchName)
+	}
+	return ""
+}
+
+type deleteMergedBranchFailure struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+type deleteMergedBranchesResult struct {
+	Redirect string                      `json:"redirect"`
+	Deleted  []string                    `json:"deleted"`
+	Failed   []deleteMergedBranchFailure `json:"failed"`
+}
+
+// DeleteMergedBranchesPost deletes the confirmed list of branches which are still fully merged into the
+// default branch, each like DeleteBranchPost. The deleted branches can be restored from the branches page.
+func DeleteMergedBranchesPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.DeleteMergedBranchesForm)
+	result := &deleteMergedBranchesResult{
+		Redirect: ctx.Repo.RepoLink + "/branches",
+		Deleted:  []string{},
+		Failed:   []deleteMergedBranchFailure{},
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.JSON(http.StatusOK, result)
 		return
 	}
 
-	ctx.Flash.Success(ctx.Tr("repo.branch.deletion_success", branchName))
+	defaultCommitID, err := ctx.Repo.GitRepo.GetBranchCommitID(ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		ctx.ServerError("GetBranchCommitID", err)
+		return
+	}
+	mergedNames, err := git.GetMergedBranches(ctx.Repo.Repository.RepoPath(), defaultCommitID)
+	if err != nil {
+		ctx.ServerError("GetMergedBranches", err)
+		return
+	}
+	merged := make(map[string]bool, len(mergedNames))
+	for _, name := range mergedNames {
+		merged[name] = true
+	}
+
+	seen := make(map[string]bool, len(form.Names))
+	for _, branchName := range form.Names {
+		if seen[branchName] {
+			continue
+		}
+		seen[branchName] = true
+
+		// the branch may have got new commits since the list was confirmed
+		message := ctx.Tr("repo.branch.bulk_delete.not_merged", branchName)
+		if merged[branchName] {
+			message = deleteBranchIfAllowed(ctx, branchName)
+		}
+		if message != "" {
+			result.Failed = append(result.Failed, deleteMergedBranchFailure{Name: branchName, Message: message})
+			continue
+		}
+		result.Deleted = append(result.Deleted, branchName)
+	}
+
+	if len(result.Deleted) > 0 {
+		ctx.Flash.Success(ctx.Tr("repo.branch.bulk_delete.success", len(result.Deleted)))
+	}
+	if len(result.Failed) > 0 {
+		messages := make([]string, len(result.Failed))
+		for i, failure := range result.Failed {
+			messages[i] = failure.Message
+		}
+		ctx.Flash.Error(strings.Join(messages, " "))
+	}
+	ctx.JSON(http.StatusOK, result)
 }
 
 // RestoreBranchPost responses for delete merged branch
@@ -203,23 +326,58 @@ func deleteBranch(ctx *context.Context, branchName string) error {
 	return nil
 }
 
-// loadBranches loads branches from the repository limited by page & pageSize.
+// loadBranches loads branches from the repository limited by page & pageSize,
+// only the ones fully merged into the default branch if onlyMerged is set.
 // NOTE: May write to context on error.
-func loadBranches(ctx *context.Context, skip, limit int) ([]*Branch, int) {
+func loadBranches(ctx *context.Context, skip, limit int, onlyMerged bool) ([]*Branch, int) {
 	defaultBranch, err := repo_module.GetBranch(ctx.Repo.Repository, ctx.Repo.Repository.DefaultBranch)
 	if err != nil {
 		log.Error("loadBranches: get default branch: %v", err)
 		ctx.ServerError("GetDefaultBranch", err)
 		return nil, 0
 	}
+	defaultCommit, err := defaultBranch.GetCommit()
+	if err != nil {
+		ctx.ServerError("GetCommit", err)
+		return nil, 0
+	}
 
-	rawBranches, totalNumOfBranches, err := repo_module.GetBranches(ctx.Repo.Repository, skip, limit)
+	var rawBranches []*git.Branch
+	var totalNumOfBranches int
+	if onlyMerged {
+		rawBranches, totalNumOfBranches, err = getMergedBranches(ctx, defaultCommit.ID.String(), skip, limit)
+	} else {
+		rawBranches, totalNumOfBranches, err = repo_module.GetBranches(ctx.Repo.Repository, skip, limit)
+	}
 	if err != nil {
 		log.Error("GetBranches: %v", err)
 		ctx.ServerError("GetBranches", err)
 		return nil, 0
 	}
 
+	// all the branches are compared to the default branch at once
+	commits := make(map[string]*git.Commit, len(rawBranches)+1)
+	commitIDs := make(map[string]string, len(rawBranches))
+	commits[defaultBranch.Name] = defaultCommit
+	for _, rawBranch := range rawBranches {
+		if rawBranch.Name == defaultBranch.Name {
+			continue
+		}
+		commit, err := rawBranch.GetCommit()
+		if err != nil {
+			ctx.ServerError("GetCommit", err)
+			return nil, 0
+		}
+		commits[rawBranch.Name] = commit
+		commitIDs[rawBranch.Name] = commit.ID.String()
+	}
+	divergences, err := repofiles.CountBranchesDivergingCommits(ctx.Repo.Repository, defaultCommit.ID.String(), commitIDs)
+	if err != nil {
+		ctx.ServerError("CountBranchesDivergingCommits", err)
+		return nil, 0
+	}
+	divergences[defaultBranch.Name] = &git.DivergeObject{}
+
 	protectedBranches, err := ctx.Repo.Repository.GetProtectedBranches()
 	if err != nil {
 		ctx.ServerError("GetProtectedBranches", err)
@@ -239,7 +397,7 @@ func loadBranches(ctx *context.Context, skip, limit int) ([]*Branch, int) {
 			continue
 		}
 
-		var branch = loadOneBranch(ctx, rawBranches[i], protectedBranches, repoIDToRepo, repoIDToGitRepo)
+		var branch = loadOneBranch(ctx, rawBranches[i], commits, divergences, protectedBranches, repoIDToRepo, repoIDToGitRepo)
 		if branch == nil {
 			return nil, 0
 		}
@@ -249,7 +407,12 @@ func loadBranches(ctx *context.Context, skip, limit int) ([]*Branch, int) {
 
 	// Always add the default branch
 	log.Debug("loadOneBranch: load default: '%s'", defaultBranch.Name)
-	branches = append(branches, loadOneBranch(ctx, defaultBranch, protectedBranches, repoIDToRepo, repoIDToGitRepo))
+	branches = append(branches, loadOneBranch(ctx, defaultBranch, commits, divergences, protectedBranches, repoIDToRepo, repoIDToGitRepo))
+
+	if onlyMerged {
+		// the default branch is not part of the merged branches
+		return branches, totalNumOfBranches
+	}
 
 	if ctx.Repo.CanWrite(models.UnitTypeCode) {
 		deletedBranches, err := getDeletedBranches(ctx)
@@ -263,17 +426,58 @@ func loadBranches(ctx *context.Context, skip, limit int) ([]*Branch, int) {
 	return branches, totalNumOfBranches - 1
 }
 
-func loadOneBranch(ctx *context.Context, rawBranch *git.Branch, protectedBranches []*models.ProtectedBranch,
-	repoIDToRepo map[int64]*models.Repository,
-	repoIDToGitRepo map[int64]*git.Repository) *Branch {
-	log.Trace("loadOneBranch: '%s'", rawBranch.Name)
+// getMergedBranchNames returns the names of the branches but the default one which are fully merged into it
+func getMergedBranchNames(ctx *context.Context, defaultCommitID string) ([]string, error) {
+	names, err := git.GetMergedBranches(ctx.Repo.Repository.RepoPath(), defaultCommitID)
+	if err != nil {
+		return nil, err
+	}
+	merged := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != ctx.Repo.Repository.DefaultBranch {
+			merged = append(merged, name)
+		}
+	}
+	return merged, nil
+}
 
-	commit, err := rawBranch.GetCommit()
+// getMergedBranches returns the branches fully merged into the default branch, limited by page & pageSize,
+// along with their total number
+func getMergedBranches(ctx *context.Context, defaultCommitID string, skip, limit int) ([]*git.Branch, int, error) {
+	merged, err := getMergedBranchNames(ctx, defaultCommitID)
 	if err != nil {
-		ctx.ServerError("GetCommit", err)
-		return nil
+		return nil, 0, err
+	}
+
+	total := len(merged)
+	if skip > total {
+		skip = total
+	}
+	if limit > 0 && skip+limit < total {
+		merged = merged[skip : skip+limit]
+	} else {
+		merged = merged[skip:]
+	}
+
+	branches := make([]*git.Branch, 0, len(merged))
+	for _, name := range merged {
+		branch, err := ctx.Repo.GitRepo.GetBranch(name)
+		if err != nil {
+			return nil, 0, err
+		}
+		branches = append(branches, branch)
 	}
+	return branches, total, nil
+}
+
+func loadOneBranch(ctx *context.Context, rawBranch *git.Branch, commits map[string]*git.Commit,
+	divergences map[string]*git.DivergeObject,
+	protectedBranches []*models.ProtectedBranch,
+	repoIDToRepo map[int64]*models.Repository,
+	repoIDToGitRepo map[int64]*git.Repository) *Branch {
+	log.Trace("loadOneBranch: '%s'", rawBranch.Name)
 
+	commit := commits[rawBranch.Name]
 	branchName := rawBranch.Name
 	var isProtected bool
 	for _, b := range protectedBranches {
@@ -283,10 +487,10 @@ func loadOneBranch(ctx *context.Context, rawBranch *git.Branch, protectedBranche
 		}
 	}
 
-	divergence, divergenceError := repofiles.CountDivergingCommits(ctx.Repo.Repository, git.BranchPrefix+branchName)
-	if divergenceError != nil {
-		ctx.ServerError("CountDivergingCommits", divergenceError)
-		return nil
+	divergence, ok := divergences[branchName]
+	if !ok {
+		// the branch was removed while the branches were compared
+		divergence = &git.DivergeObject{}
 	}
 
 	pr, err := models.GetLatestPullRequestByHeadInfo(ctx.Repo.Repository.ID, branchName)
@@ -383,6 +587,29 @@ func CreateBranch(ctx *context.Context) {
 		return
 	}
 
+	var namingWarning string
+	if !form.CreateTag {
+		policy, err := models.GetPushPolicyByRepoID(ctx.Repo.Repository.ID)
+		if err != nil {
+			ctx.ServerError("GetPushPolicyByRepoID", err)
+			return
+		}
+		allowed, err := policy.IsBranchNameAllowed(form.NewBranchName, ctx.Repo.Repository.DefaultBranch)
+		if err != nil {
+			ctx.ServerError("IsBranchNameAllowed", err)
+			return
+		}
+		if !allowed {
+			message := ctx.Tr("repo.branch.name_not_allowed", form.NewBranchName, strings.Join(policy.BranchNamePatternList(), ", "))
+			if policy.BranchNameMode.IsBlocking() {
+				ctx.Flash.Error(message)
+				ctx.Redirect(ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL())
+				return
+			}
+			namingWarning = message
+		}
+	}
+
 	var err error
 
 	if form.CreateTag {
@@ -447,5 +674,8 @@ func CreateBranch(ctx *context.Context) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.branch.create_success", form.NewBranchName))
+	if namingWarning != "" {
+		ctx.Flash.Warning(namingWarning)
+	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/src/branch/" + util.PathEscapeSegments(form.NewBranchName))
 }
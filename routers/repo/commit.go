@@ -6,7 +6,9 @@
 package repo
 
 import (
+	"net/url"
 	"path"
+	"strconv"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -116,21 +118,30 @@ func Graph(ctx *context.Context) {
 		return
 	}
 
-	graphCommitsCount, err := ctx.Repo.GetCommitGraphsCount(hidePRRefs, realBranches, files)
-	if err != nil {
-		log.Warn("GetCommitGraphsCount error for generate graph exclude prs: %t branches: %s in %-v, Will Ignore branches and try again. Underlying Error: %v", hidePRRefs, branches, ctx.Repo.Repository, err)
+	limit := ctx.QueryInt("limit")
+	if limit <= 0 || limit > setting.UI.GraphMaxCommitNum {
+		limit = setting.UI.GraphMaxCommitNum
+	}
+	skip := ctx.QueryInt("skip")
+	if page := ctx.QueryInt("page"); skip <= 0 && page > 1 {
+		// links using the old page parameter
+		skip = (page - 1) * limit
+	} else if skip < 0 {
+		skip = 0
+	}
+	// the rows of older commits appended to an already rendered graph continue from row
+	row := ctx.QueryInt("row")
+	if row < 0 {
+		row = 0
+	}
+
+	graph, err := gitgraph.GetCommitGraph(ctx.Repo.GitRepo, skip, limit, row, 0, hidePRRefs, realBranches, files)
+	if err != nil && len(realBranches) > 0 {
+		log.Warn("GetCommitGraph error for generate graph exclude prs: %t branches: %s in %-v, Will Ignore branches and try again. Underlying Error: %v", hidePRRefs, branches, ctx.Repo.Repository, err)
 		realBranches = []string{}
 		branches = []string{}
-		graphCommitsCount, err = ctx.Repo.GetCommitGraphsCount(hidePRRefs, realBranches, files)
-		if err != nil {
-			ctx.ServerError("GetCommitGraphsCount", err)
-			return
-		}
+		graph, err = gitgraph.GetCommitGraph(ctx.Repo.GitRepo, skip, limit, row, 0, hidePRRefs, realBranches, files)
 	}
-
-	page := ctx.QueryInt("page")
-
-	graph, err := gitgraph.GetCommitGraph(ctx.Repo.GitRepo, page, 0, hidePRRefs, realBranches, files)
 	if err != nil {
 		ctx.ServerError("GetCommitGraph", err)
 		return
@@ -155,16 +166,24 @@ func Graph(ctx *context.Context) {
 	ctx.Data["Reponame"] = ctx.Repo.Repository.Name
 	ctx.Data["CommitCount"] = commitsCount
 	ctx.Data["Branch"] = ctx.Repo.BranchName
-	paginator := context.NewPagination(int(graphCommitsCount), setting.UI.GraphMaxCommitNum, page, 5)
-	paginator.AddParam(ctx, "mode", "Mode")
-	paginator.AddParam(ctx, "hide-pr-refs", "HidePRRefs")
-	for _, branch := range branches {
-		paginator.AddParamString("branch", branch)
-	}
-	for _, file := range files {
-		paginator.AddParamString("file", file)
+
+	if graph.HasMore {
+		params := url.Values{}
+		if mode == "monochrome" {
+			params.Set("mode", mode)
+		}
+		if hidePRRefs {
+			params.Set("hide-pr-refs", "true")
+		}
+		for _, branch := range branches {
+			params.Add("branch", branch)
+		}
+		for _, file := range files {
+			params.Add("file", file)
+		}
+		params.Set("skip", strconv.Itoa(graph.NextSkip()))
+		ctx.Data["LoadOlderLink"] = ctx.Repo.RepoLink + "/graph?" + params.Encode()
 	}
-	ctx.Data["Page"] = paginator
 	if ctx.QueryBool("div-only") {
 		ctx.HTML(200, tplGraphDiv)
 		return
@@ -325,6 +344,9 @@ func Diff(ctx *context.Context) {
 
 	ctx.Data["CommitID"] = commitID
 	ctx.Data["AfterCommitID"] = commitID
+	if ctx.Data["PageIsWiki"] == nil {
+		ctx.Data["DiffLinesURL"] = ctx.Repo.RepoLink + "/diff_lines/" + commitID
+	}
 	ctx.Data["Username"] = userName
 	ctx.Data["Reponame"] = repoName
 
@@ -372,6 +394,13 @@ func Diff(ctx *context.Context) {
 		ctx.ServerError("commit.GetTagName", err)
 		return
 	}
+
+	if ctx.Data["PageIsWiki"] == nil {
+		loadCommitComments(ctx, commitID)
+		if ctx.Written() {
+			return
+		}
+	}
 	ctx.HTML(200, tplCommitPage)
 }
 
@@ -0,0 +1,111 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/comments"
+)
+
+// loadCommitComments loads the comments on the commit shown by the diff page
+func loadCommitComments(ctx *context.Context, commitID string) {
+	commitComments, err := models.GetCommitComments(ctx.Repo.Repository.ID, commitID)
+	if err != nil {
+		ctx.ServerError("GetCommitComments", err)
+		return
+	}
+	for _, c := range commitComments {
+		c.RenderedContent = string(markdown.Render([]byte(c.Content), ctx.Repo.RepoLink,
+			ctx.Repo.Repository.ComposeMetas()))
+	}
+	ctx.Data["CommitComments"] = commitComments
+	ctx.Data["CanWriteCode"] = ctx.Repo.CanWrite(models.UnitTypeCode)
+}
+
+// CreateCommitComment creates a comment on a commit
+func CreateCommitComment(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.CreateCommitCommentForm)
+	commit, err := ctx.Repo.GitRepo.GetCommit(ctx.Params(":sha"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetCommit", err)
+		} else {
+			ctx.ServerError("GetCommit", err)
+		}
+		return
+	}
+	commitLink := ctx.Repo.RepoLink + "/commit/" + commit.ID.String()
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(commitLink)
+		return
+	}
+
+	treePath := strings.Trim(form.TreePath, "/")
+	line := form.Line
+	if len(treePath) == 0 {
+		line = 0
+	} else {
+		// a negative line refers to the previous version of the file
+		target := commit
+		if line < 0 && commit.ParentCount() > 0 {
+			if target, err = commit.Parent(0); err != nil {
+				ctx.ServerError("Parent", err)
+				return
+			}
+		}
+		if _, err = target.GetTreeEntryByPath(treePath); err != nil {
+			if git.IsErrNotExist(err) {
+				ctx.Flash.Error(ctx.Tr("repo.commit_comment.path_not_exist", treePath))
+				ctx.Redirect(commitLink)
+			} else {
+				ctx.ServerError("GetTreeEntryByPath", err)
+			}
+			return
+		}
+	}
+
+	comment, err := comments.CreateCommitComment(ctx.User, ctx.Repo.Repository, commit.ID.String(), treePath, line, form.Content)
+	if err != nil {
+		ctx.ServerError("CreateCommitComment", err)
+		return
+	}
+
+	ctx.Redirect(commitLink + "#" + comment.HashTag())
+}
+
+// DeleteCommitComment deletes a comment on a commit, only its poster and code writers are allowed to
+func DeleteCommitComment(ctx *context.Context) {
+	comment, err := models.GetCommitCommentByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommitCommentByID", models.IsErrCommitCommentNotExist, err)
+		return
+	}
+	if !strings.HasPrefix(comment.CommitSHA, ctx.Params(":sha")) {
+		ctx.NotFound("DeleteCommitComment", nil)
+		return
+	}
+
+	if ctx.User.ID != comment.PosterID && !ctx.Repo.CanWrite(models.UnitTypeCode) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
+
+	if err = comments.DeleteCommitComment(ctx.User, ctx.Repo.Repository, comment); err != nil {
+		ctx.ServerError("DeleteCommitComment", err)
+		return
+	}
+
+	ctx.Redirect(ctx.Repo.RepoLink + "/commit/" + comment.CommitSHA)
+}
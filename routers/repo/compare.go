@@ -15,15 +15,18 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/highlight"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/gitdiff"
 )
 
 const (
 	tplCompare     base.TplName = "repo/diff/compare"
 	tplBlobExcerpt base.TplName = "repo/diff/blob_excerpt"
+	tplDiffLines   base.TplName = "repo/diff/lines"
 )
 
 // setPathsCompareContext sets context data for source and raw paths
@@ -87,6 +90,9 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 	// 2. If :headOwner is set - then look for the fork of :baseRepo owned by :headOwner
 	// 3. But... :baseRepo could be a fork of :headOwner's repo - so check that
 	// 4. Now, :baseRepo and :headRepos could be forks of the same repo - so check that
+	// 5. Finally, :headOwner could own any other repo of the fork network, like a fork of a fork - so look for it
+	//
+	// A :headRepo given with its name has to belong to the fork network of :baseRepo as well.
 	//
 	// format: <base branch>...[<head repo>:]<head branch>
 	// base<-head: master...head:feature
@@ -157,6 +163,14 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 			headBranch = headInfos[1]
 			headUser = headRepo.Owner
 			isSameRepo = headRepo.ID == ctx.Repo.Repository.ID
+			if inNetwork, err := models.IsInSameForkNetwork(baseRepo, headRepo); err != nil {
+				ctx.ServerError("IsInSameForkNetwork", err)
+				return nil, nil, nil, nil, "", ""
+			} else if !inNetwork {
+				log.Trace("ParseCompareInfo[%d]: %-v is not in the fork network", baseRepo.ID, headRepo)
+				ctx.NotFound("IsInSameForkNetwork", nil)
+				return nil, nil, nil, nil, "", ""
+			}
 		}
 	} else {
 		ctx.NotFound("CompareAndPullRequest", nil)
@@ -248,12 +262,23 @@ func ParseCompareInfo(ctx *context.Context) (*models.User, *models.Repository, *
 		headRepo, has = models.HasForkedRepo(headUser.ID, baseRepo.ForkID)
 	}
 
-	// 7. Otherwise if we're not the same repo and haven't found a repo give up
+	// 7. If the headUser has another repo further in the fork network use that
+	if !has && !isSameRepo {
+		headRepo, has, err = models.FindRepoInForkNetwork(headUser.ID, baseRepo)
+		if err != nil {
+			ctx.ServerError("FindRepoInForkNetwork", err)
+			return nil, nil, nil, nil, "", ""
+		}
+	}
+
+	// 8. Otherwise if we're not the same repo and haven't found a repo give up
 	if !isSameRepo && !has {
-		ctx.Data["PageIsComparePull"] = false
+		log.Trace("ParseCompareInfo[%d]: %-v has no repository in the fork network", baseRepo.ID, headUser)
+		ctx.NotFound("FindRepoInForkNetwork", nil)
+		return nil, nil, nil, nil, "", ""
 	}
 
-	// 8. Finally open the git repo
+	// 9. Finally open the git repo
 	var headGitRepo *git.Repository
 	if isSameRepo {
 		headRepo = ctx.Repo.Repository
@@ -426,6 +451,7 @@ func PrepareCompareDiff(
 	headCommitID := compareInfo.HeadCommitID
 
 	ctx.Data["AfterCommitID"] = headCommitID
+	ctx.Data["DiffLinesURL"] = headRepo.Link() + "/diff_lines/" + headCommitID
 
 	if headCommitID == compareInfo.MergeBase {
 		ctx.Data["IsNothingToCompare"] = true
@@ -514,14 +540,37 @@ func getBranchesForRepo(user *models.User, repo *models.Repository) (bool, []str
 	return true, branches, nil
 }
 
+// RawCompareDiff dumps the changes between the compared branches as a diff or a patch, combined into one or
+// commit by commit according to the combined query parameter. Diffs are combined and patches are not by default.
+func RawCompareDiff(ctx *context.Context, diffType git.RawDiffType) {
+	_, _, headGitRepo, compareInfo, _, _ := ParseCompareInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	defer headGitRepo.Close()
+
+	combined := ctx.QueryBool("combined", diffType == git.RawDiffNormal)
+	if err := git.GetRepoRawDiffForRange(headGitRepo, compareInfo.MergeBase, compareInfo.HeadCommitID, diffType, combined, ctx.Resp); err != nil {
+		ctx.ServerError("GetRepoRawDiffForRange", err)
+	}
+}
+
 // CompareDiff show different from one commit to another commit
 func CompareDiff(ctx *context.Context) {
+	// {base}...{head}.diff and {base}...{head}.patch return the raw changes
+	if ext := path.Ext(ctx.Params("*")); ext == ".diff" || ext == ".patch" {
+		ctx.SetParams("*", strings.TrimSuffix(ctx.Params("*"), ext))
+		RawCompareDiff(ctx, git.RawDiffType(ext[1:]))
+		return
+	}
+
 	headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch := ParseCompareInfo(ctx)
 
 	if ctx.Written() {
 		return
 	}
 	defer headGitRepo.Close()
+	ctx.Data["RawCompareLink"] = ctx.Repo.RepoLink + "/compare/" + util.PathEscapeSegments(ctx.Params("*"))
 
 	nothingToCompare := PrepareCompareDiff(ctx, headUser, headRepo, headGitRepo, compareInfo, baseBranch, headBranch,
 		gitdiff.GetWhitespaceFlag(ctx.Data["WhitespaceBehavior"].(string)))
@@ -591,36 +640,55 @@ func ExcerptBlob(ctx *context.Context) {
 	filePath := ctx.Query("path")
 	gitRepo := ctx.Repo.GitRepo
 	chunkSize := gitdiff.BlobExcerptChunkSize
+	// a larger chunk size expands progressively: every expansion doubles it for the next one
+	nextChunkSize := 0
+	if lines := ctx.QueryInt("lines"); lines > chunkSize {
+		chunkSize = util.Min(lines, gitdiff.MaxBlobExcerptChunkSize)
+		nextChunkSize = util.Min(chunkSize*2, gitdiff.MaxBlobExcerptChunkSize)
+	}
+	// full expands the whole hidden part at once, up to the start or the end of the file
+	full := ctx.QueryBool("full")
 	commit, err := gitRepo.GetCommit(commitID)
 	if err != nil {
 		ctx.Error(500, "GetCommit")
 		return
 	}
+	blob, err := commit.Tree.GetBlobByPath(filePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetBlobByPath", err)
+		} else {
+			ctx.Error(500, "GetBlobByPath")
+		}
+		return
+	}
 	section := &gitdiff.DiffSection{
-		FileName: filePath,
-		Name:     filePath,
+		FileName:         filePath,
+		Name:             filePath,
+		DisableHighlight: blob.Size() > highlight.SizeLimit,
 	}
-	if direction == "up" && (idxLeft-lastLeft) > chunkSize {
+	if !full && direction == "up" && (idxLeft-lastLeft) > chunkSize {
 		idxLeft -= chunkSize
 		idxRight -= chunkSize
 		leftHunkSize += chunkSize
 		rightHunkSize += chunkSize
-		section.Lines, err = getExcerptLines(commit, filePath, idxLeft-1, idxRight-1, chunkSize)
-	} else if direction == "down" && (idxLeft-lastLeft) > chunkSize {
-		section.Lines, err = getExcerptLines(commit, filePath, lastLeft, lastRight, chunkSize)
+		section.Lines, err = getExcerptLines(blob, idxLeft-1, idxRight-1, chunkSize)
+	} else if !full && direction == "down" && (idxLeft-lastLeft) > chunkSize {
+		section.Lines, err = getExcerptLines(blob, lastLeft, lastRight, chunkSize)
 		lastLeft += chunkSize
 		lastRight += chunkSize
 	} else {
-		section.Lines, err = getExcerptLines(commit, filePath, lastLeft, lastRight, idxRight-lastRight-1)
+		count := idxRight - lastRight - 1
+		if leftHunkSize <= 0 && rightHunkSize <= 0 {
+			// the section after the last hunk ends with the last line of the file
+			count++
+		}
+		section.Lines, err = getExcerptLines(blob, lastLeft, lastRight, count)
 		leftHunkSize = 0
 		rightHunkSize = 0
 		idxLeft = lastLeft
 		idxRight = lastRight
 	}
-	if err != nil {
-		ctx.Error(500, "getExcerptLines")
-		return
-	}
 	if idxRight > lastRight {
 		lineText := " "
 		if rightHunkSize > 0 || leftHunkSize > 0 {
@@ -638,6 +706,7 @@ func ExcerptBlob(ctx *context.Context) {
 				RightIdx:      idxRight,
 				LeftHunkSize:  leftHunkSize,
 				RightHunkSize: rightHunkSize,
+				ChunkSize:     nextChunkSize,
 			}}
 		if direction == "up" {
 			section.Lines = append([]*gitdiff.DiffLine{lineSection}, section.Lines...)
@@ -652,11 +721,55 @@ func ExcerptBlob(ctx *context.Context) {
 	ctx.HTML(200, tplBlobExcerpt)
 }
 
-func getExcerptLines(commit *git.Commit, filePath string, idxLeft int, idxRight int, chunkSize int) ([]*gitdiff.DiffLine, error) {
-	blob, err := commit.Tree.GetBlobByPath(filePath)
+// DiffLines render the lines following the ones already shown of a truncated file in a commit or compare diff
+func DiffLines(ctx *context.Context) {
+	commitID := ctx.Params("sha")
+	ctx.Data["DiffLinesURL"] = ctx.Repo.RepoLink + "/diff_lines/" + commitID
+	renderDiffLines(ctx, ctx.Query("before"), commitID, nil)
+}
+
+// renderDiffLines renders the next chunk of lines of a truncated file, loading
+// the code comments of the issue if one is given so they stay anchored to their lines.
+func renderDiffLines(ctx *context.Context, beforeCommitID, afterCommitID string, issue *models.Issue) {
+	skip := ctx.QueryInt("skip")
+	if skip < 0 {
+		skip = 0
+	}
+
+	diffFile, err := gitdiff.GetDiffFileLines(ctx.Repo.GitRepo, beforeCommitID, afterCommitID,
+		ctx.Query("old_path"), ctx.Query("path"), skip, setting.Git.MaxGitDiffLines,
+		setting.Git.MaxGitDiffLineCharacters, gitdiff.GetWhitespaceFlag(ctx.Data["WhitespaceBehavior"].(string)))
 	if err != nil {
-		return nil, err
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetDiffFileLines", err)
+		} else {
+			ctx.ServerError("GetDiffFileLines", err)
+		}
+		return
 	}
+
+	if issue != nil {
+		diff := &gitdiff.Diff{Files: []*gitdiff.DiffFile{diffFile}}
+		if err = diff.LoadComments(issue, ctx.User); err != nil {
+			ctx.ServerError("LoadComments", err)
+			return
+		}
+
+		annotations, err := models.GetCommitStatusAnnotations(ctx.Repo.Repository.ID, afterCommitID)
+		if err != nil {
+			ctx.ServerError("GetCommitStatusAnnotations", err)
+			return
+		}
+		diff.LoadAnnotations(annotations)
+	}
+
+	ctx.Data["File"] = diffFile
+	ctx.Data["BeforeCommitID"] = beforeCommitID
+	ctx.Data["AfterCommitID"] = afterCommitID
+	ctx.HTML(200, tplDiffLines)
+}
+
+func getExcerptLines(blob *git.Blob, idxLeft int, idxRight int, chunkSize int) ([]*gitdiff.DiffLine, error) {
 	reader, err := blob.DataAsync()
 	if err != nil {
 		return nil, err
@@ -12,11 +12,14 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/charset"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/lfs"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // ServeData download file from io.Reader
@@ -116,6 +119,22 @@ func ServeBlobOrLFS(ctx *context.Context, blob *git.Blob) error {
 	return ServeBlob(ctx, blob)
 }
 
+// HandleLastModified sets the Last-Modified header of a file to the commit time of the last commit which
+// changed it and answers If-Modified-Since requests, it returns whether the response has been written
+func HandleLastModified(ctx *context.Context, commit *git.Commit, treePath string) (handled bool) {
+	var c *git.LastCommitCache
+	if setting.CacheService.LastCommit.Enabled {
+		c = git.NewLastCommitCache(ctx.Repo.Repository.FullName(), ctx.Repo.GitRepo, setting.LastCommitCacheTTLSeconds, cache.GetCache())
+	}
+	lastCommit, err := commit.GetLastCommitForPath(treePath, c)
+	if err != nil {
+		// the file can still be served, just without support for conditional requests
+		log.Error("GetLastCommitForPath(%s) in %s: %v", treePath, ctx.Repo.Repository.FullName(), err)
+		return false
+	}
+	return httpcache.HandleModTimeCache(ctx.Req, ctx.Resp, lastCommit.Committer.When)
+}
+
 // SingleDownload download a file by repos path
 func SingleDownload(ctx *context.Context) {
 	blob, err := ctx.Repo.Commit.GetBlobByPath(ctx.Repo.TreePath)
@@ -127,6 +146,9 @@ func SingleDownload(ctx *context.Context) {
 		}
 		return
 	}
+	if HandleLastModified(ctx, ctx.Repo.Commit, ctx.Repo.TreePath) {
+		return
+	}
 	if err = ServeBlob(ctx, blob); err != nil {
 		ctx.ServerError("ServeBlob", err)
 	}
@@ -143,6 +165,9 @@ func SingleDownloadOrLFS(ctx *context.Context) {
 		}
 		return
 	}
+	if HandleLastModified(ctx, ctx.Repo.Commit, ctx.Repo.TreePath) {
+		return
+	}
 	if err = ServeBlobOrLFS(ctx, blob); err != nil {
 		ctx.ServerError("ServeBlobOrLFS", err)
 	}
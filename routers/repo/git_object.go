@@ -0,0 +1,124 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/repofiles"
+)
+
+// getReachableObjectID returns the ID of the object addressed by the sha parameter,
+// it only accepts full SHAs of objects of the given type reachable from a reference.
+func getReachableObjectID(ctx *context.Context, typ git.ObjectType) (git.SHA1, bool) {
+	sha := ctx.Params("sha")
+	if len(sha) != 40 || !git.SHAPattern.MatchString(sha) {
+		ctx.Error(http.StatusBadRequest, "invalid sha")
+		return git.SHA1{}, false
+	}
+	id := git.MustIDFromString(sha)
+
+	objectType, err := ctx.Repo.GitRepo.GetObjectType(id)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetObjectType", nil)
+		} else {
+			ctx.ServerError("GetObjectType", err)
+		}
+		return git.SHA1{}, false
+	}
+	if objectType != typ {
+		ctx.NotFound("GetObjectType", nil)
+		return git.SHA1{}, false
+	}
+
+	// objects left behind by force pushes or deleted branches must not be served
+	reachable, err := isObjectReachable(ctx, id, typ)
+	if err != nil {
+		ctx.ServerError("IsObjectReachable", err)
+		return git.SHA1{}, false
+	}
+	if !reachable {
+		ctx.NotFound("IsObjectReachable", nil)
+		return git.SHA1{}, false
+	}
+	return id, true
+}
+
+// isObjectReachable returns whether the object is reachable from a reference of the repository. Walking all
+// the objects to find a blob is expensive, so the result is cached until a reference of the repository changes.
+func isObjectReachable(ctx *context.Context, id git.SHA1, typ git.ObjectType) (bool, error) {
+	if typ == git.ObjectCommit {
+		return ctx.Repo.GitRepo.IsObjectReachable(id, typ)
+	}
+
+	refsHash, err := ctx.Repo.GitRepo.RefsHash()
+	if err != nil {
+		return false, err
+	}
+	key := fmt.Sprintf("git_object_reachable_%d_%s_%s", ctx.Repo.Repository.ID, refsHash, id.String())
+	reachable, err := cache.GetInt(key, func() (int, error) {
+		reachable, err := ctx.Repo.GitRepo.IsObjectReachable(id, typ)
+		if err != nil || !reachable {
+			return 0, err
+		}
+		return 1, nil
+	})
+	return reachable == 1, err
+}
+
+// GitBlob returns a blob by its SHA, as JSON or raw when requested
+func GitBlob(ctx *context.Context) {
+	id, ok := getReachableObjectID(ctx, git.ObjectBlob)
+	if !ok {
+		return
+	}
+
+	if ctx.QueryBool("raw") {
+		blob, err := ctx.Repo.GitRepo.GetBlob(id.String())
+		if err != nil {
+			ctx.ServerError("GetBlob", err)
+			return
+		}
+		ctx.Repo.TreePath = id.String()
+		if err = ServeBlob(ctx, blob); err != nil {
+			ctx.ServerError("ServeBlob", err)
+		}
+		return
+	}
+
+	blob, err := repofiles.GetBlobBySHA(ctx.Repo.Repository, id.String())
+	if err != nil {
+		ctx.ServerError("GetBlobBySHA", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, blob)
+}
+
+// GitCommit returns the metadata of a commit by its SHA as JSON
+func GitCommit(ctx *context.Context) {
+	id, ok := getReachableObjectID(ctx, git.ObjectCommit)
+	if !ok {
+		return
+	}
+
+	commit, err := ctx.Repo.GitRepo.GetCommit(id.String())
+	if err != nil {
+		ctx.ServerError("GetCommit", err)
+		return
+	}
+
+	apiCommit, err := convert.ToCommit(ctx.Repo.Repository, commit, nil)
+	if err != nil {
+		ctx.ServerError("ToCommit", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiCommit)
+}
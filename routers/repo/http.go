@@ -10,7 +10,9 @@ import (
 	"compress/gzip"
 	gocontext "context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
@@ -80,6 +82,9 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 
 	var isPull, receivePack bool
 	service := ctx.Query("service")
+	isSmartHTTP := len(service) > 0 ||
+		strings.HasSuffix(ctx.Req.URL.Path, "git-receive-pack") ||
+		strings.HasSuffix(ctx.Req.URL.Path, "git-upload-pack")
 	if service == "git-receive-pack" ||
 		strings.HasSuffix(ctx.Req.URL.Path, "git-receive-pack") {
 		isPull = false
@@ -156,6 +161,7 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		authUsername string
 		authPasswd   string
 		environ      []string
+		gitToken     *models.GitOneTimeToken
 	)
 
 	// don't allow anonymous pulls if organization is not public
@@ -165,7 +171,35 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
-		askAuth = askAuth || (repo.Owner.Visibility != structs.VisibleTypePublic)
+		askAuth = askAuth || (repo.Owner.Visibility != structs.VisibleTypePublic) || repo.RequireGitAuth
+	}
+
+	// read-only access tokens of a repository can only be used to pull it
+	if askAuth && repoExist {
+		repoToken, err := getRepoAccessToken(ctx)
+		if err != nil {
+			ctx.ServerError("GetRepoAccessTokenBySHA", err)
+			return
+		}
+		if repoToken != nil {
+			if !isPull {
+				ctx.HandleText(http.StatusForbidden, "Repository access tokens are read-only")
+				return
+			}
+			perm, err := repoToken.Permission(repo)
+			if err != nil {
+				ctx.ServerError("Permission", err)
+				return
+			}
+			if !perm.CanAccess(accessMode, unitType) {
+				ctx.HandleText(http.StatusForbidden, "Token permission denied")
+				return
+			}
+			if err = repoToken.UpdateLastUsed(); err != nil {
+				log.Error("UpdateLastUsed: %v", err)
+			}
+			askAuth = false
+		}
 	}
 
 	// check access
@@ -235,6 +269,24 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 				log.Error("GetAccessTokenBySha: %v", err)
 			}
 
+			if authUser == nil {
+				// Check if it is a one-time token, which acts on behalf of the user who created it
+				gitToken, err = models.GetGitOneTimeToken(authToken)
+				if err == nil {
+					if !isSmartHTTP {
+						ctx.HandleText(http.StatusForbidden, "One-time tokens can only be used with the smart HTTP protocol")
+						return
+					}
+					authUser, err = models.GetUserByID(gitToken.UID)
+					if err != nil {
+						ctx.ServerError("GetUserByID", err)
+						return
+					}
+				} else if !models.IsErrGitOneTimeTokenInvalid(err) {
+					log.Error("GetGitOneTimeToken: %v", err)
+				}
+			}
+
 			if authUser == nil {
 				// Check username and password
 				authUser, err = models.UserSignIn(authUsername, authPasswd)
@@ -242,16 +294,29 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 					if models.IsErrUserProhibitLogin(err) {
 						ctx.HandleText(http.StatusForbidden, "User is not permitted to login")
 						return
+					} else if models.IsErrUserLocked(err) {
+						ctx.HandleText(http.StatusForbidden, "User account is locked after too many failed sign-in attempts")
+						return
 					} else if !models.IsErrUserNotExist(err) {
 						ctx.ServerError("UserSignIn error: %v", err)
 						return
 					}
+					// A wrong password of an existing user counts towards the lockout of the account
+					if uid := err.(models.ErrUserNotExist).UID; uid > 0 {
+						if _, err := models.RecordLoginFailure(uid); err != nil {
+							log.Error("RecordLoginFailure: %v", err)
+						}
+					}
 				}
 
 				if authUser == nil {
 					ctx.HandleText(http.StatusUnauthorized, fmt.Sprintf("invalid credentials from %s", ctx.RemoteAddr()))
 					return
 				}
+				if err := models.ResetLoginFailures(authUser); err != nil {
+					ctx.ServerError("ResetLoginFailures", err)
+					return
+				}
 
 				_, err = models.GetTwoFactorByUID(authUser.ID)
 				if err == nil {
@@ -270,6 +335,21 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
+		if repoExist {
+			if err := repo.GetOwner(); err != nil {
+				ctx.ServerError("GetOwner", err)
+				return
+			}
+			// the members of an organization requiring two-factor authentication must enroll it first
+			if missing, err := repo.Owner.IsTwoFactorMissingFor(authUser.ID); err != nil {
+				ctx.ServerError("IsTwoFactorMissingFor", err)
+				return
+			} else if missing {
+				ctx.HandleText(http.StatusForbidden, fmt.Sprintf("Organization %s requires two-factor authentication, enroll it first", repo.Owner.Name))
+				return
+			}
+		}
+
 		if repoExist {
 			perm, err := models.GetUserRepoPermission(repo, authUser)
 			if err != nil {
@@ -282,12 +362,25 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 				return
 			}
 
+			if gitToken != nil && (gitToken.RepoID != repo.ID || gitToken.Mode < accessMode) {
+				ctx.HandleText(http.StatusForbidden, "Token permission denied")
+				return
+			}
+
 			if !isPull && repo.IsMirror {
 				ctx.HandleText(http.StatusForbidden, "mirror repository is read-only")
 				return
 			}
 		}
 
+		if gitToken != nil {
+			if !repoExist {
+				ctx.HandleText(http.StatusForbidden, "Token permission denied")
+				return
+			}
+			ctx.Data["GitOneTimeToken"] = gitToken
+		}
+
 		environ = []string{
 			models.EnvRepoUsername + "=" + username,
 			models.EnvRepoName + "=" + reponame,
@@ -372,6 +465,32 @@ var (
 	infoRefsOnce  sync.Once
 )
 
+// getRepoAccessToken returns the repository access token sent with the basic
+// authentication header of the request, if any.
+func getRepoAccessToken(ctx *context.Context) (*models.RepoAccessToken, error) {
+	auths := strings.Fields(ctx.Req.Header.Get("Authorization"))
+	if len(auths) != 2 || auths[0] != "Basic" {
+		return nil, nil
+	}
+	authUsername, authPasswd, err := base.BasicAuthDecode(auths[1])
+	if err != nil {
+		return nil, nil
+	}
+	// Either the username or the password can be the token
+	authToken := authUsername
+	if len(authPasswd) != 0 && authPasswd != "x-oauth-basic" {
+		authToken = authPasswd
+	}
+	token, err := models.GetRepoAccessTokenBySHA(authToken)
+	if err != nil {
+		if models.IsErrRepoAccessTokenNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
 func dummyInfoRefs(ctx *context.Context) {
 	infoRefsOnce.Do(func() {
 		tmpDir, err := ioutil.TempDir(os.TempDir(), "gitea-info-refs-cache")
@@ -455,6 +574,61 @@ func (h *serviceHandler) sendFile(contentType, file string) {
 // one or more key=value pairs separated by colons
 var safeGitProtocolHeader = regexp.MustCompile(`^[0-9a-zA-Z]+=[0-9a-zA-Z]+(:[0-9a-zA-Z]+=[0-9a-zA-Z]+)*$`)
 
+// capabilityConfigs are the git config options disabling the capabilities of the services
+// which git also refuses when they are not advertised.
+var capabilityConfigs = map[string]map[string]string{
+	"upload-pack": {
+		"filter":                       "uploadpack.allowFilter",
+		"allow-tip-sha1-in-want":       "uploadpack.allowTipSHA1InWant",
+		"allow-reachable-sha1-in-want": "uploadpack.allowReachableSHA1InWant",
+		"ref-in-want":                  "uploadpack.allowRefInWant",
+		"sideband-all":                 "uploadpack.allowSidebandAll",
+	},
+	"receive-pack": {
+		"push-options": "receive.advertisePushOptions",
+		"atomic":       "receive.advertiseAtomic",
+	},
+}
+
+// serviceConfigArgs returns the git config options the given service is run with,
+// they must be the same for the advertisement and the RPC of a request.
+func serviceConfigArgs(service string) []string {
+	var args []string
+	if service == "upload-pack" && setting.Repository.EnablePartialClone && git.CheckGitVersionAtLeast("2.19") == nil {
+		// Partial clones fetch the filtered out objects later on by their ID,
+		// so the objects reachable from the advertised refs must be allowed as wants.
+		args = append(args,
+			"-c", "uploadpack.allowFilter=true",
+			"-c", "uploadpack.allowReachableSHA1InWant=true",
+		)
+	}
+	// the options given last take precedence
+	for _, capability := range setting.Git.DisabledCapabilities {
+		if config, ok := capabilityConfigs[service][capability]; ok {
+			args = append(args, "-c", config+"=false")
+		}
+	}
+	return args
+}
+
+// isGitProtocolV2 returns true if the client asks for protocol v2 in the Git-Protocol header,
+// otherwise the original protocol is used. Only upload-pack supports protocol v2.
+func isGitProtocolV2(service string, r *http.Request) bool {
+	if service != "upload-pack" {
+		return false
+	}
+	protocol := r.Header.Get("Git-Protocol")
+	if !safeGitProtocolHeader.MatchString(protocol) {
+		return false
+	}
+	for _, param := range strings.Split(protocol, ":") {
+		if param == "version=2" {
+			return true
+		}
+	}
+	return false
+}
+
 func getGitConfig(option, dir string) string {
 	out, err := git.NewCommand("config", option).RunInDir(dir)
 	if err != nil {
@@ -525,14 +699,15 @@ func serviceRPC(h serviceHandler, service string) {
 	// set this for allow pre-receive and post-receive execute
 	h.environ = append(h.environ, "SSH_ORIGINAL_COMMAND="+service)
 
-	if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
-		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
+	if isGitProtocolV2(service, h.r) {
+		h.environ = append(h.environ, "GIT_PROTOCOL=version=2")
 	}
 
 	ctx, cancel := gocontext.WithCancel(git.DefaultContext)
 	defer cancel()
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, git.GitExecutable, service, "--stateless-rpc", h.dir)
+	args := append(serviceConfigArgs(service), service, "--stateless-rpc", h.dir)
+	cmd := exec.CommandContext(ctx, git.GitExecutable, args...)
 	cmd.Dir = h.dir
 	cmd.Env = append(os.Environ(), h.environ...)
 	cmd.Stdout = h.w
@@ -551,7 +726,7 @@ func serviceRPC(h serviceHandler, service string) {
 // ServiceUploadPack implements Git Smart HTTP protocol
 func ServiceUploadPack(ctx *context.Context) {
 	h := httpBase(ctx)
-	if h != nil {
+	if h != nil && useGitOneTimeToken(ctx, "upload-pack", false) {
 		serviceRPC(*h, "upload-pack")
 	}
 }
@@ -559,11 +734,35 @@ func ServiceUploadPack(ctx *context.Context) {
 // ServiceReceivePack implements Git Smart HTTP protocol
 func ServiceReceivePack(ctx *context.Context) {
 	h := httpBase(ctx)
-	if h != nil {
+	if h != nil && useGitOneTimeToken(ctx, "receive-pack", false) {
 		serviceRPC(*h, "receive-pack")
 	}
 }
 
+// useGitOneTimeToken uses the one-time token the request has been authenticated with, if any, for the
+// git operation of the service the request is part of, the advertisement of the refs starts the operation.
+// The operation is bound to the client sending the request. It returns false if the token cannot be used.
+func useGitOneTimeToken(ctx *context.Context, service string, startsOperation bool) bool {
+	gitToken, ok := ctx.Data["GitOneTimeToken"].(*models.GitOneTimeToken)
+	if !ok {
+		return true
+	}
+	ip, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		ip = ctx.RemoteAddr()
+	}
+	client := strings.Join([]string{service, ip, ctx.Req.UserAgent()}, "\n")
+	if err := gitToken.Use(client, startsOperation); err != nil {
+		if models.IsErrGitOneTimeTokenInvalid(err) {
+			ctx.HandleText(http.StatusUnauthorized, "The one-time token has expired or has already been used")
+		} else {
+			ctx.ServerError("Use", err)
+		}
+		return false
+	}
+	return true
+}
+
 func getServiceType(r *http.Request) string {
 	serviceType := r.FormValue("service")
 	if !strings.HasPrefix(serviceType, "git-") {
@@ -580,6 +779,28 @@ func updateServerInfo(dir string) []byte {
 	return out
 }
 
+// limitAdvertisedRefs returns the arguments limiting the refs advertised to clients of the repository
+// using protocol v0 or v1 according to the MAX_ADVERTISED_REFS setting, or the message refusing the
+// advertisement if the repository has too many refs
+func limitAdvertisedRefs(dir string) ([]string, string) {
+	if setting.Git.MaxAdvertisedRefs <= 0 {
+		return nil, ""
+	}
+	counts, err := git.CountRefs(dir)
+	if err != nil {
+		log.Error("CountRefs(%s): %v", dir, err)
+		return nil, ""
+	}
+	if counts.Total <= setting.Git.MaxAdvertisedRefs {
+		return nil, ""
+	}
+	if setting.Git.HidePullRefsOverLimit && counts.Total-counts.Pulls <= setting.Git.MaxAdvertisedRefs {
+		return []string{"-c", "transfer.hideRefs=" + git.PullPrefix}, ""
+	}
+	log.Warn("Refused to advertise the %d refs of %s, the limit is %d", counts.Total, dir, setting.Git.MaxAdvertisedRefs)
+	return nil, fmt.Sprintf("the repository has more than %d refs, use git wire protocol version 2: git -c protocol.version=2 ...", setting.Git.MaxAdvertisedRefs)
+}
+
 func packetWrite(str string) []byte {
 	s := strconv.FormatInt(int64(len(str)+4), 16)
 	if len(s)%4 != 0 {
@@ -591,28 +812,51 @@ func packetWrite(str string) []byte {
 // GetInfoRefs implements Git dumb HTTP
 func GetInfoRefs(ctx *context.Context) {
 	h := httpBase(ctx)
-	if h == nil {
+	if h == nil || !useGitOneTimeToken(ctx, getServiceType(ctx.Req), true) {
 		return
 	}
 	h.setHeaderNoCache()
 	if hasAccess(getServiceType(h.r), *h, false) {
 		service := getServiceType(h.r)
 
-		if protocol := h.r.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
-			h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
+		isV2 := isGitProtocolV2(service, h.r)
+		if isV2 {
+			h.environ = append(h.environ, "GIT_PROTOCOL=version=2")
 		}
 		h.environ = append(os.Environ(), h.environ...)
 
-		refs, err := git.NewCommand(service, "--stateless-rpc", "--advertise-refs", ".").RunInDirTimeoutEnv(h.environ, -1, h.dir)
-		if err != nil {
-			log.Error(fmt.Sprintf("%v - %s", err, string(refs)))
+		args := append(serviceConfigArgs(service), service, "--stateless-rpc", "--advertise-refs", ".")
+		var refsErr string
+		if !isV2 {
+			// protocol v2 clients list only the refs they need, older clients get all of them at once
+			var limitArgs []string
+			limitArgs, refsErr = limitAdvertisedRefs(h.dir)
+			args = append(limitArgs, args...)
 		}
 
 		h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-advertisement", service))
 		h.w.WriteHeader(http.StatusOK)
-		_, _ = h.w.Write(packetWrite("# service=git-" + service + "\n"))
-		_, _ = h.w.Write([]byte("0000"))
-		_, _ = h.w.Write(refs)
+		// the capability advertisement of protocol v2 is not preceded by the service line
+		if !isV2 {
+			_, _ = h.w.Write(packetWrite("# service=git-" + service + "\n"))
+			_, _ = h.w.Write([]byte("0000"))
+		}
+		if refsErr != "" {
+			_, _ = h.w.Write(packetWrite("ERR " + refsErr + "\n"))
+			return
+		}
+
+		// stream the advertisement, it can be large for repositories with many refs
+		var stdout io.Writer = h.w
+		if len(setting.Git.DisabledCapabilities) > 0 {
+			filter := git.NewAdvertisementFilter(h.w, setting.Git.DisabledCapabilities, isV2)
+			defer filter.Close()
+			stdout = filter
+		}
+		var stderr strings.Builder
+		if err := git.NewCommand(args...).RunInDirTimeoutEnvPipeline(h.environ, -1, h.dir, stdout, &stderr); err != nil {
+			log.Error("%v - %s", err, stderr.String())
+		}
 	} else {
 		updateServerInfo(h.dir)
 		h.sendFile("text/plain; charset=utf-8", "info/refs")
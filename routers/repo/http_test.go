@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGitProtocolV2(t *testing.T) {
+	kases := []struct {
+		service  string
+		header   string
+		expected bool
+	}{
+		{"upload-pack", "", false},
+		{"upload-pack", "version=2", true},
+		{"upload-pack", "foo=bar:version=2", true},
+		{"upload-pack", "version=1", false},
+		{"upload-pack", "version=2 ", false},
+		{"upload-pack", "version=2\nfoo=bar", false},
+		{"receive-pack", "version=2", false},
+	}
+	for _, kase := range kases {
+		req := httptest.NewRequest("GET", "/user2/repo1.git/info/refs?service=git-"+kase.service, nil)
+		if len(kase.header) > 0 {
+			req.Header.Set("Git-Protocol", kase.header)
+		}
+		assert.Equal(t, kase.expected, isGitProtocolV2(kase.service, req), "%s: %q", kase.service, kase.header)
+	}
+}
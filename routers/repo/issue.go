@@ -119,6 +119,11 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 		viewType = "all"
 	}
 
+	dueDate := ctx.Query("due")
+	if !util.IsStringInSlice(dueDate, []string{"overdue", "set", "unset"}) {
+		dueDate = ""
+	}
+
 	var (
 		assigneeID        = ctx.QueryInt64("assignee")
 		posterID          int64
@@ -166,6 +171,9 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 		if len(issueIDs) == 0 {
 			forceEmpty = true
 		}
+		if sortType == "" {
+			sortType = "relevance"
+		}
 	}
 
 	var issueStats *models.IssueStats
@@ -182,6 +190,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 			ReviewRequestedID: reviewRequestedID,
 			IsPull:            isPullOption,
 			IssueIDs:          issueIDs,
+			DueDate:           dueDate,
 		})
 		if err != nil {
 			ctx.ServerError("GetIssueStats", err)
@@ -234,6 +243,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 			LabelIDs:          labelIDs,
 			SortType:          sortType,
 			IssueIDs:          issueIDs,
+			DueDate:           dueDate,
 		})
 		if err != nil {
 			ctx.ServerError("Issues", err)
@@ -340,6 +350,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	ctx.Data["SortType"] = sortType
 	ctx.Data["MilestoneID"] = milestoneID
 	ctx.Data["AssigneeID"] = assigneeID
+	ctx.Data["DueDate"] = dueDate
 	ctx.Data["IsShowClosed"] = isShowClosed
 	ctx.Data["Keyword"] = keyword
 	if isShowClosed {
@@ -355,6 +366,7 @@ func issues(ctx *context.Context, milestoneID, projectID int64, isPullOption uti
 	pager.AddParam(ctx, "labels", "SelectLabels")
 	pager.AddParam(ctx, "milestone", "MilestoneID")
 	pager.AddParam(ctx, "assignee", "AssigneeID")
+	pager.AddParam(ctx, "due", "DueDate")
 	ctx.Data["Page"] = pager
 }
 
@@ -379,8 +391,24 @@ func Issues(ctx *context.Context) {
 	}
 
 	issues(ctx, ctx.QueryInt64("milestone"), ctx.QueryInt64("project"), util.OptionalBoolOf(isPullList))
+	if ctx.Written() {
+		return
+	}
 
 	var err error
+	// the pinned issues are listed above the first page of the list unless it is searched
+	if ctx.QueryInt("page") <= 1 && ctx.Data["Keyword"] == "" {
+		pinnedIssues, err := models.GetPinnedIssues(ctx.Repo.Repository.ID, isPullList)
+		if err != nil {
+			ctx.ServerError("GetPinnedIssues", err)
+			return
+		}
+		for _, issue := range pinnedIssues {
+			issue.Repo = ctx.Repo.Repository
+		}
+		ctx.Data["PinnedIssues"] = pinnedIssues
+	}
+
 	// Get milestones
 	ctx.Data["Milestones"], err = models.GetMilestones(models.GetMilestonesOption{
 		RepoID: ctx.Repo.Repository.ID,
@@ -429,29 +457,36 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 }
 
 func retrieveProjects(ctx *context.Context, repo *models.Repository) {
+	for _, state := range []struct {
+		key      string
+		isClosed util.OptionalBool
+	}{
+		{"OpenProjects", util.OptionalBoolFalse},
+		{"ClosedProjects", util.OptionalBoolTrue},
+	} {
+		projects, _, err := models.GetProjects(models.ProjectSearchOptions{
+			RepoID:   repo.ID,
+			Page:     -1,
+			IsClosed: state.isClosed,
+			Type:     models.ProjectTypeRepository,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
 
-	var err error
-
-	ctx.Data["OpenProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
-		RepoID:   repo.ID,
-		Page:     -1,
-		IsClosed: util.OptionalBoolFalse,
-		Type:     models.ProjectTypeRepository,
-	})
-	if err != nil {
-		ctx.ServerError("GetProjects", err)
-		return
-	}
-
-	ctx.Data["ClosedProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
-		RepoID:   repo.ID,
-		Page:     -1,
-		IsClosed: util.OptionalBoolTrue,
-		Type:     models.ProjectTypeRepository,
-	})
-	if err != nil {
-		ctx.ServerError("GetProjects", err)
-		return
+		// the issues can be assigned to the projects of the organization owning the repository too
+		orgProjects, _, err := models.GetProjects(models.ProjectSearchOptions{
+			OwnerID:  repo.OwnerID,
+			Page:     -1,
+			IsClosed: state.isClosed,
+			Type:     models.ProjectTypeOrganization,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
+		ctx.Data[state.key] = append(projects, orgProjects...)
 	}
 }
 
@@ -801,7 +836,7 @@ func NewIssue(ctx *context.Context) {
 		project, err := models.GetProjectByID(projectID)
 		if err != nil {
 			log.Error("GetProjectByID: %d: %v", projectID, err)
-		} else if project.RepoID != ctx.Repo.Repository.ID {
+		} else if !project.IsAssignableIn(ctx.Repo.Repository) {
 			log.Error("GetProjectByID: %d: %v", projectID, fmt.Errorf("project[%d] not in repo [%d]", project.ID, ctx.Repo.Repository.ID))
 		} else {
 			ctx.Data["project_id"] = projectID
@@ -818,6 +853,8 @@ func NewIssue(ctx *context.Context) {
 
 	ctx.Data["HasIssuesOrPullsWritePermission"] = ctx.Repo.CanWrite(models.UnitTypeIssues)
 
+	ctx.SetCaptchaData(isIssueCaptchaRequired(ctx))
+
 	ctx.HTML(200, tplIssueNew)
 }
 
@@ -885,7 +922,7 @@ func ValidateRepoMetas(ctx *context.Context, form auth.CreateIssueForm, isPull b
 			ctx.ServerError("GetProjectByID", err)
 			return nil, nil, 0, 0
 		}
-		if p.RepoID != ctx.Repo.Repository.ID {
+		if !p.IsAssignableIn(ctx.Repo.Repository) {
 			ctx.NotFound("", nil)
 			return nil, nil, 0, 0
 		}
@@ -931,6 +968,12 @@ func ValidateRepoMetas(ctx *context.Context, form auth.CreateIssueForm, isPull b
 	return labelIDs, assigneeIDs, milestoneID, form.ProjectID
 }
 
+// isIssueCaptchaRequired returns true if the doer has to solve a captcha to open an issue
+func isIssueCaptchaRequired(ctx *context.Context) bool {
+	return setting.Service.EnableCaptcha && setting.Service.RequireCaptchaForIssueCreation &&
+		!ctx.Repo.CanWrite(models.UnitTypeIssues)
+}
+
 // NewIssuePost response for creating new issue
 func NewIssuePost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.CreateIssueForm)
@@ -958,6 +1001,9 @@ func NewIssuePost(ctx *context.Context) {
 		attachments = form.Files
 	}
 
+	captchaRequired := isIssueCaptchaRequired(ctx)
+	ctx.SetCaptchaData(captchaRequired)
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplIssueNew)
 		return
@@ -968,6 +1014,26 @@ func NewIssuePost(ctx *context.Context) {
 		return
 	}
 
+	if captchaRequired {
+		valid, err := ctx.VerifyCaptcha()
+		if err != nil {
+			log.Debug("%s", err.Error())
+		}
+		if !valid {
+			ctx.Data["Err_Captcha"] = true
+			ctx.RenderWithErr(ctx.Tr("form.captcha_incorrect"), tplIssueNew, form)
+			return
+		}
+	}
+
+	if len(assigneeIDs) == 0 {
+		var err error
+		if assigneeIDs, err = issue_service.DefaultAssigneeIDs(repo); err != nil {
+			ctx.ServerError("DefaultAssigneeIDs", err)
+			return
+		}
+	}
+
 	issue := &models.Issue{
 		RepoID:      repo.ID,
 		Title:       form.Title,
@@ -1279,6 +1345,22 @@ func ViewIssue(ctx *context.Context) {
 	}
 	marked[issue.PosterID] = issue.ShowTag
 
+	commentIDs := make([]int64, 0, len(issue.Comments))
+	for _, comment := range issue.Comments {
+		if comment.Type == models.CommentTypeComment {
+			commentIDs = append(commentIDs, comment.ID)
+		}
+	}
+	editedComments, err := models.GetEditedCommentIDs(commentIDs)
+	if err != nil {
+		ctx.ServerError("GetEditedCommentIDs", err)
+		return
+	}
+	ctx.Data["EditedComments"] = editedComments
+
+	// permissions of the repositories of the dependencies
+	dependencyPerms := map[int64]models.Permission{}
+
 	// Render comments and and fetch participants.
 	participants[0] = issue.Poster
 	for _, comment = range issue.Comments {
@@ -1295,6 +1377,11 @@ func ViewIssue(ctx *context.Context) {
 				return
 			}
 
+			if err := comment.LoadExtractedIssue(); err != nil && !models.IsErrIssueNotExist(err) {
+				ctx.ServerError("LoadExtractedIssue", err)
+				return
+			}
+
 			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
 				ctx.Repo.Repository.ComposeMetas()))
 
@@ -1364,6 +1451,16 @@ func ViewIssue(ctx *context.Context) {
 					return
 				}
 			}
+			if comment.DependentIssue != nil {
+				canRead, err := canReadDependency(ctx, dependencyPerms, comment.DependentIssue)
+				if err != nil {
+					ctx.ServerError("canReadDependency", err)
+					return
+				}
+				if !canRead {
+					comment.DependentIssue = nil
+				}
+			}
 		} else if comment.Type == models.CommentTypeCode || comment.Type == models.CommentTypeReview || comment.Type == models.CommentTypeDismissReview {
 			comment.RenderedContent = string(markdown.Render([]byte(comment.Content), ctx.Repo.RepoLink,
 				ctx.Repo.Repository.ComposeMetas()))
@@ -1512,6 +1609,41 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["ChangedProtectedFiles"] = pull.ChangedProtectedFiles
 			ctx.Data["IsBlockedByChangedProtectedFiles"] = len(pull.ChangedProtectedFiles) != 0
 			ctx.Data["ChangedProtectedFilesNum"] = len(pull.ChangedProtectedFiles)
+			if !issue.IsClosed && !pull.HasMerged {
+				pendingCodeOwners, err := pull_service.GetPendingCodeOwners(pull)
+				if err != nil {
+					ctx.ServerError("GetPendingCodeOwners", err)
+					return
+				}
+				ctx.Data["PendingCodeOwners"] = pendingCodeOwners
+				ctx.Data["IsBlockedByCodeOwners"] = len(pendingCodeOwners) != 0
+			}
+		}
+		isAutoMergeScheduled, autoMerge, err := models.GetScheduledMergeByPullID(pull.ID)
+		if err != nil && !models.IsErrUserNotExist(err) {
+			ctx.ServerError("GetScheduledMergeByPullID", err)
+			return
+		}
+		ctx.Data["IsAutoMergeScheduled"] = isAutoMergeScheduled && autoMerge.Doer != nil
+		ctx.Data["AutoMerge"] = autoMerge
+		ctx.Data["IsMergeQueueEnabled"] = pull.ProtectedBranch != nil && pull.ProtectedBranch.EnableMergeQueue
+		if isInMergeQueue, entry, err := models.GetMergeQueueEntryByPullID(pull.ID); err != nil && !models.IsErrUserNotExist(err) {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		} else if isInMergeQueue && entry.Doer != nil {
+			queue, err := models.GetMergeQueue(pull.BaseRepoID, pull.BaseBranch)
+			if err != nil {
+				ctx.ServerError("GetMergeQueue", err)
+				return
+			}
+			for i := range queue {
+				if queue[i].PullID == pull.ID {
+					ctx.Data["MergeQueuePosition"] = i + 1
+				}
+			}
+			ctx.Data["IsInMergeQueue"] = true
+			ctx.Data["MergeQueueEntry"] = entry
+			ctx.Data["MergeQueueLength"] = len(queue)
 		}
 		ctx.Data["WillSign"] = false
 		if ctx.User != nil {
@@ -1551,17 +1683,27 @@ func ViewIssue(ctx *context.Context) {
 		ctx.Data["StillCanManualMerge"] = stillCanManualMerge()
 	}
 
-	// Get Dependencies
-	ctx.Data["BlockedByDependencies"], err = issue.BlockedByDependencies()
+	// Get Dependencies, the ones in repositories the user cannot access are only counted
+	blockedBy, err := issue.BlockedByDependencies()
 	if err != nil {
 		ctx.ServerError("BlockedByDependencies", err)
 		return
 	}
-	ctx.Data["BlockingDependencies"], err = issue.BlockingDependencies()
+	ctx.Data["BlockedByDependencies"], ctx.Data["BlockedByDependenciesNotPermitted"], err = filterReadableDependencies(ctx, dependencyPerms, blockedBy)
+	if err != nil {
+		ctx.ServerError("filterReadableDependencies", err)
+		return
+	}
+	blocking, err := issue.BlockingDependencies()
 	if err != nil {
 		ctx.ServerError("BlockingDependencies", err)
 		return
 	}
+	ctx.Data["BlockingDependencies"], ctx.Data["BlockingDependenciesNotPermitted"], err = filterReadableDependencies(ctx, dependencyPerms, blocking)
+	if err != nil {
+		ctx.ServerError("filterReadableDependencies", err)
+		return
+	}
 
 	ctx.Data["Participants"] = participants
 	ctx.Data["NumParticipants"] = len(participants)
@@ -2206,7 +2348,7 @@ func ChangeIssueReaction(ctx *context.Context) {
 		reaction, err := models.CreateIssueReaction(ctx.User, issue, form.Content)
 		if err != nil {
 			if models.IsErrForbiddenIssueReaction(err) {
-				ctx.ServerError("ChangeIssueReaction", err)
+				ctx.Error(http.StatusForbidden, err.Error())
 				return
 			}
 			log.Info("CreateIssueReaction: %s", err)
@@ -2306,7 +2448,7 @@ func ChangeCommentReaction(ctx *context.Context) {
 		reaction, err := models.CreateCommentReaction(ctx.User, comment.Issue, comment, form.Content)
 		if err != nil {
 			if models.IsErrForbiddenIssueReaction(err) {
-				ctx.ServerError("ChangeIssueReaction", err)
+				ctx.Error(http.StatusForbidden, err.Error())
 				return
 			}
 			log.Info("CreateCommentReaction: %s", err)
@@ -0,0 +1,210 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/web"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+type bulkEditFailure struct {
+	Index   int64  `json:"index"`
+	Message string `json:"message"`
+}
+
+type bulkEditResult struct {
+	OK        bool              `json:"ok"`
+	Succeeded []int64           `json:"succeeded"`
+	Failed    []bulkEditFailure `json:"failed"`
+}
+
+// BulkEditIssues applies one change to the issues or pull requests of the given indexes.
+// The change and all the indexes are checked before any issue is changed, each issue is then changed
+// on its own and the response lists the indexes which were changed and those which failed.
+func BulkEditIssues(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.BulkEditIssuesForm)
+	if ctx.HasError() {
+		ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"message": ctx.GetErrMsg(),
+		})
+		return
+	}
+
+	issues := make([]*models.Issue, 0, len(form.IssueIndexes))
+	seen := make(map[int64]bool, len(form.IssueIndexes))
+	for _, index := range form.IssueIndexes {
+		if seen[index] {
+			continue
+		}
+		seen[index] = true
+
+		issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, index)
+		if err != nil {
+			if models.IsErrIssueNotExist(err) {
+				ctx.JSON(http.StatusNotFound, map[string]string{
+					"message": err.Error(),
+				})
+				return
+			}
+			ctx.ServerError("GetIssueByIndex", err)
+			return
+		}
+		if err := issue.LoadAttributes(); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+		issues = append(issues, issue)
+	}
+
+	edit := prepareBulkEdit(ctx, form)
+	if ctx.Written() {
+		return
+	}
+
+	succeeded := make([]int64, 0, len(issues))
+	failed := make([]bulkEditFailure, 0)
+	for _, issue := range issues {
+		if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+			failed = append(failed, bulkEditFailure{
+				Index:   issue.Index,
+				Message: ctx.Tr("repo.issues.bulk_edit.forbidden"),
+			})
+			continue
+		}
+
+		if err := edit(issue); err != nil {
+			var message string
+			switch {
+			case models.IsErrDependenciesLeft(err):
+				message = ctx.Tr("repo.issues.dependency.issue_close_blocked")
+			case models.IsErrUserDoesNotHaveAccessToRepo(err):
+				message = ctx.Tr("repo.issues.bulk_edit.cannot_assign")
+			default:
+				log.Error("Bulk edit %s of issue %d in %-v: %v", form.Action, issue.Index, ctx.Repo.Repository, err)
+				message = ctx.Tr("repo.issues.bulk_edit.failed")
+			}
+			failed = append(failed, bulkEditFailure{
+				Index:   issue.Index,
+				Message: message,
+			})
+			continue
+		}
+		succeeded = append(succeeded, issue.Index)
+	}
+
+	ctx.JSON(http.StatusOK, &bulkEditResult{
+		OK:        len(failed) == 0,
+		Succeeded: succeeded,
+		Failed:    failed,
+	})
+}
+
+// prepareBulkEdit checks the label, milestone or assignee of the action and returns the function changing
+// one issue, which leaves the issues already in the requested state alone
+func prepareBulkEdit(ctx *context.Context, form *auth.BulkEditIssuesForm) func(*models.Issue) error {
+	repo := ctx.Repo.Repository
+	switch form.Action {
+	case "add_label", "remove_label":
+		label, err := models.GetLabelByID(form.ID)
+		if err == nil && label.RepoID != repo.ID && (!label.BelongsToOrg() || label.OrgID != repo.OwnerID) {
+			err = models.ErrRepoLabelNotExist{LabelID: form.ID, RepoID: repo.ID}
+		}
+		if err != nil {
+			if models.IsErrRepoLabelNotExist(err) {
+				ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+					"message": err.Error(),
+				})
+				return nil
+			}
+			ctx.ServerError("GetLabelByID", err)
+			return nil
+		}
+
+		if form.Action == "add_label" {
+			return func(issue *models.Issue) error {
+				if issue.HasLabel(label.ID) {
+					return nil
+				}
+				return issue_service.AddLabel(issue, ctx.User, label)
+			}
+		}
+		return func(issue *models.Issue) error {
+			if !issue.HasLabel(label.ID) {
+				return nil
+			}
+			return issue_service.RemoveLabel(issue, ctx.User, label)
+		}
+
+	case "set_milestone":
+		if form.ID > 0 {
+			if _, err := models.GetMilestoneByRepoID(repo.ID, form.ID); err != nil {
+				if models.IsErrMilestoneNotExist(err) {
+					ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+						"message": err.Error(),
+					})
+					return nil
+				}
+				ctx.ServerError("GetMilestoneByRepoID", err)
+				return nil
+			}
+		}
+		return func(issue *models.Issue) error {
+			oldMilestoneID := issue.MilestoneID
+			if oldMilestoneID == form.ID {
+				return nil
+			}
+			issue.MilestoneID = form.ID
+			return issue_service.ChangeMilestoneAssign(issue, ctx.User, oldMilestoneID)
+		}
+
+	case "set_assignee":
+		if form.ID == 0 {
+			return func(issue *models.Issue) error {
+				return issue_service.DeleteNotPassedAssignee(issue, ctx.User, []*models.User{})
+			}
+		}
+		assignee, err := models.GetUserByID(form.ID)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+					"message": err.Error(),
+				})
+				return nil
+			}
+			ctx.ServerError("GetUserByID", err)
+			return nil
+		}
+		return func(issue *models.Issue) error {
+			valid, err := models.CanBeAssigned(assignee, issue.Repo, issue.IsPull)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				return models.ErrUserDoesNotHaveAccessToRepo{UserID: assignee.ID, RepoName: issue.Repo.Name}
+			}
+			isAssigned, err := models.IsUserAssignedToIssue(issue, assignee)
+			if err != nil || isAssigned {
+				return err
+			}
+			_, _, err = issue_service.ToggleAssignee(issue, ctx.User, assignee.ID)
+			return err
+		}
+
+	default: // close, reopen
+		isClosed := form.Action == "close"
+		return func(issue *models.Issue) error {
+			if issue.IsClosed == isClosed {
+				return nil
+			}
+			return issue_service.ChangeStatus(issue, ctx.User, isClosed)
+		}
+	}
+}
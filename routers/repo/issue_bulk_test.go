@@ -0,0 +1,66 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/web"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bulkEditIssues(t *testing.T, form auth.BulkEditIssuesForm) int {
+	ctx := test.MockContext(t, "user2/repo1/issues/bulk")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+	web.SetForm(ctx, &form)
+	BulkEditIssues(ctx)
+	return ctx.Resp.Status()
+}
+
+func TestBulkEditIssues(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	// issue 5 has index 4 and label 2 already
+	assert.EqualValues(t, http.StatusOK, bulkEditIssues(t, auth.BulkEditIssuesForm{
+		IssueIndexes: []int64{1, 4},
+		Action:       "add_label",
+		ID:           2,
+	}))
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: 1, LabelID: 2})
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: 5, LabelID: 2})
+	models.CheckConsistencyFor(t, &models.Label{})
+
+	assert.EqualValues(t, http.StatusOK, bulkEditIssues(t, auth.BulkEditIssuesForm{
+		IssueIndexes: []int64{1, 2},
+		Action:       "set_milestone",
+		ID:           1,
+	}))
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, MilestoneID: 1})
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2, MilestoneID: 1})
+	models.CheckConsistencyFor(t, &models.Milestone{})
+
+	assert.EqualValues(t, http.StatusOK, bulkEditIssues(t, auth.BulkEditIssuesForm{
+		IssueIndexes: []int64{1, 4},
+		Action:       "set_assignee",
+		ID:           2,
+	}))
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignees{IssueID: 1, AssigneeID: 2})
+	models.AssertExistsAndLoadBean(t, &models.IssueAssignees{IssueID: 5, AssigneeID: 2})
+
+	assert.EqualValues(t, http.StatusOK, bulkEditIssues(t, auth.BulkEditIssuesForm{
+		IssueIndexes: []int64{1, 4},
+		Action:       "close",
+	}))
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsClosed: true})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeClose})
+	// issue 5 was closed already
+	models.AssertNotExistsBean(t, &models.Comment{IssueID: 5, Type: models.CommentTypeClose})
+}
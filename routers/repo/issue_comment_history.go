@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"html/template"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	comment_service "code.gitea.io/gitea/services/comments"
+)
+
+const tplCommentHistory base.TplName = "repo/issue/comment_history"
+
+// commentVersionView is a version of an edited comment with the changes from the previous one
+type commentVersionView struct {
+	*models.CommentVersion
+	Diff       template.HTML
+	IsOriginal bool
+	IsCurrent  bool
+}
+
+// getHistoryComment returns the comment of the request if it belongs to the repository and can be read
+func getHistoryComment(ctx *context.Context) *models.Comment {
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return nil
+	}
+	if err := comment.LoadIssue(); err != nil {
+		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
+		return nil
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID || !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull) {
+		ctx.NotFound("CommentHistory", nil)
+		return nil
+	}
+	return comment
+}
+
+// CommentHistory shows the versions of an edited comment, the most recent first, with their changes
+func CommentHistory(ctx *context.Context) {
+	comment := getHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	versions, err := models.GetCommentVersions(comment.ID)
+	if err != nil {
+		ctx.ServerError("GetCommentVersions", err)
+		return
+	}
+	if len(versions) == 0 {
+		ctx.NotFound("CommentHistory", nil)
+		return
+	}
+
+	views := make([]*commentVersionView, len(versions))
+	for i, version := range versions {
+		view := &commentVersionView{
+			CommentVersion: version,
+			IsOriginal:     i == 0,
+			IsCurrent:      i == len(versions)-1,
+		}
+		if i > 0 {
+			view.Diff = comment_service.RenderContentDiff(versions[i-1].Content, version.Content)
+		}
+		views[len(versions)-1-i] = view
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.issues.comment_history.title")
+	ctx.Data["PageIsIssueList"] = !comment.Issue.IsPull
+	ctx.Data["PageIsPullList"] = comment.Issue.IsPull
+	ctx.Data["Comment"] = comment
+	ctx.Data["Versions"] = views
+	ctx.Data["CanRestore"] = ctx.Repo.CanWriteIssuesOrPulls(comment.Issue.IsPull) && !ctx.Repo.Repository.IsArchived
+	ctx.HTML(200, tplCommentHistory)
+}
+
+// RestoreCommentVersion sets the content of a comment back to one of its versions
+func RestoreCommentVersion(ctx *context.Context) {
+	comment := getHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(comment.Issue.IsPull) {
+		ctx.NotFound("RestoreCommentVersion", nil)
+		return
+	}
+
+	version, err := models.GetCommentVersion(comment.ID, ctx.ParamsInt64(":version"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentVersion", models.IsErrCommentVersionNotExist, err)
+		return
+	}
+
+	if err := comment_service.RestoreCommentVersion(comment, ctx.User, version); err != nil {
+		ctx.ServerError("RestoreCommentVersion", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.comment_history.restored"))
+	ctx.Redirect(fmt.Sprintf("%s/comments/%d/history", ctx.Repo.RepoLink, comment.ID))
+}
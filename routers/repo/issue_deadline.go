@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// UpdateIssueDeadline sets or removes the due date of an issue, only the day of the date is kept
+func UpdateIssueDeadline(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.IssueDeadlineForm)
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.JSON(http.StatusForbidden, map[string]string{
+			"message": "Only authorized users are allowed to perform this action.",
+		})
+		return
+	}
+
+	var deadlineUnix timeutil.TimeStamp
+	var deadline time.Time
+	if form.DueDate != nil && !form.DueDate.IsZero() {
+		deadline = time.Date(form.DueDate.Year(), form.DueDate.Month(), form.DueDate.Day(),
+			23, 59, 59, 0, time.Local)
+		deadlineUnix = timeutil.TimeStamp(deadline.Unix())
+	}
+
+	if err := issue_service.ChangeDeadline(issue, ctx.User, deadlineUnix); err != nil {
+		ctx.ServerError("ChangeDeadline", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, api.IssueDeadline{Deadline: &deadline})
+}
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	issue_service "code.gitea.io/gitea/services/issue"
 )
 
 // AddDependency adds new dependencies
@@ -34,8 +35,12 @@ func AddDependency(ctx *context.Context) {
 		return
 	}
 
-	// Redirect
-	defer ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+	// Redirect, unless an error page has been rendered
+	defer func() {
+		if !ctx.Written() {
+			ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+		}
+	}()
 
 	// Dependency
 	dep, err := models.GetIssueByID(depID)
@@ -50,13 +55,22 @@ func AddDependency(ctx *context.Context) {
 		return
 	}
 
+	// The dependency must be readable by the user, in its own repository
+	if canRead, err := canReadDependency(ctx, map[int64]models.Permission{}, dep); err != nil {
+		ctx.ServerError("canReadDependency", err)
+		return
+	} else if !canRead {
+		ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_dep_issue_not_exist"))
+		return
+	}
+
 	// Check if issue and dependency is the same
 	if dep.ID == issue.ID {
 		ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_same_issue"))
 		return
 	}
 
-	err = models.CreateIssueDependency(ctx.User, issue, dep)
+	err = issue_service.AddDependency(ctx.User, issue, dep)
 	if err != nil {
 		if models.IsErrDependencyExists(err) {
 			ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_dep_exists"))
@@ -115,7 +129,8 @@ func RemoveDependency(ctx *context.Context) {
 		return
 	}
 
-	if err = models.RemoveIssueDependency(ctx.User, issue, dep, depType); err != nil {
+	// The dependency is removed even if the user cannot read it anymore, so that it does not block forever
+	if err = issue_service.RemoveDependency(ctx.User, issue, dep, depType); err != nil {
 		if models.IsErrDependencyNotExists(err) {
 			ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_dep_not_exist"))
 			return
@@ -127,3 +142,42 @@ func RemoveDependency(ctx *context.Context) {
 	// Redirect
 	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
 }
+
+// canReadDependency returns whether the user can read the issue linked by a dependency, which can be
+// in another repository, the permissions of the other repositories are cached in perms
+func canReadDependency(ctx *context.Context, perms map[int64]models.Permission, dep *models.Issue) (bool, error) {
+	if dep.RepoID == ctx.Repo.Repository.ID {
+		return ctx.Repo.CanReadIssuesOrPulls(dep.IsPull), nil
+	}
+	perm, ok := perms[dep.RepoID]
+	if !ok {
+		if err := dep.LoadRepo(); err != nil {
+			return false, err
+		}
+		var err error
+		if perm, err = models.GetUserRepoPermission(dep.Repo, ctx.User); err != nil {
+			return false, err
+		}
+		perms[dep.RepoID] = perm
+	}
+	return perm.CanReadIssuesOrPulls(dep.IsPull), nil
+}
+
+// filterReadableDependencies splits the dependencies of an issue between the ones the user can read
+// and the ones in repositories the user cannot access, the latter are only shown to be removed
+func filterReadableDependencies(ctx *context.Context, perms map[int64]models.Permission, deps []*models.DependencyInfo) (readable, hidden []*models.DependencyInfo, err error) {
+	readable = make([]*models.DependencyInfo, 0, len(deps))
+	for _, dep := range deps {
+		dep.Issue.Repo = &dep.Repository
+		canRead, err := canReadDependency(ctx, perms, &dep.Issue)
+		if err != nil {
+			return nil, nil, err
+		}
+		if canRead {
+			readable = append(readable, dep)
+		} else {
+			hidden = append(hidden, dep)
+		}
+	}
+	return readable, hidden, nil
+}
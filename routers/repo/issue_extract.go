@@ -0,0 +1,60 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/web"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// ExtractComment creates a new issue from a comment, which links to it afterwards
+func ExtractComment(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.ExtractCommentForm)
+	comment, err := models.GetCommentByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetCommentByID", models.IsErrCommentNotExist, err)
+		return
+	}
+
+	if err := comment.LoadIssue(); err != nil {
+		ctx.NotFoundOrServerError("LoadIssue", models.IsErrIssueNotExist, err)
+		return
+	}
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID || comment.Type != models.CommentTypeComment {
+		ctx.NotFound("ExtractComment", nil)
+		return
+	}
+
+	// a comment is only extracted once
+	if comment.ExtractedIssueID > 0 {
+		if err := comment.LoadExtractedIssue(); err != nil {
+			ctx.ServerError("LoadExtractedIssue", err)
+			return
+		}
+		ctx.Redirect(comment.ExtractedIssue.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(comment.HTMLURL(), http.StatusSeeOther)
+		return
+	}
+
+	issue, err := issue_service.ExtractComment(ctx.User, comment, form.Title, form.InheritLabels)
+	if err != nil {
+		ctx.ServerError("ExtractComment", err)
+		return
+	}
+
+	log.Trace("Issue created from comment %d: %d/%d", comment.ID, issue.RepoID, issue.ID)
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
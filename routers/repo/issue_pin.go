@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// IssuePin pins an issue to the top of the issue list of the repository, or unpins it if it is pinned already
+func IssuePin(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound("IssuePin", nil)
+		return
+	}
+
+	if issue.IsPinned() {
+		if err := models.UnpinIssue(issue); err != nil {
+			ctx.ServerError("UnpinIssue", err)
+			return
+		}
+	} else if err := models.PinIssue(issue, setting.Repository.Issue.MaxPinned); err != nil {
+		if !models.IsErrIssueMaxPinReached(err) {
+			ctx.ServerError("PinIssue", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("repo.issues.pin.max_reached", setting.Repository.Issue.MaxPinned))
+	}
+
+	ctx.Redirect(issue.HTMLURL(), http.StatusSeeOther)
+}
+
+// ReorderPinnedIssues sets the order in which the pinned issues of the repository are listed
+func ReorderPinnedIssues(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.PinnedIssuesOrderForm)
+	if !ctx.Repo.CanWriteIssuesOrPulls(form.IsPull) {
+		ctx.JSON(http.StatusForbidden, map[string]string{
+			"message": "Only authorized users are allowed to perform this action.",
+		})
+		return
+	}
+
+	if err := models.ReorderPinnedIssues(ctx.Repo.Repository.ID, form.IsPull, form.IssueIDs); err != nil {
+		if models.IsErrPinnedIssuesOrderMismatch(err) {
+			ctx.JSON(http.StatusUnprocessableEntity, map[string]string{
+				"message": err.Error(),
+			})
+			return
+		}
+		ctx.ServerError("ReorderPinnedIssues", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": true,
+	})
+}
@@ -45,7 +45,6 @@ func Migrate(ctx *context.Context) {
 		return
 	}
 
-	ctx.Data["private"] = getRepoPrivate(ctx)
 	ctx.Data["mirror"] = ctx.Query("mirror") == "1"
 	ctx.Data["wiki"] = ctx.Query("wiki") == "1"
 	ctx.Data["milestones"] = ctx.Query("milestones") == "1"
@@ -59,6 +58,8 @@ func Migrate(ctx *context.Context) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	ctx.Data["private"] = ctxUser.IsRepoPrivateByDefault(ctx.User)
+	setForcedPrivateData(ctx, ctxUser)
 
 	ctx.HTML(200, base.TplName("repo/migrate/"+serviceType.Name()))
 }
@@ -131,6 +132,7 @@ func MigratePost(ctx *context.Context) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	setForcedPrivateData(ctx, ctxUser)
 
 	tpl := base.TplName("repo/migrate/" + serviceType.Name())
 
@@ -179,7 +181,7 @@ func MigratePost(ctx *context.Context) {
 		CloneAddr:      remoteAddr,
 		RepoName:       form.RepoName,
 		Description:    form.Description,
-		Private:        form.Private || setting.Repository.ForcePrivate,
+		Private:        form.Private || ctxUser.IsRepoPrivateForced(),
 		Mirror:         form.Mirror && !setting.Repository.DisableMirrors,
 		AuthUsername:   form.AuthUsername,
 		AuthPassword:   form.AuthPassword,
@@ -283,6 +283,11 @@ func ViewProject(ctx *context.Context) {
 		boards[0].Title = ctx.Tr("repo.projects.type.uncategorized")
 	}
 
+	if err = boards.LoadRules(); err != nil {
+		ctx.ServerError("LoadRulesOfBoards", err)
+		return
+	}
+
 	issueList, err := boards.LoadIssues()
 	if err != nil {
 		ctx.ServerError("LoadIssuesOfBoards", err)
@@ -310,11 +315,19 @@ func ViewProject(ctx *context.Context) {
 	}
 	ctx.Data["LinkedPRs"] = linkedPrsMap
 
+	movedCards, err := models.GetAutomaticallyMovedProjectIssues(project.ID)
+	if err != nil {
+		ctx.ServerError("GetAutomaticallyMovedProjectIssues", err)
+		return
+	}
+	ctx.Data["MovedCards"] = movedCards
+
 	project.RenderedContent = string(markdown.Render([]byte(project.Description), ctx.Repo.RepoLink, ctx.Repo.Repository.ComposeMetas()))
 
 	ctx.Data["CanWriteProjects"] = ctx.Repo.Permission.CanWrite(models.UnitTypeProjects)
 	ctx.Data["Project"] = project
 	ctx.Data["Boards"] = boards
+	ctx.Data["ProjectBoardTriggers"] = models.ProjectBoardTriggers
 	ctx.Data["PageIsProjects"] = true
 	ctx.Data["RequiresDraggable"] = true
 
@@ -329,6 +342,22 @@ func UpdateIssueProject(ctx *context.Context) {
 	}
 
 	projectID := ctx.QueryInt64("id")
+	if projectID > 0 {
+		project, err := models.GetProjectByID(projectID)
+		if err != nil {
+			if models.IsErrProjectNotExist(err) {
+				ctx.NotFound("", nil)
+			} else {
+				ctx.ServerError("GetProjectByID", err)
+			}
+			return
+		}
+		if !project.IsAssignableIn(ctx.Repo.Repository) {
+			ctx.NotFound("", nil)
+			return
+		}
+	}
+
 	for _, issue := range issues {
 		oldProjectID := issue.ProjectID()
 		if oldProjectID == projectID {
@@ -502,6 +531,13 @@ func EditProjectBoard(ctx *context.Context) {
 		return
 	}
 
+	if form.Triggers != nil {
+		if err := models.UpdateProjectBoardRules(board, form.Triggers); err != nil {
+			ctx.ServerError("UpdateProjectBoardRules", err)
+			return
+		}
+	}
+
 	ctx.JSON(200, map[string]interface{}{
 		"ok": true,
 	})
@@ -13,7 +13,6 @@ import (
 	"net/http"
 	"path"
 	"strings"
-	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
@@ -30,7 +29,9 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/utils"
+	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/gitdiff"
+	"code.gitea.io/gitea/services/mergequeue"
 	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	"github.com/unknwon/com"
@@ -230,6 +231,8 @@ func ForkPost(ctx *context.Context) {
 	if err != nil {
 		ctx.Data["Err_RepoName"] = true
 		switch {
+		case models.IsErrReachLimitOfRepo(err):
+			ctx.RenderWithErr(ctx.Tr("repo.form.reach_limit_of_creation", ctxUser.MaxCreationLimit()), tplFork, &form)
 		case models.IsErrRepoAlreadyExist(err):
 			ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplFork, &form)
 		case models.IsErrNameReserved(err):
@@ -447,6 +450,17 @@ func PrepareViewPullInfo(ctx *context.Context, issue *models.Issue) *git.Compare
 		ctx.Data["GetCommitMessages"] = pull_service.GetSquashMergeCommitMessages(pull)
 	}
 
+	if !headBranchExist && !issue.IsClosed {
+		state, err := pull_service.GetHeadBranchState(pull)
+		if err != nil {
+			ctx.ServerError("GetHeadBranchState", err)
+			return nil
+		}
+		// offer to close pull requests whose head branch was deleted
+		ctx.Data["IsPullHeadBranchDeleted"] = true
+		ctx.Data["IsPullHeadBranchMerged"] = state == pull_service.HeadBranchDeletedMerged
+	}
+
 	sha, err := baseGitRepo.GetRefCommitID(pull.GetGitRefName())
 	if err != nil {
 		if git.IsErrNotExist(err) {
@@ -622,6 +636,7 @@ func ViewPullFiles(ctx *context.Context) {
 	ctx.Data["Username"] = ctx.Repo.Owner.Name
 	ctx.Data["Reponame"] = ctx.Repo.Repository.Name
 	ctx.Data["AfterCommitID"] = endCommitID
+	ctx.Data["DiffLinesURL"] = fmt.Sprintf("%s/pulls/%d/files/lines", ctx.Repo.RepoLink, issue.Index)
 
 	diff, err := gitdiff.GetDiffRangeWithWhitespaceBehavior(diffRepoPath,
 		startCommitID, endCommitID, setting.Git.MaxGitDiffLines,
@@ -637,6 +652,13 @@ func ViewPullFiles(ctx *context.Context) {
 		return
 	}
 
+	annotations, err := models.GetCommitStatusAnnotations(ctx.Repo.Repository.ID, headCommitID)
+	if err != nil {
+		ctx.ServerError("GetCommitStatusAnnotations", err)
+		return
+	}
+	diff.LoadAnnotations(annotations)
+
 	if err = pull.LoadProtectedBranch(); err != nil {
 		ctx.ServerError("LoadProtectedBranch", err)
 		return
@@ -697,6 +719,38 @@ func ViewPullFiles(ctx *context.Context) {
 	ctx.HTML(200, tplPullFiles)
 }
 
+// ViewPullFilesLines render the lines following the ones already shown of a truncated file of a pull request
+func ViewPullFilesLines(ctx *context.Context) {
+	ctx.Data["PageIsPullFiles"] = true
+
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pull := issue.PullRequest
+
+	headCommitID, err := ctx.Repo.GitRepo.GetRefCommitID(pull.GetGitRefName())
+	if err != nil {
+		ctx.ServerError("GetRefCommitID", err)
+		return
+	}
+
+	if ctx.IsSigned && ctx.User != nil {
+		if ctx.Data["CanMarkConversation"], err = models.CanMarkConversation(issue, ctx.User); err != nil {
+			ctx.ServerError("CanMarkConversation", err)
+			return
+		}
+	}
+	ctx.Data["CurrentReview"], err = models.GetCurrentReview(ctx.User, issue)
+	if err != nil && !models.IsErrReviewNotExist(err) {
+		ctx.ServerError("GetCurrentReview", err)
+		return
+	}
+
+	ctx.Data["DiffLinesURL"] = fmt.Sprintf("%s/pulls/%d/files/lines", ctx.Repo.RepoLink, issue.Index)
+	renderDiffLines(ctx, pull.MergeBase, headCommitID, issue)
+}
+
 // UpdatePullRequest merge PR's baseBranch into headBranch
 func UpdatePullRequest(ctx *context.Context) {
 	issue := checkPullInfo(ctx)
@@ -758,12 +812,29 @@ func UpdatePullRequest(ctx *context.Context) {
 		return
 	}
 
-	time.Sleep(1 * time.Second)
-
 	ctx.Flash.Success(ctx.Tr("repo.pulls.update_branch_success"))
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index))
 }
 
+// PullMergeStatus returns the mergeable status of a pull request, which the pull request page polls
+// while it is being checked in the background
+func PullMergeStatus(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+
+	conflictedFiles := pr.ConflictedFiles
+	if !pr.IsFilesConflicted() {
+		conflictedFiles = []string{}
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"status":           pr.Status.Name(),
+		"conflicted_files": conflictedFiles,
+	})
+}
+
 // MergePullRequest response for merging pull request
 func MergePullRequest(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.MergePullRequestForm)
@@ -834,6 +905,14 @@ func MergePullRequest(ctx *context.Context) {
 		return
 	}
 
+	if isQueueEnabled, err := mergequeue.IsEnabled(pr); err != nil {
+		ctx.ServerError("IsMergeQueueEnabled", err)
+		return
+	} else if isQueueEnabled {
+		addToMergeQueue(ctx, form, issue)
+		return
+	}
+
 	if err := pull_service.CheckPRReadyToMerge(pr, false); err != nil {
 		if !models.IsErrNotAllowedToMerge(err) {
 			ctx.ServerError("Merge PR status", err)
@@ -964,6 +1043,227 @@ func MergePullRequest(ctx *context.Context) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(pr.Index))
 }
 
+// ScheduleAutoMerge schedules a pull request to be merged as soon as all required status checks and reviews pass
+func ScheduleAutoMerge(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.MergePullRequestForm)
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	link := ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index)
+
+	if issue.IsClosed || pr.HasMerged {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.is_closed"))
+		ctx.Redirect(link)
+		return
+	}
+
+	allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+	if err != nil {
+		ctx.ServerError("IsUserAllowedToMerge", err)
+		return
+	}
+	if !allowedMerge {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	prUnit, err := ctx.Repo.Repository.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		ctx.ServerError("GetUnit", err)
+		return
+	}
+	style := models.MergeStyle(form.Do)
+	if style == models.MergeStyleManuallyMerged || !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+		ctx.Redirect(link)
+		return
+	}
+
+	// an empty message is replaced by the default message of the style when merging
+	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(message) > 0 && len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if err := automerge.ScheduleAutoMerge(ctx.User, pr, style, message); err != nil {
+		if models.IsErrPullAlreadyScheduledToAutoMerge(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_already_scheduled"))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("ScheduleAutoMerge", err)
+		return
+	}
+
+	log.Trace("Pull request scheduled to auto merge: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_newly_scheduled"))
+	ctx.Redirect(link)
+}
+
+// CancelAutoMerge cancels the automatic merge of a pull request
+func CancelAutoMerge(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	link := ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index)
+
+	exist, autoMerge, err := models.GetScheduledMergeByPullID(pr.ID)
+	if err != nil && !models.IsErrUserNotExist(err) {
+		ctx.ServerError("GetScheduledMergeByPullID", err)
+		return
+	}
+	if !exist {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.auto_merge_not_scheduled"))
+		ctx.Redirect(link)
+		return
+	}
+
+	// the user who scheduled the merge can always cancel it
+	if autoMerge.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+			ctx.Redirect(link)
+			return
+		}
+	}
+
+	if err := automerge.RemoveScheduledAutoMerge(ctx.User, pr); err != nil {
+		ctx.ServerError("RemoveScheduledAutoMerge", err)
+		return
+	}
+
+	log.Trace("Auto merge of pull request canceled: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.auto_merge_canceled"))
+	ctx.Redirect(link)
+}
+
+// addToMergeQueue adds a pull request to the merge queue of its base branch instead of merging it
+func addToMergeQueue(ctx *context.Context, form *auth.MergePullRequestForm, issue *models.Issue) {
+	pr := issue.PullRequest
+	link := ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index)
+
+	if err := pull_service.CheckPRReadyToQueue(pr); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			ctx.ServerError("CheckPRReadyToQueue", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_not_ready"))
+		ctx.Redirect(link)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(link)
+		return
+	}
+
+	prUnit, err := ctx.Repo.Repository.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		ctx.ServerError("GetUnit", err)
+		return
+	}
+	style := models.MergeStyle(form.Do)
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(style) {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+		ctx.Redirect(link)
+		return
+	}
+
+	pr.Issue = issue
+	pr.Issue.Repo = ctx.Repo.Repository
+	if noDeps, err := models.IssueNoDependenciesLeft(issue); err != nil {
+		ctx.ServerError("IssueNoDependenciesLeft", err)
+		return
+	} else if !noDeps {
+		ctx.Flash.Error(ctx.Tr("repo.issues.dependency.pr_close_blocked"))
+		ctx.Redirect(link)
+		return
+	}
+
+	// an empty message is replaced by the default message of the style when merging
+	message := strings.TrimSpace(form.MergeTitleField)
+	form.MergeMessageField = strings.TrimSpace(form.MergeMessageField)
+	if len(message) > 0 && len(form.MergeMessageField) > 0 {
+		message += "\n\n" + form.MergeMessageField
+	}
+
+	if err := mergequeue.Add(ctx.User, pr, style, message); err != nil {
+		if models.IsErrPullAlreadyInMergeQueue(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_already_added"))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("AddToMergeQueue", err)
+		return
+	}
+
+	log.Trace("Pull request added to the merge queue: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_newly_added"))
+	ctx.Redirect(link)
+}
+
+// CancelMergeQueue removes a pull request from the merge queue of its base branch
+func CancelMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	pr := issue.PullRequest
+	link := ctx.Repo.RepoLink + "/pulls/" + fmt.Sprint(issue.Index)
+
+	exist, entry, err := models.GetMergeQueueEntryByPullID(pr.ID)
+	if err != nil && !models.IsErrUserNotExist(err) {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if !exist {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_not_added"))
+		ctx.Redirect(link)
+		return
+	}
+
+	// the user who added the pull request can always remove it
+	if entry.DoerID != ctx.User.ID {
+		allowedMerge, err := pull_service.IsUserAllowedToMerge(pr, ctx.Repo.Permission, ctx.User)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		}
+		if !allowedMerge {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.update_not_allowed"))
+			ctx.Redirect(link)
+			return
+		}
+	}
+
+	if err := mergequeue.Remove(ctx.User, pr); err != nil {
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+
+	log.Trace("Pull request removed from the merge queue: %d", pr.ID)
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_canceled"))
+	ctx.Redirect(link)
+}
+
 func stopTimerIfAvailable(user *models.User, issue *models.Issue) error {
 
 	if models.StopwatchExists(user.ID, issue.ID) {
@@ -8,15 +8,19 @@ package repo
 import (
 	"errors"
 	"fmt"
-	"strings"
+	"net/http"
+	"os"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	archiver_service "code.gitea.io/gitea/services/archiver"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -34,6 +38,14 @@ func MustBeNotEmpty(ctx *context.Context) {
 	}
 }
 
+// MustAuthenticateForGit refuses the anonymous users when the repository requires authentication for git operations,
+// for the routes serving its git data
+func MustAuthenticateForGit(ctx *context.Context) {
+	if ctx.User == nil && ctx.Repo.Repository.RequireGitAuth {
+		ctx.Error(http.StatusUnauthorized, "Authentication is required to access the git data of this repository")
+	}
+}
+
 // MustBeEditable check that repo can be edited
 func MustBeEditable(ctx *context.Context) {
 	if !ctx.Repo.Repository.CanEnableEditor() || ctx.Repo.IsViewCommit {
@@ -103,19 +115,6 @@ func checkContextUser(ctx *context.Context, uid int64) *models.User {
 	return org
 }
 
-func getRepoPrivate(ctx *context.Context) bool {
-	switch strings.ToLower(setting.Repository.DefaultPrivate) {
-	case setting.RepoCreatingLastUserVisibility:
-		return ctx.User.LastRepoVisibility
-	case setting.RepoCreatingPrivate:
-		return true
-	case setting.RepoCreatingPublic:
-		return false
-	default:
-		return ctx.User.LastRepoVisibility
-	}
-}
-
 // Create render creating repository page
 func Create(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("new_repo")
@@ -126,8 +125,6 @@ func Create(ctx *context.Context) {
 	ctx.Data["Licenses"] = models.Licenses
 	ctx.Data["Readmes"] = models.Readmes
 	ctx.Data["readme"] = "Default"
-	ctx.Data["private"] = getRepoPrivate(ctx)
-	ctx.Data["IsForcedPrivate"] = setting.Repository.ForcePrivate
 	ctx.Data["default_branch"] = setting.Repository.DefaultBranch
 
 	ctxUser := checkContextUser(ctx, ctx.QueryInt64("org"))
@@ -135,6 +132,8 @@ func Create(ctx *context.Context) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	ctx.Data["private"] = ctxUser.IsRepoPrivateByDefault(ctx.User)
+	setForcedPrivateData(ctx, ctxUser)
 
 	ctx.Data["repo_template_name"] = ctx.Tr("repo.template_select")
 	templateID := ctx.QueryInt64("template_id")
@@ -146,12 +145,22 @@ func Create(ctx *context.Context) {
 		}
 	}
 
-	ctx.Data["CanCreateRepo"] = ctx.User.CanCreateRepo()
-	ctx.Data["MaxCreationLimit"] = ctx.User.MaxCreationLimit()
+	ctx.Data["CanCreateRepo"] = ctx.User.IsAdmin || ctxUser.CanCreateRepo()
+	ctx.Data["MaxCreationLimit"] = ctxUser.MaxCreationLimit()
+	if !ctx.User.IsAdmin {
+		ctx.Data["RemainingRepoCreations"] = ctxUser.RemainingRepoCreations()
+	}
 
 	ctx.HTML(200, tplCreate)
 }
 
+// setForcedPrivateData tells the creation forms whether the new repositories of the owner must be private,
+// and whether it is due to the instance or to the organization
+func setForcedPrivateData(ctx *context.Context, owner *models.User) {
+	ctx.Data["IsForcedPrivate"] = owner.IsRepoPrivateForced()
+	ctx.Data["IsForcedPrivateByOrg"] = !setting.Repository.ForcePrivate && owner.IsRepoPrivateForced()
+}
+
 func handleCreateError(ctx *context.Context, owner *models.User, err error, name string, tpl base.TplName, form interface{}) {
 	switch {
 	case models.IsErrReachLimitOfRepo(err):
@@ -197,19 +206,22 @@ func CreatePost(ctx *context.Context) {
 		return
 	}
 	ctx.Data["ContextUser"] = ctxUser
+	setForcedPrivateData(ctx, ctxUser)
 
 	if ctx.HasError() {
 		ctx.HTML(200, tplCreate)
 		return
 	}
 
+	isPrivate := form.Private || ctxUser.IsRepoPrivateForced()
+
 	var repo *models.Repository
 	var err error
 	if form.RepoTemplate > 0 {
 		opts := models.GenerateRepoOptions{
 			Name:        form.RepoName,
 			Description: form.Description,
-			Private:     form.Private,
+			Private:     isPrivate,
 			GitContent:  form.GitContent,
 			Topics:      form.Topics,
 			GitHooks:    form.GitHooks,
@@ -247,7 +259,7 @@ func CreatePost(ctx *context.Context) {
 			IssueLabels:   form.IssueLabels,
 			License:       form.License,
 			Readme:        form.Readme,
-			IsPrivate:     form.Private || setting.Repository.ForcePrivate,
+			IsPrivate:     isPrivate,
 			DefaultBranch: form.DefaultBranch,
 			AutoInit:      form.AutoInit,
 			IsTemplate:    form.Template,
@@ -369,8 +381,20 @@ func Download(ctx *context.Context) {
 		ctx.Error(404)
 		return
 	}
+	// a bundle can be cloned, so it is protected like the git operations
+	if aReq.IsBundle() {
+		MustAuthenticateForGit(ctx)
+		if ctx.Written() {
+			return
+		}
+	}
 
 	downloadName := ctx.Repo.Repository.Name + "-" + aReq.GetArchiveName()
+	if !setting.RepoArchive.EnableCache {
+		serveUncachedArchive(ctx, aReq, downloadName)
+		return
+	}
+
 	complete := aReq.IsComplete()
 	if !complete {
 		aReq = archiver_service.ArchiveRepository(aReq)
@@ -378,12 +402,77 @@ func Download(ctx *context.Context) {
 	}
 
 	if complete {
-		ctx.ServeFile(aReq.GetArchivePath(), downloadName)
+		serveCachedArchive(ctx, aReq, downloadName)
 	} else {
 		ctx.Error(404)
 	}
 }
 
+func setArchiveHeaders(ctx *context.Context, aReq *archiver_service.ArchiveRequest, downloadName string) {
+	contentType := "application/octet-stream"
+	if aReq.IsBundle() {
+		contentType = "application/x-git-bundle"
+	}
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Resp.Header().Set("Content-Disposition", "attachment; filename="+downloadName)
+	// The same URL serves a new archive once a branch moves, so clients must
+	// revalidate using the ETag and Last-Modified headers.
+	ctx.Resp.Header().Set("Cache-Control", "must-revalidate")
+}
+
+func serveCachedArchive(ctx *context.Context, aReq *archiver_service.ArchiveRequest, downloadName string) {
+	if setting.RepoArchive.ServeDirect {
+		//If we have a signed url (S3, object storage), redirect to this directly.
+		u, err := storage.RepoArchives.URL(aReq.GetArchivePath(), downloadName)
+		if u != nil && err == nil {
+			ctx.Redirect(u.String())
+			return
+		}
+	}
+
+	fr, err := storage.RepoArchives.Open(aReq.GetArchivePath())
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	fi, err := fr.Stat()
+	if err != nil {
+		ctx.ServerError("Stat", err)
+		return
+	}
+	if httpcache.HandleEtagCache(ctx.Req, ctx.Resp, fi) || httpcache.HandleTimeCache(ctx.Req, ctx.Resp, fi) {
+		return
+	}
+
+	setArchiveHeaders(ctx, aReq, downloadName)
+	http.ServeContent(ctx.Resp, ctx.Req, downloadName, fi.ModTime(), fr)
+}
+
+func serveUncachedArchive(ctx *context.Context, aReq *archiver_service.ArchiveRequest, downloadName string) {
+	tmpPath, err := archiver_service.CreateTemporaryArchive(aReq)
+	if err != nil {
+		ctx.ServerError("CreateTemporaryArchive", err)
+		return
+	}
+	defer func() {
+		if err := util.Remove(tmpPath); err != nil {
+			log.Warn("Unable to remove temporary archive %s: %v", tmpPath, err)
+		}
+	}()
+
+	fr, err := os.Open(tmpPath)
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	setArchiveHeaders(ctx, aReq, downloadName)
+	http.ServeContent(ctx.Resp, ctx.Req, downloadName, time.Now(), fr)
+}
+
 // InitiateDownload will enqueue an archival request, as needed.  It may submit
 // a request that's already in-progress, but the archiver service will just
 // kind of drop it on the floor if this is the case.
@@ -395,8 +484,16 @@ func InitiateDownload(ctx *context.Context) {
 		ctx.Error(404)
 		return
 	}
+	// a bundle can be cloned, so it is protected like the git operations
+	if aReq.IsBundle() {
+		MustAuthenticateForGit(ctx)
+		if ctx.Written() {
+			return
+		}
+	}
 
-	complete := aReq.IsComplete()
+	// Without the cache, the archive is generated when it is downloaded.
+	complete := aReq.IsComplete() || !setting.RepoArchive.EnableCache
 	if !complete {
 		aReq = archiver_service.ArchiveRepository(aReq)
 		complete, _ = aReq.TimedWaitForCompletion(ctx, 2*time.Second)
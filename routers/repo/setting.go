@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"path"
 	"strings"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
@@ -46,21 +48,143 @@ func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
 	ctx.Data["ForcePrivate"] = setting.Repository.ForcePrivate
+	ctx.Data["DeletedRetention"] = int64(setting.Repository.DeletedRetention.Seconds())
 
 	signing, _ := models.SigningKey(ctx.Repo.Repository.RepoPath())
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 
+	if ctx.Repo.Repository.IsMirror {
+		if err := setMirrorAuthContext(ctx); err != nil {
+			ctx.ServerError("setMirrorAuthContext", err)
+			return
+		}
+	}
+
+	if ctx.User.IsAdmin {
+		nextIssueIndex, err := models.GetNextIssueIndex(ctx.Repo.Repository)
+		if err != nil {
+			ctx.ServerError("GetNextIssueIndex", err)
+			return
+		}
+		ctx.Data["NextIssueIndex"] = nextIssueIndex
+	}
+
+	if unit, err := ctx.Repo.Repository.GetUnit(models.UnitTypeIssues); err == nil {
+		names, err := models.GetUserNamesByIDs(unit.IssuesConfig().DefaultAssigneeIDs)
+		if err != nil {
+			ctx.ServerError("GetUserNamesByIDs", err)
+			return
+		}
+		ctx.Data["DefaultAssignees"] = strings.Join(names, ", ")
+	}
+	if unit, err := ctx.Repo.Repository.GetUnit(models.UnitTypePullRequests); err == nil {
+		names, err := models.GetUserNamesByIDs(unit.PullRequestsConfig().DefaultReviewerIDs)
+		if err != nil {
+			ctx.ServerError("GetUserNamesByIDs", err)
+			return
+		}
+		ctx.Data["DefaultReviewers"] = strings.Join(names, ", ")
+	}
+
+	gcTask, err := models.GetLastRepoTask(ctx.Repo.Repository.ID, structs.TaskTypeGarbageCollectRepo)
+	if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		ctx.ServerError("GetLastRepoTask", err)
+		return
+	}
+	if err == nil {
+		ctx.Data["GarbageCollectTask"] = gcTask
+		ctx.Data["GarbageCollectPending"] = repo_service.IsGarbageCollectPending(gcTask)
+		if gcTask.Status == structs.TaskStatusFinished {
+			result, err := repo_service.GetGarbageCollectResult(gcTask)
+			if err != nil {
+				ctx.ServerError("GetGarbageCollectResult", err)
+				return
+			}
+			ctx.Data["GarbageCollectResult"] = result
+		}
+	}
+
 	ctx.HTML(200, tplSettingsOptions)
 }
 
+// GarbageCollectPost queues a garbage collection of the repository, its status is shown in the settings
+func GarbageCollectPost(ctx *context.Context) {
+	if _, err := repo_service.GarbageCollect(ctx.User, ctx.Repo.Repository); err != nil {
+		ctx.ServerError("GarbageCollect", err)
+		return
+	}
+
+	ctx.Flash.Info(ctx.Tr("repo.settings.gc_queued"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+}
+
+// parseDefaultUsers returns the IDs of the users of the comma separated list of names, who have to be
+// able to be assigned to new issues, or to review new pull requests if isPull is set.
+// It redirects to the settings with an error message if a user isn't valid.
+func parseDefaultUsers(ctx *context.Context, names string, isPull bool) ([]int64, bool) {
+	repo := ctx.Repo.Repository
+	var ids []int64
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		u, err := models.GetUserByName(name)
+		if err != nil {
+			if !models.IsErrUserNotExist(err) {
+				ctx.ServerError("GetUserByName", err)
+				return nil, false
+			}
+			u = nil
+		}
+
+		valid := false
+		if u != nil && !u.IsOrganization() {
+			if isPull {
+				perm, err := models.GetUserRepoPermission(repo, u)
+				if err != nil {
+					ctx.ServerError("GetUserRepoPermission", err)
+					return nil, false
+				}
+				valid = perm.CanAccessAny(models.AccessModeRead, models.UnitTypePullRequests)
+			} else if valid, err = models.CanBeAssigned(u, repo, false); err != nil {
+				ctx.ServerError("CanBeAssigned", err)
+				return nil, false
+			}
+		}
+		if !valid {
+			if isPull {
+				ctx.Flash.Error(ctx.Tr("repo.settings.pulls.default_reviewers_invalid", name))
+			} else {
+				ctx.Flash.Error(ctx.Tr("repo.settings.default_assignees_invalid", name))
+			}
+			ctx.Redirect(repo.Link() + "/settings")
+			return nil, false
+		}
+
+		if !util.IsInt64InSlice(u.ID, ids) {
+			ids = append(ids, u.ID)
+		}
+	}
+	return ids, true
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*auth.RepoSettingForm)
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+	ctx.Data["DeletedRetention"] = int64(setting.Repository.DeletedRetention.Seconds())
 
 	repo := ctx.Repo.Repository
+	if repo.IsMirror {
+		if err := setMirrorAuthContext(ctx); err != nil {
+			ctx.ServerError("setMirrorAuthContext", err)
+			return
+		}
+	}
 
 	switch ctx.Query("action") {
 	case "update":
@@ -112,6 +236,7 @@ func SettingsPost(ctx *context.Context) {
 		repo.Description = form.Description
 		repo.Website = form.Website
 		repo.IsTemplate = form.Template
+		repo.RequireGitAuth = form.RequireGitAuth
 
 		// Visibility of forked repository is forced sync with base repository.
 		if repo.IsFork {
@@ -201,6 +326,10 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if !updateMirrorAuth(ctx, form) {
+			return
+		}
+
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -229,6 +358,21 @@ func SettingsPost(ctx *context.Context) {
 			repoChanged = true
 		}
 
+		allowedReactions := make([]string, 0, len(form.AllowedReactions))
+		for _, reaction := range form.AllowedReactions {
+			if setting.UI.ReactionsMap[reaction] && !util.IsStringInSlice(reaction, allowedReactions) {
+				allowedReactions = append(allowedReactions, reaction)
+			}
+		}
+		// Allowing every reaction of the instance is the default, which follows changes of the instance settings
+		if len(allowedReactions) == len(setting.UI.Reactions) {
+			allowedReactions = nil
+		}
+		if strings.Join(allowedReactions, ",") != strings.Join(repo.AllowedReactions, ",") {
+			repo.AllowedReactions = allowedReactions
+			repoChanged = true
+		}
+
 		if form.EnableWiki && form.EnableExternalWiki && !models.UnitTypeExternalWiki.UnitGlobalDisabled() {
 			if !validation.IsValidExternalURL(form.ExternalWikiURL) {
 				ctx.Flash.Error(ctx.Tr("repo.settings.external_wiki_url_error"))
@@ -248,7 +392,9 @@ func SettingsPost(ctx *context.Context) {
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypeWiki,
-				Config: new(models.UnitConfig),
+				Config: &models.WikiConfig{
+					IncludeTOC: form.WikiIncludeTOC,
+				},
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeExternalWiki)
 		} else {
@@ -282,6 +428,10 @@ func SettingsPost(ctx *context.Context) {
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeIssues)
 		} else if form.EnableIssues && !form.EnableExternalTracker && !models.UnitTypeIssues.UnitGlobalDisabled() {
+			defaultAssigneeIDs, ok := parseDefaultUsers(ctx, form.DefaultAssignees, false)
+			if !ok {
+				return
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypeIssues,
@@ -289,6 +439,7 @@ func SettingsPost(ctx *context.Context) {
 					EnableTimetracker:                form.EnableTimetracker,
 					AllowOnlyContributorsToTrackTime: form.AllowOnlyContributorsToTrackTime,
 					EnableDependencies:               form.EnableIssueDependencies,
+					DefaultAssigneeIDs:               defaultAssigneeIDs,
 				},
 			})
 			deleteUnitTypes = append(deleteUnitTypes, models.UnitTypeExternalTracker)
@@ -311,6 +462,10 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		if form.EnablePulls && !models.UnitTypePullRequests.UnitGlobalDisabled() {
+			defaultReviewerIDs, ok := parseDefaultUsers(ctx, form.DefaultReviewers, true)
+			if !ok {
+				return
+			}
 			units = append(units, models.RepoUnit{
 				RepoID: repo.ID,
 				Type:   models.UnitTypePullRequests,
@@ -322,6 +477,7 @@ func SettingsPost(ctx *context.Context) {
 					AllowSquash:               form.PullsAllowSquash,
 					AllowManualMerge:          form.PullsAllowManualMerge,
 					AutodetectManualMerge:     form.EnableAutodetectManualMerge,
+					DefaultReviewerIDs:        defaultReviewerIDs,
 				},
 			})
 		} else if !models.UnitTypePullRequests.UnitGlobalDisabled() {
@@ -363,6 +519,22 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "readme":
+		if ctx.HasError() {
+			ctx.HTML(200, tplSettingsOptions)
+			return
+		}
+		repo.ReadmePath = strings.Trim(path.Clean("/"+strings.TrimSpace(form.ReadmePath)), "/")
+		repo.ShowReadmeOverlay = form.ShowReadmeOverlay
+		if err := models.UpdateRepositoryCols(repo, "readme_path", "show_readme_overlay"); err != nil {
+			ctx.ServerError("UpdateRepositoryCols", err)
+			return
+		}
+		log.Trace("Repository README settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(403)
@@ -378,6 +550,25 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if form.NextIssueIndex > 0 {
+			nextIssueIndex, err := models.GetNextIssueIndex(repo)
+			if err != nil {
+				ctx.ServerError("GetNextIssueIndex", err)
+				return
+			}
+			if form.NextIssueIndex != nextIssueIndex {
+				if err := models.SetNextIssueIndex(repo, form.NextIssueIndex); err != nil {
+					if models.IsErrIssueIndexTooLow(err) {
+						ctx.Flash.Error(ctx.Tr("repo.settings.admin_next_issue_index_too_low", err.(models.ErrIssueIndexTooLow).MaxIndex))
+						ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+						return
+					}
+					ctx.ServerError("SetNextIssueIndex", err)
+					return
+				}
+			}
+		}
+
 		log.Trace("Repository admin settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
@@ -544,8 +735,18 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 		log.Trace("Repository deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
-
-		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		ctx.Audit(&models.AuditEvent{
+			Action:     models.AuditRepoDelete,
+			TargetType: models.AuditTargetRepository,
+			TargetID:   repo.ID,
+			TargetName: repo.FullName(),
+		})
+
+		if repo.IsDeleted {
+			ctx.Flash.Success(ctx.Tr("repo.settings.deletion_pending", repo.PurgeUnix().FormatDate()))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		}
 		ctx.Redirect(ctx.Repo.Owner.DashboardLink())
 
 	case "delete-wiki":
@@ -682,6 +883,7 @@ func CollaborationPost(ctx *context.Context) {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
+	auditCollaboration(ctx, models.AuditCollaboratorAdd, u.ID, "")
 
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.User, ctx.Repo.Repository)
@@ -697,7 +899,9 @@ func ChangeCollaborationAccessMode(ctx *context.Context) {
 		ctx.QueryInt64("uid"),
 		models.AccessMode(ctx.QueryInt("mode"))); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+		return
 	}
+	auditCollaboration(ctx, models.AuditCollaboratorUpdate, ctx.QueryInt64("uid"), "mode: "+models.AccessMode(ctx.QueryInt("mode")).String())
 }
 
 // DeleteCollaboration delete a collaboration for a repository
@@ -705,6 +909,7 @@ func DeleteCollaboration(ctx *context.Context) {
 	if err := ctx.Repo.Repository.DeleteCollaboration(ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		auditCollaboration(ctx, models.AuditCollaboratorRemove, ctx.QueryInt64("id"), "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -713,6 +918,24 @@ func DeleteCollaboration(ctx *context.Context) {
 	})
 }
 
+// auditCollaboration records a change of the collaborators of the repository in the audit log
+func auditCollaboration(ctx *context.Context, action models.AuditAction, uid int64, desc string) {
+	collaborator := fmt.Sprintf("collaborator: %d", uid)
+	if u, err := models.GetUserByID(uid); err == nil {
+		collaborator = "collaborator: " + u.Name
+	}
+	if desc != "" {
+		collaborator += ", " + desc
+	}
+	ctx.Audit(&models.AuditEvent{
+		Action:      action,
+		TargetType:  models.AuditTargetRepository,
+		TargetID:    ctx.Repo.Repository.ID,
+		TargetName:  ctx.Repo.Repository.FullName(),
+		Description: collaborator,
+	})
+}
+
 // AddTeamPost response for adding a team to a repository
 func AddTeamPost(ctx *context.Context) {
 	if !ctx.Repo.Owner.RepoAdminChangeTeamAccess && !ctx.Repo.IsOwner() {
@@ -754,6 +977,13 @@ func AddTeamPost(ctx *context.Context) {
 		ctx.ServerError("team.AddRepository", err)
 		return
 	}
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditTeamUpdate,
+		TargetType:  models.AuditTargetTeam,
+		TargetID:    team.ID,
+		TargetName:  ctx.Repo.Owner.Name + "/" + team.Name,
+		Description: "repository added: " + ctx.Repo.Repository.FullName(),
+	})
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_team_success"))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
@@ -777,6 +1007,13 @@ func DeleteTeam(ctx *context.Context) {
 		ctx.ServerError("team.RemoveRepositorys", err)
 		return
 	}
+	ctx.Audit(&models.AuditEvent{
+		Action:      models.AuditTeamUpdate,
+		TargetType:  models.AuditTargetTeam,
+		TargetID:    team.ID,
+		TargetName:  ctx.Repo.Owner.Name + "/" + team.Name,
+		Description: "repository removed: " + ctx.Repo.Repository.FullName(),
+	})
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.remove_team_success"))
 	ctx.JSON(200, map[string]interface{}{
@@ -0,0 +1,93 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplAccessTokens base.TplName = "repo/settings/access_tokens"
+)
+
+// AccessTokens render the read-only access tokens of a repository
+func AccessTokens(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.access_tokens")
+	ctx.Data["PageIsSettingsAccessTokens"] = true
+
+	tokens, err := models.ListRepoAccessTokens(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("ListRepoAccessTokens", err)
+		return
+	}
+	ctx.Data["Tokens"] = tokens
+
+	ctx.HTML(http.StatusOK, tplAccessTokens)
+}
+
+// AccessTokensPost response for creating a read-only access token of a repository
+func AccessTokensPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewAccessTokenForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings.access_tokens")
+	ctx.Data["PageIsSettingsAccessTokens"] = true
+
+	if ctx.HasError() {
+		tokens, err := models.ListRepoAccessTokens(ctx.Repo.Repository.ID)
+		if err != nil {
+			ctx.ServerError("ListRepoAccessTokens", err)
+			return
+		}
+		ctx.Data["Tokens"] = tokens
+
+		ctx.HTML(http.StatusOK, tplAccessTokens)
+		return
+	}
+
+	exist, err := models.RepoAccessTokenByNameExists(ctx.Repo.Repository.ID, form.Name)
+	if err != nil {
+		ctx.ServerError("RepoAccessTokenByNameExists", err)
+		return
+	}
+	if exist {
+		ctx.Flash.Error(ctx.Tr("settings.generate_token_name_duplicate", form.Name))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/access_tokens")
+		return
+	}
+
+	t := &models.RepoAccessToken{
+		RepoID: ctx.Repo.Repository.ID,
+		Name:   form.Name,
+	}
+	if err := models.NewRepoAccessToken(t); err != nil {
+		ctx.ServerError("NewRepoAccessToken", err)
+		return
+	}
+
+	log.Trace("Repository access token added: %d", ctx.Repo.Repository.ID)
+	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
+	ctx.Flash.Info(t.Token)
+
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/access_tokens")
+}
+
+// DeleteAccessToken response for revoking a read-only access token of a repository
+func DeleteAccessToken(ctx *context.Context) {
+	if err := models.DeleteRepoAccessTokenByID(ctx.QueryInt64("id"), ctx.Repo.Repository.ID); err != nil {
+		ctx.Flash.Error("DeleteRepoAccessTokenByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/access_tokens",
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/migrations"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// setMirrorAuthContext exposes the non-secret parts of the mirror credential configuration
+func setMirrorAuthContext(ctx *context.Context) error {
+	helpers := make([]string, 0, len(setting.Mirror.CredentialHelpers))
+	for name := range setting.Mirror.CredentialHelpers {
+		helpers = append(helpers, name)
+	}
+	sort.Strings(helpers)
+	ctx.Data["MirrorCredentialHelpers"] = helpers
+
+	cfg, err := ctx.Repo.Mirror.GetAuthConfig()
+	if err != nil {
+		return err
+	}
+	ctx.Data["MirrorAuthConfig"] = &models.MirrorAuthConfig{
+		Helper:   cfg.Helper,
+		TokenURL: cfg.TokenURL,
+		ClientID: cfg.ClientID,
+		Username: cfg.Username,
+	}
+	return nil
+}
+
+// updateMirrorAuth updates how the mirror obtains its credentials.
+// Secrets left empty in the form keep their stored value.
+func updateMirrorAuth(ctx *context.Context, form *auth.RepoSettingForm) bool {
+	m := ctx.Repo.Mirror
+	cfg, err := m.GetAuthConfig()
+	if err != nil {
+		ctx.ServerError("GetAuthConfig", err)
+		return false
+	}
+
+	authType := models.MirrorAuthType(form.MirrorAuthType)
+	switch authType {
+	case models.MirrorAuthCredentialHelper:
+		if _, ok := setting.Mirror.CredentialHelpers[form.MirrorCredentialHelper]; !ok {
+			ctx.Data["Err_MirrorCredentialHelper"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_credential_helper_invalid"), tplSettingsOptions, form)
+			return false
+		}
+		cfg.Helper = form.MirrorCredentialHelper
+		if form.MirrorHelperInput != "" {
+			cfg.HelperInput = form.MirrorHelperInput
+		}
+	case models.MirrorAuthOAuth2:
+		tokenURL := strings.TrimSpace(form.MirrorOAuth2TokenURL)
+		if tokenURL == "" {
+			ctx.Data["Err_MirrorOAuth2TokenURL"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_oauth2_token_url_invalid"), tplSettingsOptions, form)
+			return false
+		}
+		if err := migrations.IsMigrateURLAllowed(tokenURL, ctx.User); err != nil {
+			ctx.Data["Err_MirrorOAuth2TokenURL"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_oauth2_token_url_invalid"), tplSettingsOptions, form)
+			return false
+		}
+		cfg.TokenURL = tokenURL
+		cfg.ClientID = strings.TrimSpace(form.MirrorOAuth2ClientID)
+		cfg.Username = strings.TrimSpace(form.MirrorOAuth2Username)
+		if form.MirrorOAuth2ClientSecret != "" {
+			cfg.ClientSecret = form.MirrorOAuth2ClientSecret
+		}
+		if form.MirrorOAuth2RefreshToken != "" {
+			cfg.RefreshToken = form.MirrorOAuth2RefreshToken
+		}
+		if cfg.RefreshToken == "" {
+			ctx.Data["Err_MirrorOAuth2RefreshToken"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_oauth2_refresh_token_required"), tplSettingsOptions, form)
+			return false
+		}
+	default:
+		cfg = &models.MirrorAuthConfig{}
+	}
+
+	m.AuthType = authType
+	if err := m.SetAuthConfig(cfg); err != nil {
+		ctx.ServerError("SetAuthConfig", err)
+		return false
+	}
+	if err := models.UpdateMirrorAuth(m); err != nil {
+		ctx.ServerError("UpdateMirrorAuth", err)
+		return false
+	}
+	return true
+}
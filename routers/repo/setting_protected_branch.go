@@ -6,16 +6,19 @@ package repo
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -52,6 +55,22 @@ func ProtectedBranch(ctx *context.Context) {
 	ctx.HTML(200, tplBranches)
 }
 
+// ProtectedBranchesJSON returns the protection rules of all protected branches of the repository,
+// including the users and teams allowed to push, merge and approve
+func ProtectedBranchesJSON(ctx *context.Context) {
+	protectedBranches, err := ctx.Repo.Repository.GetProtectedBranches()
+	if err != nil {
+		ctx.ServerError("GetProtectedBranches", err)
+		return
+	}
+
+	rules := make([]*api.BranchProtection, 0, len(protectedBranches))
+	for _, pb := range protectedBranches {
+		rules = append(rules, convert.ToBranchProtection(pb))
+	}
+	ctx.JSON(http.StatusOK, rules)
+}
+
 // ProtectedBranchPost response for protect for a branch of a repository
 func ProtectedBranchPost(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
@@ -253,6 +272,8 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.RequireSignedCommits = f.RequireSignedCommits
 		protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
+		protectBranch.EnableMergeQueue = f.EnableMergeQueue
+		protectBranch.RequireCodeOwnerReviews = f.RequireCodeOwnerReviews
 
 		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
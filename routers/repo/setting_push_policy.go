@@ -0,0 +1,101 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	tplPushPolicy base.TplName = "repo/settings/push_policy"
+
+	bytesPerMB = 1024 * 1024
+)
+
+// PushPolicy render the push policy settings page
+func PushPolicy(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.push_policy")
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+	ctx.Data["ConventionalCommitPattern"] = models.ConventionalCommitPattern
+	ctx.Data["DefaultForbiddenPaths"] = setting.Repository.PushPolicy.DefaultForbiddenPaths
+
+	policy, err := models.GetPushPolicyByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicyByRepoID", err)
+		return
+	}
+	ctx.Data["PushPolicy"] = policy
+	ctx.Data["MaxBlobSizeMB"] = policy.MaxBlobSize / bytesPerMB
+
+	ctx.HTML(http.StatusOK, tplPushPolicy)
+}
+
+// PushPolicyPost updates the push policy of a repository
+func PushPolicyPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.PushPolicyForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings.push_policy")
+	ctx.Data["PageIsSettingsPushPolicy"] = true
+	ctx.Data["ConventionalCommitPattern"] = models.ConventionalCommitPattern
+	ctx.Data["DefaultForbiddenPaths"] = setting.Repository.PushPolicy.DefaultForbiddenPaths
+
+	policy, err := models.GetPushPolicyByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetPushPolicyByRepoID", err)
+		return
+	}
+	ctx.Data["PushPolicy"] = policy
+	ctx.Data["MaxBlobSizeMB"] = form.MaxBlobSizeMB
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplPushPolicy)
+		return
+	}
+
+	policy.CommitMessageMode = models.PushPolicyMode(form.CommitMessageMode)
+	policy.CommitMessagePattern = strings.TrimSpace(form.CommitMessagePattern)
+	policy.CommitMessageAdminBypass = form.CommitMessageAdminBypass
+	policy.MaxBlobSize = form.MaxBlobSizeMB * bytesPerMB
+	policy.BlobSizeExemptions = strings.TrimSpace(form.BlobSizeExemptions)
+	policy.ForbiddenPaths = strings.TrimSpace(form.ForbiddenPaths)
+	policy.ForbiddenPathsUseDefaults = form.ForbiddenPathsUseDefaults
+	policy.ForbiddenPathsAdminBypass = form.ForbiddenPathsAdminBypass
+	policy.SecretScanningMode = models.PushPolicyMode(form.SecretScanningMode)
+	policy.BranchNameMode = models.PushPolicyMode(form.BranchNameMode)
+	policy.BranchNamePatterns = strings.TrimSpace(form.BranchNamePatterns)
+	policy.BranchNameExemptions = strings.TrimSpace(form.BranchNameExemptions)
+	if _, err := policy.CommitMessageRegexp(); err != nil {
+		ctx.Data["Err_CommitMessagePattern"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.push_policy.invalid_pattern", err.Error()), tplPushPolicy, form)
+		return
+	}
+	if _, err := models.CompileBranchNamePatterns(policy.BranchNamePatterns); err != nil {
+		ctx.Data["Err_BranchNamePatterns"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.push_policy.invalid_pattern", err.Error()), tplPushPolicy, form)
+		return
+	}
+	if _, err := models.CompileBranchNamePatterns(policy.BranchNameExemptions); err != nil {
+		ctx.Data["Err_BranchNameExemptions"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.push_policy.invalid_pattern", err.Error()), tplPushPolicy, form)
+		return
+	}
+
+	if err := models.UpdatePushPolicy(policy); err != nil {
+		ctx.ServerError("UpdatePushPolicy", err)
+		return
+	}
+	log.Trace("Repository push policy updated: %s", ctx.Repo.Repository.FullName())
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/push_policy")
+}
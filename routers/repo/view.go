@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/activitypub"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/charset"
@@ -28,6 +29,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web/middleware"
 )
 
 const (
@@ -53,8 +56,81 @@ func linesBytesCount(s []byte) int {
 	return n
 }
 
+// readmeLocales returns the lower-cased infixes of the README files localized for the
+// language, most specific first, followed by the empty infix of the unlocalized README.
+func readmeLocales(lang string) []string {
+	locales := make([]string, 0, 3)
+	lang = strings.ToLower(lang)
+	if lang != "" {
+		locales = append(locales, "."+lang)
+		if idx := strings.IndexByte(lang, '-'); idx > 0 {
+			locales = append(locales, "."+lang[:idx])
+		}
+	}
+	return append(locales, "")
+}
+
+// findReadmeFile returns the README in entries, preferring the one localized for the language.
+// For every locale the extensions are searched by priority, a README that doesn't strictly
+// match an extension is only used when no other README exists.
+func findReadmeFile(entries git.Entries, lang string) (*namedBlob, error) {
+	var exts = []string{".md", ".txt", ""} // sorted by priority
+	locales := readmeLocales(lang)
+
+	// one slot for every locale and extension, the last one is for a readme that doesn't
+	// strictly match an extension
+	readmeFiles := make([]*namedBlob, len(locales)*len(exts)+1)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		idx := -1
+	search:
+		for l, locale := range locales {
+			for i, ext := range exts {
+				if markup.IsReadmeFile(entry.Name(), locale+ext) {
+					idx = l*len(exts) + i
+					break search
+				}
+			}
+		}
+		if idx < 0 && markup.IsReadmeFile(entry.Name()) {
+			idx = len(readmeFiles) - 1
+		}
+		if idx < 0 || (readmeFiles[idx] != nil && !base.NaturalSortLess(readmeFiles[idx].name, entry.Name())) {
+			continue
+		}
+
+		name := entry.Name()
+		isSymlink := entry.IsLink()
+		target := entry
+		if isSymlink {
+			var err error
+			target, err = entry.FollowLinks()
+			if err != nil && !git.IsErrBadLink(err) {
+				return nil, err
+			}
+		}
+		if target != nil && (target.IsExecutable() || target.IsRegular()) {
+			readmeFiles[idx] = &namedBlob{
+				name,
+				isSymlink,
+				target.Blob(),
+			}
+		}
+	}
+
+	for _, f := range readmeFiles {
+		if f != nil {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
 // FIXME: There has to be a more efficient way of doing this
-func getReadmeFileFromPath(commit *git.Commit, treePath string) (*namedBlob, error) {
+func getReadmeFileFromPath(commit *git.Commit, treePath, lang string) (*namedBlob, error) {
 	tree, err := commit.SubTree(treePath)
 	if err != nil {
 		return nil, err
@@ -65,63 +141,134 @@ func getReadmeFileFromPath(commit *git.Commit, treePath string) (*namedBlob, err
 		return nil, err
 	}
 
-	var readmeFiles [4]*namedBlob
-	var exts = []string{".md", ".txt", ""} // sorted by priority
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		for i, ext := range exts {
-			if markup.IsReadmeFile(entry.Name(), ext) {
-				if readmeFiles[i] == nil || base.NaturalSortLess(readmeFiles[i].name, entry.Blob().Name()) {
-					name := entry.Name()
-					isSymlink := entry.IsLink()
-					target := entry
-					if isSymlink {
-						target, err = entry.FollowLinks()
-						if err != nil && !git.IsErrBadLink(err) {
-							return nil, err
-						}
-					}
-					if target != nil && (target.IsExecutable() || target.IsRegular()) {
-						readmeFiles[i] = &namedBlob{
-							name,
-							isSymlink,
-							target.Blob(),
-						}
-					}
-				}
-			}
+	return findReadmeFile(entries, lang)
+}
+
+// getConfiguredReadmeFile returns the README configured in the repository settings, or the version
+// of it localized for the language. It returns nil if the configured file does not exist.
+func getConfiguredReadmeFile(commit *git.Commit, readmePath, lang string) (*namedBlob, error) {
+	readmePath = strings.Trim(path.Clean("/"+readmePath), "/")
+	dir, name := path.Split(readmePath)
+	if name == "" {
+		return nil, nil
+	}
+
+	tree, err := commit.SubTree(strings.TrimSuffix(dir, "/"))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
 		}
+		return nil, err
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
 
-		if markup.IsReadmeFile(entry.Name()) {
-			if readmeFiles[3] == nil || base.NaturalSortLess(readmeFiles[3].name, entry.Blob().Name()) {
-				name := entry.Name()
-				isSymlink := entry.IsLink()
-				if isSymlink {
-					entry, err = entry.FollowLinks()
-					if err != nil && !git.IsErrBadLink(err) {
-						return nil, err
-					}
-				}
-				if entry != nil && (entry.IsExecutable() || entry.IsRegular()) {
-					readmeFiles[3] = &namedBlob{
-						name,
-						isSymlink,
-						entry.Blob(),
-					}
+	ext := path.Ext(name)
+	for _, locale := range readmeLocales(lang) {
+		// README.md is localized as README.zh-CN.md
+		want := name
+		if locale != "" {
+			want = name[:len(name)-len(ext)] + locale + ext
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(entry.Name(), want) {
+				continue
+			}
+			isSymlink := entry.IsLink()
+			target := entry
+			if isSymlink {
+				target, err = entry.FollowLinks()
+				if err != nil && !git.IsErrBadLink(err) {
+					return nil, err
 				}
 			}
+			if target != nil && (target.IsExecutable() || target.IsRegular()) {
+				return &namedBlob{dir + entry.Name(), isSymlink, target.Blob()}, nil
+			}
 		}
 	}
-	var readmeFile *namedBlob
-	for _, f := range readmeFiles {
-		if f != nil {
-			readmeFile = f
+	return nil, nil
+}
+
+// findLocalizedEntry returns the file in entries which is the variant of the file name localized
+// for the language, e.g. guide.zh-CN.md for guide.md, together with the locale of the variant.
+// It returns nil if there is no localized variant.
+func findLocalizedEntry(entries git.Entries, name, lang string) (*git.TreeEntry, string) {
+	ext := path.Ext(name)
+	for _, locale := range readmeLocales(lang) {
+		if locale == "" {
 			break
 		}
+		want := name[:len(name)-len(ext)] + locale + ext
+		for _, entry := range entries {
+			if (entry.IsRegular() || entry.IsExecutable()) && strings.EqualFold(entry.Name(), want) {
+				return entry, locale[1:]
+			}
+		}
+	}
+	return nil, ""
+}
+
+// getLocalizedFileEntry returns the variant of the markup file at treePath localized for the language
+// and its path. It returns nil if the file is no markup file or has no localized variant.
+func getLocalizedFileEntry(commit *git.Commit, treePath, lang string) (*git.TreeEntry, string, string, error) {
+	dir, name := path.Split(treePath)
+	if markup.Type(name) == "" {
+		return nil, "", "", nil
 	}
-	return readmeFile, nil
+	tree, err := commit.SubTree(strings.TrimSuffix(dir, "/"))
+	if err != nil {
+		return nil, "", "", err
+	}
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, "", "", err
+	}
+	entry, locale := findLocalizedEntry(entries, name, lang)
+	if entry == nil {
+		return nil, "", "", nil
+	}
+	return entry, dir + entry.Name(), locale, nil
+}
+
+// renderReadmeOverlay renders the README of the .gitea directory, which is shown
+// above the README of the repository on the home view.
+func renderReadmeOverlay(ctx *context.Context, entry *git.TreeEntry, treeLink, lang string, readmeFile *namedBlob) error {
+	overlay, err := getReadmeFileFromPath(ctx.Repo.Commit, entry.Name(), lang)
+	if err != nil || overlay == nil {
+		return err
+	}
+	name := entry.Name() + "/" + overlay.name
+	if (readmeFile != nil && readmeFile.name == name) || overlay.blob.Size() >= setting.UI.MaxDisplayFileSize {
+		return nil
+	}
+
+	dataRc, err := overlay.blob.DataAsync()
+	if err != nil {
+		return err
+	}
+	defer dataRc.Close()
+	buf, err := ioutil.ReadAll(dataRc)
+	if err != nil {
+		return err
+	}
+	if !base.IsTextFile(buf) {
+		return nil
+	}
+	buf = charset.ToUTF8WithFallback(buf)
+
+	ctx.Data["ReadmeOverlayName"] = name
+	if markupType := markup.Type(overlay.name); markupType != "" {
+		ctx.Data["ReadmeOverlayMarkupType"] = string(markupType)
+		ctx.Data["ReadmeOverlay"] = string(markup.Render(overlay.name, buf, treeLink+"/"+util.PathEscapeSegments(entry.Name()), ctx.Repo.Repository.ComposeDocumentMetas()))
+	} else {
+		ctx.Data["ReadmeOverlay"] = strings.ReplaceAll(
+			gotemplate.HTMLEscapeString(string(buf)), "\n", `<br>`,
+		)
+	}
+	return nil
 }
 
 func renderDirectory(ctx *context.Context, treeLink string) {
@@ -150,82 +297,49 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 		return
 	}
 
-	// 3 for the extensions in exts[] in order
-	// the last one is for a readme that doesn't
-	// strictly match an extension
-	var readmeFiles [4]*namedBlob
 	var docsEntries [3]*git.TreeEntry
-	var exts = []string{".md", ".txt", ""} // sorted by priority
 	for _, entry := range entries {
-		if entry.IsDir() {
-			lowerName := strings.ToLower(entry.Name())
-			switch lowerName {
-			case "docs":
-				if entry.Name() == "docs" || docsEntries[0] == nil {
-					docsEntries[0] = entry
-				}
-			case ".gitea":
-				if entry.Name() == ".gitea" || docsEntries[1] == nil {
-					docsEntries[1] = entry
-				}
-			case ".github":
-				if entry.Name() == ".github" || docsEntries[2] == nil {
-					docsEntries[2] = entry
-				}
-			}
+		if !entry.IsDir() {
 			continue
 		}
-
-		for i, ext := range exts {
-			if markup.IsReadmeFile(entry.Name(), ext) {
-				log.Debug("%s", entry.Name())
-				name := entry.Name()
-				isSymlink := entry.IsLink()
-				target := entry
-				if isSymlink {
-					target, err = entry.FollowLinks()
-					if err != nil && !git.IsErrBadLink(err) {
-						ctx.ServerError("FollowLinks", err)
-						return
-					}
-				}
-				log.Debug("%t", target == nil)
-				if target != nil && (target.IsExecutable() || target.IsRegular()) {
-					readmeFiles[i] = &namedBlob{
-						name,
-						isSymlink,
-						target.Blob(),
-					}
-				}
+		lowerName := strings.ToLower(entry.Name())
+		switch lowerName {
+		case "docs":
+			if entry.Name() == "docs" || docsEntries[0] == nil {
+				docsEntries[0] = entry
 			}
-		}
-
-		if markup.IsReadmeFile(entry.Name()) {
-			name := entry.Name()
-			isSymlink := entry.IsLink()
-			if isSymlink {
-				entry, err = entry.FollowLinks()
-				if err != nil && !git.IsErrBadLink(err) {
-					ctx.ServerError("FollowLinks", err)
-					return
-				}
+		case ".gitea":
+			if entry.Name() == ".gitea" || docsEntries[1] == nil {
+				docsEntries[1] = entry
 			}
-			if entry != nil && (entry.IsExecutable() || entry.IsRegular()) {
-				readmeFiles[3] = &namedBlob{
-					name,
-					isSymlink,
-					entry.Blob(),
-				}
+		case ".github":
+			if entry.Name() == ".github" || docsEntries[2] == nil {
+				docsEntries[2] = entry
 			}
 		}
 	}
 
+	lang := ctx.Locale.Language()
 	var readmeFile *namedBlob
 	readmeTreelink := treeLink
-	for _, f := range readmeFiles {
-		if f != nil {
-			readmeFile = f
-			break
+	if ctx.Repo.TreePath == "" && ctx.Repo.Repository.ReadmePath != "" {
+		readmeFile, err = getConfiguredReadmeFile(ctx.Repo.Commit, ctx.Repo.Repository.ReadmePath, lang)
+		if err != nil {
+			ctx.ServerError("getConfiguredReadmeFile", err)
+			return
+		}
+		if readmeFile != nil {
+			if dir := path.Dir(readmeFile.name); dir != "." {
+				readmeTreelink = treeLink + "/" + util.PathEscapeSegments(dir)
+			}
+		}
+	}
+	if readmeFile == nil {
+		// fall back to the standard search order
+		readmeFile, err = findReadmeFile(entries, lang)
+		if err != nil {
+			ctx.ServerError("findReadmeFile", err)
+			return
 		}
 	}
 
@@ -234,7 +348,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 			if entry == nil {
 				continue
 			}
-			readmeFile, err = getReadmeFileFromPath(ctx.Repo.Commit, entry.GetSubJumpablePathName())
+			readmeFile, err = getReadmeFileFromPath(ctx.Repo.Commit, entry.GetSubJumpablePathName(), lang)
 			if err != nil {
 				ctx.ServerError("getReadmeFileFromPath", err)
 				return
@@ -247,6 +361,13 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 		}
 	}
 
+	if ctx.Repo.TreePath == "" && ctx.Repo.Repository.ShowReadmeOverlay && docsEntries[1] != nil {
+		if err := renderReadmeOverlay(ctx, docsEntries[1], treeLink, lang, readmeFile); err != nil {
+			ctx.ServerError("renderReadmeOverlay", err)
+			return
+		}
+	}
+
 	if readmeFile != nil {
 		ctx.Data["RawFileLink"] = ""
 		ctx.Data["ReadmeInList"] = true
@@ -566,6 +687,20 @@ func safeURL(address string) string {
 
 // Home render repository home page
 func Home(ctx *context.Context) {
+	if setting.Federation.Enabled {
+		ctx.Resp.Header().Add("Vary", "Accept")
+		if activitypub.IsActivityPubRequest(ctx.Req) {
+			if ctx.Repo.Repository.IsPrivate || !ctx.Repo.Owner.Visibility.IsPublic() {
+				ctx.NotFound("ActivityPub", nil)
+				return
+			}
+			if err := activitypub.Write(ctx.Resp, activitypub.RepoActor(ctx.Repo.Repository)); err != nil {
+				log.Error("Unable to write actor of %s: %v", ctx.Repo.Repository.FullName(), err)
+			}
+			return
+		}
+	}
+
 	if len(ctx.Repo.Units) > 0 {
 		if ctx.Repo.Repository.IsBeingCreated() {
 			task, err := models.GetMigratingTask(ctx.Repo.Repository.ID)
@@ -592,6 +727,11 @@ func Home(ctx *context.Context) {
 				ctx.ServerError("ReadBy", err)
 				return
 			}
+
+			if setting.PostLoginRedirectURL == setting.LandingPageLastRepo &&
+				ctx.GetCookie(middleware.LastVisitedRepoCookieName) != ctx.Repo.RepoLink {
+				middleware.SetLastVisitedRepoCookie(ctx.Resp, ctx.Repo.RepoLink)
+			}
 		}
 
 		var firstUnit *models.Unit
@@ -677,6 +817,22 @@ func renderCode(ctx *context.Context) {
 		return
 	}
 
+	// show the variant of a document localized for the user instead of the document itself
+	if !entry.IsDir() && ctx.Query("display") != "source" && ctx.Query("localized") != "false" {
+		localized, localizedPath, locale, err := getLocalizedFileEntry(ctx.Repo.Commit, ctx.Repo.TreePath, ctx.Locale.Language())
+		if err != nil {
+			ctx.ServerError("getLocalizedFileEntry", err)
+			return
+		}
+		if localized != nil {
+			ctx.Data["LocalizedLang"] = locale
+			ctx.Data["OriginalLink"] = treeLink + "?localized=false"
+			entry = localized
+			ctx.Repo.TreePath = localizedPath
+			treeLink = branchLink + "/" + localizedPath
+		}
+	}
+
 	if entry.IsDir() {
 		renderDirectory(ctx, treeLink)
 	} else {
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadmeLocales(t *testing.T) {
+	assert.Equal(t, []string{".zh-cn", ".zh", ""}, readmeLocales("zh-CN"))
+	assert.Equal(t, []string{".de", ""}, readmeLocales("de"))
+	assert.Equal(t, []string{""}, readmeLocales(""))
+}
+
+func TestGetReadmeFile(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadRepoCommit(t, ctx)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	readme, err := getReadmeFileFromPath(ctx.Repo.Commit, "", "zh-CN")
+	assert.NoError(t, err)
+	if assert.NotNil(t, readme) {
+		assert.Equal(t, "README.md", readme.name)
+	}
+
+	readme, err = getConfiguredReadmeFile(ctx.Repo.Commit, "/readme.md", "zh-CN")
+	assert.NoError(t, err)
+	if assert.NotNil(t, readme) {
+		assert.Equal(t, "README.md", readme.name)
+	}
+
+	readme, err = getConfiguredReadmeFile(ctx.Repo.Commit, "docs/README.md", "en-US")
+	assert.NoError(t, err)
+	assert.Nil(t, readme)
+}
+
+func TestGetLocalizedFileEntry(t *testing.T) {
+	models.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadRepoCommit(t, ctx)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	// there is no localized variant, the file itself is shown
+	entry, treePath, locale, err := getLocalizedFileEntry(ctx.Repo.Commit, "README.md", "zh-CN")
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+	assert.Empty(t, treePath)
+	assert.Empty(t, locale)
+
+	entries, err := ctx.Repo.Commit.ListEntries()
+	assert.NoError(t, err)
+	entry, locale = findLocalizedEntry(entries, "README.md", "")
+	assert.Nil(t, entry)
+	assert.Empty(t, locale)
+
+	// variants are matched case-insensitively, README.md is the "md" variant of "readme"
+	entry, locale = findLocalizedEntry(entries, "readme", "MD")
+	if assert.NotNil(t, entry) {
+		assert.Equal(t, "README.md", entry.Name())
+	}
+	assert.Equal(t, "md", locale)
+}
@@ -167,6 +167,10 @@ func ParseHookEvent(form auth.WebhookForm) *models.HookEvent {
 			IssueMilestone:       form.IssueMilestone,
 			IssueComment:         form.IssueComment,
 			Release:              form.Release,
+			Status:               form.Status,
+			CommitComment:        form.CommitComment,
+			Team:                 form.Team,
+			Membership:           form.Membership,
 			Push:                 form.Push,
 			PullRequest:          form.PullRequest,
 			PullRequestAssign:    form.PullRequestAssign,
@@ -213,6 +217,7 @@ func GiteaHooksNewPost(ctx *context.Context) {
 		HTTPMethod:      form.HTTPMethod,
 		ContentType:     contentType,
 		Secret:          form.Secret,
+		SignatureType:   form.SignatureType,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
 		IsActive:        form.Active,
 		Type:            models.GITEA,
@@ -227,6 +232,7 @@ func GiteaHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -267,6 +273,7 @@ func newGogsWebhookPost(ctx *context.Context, form auth.NewGogshookForm, kind mo
 		URL:             form.PayloadURL,
 		ContentType:     contentType,
 		Secret:          form.Secret,
+		SignatureType:   form.SignatureType,
 		HookEvent:       ParseHookEvent(form.WebhookForm),
 		IsActive:        form.Active,
 		Type:            kind,
@@ -281,6 +288,7 @@ func newGogsWebhookPost(ctx *context.Context, form auth.NewGogshookForm, kind mo
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -334,6 +342,7 @@ func DiscordHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -377,6 +386,7 @@ func DingtalkHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -430,6 +440,7 @@ func TelegramHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -486,6 +497,7 @@ func MatrixHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -529,6 +541,7 @@ func MSTeamsHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -590,6 +603,7 @@ func SlackHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -633,10 +647,214 @@ func FeishuHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
 
+// MattermostHooksNewPost response for creating mattermost hook
+func MattermostHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewMattermostHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.MATTERMOST
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(&webhook.MattermostMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+		Color:    form.Color,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.MATTERMOST,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// GoogleChatHooksNewPost response for creating Google Chat hook
+func GoogleChatHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewGoogleChatHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.GOOGLECHAT
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	if err := webhook.ValidateGoogleChatURL(form.PayloadURL); err != nil {
+		ctx.Data["Err_PayloadURL"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.googlechat_url_invalid", err.Error()), orCtx.NewTemplate, &form)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.GOOGLECHAT,
+		Meta:            "",
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// JSONHooksNewPost response for creating generic JSON hook
+func JSONHooksNewPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewJSONHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = models.JSON
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.LinkNew
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := jsonHookMeta(ctx, form, orCtx.NewTemplate)
+	if !ok {
+		return
+	}
+
+	contentType := models.ContentTypeJSON
+	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
+		contentType = models.ContentTypeForm
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		HTTPMethod:      "POST",
+		ContentType:     contentType,
+		Secret:          form.Secret,
+		SignatureType:   form.SignatureType,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		Type:            models.JSON,
+		Meta:            meta,
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookCreate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// jsonHookMeta parses the custom headers of a generic JSON hook form into the
+// webhook metadata, re-rendering the form if they are invalid.
+func jsonHookMeta(ctx *context.Context, form *auth.NewJSONHookForm, tpl base.TplName) (string, bool) {
+	headers, err := webhook.ParseJSONHeaders(form.Headers)
+	if err != nil {
+		ctx.Data["Err_Headers"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.json_headers_invalid", err.Error()), tpl, form)
+		return "", false
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(&webhook.JSONMeta{
+		Headers: headers,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return "", false
+	}
+	return string(meta), true
+}
+
+// auditWebhook records a change of a repository, organization, default or system webhook in the audit log
+func auditWebhook(ctx *context.Context, action models.AuditAction, id int64) {
+	owner := "system"
+	if len(ctx.Repo.RepoLink) > 0 {
+		owner = ctx.Repo.Repository.FullName()
+	} else if len(ctx.Org.OrgLink) > 0 {
+		owner = ctx.Org.Organization.Name
+	} else if ctx.Params(":configType") == "default-hooks" {
+		owner = "default"
+	}
+	ctx.Audit(&models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetWebhook,
+		TargetID:   id,
+		TargetName: owner,
+	})
+}
+
 func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	ctx.Data["RequireHighlightJS"] = true
 
@@ -674,6 +892,10 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["TelegramHook"] = webhook.GetTelegramHook(w)
 	case models.MATRIX:
 		ctx.Data["MatrixHook"] = webhook.GetMatrixHook(w)
+	case models.MATTERMOST:
+		ctx.Data["MattermostHook"] = webhook.GetMattermostHook(w)
+	case models.JSON:
+		ctx.Data["JSONHook"] = webhook.GetJSONHook(w)
 	}
 
 	ctx.Data["History"], err = w.History(1)
@@ -724,6 +946,7 @@ func WebHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
 	w.Secret = form.Secret
+	w.SignatureType = form.SignatureType
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	w.HTTPMethod = form.HTTPMethod
@@ -735,6 +958,7 @@ func WebHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -765,6 +989,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
 	w.Secret = form.Secret
+	w.SignatureType = form.SignatureType
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	if err := w.UpdateEvent(); err != nil {
@@ -775,6 +1000,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -827,6 +1053,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -871,6 +1098,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -904,6 +1132,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -946,6 +1175,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -991,6 +1221,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1024,6 +1255,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1057,6 +1289,142 @@ func FeishuHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// MattermostHooksEditPost response for editing mattermost hook
+func MattermostHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewMattermostHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	meta, err := json.Marshal(&webhook.MattermostMeta{
+		Channel:  strings.TrimSpace(form.Channel),
+		Username: form.Username,
+		IconURL:  form.IconURL,
+		Color:    form.Color,
+	})
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// GoogleChatHooksEditPost response for editing Google Chat hook
+func GoogleChatHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewGoogleChatHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	if err := webhook.ValidateGoogleChatURL(form.PayloadURL); err != nil {
+		ctx.Data["Err_PayloadURL"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.googlechat_url_invalid", err.Error()), orCtx.NewTemplate, &form)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// JSONHooksEditPost response for editing generic JSON hook
+func JSONHooksEditPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.NewJSONHookForm)
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	meta, ok := jsonHookMeta(ctx, form, orCtx.NewTemplate)
+	if !ok {
+		return
+	}
+
+	contentType := models.ContentTypeJSON
+	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
+		contentType = models.ContentTypeForm
+	}
+
+	w.URL = form.PayloadURL
+	w.ContentType = contentType
+	w.Secret = form.Secret
+	w.SignatureType = form.SignatureType
+	w.Meta = meta
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1071,8 +1439,35 @@ func TestWebhook(ctx *context.Context) {
 		return
 	}
 
-	// Grab latest commit or fake one if it's empty repository.
+	// The branch filter being edited may be tested before it is saved.
+	branchFilter := w.BranchFilter
+	if _, ok := ctx.Req.Form["branch_filter"]; ok {
+		branchFilter = ctx.Query("branch_filter")
+	}
+	filter, err := util.ParseBranchFilter(branchFilter)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("form.branch_filter_error", err.Error()))
+		ctx.Status(200)
+		return
+	}
+
+	branch := ctx.Repo.Repository.DefaultBranch
+	if b := strings.TrimSpace(ctx.Query("branch")); len(b) > 0 {
+		branch = b
+	}
+	if !filter.Match(branch) {
+		ctx.Flash.Warning(ctx.Tr("repo.settings.webhook.test_branch_no_match", branch, filter.String()))
+		ctx.Status(200)
+		return
+	}
+
+	// Grab latest commit of the branch or fake one if it's empty repository.
 	commit := ctx.Repo.Commit
+	if branch != ctx.Repo.Repository.DefaultBranch && ctx.Repo.GitRepo != nil {
+		if c, err := ctx.Repo.GitRepo.GetBranchCommit(branch); err == nil {
+			commit = c
+		}
+	}
 	if commit == nil {
 		ghost := models.NewGhostUser()
 		commit = &git.Commit{
@@ -1085,7 +1480,7 @@ func TestWebhook(ctx *context.Context) {
 
 	apiUser := convert.ToUser(ctx.User, true, true)
 	p := &api.PushPayload{
-		Ref:    git.BranchPrefix + ctx.Repo.Repository.DefaultBranch,
+		Ref:    git.BranchPrefix + branch,
 		Before: commit.ID.String(),
 		After:  commit.ID.String(),
 		Commits: []*api.PayloadCommit{
@@ -1107,20 +1502,46 @@ func TestWebhook(ctx *context.Context) {
 		Pusher: apiUser,
 		Sender: apiUser,
 	}
+	// The filter has been checked above and may differ from the saved one.
+	w.BranchFilter = ""
 	if err := webhook.PrepareWebhook(w, ctx.Repo.Repository, models.HookEventPush, p); err != nil {
 		ctx.Flash.Error("PrepareWebhook: " + err.Error())
 		ctx.Status(500)
 	} else {
-		ctx.Flash.Info(ctx.Tr("repo.settings.webhook.test_delivery_success"))
+		ctx.Flash.Info(ctx.Tr("repo.settings.webhook.test_branch_match", branch, filter.String()) + " " + ctx.Tr("repo.settings.webhook.test_delivery_success"))
 		ctx.Status(200)
 	}
 }
 
+// ToggleWebhook pauses or resumes the deliveries of a webhook
+func ToggleWebhook(ctx *context.Context) {
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.SetWebhookActive(w, !w.IsActive); err != nil {
+		ctx.ServerError("SetWebhookActive", err)
+		return
+	}
+	auditWebhook(ctx, models.AuditWebhookUpdate, w.ID)
+	if w.IsActive {
+		ctx.Flash.Success(ctx.Tr("repo.settings.webhook.resume_success"))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.webhook.pause_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": orCtx.Link,
+	})
+}
+
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
 	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteWebhookByRepoID: " + err.Error())
 	} else {
+		auditWebhook(ctx, models.AuditWebhookDelete, ctx.QueryInt64("id"))
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -0,0 +1,126 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/webhook"
+)
+
+const (
+	tplHookDeliveries base.TplName = "repo/settings/webhook/deliveries"
+	tplHookDelivery   base.TplName = "repo/settings/webhook/delivery"
+)
+
+// getRepoWebhook returns the webhook of the current repository given by the id parameter
+func getRepoWebhook(ctx *context.Context) *models.Webhook {
+	w, err := models.GetWebhookByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.NotFound("GetWebhookByRepoID", nil)
+		} else {
+			ctx.ServerError("GetWebhookByRepoID", err)
+		}
+		return nil
+	}
+	ctx.Data["Webhook"] = w
+	ctx.Data["HookLink"] = fmt.Sprintf("%s/settings/hooks/%d", ctx.Repo.RepoLink, w.ID)
+	return w
+}
+
+// getWebhookDelivery returns the delivery of a webhook given by the uuid parameter
+func getWebhookDelivery(ctx *context.Context, w *models.Webhook) *models.HookTask {
+	t, err := models.GetHookTaskByUUID(w.ID, ctx.Params(":uuid"))
+	if err != nil {
+		if models.IsErrHookTaskNotExist(err) {
+			ctx.NotFound("GetHookTaskByUUID", nil)
+		} else {
+			ctx.ServerError("GetHookTaskByUUID", err)
+		}
+		return nil
+	}
+	return t
+}
+
+// WebHookDeliveries render the paginated deliveries of a webhook
+func WebHookDeliveries(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.webhook.deliveries")
+	ctx.Data["PageIsSettingsHooks"] = true
+
+	w := getRepoWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	count, err := models.CountHookTasks(w.ID)
+	if err != nil {
+		ctx.ServerError("CountHookTasks", err)
+		return
+	}
+	deliveries, err := models.HookTasks(w.ID, page)
+	if err != nil {
+		ctx.ServerError("HookTasks", err)
+		return
+	}
+	ctx.Data["Deliveries"] = deliveries
+	ctx.Data["Total"] = count
+
+	pager := context.NewPagination(int(count), setting.Webhook.PagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplHookDeliveries)
+}
+
+// WebHookDelivery render the request and response of a delivery of a webhook
+func WebHookDelivery(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.webhook.deliveries")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["RequireHighlightJS"] = true
+
+	w := getRepoWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	t := getWebhookDelivery(ctx, w)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Delivery"] = t
+
+	ctx.HTML(http.StatusOK, tplHookDelivery)
+}
+
+// WebHookRedeliver sends the payload of a delivery of a webhook once more
+func WebHookRedeliver(ctx *context.Context) {
+	w := getRepoWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	t := getWebhookDelivery(ctx, w)
+	if ctx.Written() {
+		return
+	}
+
+	redelivery, err := webhook.Redeliver(t)
+	if err != nil {
+		ctx.ServerError("Redeliver", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.webhook.redelivery_success", t.UUID))
+	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d/deliveries/%s", ctx.Repo.RepoLink, w.ID, redelivery.UUID))
+}
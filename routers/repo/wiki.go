@@ -6,6 +6,7 @@
 package repo
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/url"
@@ -20,6 +21,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
@@ -32,6 +35,7 @@ const (
 	tplWikiRevision base.TplName = "repo/wiki/revision"
 	tplWikiNew      base.TplName = "repo/wiki/new"
 	tplWikiPages    base.TplName = "repo/wiki/pages"
+	tplWikiSearch   base.TplName = "repo/wiki/search"
 )
 
 // MustEnableWiki check if wiki is enabled, if external then redirect
@@ -194,6 +198,27 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 		return nil, nil
 	}
 
+	// show the variant of the page localized for the user instead of the page itself
+	if ctx.Query("localized") != "false" {
+		if localized, locale := findLocalizedEntry(entries, pageFilename, ctx.Locale.Language()); localized != nil {
+			localizedName, err := wiki_service.FilenameToName(localized.Name())
+			if err == nil {
+				data = wikiContentsByEntry(ctx, localized)
+				if ctx.Written() {
+					if wikiRepo != nil {
+						wikiRepo.Close()
+					}
+					return nil, nil
+				}
+				ctx.Data["LocalizedLang"] = locale
+				ctx.Data["OriginalLink"] = ctx.Repo.RepoLink + "/wiki/" + wiki_service.NameToSubURL(pageName) + "?localized=false"
+				ctx.Data["PageURL"] = wiki_service.NameToSubURL(localizedName)
+				entry = localized
+				pageFilename = localized.Name()
+			}
+		}
+	}
+
 	sidebarContent, _, _, _ := wikiContentsByName(ctx, commit, "_Sidebar")
 	if ctx.Written() {
 		if wikiRepo != nil {
@@ -211,7 +236,7 @@ func renderViewPage(ctx *context.Context) (*git.Repository, *git.TreeEntry) {
 	}
 
 	metas := ctx.Repo.Repository.ComposeDocumentMetas()
-	ctx.Data["content"] = markdown.RenderWiki(data, ctx.Repo.RepoLink, metas)
+	ctx.Data["content"] = markdown.RenderWiki(data, ctx.Repo.RepoLink, wiki_service.PageMetas(ctx.Repo.Repository))
 	ctx.Data["sidebarPresent"] = sidebarContent != nil
 	ctx.Data["sidebarContent"] = markdown.RenderWiki(sidebarContent, ctx.Repo.RepoLink, metas)
 	ctx.Data["footerPresent"] = footerContent != nil
@@ -383,6 +408,7 @@ func Wiki(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Author"] = lastCommit.Author
+	ctx.Data["CanExportPDF"] = wiki_service.IsExportFormatSupported(wiki_service.ExportFormatPDF)
 
 	ctx.HTML(200, tplWikiView)
 }
@@ -438,6 +464,7 @@ func WikiPages(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.wiki.pages")
 	ctx.Data["PageIsWiki"] = true
 	ctx.Data["CanWriteWiki"] = ctx.Repo.CanWrite(models.UnitTypeWiki) && !ctx.Repo.Repository.IsArchived
+	ctx.Data["CanExportPDF"] = wiki_service.IsExportFormatSupported(wiki_service.ExportFormatPDF)
 
 	wikiRepo, commit, err := findWikiRepoCommit(ctx)
 	if err != nil {
@@ -498,6 +525,122 @@ func WikiPages(ctx *context.Context) {
 	ctx.HTML(200, tplWikiPages)
 }
 
+// WikiSearch render the pages of the wiki matching a search by their title or content
+func WikiSearch(ctx *context.Context) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+
+	keyword := strings.TrimSpace(ctx.Query("q"))
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	total, results, err := wiki_service.SearchWiki(ctx.Repo.Repository, keyword, page, setting.UI.RepoSearchPagingNum)
+	if err != nil {
+		ctx.ServerError("SearchWiki", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("repo.wiki.search")
+	ctx.Data["PageIsWiki"] = true
+	ctx.Data["CanWriteWiki"] = ctx.Repo.CanWrite(models.UnitTypeWiki) && !ctx.Repo.Repository.IsArchived
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["SearchResults"] = results
+	ctx.Data["RequireHighlightJS"] = true
+
+	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(200, tplWikiSearch)
+}
+
+// wikiExportFormat returns the format requested for an export, PDF unless chosen otherwise when it is
+// supported. Writes to ctx if the format is not supported.
+func wikiExportFormat(ctx *context.Context) string {
+	format := ctx.Query("format")
+	if len(format) == 0 {
+		format = wiki_service.ExportFormatHTML
+		if wiki_service.IsExportFormatSupported(wiki_service.ExportFormatPDF) {
+			format = wiki_service.ExportFormatPDF
+		}
+	}
+	if !wiki_service.IsExportFormatSupported(format) {
+		ctx.NotFound("IsExportFormatSupported", nil)
+		return ""
+	}
+	return format
+}
+
+// WikiPageExport exports a wiki page to a PDF or HTML document
+func WikiPageExport(ctx *context.Context) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+	format := wikiExportFormat(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	wikiName := wiki_service.NormalizeWikiName(ctx.Params(":page"))
+	document, err := wiki_service.ExportPage(ctx.Repo.Repository, wikiName, format)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("ExportPage", nil)
+		} else {
+			ctx.ServerError("ExportPage", err)
+		}
+		return
+	}
+	downloadName := strings.TrimSuffix(wiki_service.NameToFilename(wikiName), ".md") + "." + format
+	ctx.ServeContent(downloadName, bytes.NewReader(document))
+}
+
+// WikiExport exports all the pages of the wiki, in the order of the sidebar, to a PDF or HTML document
+// or to a zip archive of a document per page. The export is generated in the background.
+func WikiExport(ctx *context.Context) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki")
+		return
+	}
+	format := wikiExportFormat(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	exportReq, err := wiki_service.NewExportRequest(ctx.Repo.Repository, format, ctx.QueryBool("zip"))
+	if err != nil {
+		ctx.ServerError("NewExportRequest", err)
+		return
+	}
+	complete, err := wiki_service.ExportWiki(exportReq)
+	if err != nil {
+		ctx.ServerError("ExportWiki", err)
+		return
+	} else if !complete {
+		ctx.Flash.Info(ctx.Tr("repo.wiki.export_in_progress"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/wiki/_pages")
+		return
+	}
+
+	fr, err := storage.RepoArchives.Open(exportReq.GetExportPath())
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+	fi, err := fr.Stat()
+	if err != nil {
+		ctx.ServerError("Stat", err)
+		return
+	}
+	ctx.ServeContent(exportReq.GetExportName(ctx.Repo.Repository), fr, fi.ModTime())
+}
+
 // WikiRaw outputs raw blob requested by user (image for example)
 func WikiRaw(ctx *context.Context) {
 	wikiRepo, commit, err := findWikiRepoCommit(ctx)
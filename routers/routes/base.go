@@ -18,12 +18,14 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/metrics"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/web/middleware"
 
 	"gitea.com/go-chi/session"
+	"github.com/go-chi/chi"
 )
 
 // LoggerHandler is a handler that will log the routing to the default gitea log
@@ -46,6 +48,27 @@ func LoggerHandler(level log.Level) func(next http.Handler) http.Handler {
 	}
 }
 
+// RequestMetricsHandler is a handler that records the durations of the requests by route template
+func RequestMetricsHandler(m *metrics.RequestMetrics) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+
+			next.ServeHTTP(w, req)
+
+			status := http.StatusOK
+			if v, ok := w.(context.ResponseWriter); ok && v.Status() != 0 {
+				status = v.Status()
+			}
+			var route string
+			if rctx := chi.RouteContext(req.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+			m.Observe(req.Method, route, status, time.Since(start))
+		})
+	}
+}
+
 func storageHandler(storageSetting setting.Storage, prefix string, objStore storage.ObjectStorage) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		if storageSetting.ServeDirect {
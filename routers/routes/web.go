@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/context"
 	auth "code.gitea.io/gitea/modules/forms"
 	"code.gitea.io/gitea/modules/httpcache"
@@ -41,7 +42,7 @@ import (
 	"code.gitea.io/gitea/services/mailer"
 
 	// to registers all internal adapters
-	_ "code.gitea.io/gitea/modules/session"
+	session_module "code.gitea.io/gitea/modules/session"
 
 	"gitea.com/go-chi/captcha"
 	"gitea.com/go-chi/session"
@@ -84,6 +85,14 @@ func commonMiddlewares() []func(http.Handler) http.Handler {
 
 	handlers = append(handlers, middleware.StripSlashes)
 
+	if setting.Metrics.Enabled && setting.Metrics.EnableRequestMetrics {
+		m := metrics.NewRequestMetrics(setting.Metrics.RequestDurationBuckets)
+		if err := m.Register(prometheus.DefaultRegisterer); err != nil {
+			log.Fatal("Failed to register request metrics: %v", err)
+		}
+		handlers = append(handlers, RequestMetricsHandler(m))
+	}
+
 	if !setting.DisableRouterLog && setting.RouterLogLevel != log.NONE {
 		if log.GetLogger("router").GetLevel() <= setting.RouterLogLevel {
 			handlers = append(handlers, LoggerHandler(setting.RouterLogLevel))
@@ -140,6 +149,8 @@ func WebRoutes() *web.Route {
 		Secure:         setting.SessionConfig.Secure,
 		Domain:         setting.SessionConfig.Domain,
 	}))
+	r.Use(session_module.Expirer(setting.SessionConfig.IdleTimeout, setting.SessionConfig.Maxlifetime))
+	r.Use(session_module.Tracker(setting.SessionConfig.Maxlifetime))
 
 	r.Use(Recovery())
 
@@ -159,6 +170,7 @@ func WebRoutes() *web.Route {
 	r.Use(storageHandler(setting.RepoAvatar.Storage, "repo-avatars", storage.RepoAvatars))
 
 	gob.Register(&u2f.Challenge{})
+	gob.Register(&webauthn.SessionData{})
 
 	if setting.EnableGzip {
 		h, err := gziphandler.GzipHandlerWithOpts(gziphandler.MinSize(GzipMinSize))
@@ -216,6 +228,15 @@ func WebRoutes() *web.Route {
 		})
 	}
 
+	if setting.SecurityTxt.Enabled {
+		r.Get("/.well-known/security.txt", routers.SecurityTxt)
+	}
+
+	// Lets password managers send users straight to the password form.
+	r.Get("/.well-known/change-password", func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, setting.AppSubURL+"/user/settings/account", http.StatusFound)
+	})
+
 	r.Get("/apple-touch-icon.png", func(w http.ResponseWriter, req *http.Request) {
 		http.Redirect(w, req, path.Join(setting.StaticURLPrefix, "img/apple-touch-icon.png"), 301)
 	})
@@ -353,6 +374,7 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/users", routers.ExploreUsers)
 		m.Get("/organizations", routers.ExploreOrganizations)
 		m.Get("/code", routers.ExploreCode)
+		m.Get("/issues", routers.ExploreIssues)
 	}, ignExploreSignIn)
 	m.Get("/issues", reqSignIn, user.Issues)
 	m.Get("/pulls", reqSignIn, user.Pulls)
@@ -361,7 +383,7 @@ func RegisterRoutes(m *web.Route) {
 	// ***** START: User *****
 	m.Group("/user", func() {
 		m.Get("/login", user.SignIn)
-		m.Post("/login", bindIgnErr(auth.SignInForm{}), user.SignInPost)
+		m.Post("/login", user.LoginRateLimit, bindIgnErr(auth.SignInForm{}), user.SignInPost)
 		m.Group("", func() {
 			m.Combo("/login/openid").
 				Get(user.SignInOpenID).
@@ -383,95 +405,120 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{provider}", user.SignInOAuth)
 			m.Get("/{provider}/callback", user.SignInOAuthCallback)
 		})
+		m.Group("/saml", func() {
+			m.Get("/{provider}", user.SignInSAML)
+			m.Post("/{provider}/acs", user.SAMLAssertionConsumerService)
+		})
 		m.Get("/link_account", user.LinkAccount)
 		m.Post("/link_account_signin", bindIgnErr(auth.SignInForm{}), user.LinkAccountPostSignIn)
 		m.Post("/link_account_signup", bindIgnErr(auth.RegisterForm{}), user.LinkAccountPostRegister)
 		m.Group("/two_factor", func() {
 			m.Get("", user.TwoFactor)
-			m.Post("", bindIgnErr(auth.TwoFactorAuthForm{}), user.TwoFactorPost)
+			m.Post("", user.LoginRateLimit, bindIgnErr(auth.TwoFactorAuthForm{}), user.TwoFactorPost)
 			m.Get("/scratch", user.TwoFactorScratch)
-			m.Post("/scratch", bindIgnErr(auth.TwoFactorScratchAuthForm{}), user.TwoFactorScratchPost)
+			m.Post("/scratch", user.LoginRateLimit, bindIgnErr(auth.TwoFactorScratchAuthForm{}), user.TwoFactorScratchPost)
 		})
 		m.Group("/u2f", func() {
 			m.Get("", user.U2F)
 			m.Get("/challenge", user.U2FChallenge)
-			m.Post("/sign", bindIgnErr(u2f.SignResponse{}), user.U2FSign)
-
+			m.Post("/sign", user.LoginRateLimit, bindIgnErr(u2f.SignResponse{}), user.U2FSign)
+		})
+		m.Group("/webauthn", func() {
+			m.Get("", user.WebAuthn)
+			m.Get("/challenge", user.WebAuthnChallenge)
+			m.Post("/sign", user.LoginRateLimit, bindIgnErr(webauthn.CredentialAssertionResponse{}), user.WebAuthnSign)
 		})
 	}, reqSignOut)
 
 	m.Any("/user/events", reqSignIn, events.Events)
+	m.Get("/user/saml/{provider}/metadata", user.SAMLMetadata)
 
 	m.Group("/login/oauth", func() {
 		m.Get("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
-		m.Post("/grant", bindIgnErr(auth.GrantApplicationForm{}), user.GrantApplicationOAuth)
 		// TODO manage redirection
 		m.Post("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
 	}, ignSignInAndCsrf, reqSignIn)
+	// granting an application relies on the session, so it must be protected against CSRF
+	m.Post("/login/oauth/grant", reqSignIn, bindIgnErr(auth.GrantApplicationForm{}), user.GrantApplicationOAuth)
 	if setting.CORSConfig.Enabled {
-		m.Post("/login/oauth/access_token", cors.Handler(cors.Options{
+		oauthCORS := cors.Handler(cors.Options{
 			//Scheme:           setting.CORSConfig.Scheme, // FIXME: the cors middleware needs scheme option
 			AllowedOrigins: setting.CORSConfig.AllowDomain,
 			//setting.CORSConfig.AllowSubdomain // FIXME: the cors middleware needs allowSubdomain option
 			AllowedMethods:   setting.CORSConfig.Methods,
 			AllowCredentials: setting.CORSConfig.AllowCredentials,
 			MaxAge:           int(setting.CORSConfig.MaxAge.Seconds()),
-		}), bindIgnErr(auth.AccessTokenForm{}), ignSignInAndCsrf, user.AccessTokenOAuth)
+		})
+		m.Post("/login/oauth/access_token", oauthCORS, bindIgnErr(auth.AccessTokenForm{}), ignSignInAndCsrf, user.AccessTokenOAuth)
+		m.Post("/login/oauth/revoke", oauthCORS, bindIgnErr(auth.RevokeTokenForm{}), ignSignInAndCsrf, user.RevokeOAuth)
 	} else {
 		m.Post("/login/oauth/access_token", bindIgnErr(auth.AccessTokenForm{}), ignSignInAndCsrf, user.AccessTokenOAuth)
+		m.Post("/login/oauth/revoke", bindIgnErr(auth.RevokeTokenForm{}), ignSignInAndCsrf, user.RevokeOAuth)
 	}
 
 	m.Group("/user/settings", func() {
 		m.Get("", userSetting.Profile)
 		m.Post("", bindIgnErr(auth.UpdateProfileForm{}), userSetting.ProfilePost)
 		m.Get("/change_password", user.MustChangePassword)
-		m.Post("/change_password", bindIgnErr(auth.MustChangePasswordForm{}), user.MustChangePasswordPost)
+		m.Post("/change_password", context.NotImpersonated, bindIgnErr(auth.MustChangePasswordForm{}), user.MustChangePasswordPost)
 		m.Post("/avatar", bindIgnErr(auth.AvatarForm{}), userSetting.AvatarPost)
 		m.Post("/avatar/delete", userSetting.DeleteAvatar)
 		m.Group("/account", func() {
-			m.Combo("").Get(userSetting.Account).Post(bindIgnErr(auth.ChangePasswordForm{}), userSetting.AccountPost)
-			m.Post("/email", bindIgnErr(auth.AddEmailForm{}), userSetting.EmailPost)
-			m.Post("/email/delete", userSetting.DeleteEmail)
-			m.Post("/delete", userSetting.DeleteAccount)
+			m.Combo("").Get(userSetting.Account).Post(context.NotImpersonated, bindIgnErr(auth.ChangePasswordForm{}), userSetting.AccountPost)
+			m.Post("/email", context.NotImpersonated, bindIgnErr(auth.AddEmailForm{}), userSetting.EmailPost)
+			m.Post("/email/delete", context.NotImpersonated, userSetting.DeleteEmail)
+			m.Post("/delete", context.NotImpersonated, userSetting.DeleteAccount)
 			m.Post("/theme", bindIgnErr(auth.UpdateThemeForm{}), userSetting.UpdateUIThemePost)
 		})
 		m.Group("/security", func() {
 			m.Get("", userSetting.Security)
+			m.Get("/sessions", userSetting.Sessions)
+			m.Post("/sessions/delete", context.NotImpersonated, userSetting.DeleteSession)
+			m.Post("/sessions/delete_others", context.NotImpersonated, userSetting.DeleteOtherSessions)
 			m.Group("/two_factor", func() {
 				m.Post("/regenerate_scratch", userSetting.RegenerateScratchTwoFactor)
 				m.Post("/disable", userSetting.DisableTwoFactor)
 				m.Get("/enroll", userSetting.EnrollTwoFactor)
 				m.Post("/enroll", bindIgnErr(auth.TwoFactorAuthForm{}), userSetting.EnrollTwoFactorPost)
-			})
+			}, context.NotImpersonated)
 			m.Group("/u2f", func() {
 				m.Post("/request_register", bindIgnErr(auth.U2FRegistrationForm{}), userSetting.U2FRegister)
 				m.Post("/register", bindIgnErr(u2f.RegisterResponse{}), userSetting.U2FRegisterPost)
 				m.Post("/delete", bindIgnErr(auth.U2FDeleteForm{}), userSetting.U2FDelete)
-			})
+			}, context.NotImpersonated)
+			m.Group("/webauthn", func() {
+				m.Post("/request_register", bindIgnErr(auth.WebAuthnRegistrationForm{}), userSetting.WebAuthnRegister)
+				m.Post("/register", bindIgnErr(webauthn.CredentialCreationResponse{}), userSetting.WebAuthnRegisterPost)
+				m.Post("/delete", bindIgnErr(auth.WebAuthnDeleteForm{}), userSetting.WebAuthnDelete)
+			}, context.NotImpersonated)
 			m.Group("/openid", func() {
 				m.Post("", bindIgnErr(auth.AddOpenIDForm{}), userSetting.OpenIDPost)
 				m.Post("/delete", userSetting.DeleteOpenID)
 				m.Post("/toggle_visibility", userSetting.ToggleOpenIDVisibility)
-			}, openIDSignInEnabled)
-			m.Post("/account_link", userSetting.DeleteAccountLink)
+			}, openIDSignInEnabled, context.NotImpersonated)
+			m.Post("/account_link", context.NotImpersonated, userSetting.DeleteAccountLink)
+			m.Post("/remembered_device/delete", context.NotImpersonated, userSetting.DeleteRememberedDevice)
 		})
-		m.Group("/applications/oauth2", func() {
-			m.Get("/{id}", userSetting.OAuth2ApplicationShow)
-			m.Post("/{id}", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuthApplicationsEdit)
-			m.Post("/{id}/regenerate_secret", userSetting.OAuthApplicationsRegenerateSecret)
-			m.Post("", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuthApplicationsPost)
-			m.Post("/delete", userSetting.DeleteOAuth2Application)
-			m.Post("/revoke", userSetting.RevokeOAuth2Grant)
-		})
-		m.Combo("/applications").Get(userSetting.Applications).
-			Post(bindIgnErr(auth.NewAccessTokenForm{}), userSetting.ApplicationsPost)
-		m.Post("/applications/delete", userSetting.DeleteApplication)
+		m.Group("/applications", func() {
+			m.Group("/oauth2", func() {
+				m.Get("/{id}", userSetting.OAuth2ApplicationShow)
+				m.Post("/{id}", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuthApplicationsEdit)
+				m.Post("/{id}/regenerate_secret", userSetting.OAuthApplicationsRegenerateSecret)
+				m.Post("", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuthApplicationsPost)
+				m.Post("/delete", userSetting.DeleteOAuth2Application)
+				m.Post("/revoke", userSetting.RevokeOAuth2Grant)
+			})
+			m.Combo("").Get(userSetting.Applications).
+				Post(bindIgnErr(auth.NewAccessTokenForm{}), userSetting.ApplicationsPost)
+			m.Post("/delete", userSetting.DeleteApplication)
+		}, context.NotImpersonated)
 		m.Combo("/keys").Get(userSetting.Keys).
-			Post(bindIgnErr(auth.AddKeyForm{}), userSetting.KeysPost)
-		m.Post("/keys/delete", userSetting.DeleteKey)
+			Post(context.NotImpersonated, bindIgnErr(auth.AddKeyForm{}), userSetting.KeysPost)
+		m.Post("/keys/delete", context.NotImpersonated, userSetting.DeleteKey)
 		m.Get("/organization", userSetting.Organization)
 		m.Get("/repos", userSetting.Repos)
 		m.Post("/repos/unadopted", userSetting.AdoptOrDeleteRepository)
+		m.Post("/repos/restore", userSetting.RestoreRepository)
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
 		ctx.Data["AllThemes"] = setting.UI.Themes
@@ -484,10 +531,11 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/avatar/{username}/{size}", user.Avatar)
 		m.Get("/email2user", user.Email2User)
 		m.Get("/recover_account", user.ResetPasswd)
-		m.Post("/recover_account", user.ResetPasswdPost)
+		m.Post("/recover_account", user.LoginRateLimit, user.ResetPasswdPost)
 		m.Get("/forgot_password", user.ForgotPasswd)
 		m.Post("/forgot_password", user.ForgotPasswdPost)
 		m.Post("/logout", user.SignOut)
+		m.Post("/impersonation/stop", reqSignIn, user.StopImpersonation)
 		m.Get("/task/{task}", user.TaskStatus)
 	})
 	// ***** END: User *****
@@ -500,6 +548,7 @@ func RegisterRoutes(m *web.Route) {
 	m.Group("/admin", func() {
 		m.Get("", adminReq, admin.Dashboard)
 		m.Post("", adminReq, bindIgnErr(auth.AdminDashboardForm{}), admin.DashboardPost)
+		m.Post("/indexers/{indexer}/reindex", admin.ReindexPost)
 		m.Get("/config", admin.Config)
 		m.Post("/config/test_mail", admin.SendTestMail)
 		m.Group("/monitor", func() {
@@ -517,8 +566,11 @@ func RegisterRoutes(m *web.Route) {
 		m.Group("/users", func() {
 			m.Get("", admin.Users)
 			m.Combo("/new").Get(admin.NewUser).Post(bindIgnErr(auth.AdminCreateUserForm{}), admin.NewUserPost)
+			m.Combo("/import").Get(admin.ImportUsers).Post(bindIgnErr(auth.AdminImportUsersForm{}), admin.ImportUsersPost)
 			m.Combo("/{userid}").Get(admin.EditUser).Post(bindIgnErr(auth.AdminEditUserForm{}), admin.EditUserPost)
 			m.Post("/{userid}/delete", admin.DeleteUser)
+			m.Post("/{userid}/unlock", admin.UnlockUser)
+			m.Post("/{userid}/impersonate", admin.ImpersonateUser)
 		})
 
 		m.Group("/emails", func() {
@@ -534,12 +586,16 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("", admin.Repos)
 			m.Combo("/unadopted").Get(admin.UnadoptedRepos).Post(admin.AdoptOrDeleteRepository)
 			m.Post("/delete", admin.DeleteRepo)
+			m.Get("/deleted", admin.DeletedRepos)
+			m.Post("/deleted/restore", admin.RestoreRepo)
+			m.Post("/deleted/purge", admin.PurgeRepo)
 		})
 
 		m.Group("/hooks", func() {
 			m.Get("", admin.DefaultOrSystemWebhooks)
 			m.Post("/delete", admin.DeleteDefaultOrSystemWebhook)
 			m.Get("/{id}", repo.WebHooksEdit)
+			m.Post("/{id}/toggle", repo.ToggleWebhook)
 			m.Post("/gitea/{id}", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 			m.Post("/gogs/{id}", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
 			m.Post("/slack/{id}", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
@@ -549,6 +605,9 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/matrix/{id}", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 			m.Post("/msteams/{id}", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 			m.Post("/feishu/{id}", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+			m.Post("/mattermost/{id}", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+			m.Post("/googlechat/{id}", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksEditPost)
+			m.Post("/json/{id}", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksEditPost)
 		}, webhooksEnabled)
 
 		m.Group("/{configType:default-hooks|system-hooks}", func() {
@@ -562,6 +621,9 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 			m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 			m.Post("/feishu/new", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+			m.Post("/mattermost/new", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+			m.Post("/googlechat/new", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksNewPost)
+			m.Post("/json/new", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksNewPost)
 		})
 
 		m.Group("/auths", func() {
@@ -572,6 +634,11 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/{authid}/delete", admin.DeleteAuthSource)
 		})
 
+		m.Group("/audit", func() {
+			m.Get("", admin.AuditLog)
+			m.Get("/export", admin.ExportAuditLog)
+		})
+
 		m.Group("/notices", func() {
 			m.Get("", admin.Notices)
 			m.Post("/delete", admin.DeleteNotices)
@@ -635,6 +702,31 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/teams/{team}/action/repo/{action}", org.TeamsRepoAction)
 		}, context.OrgAssignment(true, false, true))
 
+		m.Group("/{org}/projects", func() {
+			m.Get("", org.Projects)
+			m.Get("/{id}", org.ViewProject)
+			// the permission to move a card is checked in the repository of its issue
+			m.Post("/{id}/{boardID}/{index}", org.MoveIssueAcrossBoards)
+			m.Group("", func() {
+				m.Get("/new", org.NewProject)
+				m.Post("/new", bindIgnErr(auth.CreateProjectForm{}), org.NewProjectPost)
+				m.Group("/{id}", func() {
+					m.Post("", bindIgnErr(auth.EditProjectBoardForm{}), org.AddBoardToProjectPost)
+					m.Post("/delete", org.DeleteProject)
+
+					m.Get("/edit", org.EditProject)
+					m.Post("/edit", bindIgnErr(auth.CreateProjectForm{}), org.EditProjectPost)
+					m.Post("/{action:open|close}", org.ChangeProjectStatus)
+
+					m.Group("/{boardID}", func() {
+						m.Put("", bindIgnErr(auth.EditProjectBoardForm{}), org.EditProjectBoard)
+						m.Delete("", org.DeleteProjectBoard)
+						m.Post("/default", org.SetDefaultProjectBoard)
+					})
+				})
+			}, org.MustManageProjects)
+		}, context.OrgAssignment(true), repo.MustEnableProjects)
+
 		m.Group("/{org}", func() {
 			m.Get("/teams/new", org.NewTeam)
 			m.Post("/teams/new", bindIgnErr(auth.CreateTeamForm{}), org.NewTeamPost)
@@ -647,6 +739,8 @@ func RegisterRoutes(m *web.Route) {
 					Post(bindIgnErr(auth.UpdateOrgSettingForm{}), org.SettingsPost)
 				m.Post("/avatar", bindIgnErr(auth.AvatarForm{}), org.SettingsAvatar)
 				m.Post("/avatar/delete", org.SettingsDeleteAvatar)
+				m.Combo("/push_policy").Get(org.PushPolicy).
+					Post(bindIgnErr(auth.OrgPushPolicyForm{}), org.PushPolicyPost)
 
 				m.Group("/hooks", func() {
 					m.Get("", org.Webhooks)
@@ -661,7 +755,11 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 					m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 					m.Post("/feishu/new", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+					m.Post("/mattermost/new", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+					m.Post("/googlechat/new", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksNewPost)
+					m.Post("/json/new", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksNewPost)
 					m.Get("/{id}", repo.WebHooksEdit)
+					m.Post("/{id}/toggle", repo.ToggleWebhook)
 					m.Post("/gitea/{id}", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/{id}", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
 					m.Post("/slack/{id}", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
@@ -671,6 +769,9 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/matrix/{id}", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 					m.Post("/msteams/{id}", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 					m.Post("/feishu/{id}", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+					m.Post("/mattermost/{id}", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+					m.Post("/googlechat/{id}", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksEditPost)
+					m.Post("/json/{id}", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksEditPost)
 				}, webhooksEnabled)
 
 				m.Group("/labels", func() {
@@ -708,6 +809,7 @@ func RegisterRoutes(m *web.Route) {
 				Post(bindIgnErr(auth.RepoSettingForm{}), repo.SettingsPost)
 			m.Post("/avatar", bindIgnErr(auth.AvatarForm{}), repo.SettingsAvatar)
 			m.Post("/avatar/delete", repo.SettingsDeleteAvatar)
+			m.Post("/gc", repo.GarbageCollectPost)
 
 			m.Group("/collaboration", func() {
 				m.Combo("").Get(repo.Collaboration).Post(repo.CollaborationPost)
@@ -720,10 +822,22 @@ func RegisterRoutes(m *web.Route) {
 			})
 			m.Group("/branches", func() {
 				m.Combo("").Get(repo.ProtectedBranch).Post(repo.ProtectedBranchPost)
+				m.Get("/protected.json", repo.ProtectedBranchesJSON)
 				m.Combo("/*").Get(repo.SettingsProtectedBranch).
 					Post(bindIgnErr(auth.ProtectBranchForm{}), context.RepoMustNotBeArchived(), repo.SettingsProtectedBranchPost)
 			}, repo.MustBeNotEmpty)
 
+			m.Group("/tags", func() {
+				m.Get("", repo.Tags)
+				m.Post("", bindIgnErr(auth.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.NewProtectedTagPost)
+				m.Post("/delete", context.RepoMustNotBeArchived(), repo.DeleteProtectedTagPost)
+				m.Get("/{id}", repo.EditProtectedTag)
+				m.Post("/{id}", bindIgnErr(auth.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.EditProtectedTagPost)
+			}, repo.MustBeNotEmpty)
+
+			m.Combo("/push_policy").Get(repo.PushPolicy).
+				Post(bindIgnErr(auth.PushPolicyForm{}), context.RepoMustNotBeArchived(), repo.PushPolicyPost)
+
 			m.Group("/hooks/git", func() {
 				m.Get("", repo.GitHooks)
 				m.Combo("/{name}").Get(repo.GitHooksEdit).
@@ -743,8 +857,15 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
 				m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
 				m.Post("/feishu/new", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
+				m.Post("/mattermost/new", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksNewPost)
+				m.Post("/googlechat/new", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksNewPost)
+				m.Post("/json/new", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksNewPost)
 				m.Get("/{id}", repo.WebHooksEdit)
 				m.Post("/{id}/test", repo.TestWebhook)
+				m.Post("/{id}/toggle", repo.ToggleWebhook)
+				m.Get("/{id}/deliveries", repo.WebHookDeliveries)
+				m.Get("/{id}/deliveries/{uuid}", repo.WebHookDelivery)
+				m.Post("/{id}/deliveries/{uuid}/redeliver", repo.WebHookRedeliver)
 				m.Post("/gitea/{id}", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 				m.Post("/gogs/{id}", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
 				m.Post("/slack/{id}", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
@@ -754,6 +875,9 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/matrix/{id}", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
 				m.Post("/msteams/{id}", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
 				m.Post("/feishu/{id}", bindIgnErr(auth.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
+				m.Post("/mattermost/{id}", bindIgnErr(auth.NewMattermostHookForm{}), repo.MattermostHooksEditPost)
+				m.Post("/googlechat/{id}", bindIgnErr(auth.NewGoogleChatHookForm{}), repo.GoogleChatHooksEditPost)
+				m.Post("/json/{id}", bindIgnErr(auth.NewJSONHookForm{}), repo.JSONHooksEditPost)
 			}, webhooksEnabled)
 
 			m.Group("/keys", func() {
@@ -762,6 +886,12 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Group("/access_tokens", func() {
+				m.Combo("").Get(repo.AccessTokens).
+					Post(bindIgnErr(auth.NewAccessTokenForm{}), repo.AccessTokensPost)
+				m.Post("/delete", repo.DeleteAccessToken)
+			})
+
 			m.Group("/lfs", func() {
 				m.Get("/", repo.LFSFiles)
 				m.Get("/show/{oid}", repo.LFSFileGet)
@@ -827,6 +957,8 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/reactions/{action}", bindIgnErr(auth.ReactionForm{}), repo.ChangeIssueReaction)
 				m.Post("/lock", reqRepoIssueWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
 				m.Post("/unlock", reqRepoIssueWriter, repo.UnlockIssue)
+				m.Post("/pin", reqRepoIssuesOrPullsWriter, repo.IssuePin)
+				m.Post("/deadline", reqRepoIssuesOrPullsWriter, bindIgnErr(auth.IssueDeadlineForm{}), repo.UpdateIssueDeadline)
 			}, context.RepoMustNotBeArchived())
 			m.Group("/{index}", func() {
 				m.Get("/attachments", repo.GetIssueAttachments)
@@ -840,6 +972,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/request_review", reqRepoIssuesOrPullsReader, repo.UpdatePullReviewRequest)
 			m.Post("/dismiss_review", reqRepoAdmin, bindIgnErr(auth.DismissReviewForm{}), repo.DismissReview)
 			m.Post("/status", reqRepoIssuesOrPullsWriter, repo.UpdateIssueStatus)
+			m.Post("/bulk", reqRepoIssuesOrPullsWriter, bindIgnErr(auth.BulkEditIssuesForm{}), repo.BulkEditIssues)
+			m.Post("/pins/reorder", reqRepoIssuesOrPullsWriter, bindIgnErr(auth.PinnedIssuesOrderForm{}), repo.ReorderPinnedIssues)
 			m.Post("/resolve_conversation", reqRepoIssuesOrPullsReader, repo.UpdateResolveConversation)
 			m.Post("/attachments", repo.UploadIssueAttachment)
 			m.Post("/attachments/remove", repo.DeleteAttachment)
@@ -847,10 +981,13 @@ func RegisterRoutes(m *web.Route) {
 		m.Group("/comments/{id}", func() {
 			m.Post("", repo.UpdateCommentContent)
 			m.Post("/delete", repo.DeleteComment)
+			m.Post("/extract", reqRepoIssueWriter, bindIgnErr(auth.ExtractCommentForm{}), repo.ExtractComment)
 			m.Post("/reactions/{action}", bindIgnErr(auth.ReactionForm{}), repo.ChangeCommentReaction)
+			m.Post("/history/{version}/restore", repo.RestoreCommentVersion)
 		}, context.RepoMustNotBeArchived())
 		m.Group("/comments/{id}", func() {
 			m.Get("/attachments", repo.GetCommentAttachments)
+			m.Get("/history", context.RepoRef(), repo.CommentHistory)
 		})
 		m.Group("/labels", func() {
 			m.Post("/new", bindIgnErr(auth.CreateLabelForm{}), repo.NewLabel)
@@ -896,6 +1033,7 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/commit/*", context.RepoRefByType(context.RepoRefCommit), repo.CreateBranch)
 			}, bindIgnErr(auth.NewBranchForm{}))
 			m.Post("/delete", repo.DeleteBranchPost)
+			m.Post("/delete_merged", bindIgnErr(auth.DeleteMergedBranchesForm{}), repo.DeleteMergedBranchesPost)
 			m.Post("/restore", repo.RestoreBranchPost)
 		}, context.RepoMustNotBeArchived(), reqRepoCodeWriter, repo.MustBeNotEmpty)
 
@@ -980,6 +1118,9 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/", repo.Wiki)
 			m.Get("/{page}", repo.Wiki)
 			m.Get("/_pages", repo.WikiPages)
+			m.Get("/search", repo.WikiSearch)
+			m.Get("/_export", repo.WikiExport)
+			m.Get("/{page}/export", repo.WikiPageExport)
 			m.Get("/{page}/_revision", repo.WikiRevision)
 			m.Get("/commit/{sha:[a-f0-9]{7,40}}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.Diff)
 			m.Get("/commit/{sha:[a-f0-9]{7,40}}.{:patch|diff}", repo.RawDiff)
@@ -1022,15 +1163,24 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{sha}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.ExcerptBlob)
 		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
 
+		m.Group("/diff_lines", func() {
+			m.Get("/{sha}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.DiffLines)
+		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
+
 		m.Group("/pulls/{index}", func() {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/auto_merge", context.RepoMustNotBeArchived(), bindIgnErr(auth.MergePullRequestForm{}), repo.ScheduleAutoMerge)
+			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMerge)
+			m.Post("/cancel_merge_queue", context.RepoMustNotBeArchived(), repo.CancelMergeQueue)
 			m.Post("/update", repo.UpdatePullRequest)
+			m.Get("/merge_status", repo.PullMergeStatus)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
+				m.Get("/lines", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFilesLines)
 				m.Group("/reviews", func() {
 					m.Get("/new_comment", repo.RenderNewCodeCommentForm)
 					m.Post("/comments", bindIgnErr(auth.CodeCommentForm{}), repo.CreateCodeComment)
@@ -1057,6 +1207,11 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/*", context.RepoRefByType(context.RepoRefLegacy), repo.SingleDownload)
 		}, repo.MustBeNotEmpty, reqRepoCodeReader)
 
+		m.Group("/git", func() {
+			m.Get("/blobs/{sha}", repo.GitBlob)
+			m.Get("/commits/{sha}", repo.GitCommit)
+		}, repo.MustBeNotEmpty, reqRepoCodeReader, repo.MustAuthenticateForGit)
+
 		m.Group("/commits", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.RefCommits)
 			m.Get("/tag/*", context.RepoRefByType(context.RepoRefTag), repo.RefCommits)
@@ -1075,6 +1230,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/graph", repo.Graph)
 			m.Get("/commit/{sha:([a-f0-9]{7,40})$}", repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.Diff)
 		}, repo.MustBeNotEmpty, context.RepoRef(), reqRepoCodeReader)
+		m.Group("/commit/{sha:([a-f0-9]{7,40})}/comments", func() {
+			m.Post("", bindIgnErr(auth.CreateCommitCommentForm{}), repo.CreateCommitComment)
+			m.Post("/{id}/delete", repo.DeleteCommitComment)
+		}, reqSignIn, repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoCodeReader)
 
 		m.Group("/src", func() {
 			m.Get("/branch/*", context.RepoRefByType(context.RepoRefBranch), repo.Home)
@@ -0,0 +1,53 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package routers
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/httpcache"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// SecurityTxt serves /.well-known/security.txt, either from the custom
+// directory or generated from the [security_txt] settings.
+func SecurityTxt(w http.ResponseWriter, req *http.Request) {
+	if setting.SecurityTxt.HasCustomFile {
+		filePath := path.Join(setting.CustomPath, "security.txt")
+		fi, err := os.Stat(filePath)
+		if err == nil && httpcache.HandleTimeCache(req, w, fi) {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeFile(w, req, filePath)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(securityTxtContent()))
+}
+
+func securityTxtContent() string {
+	var sb strings.Builder
+	for _, contact := range setting.SecurityTxt.Contact {
+		sb.WriteString("Contact: " + contact + "\n")
+	}
+	sb.WriteString("Expires: " + setting.SecurityTxt.Expires.UTC().Format(time.RFC3339) + "\n")
+	if setting.SecurityTxt.Encryption != "" {
+		sb.WriteString("Encryption: " + setting.SecurityTxt.Encryption + "\n")
+	}
+	if setting.SecurityTxt.Policy != "" {
+		sb.WriteString("Policy: " + setting.SecurityTxt.Policy + "\n")
+	}
+	if setting.SecurityTxt.PreferredLanguages != "" {
+		sb.WriteString("Preferred-Languages: " + setting.SecurityTxt.PreferredLanguages + "\n")
+	}
+	sb.WriteString("Canonical: " + setting.AppURL + ".well-known/security.txt\n")
+	return sb.String()
+}
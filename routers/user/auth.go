@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth/oauth2"
@@ -21,11 +22,13 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/password"
 	"code.gitea.io/gitea/modules/recaptcha"
+	"code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/turnstile"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
-	"code.gitea.io/gitea/routers/utils"
 	"code.gitea.io/gitea/services/externalaccount"
 	"code.gitea.io/gitea/services/mailer"
 
@@ -56,35 +59,37 @@ func AutoSignIn(ctx *context.Context) (bool, error) {
 		return false, nil
 	}
 
-	uname := ctx.GetCookie(setting.CookieUserName)
-	if len(uname) == 0 {
+	value := ctx.GetCookie(setting.CookieRememberName)
+	if len(value) == 0 {
 		return false, nil
 	}
 
 	isSucceed := false
 	defer func() {
 		if !isSucceed {
-			log.Trace("auto-login cookie cleared: %s", uname)
+			log.Trace("auto-login cookie cleared")
 			ctx.DeleteCookie(setting.CookieUserName)
 			ctx.DeleteCookie(setting.CookieRememberName)
 		}
 	}()
 
-	u, err := models.GetUserByName(uname)
+	days := 86400 * setting.LogInRememberDays
+	u, newValue, err := models.UseRememberToken(value, ctx.Req.UserAgent(), ctx.RemoteAddr(), time.Duration(days)*time.Second)
 	if err != nil {
-		if !models.IsErrUserNotExist(err) {
-			return false, fmt.Errorf("GetUserByName: %v", err)
+		if models.IsErrRememberTokenInvalid(err) {
+			log.Info("Invalid remember token from %s: %v", ctx.RemoteAddr(), err)
+			return false, nil
 		}
-		return false, nil
-	}
-
-	if val, ok := ctx.GetSuperSecureCookie(
-		base.EncodeMD5(u.Rands+u.Passwd), setting.CookieRememberName); !ok || val != u.Name {
-		return false, nil
+		return false, fmt.Errorf("UseRememberToken: %v", err)
 	}
 
 	isSucceed = true
 
+	if len(newValue) > 0 {
+		ctx.SetCookie(setting.CookieUserName, u.Name, days)
+		ctx.SetCookie(setting.CookieRememberName, newValue, days)
+	}
+
 	// Set session IDs
 	if err := ctx.Session.Set("uid", u.ID); err != nil {
 		return false, err
@@ -100,6 +105,19 @@ func AutoSignIn(ctx *context.Context) (bool, error) {
 	return true, nil
 }
 
+// SetRememberCookie issues a new "remember me" token for the device of the
+// current request and stores it in the remember cookie.
+func SetRememberCookie(ctx *context.Context, u *models.User) {
+	days := 86400 * setting.LogInRememberDays
+	_, value, err := models.NewRememberToken(u, ctx.Req.UserAgent(), ctx.RemoteAddr(), time.Duration(days)*time.Second)
+	if err != nil {
+		log.Error("NewRememberToken: %v", err)
+		return
+	}
+	ctx.SetCookie(setting.CookieUserName, u.Name, days)
+	ctx.SetCookie(setting.CookieRememberName, value, days)
+}
+
 func checkAutoLogin(ctx *context.Context) bool {
 	// Check auto-login.
 	isSucceed, err := AutoSignIn(ctx)
@@ -109,7 +127,7 @@ func checkAutoLogin(ctx *context.Context) bool {
 	}
 
 	redirectTo := ctx.Query("redirect_to")
-	if len(redirectTo) > 0 {
+	if util.IsLocalPath(redirectTo) {
 		middleware.SetRedirectToCookie(ctx.Resp, redirectTo)
 	} else {
 		redirectTo = ctx.GetCookie("redirect_to")
@@ -117,7 +135,7 @@ func checkAutoLogin(ctx *context.Context) bool {
 
 	if isSucceed {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
-		ctx.RedirectToFirst(redirectTo, setting.AppSubURL+string(setting.LandingPageURL))
+		ctx.RedirectToFirst(redirectTo, postLoginRedirectURL(ctx))
 		return true
 	}
 
@@ -140,12 +158,22 @@ func SignIn(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	ctx.Data["SAMLProviders"], err = models.GetActiveSAMLProviders()
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
 	ctx.Data["PageIsLogin"] = true
 	ctx.Data["EnableSSPI"] = models.IsSSPIEnabled()
 
+	if isSignInCaptchaRequired(ctx, "") {
+		ctx.Data["RequireSignInCaptcha"] = true
+		ctx.SetCaptchaData(true)
+	}
+
 	ctx.HTML(200, tplSignIn)
 }
 
@@ -160,6 +188,11 @@ func SignInPost(ctx *context.Context) {
 	}
 	ctx.Data["OrderedOAuth2Names"] = orderedOAuth2Names
 	ctx.Data["OAuth2Providers"] = oauth2Providers
+	ctx.Data["SAMLProviders"], err = models.GetActiveSAMLProviders()
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
 	ctx.Data["Title"] = ctx.Tr("sign_in")
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login"
 	ctx.Data["PageIsSignIn"] = true
@@ -172,11 +205,30 @@ func SignInPost(ctx *context.Context) {
 	}
 
 	form := web.GetForm(ctx).(*auth.SignInForm)
+	if isSignInCaptchaRequired(ctx, form.UserName) {
+		ctx.Data["RequireSignInCaptcha"] = true
+		ctx.SetCaptchaData(true)
+		if !checkCaptcha(ctx, tplSignIn, form) {
+			return
+		}
+	}
+
 	u, err := models.UserSignIn(form.UserName, form.Password)
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
+			recordSignInFailure(ctx, form.UserName)
+			if isSignInCaptchaRequired(ctx, form.UserName) {
+				ctx.Data["RequireSignInCaptcha"] = true
+				ctx.SetCaptchaData(true)
+			}
+			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			if uid := err.(models.ErrUserNotExist).UID; uid > 0 && recordLoginFailure(ctx, uid, tplSignIn, &form) {
+				return
+			}
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
+		} else if models.IsErrUserLocked(err) {
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			ctx.RenderWithErr(ctx.Tr("auth.account_locked", err.(models.ErrUserLocked).LockedUntil.FormatLong()), tplSignIn, &form)
 		} else if models.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
@@ -198,6 +250,13 @@ func SignInPost(ctx *context.Context) {
 		}
 		return
 	}
+	resetSignInFailures(ctx, form.UserName)
+	resetLoginRateLimit(ctx, loginRateLimitNameAccount(form.UserName))
+	if err := models.ResetLoginFailures(u); err != nil {
+		ctx.ServerError("ResetLoginFailures", err)
+		return
+	}
+
 	// If this user is enrolled in 2FA, we can't sign the user in just yet.
 	// Instead, redirect them to the 2FA authentication page.
 	_, err = models.GetTwoFactorByUID(u.ID)
@@ -224,13 +283,7 @@ func SignInPost(ctx *context.Context) {
 		return
 	}
 
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
-		return
-	}
-
-	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
+	ctx.Redirect(twoFactorPageURL(u.ID))
 }
 
 // TwoFactor shows the user a two-factor authentication page.
@@ -243,10 +296,12 @@ func TwoFactor(ctx *context.Context) {
 	}
 
 	// Ensure user is in a 2FA session.
-	if ctx.Session.Get("twofaUid") == nil {
+	idSess := ctx.Session.Get("twofaUid")
+	if idSess == nil {
 		ctx.ServerError("UserSignIn", errors.New("not in 2FA session"))
 		return
 	}
+	ctx.Data["HasSecurityKeys"] = hasSecurityKeys(idSess.(int64))
 
 	ctx.HTML(200, tplTwofa)
 }
@@ -264,6 +319,15 @@ func TwoFactorPost(ctx *context.Context) {
 	}
 
 	id := idSess.(int64)
+	u, err := models.GetUserByID(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if u.IsLocked() {
+		ctx.RenderWithErr(ctx.Tr("auth.account_locked", u.LockedUntilUnix.FormatLong()), tplTwofa, auth.TwoFactorAuthForm{})
+		return
+	}
 	twofa, err := models.GetTwoFactorByUID(id)
 	if err != nil {
 		ctx.ServerError("UserSignIn", err)
@@ -279,9 +343,8 @@ func TwoFactorPost(ctx *context.Context) {
 
 	if ok && twofa.LastUsedPasscode != form.Passcode {
 		remember := ctx.Session.Get("twofaRemember").(bool)
-		u, err := models.GetUserByID(id)
-		if err != nil {
-			ctx.ServerError("UserSignIn", err)
+		if err := models.ResetLoginFailures(u); err != nil {
+			ctx.ServerError("ResetLoginFailures", err)
 			return
 		}
 
@@ -309,6 +372,9 @@ func TwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	if recordLoginFailure(ctx, id, tplTwofa, auth.TwoFactorAuthForm{}) {
+		return
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_passcode_incorrect"), tplTwofa, auth.TwoFactorAuthForm{})
 }
 
@@ -343,6 +409,15 @@ func TwoFactorScratchPost(ctx *context.Context) {
 	}
 
 	id := idSess.(int64)
+	u, err := models.GetUserByID(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if u.IsLocked() {
+		ctx.RenderWithErr(ctx.Tr("auth.account_locked", u.LockedUntilUnix.FormatLong()), tplTwofaScratch, auth.TwoFactorScratchAuthForm{})
+		return
+	}
 	twofa, err := models.GetTwoFactorByUID(id)
 	if err != nil {
 		ctx.ServerError("UserSignIn", err)
@@ -363,9 +438,8 @@ func TwoFactorScratchPost(ctx *context.Context) {
 		}
 
 		remember := ctx.Session.Get("twofaRemember").(bool)
-		u, err := models.GetUserByID(id)
-		if err != nil {
-			ctx.ServerError("UserSignIn", err)
+		if err := models.ResetLoginFailures(u); err != nil {
+			ctx.ServerError("ResetLoginFailures", err)
 			return
 		}
 
@@ -375,6 +449,9 @@ func TwoFactorScratchPost(ctx *context.Context) {
 		return
 	}
 
+	if recordLoginFailure(ctx, id, tplTwofaScratch, auth.TwoFactorScratchAuthForm{}) {
+		return
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, auth.TwoFactorScratchAuthForm{})
 }
 
@@ -455,39 +532,61 @@ func U2FSign(ctx *context.Context) {
 		newCounter, authErr := r.Authenticate(*signResp, *challenge, reg.Counter)
 		if authErr == nil {
 			reg.Counter = newCounter
-			user, err := models.GetUserByID(id)
-			if err != nil {
-				ctx.ServerError("UserSignIn", err)
-				return
-			}
-			remember := ctx.Session.Get("twofaRemember").(bool)
 			if err := reg.UpdateCounter(); err != nil {
 				ctx.ServerError("UserSignIn", err)
 				return
 			}
+			handleSecurityKeySignIn(ctx, id)
+			return
+		}
+	}
+	ctx.Error(401)
+}
 
-			if ctx.Session.Get("linkAccount") != nil {
-				gothUser := ctx.Session.Get("linkAccountGothUser")
-				if gothUser == nil {
-					ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
-					return
-				}
+// handleSecurityKeySignIn signs in the user of the 2FA session after a security key
+// has been verified and responds with the URL to redirect to.
+func handleSecurityKeySignIn(ctx *context.Context, id int64) {
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	remember := ctx.Session.Get("twofaRemember").(bool)
 
-				err = externalaccount.LinkAccountToUser(user, gothUser.(goth.User))
-				if err != nil {
-					ctx.ServerError("UserSignIn", err)
-					return
-				}
-			}
-			redirect := handleSignInFull(ctx, user, remember, false)
-			if redirect == "" {
-				redirect = setting.AppSubURL + "/"
-			}
-			ctx.PlainText(200, []byte(redirect))
+	if ctx.Session.Get("linkAccount") != nil {
+		gothUser := ctx.Session.Get("linkAccountGothUser")
+		if gothUser == nil {
+			ctx.ServerError("UserSignIn", errors.New("not in LinkAccount session"))
+			return
+		}
+
+		err = externalaccount.LinkAccountToUser(user, gothUser.(goth.User))
+		if err != nil {
+			ctx.ServerError("UserSignIn", err)
 			return
 		}
 	}
-	ctx.Error(401)
+	redirect := handleSignInFull(ctx, user, remember, false)
+	if redirect == "" {
+		redirect = setting.AppSubURL + "/"
+	}
+	ctx.PlainText(200, []byte(redirect))
+}
+
+// recordLoginFailure counts a failed sign-in attempt of the user. If the attempt locked the
+// account, the lockout message is rendered with the template and true is returned.
+func recordLoginFailure(ctx *context.Context, uid int64, tpl base.TplName, form interface{}) bool {
+	lockedUntil, err := models.RecordLoginFailure(uid)
+	if err != nil {
+		log.Error("RecordLoginFailure: %v", err)
+		return false
+	}
+	if lockedUntil == 0 {
+		return false
+	}
+	log.Warn("Account of user %d has been locked after too many failed sign-in attempts, last from %s", uid, ctx.RemoteAddr())
+	ctx.RenderWithErr(ctx.Tr("auth.account_locked", lockedUntil.FormatLong()), tpl, form)
+	return true
 }
 
 // This handles the final part of the sign-in process of the user.
@@ -497,12 +596,11 @@ func handleSignIn(ctx *context.Context, u *models.User, remember bool) {
 
 func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyRedirect bool) string {
 	if remember {
-		days := 86400 * setting.LogInRememberDays
-		ctx.SetCookie(setting.CookieUserName, u.Name, days)
-		ctx.SetSuperSecureCookie(base.EncodeMD5(u.Rands+u.Passwd),
-			setting.CookieRememberName, u.Name, days)
+		SetRememberCookie(ctx, u)
 	}
 
+	resetLoginRateLimit(ctx, loginRateLimitUIDAccount(u.ID))
+
 	_ = ctx.Session.Delete("openid_verified_uri")
 	_ = ctx.Session.Delete("openid_signin_remember")
 	_ = ctx.Session.Delete("openid_determined_email")
@@ -510,6 +608,7 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 	_ = ctx.Session.Delete("twofaUid")
 	_ = ctx.Session.Delete("twofaRemember")
 	_ = ctx.Session.Delete("u2fChallenge")
+	_ = ctx.Session.Delete("webauthnSession")
 	_ = ctx.Session.Delete("linkAccount")
 	if err := ctx.Session.Set("uid", u.ID); err != nil {
 		log.Error("Error setting uid %d in session: %v", u.ID, err)
@@ -543,7 +642,7 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 		return setting.AppSubURL + "/"
 	}
 
-	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
+	if redirectTo := ctx.GetCookie("redirect_to"); util.IsLocalPath(redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
 		if obeyRedirect {
 			ctx.RedirectToFirst(redirectTo)
@@ -551,10 +650,23 @@ func handleSignInFull(ctx *context.Context, u *models.User, remember bool, obeyR
 		return redirectTo
 	}
 
+	redirectTo := postLoginRedirectURL(ctx)
 	if obeyRedirect {
-		ctx.Redirect(setting.AppSubURL + "/")
+		ctx.Redirect(redirectTo)
+	}
+	return redirectTo
+}
+
+// postLoginRedirectURL returns where users are sent after signing in if no page has been requested
+func postLoginRedirectURL(ctx *context.Context) string {
+	if setting.PostLoginRedirectURL == setting.LandingPageLastRepo {
+		// The cookie can be modified by the user, only follow it within this instance
+		if link := ctx.GetCookie(middleware.LastVisitedRepoCookieName); util.IsLocalPath(link) {
+			return link
+		}
+		return setting.AppSubURL + "/"
 	}
-	return setting.AppSubURL + "/"
+	return setting.AppSubURL + string(setting.PostLoginRedirectURL)
 }
 
 // SignInOAuth handles the OAuth2 login buttons
@@ -664,13 +776,13 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 			log.Error("UpdateExternalUser failed: %v", err)
 		}
 
-		if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 {
+		if redirectTo := ctx.GetCookie("redirect_to"); util.IsLocalPath(redirectTo) {
 			middleware.DeleteRedirectToCookie(ctx.Resp)
 			ctx.RedirectToFirst(redirectTo)
 			return
 		}
 
-		ctx.Redirect(setting.AppSubURL + "/")
+		ctx.Redirect(postLoginRedirectURL(ctx))
 		return
 	}
 
@@ -685,14 +797,8 @@ func handleOAuth2SignIn(u *models.User, gothUser goth.User, ctx *context.Context
 		log.Error("Error storing session: %v", err)
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
-		return
-	}
-
-	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
+	// If security keys are enrolled -> Redirect to WebAuthn instead
+	ctx.Redirect(twoFactorPageURL(u.ID))
 }
 
 // OAuth2UserLoginCallback attempts to handle the callback from the OAuth2 provider and if successful
@@ -753,6 +859,7 @@ func LinkAccount(ctx *context.Context) {
 	ctx.Data["RecaptchaURL"] = setting.Service.RecaptchaURL
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["DisableRegistration"] = setting.Service.DisableRegistration
 	ctx.Data["ShowRegistrationButton"] = false
 
@@ -807,6 +914,7 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["DisableRegistration"] = setting.Service.DisableRegistration
 	ctx.Data["ShowRegistrationButton"] = false
 
@@ -830,6 +938,9 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 		if models.IsErrUserNotExist(err) {
 			ctx.Data["user_exists"] = true
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplLinkAccount, &signInForm)
+		} else if models.IsErrUserLocked(err) {
+			ctx.Data["user_exists"] = true
+			ctx.RenderWithErr(ctx.Tr("auth.account_locked", err.(models.ErrUserLocked).LockedUntil.FormatLong()), tplLinkAccount, &signInForm)
 		} else {
 			ctx.ServerError("UserLinkAccount", err)
 		}
@@ -869,14 +980,8 @@ func LinkAccountPostSignIn(ctx *context.Context) {
 		log.Error("Error storing session: %v", err)
 	}
 
-	// If U2F is enrolled -> Redirect to U2F instead
-	regs, err := models.GetU2FRegistrationsByUID(u.ID)
-	if err == nil && len(regs) > 0 {
-		ctx.Redirect(setting.AppSubURL + "/user/u2f")
-		return
-	}
-
-	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
+	// If security keys are enrolled -> Redirect to WebAuthn instead
+	ctx.Redirect(twoFactorPageURL(u.ID))
 }
 
 // LinkAccountPostRegister handle the creation of a new account for an external account using signUp
@@ -894,6 +999,7 @@ func LinkAccountPostRegister(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["DisableRegistration"] = setting.Service.DisableRegistration
 	ctx.Data["ShowRegistrationButton"] = false
 
@@ -927,6 +1033,8 @@ func LinkAccountPostRegister(ctx *context.Context) {
 			valid, err = recaptcha.Verify(ctx.Req.Context(), form.GRecaptchaResponse)
 		case setting.HCaptcha:
 			valid, err = hcaptcha.Verify(ctx.Req.Context(), form.HcaptchaResponse)
+		case setting.Turnstile:
+			valid, err = turnstile.Verify(ctx.Req.Context(), form.TurnstileResponse)
 		default:
 			ctx.ServerError("Unknown Captcha Type", fmt.Errorf("Unknown Captcha Type: %s", setting.Service.CaptchaType))
 			return
@@ -1041,8 +1149,14 @@ func LinkAccountPostRegister(ctx *context.Context) {
 
 // HandleSignOut resets the session and sets the cookies
 func HandleSignOut(ctx *context.Context) {
+	if err := models.DeleteUserSessionByKeyHash(session.TrackedKeyHash(ctx.Session)); err != nil {
+		log.Error("DeleteUserSessionByKeyHash: %v", err)
+	}
 	_ = ctx.Session.Flush()
 	_ = ctx.Session.Destroy(ctx.Resp, ctx.Req)
+	if err := models.DeleteRememberTokenByValue(ctx.GetCookie(setting.CookieRememberName)); err != nil {
+		log.Error("DeleteRememberTokenByValue: %v", err)
+	}
 	ctx.DeleteCookie(setting.CookieUserName)
 	ctx.DeleteCookie(setting.CookieRememberName)
 	middleware.DeleteCSRFCookie(ctx.Resp)
@@ -1062,6 +1176,29 @@ func SignOut(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/")
 }
 
+// StopImpersonation returns an admin who impersonates a user to their own account
+func StopImpersonation(ctx *context.Context) {
+	if !ctx.IsImpersonated() {
+		ctx.Redirect(setting.AppSubURL + "/")
+		return
+	}
+
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditUserStopImpersonation,
+		TargetType: models.AuditTargetUser,
+		TargetID:   ctx.User.ID,
+		TargetName: ctx.User.Name,
+	})
+	adminName := session.ImpersonatorName(ctx.Session)
+	if _, err := session.StopImpersonation(ctx.Session); err != nil {
+		ctx.ServerError("StopImpersonation", err)
+		return
+	}
+	log.Info("Admin %s stopped impersonating %s", adminName, ctx.User.Name)
+
+	ctx.Redirect(fmt.Sprintf("%s/admin/users/%d", setting.AppSubURL, ctx.User.ID))
+}
+
 // SignUp render the register page
 func SignUp(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("sign_up")
@@ -1074,6 +1211,7 @@ func SignUp(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["PageIsSignUp"] = true
 
 	//Show Disabled Registration message if DisableRegistration or AllowOnlyExternalRegistration options are true
@@ -1095,6 +1233,7 @@ func SignUpPost(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["PageIsSignUp"] = true
 
 	//Permission denied if DisableRegistration or AllowOnlyExternalRegistration options are true
@@ -1118,6 +1257,8 @@ func SignUpPost(ctx *context.Context) {
 			valid, err = recaptcha.Verify(ctx.Req.Context(), form.GRecaptchaResponse)
 		case setting.HCaptcha:
 			valid, err = hcaptcha.Verify(ctx.Req.Context(), form.HcaptchaResponse)
+		case setting.Turnstile:
+			valid, err = turnstile.Verify(ctx.Req.Context(), form.TurnstileResponse)
 		default:
 			ctx.ServerError("Unknown Captcha Type", fmt.Errorf("Unknown Captcha Type: %s", setting.Service.CaptchaType))
 			return
@@ -1350,6 +1491,7 @@ func ForgotPasswd(ctx *context.Context) {
 	email := ctx.Query("email")
 	ctx.Data["Email"] = email
 
+	ctx.SetCaptchaData(setting.Service.EnableCaptcha && setting.Service.RequireCaptchaForForgotPassword)
 	ctx.Data["IsResetRequest"] = true
 	ctx.HTML(200, tplForgotPassword)
 }
@@ -1367,6 +1509,13 @@ func ForgotPasswdPost(ctx *context.Context) {
 	email := ctx.Query("email")
 	ctx.Data["Email"] = email
 
+	if setting.Service.EnableCaptcha && setting.Service.RequireCaptchaForForgotPassword {
+		ctx.SetCaptchaData(true)
+		if !checkCaptcha(ctx, tplForgotPassword, nil) {
+			return
+		}
+	}
+
 	u, err := models.GetUserByEmail(email)
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
@@ -1623,7 +1772,7 @@ func MustChangePasswordPost(ctx *context.Context) {
 
 	log.Trace("User updated password: %s", u.Name)
 
-	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
+	if redirectTo := ctx.GetCookie("redirect_to"); util.IsLocalPath(redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
 		ctx.RedirectToFirst(redirectTo)
 		return
@@ -0,0 +1,90 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// signInFailuresTTL is the time in seconds failed sign-in attempts are remembered
+const signInFailuresTTL = 3600
+
+// remoteIP returns the IP address of the client without the port
+func remoteIP(ctx *context.Context) string {
+	host, _, err := net.SplitHostPort(ctx.RemoteAddr())
+	if err != nil {
+		return ctx.RemoteAddr()
+	}
+	return host
+}
+
+// signInFailureKeys returns the cache keys counting failed sign-in attempts
+// for the given account and for the client of the request.
+func signInFailureKeys(ctx *context.Context, userName string) (userKey, ipKey string) {
+	return "SignInFailures_user_" + strings.ToLower(userName), "SignInFailures_ip_" + remoteIP(ctx)
+}
+
+func getSignInFailures(ctx *context.Context, key string) int {
+	val, _ := ctx.Cache.Get(key).(string)
+	n, _ := strconv.Atoi(val)
+	return n
+}
+
+// isSignInCaptchaRequired returns true if too many sign-in attempts failed
+// for the given account or from the client of the request.
+func isSignInCaptchaRequired(ctx *context.Context, userName string) bool {
+	if !setting.Service.EnableCaptcha || setting.Service.RequireCaptchaForSignInAfterFailures <= 0 {
+		return false
+	}
+	userKey, ipKey := signInFailureKeys(ctx, userName)
+	return getSignInFailures(ctx, userKey) >= setting.Service.RequireCaptchaForSignInAfterFailures ||
+		getSignInFailures(ctx, ipKey) >= setting.Service.RequireCaptchaForSignInAfterFailures
+}
+
+// recordSignInFailure counts a failed sign-in attempt
+func recordSignInFailure(ctx *context.Context, userName string) {
+	if !setting.Service.EnableCaptcha || setting.Service.RequireCaptchaForSignInAfterFailures <= 0 {
+		return
+	}
+	userKey, ipKey := signInFailureKeys(ctx, userName)
+	for _, key := range []string{userKey, ipKey} {
+		if err := ctx.Cache.Put(key, strconv.Itoa(getSignInFailures(ctx, key)+1), signInFailuresTTL); err != nil {
+			log.Error("Set cache(%s) fail: %v", key, err)
+		}
+	}
+}
+
+// resetSignInFailures forgets the failed sign-in attempts of an account after a successful sign-in
+func resetSignInFailures(ctx *context.Context, userName string) {
+	if !setting.Service.EnableCaptcha || setting.Service.RequireCaptchaForSignInAfterFailures <= 0 {
+		return
+	}
+	userKey, _ := signInFailureKeys(ctx, userName)
+	if err := ctx.Cache.Delete(userKey); err != nil {
+		log.Error("Delete cache(%s) fail: %v", userKey, err)
+	}
+}
+
+// checkCaptcha verifies the captcha of the request and renders the given
+// template with an error if it is invalid. It returns false if rendered.
+func checkCaptcha(ctx *context.Context, tpl base.TplName, form interface{}) bool {
+	valid, err := ctx.VerifyCaptcha()
+	if err != nil {
+		log.Debug("%s", err.Error())
+	}
+	if !valid {
+		ctx.Data["Err_Captcha"] = true
+		ctx.RenderWithErr(ctx.Tr("form.captcha_incorrect"), tpl, form)
+		return false
+	}
+	return true
+}
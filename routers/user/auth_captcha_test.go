@@ -0,0 +1,52 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"gitea.com/go-chi/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignInCaptchaAfterFailures(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	defer func(enabled bool, failures int) {
+		setting.Service.EnableCaptcha = enabled
+		setting.Service.RequireCaptchaForSignInAfterFailures = failures
+	}(setting.Service.EnableCaptcha, setting.Service.RequireCaptchaForSignInAfterFailures)
+	setting.Service.EnableCaptcha = true
+	setting.Service.RequireCaptchaForSignInAfterFailures = 2
+
+	c, err := cache.NewCacher(cache.Options{Adapter: "memory", Interval: 60})
+	assert.NoError(t, err)
+
+	ctx := test.MockContext(t, "user/login")
+	ctx.Cache = c
+	ctx.Req.RemoteAddr = "192.0.2.1:1234"
+	assert.False(t, isSignInCaptchaRequired(ctx, "user2"))
+
+	recordSignInFailure(ctx, "user2")
+	assert.False(t, isSignInCaptchaRequired(ctx, "user2"))
+	recordSignInFailure(ctx, "User2")
+	assert.True(t, isSignInCaptchaRequired(ctx, "user2"))
+
+	// a successful sign-in only resets the failures of the account, not those of the client
+	resetSignInFailures(ctx, "user2")
+	assert.True(t, isSignInCaptchaRequired(ctx, "user2"))
+
+	other := test.MockContext(t, "user/login")
+	other.Cache = c
+	other.Req.RemoteAddr = "192.0.2.2:1234"
+	assert.False(t, isSignInCaptchaRequired(other, "user2"))
+
+	setting.Service.RequireCaptchaForSignInAfterFailures = 0
+	assert.False(t, isSignInCaptchaRequired(ctx, "user2"))
+}
@@ -19,6 +19,8 @@ import (
 	"code.gitea.io/gitea/modules/recaptcha"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/turnstile"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/services/mailer"
@@ -47,7 +49,7 @@ func SignInOpenID(ctx *context.Context) {
 	}
 
 	redirectTo := ctx.Query("redirect_to")
-	if len(redirectTo) > 0 {
+	if util.IsLocalPath(redirectTo) {
 		middleware.SetRedirectToCookie(ctx.Resp, redirectTo)
 	} else {
 		redirectTo = ctx.GetCookie("redirect_to")
@@ -294,6 +296,8 @@ func ConnectOpenIDPost(ctx *context.Context) {
 	if err != nil {
 		if models.IsErrUserNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplConnectOID, &form)
+		} else if models.IsErrUserLocked(err) {
+			ctx.RenderWithErr(ctx.Tr("auth.account_locked", err.(models.ErrUserLocked).LockedUntil.FormatLong()), tplConnectOID, &form)
 		} else {
 			ctx.ServerError("ConnectOpenIDPost", err)
 		}
@@ -334,6 +338,7 @@ func RegisterOpenID(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["RecaptchaURL"] = setting.Service.RecaptchaURL
 	ctx.Data["OpenID"] = oid
 	userName, _ := ctx.Session.Get("openid_determined_username").(string)
@@ -366,6 +371,7 @@ func RegisterOpenIDPost(ctx *context.Context) {
 	ctx.Data["CaptchaType"] = setting.Service.CaptchaType
 	ctx.Data["RecaptchaSitekey"] = setting.Service.RecaptchaSitekey
 	ctx.Data["HcaptchaSitekey"] = setting.Service.HcaptchaSitekey
+	ctx.Data["TurnstileSitekey"] = setting.Service.TurnstileSitekey
 	ctx.Data["OpenID"] = oid
 
 	if setting.Service.EnableCaptcha {
@@ -386,6 +392,12 @@ func RegisterOpenIDPost(ctx *context.Context) {
 				return
 			}
 			valid, err = hcaptcha.Verify(ctx.Req.Context(), form.HcaptchaResponse)
+		case setting.Turnstile:
+			if err := ctx.Req.ParseForm(); err != nil {
+				ctx.ServerError("", err)
+				return
+			}
+			valid, err = turnstile.Verify(ctx.Req.Context(), form.TurnstileResponse)
 		default:
 			ctx.ServerError("Unknown Captcha Type", fmt.Errorf("Unknown Captcha Type: %s", setting.Service.CaptchaType))
 			return
@@ -0,0 +1,150 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"gitea.com/go-chi/cache"
+)
+
+var (
+	loginRateLimitMemoryOnce sync.Once
+	loginRateLimitMemory     cache.Cache
+
+	// loginRateLimitMutex serializes the creation of the counters of this process with their
+	// increments, the cache service increments them atomically between the processes
+	loginRateLimitMutex sync.Mutex
+)
+
+// loginRateLimitStore returns the store of the attempt counters: the cache service,
+// which can be shared by multiple instances, or a store local to this process.
+func loginRateLimitStore(ctx *context.Context) cache.Cache {
+	if setting.LoginRateLimit.Store == "cache" && ctx.Cache != nil {
+		return ctx.Cache
+	}
+	loginRateLimitMemoryOnce.Do(func() {
+		var err error
+		if loginRateLimitMemory, err = cache.NewCacher(cache.Options{Adapter: "memory", Interval: 60}); err != nil {
+			log.Fatal("Unable to create the login rate limit store: %v", err)
+		}
+	})
+	return loginRateLimitMemory
+}
+
+// loginRateLimitWindow returns the current fixed window and the seconds until it ends
+func loginRateLimitWindow(now time.Time) (window, retryAfter int64) {
+	size := int64(setting.LoginRateLimit.Window / time.Second)
+	window = now.Unix() / size
+	return window, (window+1)*size - now.Unix()
+}
+
+func loginRateLimitNameAccount(userName string) string {
+	return "name_" + strings.ToLower(userName)
+}
+
+func loginRateLimitUIDAccount(uid int64) string {
+	return "uid_" + strconv.FormatInt(uid, 10)
+}
+
+// loginRateLimitAccount returns the account the attempt of the request is made for:
+// the submitted user name, or the user in a two-factor session.
+func loginRateLimitAccount(ctx *context.Context) string {
+	if userName := ctx.Req.FormValue("user_name"); userName != "" {
+		return loginRateLimitNameAccount(userName)
+	}
+	if uid, ok := ctx.Session.Get("twofaUid").(int64); ok {
+		return loginRateLimitUIDAccount(uid)
+	}
+	return ""
+}
+
+type loginRateLimitCounter struct {
+	key   string
+	limit int
+}
+
+func loginRateLimitCounters(ctx *context.Context, account string, window int64) []loginRateLimitCounter {
+	suffix := "_" + strconv.FormatInt(window, 10)
+	counters := make([]loginRateLimitCounter, 0, 2)
+	if setting.LoginRateLimit.MaxAttemptsPerIP > 0 {
+		counters = append(counters, loginRateLimitCounter{"LoginRateLimit_ip_" + remoteIP(ctx) + suffix, setting.LoginRateLimit.MaxAttemptsPerIP})
+	}
+	if account != "" && setting.LoginRateLimit.MaxAttemptsPerUser > 0 {
+		counters = append(counters, loginRateLimitCounter{"LoginRateLimit_" + account + suffix, setting.LoginRateLimit.MaxAttemptsPerUser})
+	}
+	return counters
+}
+
+func getLoginAttempts(store cache.Cache, key string) int {
+	// the memory store keeps the counters as integers, the other ones as strings
+	switch val := store.Get(key).(type) {
+	case int:
+		return val
+	case string:
+		n, _ := strconv.Atoi(val)
+		return n
+	}
+	return 0
+}
+
+// incrLoginAttempts counts an attempt, the counter expires at the end of the window it is created in
+func incrLoginAttempts(store cache.Cache, key string, ttl int64) error {
+	loginRateLimitMutex.Lock()
+	defer loginRateLimitMutex.Unlock()
+
+	if !store.IsExist(key) {
+		if err := store.Put(key, 0, ttl); err != nil {
+			return err
+		}
+	}
+	return store.Incr(key)
+}
+
+// LoginRateLimit rejects sign-in attempts with 429 Too Many Requests once too many
+// attempts were made in the current window from the client or for the account.
+func LoginRateLimit(ctx *context.Context) {
+	if !setting.LoginRateLimit.Enabled {
+		return
+	}
+	store := loginRateLimitStore(ctx)
+	window, retryAfter := loginRateLimitWindow(time.Now())
+	counters := loginRateLimitCounters(ctx, loginRateLimitAccount(ctx), window)
+	for _, counter := range counters {
+		if getLoginAttempts(store, counter.key) >= counter.limit {
+			log.Info("Too many sign-in attempts from %s (%s), rejected", ctx.RemoteAddr(), counter.key)
+			ctx.Resp.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			ctx.Error(http.StatusTooManyRequests, ctx.Tr("auth.login_rate_limited"))
+			return
+		}
+	}
+	ttl := int64(setting.LoginRateLimit.Window / time.Second)
+	for _, counter := range counters {
+		if err := incrLoginAttempts(store, counter.key, ttl); err != nil {
+			log.Error("Incr cache(%s) fail: %v", counter.key, err)
+		}
+	}
+}
+
+// resetLoginRateLimit forgets the attempts made for an account after it signed in successfully.
+// The attempts of the client are kept.
+func resetLoginRateLimit(ctx *context.Context, account string) {
+	if !setting.LoginRateLimit.Enabled || setting.LoginRateLimit.MaxAttemptsPerUser <= 0 {
+		return
+	}
+	window, _ := loginRateLimitWindow(time.Now())
+	key := "LoginRateLimit_" + account + "_" + strconv.FormatInt(window, 10)
+	if err := loginRateLimitStore(ctx).Delete(key); err != nil {
+		log.Error("Delete cache(%s) fail: %v", key, err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"gitea.com/go-chi/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginRateLimitWindow(t *testing.T) {
+	defer func(window time.Duration) {
+		setting.LoginRateLimit.Window = window
+	}(setting.LoginRateLimit.Window)
+	setting.LoginRateLimit.Window = time.Minute
+
+	window, retryAfter := loginRateLimitWindow(time.Unix(125, 0))
+	assert.EqualValues(t, 2, window)
+	assert.EqualValues(t, 55, retryAfter)
+}
+
+func TestIncrLoginAttempts(t *testing.T) {
+	c, err := cache.NewCacher(cache.Options{Adapter: "memory", Interval: 60})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, incrLoginAttempts(c, "LoginRateLimit_test", 60))
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 20, getLoginAttempts(c, "LoginRateLimit_test"))
+}
+
+func TestLoginRateLimit(t *testing.T) {
+	models.PrepareTestEnv(t)
+
+	saved := setting.LoginRateLimit
+	defer func() {
+		setting.LoginRateLimit = saved
+	}()
+	setting.LoginRateLimit.Enabled = true
+	setting.LoginRateLimit.Window = time.Hour
+	setting.LoginRateLimit.MaxAttemptsPerIP = 3
+	setting.LoginRateLimit.MaxAttemptsPerUser = 2
+	setting.LoginRateLimit.Store = "cache"
+
+	c, err := cache.NewCacher(cache.Options{Adapter: "memory", Interval: 60})
+	assert.NoError(t, err)
+
+	attempt := func(remoteAddr, userName string) *context.Context {
+		ctx := test.MockContext(t, "user/login")
+		ctx.Cache = c
+		ctx.Req.Method = "POST"
+		ctx.Req.Form = url.Values{"user_name": {userName}}
+		ctx.Req.RemoteAddr = remoteAddr
+		LoginRateLimit(ctx)
+		return ctx
+	}
+
+	assert.NotEqual(t, http.StatusTooManyRequests, attempt("192.0.2.1:1234", "user2").Resp.Status())
+	assert.NotEqual(t, http.StatusTooManyRequests, attempt("192.0.2.1:1234", "User2").Resp.Status())
+
+	// the account is limited, from any client
+	ctx := attempt("192.0.2.2:1234", "user2")
+	assert.EqualValues(t, http.StatusTooManyRequests, ctx.Resp.Status())
+	assert.NotEmpty(t, ctx.Resp.Header().Get("Retry-After"))
+
+	// a successful sign-in resets the counter of the account
+	resetLoginRateLimit(ctx, loginRateLimitNameAccount("user2"))
+	assert.NotEqual(t, http.StatusTooManyRequests, attempt("192.0.2.2:1234", "user2").Resp.Status())
+
+	// the client is limited for any account
+	assert.NotEqual(t, http.StatusTooManyRequests, attempt("192.0.2.1:1234", "user5").Resp.Status())
+	ctx = attempt("192.0.2.1:1234", "user4")
+	assert.EqualValues(t, http.StatusTooManyRequests, ctx.Resp.Status())
+
+	setting.LoginRateLimit.Enabled = false
+	assert.NotEqual(t, http.StatusTooManyRequests, attempt("192.0.2.1:1234", "user4").Resp.Status())
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/saml"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// samlRequestTTL is the time in seconds a user has to sign in at the identity provider
+const samlRequestTTL = 10 * 60
+
+// samlURLs returns the entity ID and the URL of the assertion consumer service of Gitea for the given source
+func samlURLs(source *models.LoginSource) (entityID, acsURL string) {
+	link := setting.AppURL + "user/saml/" + url.PathEscape(source.Name)
+	entityID = source.SAML().ServiceProviderEntityID
+	if entityID == "" {
+		entityID = link + "/metadata"
+	}
+	return entityID, link + "/acs"
+}
+
+func getActiveSAMLLoginSource(ctx *context.Context) *models.LoginSource {
+	source, err := models.GetActiveSAMLLoginSourceByName(ctx.Params(":provider"))
+	if err != nil {
+		if models.IsErrLoginSourceNotExist(err) {
+			ctx.NotFound("GetActiveSAMLLoginSourceByName", err)
+		} else {
+			ctx.ServerError("GetActiveSAMLLoginSourceByName", err)
+		}
+		return nil
+	}
+	return source
+}
+
+// SAMLMetadata returns the metadata of Gitea as service provider of the given SAML source
+func SAMLMetadata(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	metadata, err := saml.Metadata(samlURLs(source))
+	if err != nil {
+		ctx.ServerError("Metadata", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	if _, err := ctx.Resp.Write(metadata); err != nil {
+		log.Error("Unable to write SAML metadata: %v", err)
+	}
+}
+
+// SignInSAML redirects the user to the identity provider of the given SAML source to sign in
+func SignInSAML(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	requestID, err := saml.NewRequestID()
+	if err != nil {
+		ctx.ServerError("NewRequestID", err)
+		return
+	}
+	if err := ctx.Cache.Put("SAMLRequest_"+requestID, source.Name, samlRequestTTL); err != nil {
+		ctx.ServerError("Put", err)
+		return
+	}
+
+	var relayState string
+	if redirectTo := ctx.GetCookie("redirect_to"); util.IsLocalPath(redirectTo) {
+		relayState = redirectTo
+	}
+	entityID, acsURL := samlURLs(source)
+	link, err := source.SAML().AuthnRequestURL(entityID, acsURL, requestID, relayState, time.Now())
+	if err != nil {
+		ctx.ServerError("AuthnRequestURL", err)
+		return
+	}
+	ctx.Redirect(link)
+}
+
+// SAMLAssertionConsumerService signs in the user with the response posted by the identity provider
+// of the given SAML source, either in response to SignInSAML or initiated by the identity provider
+func SAMLAssertionConsumerService(ctx *context.Context) {
+	source := getActiveSAMLLoginSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	isRequested := func(requestID string) bool {
+		key := "SAMLRequest_" + requestID
+		name, ok := ctx.Cache.Get(key).(string)
+		if !ok || name != source.Name {
+			return false
+		}
+		if err := ctx.Cache.Delete(key); err != nil {
+			log.Error("Unable to delete SAML request %s: %v", requestID, err)
+		}
+		return true
+	}
+	entityID, acsURL := samlURLs(source)
+	su, err := source.SAML().ParseResponse(ctx.Req.FormValue("SAMLResponse"), entityID, acsURL, isRequested, time.Now())
+	if err != nil {
+		log.Warn("Failed SAML authentication via %s from %s: %v", source.Name, ctx.RemoteAddr(), err)
+		ctx.Flash.Error(ctx.Tr("auth.saml_invalid_response"))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	u, err := models.LoginViaSAML(source, su)
+	if err != nil {
+		switch {
+		case models.IsErrUserNotExist(err):
+			ctx.Flash.Error(ctx.Tr("auth.saml_no_account", su.NameID))
+		case models.IsErrUserAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("form.username_been_taken"))
+		case models.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
+		case models.IsErrNameReserved(err), models.IsErrNamePatternNotAllowed(err), models.IsErrNameCharsNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("auth.saml_invalid_username", su.Username))
+		case models.IsErrUserProhibitLogin(err):
+			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
+			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
+			return
+		default:
+			ctx.ServerError("LoginViaSAML", err)
+			return
+		}
+		log.Info("Failed SAML authentication of %s via %s from %s: %v", su.NameID, source.Name, ctx.RemoteAddr(), err)
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
+	// If this user is enrolled in 2FA, we can't sign the user in just yet.
+	// Instead, redirect them to the 2FA authentication page.
+	if _, err := models.GetTwoFactorByUID(u.ID); err == nil {
+		if err := ctx.Session.Set("twofaUid", u.ID); err != nil {
+			log.Error("Error setting twofaUid in session: %v", err)
+		}
+		if err := ctx.Session.Set("twofaRemember", false); err != nil {
+			log.Error("Error setting twofaRemember in session: %v", err)
+		}
+		if err := ctx.Session.Release(); err != nil {
+			log.Error("Error storing session: %v", err)
+		}
+		ctx.Redirect(twoFactorPageURL(u.ID))
+		return
+	} else if !models.IsErrTwoFactorNotEnrolled(err) {
+		ctx.ServerError("GetTwoFactorByUID", err)
+		return
+	}
+
+	redirectTo := handleSignInFull(ctx, u, false, false)
+	if ctx.Written() {
+		return
+	}
+	if relayState := ctx.Req.FormValue("RelayState"); util.IsLocalPath(relayState) {
+		redirectTo = relayState
+	}
+	ctx.RedirectToFirst(redirectTo)
+}
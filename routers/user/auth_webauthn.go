@@ -0,0 +1,190 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// tplWebAuthn template for the WebAuthn login page
+const tplWebAuthn base.TplName = "user/auth/webauthn"
+
+// hasSecurityKeys returns whether the user has registered WebAuthn credentials or U2F keys
+func hasSecurityKeys(uid int64) bool {
+	if has, err := models.HasWebAuthnCredentialsByUID(uid); err != nil {
+		log.Error("HasWebAuthnCredentialsByUID: %v", err)
+	} else if has {
+		return true
+	}
+	regs, err := models.GetU2FRegistrationsByUID(uid)
+	return err == nil && len(regs) > 0
+}
+
+// twoFactorPageURL returns the page a user in a 2FA session is sent to:
+// the security key page if keys are enrolled, the TOTP page otherwise.
+func twoFactorPageURL(uid int64) string {
+	if hasSecurityKeys(uid) {
+		return setting.AppSubURL + "/user/webauthn"
+	}
+	return setting.AppSubURL + "/user/two_factor"
+}
+
+// WebAuthn shows the WebAuthn login page
+func WebAuthn(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("twofa")
+	// Check auto-login.
+	if checkAutoLogin(ctx) {
+		return
+	}
+
+	// Ensure user is in a 2FA session.
+	idSess := ctx.Session.Get("twofaUid")
+	if idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
+		return
+	}
+
+	// browsers without WebAuthn support fall back to the U2F API if U2F keys are enrolled
+	ctx.Data["FallbackURL"] = setting.AppSubURL + "/user/two_factor"
+	if regs, err := models.GetU2FRegistrationsByUID(idSess.(int64)); err == nil && len(regs) > 0 {
+		ctx.Data["FallbackURL"] = setting.AppSubURL + "/user/u2f"
+	}
+
+	ctx.HTML(200, tplWebAuthn)
+}
+
+// securityKeys collects the WebAuthn credentials and the U2F keys of a user
+type securityKeys struct {
+	credentials []*webauthn.Credential
+	webAuthn    map[*webauthn.Credential]*models.WebAuthnCredential
+	u2f         map[*webauthn.Credential]*models.U2FRegistration
+}
+
+func loadSecurityKeys(uid int64) (*securityKeys, error) {
+	creds, err := models.GetWebAuthnCredentialsByUID(uid)
+	if err != nil {
+		return nil, err
+	}
+	regs, err := models.GetU2FRegistrationsByUID(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := &securityKeys{
+		webAuthn: make(map[*webauthn.Credential]*models.WebAuthnCredential, len(creds)),
+		u2f:      make(map[*webauthn.Credential]*models.U2FRegistration, len(regs)),
+	}
+	for _, cred := range creds {
+		c := cred.ToCredential()
+		keys.credentials = append(keys.credentials, c)
+		keys.webAuthn[c] = cred
+	}
+	legacy := regs.LegacyU2FCredentials()
+	for _, reg := range regs {
+		if c, ok := legacy[reg.ID]; ok {
+			keys.credentials = append(keys.credentials, c)
+			keys.u2f[c] = reg
+		}
+	}
+	return keys, nil
+}
+
+// WebAuthnChallenge submits the assertion options to the browser
+func WebAuthnChallenge(ctx *context.Context) {
+	// Ensure user is in a 2FA session.
+	idSess := ctx.Session.Get("twofaUid")
+	if idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
+		return
+	}
+	id := idSess.(int64)
+	keys, err := loadSecurityKeys(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if len(keys.credentials) == 0 {
+		ctx.ServerError("UserSignIn", errors.New("no device registered"))
+		return
+	}
+
+	rp, err := webauthn.NewRelyingParty()
+	if err != nil {
+		ctx.ServerError("webauthn.NewRelyingParty", err)
+		return
+	}
+	options, session, err := rp.BeginLogin(id, keys.credentials, len(keys.u2f) > 0)
+	if err != nil {
+		ctx.ServerError("webauthn.BeginLogin", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnSession", session); err != nil {
+		ctx.ServerError("UserSignIn: unable to set webauthnSession in session", err)
+		return
+	}
+	if err := ctx.Session.Release(); err != nil {
+		ctx.ServerError("UserSignIn: unable to store session", err)
+	}
+
+	ctx.JSON(200, options)
+}
+
+// WebAuthnSign authenticates the user by the assertion of the authenticator
+func WebAuthnSign(ctx *context.Context) {
+	assertion := web.GetForm(ctx).(*webauthn.CredentialAssertionResponse)
+	sessionData, ok := ctx.Session.Get("webauthnSession").(*webauthn.SessionData)
+	idSess := ctx.Session.Get("twofaUid")
+	if !ok || idSess == nil {
+		ctx.ServerError("UserSignIn", errors.New("not in WebAuthn session"))
+		return
+	}
+	id := idSess.(int64)
+	if sessionData.UserID != id {
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := loadSecurityKeys(id)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	rp, err := webauthn.NewRelyingParty()
+	if err != nil {
+		ctx.ServerError("webauthn.NewRelyingParty", err)
+		return
+	}
+	cred, err := rp.FinishLogin(sessionData, assertion, keys.credentials)
+	if err != nil {
+		log.Debug("WebAuthn assertion of user %d failed: %v", id, err)
+		ctx.Error(http.StatusUnauthorized)
+		return
+	}
+
+	if c, ok := keys.webAuthn[cred]; ok {
+		c.SignCount = cred.SignCount
+		if err := c.UpdateSignCount(); err != nil {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+	} else if reg, ok := keys.u2f[cred]; ok {
+		// the U2F key works through WebAuthn, keep it as WebAuthn credential from now on
+		if _, err := models.UpgradeU2FRegistration(reg, cred); err != nil {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+	}
+
+	handleSecurityKeySignIn(ctx, id)
+}
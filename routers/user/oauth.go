@@ -156,8 +156,11 @@ func newAccessTokenResponse(grant *models.OAuth2Grant, clientSecret string) (*Ac
 	}
 
 	// generate OpenID Connect id_token
+	// The id_token is signed with HS256 using the client secret, the only key shared with the client.
+	// Public clients have no secret, so they could neither verify nor trust an id_token: they do not
+	// get one and identify the user with the access token through the API instead.
 	signedIDToken := ""
-	if grant.ScopeContains("openid") {
+	if grant.ScopeContains("openid") && clientSecret != "" {
 		app, err := models.GetOAuth2ApplicationByID(grant.ApplicationID)
 		if err != nil {
 			return nil, &AccessTokenError{
@@ -243,9 +246,20 @@ func AuthorizeOAuth(ctx *context.Context) {
 	}
 
 	// pkce support
+	if form.CodeChallenge != "" && form.CodeChallengeMethod == "" {
+		// plain is the default method, see https://tools.ietf.org/html/rfc7636#section-4.3
+		form.CodeChallengeMethod = "plain"
+	}
 	switch form.CodeChallengeMethod {
-	case "S256":
-	case "plain":
+	case "S256", "plain":
+		if !isValidCodeChallenge(form.CodeChallenge) {
+			handleAuthorizeError(ctx, AuthorizeError{
+				ErrorCode:        ErrorCodeInvalidRequest,
+				ErrorDescription: "invalid code challenge",
+				State:            form.State,
+			}, form.RedirectURI)
+			return
+		}
 		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallengeMethod); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
@@ -254,7 +268,7 @@ func AuthorizeOAuth(ctx *context.Context) {
 			}, form.RedirectURI)
 			return
 		}
-		if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallenge); err != nil {
+		if err := ctx.Session.Set("CodeChallenge", form.CodeChallenge); err != nil {
 			handleAuthorizeError(ctx, AuthorizeError{
 				ErrorCode:        ErrorCodeServerError,
 				ErrorDescription: "cannot set code challenge",
@@ -268,7 +282,9 @@ func AuthorizeOAuth(ctx *context.Context) {
 			log.Error("Unable to save changes to the session: %v", err)
 		}
 	case "":
-		break
+		// do not reuse the challenge of an earlier authorization request
+		_ = ctx.Session.Delete("CodeChallengeMethod")
+		_ = ctx.Session.Delete("CodeChallenge")
 	default:
 		handleAuthorizeError(ctx, AuthorizeError{
 			ErrorCode:        ErrorCodeInvalidRequest,
@@ -388,31 +404,36 @@ func GrantApplicationOAuth(ctx *context.Context) {
 	ctx.Redirect(redirect.String(), 302)
 }
 
+// parseBasicClientCredentials reads the client credentials from the basic auth header.
+// It returns false only if a basic auth header is present but malformed.
+func parseBasicClientCredentials(ctx *context.Context) (clientID, clientSecret string, ok bool) {
+	authHeader := ctx.Req.Header.Get("Authorization")
+	authContent := strings.SplitN(authHeader, " ", 2)
+	if len(authContent) != 2 || authContent[0] != "Basic" {
+		return "", "", true
+	}
+	payload, err := base64.StdEncoding.DecodeString(authContent[1])
+	if err != nil {
+		return "", "", false
+	}
+	pair := strings.SplitN(string(payload), ":", 2)
+	if len(pair) != 2 {
+		return "", "", false
+	}
+	return pair[0], pair[1], true
+}
+
 // AccessTokenOAuth manages all access token requests by the client
 func AccessTokenOAuth(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*auth.AccessTokenForm)
 	if form.ClientID == "" {
-		authHeader := ctx.Req.Header.Get("Authorization")
-		authContent := strings.SplitN(authHeader, " ", 2)
-		if len(authContent) == 2 && authContent[0] == "Basic" {
-			payload, err := base64.StdEncoding.DecodeString(authContent[1])
-			if err != nil {
-				handleAccessTokenError(ctx, AccessTokenError{
-					ErrorCode:        AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "cannot parse basic auth header",
-				})
-				return
-			}
-			pair := strings.SplitN(string(payload), ":", 2)
-			if len(pair) != 2 {
-				handleAccessTokenError(ctx, AccessTokenError{
-					ErrorCode:        AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "cannot parse basic auth header",
-				})
-				return
-			}
-			form.ClientID = pair[0]
-			form.ClientSecret = pair[1]
+		var ok bool
+		if form.ClientID, form.ClientSecret, ok = parseBasicClientCredentials(ctx); !ok {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot parse basic auth header",
+			})
+			return
 		}
 	}
 	switch form.GrantType {
@@ -456,6 +477,12 @@ func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
 			ErrorDescription: "token was already used",
 		})
 		log.Warn("A client tried to use a refresh token for grant_id = %d was used twice!", grant.ID)
+		if token.Counter != 0 {
+			// the token family might be stolen, revoke the grant so that all tokens derived from it become invalid
+			if err := models.RevokeOAuth2Grant(grant.ID, grant.UserID); err != nil {
+				log.Error("Unable to revoke grant_id = %d after refresh token reuse: %v", grant.ID, err)
+			}
+		}
 		return
 	}
 	accessToken, tokenErr := newAccessTokenResponse(grant, form.ClientSecret)
@@ -475,41 +502,50 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 		})
 		return
 	}
-	if !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+	authorizationCode, err := models.GetOAuth2AuthorizationByCode(form.Code)
+	if err != nil || authorizationCode == nil {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
 		})
 		return
 	}
-	if form.RedirectURI != "" && !app.ContainsRedirectURI(form.RedirectURI) {
+	// check if granted for this application, before trusting the code to authenticate the client
+	if authorizationCode.Grant.ApplicationID != app.ID {
 		handleAccessTokenError(ctx, AccessTokenError{
-			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
-			ErrorDescription: "client is not authorized",
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid grant",
 		})
 		return
 	}
-	authorizationCode, err := models.GetOAuth2AuthorizationByCode(form.Code)
-	if err != nil || authorizationCode == nil {
+	// public clients can not keep a secret, they authenticate by the PKCE code verifier instead
+	isPublicClient := form.ClientSecret == "" && authorizationCode.CodeChallenge != ""
+	if !isPublicClient && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
 		})
 		return
 	}
-	// check if code verifier authorizes the client, PKCE support
-	if !authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
+	if form.RedirectURI != "" && !app.ContainsRedirectURI(form.RedirectURI) {
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
 			ErrorDescription: "client is not authorized",
 		})
 		return
 	}
-	// check if granted for this application
-	if authorizationCode.Grant.ApplicationID != app.ID {
+	if authorizationCode.CodeChallenge != "" && form.CodeVerifier == "" {
 		handleAccessTokenError(ctx, AccessTokenError{
-			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
-			ErrorDescription: "invalid grant",
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "code verifier is required",
+		})
+		return
+	}
+	// check if code verifier authorizes the client, PKCE support
+	if !authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
 		})
 		return
 	}
@@ -519,6 +555,7 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
 			ErrorDescription: "cannot proceed your request",
 		})
+		return
 	}
 	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant, form.ClientSecret)
 	if tokenErr != nil {
@@ -529,6 +566,82 @@ func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
 	ctx.JSON(200, resp)
 }
 
+// RevokeOAuth revokes an access or refresh token together with all tokens of the same grant,
+// see https://tools.ietf.org/html/rfc7009
+func RevokeOAuth(ctx *context.Context) {
+	form := *web.GetForm(ctx).(*auth.RevokeTokenForm)
+	if form.ClientID == "" {
+		var ok bool
+		if form.ClientID, form.ClientSecret, ok = parseBasicClientCredentials(ctx); !ok {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot parse basic auth header",
+			})
+			return
+		}
+	}
+	if form.Token == "" {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "token is required",
+		})
+		return
+	}
+
+	// invalid tokens do not cause an error response, the client can not handle it anyway
+	token, err := models.ParseOAuth2Token(form.Token)
+	if err != nil {
+		ctx.Status(200)
+		return
+	}
+	grant, err := models.GetOAuth2GrantByID(token.GrantID)
+	if err != nil {
+		ctx.ServerError("GetOAuth2GrantByID", err)
+		return
+	}
+	if grant == nil {
+		ctx.Status(200)
+		return
+	}
+
+	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", form.ClientID),
+		})
+		return
+	}
+	// public clients can not authenticate by a secret, possession of the token is enough for them
+	if app.ID != grant.ApplicationID || (form.ClientSecret != "" && !app.ValidateClientSecret([]byte(form.ClientSecret))) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
+		})
+		return
+	}
+
+	if err := models.RevokeOAuth2Grant(grant.ID, grant.UserID); err != nil {
+		ctx.ServerError("RevokeOAuth2Grant", err)
+		return
+	}
+	ctx.Status(200)
+}
+
+// isValidCodeChallenge checks the length and the characters of a code challenge,
+// see https://tools.ietf.org/html/rfc7636#section-4.1
+func isValidCodeChallenge(challenge string) bool {
+	if len(challenge) < 43 || len(challenge) > 128 {
+		return false
+	}
+	for _, c := range challenge {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.ContainsRune("-._~", c)) {
+			return false
+		}
+	}
+	return true
+}
+
 func handleAccessTokenError(ctx *context.Context, acErr AccessTokenError) {
 	ctx.JSON(400, acErr)
 }
@@ -11,7 +11,9 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/activitypub"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -82,6 +84,20 @@ func Profile(ctx *context.Context) {
 		return
 	}
 
+	if setting.Federation.Enabled {
+		ctx.Resp.Header().Add("Vary", "Accept")
+		if activitypub.IsActivityPubRequest(ctx.Req) {
+			if !ctxUser.Visibility.IsPublic() {
+				ctx.NotFound("ActivityPub", nil)
+				return
+			}
+			if err := activitypub.Write(ctx.Resp, activitypub.UserActor(ctxUser)); err != nil {
+				log.Error("Unable to write actor of %s: %v", ctxUser.Name, err)
+			}
+			return
+		}
+	}
+
 	if ctxUser.IsOrganization() {
 		org.Home(ctx)
 		return
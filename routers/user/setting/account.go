@@ -231,6 +231,10 @@ func DeleteAccount(ctx *context.Context) {
 			loadAccountData(ctx)
 
 			ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_password"), tplSettingsAccount, nil)
+		} else if models.IsErrUserLocked(err) {
+			loadAccountData(ctx)
+
+			ctx.RenderWithErr(ctx.Tr("auth.account_locked", err.(models.ErrUserLocked).LockedUntil.FormatLong()), tplSettingsAccount, nil)
 		} else {
 			ctx.ServerError("UserSignIn", err)
 		}
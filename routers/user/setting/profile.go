@@ -301,9 +301,57 @@ func Repos(ctx *context.Context) {
 
 		ctx.Data["Repos"] = repos
 	}
+
+	// the repositories pending deletion can be restored by the owners of the user and its organizations
+	orgs, err := models.GetOwnedOrgsByUserID(ctxUser.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnedOrgsByUserID", err)
+		return
+	}
+	ownerIDs := []int64{ctxUser.ID}
+	for _, org := range orgs {
+		ownerIDs = append(ownerIDs, org.ID)
+	}
+	ctx.Data["DeletedRepos"], _, err = models.FindDeletedRepositories(&models.FindDeletedRepoOptions{OwnerIDs: ownerIDs})
+	if err != nil {
+		ctx.ServerError("FindDeletedRepositories", err)
+		return
+	}
+
 	ctx.Data["Owner"] = ctxUser
 	pager := context.NewPagination(int(count), opts.PageSize, opts.Page, 5)
 	pager.SetDefaultParams(ctx)
 	ctx.Data["Page"] = pager
 	ctx.HTML(200, tplSettingsRepositories)
 }
+
+// RestoreRepository restores a repository of the user or one of its organizations pending deletion
+func RestoreRepository(ctx *context.Context) {
+	repo, err := models.GetDeletedRepositoryByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetDeletedRepositoryByID", models.IsErrRepoNotExist, err)
+		return
+	}
+	if canRestore, err := repo.CanUserDelete(ctx.User); err != nil {
+		ctx.ServerError("CanUserDelete", err)
+		return
+	} else if !canRestore {
+		ctx.NotFound("CanUserDelete", nil)
+		return
+	}
+
+	if err := models.RestoreRepository(repo); err != nil {
+		ctx.ServerError("RestoreRepository", err)
+		return
+	}
+	log.Trace("Repository restored: %s", repo.FullName())
+	ctx.Audit(&models.AuditEvent{
+		Action:     models.AuditRepoRestore,
+		TargetType: models.AuditTargetRepository,
+		TargetID:   repo.ID,
+		TargetName: repo.FullName(),
+	})
+
+	ctx.Flash.Success(ctx.Tr("admin.repos.restore_success", repo.FullName()))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/repos")
+}
@@ -9,12 +9,16 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/session"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 const (
 	tplSettingsSecurity    base.TplName = "user/settings/security"
 	tplSettingsTwofaEnroll base.TplName = "user/settings/twofa_enroll"
+	tplSettingsSessions    base.TplName = "user/settings/sessions"
 )
 
 // Security render change user's password page and 2FA
@@ -51,6 +55,65 @@ func DeleteAccountLink(ctx *context.Context) {
 	})
 }
 
+// DeleteRememberedDevice revokes the "remember me" token of a single device
+func DeleteRememberedDevice(ctx *context.Context) {
+	if err := models.DeleteRememberToken(ctx.User.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteRememberToken: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.remembered_device_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
+
+// Sessions render the signed-in sessions of the user
+func Sessions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.sessions")
+	ctx.Data["PageIsSettingsSecurity"] = true
+
+	since := timeutil.TimeStampNow().Add(-setting.SessionConfig.Maxlifetime)
+	sessions, err := models.GetUserSessions(ctx.User.ID, since)
+	if err != nil {
+		ctx.ServerError("GetUserSessions", err)
+		return
+	}
+	ctx.Data["Sessions"] = sessions
+	ctx.Data["CurrentSessionKeyHash"] = session.TrackedKeyHash(ctx.Session)
+
+	ctx.HTML(200, tplSettingsSessions)
+}
+
+// DeleteSession signs out a single session of the user
+func DeleteSession(ctx *context.Context) {
+	if err := models.DeleteUserSession(ctx.User.ID, ctx.QueryInt64("id")); err != nil && !models.IsErrUserSessionNotExist(err) {
+		ctx.ServerError("DeleteUserSession", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.session_deletion_success"))
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security/sessions",
+	})
+}
+
+// DeleteOtherSessions signs out all sessions of the user except the current one
+func DeleteOtherSessions(ctx *context.Context) {
+	keyHash := session.TrackedKeyHash(ctx.Session)
+	if keyHash == "" {
+		keyHash = models.HashSessionKey(ctx.Session.ID())
+	}
+	if err := models.DeleteOtherUserSessions(ctx.User.ID, keyHash, ctx.GetCookie(setting.CookieRememberName)); err != nil {
+		ctx.ServerError("DeleteOtherUserSessions", err)
+		return
+	}
+	log.Trace("Other sessions of user %s revoked", ctx.User.Name)
+
+	ctx.Flash.Success(ctx.Tr("settings.sessions_deletion_success"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security/sessions")
+}
+
 func loadSecurityData(ctx *context.Context) {
 	enrolled := true
 	_, err := models.GetTwoFactorByUID(ctx.User.ID)
@@ -69,6 +132,11 @@ func loadSecurityData(ctx *context.Context) {
 			ctx.ServerError("GetU2FRegistrationsByUID", err)
 			return
 		}
+		ctx.Data["WebAuthnCredentials"], err = models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+		if err != nil {
+			ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+			return
+		}
 	}
 
 	tokens, err := models.ListAccessTokens(models.ListAccessTokensOptions{UserID: ctx.User.ID})
@@ -100,6 +168,14 @@ func loadSecurityData(ctx *context.Context) {
 	}
 	ctx.Data["AccountLinks"] = sources
 
+	rememberedDevices, err := models.GetRememberTokensByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetRememberTokensByUID", err)
+		return
+	}
+	ctx.Data["RememberedDevices"] = rememberedDevices
+	ctx.Data["RememberCookie"] = ctx.GetCookie(setting.CookieRememberName)
+
 	openid, err := models.GetUserOpenIDs(ctx.User.ID)
 	if err != nil {
 		ctx.ServerError("GetUserOpenIDs", err)
@@ -0,0 +1,127 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"errors"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth/webauthn"
+	"code.gitea.io/gitea/modules/context"
+	auth "code.gitea.io/gitea/modules/forms"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// WebAuthnRegister initializes the WebAuthn registration procedure
+func WebAuthnRegister(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.WebAuthnRegistrationForm)
+	if form.Name == "" {
+		ctx.Error(409)
+		return
+	}
+	creds, err := models.GetWebAuthnCredentialsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
+		return
+	}
+	for _, cred := range creds {
+		if cred.Name == form.Name {
+			ctx.Error(409, "Name already taken")
+			return
+		}
+	}
+	regs, err := models.GetU2FRegistrationsByUID(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetU2FRegistrationsByUID", err)
+		return
+	}
+
+	// exclude the keys already registered through U2F as well
+	existing := creds.ToCredentials()
+	for _, cred := range regs.LegacyU2FCredentials() {
+		existing = append(existing, cred)
+	}
+
+	rp, err := webauthn.NewRelyingParty()
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	options, session, err := rp.BeginRegistration(ctx.User.ID, ctx.User.Name, ctx.User.DisplayName(), existing)
+	if err != nil {
+		ctx.ServerError("BeginRegistration", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnRegistration", session); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnRegistration", err)
+		return
+	}
+	if err := ctx.Session.Set("webauthnName", form.Name); err != nil {
+		ctx.ServerError("Unable to set session key for webauthnName", err)
+		return
+	}
+	// Here we're just going to try to release the session early
+	if err := ctx.Session.Release(); err != nil {
+		// we'll tolerate errors here as they *should* get saved elsewhere
+		log.Error("Unable to save changes to the session: %v", err)
+	}
+	ctx.JSON(200, options)
+}
+
+// WebAuthnRegisterPost receives the response of the authenticator
+func WebAuthnRegisterPost(ctx *context.Context) {
+	response := web.GetForm(ctx).(*webauthn.CredentialCreationResponse)
+	session, ok := ctx.Session.Get("webauthnRegistration").(*webauthn.SessionData)
+	name, hasName := ctx.Session.Get("webauthnName").(string)
+	if !ok || !hasName || session.UserID != ctx.User.ID {
+		ctx.ServerError("WebAuthnRegisterPost", errors.New("not in WebAuthn session"))
+		return
+	}
+	_ = ctx.Session.Delete("webauthnRegistration")
+	_ = ctx.Session.Delete("webauthnName")
+
+	rp, err := webauthn.NewRelyingParty()
+	if err != nil {
+		ctx.ServerError("NewRelyingParty", err)
+		return
+	}
+	cred, err := rp.FinishRegistration(session, response)
+	if err != nil {
+		ctx.ServerError("FinishRegistration", err)
+		return
+	}
+	if _, err = models.CreateWebAuthnCredential(ctx.User, name, cred); err != nil {
+		ctx.ServerError("CreateWebAuthnCredential", err)
+		return
+	}
+	ctx.Status(200)
+}
+
+// WebAuthnDelete deletes a WebAuthn credential by id
+func WebAuthnDelete(ctx *context.Context) {
+	form := web.GetForm(ctx).(*auth.WebAuthnDeleteForm)
+	cred, err := models.GetWebAuthnCredentialByID(form.ID)
+	if err != nil {
+		if models.IsErrWebAuthnCredentialNotExist(err) {
+			ctx.Status(200)
+			return
+		}
+		ctx.ServerError("GetWebAuthnCredentialByID", err)
+		return
+	}
+	if cred.UserID != ctx.User.ID {
+		ctx.Status(401)
+		return
+	}
+	if err := models.DeleteWebAuthnCredential(cred); err != nil {
+		ctx.ServerError("DeleteWebAuthnCredential", err)
+		return
+	}
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/security",
+	})
+}
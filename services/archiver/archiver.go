@@ -6,7 +6,9 @@
 package archiver
 
 import (
-	"io"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -15,12 +17,12 @@ import (
 	"sync"
 	"time"
 
-	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
 )
 
@@ -33,9 +35,12 @@ type ArchiveRequest struct {
 	uri             string
 	repo            *git.Repository
 	refName         string
+	bundleRef       string
+	subdir          string
 	ext             string
 	archivePath     string
 	archiveType     git.ArchiveType
+	attributesKey   string
 	archiveComplete bool
 	commit          *git.Commit
 	cchan           chan struct{}
@@ -54,17 +59,27 @@ var archiveQueueMutex *sync.Mutex
 var archiveQueueStartCond *sync.Cond
 var archiveQueueReleaseCond *sync.Cond
 
-// GetArchivePath returns the path from which we can serve this archive.
+// GetArchivePath returns the path in the archive storage from which we can
+// serve this archive.
 func (aReq *ArchiveRequest) GetArchivePath() string {
 	return aReq.archivePath
 }
 
 // GetArchiveName returns the name of the caller, based on the ref used by the
-// caller to create this request.
+// caller to create this request and the requested subdirectory.
 func (aReq *ArchiveRequest) GetArchiveName() string {
+	if aReq.subdir != "" {
+		return aReq.refName + "-" + strings.ReplaceAll(aReq.subdir, "/", "-") + aReq.ext
+	}
 	return aReq.refName + aReq.ext
 }
 
+// IsBundle returns whether this request is for a git bundle rather than an
+// archive of the tree.
+func (aReq *ArchiveRequest) IsBundle() bool {
+	return aReq.archiveType == git.BUNDLE
+}
+
 // IsComplete returns the completion status of this request.
 func (aReq *ArchiveRequest) IsComplete() bool {
 	return aReq.archiveComplete
@@ -99,10 +114,10 @@ func (aReq *ArchiveRequest) TimedWaitForCompletion(ctx *context.Context, dur tim
 }
 
 // The caller must hold the archiveMutex across calls to getArchiveRequest.
-func getArchiveRequest(repo *git.Repository, commit *git.Commit, archiveType git.ArchiveType) *ArchiveRequest {
+func getArchiveRequest(archivePath string) *ArchiveRequest {
 	for _, r := range archiveInProgress {
-		// Need to be referring to the same repository.
-		if r.repo.Path == repo.Path && r.commit.ID == commit.ID && r.archiveType == archiveType {
+		// The archive path identifies the repository, commit, format and content.
+		if r.archivePath == archivePath {
 			return r
 		}
 	}
@@ -113,7 +128,7 @@ func getArchiveRequest(repo *git.Repository, commit *git.Commit, archiveType git
 // resulting ArchiveRequest is suitable for being passed to ArchiveRepository()
 // if it's determined that the request still needs to be satisfied.
 func DeriveRequestFrom(ctx *context.Context, uri string) *ArchiveRequest {
-	if ctx.Repo == nil || ctx.Repo.GitRepo == nil {
+	if ctx.Repo == nil || ctx.Repo.Repository == nil || ctx.Repo.GitRepo == nil {
 		log.Trace("Repo not initialized")
 		return nil
 	}
@@ -125,44 +140,49 @@ func DeriveRequestFrom(ctx *context.Context, uri string) *ArchiveRequest {
 	switch {
 	case strings.HasSuffix(uri, ".zip"):
 		r.ext = ".zip"
-		r.archivePath = path.Join(r.repo.Path, "archives/zip")
 		r.archiveType = git.ZIP
 	case strings.HasSuffix(uri, ".tar.gz"):
 		r.ext = ".tar.gz"
-		r.archivePath = path.Join(r.repo.Path, "archives/targz")
 		r.archiveType = git.TARGZ
+	case strings.HasSuffix(uri, ".bundle"):
+		r.ext = ".bundle"
+		r.archiveType = git.BUNDLE
 	default:
 		log.Trace("Unknown format: %s", uri)
 		return nil
 	}
 
 	r.refName = strings.TrimSuffix(r.uri, r.ext)
-	isDir, err := util.IsDir(r.archivePath)
-	if err != nil {
-		ctx.ServerError("Download -> util.IsDir(archivePath)", err)
-		return nil
-	}
-	if !isDir {
-		if err := os.MkdirAll(r.archivePath, os.ModePerm); err != nil {
-			ctx.ServerError("Download -> os.MkdirAll(archivePath)", err)
+
+	if subdir := ctx.Query("subdir"); subdir != "" {
+		// A bundle carries history rather than a tree, so it can't be limited
+		// to a subdirectory.
+		if r.archiveType == git.BUNDLE {
+			ctx.NotFound("DeriveRequestFrom", nil)
 			return nil
 		}
+		r.subdir = strings.Trim(path.Clean("/"+subdir), "/")
 	}
 
 	// Get corresponding commit.
+	var err error
 	if r.repo.IsBranchExist(r.refName) {
 		r.commit, err = r.repo.GetBranchCommit(r.refName)
 		if err != nil {
 			ctx.ServerError("GetBranchCommit", err)
 			return nil
 		}
+		r.bundleRef = git.BranchPrefix + r.refName
 	} else if r.repo.IsTagExist(r.refName) {
 		r.commit, err = r.repo.GetTagCommit(r.refName)
 		if err != nil {
 			ctx.ServerError("GetTagCommit", err)
 			return nil
 		}
-	} else if shaRegex.MatchString(r.refName) {
+		r.bundleRef = git.TagPrefix + r.refName
+	} else if r.archiveType != git.BUNDLE && shaRegex.MatchString(r.refName) {
+		// A bundle needs a ref to be cloned from, so bare commits are only
+		// available as tree archives.
 		r.commit, err = r.repo.GetCommit(r.refName)
 		if err != nil {
 			ctx.NotFound("GetCommit", nil)
@@ -173,28 +193,86 @@ func DeriveRequestFrom(ctx *context.Context, uri string) *ArchiveRequest {
 		return nil
 	}
 
+	if r.subdir != "" {
+		entry, err := r.commit.GetTreeEntryByPath(r.subdir)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				ctx.NotFound("GetTreeEntryByPath", nil)
+			} else {
+				ctx.ServerError("GetTreeEntryByPath", err)
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			ctx.NotFound("GetTreeEntryByPath", nil)
+			return nil
+		}
+	}
+
+	if r.archiveType == git.BUNDLE {
+		// Attributes do not affect bundles, but the ref recorded in them
+		// does: a branch and a tag pointing at the same commit produce
+		// different bundles, so the ref takes the place of the attributes
+		// in the cache key.
+		sum := sha1.Sum([]byte(r.bundleRef))
+		r.attributesKey = hex.EncodeToString(sum[:])[:10]
+	} else {
+		// The repository-local attributes can change export-ignore/export-subst
+		// without a new commit, so they need to be part of the cache key.
+		r.attributesKey, err = r.repo.ArchiveAttributesKey()
+		if err != nil {
+			ctx.ServerError("ArchiveAttributesKey", err)
+			return nil
+		}
+	}
+
+	// Archives are cached in storage keyed by repository, commit, format and
+	// subdirectory.
+	archiveName := r.commit.ID.String()
+	if r.attributesKey != "" {
+		archiveName += "-" + r.attributesKey
+	}
+	if r.subdir != "" {
+		sum := sha1.Sum([]byte(r.subdir))
+		archiveName += "-" + hex.EncodeToString(sum[:])[:10]
+	}
+	r.archivePath = fmt.Sprintf("%d/%s%s", ctx.Repo.Repository.ID, archiveName, r.ext)
+
 	archiveMutex.Lock()
 	defer archiveMutex.Unlock()
-	if rExisting := getArchiveRequest(r.repo, r.commit, r.archiveType); rExisting != nil {
+	if rExisting := getArchiveRequest(r.archivePath); rExisting != nil {
 		return rExisting
 	}
 
-	r.archivePath = path.Join(r.archivePath, base.ShortSha(r.commit.ID.String())+r.ext)
-	r.archiveComplete, err = util.IsFile(r.archivePath)
+	r.archiveComplete, err = isArchiveCached(r.archivePath)
 	if err != nil {
-		ctx.ServerError("util.IsFile", err)
+		ctx.ServerError("isArchiveCached", err)
 		return nil
 	}
 	return r
 }
 
-func doArchive(r *ArchiveRequest) {
-	var (
-		err         error
-		tmpArchive  *os.File
-		destArchive *os.File
-	)
+// isArchiveCached returns whether a fresh copy of the archive is available in
+// the storage. Archives older than the cache TTL are treated as missing so they
+// get regenerated.
+func isArchiveCached(archivePath string) (bool, error) {
+	if !setting.RepoArchive.EnableCache {
+		return false, nil
+	}
+	fi, err := storage.RepoArchives.Stat(archivePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if setting.RepoArchive.CacheTTL > 0 && time.Since(fi.ModTime()) > setting.RepoArchive.CacheTTL {
+		return false, nil
+	}
+	return true, nil
+}
 
+func doArchive(r *ArchiveRequest) {
 	// Close the channel to indicate to potential waiters that this request
 	// has finished.
 	defer close(r.cchan)
@@ -203,45 +281,37 @@ func doArchive(r *ArchiveRequest) {
 	// race conditions and difficulties in locking.  Do one last check that
 	// the archive we're referring to doesn't already exist.  If it does exist,
 	// then just mark the request as complete and move on.
-	isFile, err := util.IsFile(r.archivePath)
+	isCached, err := isArchiveCached(r.archivePath)
 	if err != nil {
-		log.Error("Unable to check if %s util.IsFile: %v. Will ignore and recreate.", r.archivePath, err)
+		log.Error("Unable to check if %s is cached: %v. Will ignore and recreate.", r.archivePath, err)
 	}
-	if isFile {
+	if isCached {
 		r.archiveComplete = true
 		return
 	}
 
-	// Create a temporary file to use while the archive is being built.  We
-	// will then copy it into place (r.archivePath) once it's fully
-	// constructed.
-	tmpArchive, err = ioutil.TempFile("", "archive")
+	// Build the archive in a temporary file, then copy it into the storage
+	// (at r.archivePath) once it's fully constructed.
+	tmpPath, err := CreateTemporaryArchive(r)
 	if err != nil {
-		log.Error("Unable to create a temporary archive file! Error: %v", err)
+		log.Error("Download -> CreateArchive: %v", err)
 		return
 	}
 	defer func() {
-		tmpArchive.Close()
-		os.Remove(tmpArchive.Name())
+		if err := util.Remove(tmpPath); err != nil {
+			log.Warn("Unable to remove temporary archive %s: %v", tmpPath, err)
+		}
 	}()
 
-	if err = r.commit.CreateArchive(graceful.GetManager().ShutdownContext(), tmpArchive.Name(), git.CreateArchiveOpts{
-		Format: r.archiveType,
-		Prefix: setting.Repository.PrefixArchiveFiles,
-	}); err != nil {
-		log.Error("Download -> CreateArchive "+tmpArchive.Name(), err)
+	tmpArchive, err := os.Open(tmpPath)
+	if err != nil {
+		log.Error("Unable to open temporary archive %s: %v", tmpPath, err)
 		return
 	}
+	defer tmpArchive.Close()
 
-	// Now we copy it into place
-	if destArchive, err = os.Create(r.archivePath); err != nil {
-		log.Error("Unable to open archive " + r.archivePath)
-		return
-	}
-	_, err = io.Copy(destArchive, tmpArchive)
-	destArchive.Close()
-	if err != nil {
-		log.Error("Unable to write archive " + r.archivePath)
+	if _, err = storage.RepoArchives.Save(r.archivePath, tmpArchive); err != nil {
+		log.Error("Unable to store archive %s: %v", r.archivePath, err)
 		return
 	}
 
@@ -249,6 +319,32 @@ func doArchive(r *ArchiveRequest) {
 	r.archiveComplete = true
 }
 
+// CreateTemporaryArchive generates the requested archive into a new temporary
+// file and returns its path. The caller is responsible for removing it. This is
+// used directly when the archive cache is disabled.
+func CreateTemporaryArchive(r *ArchiveRequest) (string, error) {
+	tmpArchive, err := ioutil.TempFile("", "archive")
+	if err != nil {
+		return "", fmt.Errorf("unable to create a temporary archive file: %v", err)
+	}
+	tmpArchive.Close()
+
+	if r.archiveType == git.BUNDLE {
+		err = r.repo.CreateBundle(graceful.GetManager().ShutdownContext(), tmpArchive.Name(), r.bundleRef)
+	} else {
+		err = r.commit.CreateArchive(graceful.GetManager().ShutdownContext(), tmpArchive.Name(), git.CreateArchiveOpts{
+			Format: r.archiveType,
+			Prefix: setting.Repository.PrefixArchiveFiles,
+			Subdir: r.subdir,
+		})
+	}
+	if err != nil {
+		_ = util.Remove(tmpArchive.Name())
+		return "", err
+	}
+	return tmpArchive.Name(), nil
+}
+
 // ArchiveRepository satisfies the ArchiveRequest being passed in.  Processing
 // will occur in a separate goroutine, as this phase may take a while to
 // complete.  If the archive already exists, ArchiveRepository will not do
@@ -261,7 +357,7 @@ func ArchiveRepository(request *ArchiveRequest) *ArchiveRequest {
 	// and it is not marked complete.
 	archiveMutex.Lock()
 	defer archiveMutex.Unlock()
-	if rExisting := getArchiveRequest(request.repo, request.commit, request.archiveType); rExisting != nil {
+	if rExisting := getArchiveRequest(request.archivePath); rExisting != nil {
 		return rExisting
 	}
 	if request.archiveComplete {
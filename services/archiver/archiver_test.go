@@ -5,12 +5,20 @@
 package archiver
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/test"
 	"code.gitea.io/gitea/modules/util"
 
@@ -144,9 +152,8 @@ func TestArchive_Basic(t *testing.T) {
 
 	for _, req := range inFlight {
 		assert.True(t, req.IsComplete())
-		exist, err := util.IsExist(req.GetArchivePath())
+		_, err := storage.RepoArchives.Stat(req.GetArchivePath())
 		assert.NoError(t, err)
-		assert.True(t, exist)
 	}
 
 	arbitraryReq := inFlight[0]
@@ -221,3 +228,119 @@ func TestArchive_Basic(t *testing.T) {
 	assert.NotEqual(t, zipReq.GetArchiveName(), tgzReq.GetArchiveName())
 	assert.NotEqual(t, zipReq.GetArchiveName(), secondReq.GetArchiveName())
 }
+
+func TestArchive_Bundle(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "user27/repo49")
+	test.LoadRepo(t, ctx, 49)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	// Bundles need a ref, so bare commits are rejected.
+	bogusReq := DeriveRequestFrom(ctx, "aacbdfe9e1c4.bundle")
+	assert.Nil(t, bogusReq)
+
+	bundleReq := DeriveRequestFrom(ctx, "master.bundle")
+	assert.NotNil(t, bundleReq)
+	assert.True(t, bundleReq.IsBundle())
+	assert.Equal(t, "master.bundle", bundleReq.GetArchiveName())
+
+	zipReq := DeriveRequestFrom(ctx, "master.zip")
+	assert.NotNil(t, zipReq)
+	assert.False(t, zipReq.IsBundle())
+
+	bundleReq = ArchiveRepository(bundleReq)
+	if !bundleReq.IsComplete() {
+		assert.True(t, bundleReq.WaitForCompletion(ctx))
+	}
+
+	fr, err := storage.RepoArchives.Open(bundleReq.GetArchivePath())
+	assert.NoError(t, err)
+	defer fr.Close()
+	content, err := ioutil.ReadAll(fr)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "# v2 git bundle\n"))
+	assert.Contains(t, string(content), "aacbdfe9e1c4b47f60abe81849045fa4e96f1d75 refs/heads/master\n")
+}
+
+func TestArchive_Cache(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "user27/repo49")
+	test.LoadRepo(t, ctx, 49)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	zipReq := ArchiveRepository(DeriveRequestFrom(ctx, "master.zip"))
+	if !zipReq.IsComplete() {
+		assert.True(t, zipReq.WaitForCompletion(ctx))
+	}
+	assert.True(t, DeriveRequestFrom(ctx, "master.zip").IsComplete())
+
+	defer func(ttl time.Duration) { setting.RepoArchive.CacheTTL = ttl }(setting.RepoArchive.CacheTTL)
+	setting.RepoArchive.CacheTTL = time.Nanosecond
+	assert.False(t, DeriveRequestFrom(ctx, "master.zip").IsComplete())
+	setting.RepoArchive.CacheTTL = 0
+	assert.True(t, DeriveRequestFrom(ctx, "master.zip").IsComplete())
+
+	defer func(enabled bool) { setting.RepoArchive.EnableCache = enabled }(setting.RepoArchive.EnableCache)
+	setting.RepoArchive.EnableCache = false
+	assert.False(t, DeriveRequestFrom(ctx, "master.zip").IsComplete())
+
+	tmpPath, err := CreateTemporaryArchive(DeriveRequestFrom(ctx, "master.zip"))
+	assert.NoError(t, err)
+	assert.NoError(t, util.Remove(tmpPath))
+}
+
+func TestArchive_Subdir(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	ctx := test.MockContext(t, "user27/repo49")
+	test.LoadRepo(t, ctx, 49)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	// Missing directories, files and bundles can't be archived.
+	for _, uri := range []string{"master.zip", "master.bundle"} {
+		ctx.Req.Form.Set("subdir", "missing")
+		assert.Nil(t, DeriveRequestFrom(ctx, uri))
+	}
+	ctx.Req.Form.Set("subdir", "README.md")
+	assert.Nil(t, DeriveRequestFrom(ctx, "master.zip"))
+	ctx.Req.Form.Set("subdir", "test")
+	assert.Nil(t, DeriveRequestFrom(ctx, "master.bundle"))
+
+	ctx.Req.Form.Set("subdir", "/test/")
+	subdirReq := DeriveRequestFrom(ctx, "master.tar.gz")
+	assert.NotNil(t, subdirReq)
+	assert.Equal(t, "master-test.tar.gz", subdirReq.GetArchiveName())
+
+	ctx.Req.Form.Del("subdir")
+	fullReq := DeriveRequestFrom(ctx, "master.tar.gz")
+	assert.NotNil(t, fullReq)
+	assert.NotEqual(t, fullReq.GetArchivePath(), subdirReq.GetArchivePath())
+
+	tmpPath, err := CreateTemporaryArchive(subdirReq)
+	assert.NoError(t, err)
+	defer util.Remove(tmpPath)
+
+	f, err := os.Open(tmpPath)
+	assert.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if hdr.Typeflag == tar.TypeReg {
+			names = append(names, hdr.Name)
+		}
+	}
+	assert.Equal(t, []string{"repo49/test/test.txt"}, names)
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package automerge
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/services/mergequeue"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// The reasons for which the automatic merge of a pull request is canceled, the locale keys of the
+// comments about it are suffixed with them
+const (
+	CancelReasonChecksFailed = "checks_failed"
+	CancelReasonOutOfDate    = "out_of_date"
+	CancelReasonConflict     = "conflict"
+	CancelReasonNotAllowed   = "not_allowed"
+	CancelReasonMergeFailed  = "merge_failed"
+)
+
+// prAutoMergeQueue represents a queue of pull requests scheduled to be merged which need to be checked
+var prAutoMergeQueue queue.UniqueQueue
+
+// Init runs the queue merging the scheduled pull requests and registers the notifier adding them to it
+func Init() error {
+	prAutoMergeQueue = queue.CreateUniqueQueue("pr_auto_merge", handle, "").(queue.UniqueQueue)
+
+	if prAutoMergeQueue == nil {
+		return fmt.Errorf("Unable to create pr_auto_merge Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(prAutoMergeQueue.Run)
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+// addToQueue adds a pull request to the queue to be checked and merged if it is ready
+func addToQueue(pullID int64) {
+	if err := prAutoMergeQueue.PushFunc(strconv.FormatInt(pullID, 10), func() error {
+		log.Trace("Adding PR ID: %d to the pull requests auto merge queue", pullID)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding prID %d to the pull requests auto merge queue: %v", pullID, err)
+	}
+}
+
+// addScheduledToQueue adds the pull requests into the repository which are scheduled to be merged to
+// the queue, limited to the pull requests into baseBranch if it is not empty
+func addScheduledToQueue(baseRepoID int64, baseBranch string) {
+	pullIDs, err := models.GetScheduledMergePullIDs(baseRepoID, baseBranch)
+	if err != nil {
+		log.Error("GetScheduledMergePullIDs[%d]: %v", baseRepoID, err)
+		return
+	}
+	for _, pullID := range pullIDs {
+		addToQueue(pullID)
+	}
+}
+
+// ScheduleAutoMerge schedules the pull request to be merged by doer as soon as all required status
+// checks and reviews pass
+func ScheduleAutoMerge(doer *models.User, pr *models.PullRequest, style models.MergeStyle, message string) error {
+	if _, err := models.ScheduleAutoMerge(doer, pr, style, message); err != nil {
+		return err
+	}
+	// the pull request may be ready already
+	addToQueue(pr.ID)
+	return nil
+}
+
+// RemoveScheduledAutoMerge cancels the automatic merge of the pull request on behalf of doer
+func RemoveScheduledAutoMerge(doer *models.User, pr *models.PullRequest) error {
+	_, err := models.UnscheduleAutoMerge(doer, pr, "")
+	return err
+}
+
+// cancel cancels the automatic merge of the pull request for the given reason and notifies the user
+// who scheduled it
+func cancel(pr *models.PullRequest, scheduled *models.PullAutoMerge, reason string) {
+	log.Trace("Canceling auto merge of PR ID %d: %s", pr.ID, reason)
+	comment, err := models.UnscheduleAutoMerge(scheduled.Doer, pr, reason)
+	if err != nil {
+		log.Error("UnscheduleAutoMerge[%d]: %v", pr.ID, err)
+		return
+	} else if comment == nil {
+		return
+	}
+	if err := models.CreateOrUpdateIssueNotifications(pr.IssueID, comment.ID, 0, scheduled.DoerID); err != nil {
+		log.Error("CreateOrUpdateIssueNotifications[%d]: %v", pr.IssueID, err)
+	}
+}
+
+// handle checks the pull requests of the queue and merges those which are ready
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		id, _ := strconv.ParseInt(datum.(string), 10, 64)
+
+		log.Trace("Checking PR ID %d from the pull requests auto merge queue", id)
+		handlePull(id)
+	}
+}
+
+func handlePull(pullID int64) {
+	exist, scheduled, err := models.GetScheduledMergeByPullID(pullID)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			// the user who scheduled the merge has been deleted
+			if err := models.DeleteScheduledAutoMerge(pullID); err != nil {
+				log.Error("DeleteScheduledAutoMerge[%d]: %v", pullID, err)
+			}
+			return
+		}
+		log.Error("GetScheduledMergeByPullID[%d]: %v", pullID, err)
+		return
+	} else if !exist {
+		return
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		log.Error("GetPullRequestByID[%d]: %v", pullID, err)
+		return
+	}
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue[%d]: %v", pullID, err)
+		return
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		if err := models.DeleteScheduledAutoMerge(pullID); err != nil {
+			log.Error("DeleteScheduledAutoMerge[%d]: %v", pullID, err)
+		}
+		return
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pullID, err)
+		return
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, scheduled.Doer)
+	if err != nil {
+		log.Error("GetUserRepoPermission[%d]: %v", pullID, err)
+		return
+	}
+	if allowed, err := pull_service.IsUserAllowedToMerge(pr, perm, scheduled.Doer); err != nil {
+		log.Error("IsUserAllowedToMerge[%d]: %v", pullID, err)
+		return
+	} else if !allowed {
+		cancel(pr, scheduled, CancelReasonNotAllowed)
+		return
+	}
+
+	if pr.Status == models.PullRequestStatusChecking {
+		// the patch checker has not caught up with the latest push yet
+		if err := pull_service.TestPatch(pr); err != nil {
+			log.Error("TestPatch[%d]: %v", pullID, err)
+			return
+		}
+	}
+	if pr.Status == models.PullRequestStatusConflict {
+		cancel(pr, scheduled, CancelReasonConflict)
+		return
+	}
+
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableStatusCheck {
+		state, err := pull_service.GetPullRequestCommitStatusState(pr)
+		if err != nil {
+			log.Error("GetPullRequestCommitStatusState[%d]: %v", pullID, err)
+			return
+		}
+		if state.IsFailure() || state.IsError() {
+			cancel(pr, scheduled, CancelReasonChecksFailed)
+			return
+		} else if !state.IsSuccess() {
+			return
+		}
+	}
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.BlockOnOutdatedBranch {
+		diff, err := pull_service.GetDiverging(pr)
+		if err != nil {
+			log.Error("GetDiverging[%d]: %v", pullID, err)
+			return
+		}
+		if diff.Behind > 0 {
+			cancel(pr, scheduled, CancelReasonOutOfDate)
+			return
+		}
+		pr.CommitsBehind = diff.Behind
+	}
+
+	// wait for the remaining reviews and for the pull request to be marked as ready
+	if pr.IsWorkInProgress() {
+		return
+	}
+	if err := pull_service.CheckPRReadyToMerge(pr, false); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			log.Error("CheckPRReadyToMerge[%d]: %v", pullID, err)
+		}
+		return
+	}
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil {
+		log.Error("IssueNoDependenciesLeft[%d]: %v", pullID, err)
+		return
+	} else if !noDeps {
+		return
+	}
+
+	// the merge queue of the base branch takes over to serialize the merge with the other pull requests
+	if isQueueEnabled, err := mergequeue.IsEnabled(pr); err != nil {
+		log.Error("IsMergeQueueEnabled[%d]: %v", pullID, err)
+		return
+	} else if isQueueEnabled {
+		if err := mergequeue.Add(scheduled.Doer, pr, scheduled.MergeStyle, scheduled.Message); err != nil && !models.IsErrPullAlreadyInMergeQueue(err) {
+			log.Error("AddToMergeQueue[%d]: %v", pullID, err)
+			return
+		}
+		if err := models.DeleteScheduledAutoMerge(pullID); err != nil {
+			log.Error("DeleteScheduledAutoMerge[%d]: %v", pullID, err)
+		}
+		return
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%d]: %v", pullID, err)
+		return
+	}
+	defer baseGitRepo.Close()
+
+	message := scheduled.Message
+	if message == "" {
+		if scheduled.MergeStyle == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		} else {
+			message = pr.GetDefaultMergeMessage()
+		}
+	}
+	if err := pull_service.Merge(pr, scheduled.Doer, baseGitRepo, scheduled.MergeStyle, message); err != nil {
+		log.Error("Merge[%d]: %v", pullID, err)
+		cancel(pr, scheduled, CancelReasonMergeFailed)
+		return
+	}
+	if err := models.DeleteScheduledAutoMerge(pullID); err != nil {
+		log.Error("DeleteScheduledAutoMerge[%d]: %v", pullID, err)
+	}
+	log.Trace("Pull request auto merged: %d", pullID)
+}
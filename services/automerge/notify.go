@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package automerge
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+type autoMergeNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &autoMergeNotifier{}
+)
+
+// NewNotifier create a new autoMergeNotifier notifier, which checks the pull requests scheduled to be
+// merged on the events which can make them ready or block them
+func NewNotifier() base.Notifier {
+	return &autoMergeNotifier{}
+}
+
+// NotifyCreateCommitStatus checks the scheduled pull requests into the repository of the status,
+// which is where the statuses of the head commits of pull requests are looked up
+func (*autoMergeNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	addScheduledToQueue(repo.ID, "")
+}
+
+func (*autoMergeNotifier) NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
+	addToQueue(pr.ID)
+}
+
+func (*autoMergeNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
+	addToQueue(pr.ID)
+}
+
+// NotifyPushCommits checks the scheduled pull requests into the pushed branch, which may be out of date
+// or conflicting now
+func (*autoMergeNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !opts.IsUpdateBranch() {
+		return
+	}
+	addScheduledToQueue(repo.ID, opts.BranchName())
+}
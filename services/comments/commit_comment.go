@@ -0,0 +1,41 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// CreateCommitComment creates a comment on a commit, on a line of one of its files when treePath is set.
+func CreateCommitComment(doer *models.User, repo *models.Repository, sha, treePath string, line int64, content string) (*models.CommitComment, error) {
+	comment := &models.CommitComment{
+		RepoID:    repo.ID,
+		CommitSHA: sha,
+		TreePath:  treePath,
+		Line:      line,
+		PosterID:  doer.ID,
+		Poster:    doer,
+		Content:   content,
+	}
+	if err := models.CreateCommitComment(comment); err != nil {
+		return nil, err
+	}
+
+	notification.NotifyCreateCommitComment(doer, repo, comment)
+
+	return comment, nil
+}
+
+// DeleteCommitComment deletes a comment on a commit
+func DeleteCommitComment(doer *models.User, repo *models.Repository, comment *models.CommitComment) error {
+	if err := models.DeleteCommitComment(comment); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteCommitComment(doer, repo, comment)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"html"
+	"html/template"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// RenderContentDiff renders the changes between two versions of the content of a comment as HTML,
+// the removed and added text is highlighted the same way as the changed words of a code diff
+func RenderContentDiff(oldContent, newContent string) template.HTML {
+	dmp := diffmatchpatch.New()
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(oldContent, newContent, true))
+
+	var buf strings.Builder
+	for _, diff := range diffs {
+		text := html.EscapeString(diff.Text)
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			buf.WriteString(`<span class="added-code">` + text + `</span>`)
+		case diffmatchpatch.DiffDelete:
+			buf.WriteString(`<span class="removed-code">` + text + `</span>`)
+		default:
+			buf.WriteString(text)
+		}
+	}
+	return template.HTML(buf.String())
+}
+
+// RestoreCommentVersion sets the content of the comment back to one of its versions,
+// which is kept as a new version like any other edit
+func RestoreCommentVersion(comment *models.Comment, doer *models.User, version *models.CommentVersion) error {
+	if comment.Content == version.Content {
+		return nil
+	}
+	oldContent := comment.Content
+	comment.Content = version.Content
+	return UpdateComment(comment, doer, oldContent)
+}
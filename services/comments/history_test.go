@@ -0,0 +1,18 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderContentDiff(t *testing.T) {
+	assert.Equal(t, template.HTML(`a <span class="removed-code">good</span><span class="added-code">&lt;b&gt;great&lt;/b&gt;</span> comment`),
+		RenderContentDiff("a good comment", "a <b>great</b> comment"))
+	assert.Equal(t, template.HTML("unchanged"), RenderContentDiff("unchanged", "unchanged"))
+}
@@ -76,6 +76,7 @@ type DiffLine struct {
 	Type        DiffLineType
 	Content     string
 	Comments    []*models.Comment
+	Annotations []*models.CommitStatusAnnotation
 	SectionInfo *DiffLineSectionInfo
 }
 
@@ -88,11 +89,24 @@ type DiffLineSectionInfo struct {
 	RightIdx      int
 	LeftHunkSize  int
 	RightHunkSize int
+	// ChunkSize is the number of lines loaded by the next expansion, zero means BlobExcerptChunkSize
+	ChunkSize int
 }
 
 // BlobExcerptChunkSize represent max lines of excerpt
 const BlobExcerptChunkSize = 20
 
+// MaxBlobExcerptChunkSize is the maximum number of lines loaded by a single progressive expansion
+const MaxBlobExcerptChunkSize = 1000
+
+// GetChunkSize returns the number of lines loaded by the next expansion of the section
+func (info *DiffLineSectionInfo) GetChunkSize() int {
+	if info.ChunkSize <= 0 {
+		return BlobExcerptChunkSize
+	}
+	return info.ChunkSize
+}
+
 // GetType returns the type of a DiffLine.
 func (d *DiffLine) GetType() int {
 	return int(d.Type)
@@ -130,6 +144,9 @@ func (d *DiffLine) GetBlobExcerptQuery() string {
 		d.SectionInfo.LeftIdx, d.SectionInfo.RightIdx,
 		d.SectionInfo.LeftHunkSize, d.SectionInfo.RightHunkSize,
 		url.QueryEscape(d.SectionInfo.Path))
+	if d.SectionInfo.ChunkSize > 0 {
+		query += fmt.Sprintf("&lines=%d", d.SectionInfo.ChunkSize)
+	}
 	return query
 }
 
@@ -140,7 +157,7 @@ func (d *DiffLine) GetExpandDirection() DiffLineExpandDirection {
 	}
 	if d.SectionInfo.LastLeftIdx <= 0 && d.SectionInfo.LastRightIdx <= 0 {
 		return DiffLineExpandUp
-	} else if d.SectionInfo.RightIdx-d.SectionInfo.LastRightIdx > BlobExcerptChunkSize && d.SectionInfo.RightHunkSize > 0 {
+	} else if d.SectionInfo.RightIdx-d.SectionInfo.LastRightIdx > d.SectionInfo.GetChunkSize() && d.SectionInfo.RightHunkSize > 0 {
 		return DiffLineExpandUpDown
 	} else if d.SectionInfo.LeftHunkSize <= 0 && d.SectionInfo.RightHunkSize <= 0 {
 		return DiffLineExpandDown
@@ -175,6 +192,8 @@ type DiffSection struct {
 	FileName string
 	Name     string
 	Lines    []*DiffLine
+	// DisableHighlight is set for sections of files too large to be highlighted
+	DisableHighlight bool
 }
 
 var (
@@ -530,7 +549,7 @@ func init() {
 
 // GetComputedInlineDiffFor computes inline diff for the given line.
 func (diffSection *DiffSection) GetComputedInlineDiffFor(diffLine *DiffLine) template.HTML {
-	if setting.Git.DisableDiffHighlight {
+	if setting.Git.DisableDiffHighlight || diffSection.DisableHighlight {
 		return template.HTML(getLineContent(diffLine.Content[1:]))
 	}
 
@@ -587,9 +606,17 @@ type DiffFile struct {
 	IsSubmodule        bool
 	Sections           []*DiffSection
 	IsIncomplete       bool
+	IsTruncated        bool
+	ShownLineCount     int
 	IsProtected        bool
 }
 
+// GetLinesQuery builds the query string to load the lines following the ones shown of a truncated file
+func (diffFile *DiffFile) GetLinesQuery() string {
+	return fmt.Sprintf("old_path=%s&path=%s&skip=%d",
+		url.QueryEscape(diffFile.OldName), url.QueryEscape(diffFile.Name), diffFile.ShownLineCount)
+}
+
 // GetType returns type of diff file.
 func (diffFile *DiffFile) GetType() int {
 	return int(diffFile.Type)
@@ -597,7 +624,7 @@ func (diffFile *DiffFile) GetType() int {
 
 // GetTailSection creates a fake DiffLineSection if the last section is not the end of the file
 func (diffFile *DiffFile) GetTailSection(gitRepo *git.Repository, leftCommitID, rightCommitID string) *DiffSection {
-	if len(diffFile.Sections) == 0 || diffFile.Type != DiffFileChange || diffFile.IsBin || diffFile.IsLFSFile {
+	if len(diffFile.Sections) == 0 || diffFile.Type != DiffFileChange || diffFile.IsBin || diffFile.IsLFSFile || diffFile.IsTruncated {
 		return nil
 	}
 	leftCommit, err := gitRepo.GetCommit(leftCommitID)
@@ -675,6 +702,35 @@ func (diff *Diff) LoadComments(issue *models.Issue, currentUser *models.User) er
 	return nil
 }
 
+// LoadAnnotations adds the annotations of checks of the commit the diff leads to
+// to the lines of the new version of the files they are about
+func (diff *Diff) LoadAnnotations(annotations []*models.CommitStatusAnnotation) {
+	if len(annotations) == 0 {
+		return
+	}
+	fileAnnotations := make(map[string]map[int64][]*models.CommitStatusAnnotation)
+	for _, annotation := range annotations {
+		if fileAnnotations[annotation.Path] == nil {
+			fileAnnotations[annotation.Path] = make(map[int64][]*models.CommitStatusAnnotation)
+		}
+		fileAnnotations[annotation.Path][annotation.Line] = append(fileAnnotations[annotation.Path][annotation.Line], annotation)
+	}
+	for _, file := range diff.Files {
+		lineAnnotations, ok := fileAnnotations[file.Name]
+		if !ok {
+			continue
+		}
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.Type == DiffLineSection || line.RightIdx <= 0 {
+					continue
+				}
+				line.Annotations = append(line.Annotations, lineAnnotations[int64(line.RightIdx)]...)
+			}
+		}
+	}
+}
+
 const cmdDiffHead = "diff --git "
 
 // ParsePatch builds a Diff object from a io.Reader and some parameters.
@@ -959,6 +1015,7 @@ func parseHunks(curFile *DiffFile, maxLines, maxLineCharacters int, input *bufio
 		case '@':
 			if curFileLinesCount >= maxLines {
 				curFile.IsIncomplete = true
+				curFile.IsTruncated = true
 				continue
 			}
 
@@ -1010,8 +1067,10 @@ func parseHunks(curFile *DiffFile, maxLines, maxLineCharacters int, input *bufio
 			curFile.Addition++
 			if curFileLinesCount >= maxLines {
 				curFile.IsIncomplete = true
+				curFile.IsTruncated = true
 				continue
 			}
+			curFile.ShownLineCount = curFileLinesCount
 			diffLine := &DiffLine{Type: DiffLineAdd, RightIdx: rightLine}
 			rightLine++
 			curSection.Lines = append(curSection.Lines, diffLine)
@@ -1020,8 +1079,10 @@ func parseHunks(curFile *DiffFile, maxLines, maxLineCharacters int, input *bufio
 			curFile.Deletion++
 			if curFileLinesCount >= maxLines {
 				curFile.IsIncomplete = true
+				curFile.IsTruncated = true
 				continue
 			}
+			curFile.ShownLineCount = curFileLinesCount
 			diffLine := &DiffLine{Type: DiffLineDel, LeftIdx: leftLine}
 			if leftLine > 0 {
 				leftLine++
@@ -1031,8 +1092,10 @@ func parseHunks(curFile *DiffFile, maxLines, maxLineCharacters int, input *bufio
 			curFileLinesCount++
 			if curFileLinesCount >= maxLines {
 				curFile.IsIncomplete = true
+				curFile.IsTruncated = true
 				continue
 			}
+			curFile.ShownLineCount = curFileLinesCount
 			diffLine := &DiffLine{Type: DiffLinePlain, LeftIdx: leftLine, RightIdx: rightLine}
 			leftLine++
 			rightLine++
@@ -1268,6 +1331,95 @@ func GetDiffCommitWithWhitespaceBehavior(repoPath, commitID string, maxLines, ma
 	return GetDiffRangeWithWhitespaceBehavior(repoPath, "", commitID, maxLines, maxLineCharacters, maxFiles, whitespaceBehavior)
 }
 
+// GetDiffFileLines builds the diff of a single file between two commits and returns
+// the file with the first skip lines of its hunks removed, so that the next maxLines
+// lines of a truncated file can be rendered after the ones already shown.
+// Passing the empty string as beforeCommitID returns a diff from the parent commit.
+func GetDiffFileLines(gitRepo *git.Repository, beforeCommitID, afterCommitID, oldPath, filePath string, skip, maxLines, maxLineCharacters int, whitespaceBehavior string) (*DiffFile, error) {
+	commit, err := gitRepo.GetCommit(afterCommitID)
+	if err != nil {
+		return nil, err
+	}
+	afterCommitID = commit.ID.String()
+
+	if len(beforeCommitID) == 0 || beforeCommitID == git.EmptySHA {
+		if commit.ParentCount() == 0 {
+			beforeCommitID = git.EmptyTreeSHA
+		} else {
+			parentCommit, err := commit.Parent(0)
+			if err != nil {
+				return nil, err
+			}
+			beforeCommitID = parentCommit.ID.String()
+		}
+	} else {
+		beforeCommit, err := gitRepo.GetCommit(beforeCommitID)
+		if err != nil {
+			return nil, err
+		}
+		beforeCommitID = beforeCommit.ID.String()
+	}
+
+	diffArgs := []string{"diff", "--src-prefix=\\a/", "--dst-prefix=\\b/", "-M"}
+	if len(whitespaceBehavior) != 0 {
+		diffArgs = append(diffArgs, whitespaceBehavior)
+	}
+	diffArgs = append(diffArgs, beforeCommitID, afterCommitID, "--", filePath)
+	if len(oldPath) != 0 && oldPath != filePath {
+		// The old path is needed for git to detect the rename
+		diffArgs = append(diffArgs, oldPath)
+	}
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+	go func() {
+		stderr := &strings.Builder{}
+		if err := git.NewCommand(diffArgs...).RunInDirPipeline(gitRepo.Path, writer, stderr); err != nil {
+			_ = writer.CloseWithError(fmt.Errorf("%v - %s", err, stderr))
+			return
+		}
+		_ = writer.Close()
+	}()
+
+	diff, err := ParsePatch(skip+maxLines, maxLineCharacters, 1, reader)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePatch: %v", err)
+	}
+	if len(diff.Files) == 0 {
+		return nil, git.ErrNotExist{ID: afterCommitID, RelPath: filePath}
+	}
+
+	diffFile := diff.Files[0]
+	diffFile.skipLines(skip)
+	if tailSection := diffFile.GetTailSection(gitRepo, beforeCommitID, afterCommitID); tailSection != nil {
+		diffFile.Sections = append(diffFile.Sections, tailSection)
+	}
+	return diffFile, nil
+}
+
+// skipLines drops the first skip hunk lines of the file together with the
+// section headers that precede them.
+func (diffFile *DiffFile) skipLines(skip int) {
+	count := 0
+	sections := make([]*DiffSection, 0, len(diffFile.Sections))
+	for _, section := range diffFile.Sections {
+		lines := make([]*DiffLine, 0, len(section.Lines))
+		for _, line := range section.Lines {
+			if line.Type != DiffLineSection {
+				count++
+			}
+			if count > skip {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			section.Lines = lines
+			sections = append(sections, section)
+		}
+	}
+	diffFile.Sections = sections
+}
+
 // CommentAsDiff returns c.Patch as *Diff
 func CommentAsDiff(c *models.Comment) (*Diff, error) {
 	diff, err := ParsePatch(setting.Git.MaxGitDiffLines,
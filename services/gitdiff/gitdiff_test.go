@@ -8,6 +8,7 @@ package gitdiff
 import (
 	"fmt"
 	"html/template"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
@@ -500,6 +501,21 @@ func TestDiff_LoadComments(t *testing.T) {
 	assert.Len(t, diff.Files[0].Sections[0].Lines[0].Comments, 2)
 }
 
+func TestDiff_LoadAnnotations(t *testing.T) {
+	diff := setupDefaultDiff()
+	diff.LoadAnnotations([]*models.CommitStatusAnnotation{
+		{Path: "README.md", Line: 4, Message: "a"},
+		{Path: "README.md", Line: 4, Message: "b"},
+		{Path: "README.md", Line: 5, Message: "c"},
+		{Path: "main.go", Line: 4, Message: "d"},
+	})
+	annotations := diff.Files[0].Sections[0].Lines[0].Annotations
+	if assert.Len(t, annotations, 2) {
+		assert.Equal(t, "a", annotations[0].Message)
+		assert.Equal(t, "b", annotations[1].Message)
+	}
+}
+
 func TestDiffLine_CanComment(t *testing.T) {
 	assert.False(t, (&DiffLine{Type: DiffLineSection}).CanComment())
 	assert.False(t, (&DiffLine{Type: DiffLineAdd, Comments: []*models.Comment{{Content: "bla"}}}).CanComment())
@@ -535,3 +551,78 @@ func TestDiffToHTML_14231(t *testing.T) {
 
 	assertEqual(t, expected, output)
 }
+
+func TestGetDiffFileLines(t *testing.T) {
+	gitRepo, err := git.OpenRepository("./testdata/academic-module")
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	const (
+		beforeCommitID = "559c156f8e0178b71cb44355428f24001b08fc68"
+		afterCommitID  = "bd7063cc7c04689c4d082183d32a604ed27a24f9"
+		filePath       = "Http/Requests/CourseModeUpdateRequest.php"
+	)
+
+	contents := func(file *DiffFile) (lines []string) {
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.Type != DiffLineSection {
+					lines = append(lines, line.Content)
+				}
+			}
+		}
+		return
+	}
+
+	full, err := GetDiffFileLines(gitRepo, beforeCommitID, afterCommitID, filePath, filePath, 0, 1000, setting.Git.MaxGitDiffLineCharacters, "")
+	assert.NoError(t, err)
+	assert.False(t, full.IsTruncated)
+	assert.Len(t, contents(full), 26)
+
+	var loaded []string
+	skip := 0
+	for i := 0; i < 3; i++ {
+		file, err := GetDiffFileLines(gitRepo, beforeCommitID, afterCommitID, filePath, filePath, skip, 10, setting.Git.MaxGitDiffLineCharacters, "")
+		assert.NoError(t, err)
+		loaded = append(loaded, contents(file)...)
+		assert.Equal(t, i < 2, file.IsTruncated)
+		assert.Equal(t, "old_path="+url.QueryEscape(filePath)+"&path="+url.QueryEscape(filePath)+"&skip="+strconv.Itoa(file.ShownLineCount), file.GetLinesQuery())
+		skip = file.ShownLineCount
+	}
+	assert.Equal(t, contents(full), loaded)
+
+	_, err = GetDiffFileLines(gitRepo, beforeCommitID, afterCommitID, "", "does/not/exist", 0, 10, setting.Git.MaxGitDiffLineCharacters, "")
+	assert.True(t, git.IsErrNotExist(err))
+}
+
+func TestDiffLine_GetBlobExcerptQuery(t *testing.T) {
+	line := &DiffLine{
+		Type: DiffLineSection,
+		SectionInfo: &DiffLineSectionInfo{
+			Path:          "a b.go",
+			LastLeftIdx:   10,
+			LastRightIdx:  10,
+			LeftIdx:       60,
+			RightIdx:      60,
+			LeftHunkSize:  3,
+			RightHunkSize: 3,
+		},
+	}
+	assert.Equal(t, "last_left=10&last_right=10&left=60&right=60&left_hunk_size=3&right_hunk_size=3&path=a+b.go", line.GetBlobExcerptQuery())
+	assert.EqualValues(t, DiffLineExpandUpDown, line.GetExpandDirection())
+
+	line.SectionInfo.ChunkSize = 80
+	assert.Equal(t, "last_left=10&last_right=10&left=60&right=60&left_hunk_size=3&right_hunk_size=3&path=a+b.go&lines=80", line.GetBlobExcerptQuery())
+	// the next expansion loads the whole gap
+	assert.EqualValues(t, DiffLineExpandSingle, line.GetExpandDirection())
+}
+
+func TestDiffSection_DisableHighlight(t *testing.T) {
+	setting.Cfg = ini.Empty()
+	line := &DiffLine{Type: DiffLinePlain, Content: " if a < b {"}
+	section := &DiffSection{FileName: "main.go", Lines: []*DiffLine{line}}
+	assert.Contains(t, string(section.GetComputedInlineDiffFor(line)), "<span")
+
+	section.DisableHighlight = true
+	assert.EqualValues(t, "if a &lt; b {", section.GetComputedInlineDiffFor(line))
+}
@@ -0,0 +1,302 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/graceful"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// The indexers which can be reindexed
+const (
+	IndexerCode   = "code"
+	IndexerIssues = "issues"
+)
+
+// Indexers lists the indexers which can be reindexed
+var Indexers = []string{IndexerCode, IndexerIssues}
+
+// progressSaveInterval is the number of repositories reindexed between two saves of the progress of a reindex
+const progressSaveInterval = 10
+
+// reindexQueue represents a queue of the ids of the reindex tasks to run
+var reindexQueue queue.UniqueQueue
+
+// ReindexProgress represents the payload of a reindex task
+type ReindexProgress struct {
+	// Total is the number of repositories to reindex
+	Total int64 `json:"total"`
+	// Done and Failed are the numbers of repositories which have been reindexed and which failed to be
+	Done   int64 `json:"done"`
+	Failed int64 `json:"failed"`
+	// LastRepoID is the id of the last repository handled, a stopped reindex of all the repositories
+	// resumes after it
+	LastRepoID int64 `json:"last_repo_id"`
+}
+
+// Init runs the queue of the reindex tasks and queues again the ones which were interrupted
+func Init() error {
+	reindexQueue = queue.CreateUniqueQueue("indexer_reindex", handle, "").(queue.UniqueQueue)
+
+	if reindexQueue == nil {
+		return fmt.Errorf("Unable to create indexer_reindex Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(reindexQueue.Run)
+
+	for _, indexer := range Indexers {
+		tasks, err := models.GetUnfinishedTasks(taskType(indexer))
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := addToQueue(task.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// IsValidIndexer returns whether the indexer exists
+func IsValidIndexer(indexer string) bool {
+	return indexer == IndexerCode || indexer == IndexerIssues
+}
+
+// IsIndexerEnabled returns whether the indexer is enabled and can be reindexed
+func IsIndexerEnabled(indexer string) bool {
+	switch indexer {
+	case IndexerCode:
+		return setting.Indexer.RepoIndexerEnabled
+	case IndexerIssues:
+		// the database indexer searches the issues table directly
+		return setting.Indexer.IssueType != "db"
+	}
+	return false
+}
+
+func taskType(indexer string) structs.TaskType {
+	if indexer == IndexerCode {
+		return structs.TaskTypeReindexCode
+	}
+	return structs.TaskTypeReindexIssues
+}
+
+// TaskIndexer returns the indexer reindexed by a reindex task
+func TaskIndexer(task *models.Task) string {
+	if task.Type == structs.TaskTypeReindexCode {
+		return IndexerCode
+	}
+	return IndexerIssues
+}
+
+// GetReindexProgress returns the progress of a reindex task
+func GetReindexProgress(task *models.Task) (*ReindexProgress, error) {
+	if task.Type != structs.TaskTypeReindexCode && task.Type != structs.TaskTypeReindexIssues {
+		return nil, fmt.Errorf("Task type is %s, not Reindex", task.Type.Name())
+	}
+	progress := new(ReindexProgress)
+	if task.PayloadContent == "" {
+		return progress, nil
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return progress, json.Unmarshal([]byte(task.PayloadContent), progress)
+}
+
+// IsReindexPending returns true if the reindex task is still to be run, is running or has been
+// interrupted and will resume
+func IsReindexPending(task *models.Task) bool {
+	return task.Status == structs.TaskStatusQueue || task.Status == structs.TaskStatusRunning ||
+		task.Status == structs.TaskStatusStopped
+}
+
+// GetLastReindex returns the most recent reindex of all the repositories of the indexer, or of
+// the repository if it is not nil
+func GetLastReindex(indexer string, repo *models.Repository) (*models.Task, error) {
+	var repoID int64
+	if repo != nil {
+		repoID = repo.ID
+	}
+	return models.GetLastRepoTask(repoID, taskType(indexer))
+}
+
+// Reindex queues a reindex of the indexer, which runs in the background while the indexer keeps
+// being updated and searched. All the repositories are reindexed if repo is nil. It returns the task
+// recording the progress, which is the pending one if there is already one for the same repositories.
+func Reindex(doer *models.User, indexer string, repo *models.Repository) (*models.Task, error) {
+	if !IsIndexerEnabled(indexer) {
+		return nil, fmt.Errorf("the %s indexer is not enabled", indexer)
+	}
+
+	task, err := GetLastReindex(indexer, repo)
+	if err == nil && IsReindexPending(task) {
+		return task, nil
+	} else if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		return nil, err
+	}
+
+	task = &models.Task{
+		DoerID: doer.ID,
+		Type:   taskType(indexer),
+		Status: structs.TaskStatusQueue,
+	}
+	if repo != nil {
+		task.OwnerID = repo.OwnerID
+		task.RepoID = repo.ID
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+	return task, addToQueue(task.ID)
+}
+
+func addToQueue(taskID int64) error {
+	if err := reindexQueue.PushFunc(strconv.FormatInt(taskID, 10), func() error {
+		log.Trace("Adding task ID: %d to the indexer reindex queue", taskID)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		return err
+	}
+	return nil
+}
+
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		id, _ := strconv.ParseInt(datum.(string), 10, 64)
+		if err := runReindex(graceful.GetManager().ShutdownContext(), id); err != nil {
+			log.Error("Reindex task %d failed: %v", id, err)
+		}
+	}
+}
+
+func reindexRepo(indexer string, repo *models.Repository) error {
+	if indexer == IndexerCode {
+		return code_indexer.ReindexRepo(repo)
+	}
+	return issue_indexer.ReindexRepoIssues(repo)
+}
+
+func saveProgress(task *models.Task, progress *ReindexProgress, cols ...string) error {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	payload, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	task.PayloadContent = string(payload)
+	return task.UpdateCols(append(cols, "payload_content")...)
+}
+
+func runReindex(ctx context.Context, taskID int64) error {
+	task, err := models.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if !IsReindexPending(task) {
+		return nil
+	}
+	progress, err := GetReindexProgress(task)
+	if err != nil {
+		return err
+	}
+	indexer := TaskIndexer(task)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pid := process.GetManager().Add(fmt.Sprintf("Reindex of the %s indexer", indexer), cancel)
+	defer process.GetManager().Remove(pid)
+
+	task.Status = structs.TaskStatusRunning
+	if task.StartTime == 0 {
+		task.StartTime = timeutil.TimeStampNow()
+	}
+	if err := task.UpdateCols("status", "start_time"); err != nil {
+		return err
+	}
+
+	if task.RepoID > 0 {
+		if err := task.LoadRepo(); err != nil {
+			return err
+		}
+		progress.Total = 1
+		err = reindexRepo(indexer, task.Repo)
+		if err == nil {
+			progress.Done = 1
+		}
+		return finishReindex(task, progress, err)
+	}
+
+	if progress.Total == 0 {
+		progress.Total = models.CountRepositories(true)
+	}
+	for {
+		repos, err := models.GetRepositoriesAfterID(progress.LastRepoID, setting.Database.IterateBufferSize)
+		if err != nil {
+			return finishReindex(task, progress, err)
+		}
+		if len(repos) == 0 {
+			break
+		}
+		for _, repo := range repos {
+			select {
+			case <-ctx.Done():
+				return stopReindex(task, progress)
+			default:
+			}
+			if err := reindexRepo(indexer, repo); err != nil {
+				log.Error("Reindex of the %s indexer failed for repository %s: %v", indexer, repo.FullName(), err)
+				progress.Failed++
+			} else {
+				progress.Done++
+			}
+			progress.LastRepoID = repo.ID
+			if (progress.Done+progress.Failed)%progressSaveInterval == 0 {
+				if err := saveProgress(task, progress); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if progress.Failed > 0 {
+		task.Errors = fmt.Sprintf("%d repositories failed to be reindexed, see the log for details", progress.Failed)
+	}
+	return finishReindex(task, progress, nil)
+}
+
+// stopReindex records an interrupted reindex, which resumes when Gitea restarts if it is shutting
+// down, or is failed if it has been cancelled
+func stopReindex(task *models.Task, progress *ReindexProgress) error {
+	if graceful.GetManager().ShutdownContext().Err() == nil {
+		return finishReindex(task, progress, fmt.Errorf("cancelled"))
+	}
+	log.Info("Reindex task %d stopped after repository %d, it will resume at the next start", task.ID, progress.LastRepoID)
+	task.Status = structs.TaskStatusStopped
+	return saveProgress(task, progress, "status")
+}
+
+func finishReindex(task *models.Task, progress *ReindexProgress, err error) error {
+	task.EndTime = timeutil.TimeStampNow()
+	if err != nil {
+		task.Status = structs.TaskStatusFailed
+		task.Errors = err.Error()
+	} else {
+		task.Status = structs.TaskStatusFinished
+	}
+	return saveProgress(task, progress, "status", "end_time", "errors")
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReindexProgress(t *testing.T) {
+	progress, err := GetReindexProgress(&models.Task{Type: structs.TaskTypeReindexCode})
+	assert.NoError(t, err)
+	assert.EqualValues(t, &ReindexProgress{}, progress)
+
+	progress, err = GetReindexProgress(&models.Task{
+		Type:           structs.TaskTypeReindexIssues,
+		PayloadContent: `{"total":10,"done":4,"failed":1,"last_repo_id":5}`,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, &ReindexProgress{Total: 10, Done: 4, Failed: 1, LastRepoID: 5}, progress)
+
+	_, err = GetReindexProgress(&models.Task{Type: structs.TaskTypeGarbageCollectRepo})
+	assert.Error(t, err)
+}
+
+func TestRunReindexCancelled(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	task := &models.Task{
+		DoerID:         1,
+		Type:           structs.TaskTypeReindexIssues,
+		Status:         structs.TaskStatusStopped,
+		PayloadContent: `{"total":10,"done":5,"failed":0,"last_repo_id":5}`,
+	}
+	assert.NoError(t, models.CreateTask(task))
+	assert.True(t, IsReindexPending(task))
+
+	unfinished, err := models.GetUnfinishedTasks(structs.TaskTypeReindexIssues)
+	assert.NoError(t, err)
+	if assert.Len(t, unfinished, 1) {
+		assert.EqualValues(t, task.ID, unfinished[0].ID)
+	}
+
+	// the stopped reindex resumes and is cancelled before reindexing any repository
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, runReindex(ctx, task.ID))
+
+	task, err = GetLastReindex(IndexerIssues, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, structs.TaskStatusFailed, task.Status)
+	assert.False(t, IsReindexPending(task))
+	assert.Greater(t, int64(task.StartTime), int64(0))
+	progress, err := GetReindexProgress(task)
+	assert.NoError(t, err)
+	assert.EqualValues(t, &ReindexProgress{Total: 10, Done: 5, LastRepoID: 5}, progress)
+
+	unfinished, err = models.GetUnfinishedTasks(structs.TaskTypeReindexIssues)
+	assert.NoError(t, err)
+	assert.Empty(t, unfinished)
+}
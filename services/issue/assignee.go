@@ -260,3 +260,74 @@ func TeamReviewRequest(issue *models.Issue, doer *models.User, reviewer *models.
 
 	return
 }
+
+// DefaultAssigneeIDs returns the IDs of the default assignees of new issues of the repository,
+// who can still be assigned to them
+func DefaultAssigneeIDs(repo *models.Repository) ([]int64, error) {
+	unit, err := repo.GetUnit(models.UnitTypeIssues)
+	if err != nil {
+		if models.IsErrUnitTypeNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	users, err := models.GetUsersByIDs(unit.IssuesConfig().DefaultAssigneeIDs)
+	if err != nil {
+		return nil, err
+	}
+	assigneeIDs := make([]int64, 0, len(users))
+	for _, user := range users {
+		if !user.IsActive || user.ProhibitLogin {
+			continue
+		}
+		valid, err := models.CanBeAssigned(user, repo, false)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			assigneeIDs = append(assigneeIDs, user.ID)
+		}
+	}
+	return assigneeIDs, nil
+}
+
+// RequestDefaultReviewers requests the reviews of the default reviewers of the repository of a new
+// pull request on behalf of its poster, skipping the poster and users who can't review it anymore.
+func RequestDefaultReviewers(issue *models.Issue) error {
+	if err := issue.LoadRepo(); err != nil {
+		return err
+	}
+	if err := issue.LoadPoster(); err != nil {
+		return err
+	}
+	unit, err := issue.Repo.GetUnit(models.UnitTypePullRequests)
+	if err != nil {
+		if models.IsErrUnitTypeNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	reviewers, err := models.GetUsersByIDs(unit.PullRequestsConfig().DefaultReviewerIDs)
+	if err != nil {
+		return err
+	}
+	for _, reviewer := range reviewers {
+		if reviewer.ID == issue.PosterID || !reviewer.IsActive || reviewer.ProhibitLogin {
+			continue
+		}
+		perm, err := models.GetUserRepoPermission(issue.Repo, reviewer)
+		if err != nil {
+			return err
+		}
+		if !perm.CanAccessAny(models.AccessModeRead, models.UnitTypePullRequests) {
+			log.Debug("Default reviewer %s can't read the pull requests of %s anymore", reviewer.Name, issue.Repo.FullName())
+			continue
+		}
+		if _, err := ReviewRequest(issue, issue.Poster, reviewer, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
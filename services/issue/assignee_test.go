@@ -35,3 +35,39 @@ func TestDeleteNotPassedAssignee(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(assignees))
 }
+
+func TestDefaultAssigneeIDs(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assigneeIDs, err := DefaultAssigneeIDs(repo)
+	assert.NoError(t, err)
+	assert.Empty(t, assigneeIDs)
+
+	unit, err := repo.GetUnit(models.UnitTypeIssues)
+	assert.NoError(t, err)
+	// user4 can't be assigned to issues of repo1, user 1000 doesn't exist
+	unit.IssuesConfig().DefaultAssigneeIDs = []int64{2, 4, 1000}
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{*unit}, nil))
+
+	repo = models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assigneeIDs, err = DefaultAssigneeIDs(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, assigneeIDs)
+}
+
+func TestRequestDefaultReviewers(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	unit, err := repo.GetUnit(models.UnitTypePullRequests)
+	assert.NoError(t, err)
+	// user1 is the poster of the pull request
+	unit.PullRequestsConfig().DefaultReviewerIDs = []int64{1, 2}
+	assert.NoError(t, models.UpdateRepositoryUnits(repo, []models.RepoUnit{*unit}, nil))
+
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{ID: 2}).(*models.Issue)
+	assert.NoError(t, RequestDefaultReviewers(issue))
+	models.AssertExistsAndLoadBean(t, &models.Review{IssueID: 2, ReviewerID: 2, Type: models.ReviewTypeRequest})
+	models.AssertNotExistsBean(t, &models.Review{IssueID: 2, ReviewerID: 1, Type: models.ReviewTypeRequest})
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ChangeDeadline changes the due date of the issue, a zero deadline removes it.
+func ChangeDeadline(issue *models.Issue, doer *models.User, deadlineUnix timeutil.TimeStamp) error {
+	oldDeadlineUnix := issue.DeadlineUnix
+	comment, err := models.UpdateIssueDeadline(issue, deadlineUnix, doer)
+	if err != nil {
+		return err
+	}
+	if comment != nil {
+		notification.NotifyIssueChangeDeadline(doer, issue, oldDeadlineUnix, comment)
+	}
+	return nil
+}
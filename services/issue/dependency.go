@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// AddDependency makes the issue blocked by the dependency, which can be in another repository
+func AddDependency(doer *models.User, issue, dependency *models.Issue) error {
+	if err := models.CreateIssueDependency(doer, issue, dependency); err != nil {
+		return err
+	}
+
+	notification.NotifyIssueChangeDependency(doer, issue, dependency, false)
+	return nil
+}
+
+// RemoveDependency removes the dependency of the given type between the issue and the other one
+func RemoveDependency(doer *models.User, issue, dependency *models.Issue, depType models.DependencyType) error {
+	if err := models.RemoveIssueDependency(doer, issue, dependency, depType); err != nil {
+		return err
+	}
+
+	blocked, blocking := issue, dependency
+	if depType == models.DependencyTypeBlocking {
+		blocked, blocking = dependency, issue
+	}
+	notification.NotifyIssueChangeDependency(doer, blocked, blocking, true)
+	return nil
+}
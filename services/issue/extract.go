@@ -0,0 +1,57 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+)
+
+// ExtractComment creates a new issue from the content of the comment, with a link back to it, in the repository
+// of the comment and records the new issue on the comment. The labels of the issue of the comment are copied
+// to the new issue if inheritLabels is true.
+func ExtractComment(doer *models.User, comment *models.Comment, title string, inheritLabels bool) (*models.Issue, error) {
+	if err := comment.LoadIssue(); err != nil {
+		return nil, err
+	}
+	source := comment.Issue
+	if err := source.LoadRepo(); err != nil {
+		return nil, err
+	}
+
+	var labelIDs []int64
+	if inheritLabels {
+		if err := source.LoadLabels(); err != nil {
+			return nil, err
+		}
+		labelIDs = make([]int64, 0, len(source.Labels))
+		for _, label := range source.Labels {
+			labelIDs = append(labelIDs, label.ID)
+		}
+	}
+
+	assigneeIDs, err := DefaultAssigneeIDs(source.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	issue := &models.Issue{
+		RepoID:   source.RepoID,
+		Repo:     source.Repo,
+		Title:    title,
+		PosterID: doer.ID,
+		Poster:   doer,
+		Content:  fmt.Sprintf("%s\n\n_Extracted from %s in #%d_", comment.Content, comment.HTMLURL(), source.Index),
+	}
+	if err := NewIssue(source.Repo, issue, labelIDs, nil, assigneeIDs); err != nil {
+		return nil, err
+	}
+
+	if err := comment.SetExtractedIssue(issue); err != nil {
+		return nil, err
+	}
+	return issue, nil
+}
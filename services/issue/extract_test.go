@@ -0,0 +1,34 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractComment(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	comment := models.AssertExistsAndLoadBean(t, &models.Comment{ID: 2}).(*models.Comment)
+
+	issue, err := ExtractComment(doer, comment, "follow up", true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, issue.RepoID)
+	assert.Equal(t, "follow up", issue.Title)
+	assert.Contains(t, issue.Content, "good work!")
+	assert.Contains(t, issue.Content, "#1")
+	assert.Contains(t, issue.Content, comment.HTMLURL())
+	models.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: issue.ID, LabelID: 1})
+	models.AssertExistsAndLoadBean(t, &models.Comment{ID: 2, ExtractedIssueID: issue.ID})
+
+	comment = models.AssertExistsAndLoadBean(t, &models.Comment{ID: 3}).(*models.Comment)
+	issue, err = ExtractComment(doer, comment, "without labels", false)
+	assert.NoError(t, err)
+	models.AssertNotExistsBean(t, &models.IssueLabel{IssueID: issue.ID})
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// maxSearchHits is the maximum number of issues matching the keyword of a search
+const maxSearchHits = 500
+
+// SearchOptions represents the options of a search for issues and pull requests
+// across the repositories that a user has access to
+type SearchOptions struct {
+	models.ListOptions
+	Keyword            string
+	IsPull             util.OptionalBool
+	IsClosed           util.OptionalBool
+	IncludedLabelNames []string
+	PosterID           int64
+	AssigneeID         int64
+	MentionedID        int64
+	ReviewRequestedID  int64
+	UpdatedAfterUnix   int64
+	UpdatedBeforeUnix  int64
+	// SortType is "relevance" or one of the sort types of the issue lists,
+	// the relevance only applying to searches with a keyword
+	SortType       string
+	PriorityRepoID int64
+}
+
+// SearchIssues searches for issues and pull requests in the repositories where the doer,
+// who is anonymous if nil, can read them, returning a page of them and their total number.
+func SearchIssues(doer *models.User, opts *SearchOptions) ([]*models.Issue, int64, error) {
+	cond := repoCond(doer, opts.IsPull)
+
+	var issueIDs []int64
+	if len(opts.Keyword) > 0 {
+		// the issue indexer filters its results by the ids of the repositories
+		repoIDs, err := models.FindRepoIDsByCond(readableRepoCond(doer, opts.IsPull))
+		if err != nil {
+			return nil, 0, fmt.Errorf("FindRepoIDsByCond: %v", err)
+		}
+		if len(repoIDs) == 0 {
+			return []*models.Issue{}, 0, nil
+		}
+		if issueIDs, err = issue_indexer.SearchIssuesByKeywordWithLimit(repoIDs, opts.Keyword, maxSearchHits); err != nil {
+			return nil, 0, fmt.Errorf("SearchIssuesByKeyword: %v", err)
+		}
+		if len(issueIDs) == 0 {
+			return []*models.Issue{}, 0, nil
+		}
+	}
+
+	sortType := opts.SortType
+	if sortType == "relevance" && len(issueIDs) == 0 {
+		sortType = "recentupdate"
+	}
+	issuesOpts := &models.IssuesOptions{
+		ListOptions:        opts.ListOptions,
+		RepoCond:           cond,
+		IssueIDs:           issueIDs,
+		IsPull:             opts.IsPull,
+		IsClosed:           opts.IsClosed,
+		IncludedLabelNames: opts.IncludedLabelNames,
+		PosterID:           opts.PosterID,
+		AssigneeID:         opts.AssigneeID,
+		MentionedID:        opts.MentionedID,
+		ReviewRequestedID:  opts.ReviewRequestedID,
+		UpdatedAfterUnix:   opts.UpdatedAfterUnix,
+		UpdatedBeforeUnix:  opts.UpdatedBeforeUnix,
+		SortType:           sortType,
+		PriorityRepoID:     opts.PriorityRepoID,
+	}
+	issues, err := models.Issues(issuesOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Issues: %v", err)
+	}
+
+	issuesOpts.ListOptions = models.ListOptions{
+		Page: -1,
+	}
+	count, err := models.CountIssues(issuesOpts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("CountIssues: %v", err)
+	}
+	return issues, count, nil
+}
+
+// readableRepoCond selects the repositories where the doer can read the issues, or the pull
+// requests if isPull is true, or either if it is none
+func readableRepoCond(doer *models.User, isPull util.OptionalBool) builder.Cond {
+	switch isPull {
+	case util.OptionalBoolFalse:
+		return models.AccessibleRepositoryUnitCondition(doer, models.UnitTypeIssues)
+	case util.OptionalBoolTrue:
+		return models.AccessibleRepositoryUnitCondition(doer, models.UnitTypePullRequests)
+	}
+	return builder.Or(
+		models.AccessibleRepositoryUnitCondition(doer, models.UnitTypeIssues),
+		models.AccessibleRepositoryUnitCondition(doer, models.UnitTypePullRequests),
+	)
+}
+
+// readableRepoIDsQuery selects the ids of the repositories matching readableRepoCond
+func readableRepoIDsQuery(doer *models.User, isPull util.OptionalBool) *builder.Builder {
+	return builder.Select("`repository`.id").From("repository").Where(readableRepoCond(doer, isPull))
+}
+
+// repoCond restricts the issues to the repositories whose issues can be read, and the pull requests
+// to the ones whose pull requests can be read
+func repoCond(doer *models.User, isPull util.OptionalBool) builder.Cond {
+	issueCond := builder.And(builder.Eq{"issue.is_pull": false},
+		builder.In("issue.repo_id", readableRepoIDsQuery(doer, util.OptionalBoolFalse)))
+	pullCond := builder.And(builder.Eq{"issue.is_pull": true},
+		builder.In("issue.repo_id", readableRepoIDsQuery(doer, util.OptionalBoolTrue)))
+	switch isPull {
+	case util.OptionalBoolFalse:
+		return issueCond
+	case util.OptionalBoolTrue:
+		return pullCond
+	}
+	return builder.Or(issueCond, pullCond)
+}
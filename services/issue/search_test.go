@@ -0,0 +1,71 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func issueIDs(issues []*models.Issue) []int64 {
+	ids := make([]int64, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	return ids
+}
+
+func TestSearchIssues(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	opts := &SearchOptions{
+		ListOptions: models.ListOptions{
+			Page:     1,
+			PageSize: 50,
+		},
+		IsPull:   util.OptionalBoolFalse,
+		SortType: "oldest",
+	}
+
+	// anonymous users only find the issues of public repositories
+	issues, count, err := SearchIssues(nil, opts)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(issues), count)
+	ids := issueIDs(issues)
+	assert.Contains(t, ids, int64(1))
+	assert.Contains(t, ids, int64(5))
+	assert.NotContains(t, ids, int64(4))
+	assert.NotContains(t, ids, int64(6))
+	assert.NotContains(t, ids, int64(7))
+	for _, issue := range issues {
+		assert.False(t, issue.IsPull)
+		assert.False(t, issue.Repo.IsPrivate)
+	}
+
+	// the owner of a private repository finds its issues
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	issues, _, err = SearchIssues(user2, opts)
+	assert.NoError(t, err)
+	ids = issueIDs(issues)
+	assert.Contains(t, ids, int64(4))
+	assert.Contains(t, ids, int64(7))
+
+	// filtered by state and author
+	opts.IsClosed = util.OptionalBoolFalse
+	opts.PosterID = user2.ID
+	issues, _, err = SearchIssues(user2, opts)
+	assert.NoError(t, err)
+	ids = issueIDs(issues)
+	assert.Contains(t, ids, int64(7))
+	assert.NotContains(t, ids, int64(4))
+	for _, issue := range issues {
+		assert.False(t, issue.IsClosed)
+		assert.EqualValues(t, user2.ID, issue.PosterID)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/sync"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// The reasons for which a pull request is removed from the merge queue, the locale keys of the
+// comments about it are suffixed with them
+const (
+	RemoveReasonChecksFailed = "checks_failed"
+	RemoveReasonConflict     = "conflict"
+	RemoveReasonUpdateFailed = "update_failed"
+	RemoveReasonNotAllowed   = "not_allowed"
+	RemoveReasonNotReady     = "not_ready"
+	RemoveReasonMergeFailed  = "merge_failed"
+)
+
+// branchQueue represents a queue of the branches whose merge queue has to be advanced
+var branchQueue queue.UniqueQueue
+
+// branchWorkingPool makes sure that the merge queue of a branch is advanced by one worker at a time
+var branchWorkingPool = sync.NewExclusivePool()
+
+// Init runs the queue advancing the merge queues and registers the notifier adding branches to it
+func Init() error {
+	branchQueue = queue.CreateUniqueQueue("pr_merge_queue", handle, "").(queue.UniqueQueue)
+
+	if branchQueue == nil {
+		return fmt.Errorf("Unable to create pr_merge_queue Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(branchQueue.Run)
+	notification.RegisterNotifier(NewNotifier())
+
+	// resume the merge queues which were waiting when the server stopped
+	go addBranchesToQueue(0)
+	return nil
+}
+
+func branchKey(repoID int64, branch string) string {
+	// a colon is not allowed in a branch name
+	return fmt.Sprintf("%d:%s", repoID, branch)
+}
+
+// addToQueue adds a branch to the queue to advance its merge queue
+func addToQueue(repoID int64, branch string) {
+	key := branchKey(repoID, branch)
+	if err := branchQueue.PushFunc(key, func() error {
+		log.Trace("Adding branch %s to the merge queue", key)
+		return nil
+	}); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding branch %s to the merge queue: %v", key, err)
+	}
+}
+
+// addBranchesToQueue adds the branches of the repository with a non empty merge queue to the queue,
+// or those of all repositories if repoID is 0
+func addBranchesToQueue(repoID int64) {
+	branches, err := models.GetMergeQueueBranches(repoID)
+	if err != nil {
+		log.Error("GetMergeQueueBranches[%d]: %v", repoID, err)
+		return
+	}
+	for _, branch := range branches {
+		addToQueue(branch.BaseRepoID, branch.BaseBranch)
+	}
+}
+
+// IsEnabled returns true if the pull request has to be added to the merge queue of its base branch
+// instead of being merged directly
+func IsEnabled(pr *models.PullRequest) (bool, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return false, err
+	}
+	return pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableMergeQueue, nil
+}
+
+// Add adds the pull request at the end of the merge queue of its base branch, to be merged by doer
+// once the pull requests queued before it have been merged and its required status checks pass
+func Add(doer *models.User, pr *models.PullRequest, style models.MergeStyle, message string) error {
+	if _, err := models.AddToMergeQueue(doer, pr, style, message); err != nil {
+		return err
+	}
+	// the queue may be empty
+	addToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// Remove removes the pull request from the merge queue on behalf of doer
+func Remove(doer *models.User, pr *models.PullRequest) error {
+	if _, err := models.RemoveFromMergeQueue(doer, pr, ""); err != nil {
+		return err
+	}
+	// the next pull request may be the first one now
+	addToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// remove removes the pull request from the merge queue for the given reason and notifies the user who
+// added it
+func remove(pr *models.PullRequest, entry *models.PullMergeQueue, reason string) {
+	log.Trace("Removing PR ID %d from the merge queue: %s", pr.ID, reason)
+	comment, err := models.RemoveFromMergeQueue(entry.Doer, pr, reason)
+	if err != nil {
+		log.Error("RemoveFromMergeQueue[%d]: %v", pr.ID, err)
+		return
+	} else if comment == nil {
+		return
+	}
+	if err := models.CreateOrUpdateIssueNotifications(pr.IssueID, comment.ID, 0, entry.DoerID); err != nil {
+		log.Error("CreateOrUpdateIssueNotifications[%d]: %v", pr.IssueID, err)
+	}
+}
+
+// handle advances the merge queues of the branches of the queue
+func handle(data ...queue.Data) {
+	for _, datum := range data {
+		key := datum.(string)
+		parts := strings.SplitN(key, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		repoID, _ := strconv.ParseInt(parts[0], 10, 64)
+
+		log.Trace("Advancing the merge queue of branch %s", key)
+		handleBranch(repoID, parts[1])
+	}
+}
+
+// handleBranch merges the pull requests at the head of the merge queue of the branch until one of
+// them has to wait
+func handleBranch(repoID int64, branch string) {
+	key := branchKey(repoID, branch)
+	branchWorkingPool.CheckIn(key)
+	defer branchWorkingPool.CheckOut(key)
+
+	for {
+		entries, err := models.GetMergeQueue(repoID, branch)
+		if err != nil {
+			log.Error("GetMergeQueue[%s]: %v", key, err)
+			return
+		}
+		if len(entries) == 0 || !handleHead(entries[0]) {
+			return
+		}
+	}
+}
+
+// handleHead advances the pull request at the head of a merge queue. It returns true if the pull
+// request has left the queue, so that the next one can be handled.
+func handleHead(entry *models.PullMergeQueue) bool {
+	pullID := entry.PullID
+	if err := entry.LoadDoer(); err != nil {
+		if !models.IsErrUserNotExist(err) {
+			log.Error("LoadDoer[%d]: %v", pullID, err)
+			return false
+		}
+		// the user who queued the pull request has been deleted
+		if err := models.DeleteMergeQueueEntry(pullID); err != nil {
+			log.Error("DeleteMergeQueueEntry[%d]: %v", pullID, err)
+			return false
+		}
+		return true
+	}
+
+	pr, err := models.GetPullRequestByID(pullID)
+	if err != nil {
+		log.Error("GetPullRequestByID[%d]: %v", pullID, err)
+		return false
+	}
+	if err := pr.LoadIssue(); err != nil {
+		log.Error("LoadIssue[%d]: %v", pullID, err)
+		return false
+	}
+	if pr.Issue.IsClosed {
+		if err := models.DeleteMergeQueueEntry(pullID); err != nil {
+			log.Error("DeleteMergeQueueEntry[%d]: %v", pullID, err)
+			return false
+		}
+		return true
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pullID, err)
+		return false
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	perm, err := models.GetUserRepoPermission(pr.BaseRepo, entry.Doer)
+	if err != nil {
+		log.Error("GetUserRepoPermission[%d]: %v", pullID, err)
+		return false
+	}
+	if allowed, err := pull_service.IsUserAllowedToMerge(pr, perm, entry.Doer); err != nil {
+		log.Error("IsUserAllowedToMerge[%d]: %v", pullID, err)
+		return false
+	} else if !allowed {
+		remove(pr, entry, RemoveReasonNotAllowed)
+		return true
+	}
+
+	if pr.Status == models.PullRequestStatusChecking {
+		// the patch checker has not caught up with the latest push yet
+		if err := pull_service.TestPatch(pr); err != nil {
+			log.Error("TestPatch[%d]: %v", pullID, err)
+			return false
+		}
+	}
+	if pr.Status == models.PullRequestStatusConflict {
+		remove(pr, entry, RemoveReasonConflict)
+		return true
+	}
+
+	// the queue must not wait for the reviews or the dependencies of its head
+	if pr.IsWorkInProgress() {
+		remove(pr, entry, RemoveReasonNotReady)
+		return true
+	}
+	if err := pull_service.CheckPRReadyToQueue(pr); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			log.Error("CheckPRReadyToQueue[%d]: %v", pullID, err)
+			return false
+		}
+		remove(pr, entry, RemoveReasonNotReady)
+		return true
+	}
+	if noDeps, err := models.IssueNoDependenciesLeft(pr.Issue); err != nil {
+		log.Error("IssueNoDependenciesLeft[%d]: %v", pullID, err)
+		return false
+	} else if !noDeps {
+		remove(pr, entry, RemoveReasonNotReady)
+		return true
+	}
+
+	if !entry.IsTesting() {
+		entry.Status = models.MergeQueueStatusTesting
+		if err := models.UpdateMergeQueueStatus(entry); err != nil {
+			log.Error("UpdateMergeQueueStatus[%d]: %v", pullID, err)
+			return false
+		}
+	}
+
+	// update the head branch with the latest base branch, so that the required status checks test
+	// the result of the merge
+	diff, err := pull_service.GetDiverging(pr)
+	if err != nil {
+		log.Error("GetDiverging[%d]: %v", pullID, err)
+		return false
+	}
+	if diff.Behind > 0 {
+		if err := pr.LoadHeadRepo(); err != nil {
+			log.Error("LoadHeadRepo[%d]: %v", pullID, err)
+			return false
+		}
+		if allowed, err := pull_service.IsUserAllowedToUpdate(pr, entry.Doer); err != nil {
+			log.Error("IsUserAllowedToUpdate[%d]: %v", pullID, err)
+			return false
+		} else if !allowed {
+			remove(pr, entry, RemoveReasonUpdateFailed)
+			return true
+		}
+
+		message := fmt.Sprintf("Merge branch '%s' into %s", pr.BaseBranch, pr.HeadBranch)
+		if err := pull_service.Update(pr, entry.Doer, message); err != nil {
+			if models.IsErrMergeConflicts(err) {
+				remove(pr, entry, RemoveReasonConflict)
+			} else {
+				log.Error("Update[%d]: %v", pullID, err)
+				remove(pr, entry, RemoveReasonUpdateFailed)
+			}
+			return true
+		}
+		// the push to the head branch synchronizes the pull request, which advances the queue again
+		return false
+	}
+
+	if pr.ProtectedBranch != nil && pr.ProtectedBranch.EnableStatusCheck {
+		state, err := pull_service.GetPullRequestCommitStatusState(pr)
+		if err != nil {
+			log.Error("GetPullRequestCommitStatusState[%d]: %v", pullID, err)
+			return false
+		}
+		if state.IsFailure() || state.IsError() {
+			remove(pr, entry, RemoveReasonChecksFailed)
+			return true
+		} else if !state.IsSuccess() {
+			return false
+		}
+	}
+
+	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%d]: %v", pullID, err)
+		return false
+	}
+	defer baseGitRepo.Close()
+
+	message := entry.Message
+	if message == "" {
+		if entry.MergeStyle == models.MergeStyleSquash {
+			message = pr.GetDefaultSquashMessage()
+		} else {
+			message = pr.GetDefaultMergeMessage()
+		}
+	}
+	if err := pull_service.Merge(pr, entry.Doer, baseGitRepo, entry.MergeStyle, message); err != nil {
+		log.Error("Merge[%d]: %v", pullID, err)
+		remove(pr, entry, RemoveReasonMergeFailed)
+		return true
+	}
+	if err := models.DeleteMergeQueueEntry(pullID); err != nil {
+		log.Error("DeleteMergeQueueEntry[%d]: %v", pullID, err)
+	}
+	log.Trace("Pull request merged from the merge queue: %d", pullID)
+	return true
+}
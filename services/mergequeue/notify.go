@@ -0,0 +1,58 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mergequeue
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+type mergeQueueNotifier struct {
+	base.NullNotifier
+}
+
+var (
+	_ base.Notifier = &mergeQueueNotifier{}
+)
+
+// NewNotifier create a new mergeQueueNotifier notifier, which advances the merge queues on the events
+// which can let the pull request at their head be merged or removed
+func NewNotifier() base.Notifier {
+	return &mergeQueueNotifier{}
+}
+
+// NotifyCreateCommitStatus advances the merge queues of the repository of the status, which is where
+// the statuses of the head commits of pull requests are looked up
+func (*mergeQueueNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, sha string, status *models.CommitStatus) {
+	addBranchesToQueue(repo.ID)
+}
+
+func (*mergeQueueNotifier) NotifyPullRequestSynchronized(doer *models.User, pr *models.PullRequest) {
+	addToQueue(pr.BaseRepoID, pr.BaseBranch)
+}
+
+func (*mergeQueueNotifier) NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment, mentions []*models.User) {
+	addToQueue(pr.BaseRepoID, pr.BaseBranch)
+}
+
+// NotifyIssueChangeStatus advances the merge queue of a closed pull request, which leaves it
+func (*mergeQueueNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, actionComment *models.Comment, closeOrReopen bool) {
+	if !issue.IsPull || !closeOrReopen {
+		return
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		return
+	}
+	addToQueue(issue.PullRequest.BaseRepoID, issue.PullRequest.BaseBranch)
+}
+
+// NotifyPushCommits advances the merge queue of the pushed branch, its head may be out of date now
+func (*mergeQueueNotifier) NotifyPushCommits(pusher *models.User, repo *models.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !opts.IsUpdateBranch() {
+		return
+	}
+	addToQueue(repo.ID, opts.BranchName())
+}
@@ -211,8 +211,15 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 	wikiPath := m.Repo.WikiPath()
 	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
 
+	creds, err := obtainCredentials(m)
+	if err != nil {
+		log.Error("Failed to update mirror repository %v: %v", m.Repo, err)
+		recordSyncError(m, models.MirrorErrorAuth, err.Error())
+		return nil, false
+	}
+
 	log.Trace("SyncMirrors [repo: %-v]: running git remote update...", m.Repo)
-	gitArgs := []string{"remote", "update"}
+	gitArgs := append(creds.gitArgs(), "remote", "update")
 	if m.EnablePrune {
 		gitArgs = append(gitArgs, "--prune")
 	}
@@ -221,7 +228,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 	stderrBuilder := strings.Builder{}
 	if err := git.NewCommand(gitArgs...).
 		SetDescription(fmt.Sprintf("Mirror.runSync: %s", m.Repo.FullName())).
-		RunInDirTimeoutPipeline(timeout, repoPath, &stdoutBuilder, &stderrBuilder); err != nil {
+		RunInDirTimeoutEnvPipeline(creds.env(), timeout, repoPath, &stdoutBuilder, &stderrBuilder); err != nil {
 		stdout := stdoutBuilder.String()
 		stderr := stderrBuilder.String()
 		// sanitize the output, since it may contain the remote address, which may
@@ -244,6 +251,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 		if err = models.CreateRepositoryNotice(desc); err != nil {
 			log.Error("CreateRepositoryNotice: %v", err)
 		}
+		recordSyncError(m, classifySyncError(stderrMessage), stderrMessage)
 		return nil, false
 	}
 	output := stderrBuilder.String()
@@ -270,9 +278,9 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 		log.Trace("SyncMirrors [repo: %-v Wiki]: running git remote update...", m.Repo)
 		stderrBuilder.Reset()
 		stdoutBuilder.Reset()
-		if err := git.NewCommand("remote", "update", "--prune").
+		if err := git.NewCommand(append(creds.gitArgs(), "remote", "update", "--prune")...).
 			SetDescription(fmt.Sprintf("Mirror.runSync Wiki: %s ", m.Repo.FullName())).
-			RunInDirTimeoutPipeline(timeout, wikiPath, &stdoutBuilder, &stderrBuilder); err != nil {
+			RunInDirTimeoutEnvPipeline(creds.env(), timeout, wikiPath, &stdoutBuilder, &stderrBuilder); err != nil {
 			stdout := stdoutBuilder.String()
 			stderr := stderrBuilder.String()
 			// sanitize the output, since it may contain the remote address, which may
@@ -295,6 +303,7 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 			if err = models.CreateRepositoryNotice(desc); err != nil {
 				log.Error("CreateRepositoryNotice: %v", err)
 			}
+			recordSyncError(m, classifySyncError(stderrMessage), stderrMessage)
 			return nil, false
 		}
 		log.Trace("SyncMirrors [repo: %-v Wiki]: git remote update complete", m.Repo)
@@ -311,6 +320,10 @@ func runSync(m *models.Mirror) ([]*mirrorSyncResult, bool) {
 		cache.Remove(m.Repo.GetCommitsCountCacheKey(branch.Name, true))
 	}
 
+	if m.LastErrorKind != models.MirrorErrorNone {
+		recordSyncError(m, models.MirrorErrorNone, "")
+	}
+
 	m.UpdatedUnix = timeutil.TimeStampNow()
 	return parseRemoteUpdateOutput(output), true
 }
@@ -352,6 +365,9 @@ func Update(ctx context.Context) error {
 		if m.Repo == nil {
 			log.Error("Disconnected mirror repository found: %d", m.ID)
 			return nil
+		} else if m.Repo.IsDeleted {
+			// mirrors pending deletion are not synced anymore
+			return nil
 		}
 		select {
 		case <-ctx.Done():
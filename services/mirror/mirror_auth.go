@@ -0,0 +1,246 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// credentialTimeout is the maximum time to obtain fresh credentials
+const credentialTimeout = time.Minute
+
+// mirrorHTTPClient requests the token endpoints through the proxy configured in the environment
+var mirrorHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	},
+	Timeout: credentialTimeout,
+}
+
+// credentialHelperScript is passed to git as credential helper, it answers with the
+// credentials from the environment so they never appear on a command line.
+const credentialHelperScript = `!f() { test "$1" = get && echo "username=${GITEA_MIRROR_USERNAME}" && echo "password=${GITEA_MIRROR_PASSWORD}"; }; f`
+
+// errMirrorAuth is returned when the credentials of a mirror could not be obtained
+type errMirrorAuth struct {
+	err error
+}
+
+func (e *errMirrorAuth) Error() string {
+	return fmt.Sprintf("unable to obtain mirror credentials: %v", e.err)
+}
+
+// mirrorCredentials holds credentials obtained for a single sync
+type mirrorCredentials struct {
+	username string
+	password string
+}
+
+// gitArgs returns the git arguments making the credentials available to the remote
+func (c *mirrorCredentials) gitArgs() []string {
+	if c == nil {
+		return nil
+	}
+	// The first empty helper resets the helpers configured globally
+	return []string{"-c", "credential.helper=", "-c", "credential.helper=" + credentialHelperScript}
+}
+
+// env returns the environment git has to be run with
+func (c *mirrorCredentials) env() []string {
+	if c == nil {
+		return nil
+	}
+	return append(os.Environ(), "GIT_TERMINAL_PROMPT=0",
+		"GITEA_MIRROR_USERNAME="+c.username, "GITEA_MIRROR_PASSWORD="+c.password)
+}
+
+// obtainCredentials returns fresh credentials for the mirror, or nil if the credentials
+// stored in the remote address are used.
+func obtainCredentials(m *models.Mirror) (*mirrorCredentials, error) {
+	if m.AuthType == models.MirrorAuthStatic {
+		return nil, nil
+	}
+
+	cfg, err := m.GetAuthConfig()
+	if err != nil {
+		return nil, &errMirrorAuth{err}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), credentialTimeout)
+	defer cancel()
+
+	var creds *mirrorCredentials
+	switch m.AuthType {
+	case models.MirrorAuthCredentialHelper:
+		creds, err = runCredentialHelper(ctx, m, cfg)
+	case models.MirrorAuthOAuth2:
+		creds, err = refreshOAuth2Token(ctx, m, cfg)
+	default:
+		err = fmt.Errorf("unknown auth type %d", m.AuthType)
+	}
+	if err != nil {
+		return nil, &errMirrorAuth{err}
+	}
+	return creds, nil
+}
+
+// runCredentialHelper runs a helper configured in [mirror.credential-helpers]
+func runCredentialHelper(ctx context.Context, m *models.Mirror, cfg *models.MirrorAuthConfig) (*mirrorCredentials, error) {
+	helper, ok := setting.Mirror.CredentialHelpers[cfg.Helper]
+	if !ok || helper == "" {
+		return nil, fmt.Errorf("credential helper %q is not configured", cfg.Helper)
+	}
+
+	readAddress(m)
+	u, err := url.Parse(m.Address)
+	if err != nil {
+		return nil, err
+	}
+	stdin := fmt.Sprintf("protocol=%s\nhost=%s\npath=%s\n\n", u.Scheme, u.Host, strings.TrimPrefix(u.Path, "/"))
+
+	stdout := new(bytes.Buffer)
+	stderr := new(strings.Builder)
+	cmd := exec.CommandContext(ctx, helper)
+	cmd.Env = append(os.Environ(),
+		"GITEA_MIRROR_REPO="+m.Repo.FullName(),
+		"GITEA_MIRROR_CREDENTIAL_INPUT="+cfg.HelperInput)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %q failed: %v - %s", cfg.Helper, err, strings.TrimSpace(stderr.String()))
+	}
+
+	creds := &mirrorCredentials{}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "=", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "username":
+			creds.username = fields[1]
+		case "password":
+			creds.password = fields[1]
+		}
+	}
+	if creds.password == "" {
+		return nil, fmt.Errorf("credential helper %q returned no password", cfg.Helper)
+	}
+	return creds, nil
+}
+
+// refreshOAuth2Token exchanges the refresh token of the mirror for a new access token.
+// If the provider rotates refresh tokens the new one is stored.
+func refreshOAuth2Token(ctx context.Context, m *models.Mirror, cfg *models.MirrorAuthConfig) (*mirrorCredentials, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {cfg.RefreshToken},
+		"client_id":     {cfg.ClientID},
+	}
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := mirrorHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		Error        string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("token endpoint returned %s: %v", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned %s: %s", resp.Status, token.Error)
+	}
+
+	if token.RefreshToken != "" && token.RefreshToken != cfg.RefreshToken {
+		cfg.RefreshToken = token.RefreshToken
+		if err := m.SetAuthConfig(cfg); err != nil {
+			return nil, err
+		}
+		if err := models.UpdateMirrorAuth(m); err != nil {
+			return nil, err
+		}
+	}
+
+	username := cfg.Username
+	if username == "" {
+		username = "oauth2"
+	}
+	return &mirrorCredentials{username: username, password: token.AccessToken}, nil
+}
+
+// classifySyncError guesses from the git output whether a sync failed because of the credentials
+// or because the remote could not be reached
+func classifySyncError(stderr string) models.MirrorErrorKind {
+	lower := strings.ToLower(stderr)
+	for _, s := range []string{
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"terminal prompts disabled",
+		"permission denied (publickey",
+		"invalid username or password",
+		"http basic: access denied",
+		"the requested url returned error: 401",
+		"the requested url returned error: 403",
+	} {
+		if strings.Contains(lower, s) {
+			return models.MirrorErrorAuth
+		}
+	}
+	for _, s := range []string{
+		"could not resolve host",
+		"connection timed out",
+		"connection refused",
+		"operation timed out",
+		"failed to connect",
+		"network is unreachable",
+		"no route to host",
+		"connection reset",
+		"the remote end hung up unexpectedly",
+	} {
+		if strings.Contains(lower, s) {
+			return models.MirrorErrorNetwork
+		}
+	}
+	return models.MirrorErrorOther
+}
+
+// recordSyncError stores the cause of a failed sync so it can be shown in the mirror status.
+// The message must not contain credentials.
+func recordSyncError(m *models.Mirror, kind models.MirrorErrorKind, message string) {
+	if err := models.UpdateMirrorLastError(m, kind, message); err != nil {
+		log.Error("UpdateMirrorLastError [%d]: %v", m.ID, err)
+	}
+}
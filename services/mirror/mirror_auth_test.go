@@ -0,0 +1,68 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package mirror
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifySyncError(t *testing.T) {
+	for stderr, kind := range map[string]models.MirrorErrorKind{
+		"fatal: Authentication failed for 'https://example.com/repo.git/'":                          models.MirrorErrorAuth,
+		"fatal: could not read Username for 'https://example.com': terminal prompts disabled":       models.MirrorErrorAuth,
+		"fatal: unable to access 'https://example.com/': Could not resolve host: example.com":       models.MirrorErrorNetwork,
+		"fatal: unable to access 'https://example.com/': Failed to connect to example.com port 443": models.MirrorErrorNetwork,
+		"fatal: couldn't find remote ref refs/heads/main":                                           models.MirrorErrorOther,
+	} {
+		assert.Equal(t, kind, classifySyncError(stderr), stderr)
+	}
+}
+
+func TestRefreshOAuth2Token(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client", r.PostForm.Get("client_id"))
+		if r.PostForm.Get("refresh_token") != "old-refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","refresh_token":"new-refresh","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	m := &models.Mirror{RepoID: 5, AuthType: models.MirrorAuthOAuth2}
+	cfg := &models.MirrorAuthConfig{TokenURL: server.URL, ClientID: "client", RefreshToken: "old-refresh"}
+	assert.NoError(t, m.SetAuthConfig(cfg))
+	assert.NoError(t, models.InsertMirror(m))
+
+	creds, err := refreshOAuth2Token(context.Background(), m, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "oauth2", creds.username)
+	assert.Equal(t, "access", creds.password)
+
+	// The rotated refresh token has been stored
+	m, err = models.GetMirrorByRepoID(5)
+	assert.NoError(t, err)
+	cfg, err = m.GetAuthConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "new-refresh", cfg.RefreshToken)
+
+	// The old refresh token is rejected now
+	cfg.RefreshToken = "old-refresh-revoked"
+	_, err = refreshOAuth2Token(context.Background(), m, cfg)
+	assert.Error(t, err)
+}
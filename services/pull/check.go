@@ -28,26 +28,31 @@ var prQueue queue.UniqueQueue
 
 // AddToTaskQueue adds itself to pull request test task queue.
 func AddToTaskQueue(pr *models.PullRequest) {
-	go func() {
-		err := prQueue.PushFunc(strconv.FormatInt(pr.ID, 10), func() error {
-			pr.Status = models.PullRequestStatusChecking
-			err := pr.UpdateColsIfNotMerged("status")
-			if err != nil {
-				log.Error("AddToTaskQueue.UpdateCols[%d].(add to queue): %v", pr.ID, err)
-			} else {
-				log.Trace("Adding PR ID: %d to the test pull requests queue", pr.ID)
-			}
-			return err
-		})
-		if err != nil && err != queue.ErrAlreadyInQueue {
-			log.Error("Error adding prID %d to the test pull requests queue: %v", pr.ID, err)
+	go addToTaskQueue(pr)
+}
+
+// addToTaskQueue adds the pull request to the test task queue and marks it as being checked before
+// returning, unless it is already queued
+func addToTaskQueue(pr *models.PullRequest) {
+	err := prQueue.PushFunc(strconv.FormatInt(pr.ID, 10), func() error {
+		pr.Status = models.PullRequestStatusChecking
+		err := pr.UpdateColsIfNotMerged("status")
+		if err != nil {
+			log.Error("AddToTaskQueue.UpdateCols[%d].(add to queue): %v", pr.ID, err)
+		} else {
+			log.Trace("Adding PR ID: %d to the test pull requests queue", pr.ID)
 		}
-	}()
+		return err
+	})
+	if err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding prID %d to the test pull requests queue: %v", pr.ID, err)
+	}
 }
 
 // checkAndUpdateStatus checks if pull request is possible to leaving checking status,
-// and set to be either conflict or mergeable.
-func checkAndUpdateStatus(pr *models.PullRequest) {
+// and set to be either conflict or mergeable. The notifiers are told about the change from
+// oldStatus, the status of the pull request before the check.
+func checkAndUpdateStatus(pr *models.PullRequest, oldStatus models.PullRequestStatus) {
 	// Status is not changed to conflict means mergeable.
 	if pr.Status == models.PullRequestStatusChecking {
 		pr.Status = models.PullRequestStatusMergeable
@@ -62,6 +67,8 @@ func checkAndUpdateStatus(pr *models.PullRequest) {
 	if !has {
 		if err := pr.UpdateColsIfNotMerged("merge_base", "status", "conflicted_files", "changed_protected_files"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+		} else if pr.Status != oldStatus {
+			notification.NotifyPullRequestChangeMergeable(pr, oldStatus)
 		}
 	}
 }
@@ -228,7 +235,9 @@ func handle(data ...queue.Data) {
 			continue
 		} else if manuallyMerged(pr) {
 			continue
-		} else if err = TestPatch(pr); err != nil {
+		}
+		oldStatus := pr.Status
+		if err = TestPatch(pr); err != nil {
 			log.Error("testPatch[%d]: %v", pr.ID, err)
 			pr.Status = models.PullRequestStatusError
 			if err := pr.UpdateCols("status"); err != nil {
@@ -236,7 +245,7 @@ func handle(data ...queue.Data) {
 			}
 			continue
 		}
-		checkAndUpdateStatus(pr)
+		checkAndUpdateStatus(pr, oldStatus)
 	}
 }
 
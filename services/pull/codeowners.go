@@ -0,0 +1,224 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/codeowners"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// maxCodeOwnersSize is the size above which a CODEOWNERS file is truncated
+const maxCodeOwnersSize = 3 * 1024 * 1024
+
+// codeOwner represents an owner of a CODEOWNERS file, either a user or a team
+type codeOwner struct {
+	userID int64
+	team   *models.Team
+}
+
+// GetPendingCodeOwners returns the owners which have to approve the pull request before it can be merged,
+// i.e. the owners, listed in the CODEOWNERS file of the base branch, of the changed files none of whose
+// owners has approved the pull request yet. It returns nil if the protected base branch does not require
+// the approval of code owners.
+func GetPendingCodeOwners(pr *models.PullRequest) ([]string, error) {
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return nil, fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil || !pr.ProtectedBranch.RequireCodeOwnerReviews {
+		return nil, nil
+	}
+	if err := pr.LoadBaseRepo(); err != nil {
+		return nil, fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+	defer gitRepo.Close()
+
+	rules, err := getCodeOwnersRules(gitRepo, pr.BaseBranch)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	files, err := gitRepo.GetFilesChangedBetween(git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+	if err != nil {
+		return nil, fmt.Errorf("GetFilesChangedBetween: %v", err)
+	}
+
+	approvers, err := getCodeOwnersApprovers(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]*codeOwner)
+	checked := make(map[*codeowners.Rule]bool)
+	pending := make([]string, 0, 5)
+	seen := make(map[string]bool)
+	for _, file := range files {
+		rule := codeowners.FindRule(rules, file)
+		if rule == nil || checked[rule] {
+			continue
+		}
+		checked[rule] = true
+
+		valid := make([]string, 0, len(rule.Owners))
+		approved := false
+		for _, name := range rule.Owners {
+			owner, has := owners[name]
+			if !has {
+				if owner, err = resolveCodeOwner(name); err != nil {
+					return nil, err
+				}
+				owners[name] = owner
+			}
+			if owner == nil {
+				continue
+			}
+			valid = append(valid, name)
+
+			if approved, err = owner.hasApproved(approvers); err != nil {
+				return nil, err
+			} else if approved {
+				break
+			}
+		}
+
+		// the files whose owners are all unknown are not owned
+		if approved || len(valid) == 0 {
+			continue
+		}
+		for _, name := range valid {
+			if !seen[name] {
+				seen[name] = true
+				pending = append(pending, name)
+			}
+		}
+	}
+	return pending, nil
+}
+
+// getCodeOwnersRules returns the rules of the CODEOWNERS file of the branch, if it has one
+func getCodeOwnersRules(gitRepo *git.Repository, branch string) ([]*codeowners.Rule, error) {
+	commit, err := gitRepo.GetBranchCommit(branch)
+	if err != nil {
+		return nil, fmt.Errorf("GetBranchCommit: %v", err)
+	}
+
+	for _, location := range codeowners.Locations {
+		blob, err := commit.GetBlobByPath(location)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("GetBlobByPath: %v", err)
+		}
+
+		dataRc, err := blob.DataAsync()
+		if err != nil {
+			return nil, fmt.Errorf("DataAsync: %v", err)
+		}
+		defer dataRc.Close()
+
+		content, err := ioutil.ReadAll(io.LimitReader(dataRc, maxCodeOwnersSize))
+		if err != nil {
+			return nil, fmt.Errorf("ReadAll: %v", err)
+		}
+		return codeowners.Parse(string(content)), nil
+	}
+	return nil, nil
+}
+
+// getCodeOwnersApprovers returns the users whose approval of the pull request counts, the stale
+// approvals are ignored if the protected branch dismisses them
+func getCodeOwnersApprovers(pr *models.PullRequest) ([]int64, error) {
+	// Only the latest approving or rejecting review of every reviewer is official
+	reviews, err := models.FindReviews(models.FindReviewOptions{
+		Type:         models.ReviewTypeApprove,
+		IssueID:      pr.IssueID,
+		OfficialOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindReviews: %v", err)
+	}
+
+	approvers := make([]int64, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Dismissed || (review.Stale && pr.ProtectedBranch.DismissStaleApprovals) {
+			continue
+		}
+		approvers = append(approvers, review.ReviewerID)
+	}
+	return approvers, nil
+}
+
+// resolveCodeOwner returns the user or the team of an owner of a CODEOWNERS file, given as @user,
+// @org/team or an email address, or nil if it does not exist
+func resolveCodeOwner(name string) (*codeOwner, error) {
+	if !strings.HasPrefix(name, "@") {
+		user, err := models.GetUserByEmail(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &codeOwner{userID: user.ID}, nil
+	}
+
+	name = strings.TrimPrefix(name, "@")
+	if i := strings.Index(name, "/"); i >= 0 {
+		org, err := models.GetOrgByName(name[:i])
+		if err != nil {
+			if models.IsErrOrgNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		team, err := org.GetTeam(name[i+1:])
+		if err != nil {
+			if models.IsErrTeamNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return &codeOwner{team: team}, nil
+	}
+
+	user, err := models.GetUserByName(name)
+	if err != nil {
+		if models.IsErrUserNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &codeOwner{userID: user.ID}, nil
+}
+
+// hasApproved returns true if one of the approvers is the owner or a member of the team of the owner
+func (owner *codeOwner) hasApproved(approvers []int64) (bool, error) {
+	for _, approverID := range approvers {
+		if owner.team == nil {
+			if approverID == owner.userID {
+				return true, nil
+			}
+			continue
+		}
+		isMember, err := models.IsTeamMember(owner.team.OrgID, owner.team.ID, approverID)
+		if err != nil {
+			return false, err
+		} else if isMember {
+			return true, nil
+		}
+	}
+	return false, nil
+}
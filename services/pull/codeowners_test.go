@@ -0,0 +1,47 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCodeOwnersApprovers(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, models.UpdateProtectBranch(repo, &models.ProtectedBranch{
+		RepoID:                  repo.ID,
+		BranchName:              "master",
+		RequireCodeOwnerReviews: true,
+	}, models.WhitelistOptions{}))
+
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 1}).(*models.PullRequest)
+	assert.NoError(t, pr.LoadIssue())
+	assert.NoError(t, pr.Issue.LoadRepo())
+	assert.NoError(t, pr.LoadProtectedBranch())
+	user2 := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+
+	approvers, err := getCodeOwnersApprovers(pr)
+	assert.NoError(t, err)
+	assert.NotContains(t, approvers, user2.ID)
+
+	_, _, err = models.SubmitReview(user2, pr.Issue, models.ReviewTypeApprove, "looks good", "", false)
+	assert.NoError(t, err)
+	approvers, err = getCodeOwnersApprovers(pr)
+	assert.NoError(t, err)
+	assert.Contains(t, approvers, user2.ID)
+
+	// Requesting changes afterwards withdraws the approval
+	_, _, err = models.SubmitReview(user2, pr.Issue, models.ReviewTypeReject, "needs work", "", false)
+	assert.NoError(t, err)
+	approvers, err = getCodeOwnersApprovers(pr)
+	assert.NoError(t, err)
+	assert.NotContains(t, approvers, user2.ID)
+}
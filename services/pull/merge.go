@@ -581,38 +581,82 @@ func CheckPRReadyToMerge(pr *models.PullRequest, skipProtectedFilesCheck bool) (
 		}
 	}
 
-	if !pr.ProtectedBranch.HasEnoughApprovals(pr) {
-		return models.ErrNotAllowedToMerge{
-			Reason: "Does not have enough approvals",
-		}
+	if err := checkPRReviews(pr); err != nil {
+		return err
 	}
-	if pr.ProtectedBranch.MergeBlockedByRejectedReview(pr) {
+
+	if pr.ProtectedBranch.MergeBlockedByOutdatedBranch(pr) {
 		return models.ErrNotAllowedToMerge{
-			Reason: "There are requested changes",
+			Reason: "The head branch is behind the base branch",
 		}
 	}
-	if pr.ProtectedBranch.MergeBlockedByOfficialReviewRequests(pr) {
-		return models.ErrNotAllowedToMerge{
-			Reason: "There are official review requests",
-		}
+
+	if skipProtectedFilesCheck {
+		return nil
 	}
 
-	if pr.ProtectedBranch.MergeBlockedByOutdatedBranch(pr) {
+	if pr.ProtectedBranch.MergeBlockedByProtectedFiles(pr) {
 		return models.ErrNotAllowedToMerge{
-			Reason: "The head branch is behind the base branch",
+			Reason: "Changed protected files",
 		}
 	}
 
-	if skipProtectedFilesCheck {
+	return nil
+}
+
+// CheckPRReadyToQueue checks whether a pull request can be added to the merge queue of its base branch.
+// Unlike CheckPRReadyToMerge it ignores the required status checks and an outdated head branch, which
+// are taken care of by the queue.
+func CheckPRReadyToQueue(pr *models.PullRequest) error {
+	if err := pr.LoadBaseRepo(); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %v", err)
+	}
+
+	if err := pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil {
 		return nil
 	}
 
+	if err := checkPRReviews(pr); err != nil {
+		return err
+	}
+
 	if pr.ProtectedBranch.MergeBlockedByProtectedFiles(pr) {
 		return models.ErrNotAllowedToMerge{
 			Reason: "Changed protected files",
 		}
 	}
+	return nil
+}
 
+// checkPRReviews checks the reviews of a pull request against the protection of its base branch
+func checkPRReviews(pr *models.PullRequest) error {
+	if !pr.ProtectedBranch.HasEnoughApprovals(pr) {
+		return models.ErrNotAllowedToMerge{
+			Reason: "Does not have enough approvals",
+		}
+	}
+	if pr.ProtectedBranch.MergeBlockedByRejectedReview(pr) {
+		return models.ErrNotAllowedToMerge{
+			Reason: "There are requested changes",
+		}
+	}
+	if pr.ProtectedBranch.MergeBlockedByOfficialReviewRequests(pr) {
+		return models.ErrNotAllowedToMerge{
+			Reason: "There are official review requests",
+		}
+	}
+	pendingCodeOwners, err := GetPendingCodeOwners(pr)
+	if err != nil {
+		return err
+	}
+	if len(pendingCodeOwners) > 0 {
+		return models.ErrNotAllowedToMerge{
+			Reason: "Code owners have not approved: " + strings.Join(pendingCodeOwners, ", "),
+		}
+	}
 	return nil
 }
 
@@ -66,6 +66,10 @@ func NewPullRequest(repo *models.Repository, pull *models.Issue, labelIDs []int6
 		notification.NotifyIssueChangeMilestone(pull.Poster, pull, 0)
 	}
 
+	if err := issue_service.RequestDefaultReviewers(pull); err != nil {
+		log.Error("Unable to request the reviews of the default reviewers of %-v: %v", pr, err)
+	}
+
 	// add first push codes comment
 	baseGitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
 	if err != nil {
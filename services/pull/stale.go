@@ -0,0 +1,109 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	comment_service "code.gitea.io/gitea/services/comments"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// HeadBranchState represents whether the head branch of a pull request still exists
+type HeadBranchState int
+
+const (
+	// HeadBranchExists the head branch exists
+	HeadBranchExists HeadBranchState = iota
+	// HeadBranchDeletedMerged the head branch was deleted after its commits were merged into the base branch
+	HeadBranchDeletedMerged
+	// HeadBranchDeletedAbandoned the head branch was deleted without being merged
+	HeadBranchDeletedAbandoned
+)
+
+// GetHeadBranchState returns whether the head branch of the pull request still exists and, if it was
+// deleted, whether its last commit is part of the base branch
+func GetHeadBranchState(pr *models.PullRequest) (HeadBranchState, error) {
+	if err := pr.LoadHeadRepo(); err != nil {
+		return HeadBranchExists, err
+	}
+	if pr.HeadRepo != nil {
+		headGitRepo, err := git.OpenRepository(pr.HeadRepo.RepoPath())
+		if err != nil {
+			return HeadBranchExists, err
+		}
+		exist := headGitRepo.IsBranchExist(pr.HeadBranch)
+		headGitRepo.Close()
+		if exist {
+			return HeadBranchExists, nil
+		}
+	}
+
+	if err := pr.LoadBaseRepo(); err != nil {
+		return HeadBranchExists, err
+	}
+	_, err := git.NewCommand("merge-base", "--is-ancestor", pr.GetGitRefName(), git.BranchPrefix+pr.BaseBranch).RunInDir(pr.BaseRepo.RepoPath())
+	if err == nil {
+		return HeadBranchDeletedMerged, nil
+	}
+	// the head ref or the base branch may be missing as well, both mean the pull request was not merged
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return HeadBranchDeletedAbandoned, nil
+	}
+	return HeadBranchExists, err
+}
+
+// CloseStalePulls closes the open pull requests whose head branch was deleted and which have not been
+// updated for the given duration, a comment explaining why is posted on each of them
+func CloseStalePulls(ctx context.Context, doer *models.User, olderThan time.Duration) error {
+	prs, err := models.GetUnmergedPullRequestsNotUpdatedSince(timeutil.TimeStamp(time.Now().Add(-olderThan).Unix()))
+	if err != nil {
+		return err
+	}
+
+	for _, pr := range prs {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before closing stale pull request %d", pr.ID)
+		default:
+		}
+
+		state, err := GetHeadBranchState(pr)
+		if err != nil {
+			log.Error("GetHeadBranchState[%d]: %v", pr.ID, err)
+			continue
+		}
+		if state == HeadBranchExists {
+			continue
+		}
+		if err := pr.LoadIssue(); err != nil {
+			return err
+		}
+		if err := pr.Issue.LoadRepo(); err != nil {
+			return err
+		}
+
+		content := fmt.Sprintf("This pull request was closed automatically because its head branch `%s` was deleted without being merged.", pr.HeadBranch)
+		if state == HeadBranchDeletedMerged {
+			content = fmt.Sprintf("This pull request was closed automatically because its head branch `%s` was deleted after its changes were merged into `%s`.", pr.HeadBranch, pr.BaseBranch)
+		}
+		if _, err := comment_service.CreateIssueComment(doer, pr.Issue.Repo, pr.Issue, content, nil); err != nil {
+			return err
+		}
+		if err := issue_service.ChangeStatus(pr.Issue, doer, true); err != nil && !models.IsErrPullWasClosed(err) {
+			return err
+		}
+	}
+	return nil
+}
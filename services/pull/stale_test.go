@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHeadBranchState(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	pr := models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	state, err := GetHeadBranchState(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, HeadBranchExists, state)
+
+	pr.HeadBranch = "deleted-branch"
+	state, err = GetHeadBranchState(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, HeadBranchDeletedAbandoned, state)
+
+	// the head commit of pull request 5 is the tip of its head branch
+	pr = models.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 5}).(*models.PullRequest)
+	pr.BaseBranch = pr.HeadBranch
+	pr.HeadBranch = "deleted-branch"
+	state, err = GetHeadBranchState(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, HeadBranchDeletedMerged, state)
+}
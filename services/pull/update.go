@@ -12,7 +12,9 @@ import (
 	"code.gitea.io/gitea/modules/log"
 )
 
-// Update updates pull request with base branch.
+// Update updates pull request with base branch. Whether it succeeds or fails because of conflicts,
+// the pull request is marked as being checked and its mergeable status is computed again in the
+// background, so that its conflicting files are listed.
 func Update(pull *models.PullRequest, doer *models.User, message string) error {
 	//use merge functions but switch repo's and branch's
 	pr := &models.PullRequest{
@@ -38,6 +40,9 @@ func Update(pull *models.PullRequest, doer *models.User, message string) error {
 	}
 
 	_, err = rawMerge(pr, doer, models.MergeStyleMerge, message)
+	if err == nil || models.IsErrMergeConflicts(err) {
+		addToTaskQueue(pull)
+	}
 
 	defer func() {
 		go AddTestPullRequestTask(doer, pr.HeadRepo.ID, pr.HeadBranch, false, "", "")
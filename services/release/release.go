@@ -194,6 +194,23 @@ func DeleteReleaseByID(id int64, doer *models.User, delTag bool) error {
 	}
 
 	if delTag {
+		protectedTags, err := repo.GetProtectedTags()
+		if err != nil {
+			return fmt.Errorf("GetProtectedTags: %v", err)
+		}
+		isAllowed, err := models.IsUserAllowedToControlTag(protectedTags, rel.TagName, doer.ID)
+		if err != nil {
+			return err
+		}
+		if !isAllowed {
+			if err := models.CreateRepositoryNotice("Rejected attempt by %s to delete protected tag %s in %s", doer.Name, rel.TagName, repo.FullName()); err != nil {
+				log.Error("CreateRepositoryNotice: %v", err)
+			}
+			return models.ErrProtectedTagName{
+				TagName: rel.TagName,
+			}
+		}
+
 		if stdout, err := git.NewCommand("tag", "-d", rel.TagName).
 			SetDescription(fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID)).
 			RunInDir(repo.RepoPath()); err != nil && !strings.Contains(err.Error(), "not found") {
@@ -0,0 +1,48 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PurgeDeletedRepositories purges the repositories which have been pending deletion for longer than
+// the retention, all of them if deleted repositories are not kept anymore
+func PurgeDeletedRepositories(ctx context.Context) error {
+	repos, _, err := models.FindDeletedRepositories(&models.FindDeletedRepoOptions{
+		DeletedBefore: timeutil.TimeStampNow().AddDuration(-setting.Repository.DeletedRetention),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		select {
+		case <-ctx.Done():
+			return models.ErrCancelledf("before purging %s", repo.FullName())
+		default:
+		}
+
+		// the deletion is notified on behalf of the user who deleted the repository if they still exist
+		doer, err := models.GetUserByID(repo.DeletedByID)
+		if err != nil {
+			if !models.IsErrUserNotExist(err) {
+				return err
+			}
+			doer = repo.Owner
+		}
+		if err := PurgeRepository(doer, repo); err != nil {
+			log.Error("Unable to purge the deleted repository %s: %v", repo.FullName(), err)
+			continue
+		}
+		log.Trace("Deleted repository purged: %s", repo.FullName())
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRepositoryRetention(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(retention time.Duration) {
+		setting.Repository.DeletedRetention = retention
+	}(setting.Repository.DeletedRetention)
+	setting.Repository.DeletedRetention = time.Hour
+
+	doer := models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 4}).(*models.Repository)
+	assert.NoError(t, DeleteRepository(doer, repo))
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 4, IsDeleted: true})
+
+	// the retention is not over yet
+	assert.NoError(t, PurgeDeletedRepositories(context.Background()))
+	models.AssertExistsAndLoadBean(t, &models.Repository{ID: 4, IsDeleted: true})
+
+	// the repositories pending deletion are purged once they are not kept anymore
+	setting.Repository.DeletedRetention = 0
+	assert.NoError(t, PurgeDeletedRepositories(context.Background()))
+	models.AssertNotExistsBean(t, &models.Repository{ID: 4})
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// GarbageCollectResult represents the payload of a finished garbage collection task
+type GarbageCollectResult struct {
+	// SizeBefore and SizeAfter are the sizes in bytes of the git objects of the repository
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+}
+
+// GetGarbageCollectResult returns the result of a finished garbage collection task
+func GetGarbageCollectResult(task *models.Task) (*GarbageCollectResult, error) {
+	if task.Type != structs.TaskTypeGarbageCollectRepo {
+		return nil, fmt.Errorf("Task type is %s, not Garbage Collect Repository", task.Type.Name())
+	}
+	result := new(GarbageCollectResult)
+	if task.PayloadContent == "" {
+		return result, nil
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	return result, json.Unmarshal([]byte(task.PayloadContent), result)
+}
+
+// IsGarbageCollectPending returns true if the garbage collection task is still to be run or is running,
+// a task running for longer than the gc timeout is considered interrupted
+func IsGarbageCollectPending(task *models.Task) bool {
+	switch task.Status {
+	case structs.TaskStatusQueue:
+		return true
+	case structs.TaskStatusRunning:
+		timeout := time.Duration(setting.Git.Timeout.GC) * time.Second
+		return timeout <= 0 || task.StartTime.AddDuration(timeout) > timeutil.TimeStampNow()
+	}
+	return false
+}
+
+// GarbageCollect queues a garbage collection of the repository, which runs in the background.
+// It returns the task recording its status, which is the pending one if there is already one,
+// so the garbage collections of a repository never overlap. doer is nil for automatic ones.
+func GarbageCollect(doer *models.User, repo *models.Repository) (*models.Task, error) {
+	task, err := models.GetLastRepoTask(repo.ID, structs.TaskTypeGarbageCollectRepo)
+	if err == nil && IsGarbageCollectPending(task) {
+		return task, nil
+	} else if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		return nil, err
+	}
+
+	task = &models.Task{
+		OwnerID: repo.OwnerID,
+		RepoID:  repo.ID,
+		Type:    structs.TaskTypeGarbageCollectRepo,
+		Status:  structs.TaskStatusQueue,
+	}
+	doerName := "automatic policy"
+	if doer != nil {
+		task.DoerID = doer.ID
+		doerName = doer.Name
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+
+	return task, maintenanceQueue.Push(&MaintenanceTask{
+		Operation: MaintenanceGC,
+		RepoID:    repo.ID,
+		DoerName:  doerName,
+		TaskID:    task.ID,
+	})
+}
+
+// objectsSize returns the size in bytes of the git objects of the repository
+func objectsSize(repoPath string) (int64, error) {
+	return util.GetDirectorySize(filepath.Join(repoPath, "objects"))
+}
+
+func runGarbageCollectTask(ctx context.Context, maintenance *MaintenanceTask) error {
+	task, err := models.GetTaskByID(maintenance.TaskID)
+	if err != nil {
+		return err
+	}
+	if err := task.LoadRepo(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pid := process.GetManager().Add(fmt.Sprintf("Repository garbage collection of %s", task.Repo.FullName()), cancel)
+	defer process.GetManager().Remove(pid)
+
+	task.Status = structs.TaskStatusRunning
+	task.StartTime = timeutil.TimeStampNow()
+	if err := task.UpdateCols("status", "start_time"); err != nil {
+		return err
+	}
+
+	result := &GarbageCollectResult{}
+	result.SizeBefore, err = objectsSize(task.Repo.RepoPath())
+	if err == nil {
+		err = repo_module.GitGcRepo(ctx, task.Repo, time.Duration(setting.Git.Timeout.GC)*time.Second, setting.Git.GCArgs...)
+	}
+	if err == nil {
+		result.SizeAfter, err = objectsSize(task.Repo.RepoPath())
+	}
+	if err == nil {
+		if err := task.Repo.UpdateSize(models.DefaultDBContext()); err != nil {
+			log.Error("Failed to update size for repository: %v", err)
+		}
+	}
+
+	task.EndTime = timeutil.TimeStampNow()
+	if err != nil {
+		task.Status = structs.TaskStatusFailed
+		task.Errors = err.Error()
+	} else {
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		payload, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		task.Status = structs.TaskStatusFinished
+		task.PayloadContent = string(payload)
+	}
+	return task.UpdateCols("status", "end_time", "errors", "payload_content")
+}
+
+// needsGarbageCollect returns true if the objects of a repository reach one of the thresholds of
+// the automatic garbage collection policy
+func needsGarbageCollect(stats *git.CountObject) bool {
+	policy := setting.Git.AutoGC
+	// count-objects reports kibibytes
+	return policy.LooseObjects > 0 && stats.Count >= policy.LooseObjects ||
+		policy.LooseSize > 0 && stats.Size*1024 >= policy.LooseSize ||
+		policy.PackFiles > 0 && stats.Packs >= policy.PackFiles
+}
+
+// garbageCollectIfNeeded applies the automatic garbage collection policy to the repository, the
+// garbage collection is queued at most once per minimum interval
+func garbageCollectIfNeeded(repo *models.Repository) error {
+	if !setting.Git.AutoGC.Enabled {
+		return nil
+	}
+
+	stats, err := git.CountObjects(repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	if !needsGarbageCollect(stats) {
+		return nil
+	}
+
+	last, err := models.GetLastRepoTask(repo.ID, structs.TaskTypeGarbageCollectRepo)
+	if err == nil && last.Created.AddDuration(setting.Git.AutoGC.MinInterval) > timeutil.TimeStampNow() {
+		return nil
+	} else if err != nil && !models.IsErrTaskDoesNotExist(err) {
+		return err
+	}
+
+	log.Trace("Queueing automatic garbage collection of %s: %+v", repo.FullName(), stats)
+	_, err = GarbageCollect(nil, repo)
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunGarbageCollectTask(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	task := &models.Task{
+		OwnerID: 2,
+		RepoID:  1,
+		Type:    structs.TaskTypeGarbageCollectRepo,
+		Status:  structs.TaskStatusQueue,
+	}
+	assert.NoError(t, models.CreateTask(task))
+	assert.True(t, IsGarbageCollectPending(task))
+
+	assert.NoError(t, runGarbageCollectTask(context.Background(), &MaintenanceTask{
+		Operation: MaintenanceGC,
+		RepoID:    1,
+		TaskID:    task.ID,
+	}))
+
+	task, err := models.GetLastRepoTask(1, structs.TaskTypeGarbageCollectRepo)
+	assert.NoError(t, err)
+	assert.Equal(t, structs.TaskStatusFinished, task.Status)
+	assert.False(t, IsGarbageCollectPending(task))
+	result, err := GetGarbageCollectResult(task)
+	assert.NoError(t, err)
+	assert.Greater(t, result.SizeBefore, int64(0))
+	assert.Greater(t, result.SizeAfter, int64(0))
+}
+
+func TestNeedsGarbageCollect(t *testing.T) {
+	oldPolicy := setting.Git.AutoGC
+	defer func() {
+		setting.Git.AutoGC = oldPolicy
+	}()
+	setting.Git.AutoGC.LooseObjects = 100
+	setting.Git.AutoGC.LooseSize = 1024 * 1024
+	setting.Git.AutoGC.PackFiles = 10
+
+	assert.False(t, needsGarbageCollect(&git.CountObject{Count: 99, Size: 1023, Packs: 9}))
+	assert.True(t, needsGarbageCollect(&git.CountObject{Count: 100}))
+	assert.True(t, needsGarbageCollect(&git.CountObject{Size: 1024}))
+	assert.True(t, needsGarbageCollect(&git.CountObject{Packs: 10}))
+
+	// a zero threshold is ignored
+	setting.Git.AutoGC.PackFiles = 0
+	assert.False(t, needsGarbageCollect(&git.CountObject{Packs: 10}))
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+)
+
+// MaintenanceOperation represents a maintenance operation on git repositories
+type MaintenanceOperation string
+
+const (
+	// MaintenanceGC runs git gc
+	MaintenanceGC MaintenanceOperation = "gc"
+	// MaintenanceFsck runs git fsck
+	MaintenanceFsck MaintenanceOperation = "fsck"
+)
+
+// maxReportedRepositories is the maximum number of repositories listed in the notice of a dry run
+const maxReportedRepositories = 50
+
+// MaintenanceTask represents a maintenance operation on a repository or on all repositories
+type MaintenanceTask struct {
+	Operation MaintenanceOperation
+	// RepoID is the repository to run the operation on, zero means all repositories
+	RepoID int64
+	// DryRun only reports the repositories the operation would run on
+	DryRun bool
+	// MinPackSize skips repositories whose pack files are smaller than this number of bytes
+	MinPackSize int64
+	DoerName    string
+	// TaskID is the task recording the status of the garbage collection of the repository, if any
+	TaskID int64
+}
+
+// maintenanceQueue represents a queue to handle repository maintenance tasks
+var maintenanceQueue queue.Queue
+
+func handleMaintenance(data ...queue.Data) {
+	for _, datum := range data {
+		task := datum.(*MaintenanceTask)
+		if task.TaskID > 0 {
+			if err := runGarbageCollectTask(graceful.GetManager().ShutdownContext(), task); err != nil {
+				log.Error("Garbage collection of repository %d failed: %v", task.RepoID, err)
+			}
+			continue
+		}
+		if err := runMaintenance(graceful.GetManager().ShutdownContext(), task); err != nil {
+			log.Error("Repository maintenance %s failed: %v", task.Operation, err)
+		}
+	}
+}
+
+func initMaintenanceQueue() error {
+	maintenanceQueue = queue.CreateQueue("repo_maintenance", handleMaintenance, &MaintenanceTask{})
+	if maintenanceQueue == nil {
+		return fmt.Errorf("Unable to create repo_maintenance Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(maintenanceQueue.Run)
+	return nil
+}
+
+// QueueMaintenance adds a maintenance task to the queue, it runs in the background
+func QueueMaintenance(task *MaintenanceTask) error {
+	switch task.Operation {
+	case MaintenanceGC, MaintenanceFsck:
+	default:
+		return fmt.Errorf("unknown maintenance operation %q", task.Operation)
+	}
+	return maintenanceQueue.Push(task)
+}
+
+// bloatedRepository is a repository with its pack size as reported by a dry run
+type bloatedRepository struct {
+	FullName string
+	PackSize int64
+}
+
+func runMaintenance(ctx context.Context, task *MaintenanceTask) error {
+	target := "all repositories"
+	if task.RepoID > 0 {
+		repo, err := models.GetRepositoryByID(task.RepoID)
+		if err != nil {
+			return err
+		}
+		target = repo.FullName()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pid := process.GetManager().Add(fmt.Sprintf("Repository maintenance: %s of %s", task.Operation, target), cancel)
+	defer process.GetManager().Remove(pid)
+
+	start := time.Now()
+	var reported []bloatedRepository
+	processed, failed := 0, 0
+	cond := builder.Cond(builder.Gt{"id": 0})
+	if task.RepoID > 0 {
+		cond = builder.Eq{"id": task.RepoID}
+	}
+	err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		cond,
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before %s of %s", task.Operation, repo.FullName())
+			default:
+			}
+
+			if task.MinPackSize > 0 || task.DryRun {
+				size, err := repo_module.PackSize(repo)
+				if err != nil {
+					log.Error("Unable to get the pack size of %s: %v", repo.FullName(), err)
+					failed++
+					return nil
+				}
+				if size < task.MinPackSize {
+					return nil
+				}
+				if task.DryRun {
+					reported = append(reported, bloatedRepository{repo.FullName(), size})
+					return nil
+				}
+			}
+
+			var err error
+			switch task.Operation {
+			case MaintenanceGC:
+				err = repo_module.GitGcRepo(ctx, repo, time.Duration(setting.Git.Timeout.GC)*time.Second, setting.Git.GCArgs...)
+			case MaintenanceFsck:
+				err = repo_module.GitFsckRepo(ctx, repo, time.Duration(setting.Git.Timeout.Default)*time.Second, nil)
+			}
+			processed++
+			if err != nil {
+				failed++
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		if err := models.CreateRepositoryNotice("Repository maintenance %s of %s started by %s was aborted: %v", task.Operation, target, task.DoerName, err); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+		return err
+	}
+
+	var desc string
+	if task.DryRun {
+		sort.Slice(reported, func(i, j int) bool {
+			return reported[i].PackSize > reported[j].PackSize
+		})
+		desc = fmt.Sprintf("Dry run of repository maintenance %s of %s started by %s: %d repositories have pack files of at least %s",
+			task.Operation, target, task.DoerName, len(reported), base.FileSize(task.MinPackSize))
+		if len(reported) > maxReportedRepositories {
+			reported = reported[:maxReportedRepositories]
+			desc += fmt.Sprintf(", the %d largest are", maxReportedRepositories)
+		}
+		var sb strings.Builder
+		for _, repo := range reported {
+			fmt.Fprintf(&sb, "\n%s: %s", repo.FullName, base.FileSize(repo.PackSize))
+		}
+		desc += sb.String()
+	} else {
+		desc = fmt.Sprintf("Repository maintenance %s of %s started by %s finished in %v: %d repositories processed, %d failed",
+			task.Operation, target, task.DoerName, time.Since(start).Round(time.Second), processed, failed)
+	}
+	if err := models.CreateRepositoryNotice("%s", desc); err != nil {
+		log.Error("CreateRepositoryNotice: %v", err)
+	}
+	return nil
+}
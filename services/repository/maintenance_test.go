@@ -0,0 +1,56 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lastNotice(t *testing.T) *models.Notice {
+	notices, err := models.Notices(1, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, notices, 1) {
+		return notices[0]
+	}
+	return nil
+}
+
+func TestRunMaintenance(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	assert.NoError(t, runMaintenance(context.Background(), &MaintenanceTask{
+		Operation: MaintenanceGC,
+		RepoID:    1,
+		DryRun:    true,
+		DoerName:  "user1",
+	}))
+	notice := lastNotice(t)
+	assert.Contains(t, notice.Description, "Dry run of repository maintenance gc of user2/repo1 started by user1: 1 repositories")
+	assert.Contains(t, notice.Description, "\nuser2/repo1: ")
+
+	// repositories with smaller pack files are skipped
+	assert.NoError(t, runMaintenance(context.Background(), &MaintenanceTask{
+		Operation:   MaintenanceGC,
+		RepoID:      1,
+		DryRun:      true,
+		MinPackSize: 1 << 40,
+		DoerName:    "user1",
+	}))
+	assert.Contains(t, lastNotice(t).Description, ": 0 repositories")
+
+	for _, operation := range []MaintenanceOperation{MaintenanceGC, MaintenanceFsck} {
+		assert.NoError(t, runMaintenance(context.Background(), &MaintenanceTask{
+			Operation: operation,
+			RepoID:    1,
+			DoerName:  "user1",
+		}))
+		assert.Contains(t, lastNotice(t).Description, "1 repositories processed, 0 failed")
+	}
+}
@@ -86,6 +86,9 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 	if err = repo.UpdateSize(models.DefaultDBContext()); err != nil {
 		log.Error("Failed to update size for repository: %v", err)
 	}
+	if err = garbageCollectIfNeeded(repo); err != nil {
+		log.Error("Failed to apply the automatic garbage collection policy to %-v: %v", repo, err)
+	}
 
 	addTags := make([]string, 0, len(optsList))
 	delTags := make([]string, 0, len(optsList))
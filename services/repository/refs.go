@@ -0,0 +1,82 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"xorm.io/builder"
+)
+
+// refCountWarningPercent is the percentage of the MAX_ADVERTISED_REFS limit from which repositories are reported
+const refCountWarningPercent = 80
+
+// repositoryRefCounts is a repository with the number of its refs
+type repositoryRefCounts struct {
+	FullName string
+	git.RefCounts
+}
+
+// CheckRefCounts creates a notice listing the repositories whose number of refs is close to or above the
+// MAX_ADVERTISED_REFS limit, nothing is checked without limit
+func CheckRefCounts(ctx context.Context) error {
+	if setting.Git.MaxAdvertisedRefs <= 0 {
+		return nil
+	}
+	threshold := setting.Git.MaxAdvertisedRefs * refCountWarningPercent / 100
+
+	var reported []repositoryRefCounts
+	if err := models.Iterate(
+		models.DefaultDBContext(),
+		new(models.Repository),
+		builder.Gt{"id": 0},
+		func(idx int, bean interface{}) error {
+			repo := bean.(*models.Repository)
+			select {
+			case <-ctx.Done():
+				return models.ErrCancelledf("before checking the refs of %s", repo.FullName())
+			default:
+			}
+
+			counts, err := git.CountRefs(repo.RepoPath())
+			if err != nil {
+				log.Error("Unable to count the refs of %s: %v", repo.FullName(), err)
+				return nil
+			}
+			if counts.Total >= threshold {
+				reported = append(reported, repositoryRefCounts{repo.FullName(), counts})
+			}
+			return nil
+		},
+	); err != nil {
+		return err
+	}
+	if len(reported) == 0 {
+		return nil
+	}
+
+	sort.Slice(reported, func(i, j int) bool {
+		return reported[i].Total > reported[j].Total
+	})
+	desc := fmt.Sprintf("%d repositories have at least %d%% of the %d refs advertised at most to clients using git wire protocol version 0 or 1",
+		len(reported), refCountWarningPercent, setting.Git.MaxAdvertisedRefs)
+	if len(reported) > maxReportedRepositories {
+		reported = reported[:maxReportedRepositories]
+		desc += fmt.Sprintf(", the %d largest are", maxReportedRepositories)
+	}
+	var sb strings.Builder
+	for _, repo := range reported {
+		fmt.Fprintf(&sb, "\n%s: %d refs, %d of pull requests", repo.FullName, repo.Total, repo.Pulls)
+	}
+	return models.CreateRepositoryNotice("%s", desc+sb.String())
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRefCounts(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+	defer func(limit int64) {
+		setting.Git.MaxAdvertisedRefs = limit
+	}(setting.Git.MaxAdvertisedRefs)
+
+	numNotices := models.CountNotices()
+
+	// nothing is checked without limit
+	setting.Git.MaxAdvertisedRefs = 0
+	assert.NoError(t, CheckRefCounts(context.Background()))
+	assert.EqualValues(t, numNotices, models.CountNotices())
+
+	setting.Git.MaxAdvertisedRefs = 100000
+	assert.NoError(t, CheckRefCounts(context.Background()))
+	assert.EqualValues(t, numNotices, models.CountNotices())
+
+	setting.Git.MaxAdvertisedRefs = 1
+	assert.NoError(t, CheckRefCounts(context.Background()))
+	notice := lastNotice(t)
+	assert.Contains(t, notice.Description, "of the 1 refs advertised at most")
+	assert.Contains(t, notice.Description, "\nuser2/repo1: ")
+}
@@ -58,8 +58,17 @@ func ForkRepository(doer, u *models.User, oldRepo *models.Repository, name, desc
 	return repo, nil
 }
 
-// DeleteRepository deletes a repository for a user or organization.
+// DeleteRepository deletes a repository for a user or organization. It is only marked as deleted
+// if deleted repositories are kept for a while, see PurgeDeletedRepositories.
 func DeleteRepository(doer *models.User, repo *models.Repository) error {
+	if cfg.Repository.DeletedRetention > 0 {
+		return models.SoftDeleteRepository(doer, repo)
+	}
+	return PurgeRepository(doer, repo)
+}
+
+// PurgeRepository deletes a repository immediately, whether it is pending deletion or not
+func PurgeRepository(doer *models.User, repo *models.Repository) error {
 	if err := pull_service.CloseRepoBranchesPulls(doer, repo); err != nil {
 		log.Error("CloseRepoBranchesPulls failed: %v", err)
 	}
@@ -98,5 +107,8 @@ func PushCreateRepo(authUser, owner *models.User, repoName string) (*models.Repo
 
 // NewContext start repository service
 func NewContext() error {
-	return initPushQueue()
+	if err := initPushQueue(); err != nil {
+		return err
+	}
+	return initMaintenanceQueue()
 }
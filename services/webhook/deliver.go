@@ -36,6 +36,17 @@ func Deliver(t *models.HookTask) error {
 	}()
 	t.IsDelivered = true
 
+	// Tasks queued before the webhook has been paused are dropped
+	if w, err := models.GetWebhookByID(t.HookID); err == nil && !w.IsActive {
+		t.Delivered = time.Now().UnixNano()
+		t.ResponseInfo = &models.HookResponse{Body: "Delivery skipped: the webhook is paused"}
+		if err := models.UpdateHookTask(t); err != nil {
+			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
+		}
+		log.Trace("Hook delivery skipped (webhook paused): %s", t.UUID)
+		return nil
+	}
+
 	var req *http.Request
 	var err error
 
@@ -99,6 +110,17 @@ func Deliver(t *models.HookTask) error {
 	req.Header.Add("X-Gogs-Signature", t.Signature)
 	req.Header["X-GitHub-Delivery"] = []string{t.UUID}
 	req.Header["X-GitHub-Event"] = []string{t.EventType.Event()}
+	if len(t.Signature) > 0 {
+		switch t.SignatureType {
+		case models.HookSignatureSHA1:
+			req.Header.Set("X-Hub-Signature", "sha1="+t.Signature)
+		case models.HookSignatureSHA256:
+			req.Header.Set("X-Hub-Signature-256", "sha256="+t.Signature)
+		}
+	}
+	if t.Typ == models.JSON {
+		addJSONHookHeaders(t, req)
+	}
 
 	// Record delivery information.
 	t.RequestInfo = &models.HookRequest{
@@ -112,8 +134,12 @@ func Deliver(t *models.HookTask) error {
 		Headers: map[string]string{},
 	}
 
+	var start time.Time
 	defer func() {
 		t.Delivered = time.Now().UnixNano()
+		if !start.IsZero() {
+			t.ResponseInfo.Duration = time.Since(start).Round(time.Millisecond)
+		}
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
 		} else {
@@ -139,12 +165,17 @@ func Deliver(t *models.HookTask) error {
 			log.Error("UpdateWebhookLastStatus: %v", err)
 			return
 		}
+
+		if !t.IsSucceed && !setting.DisableWebhooks {
+			handleFailedDelivery(t, w)
+		}
 	}()
 
 	if setting.DisableWebhooks {
 		return fmt.Errorf("Webhook task skipped (webhooks disabled): [%d]", t.ID)
 	}
 
+	start = time.Now()
 	resp, err := webhookHTTPClient.Do(req)
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
@@ -195,12 +226,36 @@ func DeliverHooks(ctx context.Context) {
 		}
 	}
 
+	// Failed deliveries are retried once their scheduled time has come.
+	var retryC <-chan time.Time
+	if setting.Webhook.MaxAttempts > 1 {
+		ticker := time.NewTicker(retryCheckInterval())
+		defer ticker.Stop()
+		retryC = ticker.C
+	}
+
 	// Start listening on new hook requests.
 	for {
 		select {
 		case <-ctx.Done():
 			hookQueue.Close()
 			return
+		case <-retryC:
+			tasks, err := models.FindUndeliveredHookTasks()
+			if err != nil {
+				log.Error("DeliverHooks: %v", err)
+				continue
+			}
+			for _, t := range tasks {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				if err = Deliver(t); err != nil {
+					log.Error("deliver: %v", err)
+				}
+			}
 		case repoIDStr := <-hookQueue.Queue():
 			log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
 			hookQueue.Remove(repoIDStr)
@@ -265,6 +265,70 @@ func (d *DingtalkPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	}, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (d *DingtalkPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text,
+			Title:       text,
+			HideAvatar:  "0",
+			SingleTitle: "view commit",
+			SingleURL:   p.Repository.HTMLURL + "/commit/" + p.SHA,
+		},
+	}, nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (d *DingtalkPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, _ := getCommitCommentPayloadInfo(p, noneLinkFormatter, true)
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text + "\r\n\r\n" + p.Comment.Body,
+			Title:       text,
+			HideAvatar:  "0",
+			SingleTitle: "view commit comment",
+			SingleURL:   p.Comment.HTMLURL,
+		},
+	}, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (d *DingtalkPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, noneLinkFormatter, true)
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text,
+			Title:       text,
+			HideAvatar:  "0",
+			SingleTitle: "view team",
+			SingleURL:   orgTeamURL(p.Organization, p.Team),
+		},
+	}, nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (d *DingtalkPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, _ := getMembershipPayloadInfo(p, noneLinkFormatter, true)
+
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text,
+			Title:       text,
+			HideAvatar:  "0",
+			SingleTitle: "view members",
+			SingleURL:   membershipURL(p),
+		},
+	}, nil
+}
+
 // GetDingtalkPayload converts a ding talk webhook into a DingtalkPayload
 func GetDingtalkPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(DingtalkPayload), p, event)
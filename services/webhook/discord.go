@@ -404,6 +404,97 @@ func (d *DiscordPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	}, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (d *DiscordPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return &DiscordPayload{
+		Username:  d.Username,
+		AvatarURL: d.AvatarURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       text,
+				Description: p.Status.Description,
+				URL:         p.Repository.HTMLURL + "/commit/" + p.SHA,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (d *DiscordPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, color := getCommitCommentPayloadInfo(p, noneLinkFormatter, false)
+
+	return &DiscordPayload{
+		Username:  d.Username,
+		AvatarURL: d.AvatarURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       text,
+				Description: p.Comment.Body,
+				URL:         p.Comment.HTMLURL,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (d *DiscordPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, color := getTeamPayloadInfo(p, noneLinkFormatter, false)
+
+	return &DiscordPayload{
+		Username:  d.Username,
+		AvatarURL: d.AvatarURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       text,
+				Description: p.Team.Description,
+				URL:         orgTeamURL(p.Organization, p.Team),
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (d *DiscordPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, color := getMembershipPayloadInfo(p, noneLinkFormatter, false)
+
+	return &DiscordPayload{
+		Username:  d.Username,
+		AvatarURL: d.AvatarURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title: text,
+				URL:   membershipURL(p),
+				Color: color,
+				Author: DiscordEmbedAuthor{
+					Name:    p.Sender.UserName,
+					URL:     setting.AppURL + p.Sender.UserName,
+					IconURL: p.Sender.AvatarURL,
+				},
+			},
+		},
+	}, nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	s := new(DiscordPayload)
@@ -163,6 +163,34 @@ func (f *FeishuPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	return newFeishuTextPayload(text), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (f *FeishuPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (f *FeishuPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, _ := getCommitCommentPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text + "\r\n\r\n" + p.Comment.Body), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (f *FeishuPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (f *FeishuPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, _ := getMembershipPayloadInfo(p, noneLinkFormatter, true)
+
+	return newFeishuTextPayload(text), nil
+}
+
 // GetFeishuPayload converts a ding talk webhook into a FeishuPayload
 func GetFeishuPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(FeishuPayload), p, event)
@@ -9,6 +9,7 @@ import (
 	"html"
 	"strings"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -121,6 +122,14 @@ func getPullRequestPayloadInfo(p *api.PullRequestPayload, linkFormatter linkForm
 		text = fmt.Sprintf("[%s] Pull request milestone cleared: %s", repoLink, titleLink)
 	case api.HookIssueReviewed:
 		text = fmt.Sprintf("[%s] Pull request reviewed: %s", repoLink, titleLink)
+	case api.HookIssueMergeableChanged:
+		text = fmt.Sprintf("[%s] Pull request %s: %s", repoLink, p.PullRequest.MergeableState, titleLink)
+		switch p.PullRequest.MergeableState {
+		case "mergeable":
+			color = greenColor
+		case "conflict", "error":
+			color = redColor
+		}
 	}
 	if withSender {
 		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
@@ -156,6 +165,120 @@ func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, w
 	return text, color
 }
 
+func getStatusPayloadInfo(p *api.CommitStatusPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	commitLink := linkFormatter(p.Repository.HTMLURL+"/commit/"+p.SHA, base.ShortSha(p.SHA))
+
+	state := p.Status.State
+	if p.CombinedStatus != nil {
+		state = p.CombinedStatus.State
+	}
+	text = fmt.Sprintf("[%s] Commit %s is %s", repoLink, commitLink, state)
+
+	statusContext := p.Status.Context
+	if len(p.Status.TargetURL) > 0 {
+		statusContext = linkFormatter(p.Status.TargetURL, p.Status.Context)
+	}
+	text += fmt.Sprintf(": %s %s", statusContext, p.Status.State)
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	switch {
+	case state.IsSuccess():
+		color = greenColor
+	case state.IsPending():
+		color = yellowColor
+	case state.IsWarning():
+		color = orangeColor
+	default:
+		color = redColor
+	}
+	return text, color
+}
+
+// orgTeamURL returns the URL of the page of a team of an organization
+func orgTeamURL(org *api.Organization, team *api.Team) string {
+	return setting.AppURL + "org/" + org.UserName + "/teams/" + strings.ToLower(team.Name)
+}
+
+func getTeamPayloadInfo(p *api.TeamPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	orgLink := linkFormatter(setting.AppURL+p.Organization.UserName, p.Organization.UserName)
+	teamLink := linkFormatter(orgTeamURL(p.Organization, p.Team), p.Team.Name)
+
+	switch p.Action {
+	case api.HookTeamCreated:
+		text = fmt.Sprintf("[%s] Team created: %s", orgLink, teamLink)
+		color = greenColor
+	case api.HookTeamEdited:
+		text = fmt.Sprintf("[%s] Team edited: %s", orgLink, teamLink)
+		color = yellowColor
+	case api.HookTeamDeleted:
+		text = fmt.Sprintf("[%s] Team deleted: %s", orgLink, p.Team.Name)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+	return text, color
+}
+
+func getMembershipPayloadInfo(p *api.MembershipPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	orgLink := linkFormatter(setting.AppURL+p.Organization.UserName, p.Organization.UserName)
+	memberLink := linkFormatter(setting.AppURL+p.Member.UserName, p.Member.UserName)
+	target := "the organization"
+	if p.Team != nil {
+		target = "team " + linkFormatter(orgTeamURL(p.Organization, p.Team), p.Team.Name)
+	}
+
+	switch p.Action {
+	case api.HookMembershipAdded:
+		text = fmt.Sprintf("[%s] Member %s added to %s", orgLink, memberLink, target)
+		color = greenColor
+	case api.HookMembershipRemoved:
+		text = fmt.Sprintf("[%s] Member %s removed from %s", orgLink, memberLink, target)
+		color = redColor
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+	return text, color
+}
+
+// membershipURL returns the URL of the page of the team or, for a membership of the organization,
+// of the members of the organization
+func membershipURL(p *api.MembershipPayload) string {
+	if p.Team != nil {
+		return orgTeamURL(p.Organization, p.Team)
+	}
+	return setting.AppURL + "org/" + p.Organization.UserName + "/members"
+}
+
+func getCommitCommentPayloadInfo(p *api.CommitCommentPayload, linkFormatter linkFormatter, withSender bool) (text string, color int) {
+	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	commitLink := linkFormatter(p.Comment.HTMLURL, base.ShortSha(p.Comment.CommitID))
+
+	switch p.Action {
+	case api.HookCommitCommentCreated:
+		text = fmt.Sprintf("[%s] New comment on commit %s", repoLink, commitLink)
+		color = orangeColorLight
+	case api.HookCommitCommentDeleted:
+		text = fmt.Sprintf("[%s] Comment deleted on commit %s", repoLink, commitLink)
+		color = redColor
+	}
+	if len(p.Comment.Path) > 0 {
+		text += " at " + p.Comment.Path
+		if p.Comment.Line != 0 {
+			text += fmt.Sprintf(":%d", p.Comment.Line)
+		}
+	}
+	if withSender {
+		text += fmt.Sprintf(" by %s", linkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName))
+	}
+
+	return text, color
+}
+
 func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFormatter, withSender bool) (string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
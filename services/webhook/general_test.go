@@ -101,6 +101,85 @@ func pullReleaseTestPayload() *api.ReleasePayload {
 	}
 }
 
+func statusTestPayload() *api.CommitStatusPayload {
+	return &api.CommitStatusPayload{
+		SHA: "2020fbde0c2b0f8f0b0e1dd3d9a8c23bf4b8a65d",
+		Status: &api.CommitStatus{
+			State:     api.CommitStatusFailure,
+			TargetURL: "http://ci.example.com/builds/2",
+			Context:   "ci/test",
+		},
+		CombinedStatus: &api.CombinedStatus{
+			State: api.CommitStatusFailure,
+		},
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+	}
+}
+
+func teamTestPayload() *api.TeamPayload {
+	return &api.TeamPayload{
+		Action: api.HookTeamCreated,
+		Team: &api.Team{
+			ID:   2,
+			Name: "Developers",
+		},
+		Organization: &api.Organization{
+			UserName: "org3",
+		},
+		Sender: &api.User{
+			UserName: "user1",
+		},
+	}
+}
+
+func membershipTestPayload() *api.MembershipPayload {
+	return &api.MembershipPayload{
+		Action: api.HookMembershipAdded,
+		Scope:  api.HookMembershipScopeTeam,
+		Member: &api.User{
+			UserName: "user2",
+		},
+		Team: &api.Team{
+			ID:   2,
+			Name: "Developers",
+		},
+		Organization: &api.Organization{
+			UserName: "org3",
+		},
+		Sender: &api.User{
+			UserName: "user1",
+		},
+	}
+}
+
+func commitCommentTestPayload() *api.CommitCommentPayload {
+	return &api.CommitCommentPayload{
+		Action: api.HookCommitCommentCreated,
+		Comment: &api.CommitComment{
+			HTMLURL:  "http://localhost:3000/test/repo/commit/2020fbde0c2b0f8f0b0e1dd3d9a8c23bf4b8a65d#commitcomment-4",
+			CommitID: "2020fbde0c2b0f8f0b0e1dd3d9a8c23bf4b8a65d",
+			Path:     "README.md",
+			Line:     3,
+			Body:     "typo here",
+		},
+		Sender: &api.User{
+			UserName: "user1",
+		},
+		Repository: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+	}
+}
+
 func pullRequestTestPayload() *api.PullRequestPayload {
 	return &api.PullRequestPayload{
 		Action: api.HookIssueOpened,
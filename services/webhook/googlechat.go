@@ -0,0 +1,345 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	jsoniter "github.com/json-iterator/go"
+)
+
+type (
+	// GoogleChatPayload represents a message of a Google Chat incoming webhook
+	// see: https://developers.google.com/chat/api/reference/rest/v1/cards
+	GoogleChatPayload struct {
+		CardsV2 []GoogleChatCardV2 `json:"cardsV2"`
+	}
+
+	// GoogleChatCardV2 represents a card of a Google Chat message
+	GoogleChatCardV2 struct {
+		CardID string         `json:"cardId"`
+		Card   GoogleChatCard `json:"card"`
+	}
+
+	// GoogleChatCard represents the content of a Google Chat card
+	GoogleChatCard struct {
+		Header   GoogleChatCardHeader `json:"header"`
+		Sections []GoogleChatSection  `json:"sections"`
+	}
+
+	// GoogleChatCardHeader represents the header of a Google Chat card
+	GoogleChatCardHeader struct {
+		Title     string `json:"title"`
+		Subtitle  string `json:"subtitle,omitempty"`
+		ImageURL  string `json:"imageUrl,omitempty"`
+		ImageType string `json:"imageType,omitempty"`
+	}
+
+	// GoogleChatSection represents a section of a Google Chat card
+	GoogleChatSection struct {
+		Header  string             `json:"header,omitempty"`
+		Widgets []GoogleChatWidget `json:"widgets"`
+	}
+
+	// GoogleChatWidget represents a widget of a Google Chat card section, only one of the fields is set
+	GoogleChatWidget struct {
+		DecoratedText *GoogleChatDecoratedText `json:"decoratedText,omitempty"`
+		TextParagraph *GoogleChatTextParagraph `json:"textParagraph,omitempty"`
+		ButtonList    *GoogleChatButtonList    `json:"buttonList,omitempty"`
+	}
+
+	// GoogleChatDecoratedText represents a text with a label and an icon
+	GoogleChatDecoratedText struct {
+		TopLabel  string          `json:"topLabel,omitempty"`
+		Text      string          `json:"text"`
+		StartIcon *GoogleChatIcon `json:"startIcon,omitempty"`
+	}
+
+	// GoogleChatIcon represents an icon given by its URL
+	GoogleChatIcon struct {
+		IconURL string `json:"iconUrl"`
+	}
+
+	// GoogleChatTextParagraph represents a paragraph of text
+	GoogleChatTextParagraph struct {
+		Text string `json:"text"`
+	}
+
+	// GoogleChatButtonList represents a list of buttons
+	GoogleChatButtonList struct {
+		Buttons []GoogleChatButton `json:"buttons"`
+	}
+
+	// GoogleChatButton represents a button opening a link
+	GoogleChatButton struct {
+		Text    string            `json:"text"`
+		OnClick GoogleChatOnClick `json:"onClick"`
+	}
+
+	// GoogleChatOnClick represents the action of a button
+	GoogleChatOnClick struct {
+		OpenLink GoogleChatOpenLink `json:"openLink"`
+	}
+
+	// GoogleChatOpenLink represents a link to open
+	GoogleChatOpenLink struct {
+		URL string `json:"url"`
+	}
+)
+
+// ValidateGoogleChatURL checks that the URL of a Google Chat webhook carries
+// the key and the token of the space, which are given as query parameters.
+func ValidateGoogleChatURL(webhookURL string) error {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	if len(query.Get("key")) == 0 || len(query.Get("token")) == 0 {
+		return errors.New("the URL must contain the key and token query parameters of the space")
+	}
+	return nil
+}
+
+// SetSecret sets the Google Chat secret
+func (g *GoogleChatPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the GoogleChatPayload to json
+func (g *GoogleChatPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// googleChatText escapes text for the limited HTML supported by Google Chat cards
+func googleChatText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// createGoogleChatPayload creates a card with the repository and the actor of
+// an event, the optional body and a button linking to the subject of the event.
+func createGoogleChatPayload(event string, repo *api.Repository, sender *api.User, title, subtitle, body, linkText, link string) *GoogleChatPayload {
+	var widgets []GoogleChatWidget
+	// events of organizations, e.g. of their teams, have no repository
+	if repo != nil {
+		widgets = append(widgets, GoogleChatWidget{
+			DecoratedText: &GoogleChatDecoratedText{
+				TopLabel: "Repository",
+				Text:     htmlLinkFormatter(repo.HTMLURL, repo.FullName),
+			},
+		})
+	}
+	widgets = append(widgets, GoogleChatWidget{
+		DecoratedText: &GoogleChatDecoratedText{
+			TopLabel:  "Sender",
+			Text:      htmlLinkFormatter(setting.AppURL+sender.UserName, sender.UserName),
+			StartIcon: &GoogleChatIcon{IconURL: sender.AvatarURL},
+		},
+	})
+	sections := []GoogleChatSection{{Widgets: widgets}}
+	if len(body) > 0 {
+		sections = append(sections, GoogleChatSection{
+			Widgets: []GoogleChatWidget{
+				{TextParagraph: &GoogleChatTextParagraph{Text: body}},
+			},
+		})
+	}
+	if len(link) > 0 {
+		sections = append(sections, GoogleChatSection{
+			Widgets: []GoogleChatWidget{
+				{
+					ButtonList: &GoogleChatButtonList{
+						Buttons: []GoogleChatButton{
+							{Text: linkText, OnClick: GoogleChatOnClick{OpenLink: GoogleChatOpenLink{URL: link}}},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	return &GoogleChatPayload{
+		CardsV2: []GoogleChatCardV2{
+			{
+				CardID: "gitea-" + event,
+				Card: GoogleChatCard{
+					Header: GoogleChatCardHeader{
+						Title:     title,
+						Subtitle:  subtitle,
+						ImageURL:  sender.AvatarURL,
+						ImageType: "CIRCLE",
+					},
+					Sections: sections,
+				},
+			},
+		},
+	}
+}
+
+var (
+	_ PayloadConvertor = &GoogleChatPayload{}
+)
+
+// Create implements PayloadConvertor Create method
+func (g *GoogleChatPayload) Create(p *api.CreatePayload) (api.Payloader, error) {
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s created", p.Repo.FullName, p.RefType, refName)
+
+	return createGoogleChatPayload("create", p.Repo, p.Sender, title, "", "", "View "+p.RefType, git.RefURL(p.Repo.HTMLURL, p.Ref)), nil
+}
+
+// Delete implements PayloadConvertor Delete method
+func (g *GoogleChatPayload) Delete(p *api.DeletePayload) (api.Payloader, error) {
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s deleted", p.Repo.FullName, p.RefType, refName)
+
+	return createGoogleChatPayload("delete", p.Repo, p.Sender, title, "", "", "", ""), nil
+}
+
+// Fork implements PayloadConvertor Fork method
+func (g *GoogleChatPayload) Fork(p *api.ForkPayload) (api.Payloader, error) {
+	title := fmt.Sprintf("%s is forked to %s", p.Forkee.FullName, p.Repo.FullName)
+
+	return createGoogleChatPayload("fork", p.Repo, p.Sender, title, "", "", "View fork", p.Repo.HTMLURL), nil
+}
+
+// Issue implements PayloadConvertor Issue method
+func (g *GoogleChatPayload) Issue(p *api.IssuePayload) (api.Payloader, error) {
+	title, issueTitle, body, _ := getIssuesPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("issues", p.Repository, p.Sender, title, issueTitle, googleChatText(body), "View issue", p.Issue.HTMLURL), nil
+}
+
+// IssueComment implements PayloadConvertor IssueComment method
+func (g *GoogleChatPayload) IssueComment(p *api.IssueCommentPayload) (api.Payloader, error) {
+	title, issueTitle, _ := getIssueCommentPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("issue-comment", p.Repository, p.Sender, title, issueTitle, googleChatText(p.Comment.Body), "View comment", p.Comment.HTMLURL), nil
+}
+
+// Release implements PayloadConvertor Release method
+func (g *GoogleChatPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
+	title, _ := getReleasePayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("release", p.Repository, p.Sender, title, p.Release.Title, googleChatText(p.Release.Note), "View release", p.Release.HTMLURL), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (g *GoogleChatPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	title, _ := getStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("status", p.Repository, p.Sender, title, p.Status.Context, googleChatText(p.Status.Description), "View commit", p.Repository.HTMLURL+"/commit/"+p.SHA), nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (g *GoogleChatPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	title, _ := getCommitCommentPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("commit-comment", p.Repository, p.Sender, title, p.Comment.CommitID, googleChatText(p.Comment.Body), "View comment", p.Comment.HTMLURL), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (g *GoogleChatPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	title, _ := getTeamPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("team", nil, p.Sender, title, p.Organization.UserName, googleChatText(p.Team.Description), "View team", orgTeamURL(p.Organization, p.Team)), nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (g *GoogleChatPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	title, _ := getMembershipPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("membership", nil, p.Sender, title, p.Organization.UserName, "", "View members", membershipURL(p)), nil
+}
+
+// Push implements PayloadConvertor Push method
+func (g *GoogleChatPayload) Push(p *api.PushPayload) (api.Payloader, error) {
+	var commitDesc string
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+	}
+
+	branchName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s:%s] %s", p.Repo.FullName, branchName, commitDesc)
+
+	var body string
+	// for each commit, generate a line with a link to it
+	for i, commit := range p.Commits {
+		body += fmt.Sprintf("%s: %s - %s", htmlLinkFormatter(commit.URL, commit.ID[:7]),
+			html.EscapeString(strings.Split(commit.Message, "\n")[0]), html.EscapeString(commit.Author.Name))
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			body += "<br>"
+		}
+	}
+
+	linkText, link := "View commits", p.CompareURL
+	if len(p.CompareURL) == 0 && len(p.Commits) > 0 {
+		linkText, link = "View commit", p.Commits[len(p.Commits)-1].URL
+	}
+
+	return createGoogleChatPayload("push", p.Repo, p.Pusher, title, "", body, linkText, link), nil
+}
+
+// PullRequest implements PayloadConvertor PullRequest method
+func (g *GoogleChatPayload) PullRequest(p *api.PullRequestPayload) (api.Payloader, error) {
+	title, issueTitle, body, _ := getPullRequestPayloadInfo(p, noneLinkFormatter, false)
+
+	return createGoogleChatPayload("pull-request", p.Repository, p.Sender, title, issueTitle, googleChatText(body), "View pull request", p.PullRequest.HTMLURL), nil
+}
+
+// Review implements PayloadConvertor Review method
+func (g *GoogleChatPayload) Review(p *api.PullRequestPayload, event models.HookEventType) (api.Payloader, error) {
+	var title, body string
+
+	switch p.Action {
+	case api.HookIssueReviewed:
+		action, err := parseHookPullRequestEventType(event)
+		if err != nil {
+			return nil, err
+		}
+
+		title = fmt.Sprintf("[%s] Pull request review %s", p.Repository.FullName, action)
+		if p.Review != nil {
+			body = googleChatText(p.Review.Content)
+		}
+	}
+	issueTitle := fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title)
+
+	return createGoogleChatPayload("pull-request-review", p.Repository, p.Sender, title, issueTitle, body, "View pull request", p.PullRequest.HTMLURL), nil
+}
+
+// Repository implements PayloadConvertor Repository method
+func (g *GoogleChatPayload) Repository(p *api.RepositoryPayload) (api.Payloader, error) {
+	var title, link string
+
+	switch p.Action {
+	case api.HookRepoCreated:
+		title = fmt.Sprintf("[%s] Repository created", p.Repository.FullName)
+		link = p.Repository.HTMLURL
+	case api.HookRepoDeleted:
+		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
+	}
+
+	return createGoogleChatPayload("repository", p.Repository, p.Sender, title, "", "", "View repository", link), nil
+}
+
+// GetGoogleChatPayload converts a Google Chat webhook into a GoogleChatPayload
+func GetGoogleChatPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	return convertPayloader(new(GoogleChatPayload), p, event)
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleChatIssuesPayloadOpened(t *testing.T) {
+	p := issueTestPayload()
+	p.Action = api.HookIssueOpened
+	p.Issue.HTMLURL = "http://localhost:3000/test/repo/issues/2"
+	p.Issue.Body = "it <crashes>\nagain"
+
+	pl, err := new(GoogleChatPayload).Issue(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	gp := pl.(*GoogleChatPayload)
+	require.Len(t, gp.CardsV2, 1)
+	card := gp.CardsV2[0].Card
+	assert.Equal(t, "[test/repo] Issue opened: #2 crash", card.Header.Title)
+	assert.Equal(t, "#2 crash", card.Header.Subtitle)
+	require.Len(t, card.Sections, 3)
+
+	require.Len(t, card.Sections[0].Widgets, 2)
+	assert.Equal(t, `<a href="http://localhost:3000/test/repo">test/repo</a>`, card.Sections[0].Widgets[0].DecoratedText.Text)
+	assert.Equal(t, "Sender", card.Sections[0].Widgets[1].DecoratedText.TopLabel)
+	assert.Equal(t, "it &lt;crashes&gt;<br>again", card.Sections[1].Widgets[0].TextParagraph.Text)
+	button := card.Sections[2].Widgets[0].ButtonList.Buttons[0]
+	assert.Equal(t, "View issue", button.Text)
+	assert.Equal(t, "http://localhost:3000/test/repo/issues/2", button.OnClick.OpenLink.URL)
+}
+
+func TestGoogleChatPushPayload(t *testing.T) {
+	p := &api.PushPayload{
+		Ref:        "refs/heads/master",
+		CompareURL: "http://localhost:3000/test/repo/compare/a...b",
+		Commits: []*api.PayloadCommit{{
+			ID:      "2020558fe2e34debb818a514715839cabd25e778",
+			Message: "commit message\n\nbody",
+			URL:     "http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778",
+			Author:  &api.PayloadUser{Name: "user1"},
+		}},
+		Repo: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			FullName: "test/repo",
+		},
+		Pusher: &api.User{UserName: "user1"},
+		Sender: &api.User{UserName: "user1"},
+	}
+
+	pl, err := GetGoogleChatPayload(p, models.HookEventPush, "")
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	card := pl.(*GoogleChatPayload).CardsV2[0].Card
+	assert.Equal(t, "[test/repo:master] 1 new commit", card.Header.Title)
+	require.Len(t, card.Sections, 3)
+	assert.Equal(t, `<a href="http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778">2020558</a>: commit message - user1`, card.Sections[1].Widgets[0].TextParagraph.Text)
+	assert.Equal(t, "http://localhost:3000/test/repo/compare/a...b", card.Sections[2].Widgets[0].ButtonList.Buttons[0].OnClick.OpenLink.URL)
+
+	data, err := pl.JSONPayload()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cardsV2"`)
+}
+
+func TestValidateGoogleChatURL(t *testing.T) {
+	assert.NoError(t, ValidateGoogleChatURL("https://chat.googleapis.com/v1/spaces/AAAA/messages?key=k&token=t"))
+	assert.Error(t, ValidateGoogleChatURL("https://chat.googleapis.com/v1/spaces/AAAA/messages?key=k"))
+	assert.Error(t, ValidateGoogleChatURL("https://chat.googleapis.com/v1/spaces/AAAA/messages"))
+}
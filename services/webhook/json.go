@@ -0,0 +1,108 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// reservedJSONHeaders are set by Gitea on every delivery and cannot be overridden
+var reservedJSONHeaders = []string{
+	"Content-Type",
+	"Content-Length",
+	"Host",
+	"X-Gitea-Delivery",
+	"X-Gitea-Event",
+	"X-Gitea-Signature",
+	"X-Gogs-Delivery",
+	"X-Gogs-Event",
+	"X-Gogs-Signature",
+	"X-Github-Delivery",
+	"X-Github-Event",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+}
+
+// JSONMeta contains the generic JSON webhook metadata
+type JSONMeta struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// GetJSONHook returns generic JSON webhook metadata
+func GetJSONHook(w *models.Webhook) *JSONMeta {
+	s := &JSONMeta{}
+	if len(w.Meta) == 0 {
+		return s
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetJSONHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// HeadersText returns the custom headers as "Name: value" lines
+func (m *JSONMeta) HeadersText() string {
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(m.Headers[name])
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ParseJSONHeaders parses custom headers given as one "Name: value" pair per line
+func ParseJSONHeaders(text string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		idx := strings.IndexByte(line, ':')
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+		name := http.CanonicalHeaderKey(strings.TrimSpace(line[:idx]))
+		if strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		for _, reserved := range reservedJSONHeaders {
+			if strings.EqualFold(name, reserved) {
+				return nil, fmt.Errorf("header %q cannot be overridden", name)
+			}
+		}
+		headers[name] = strings.TrimSpace(line[idx+1:])
+	}
+	return headers, nil
+}
+
+// addJSONHookHeaders adds the custom headers of a generic JSON webhook to the request
+func addJSONHookHeaders(t *models.HookTask, req *http.Request) {
+	w, err := models.GetWebhookByID(t.HookID)
+	if err != nil {
+		log.Error("GetWebhookByID: %v", err)
+		return
+	}
+	for name, value := range GetJSONHook(w).Headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONHeaders(t *testing.T) {
+	headers, err := ParseJSONHeaders("x-api-key: abc\n\n  Authorization: Bearer t:k  \n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Api-Key":     "abc",
+		"Authorization": "Bearer t:k",
+	}, headers)
+
+	meta := &JSONMeta{Headers: headers}
+	assert.Equal(t, "Authorization: Bearer t:k\nX-Api-Key: abc\n", meta.HeadersText())
+
+	_, err = ParseJSONHeaders("no-colon")
+	assert.Error(t, err)
+	_, err = ParseJSONHeaders("Bad Name: value")
+	assert.Error(t, err)
+	_, err = ParseJSONHeaders("x-gitea-signature: forged")
+	assert.Error(t, err)
+}
+
+func TestWebhook_GetJSONHook(t *testing.T) {
+	w := &models.Webhook{
+		Meta: `{"headers": {"X-Api-Key": "abc"}}`,
+	}
+	assert.Equal(t, map[string]string{"X-Api-Key": "abc"}, GetJSONHook(w).Headers)
+	assert.Empty(t, GetJSONHook(&models.Webhook{}).Headers)
+}
+
+func TestPrepareJSONWebhook(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	w := &models.Webhook{
+		ID:          100,
+		RepoID:      repo.ID,
+		URL:         "http://localhost/json",
+		HTTPMethod:  "POST",
+		ContentType: models.ContentTypeJSON,
+		Secret:      "s3cr3t",
+		Type:        models.JSON,
+		HookEvent:   &models.HookEvent{PushOnly: true},
+	}
+	p := &api.PushPayload{Ref: "refs/heads/master", Secret: "leftover"}
+	assert.NoError(t, prepareWebhook(w, repo.ID, models.HookEventPush, p))
+
+	task := models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: 100}).(*models.HookTask)
+	assert.Equal(t, models.JSON, task.Typ)
+	assert.NotEmpty(t, task.Signature)
+	assert.NotContains(t, task.PayloadContent, "s3cr3t")
+	assert.NotContains(t, task.PayloadContent, "leftover")
+	assert.Contains(t, task.PayloadContent, `"ref": "refs/heads/master"`)
+}
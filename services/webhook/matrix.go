@@ -155,6 +155,34 @@ func (m *MatrixPayloadUnsafe) Release(p *api.ReleasePayload) (api.Payloader, err
 	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
 }
 
+// Status implements PayloadConvertor Status method
+func (m *MatrixPayloadUnsafe) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (m *MatrixPayloadUnsafe) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, _ := getCommitCommentPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (m *MatrixPayloadUnsafe) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (m *MatrixPayloadUnsafe) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, _ := getMembershipPayloadInfo(p, MatrixLinkFormatter, true)
+
+	return getMatrixPayloadUnsafe(text, nil, m.AccessToken, m.MsgType), nil
+}
+
 // Push implements PayloadConvertor Push method
 func (m *MatrixPayloadUnsafe) Push(p *api.PushPayload) (api.Payloader, error) {
 	var commitDesc string
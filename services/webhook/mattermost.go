@@ -0,0 +1,318 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// MattermostMeta contains the mattermost metadata
+type MattermostMeta struct {
+	Channel  string `json:"channel"`
+	Username string `json:"username"`
+	IconURL  string `json:"icon_url"`
+	Color    string `json:"color"`
+}
+
+// GetMattermostHook returns mattermost metadata
+func GetMattermostHook(w *models.Webhook) *MattermostMeta {
+	s := &MattermostMeta{}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetMattermostHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// MattermostPayload represents an incoming webhook message of Mattermost
+// see: https://docs.mattermost.com/developer/webhooks-incoming.html
+type MattermostPayload struct {
+	Channel     string                 `json:"channel,omitempty"`
+	Text        string                 `json:"text,omitempty"`
+	Username    string                 `json:"username,omitempty"`
+	IconURL     string                 `json:"icon_url,omitempty"`
+	Attachments []MattermostAttachment `json:"attachments,omitempty"`
+
+	Color string `json:"-"`
+}
+
+// MattermostAttachment represents a message attachment of Mattermost
+// see: https://docs.mattermost.com/developer/message-attachments.html
+type MattermostAttachment struct {
+	Fallback   string `json:"fallback"`
+	Color      string `json:"color,omitempty"`
+	Pretext    string `json:"pretext,omitempty"`
+	AuthorName string `json:"author_name,omitempty"`
+	AuthorLink string `json:"author_link,omitempty"`
+	AuthorIcon string `json:"author_icon,omitempty"`
+	Title      string `json:"title,omitempty"`
+	TitleLink  string `json:"title_link,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
+
+// SetSecret sets the mattermost secret
+func (m *MattermostPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the MattermostPayload to json
+func (m *MattermostPayload) JSONPayload() ([]byte, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+var mattermostMarkdownEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// MattermostLinkFormatter creates a markdown link compatible with Mattermost
+func MattermostLinkFormatter(url string, text string) string {
+	return fmt.Sprintf("[%s](%s)", mattermostMarkdownEscaper.Replace(text), url)
+}
+
+// MattermostLinkToRef Mattermost-formatter link to a repo ref
+func MattermostLinkToRef(repoURL, ref string) string {
+	url := git.RefURL(repoURL, ref)
+	refName := git.RefEndName(ref)
+	return MattermostLinkFormatter(url, refName)
+}
+
+// mattermostColor formats a color as expected by Mattermost attachments
+func mattermostColor(c int) string {
+	return fmt.Sprintf("#%06x", c)
+}
+
+// createPayload creates a message with a single attachment. The text is shown
+// above the colored bar of the attachment if it has a title or a body.
+func (m *MattermostPayload) createPayload(sender *api.User, text string, color int, title, titleLink, body string) *MattermostPayload {
+	attachment := MattermostAttachment{
+		Fallback:   text,
+		Color:      mattermostColor(color),
+		AuthorName: sender.UserName,
+		AuthorLink: setting.AppURL + sender.UserName,
+		AuthorIcon: sender.AvatarURL,
+		Title:      title,
+		TitleLink:  titleLink,
+		Text:       body,
+	}
+	if title == "" && body == "" {
+		attachment.Text = text
+	} else {
+		attachment.Pretext = text
+	}
+
+	return &MattermostPayload{
+		Channel:     m.Channel,
+		Username:    m.Username,
+		IconURL:     m.IconURL,
+		Attachments: []MattermostAttachment{attachment},
+	}
+}
+
+var (
+	_ PayloadConvertor = &MattermostPayload{}
+)
+
+// Create implements PayloadConvertor Create method
+func (m *MattermostPayload) Create(p *api.CreatePayload) (api.Payloader, error) {
+	repoLink := MattermostLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	refLink := MattermostLinkToRef(p.Repo.HTMLURL, p.Ref)
+	text := fmt.Sprintf("[%s:%s] %s created by %s", repoLink, refLink, p.RefType, p.Sender.UserName)
+
+	return m.createPayload(p.Sender, text, greenColor, "", "", ""), nil
+}
+
+// Delete implements PayloadConvertor Delete method
+func (m *MattermostPayload) Delete(p *api.DeletePayload) (api.Payloader, error) {
+	refName := git.RefEndName(p.Ref)
+	repoLink := MattermostLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	text := fmt.Sprintf("[%s:%s] %s deleted by %s", repoLink, refName, p.RefType, p.Sender.UserName)
+
+	return m.createPayload(p.Sender, text, redColor, "", "", ""), nil
+}
+
+// Fork implements PayloadConvertor Fork method
+func (m *MattermostPayload) Fork(p *api.ForkPayload) (api.Payloader, error) {
+	baseLink := MattermostLinkFormatter(p.Forkee.HTMLURL, p.Forkee.FullName)
+	forkLink := MattermostLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	text := fmt.Sprintf("%s is forked to %s", baseLink, forkLink)
+
+	return m.createPayload(p.Sender, text, greyColor, "", "", ""), nil
+}
+
+// Issue implements PayloadConvertor Issue method
+func (m *MattermostPayload) Issue(p *api.IssuePayload) (api.Payloader, error) {
+	text, issueTitle, attachmentText, color := getIssuesPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, issueTitle, p.Issue.HTMLURL, attachmentText), nil
+}
+
+// IssueComment implements PayloadConvertor IssueComment method
+func (m *MattermostPayload) IssueComment(p *api.IssueCommentPayload) (api.Payloader, error) {
+	text, issueTitle, color := getIssueCommentPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, issueTitle, p.Comment.HTMLURL, p.Comment.Body), nil
+}
+
+// Release implements PayloadConvertor Release method
+func (m *MattermostPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
+	text, color := getReleasePayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, p.Release.Title, p.Release.HTMLURL, p.Release.Note), nil
+}
+
+// Status implements PayloadConvertor Status method
+func (m *MattermostPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getStatusPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, p.Status.Context, p.Repository.HTMLURL+"/commit/"+p.SHA, p.Status.Description), nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (m *MattermostPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, color := getCommitCommentPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, base.ShortSha(p.Comment.CommitID), p.Comment.HTMLURL, p.Comment.Body), nil
+}
+
+// Team implements PayloadConvertor Team method
+func (m *MattermostPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, color := getTeamPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, p.Team.Name, orgTeamURL(p.Organization, p.Team), p.Team.Description), nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (m *MattermostPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, color := getMembershipPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, p.Member.UserName, membershipURL(p), ""), nil
+}
+
+// Push implements PayloadConvertor Push method
+func (m *MattermostPayload) Push(p *api.PushPayload) (api.Payloader, error) {
+	var (
+		commitDesc   string
+		commitString string
+	)
+
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+	}
+	if len(p.CompareURL) > 0 {
+		commitString = MattermostLinkFormatter(p.CompareURL, commitDesc)
+	} else {
+		commitString = commitDesc
+	}
+
+	repoLink := MattermostLinkFormatter(p.Repo.HTMLURL, p.Repo.FullName)
+	branchLink := MattermostLinkToRef(p.Repo.HTMLURL, p.Ref)
+	text := fmt.Sprintf("[%s:%s] %s pushed by %s", repoLink, branchLink, commitString, p.Pusher.UserName)
+
+	var attachmentText string
+	// for each commit, generate attachment text
+	for i, commit := range p.Commits {
+		attachmentText += fmt.Sprintf("%s: %s - %s", MattermostLinkFormatter(commit.URL, commit.ID[:7]), strings.Split(commit.Message, "\n")[0], commit.Author.Name)
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			attachmentText += "\n"
+		}
+	}
+
+	barColor := greenColor
+	if len(m.Color) > 0 {
+		barColor = color(m.Color)
+	}
+
+	return m.createPayload(p.Pusher, text, barColor, p.Repo.FullName, p.Repo.HTMLURL, attachmentText), nil
+}
+
+// PullRequest implements PayloadConvertor PullRequest method
+func (m *MattermostPayload) PullRequest(p *api.PullRequestPayload) (api.Payloader, error) {
+	text, issueTitle, attachmentText, color := getPullRequestPayloadInfo(p, MattermostLinkFormatter, true)
+
+	return m.createPayload(p.Sender, text, color, issueTitle, p.PullRequest.HTMLURL, attachmentText), nil
+}
+
+// Review implements PayloadConvertor Review method
+func (m *MattermostPayload) Review(p *api.PullRequestPayload, event models.HookEventType) (api.Payloader, error) {
+	senderLink := MattermostLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	title := fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title)
+	titleLink := fmt.Sprintf("%s/pulls/%d", p.Repository.HTMLURL, p.Index)
+	repoLink := MattermostLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	var text, content string
+	color := yellowColor
+
+	switch p.Action {
+	case api.HookIssueReviewed:
+		action, err := parseHookPullRequestEventType(event)
+		if err != nil {
+			return nil, err
+		}
+
+		text = fmt.Sprintf("[%s] Pull request review %s: %s by %s", repoLink, action, MattermostLinkFormatter(titleLink, title), senderLink)
+		switch event {
+		case models.HookEventPullRequestReviewApproved:
+			color = greenColor
+		case models.HookEventPullRequestReviewRejected:
+			color = redColor
+		}
+		if p.Review != nil {
+			content = p.Review.Content
+		}
+	}
+
+	return m.createPayload(p.Sender, text, color, title, titleLink, content), nil
+}
+
+// Repository implements PayloadConvertor Repository method
+func (m *MattermostPayload) Repository(p *api.RepositoryPayload) (api.Payloader, error) {
+	senderLink := MattermostLinkFormatter(setting.AppURL+p.Sender.UserName, p.Sender.UserName)
+	repoLink := MattermostLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	var text string
+	var color int
+
+	switch p.Action {
+	case api.HookRepoCreated:
+		text = fmt.Sprintf("[%s] Repository created by %s", repoLink, senderLink)
+		color = greenColor
+	case api.HookRepoDeleted:
+		text = fmt.Sprintf("[%s] Repository deleted by %s", repoLink, senderLink)
+		color = redColor
+	}
+
+	return m.createPayload(p.Sender, text, color, "", "", ""), nil
+}
+
+// GetMattermostPayload converts a mattermost webhook into a MattermostPayload
+func GetMattermostPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
+	s := new(MattermostPayload)
+
+	mattermost := &MattermostMeta{}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal([]byte(meta), &mattermost); err != nil {
+		return s, errors.New("GetMattermostPayload meta json:" + err.Error())
+	}
+
+	s.Channel = mattermost.Channel
+	s.Username = mattermost.Username
+	s.IconURL = mattermost.IconURL
+	s.Color = mattermost.Color
+
+	return convertPayloader(s, p, event)
+}
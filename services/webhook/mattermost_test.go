@@ -0,0 +1,102 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMattermostIssuesPayloadOpened(t *testing.T) {
+	p := issueTestPayload()
+	p.Action = api.HookIssueOpened
+
+	m := new(MattermostPayload)
+	m.Channel = "town-square"
+	m.Username = "gitea"
+
+	pl, err := m.Issue(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	mp := pl.(*MattermostPayload)
+	assert.Equal(t, "town-square", mp.Channel)
+	assert.Equal(t, "gitea", mp.Username)
+	require.Len(t, mp.Attachments, 1)
+	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Issue opened: [#2 crash](http://localhost:3000/test/repo/issues/2) by [user1](https://try.gitea.io/user1)", mp.Attachments[0].Pretext)
+	assert.Equal(t, "#2 crash", mp.Attachments[0].Title)
+	assert.Equal(t, "#eb6420", mp.Attachments[0].Color)
+
+	p.Action = api.HookIssueClosed
+	pl, err = m.Issue(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+	assert.Equal(t, "#ff3232", pl.(*MattermostPayload).Attachments[0].Color)
+}
+
+func TestMattermostPullRequestPayload(t *testing.T) {
+	p := pullRequestTestPayload()
+
+	pl, err := new(MattermostPayload).PullRequest(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	mp := pl.(*MattermostPayload)
+	require.Len(t, mp.Attachments, 1)
+	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Pull request opened: [#2 Fix bug](http://localhost:3000/test/repo/pulls/12) by [user1](https://try.gitea.io/user1)", mp.Attachments[0].Pretext)
+	assert.Equal(t, "fixes bug #2", mp.Attachments[0].Text)
+	assert.Equal(t, "#1ac600", mp.Attachments[0].Color)
+}
+
+func TestMattermostReleasePayload(t *testing.T) {
+	p := pullReleaseTestPayload()
+
+	pl, err := new(MattermostPayload).Release(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	mp := pl.(*MattermostPayload)
+	require.Len(t, mp.Attachments, 1)
+	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo)] Release created: [v1.0](http://localhost:3000/test/repo/src/v1.0) by [user1](https://try.gitea.io/user1)", mp.Attachments[0].Pretext)
+	assert.Equal(t, "First stable release", mp.Attachments[0].Title)
+}
+
+func TestMattermostPushPayload(t *testing.T) {
+	p := &api.PushPayload{
+		Ref:        "refs/heads/master",
+		CompareURL: "http://localhost:3000/test/repo/compare/a...b",
+		Commits: []*api.PayloadCommit{{
+			ID:      "2020558fe2e34debb818a514715839cabd25e778",
+			Message: "commit message\n\nbody",
+			URL:     "http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778",
+			Author:  &api.PayloadUser{Name: "user1"},
+		}},
+		Repo: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			FullName: "test/repo",
+		},
+		Pusher: &api.User{UserName: "user1"},
+		Sender: &api.User{UserName: "user1"},
+	}
+
+	pl, err := GetMattermostPayload(p, models.HookEventPush, `{"channel":"dev","color":"#dd4b39"}`)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	mp := pl.(*MattermostPayload)
+	assert.Equal(t, "dev", mp.Channel)
+	require.Len(t, mp.Attachments, 1)
+	assert.Equal(t, "[[test/repo](http://localhost:3000/test/repo):[master](http://localhost:3000/test/repo/src/branch/master)] [1 new commit](http://localhost:3000/test/repo/compare/a...b) pushed by user1", mp.Attachments[0].Pretext)
+	assert.Equal(t, "[2020558](http://localhost:3000/test/repo/commit/2020558fe2e34debb818a514715839cabd25e778): commit message - user1", mp.Attachments[0].Text)
+	assert.Equal(t, "#dd4b39", mp.Attachments[0].Color)
+}
+
+func TestMattermostLinkFormatter(t *testing.T) {
+	assert.Equal(t, `[\[WIP\] fix](http://localhost:3000)`, MattermostLinkFormatter("http://localhost:3000", "[WIP] fix"))
+}
@@ -558,6 +558,185 @@ func (m *MSTeamsPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	}, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (m *MSTeamsPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, color := getStatusPayloadInfo(p, noneLinkFormatter, false)
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      text,
+		Summary:    text,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Text:             p.Status.Description,
+				Facts: []MSTeamsFact{
+					{
+						Name:  "Repository:",
+						Value: p.Repository.FullName,
+					},
+					{
+						Name:  "Commit:",
+						Value: p.SHA,
+					},
+				},
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: p.Repository.HTMLURL + "/commit/" + p.SHA,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (m *MSTeamsPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, color := getCommitCommentPayloadInfo(p, noneLinkFormatter, false)
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      text,
+		Summary:    text,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Text:             p.Comment.Body,
+				Facts: []MSTeamsFact{
+					{
+						Name:  "Repository:",
+						Value: p.Repository.FullName,
+					},
+					{
+						Name:  "Commit:",
+						Value: p.Comment.CommitID,
+					},
+				},
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: p.Comment.HTMLURL,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (m *MSTeamsPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, color := getTeamPayloadInfo(p, noneLinkFormatter, false)
+	facts := []MSTeamsFact{
+		{
+			Name:  "Organization:",
+			Value: p.Organization.UserName,
+		},
+		{
+			Name:  "Team:",
+			Value: p.Team.Name,
+		},
+	}
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      text,
+		Summary:    text,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Text:             p.Team.Description,
+				Facts:            facts,
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: orgTeamURL(p.Organization, p.Team),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (m *MSTeamsPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, color := getMembershipPayloadInfo(p, noneLinkFormatter, false)
+	facts := []MSTeamsFact{
+		{
+			Name:  "Organization:",
+			Value: p.Organization.UserName,
+		},
+		{
+			Name:  "Member:",
+			Value: p.Member.UserName,
+		},
+	}
+	if p.Team != nil {
+		facts = append(facts, MSTeamsFact{
+			Name:  "Team:",
+			Value: p.Team.Name,
+		})
+	}
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      text,
+		Summary:    text,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    p.Sender.FullName,
+				ActivitySubtitle: p.Sender.UserName,
+				ActivityImage:    p.Sender.AvatarURL,
+				Facts:            facts,
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: membershipURL(p),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 // GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
 func GetMSTeamsPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(MSTeamsPayload), p, event)
@@ -22,6 +22,10 @@ type PayloadConvertor interface {
 	Review(*api.PullRequestPayload, models.HookEventType) (api.Payloader, error)
 	Repository(*api.RepositoryPayload) (api.Payloader, error)
 	Release(*api.ReleasePayload) (api.Payloader, error)
+	Status(*api.CommitStatusPayload) (api.Payloader, error)
+	CommitComment(*api.CommitCommentPayload) (api.Payloader, error)
+	Team(*api.TeamPayload) (api.Payloader, error)
+	Membership(*api.MembershipPayload) (api.Payloader, error)
 }
 
 func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEventType) (api.Payloader, error) {
@@ -51,6 +55,14 @@ func convertPayloader(s PayloadConvertor, p api.Payloader, event models.HookEven
 		return s.Repository(p.(*api.RepositoryPayload))
 	case models.HookEventRelease:
 		return s.Release(p.(*api.ReleasePayload))
+	case models.HookEventStatus:
+		return s.Status(p.(*api.CommitStatusPayload))
+	case models.HookEventCommitComment:
+		return s.CommitComment(p.(*api.CommitCommentPayload))
+	case models.HookEventTeam:
+		return s.Team(p.(*api.TeamPayload))
+	case models.HookEventMembership:
+		return s.Membership(p.(*api.MembershipPayload))
 	}
 	return s, nil
 }
@@ -0,0 +1,74 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"math/rand"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// retryJitter is the fraction by which the delay between two attempts is randomized
+const retryJitter = 0.2
+
+// retryBackoff returns the delay before the next delivery attempt after the
+// given attempt failed, it grows exponentially and is capped at the configured maximum.
+func retryBackoff(attempt int) time.Duration {
+	delay := setting.Webhook.RetryBackoff
+	for i := 1; i < attempt && delay < setting.Webhook.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > setting.Webhook.MaxBackoff {
+		delay = setting.Webhook.MaxBackoff
+	}
+	return time.Duration(float64(delay) * (1 - retryJitter + 2*retryJitter*rand.Float64()))
+}
+
+// retryCheckInterval returns how often due retries are looked up
+func retryCheckInterval() time.Duration {
+	interval := setting.Webhook.RetryBackoff
+	if interval > time.Minute {
+		interval = time.Minute
+	} else if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// handleFailedDelivery schedules another attempt for a failed task, or
+// deactivates the webhook once all attempts have failed if configured so.
+func handleFailedDelivery(t *models.HookTask, w *models.Webhook) {
+	if !w.IsActive {
+		return
+	}
+
+	if t.Attempt < setting.Webhook.MaxAttempts {
+		scheduled := timeutil.TimeStamp(time.Now().Add(retryBackoff(t.Attempt)).Unix())
+		retry, err := models.CreateHookTaskRetry(t, scheduled)
+		if err != nil {
+			log.Error("CreateHookTaskRetry [%d]: %v", t.ID, err)
+			return
+		}
+		log.Trace("Hook delivery %s failed, attempt %d scheduled at %v as %s", t.UUID, retry.Attempt, scheduled, retry.UUID)
+		return
+	}
+
+	if !setting.Webhook.AutoDisable || setting.Webhook.MaxAttempts <= 1 {
+		return
+	}
+
+	w.IsActive = false
+	if err := models.UpdateWebhook(w); err != nil {
+		log.Error("UpdateWebhook [%d]: %v", w.ID, err)
+		return
+	}
+	if err := models.CreateNotice(models.NoticeRepository, "Webhook [%d] to %s has been deactivated after %d failed delivery attempts", w.ID, w.URL, t.Attempt); err != nil {
+		log.Error("CreateNotice: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	defer func(backoff, max time.Duration) {
+		setting.Webhook.RetryBackoff = backoff
+		setting.Webhook.MaxBackoff = max
+	}(setting.Webhook.RetryBackoff, setting.Webhook.MaxBackoff)
+	setting.Webhook.RetryBackoff = 10 * time.Second
+	setting.Webhook.MaxBackoff = time.Minute
+
+	kases := map[int]time.Duration{
+		1:   10 * time.Second,
+		2:   20 * time.Second,
+		3:   40 * time.Second,
+		4:   time.Minute,
+		100: time.Minute,
+	}
+	for attempt, expected := range kases {
+		delay := retryBackoff(attempt)
+		assert.GreaterOrEqual(t, int64(delay), int64(float64(expected)*(1-retryJitter)), "attempt %d", attempt)
+		assert.LessOrEqual(t, int64(delay), int64(float64(expected)*(1+retryJitter)), "attempt %d", attempt)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// hookEventPayloads maps the hook events to the payloads they are dispatched with
+var hookEventPayloads = map[models.HookEventType]api.Payloader{
+	models.HookEventCreate:                    &api.CreatePayload{},
+	models.HookEventDelete:                    &api.DeletePayload{},
+	models.HookEventFork:                      &api.ForkPayload{},
+	models.HookEventPush:                      &api.PushPayload{},
+	models.HookEventIssues:                    &api.IssuePayload{},
+	models.HookEventIssueAssign:               &api.IssuePayload{},
+	models.HookEventIssueLabel:                &api.IssuePayload{},
+	models.HookEventIssueMilestone:            &api.IssuePayload{},
+	models.HookEventIssueComment:              &api.IssueCommentPayload{},
+	models.HookEventPullRequest:               &api.PullRequestPayload{},
+	models.HookEventPullRequestAssign:         &api.PullRequestPayload{},
+	models.HookEventPullRequestLabel:          &api.PullRequestPayload{},
+	models.HookEventPullRequestMilestone:      &api.PullRequestPayload{},
+	models.HookEventPullRequestComment:        &api.IssueCommentPayload{},
+	models.HookEventPullRequestReviewApproved: &api.PullRequestPayload{},
+	models.HookEventPullRequestReviewRejected: &api.PullRequestPayload{},
+	models.HookEventPullRequestReviewComment:  &api.PullRequestPayload{},
+	models.HookEventPullRequestSync:           &api.PullRequestPayload{},
+	models.HookEventRepository:                &api.RepositoryPayload{},
+	models.HookEventRelease:                   &api.ReleasePayload{},
+	models.HookEventCommitComment:             &api.CommitCommentPayload{},
+	models.HookEventTeam:                      &api.TeamPayload{},
+	models.HookEventMembership:                &api.MembershipPayload{},
+}
+
+// schemaExtraProperties lists the properties added by custom JSON marshalers
+var schemaExtraProperties = map[reflect.Type]map[string]interface{}{
+	reflect.TypeOf(api.User{}): {
+		"username": map[string]interface{}{"type": "string"},
+	},
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// PayloadSchema returns the JSON schema of the payload the given hook event is
+// dispatched with, or nil if the event is unknown.
+// The schema is generated from the payload structs and their JSON tags.
+func PayloadSchema(event models.HookEventType) map[string]interface{} {
+	p, ok := hookEventPayloads[event]
+	if !ok {
+		return nil
+	}
+
+	g := &schemaGenerator{definitions: map[string]interface{}{}}
+	t := reflect.TypeOf(p).Elem()
+	root := g.schema(t)
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["title"] = string(event)
+	root["definitions"] = g.definitions
+	return root
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// schema returns the schema of a type, structs are added to the definitions and referenced
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// byte slices are encoded as base64 strings
+			return nullable(map[string]interface{}{"type": "string"})
+		}
+		return nullable(map[string]interface{}{"type": "array", "items": g.schema(t.Elem())})
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())})
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.definitions[t.Name()]; !ok {
+			// register the name first, the type might be recursive
+			g.definitions[t.Name()] = nil
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	}
+	// interfaces can hold any value
+	return map[string]interface{}{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	g.addFields(t, properties, &required)
+	for name, prop := range schemaExtraProperties[t] {
+		properties[name] = prop
+		required = append(required, name)
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.IndexByte(tag, ','); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if field.Anonymous && len(name) == 0 {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, properties, required)
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			// unexported
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+
+		properties[name] = g.schema(field.Type)
+		omitEmpty := false
+		for _, opt := range strings.Split(opts, ",") {
+			omitEmpty = omitEmpty || opt == "omitempty"
+		}
+		// structs are never omitted by encoding/json
+		if !omitEmpty || field.Type.Kind() == reflect.Struct {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows a value to be null in addition to the given schema
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}},
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// validateSchema checks a decoded JSON value against the subset of JSON schema generated by PayloadSchema
+func validateSchema(t *testing.T, root, schema map[string]interface{}, value interface{}, path string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def, ok := root["definitions"].(map[string]interface{})[name].(map[string]interface{})
+		if assert.True(t, ok, "%s: missing definition %s", path, name) {
+			validateSchema(t, root, def, value, path)
+		}
+		return
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		if value != nil {
+			validateSchema(t, root, anyOf[0].(map[string]interface{}), value, path)
+		}
+		return
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !assert.True(t, ok, "%s: expected an object, got %T", path, value) {
+			return
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			for key, v := range obj {
+				validateSchema(t, root, additional, v, path+"."+key)
+			}
+			return
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for key, v := range obj {
+			prop, ok := properties[key].(map[string]interface{})
+			if assert.True(t, ok, "%s: property %s is not in the schema", path, key) {
+				validateSchema(t, root, prop, v, path+"."+key)
+			}
+		}
+		for _, key := range schema["required"].([]string) {
+			_, ok := obj[key]
+			assert.True(t, ok, "%s: required property %s is missing", path, key)
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if assert.True(t, ok, "%s: expected an array, got %T", path, value) {
+			for i, v := range arr {
+				validateSchema(t, root, schema["items"].(map[string]interface{}), v, fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case "string":
+		assert.IsType(t, "", value, path)
+	case "boolean":
+		assert.IsType(t, true, value, path)
+	case "integer", "number":
+		assert.IsType(t, float64(0), value, path)
+	}
+}
+
+func TestPayloadSchema(t *testing.T) {
+	assert.Nil(t, PayloadSchema("unknown"))
+
+	// every event must be documented
+	for _, event := range []models.HookEventType{
+		models.HookEventCreate, models.HookEventDelete, models.HookEventFork, models.HookEventPush,
+		models.HookEventIssues, models.HookEventIssueAssign, models.HookEventIssueLabel, models.HookEventIssueMilestone,
+		models.HookEventIssueComment, models.HookEventPullRequest, models.HookEventPullRequestAssign,
+		models.HookEventPullRequestLabel, models.HookEventPullRequestMilestone, models.HookEventPullRequestComment,
+		models.HookEventPullRequestReviewApproved, models.HookEventPullRequestReviewRejected,
+		models.HookEventPullRequestReviewComment, models.HookEventPullRequestSync,
+		models.HookEventRepository, models.HookEventRelease,
+	} {
+		schema := PayloadSchema(event)
+		if assert.NotNil(t, schema, event) {
+			assert.EqualValues(t, event, schema["title"])
+			assert.Contains(t, schema["definitions"], "User")
+		}
+	}
+
+	// the dispatched payloads must validate against their schema
+	for event, p := range map[models.HookEventType]api.Payloader{
+		models.HookEventIssues:             issueTestPayload(),
+		models.HookEventIssueComment:       issueCommentTestPayload(),
+		models.HookEventPullRequestComment: pullRequestCommentTestPayload(),
+		models.HookEventRelease:            pullReleaseTestPayload(),
+		models.HookEventPullRequest:        pullRequestTestPayload(),
+		models.HookEventPush: &api.PushPayload{
+			Ref:     "refs/heads/master",
+			Commits: []*api.PayloadCommit{{ID: "2020558fe2e34debb818a514715839cabd25e778", Author: &api.PayloadUser{Name: "user1"}}},
+			Repo:    &api.Repository{FullName: "test/repo", Owner: &api.User{UserName: "test"}},
+			Pusher:  &api.User{UserName: "user1"},
+			Sender:  &api.User{UserName: "user1"},
+		},
+		models.HookEventFork: &api.ForkPayload{
+			Forkee: &api.Repository{FullName: "test/repo", Parent: &api.Repository{FullName: "test/base"}},
+			Repo:   &api.Repository{FullName: "test/repo2"},
+			Sender: &api.User{UserName: "user1"},
+		},
+	} {
+		data, err := p.JSONPayload()
+		assert.NoError(t, err)
+
+		var value interface{}
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		assert.NoError(t, json.Unmarshal(data, &value))
+
+		schema := PayloadSchema(event)
+		validateSchema(t, schema, schema, value, string(event))
+	}
+}
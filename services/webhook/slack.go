@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -199,6 +200,60 @@ func (s *SlackPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
 	}, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (s *SlackPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, SlackLinkFormatter, true)
+
+	return &SlackPayload{
+		Channel:  s.Channel,
+		Text:     text,
+		Username: s.Username,
+		IconURL:  s.IconURL,
+	}, nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (s *SlackPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, color := getCommitCommentPayloadInfo(p, SlackLinkFormatter, true)
+
+	return &SlackPayload{
+		Channel:  s.Channel,
+		Text:     text,
+		Username: s.Username,
+		IconURL:  s.IconURL,
+		Attachments: []SlackAttachment{{
+			Color:     fmt.Sprintf("%x", color),
+			Title:     base.ShortSha(p.Comment.CommitID),
+			TitleLink: p.Comment.HTMLURL,
+			Text:      SlackTextFormatter(p.Comment.Body),
+		}},
+	}, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (s *SlackPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, SlackLinkFormatter, true)
+
+	return &SlackPayload{
+		Channel:  s.Channel,
+		Text:     text,
+		Username: s.Username,
+		IconURL:  s.IconURL,
+	}, nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (s *SlackPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, _ := getMembershipPayloadInfo(p, SlackLinkFormatter, true)
+
+	return &SlackPayload{
+		Channel:  s.Channel,
+		Text:     text,
+		Username: s.Username,
+		IconURL:  s.IconURL,
+	}, nil
+}
+
 // Push implements PayloadConvertor Push method
 func (s *SlackPayload) Push(p *api.PushPayload) (api.Payloader, error) {
 	// n new commits
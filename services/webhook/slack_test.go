@@ -67,6 +67,62 @@ func TestSlackReleasePayload(t *testing.T) {
 	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Release created: <http://localhost:3000/test/repo/src/v1.0|v1.0> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
 }
 
+func TestSlackStatusPayload(t *testing.T) {
+	p := statusTestPayload()
+	s := new(SlackPayload)
+	s.Username = p.Sender.UserName
+
+	pl, err := s.Status(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] Commit <http://localhost:3000/test/repo/commit/2020fbde0c2b0f8f0b0e1dd3d9a8c23bf4b8a65d|2020fbde0c> is failure: <http://ci.example.com/builds/2|ci/test> failure by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackTeamPayload(t *testing.T) {
+	p := teamTestPayload()
+	s := new(SlackPayload)
+	s.Username = p.Sender.UserName
+
+	pl, err := s.Team(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<https://try.gitea.io/org3|org3>] Team created: <https://try.gitea.io/org/org3/teams/developers|Developers> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackMembershipPayload(t *testing.T) {
+	p := membershipTestPayload()
+	s := new(SlackPayload)
+	s.Username = p.Sender.UserName
+
+	pl, err := s.Membership(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<https://try.gitea.io/org3|org3>] Member <https://try.gitea.io/user2|user2> added to team <https://try.gitea.io/org/org3/teams/developers|Developers> by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+
+	p.Action = api.HookMembershipRemoved
+	p.Scope = api.HookMembershipScopeOrganization
+	p.Team = nil
+	pl, err = s.Membership(p)
+	require.NoError(t, err)
+	assert.Equal(t, "[<https://try.gitea.io/org3|org3>] Member <https://try.gitea.io/user2|user2> removed from the organization by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+}
+
+func TestSlackCommitCommentPayload(t *testing.T) {
+	p := commitCommentTestPayload()
+	s := new(SlackPayload)
+	s.Username = p.Sender.UserName
+
+	pl, err := s.CommitComment(p)
+	require.NoError(t, err)
+	require.NotNil(t, pl)
+
+	assert.Equal(t, "[<http://localhost:3000/test/repo|test/repo>] New comment on commit <http://localhost:3000/test/repo/commit/2020fbde0c2b0f8f0b0e1dd3d9a8c23bf4b8a65d#commitcomment-4|2020fbde0c> at README.md:3 by <https://try.gitea.io/user1|user1>", pl.(*SlackPayload).Text)
+	assert.Equal(t, "typo here", pl.(*SlackPayload).Attachments[0].Text)
+}
+
 func TestSlackPullRequestPayload(t *testing.T) {
 	p := pullRequestTestPayload()
 	s := new(SlackPayload)
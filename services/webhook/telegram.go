@@ -208,6 +208,42 @@ func (t *TelegramPayload) Release(p *api.ReleasePayload) (api.Payloader, error)
 	}, nil
 }
 
+// Status implements PayloadConvertor Status method
+func (t *TelegramPayload) Status(p *api.CommitStatusPayload) (api.Payloader, error) {
+	text, _ := getStatusPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// CommitComment implements PayloadConvertor CommitComment method
+func (t *TelegramPayload) CommitComment(p *api.CommitCommentPayload) (api.Payloader, error) {
+	text, _ := getCommitCommentPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n" + p.Comment.Body,
+	}, nil
+}
+
+// Team implements PayloadConvertor Team method
+func (t *TelegramPayload) Team(p *api.TeamPayload) (api.Payloader, error) {
+	text, _ := getTeamPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
+// Membership implements PayloadConvertor Membership method
+func (t *TelegramPayload) Membership(p *api.MembershipPayload) (api.Payloader, error) {
+	text, _ := getMembershipPayloadInfo(p, htmlLinkFormatter, true)
+
+	return &TelegramPayload{
+		Message: text + "\n",
+	}, nil
+}
+
 // GetTelegramPayload converts a telegram webhook into a TelegramPayload
 func GetTelegramPayload(p api.Payloader, event models.HookEventType, meta string) (api.Payloader, error) {
 	return convertPayloader(new(TelegramPayload), p, event)
@@ -6,6 +6,7 @@ package webhook
 
 import (
 	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -17,7 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/sync"
-	"github.com/gobwas/glob"
+	"code.gitea.io/gitea/modules/util"
 )
 
 type webhook struct {
@@ -55,6 +56,14 @@ var (
 			name:           models.MATRIX,
 			payloadCreator: GetMatrixPayload,
 		},
+		models.MATTERMOST: {
+			name:           models.MATTERMOST,
+			payloadCreator: GetMattermostPayload,
+		},
+		models.GOOGLECHAT: {
+			name:           models.GOOGLECHAT,
+			payloadCreator: GetGoogleChatPayload,
+		},
 	}
 )
 
@@ -65,7 +74,7 @@ func RegisterWebhook(name string, webhook *webhook) {
 
 // IsValidHookTaskType returns true if a webhook registered
 func IsValidHookTaskType(name string) bool {
-	if name == models.GITEA || name == models.GOGS {
+	if name == models.GITEA || name == models.GOGS || name == models.JSON {
 		return true
 	}
 	_, ok := webhooks[models.HookTaskType(name)]
@@ -94,9 +103,19 @@ func getPayloadBranch(p api.Payloader) string {
 	return ""
 }
 
+// Redeliver sends the payload of a past delivery of a webhook once more.
+func Redeliver(t *models.HookTask) (*models.HookTask, error) {
+	redelivery, err := models.CreateHookTaskRedelivery(t)
+	if err != nil {
+		return nil, err
+	}
+	go hookQueue.Add(t.RepoID)
+	return redelivery, nil
+}
+
 // PrepareWebhook adds special webhook to task queue for given payload.
 func PrepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
-	if err := prepareWebhook(w, repo, event, p); err != nil {
+	if err := prepareWebhook(w, repo.ID, event, p); err != nil {
 		return err
 	}
 
@@ -109,17 +128,19 @@ func checkBranch(w *models.Webhook, branch string) bool {
 		return true
 	}
 
-	g, err := glob.Compile(w.BranchFilter)
+	f, err := util.ParseBranchFilter(w.BranchFilter)
 	if err != nil {
 		// should not really happen as BranchFilter is validated
 		log.Error("CheckBranch failed: %s", err)
 		return false
 	}
 
-	return g.Match(branch)
+	return f.Match(branch)
 }
 
-func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.HookEventType, p api.Payloader) error {
+// prepareWebhook creates the task delivering the payload of the event with the webhook, the tasks of
+// events of organizations, which are not about a repository, have no repoID
+func prepareWebhook(w *models.Webhook, repoID int64, event models.HookEventType, p api.Payloader) error {
 	// Skip sending if webhooks are disabled.
 	if setting.DisableWebhooks {
 		return nil
@@ -138,7 +159,7 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	// Avoid sending "0 new commits" to non-integration relevant webhooks (e.g. slack, discord, etc.).
 	// Integration webhooks (e.g. drone) still receive the required data.
 	if pushEvent, ok := p.(*api.PushPayload); ok &&
-		w.Type != models.GITEA && w.Type != models.GOGS &&
+		w.Type != models.GITEA && w.Type != models.GOGS && w.Type != models.JSON &&
 		len(pushEvent.Commits) == 0 {
 		return nil
 	}
@@ -160,6 +181,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		if err != nil {
 			return fmt.Errorf("create payload for %s[%s]: %v", w.Type, event, err)
 		}
+	} else if w.Type == models.JSON {
+		// Generic JSON webhooks receive the event verbatim, the secret is
+		// only used for the signature and must never leak into the payload.
+		p.SetSecret("")
+		payloader = p
 	} else {
 		p.SetSecret(w.Secret)
 		payloader = p
@@ -171,7 +197,11 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 		if err != nil {
 			log.Error("prepareWebhooks.JSONPayload: %v", err)
 		}
-		sig := hmac.New(sha256.New, []byte(w.Secret))
+		hash := sha256.New
+		if w.SignatureType == models.HookSignatureSHA1 {
+			hash = sha1.New
+		}
+		sig := hmac.New(hash, []byte(w.Secret))
 		_, err = sig.Write(data)
 		if err != nil {
 			log.Error("prepareWebhooks.sigWrite: %v", err)
@@ -180,16 +210,17 @@ func prepareWebhook(w *models.Webhook, repo *models.Repository, event models.Hoo
 	}
 
 	if err = models.CreateHookTask(&models.HookTask{
-		RepoID:      repo.ID,
-		HookID:      w.ID,
-		Typ:         w.Type,
-		URL:         w.URL,
-		Signature:   signature,
-		Payloader:   payloader,
-		HTTPMethod:  w.HTTPMethod,
-		ContentType: w.ContentType,
-		EventType:   event,
-		IsSSL:       w.IsSSL,
+		RepoID:        repoID,
+		HookID:        w.ID,
+		Typ:           w.Type,
+		URL:           w.URL,
+		Signature:     signature,
+		SignatureType: w.SignatureType,
+		Payloader:     payloader,
+		HTTPMethod:    w.HTTPMethod,
+		ContentType:   w.ContentType,
+		EventType:     event,
+		IsSSL:         w.IsSSL,
 	}); err != nil {
 		return fmt.Errorf("CreateHookTask: %v", err)
 	}
@@ -234,7 +265,38 @@ func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.
 	}
 
 	for _, w := range ws {
-		if err = prepareWebhook(w, repo, event, p); err != nil {
+		if err = prepareWebhook(w, repo.ID, event, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrepareOrgWebhooks adds the webhooks of the organization and the system webhooks to the task queue
+// for the payload of an event of the organization which is not about one of its repositories.
+func PrepareOrgWebhooks(org *models.User, event models.HookEventType, p api.Payloader) error {
+	if err := prepareOrgWebhooks(org, event, p); err != nil {
+		return err
+	}
+
+	go hookQueue.Add(0)
+	return nil
+}
+
+func prepareOrgWebhooks(org *models.User, event models.HookEventType, p api.Payloader) error {
+	ws, err := models.GetActiveWebhooksByOrgID(org.ID)
+	if err != nil {
+		return fmt.Errorf("GetActiveWebhooksByOrgID: %v", err)
+	}
+
+	systemHooks, err := models.GetSystemWebhooks()
+	if err != nil {
+		return fmt.Errorf("GetSystemWebhooks: %v", err)
+	}
+	ws = append(ws, systemHooks...)
+
+	for _, w := range ws {
+		if err = prepareWebhook(w, 0, event, p); err != nil {
 			return err
 		}
 	}
@@ -40,6 +40,31 @@ func TestPrepareWebhooks(t *testing.T) {
 	}
 }
 
+func TestPrepareWebhookSignature(t *testing.T) {
+	assert.NoError(t, models.PrepareTestDatabase())
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	w := models.AssertExistsAndLoadBean(t, &models.Webhook{ID: 1}).(*models.Webhook)
+	assert.NoError(t, w.UpdateEvent())
+	w.Type = models.GITEA
+	w.Secret = "secret"
+
+	for _, sigType := range []models.HookSignatureType{models.HookSignatureDefault, models.HookSignatureSHA1, models.HookSignatureSHA256} {
+		w.SignatureType = sigType
+		assert.NoError(t, prepareWebhook(w, repo.ID, models.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
+	}
+
+	tasks, err := models.FindRepoUndeliveredHookTasks(repo.ID)
+	assert.NoError(t, err)
+	signatures := make(map[models.HookSignatureType]string)
+	for _, task := range tasks {
+		signatures[task.SignatureType] = task.Signature
+	}
+	assert.Len(t, signatures[models.HookSignatureDefault], 64)
+	assert.Len(t, signatures[models.HookSignatureSHA1], 40)
+	assert.Equal(t, signatures[models.HookSignatureDefault], signatures[models.HookSignatureSHA256])
+}
+
 func TestPrepareWebhooksBranchFilterMatch(t *testing.T) {
 	assert.NoError(t, models.PrepareTestDatabase())
 
@@ -74,6 +99,21 @@ func TestPrepareWebhooksBranchFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestCheckBranch(t *testing.T) {
+	w := &models.Webhook{HookEvent: &models.HookEvent{BranchFilter: "main,release/*,!release/*-rc"}}
+	assert.True(t, checkBranch(w, "main"))
+	assert.True(t, checkBranch(w, "release/1.14"))
+	assert.False(t, checkBranch(w, "release/1.14-rc"))
+	assert.False(t, checkBranch(w, "feature/x"))
+
+	w.BranchFilter = "!wip/*"
+	assert.True(t, checkBranch(w, "main"))
+	assert.False(t, checkBranch(w, "wip/x"))
+
+	w.BranchFilter = ""
+	assert.True(t, checkBranch(w, "wip/x"))
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
@@ -0,0 +1,391 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wiki
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// The formats wiki pages can be exported to
+const (
+	ExportFormatHTML = "html"
+	ExportFormatPDF  = "pdf"
+)
+
+// exportQueue represents a queue of the whole wiki exports to generate
+var exportQueue queue.UniqueQueue
+
+var hrefPattern = regexp.MustCompile(`href="([^"]+)"`)
+
+// InitExport runs the queue generating the exports of whole wikis
+func InitExport() error {
+	exportQueue = queue.CreateUniqueQueue("wiki_export", handleExport, "").(queue.UniqueQueue)
+
+	if exportQueue == nil {
+		return fmt.Errorf("Unable to create wiki_export Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(exportQueue.Run)
+	return nil
+}
+
+// IsExportFormatSupported returns whether wiki pages can be exported to the format
+func IsExportFormatSupported(format string) bool {
+	switch format {
+	case ExportFormatHTML:
+		return true
+	case ExportFormatPDF:
+		return len(setting.Repository.Wiki.PDFRenderCommand) > 0
+	}
+	return false
+}
+
+// exportPage is a wiki page rendered for an export
+type exportPage struct {
+	Name     string
+	Filename string
+	HTML     string
+}
+
+func renderExportPage(repo *models.Repository, entry *git.TreeEntry, name string) (*exportPage, error) {
+	reader, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	// relative links are resolved to the instance as the export is read outside of it
+	return &exportPage{
+		Name:     name,
+		Filename: entry.Name(),
+		HTML:     markdown.RenderWiki(content, repo.HTMLURL(), PageMetas(repo)),
+	}, nil
+}
+
+// exportDocument wraps rendered pages in a standalone HTML document, starting a new printed page for each of them
+func exportDocument(title string, pages ...*exportPage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(title) + "</title>\n")
+	buf.WriteString("<style>section{page-break-after:always}pre{white-space:pre-wrap;background:#f6f8fa;padding:8px}code{font-family:monospace}</style>\n")
+	buf.WriteString("</head>\n<body>\n")
+	for _, page := range pages {
+		buf.WriteString("<section>\n<h1>" + html.EscapeString(page.Name) + "</h1>\n" + page.HTML + "\n</section>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// convertDocument converts an HTML document to the format
+func convertDocument(document []byte, format string) ([]byte, error) {
+	if format == ExportFormatHTML {
+		return document, nil
+	}
+
+	commands := strings.Fields(setting.Repository.Wiki.PDFRenderCommand)
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no command is configured to render PDF")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(graceful.GetManager().ShutdownContext(), commands[0], commands[1:]...)
+	cmd.Stdin = bytes.NewReader(document)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %v - %s", commands[0], err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// ExportPage renders a page of a repository's wiki to a document of the format
+func ExportPage(repo *models.Repository, wikiName, format string) ([]byte, error) {
+	gitRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit("master")
+	if err != nil {
+		return nil, err
+	}
+	entry, err := commit.GetTreeEntryByPath(NameToFilename(wikiName))
+	if err != nil {
+		return nil, err
+	}
+	page, err := renderExportPage(repo, entry, wikiName)
+	if err != nil {
+		return nil, err
+	}
+	return convertDocument(exportDocument(wikiName, page), format)
+}
+
+// ExportRequest is a request for the export of all the pages of a repository's wiki
+type ExportRequest struct {
+	RepoID   int64
+	CommitID string
+	Format   string
+	// Zip bundles a document per page in a zip archive instead of a single document
+	Zip bool
+}
+
+// NewExportRequest creates a request for the export of the current pages of a repository's wiki
+func NewExportRequest(repo *models.Repository, format string, asZip bool) (*ExportRequest, error) {
+	gitRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commitID, err := gitRepo.GetBranchCommitID("master")
+	if err != nil {
+		return nil, err
+	}
+	return &ExportRequest{RepoID: repo.ID, CommitID: commitID, Format: format, Zip: asZip}, nil
+}
+
+func parseExportRequest(key string) (*ExportRequest, error) {
+	fields := strings.Split(key, ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("invalid wiki export key %q", key)
+	}
+	repoID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportRequest{RepoID: repoID, CommitID: fields[1], Format: fields[2], Zip: fields[3] == "zip"}, nil
+}
+
+func (r *ExportRequest) key() string {
+	bundle := "single"
+	if r.Zip {
+		bundle = "zip"
+	}
+	return fmt.Sprintf("%d:%s:%s:%s", r.RepoID, r.CommitID, r.Format, bundle)
+}
+
+func (r *ExportRequest) ext() string {
+	if r.Zip {
+		return "-" + r.Format + ".zip"
+	}
+	return "." + r.Format
+}
+
+// GetExportPath returns the path of the export in the archive storage
+func (r *ExportRequest) GetExportPath() string {
+	return fmt.Sprintf("%d/wiki-%s%s", r.RepoID, r.CommitID, r.ext())
+}
+
+// GetExportName returns the name the export is downloaded as
+func (r *ExportRequest) GetExportName(repo *models.Repository) string {
+	return repo.Name + "-wiki" + r.ext()
+}
+
+// IsComplete returns whether the export is available in the archive storage
+func (r *ExportRequest) IsComplete() (bool, error) {
+	if _, err := storage.RepoArchives.Stat(r.GetExportPath()); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ExportWiki queues the generation of the export unless it is complete, and returns whether it is
+func ExportWiki(r *ExportRequest) (bool, error) {
+	if complete, err := r.IsComplete(); err != nil || complete {
+		return complete, err
+	}
+	if err := exportQueue.Push(r.key()); err != nil && err != queue.ErrAlreadyInQueue {
+		return false, err
+	}
+	return false, nil
+}
+
+func handleExport(data ...queue.Data) {
+	for _, datum := range data {
+		r, err := parseExportRequest(datum.(string))
+		if err != nil {
+			log.Error("%v", err)
+			continue
+		}
+		if err := generateExport(r); err != nil {
+			log.Error("Unable to export the wiki of repository %d at %s: %v", r.RepoID, r.CommitID, err)
+		}
+	}
+}
+
+func generateExport(r *ExportRequest) error {
+	if complete, err := r.IsComplete(); err != nil || complete {
+		return err
+	}
+
+	repo, err := models.GetRepositoryByID(r.RepoID)
+	if err != nil {
+		return err
+	}
+	gitRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetCommit(r.CommitID)
+	if err != nil {
+		return err
+	}
+	pages, err := exportPagesInSidebarOrder(repo, commit)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if r.Zip {
+		archive := zip.NewWriter(&buf)
+		for _, page := range pages {
+			document, err := convertDocument(exportDocument(page.Name, page), r.Format)
+			if err != nil {
+				return err
+			}
+			w, err := archive.Create(strings.TrimSuffix(page.Filename, ".md") + "." + r.Format)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(document); err != nil {
+				return err
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return err
+		}
+	} else {
+		document, err := convertDocument(exportDocument(repo.FullName(), pages...), r.Format)
+		if err != nil {
+			return err
+		}
+		buf.Write(document)
+	}
+
+	_, err = storage.RepoArchives.Save(r.GetExportPath(), &buf)
+	return err
+}
+
+// exportPagesInSidebarOrder renders the pages of the wiki in the order in which the sidebar links them,
+// followed by the pages it does not link in alphabetical order
+func exportPagesInSidebarOrder(repo *models.Repository, commit *git.Commit) ([]*exportPage, error) {
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	entriesByFilename := make(map[string]*git.TreeEntry, len(entries))
+	filenames := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsRegular() {
+			continue
+		}
+		name, err := FilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			return nil, err
+		} else if name == "_Sidebar" || name == "_Footer" {
+			continue
+		}
+		entriesByFilename[entry.Name()] = entry
+		filenames = append(filenames, entry.Name())
+	}
+	sort.Strings(filenames)
+
+	ordered := make([]string, 0, len(filenames))
+	if sidebar, err := commit.GetTreeEntryByPath(NameToFilename("_Sidebar")); err == nil {
+		sidebarOrder, err := sidebarFilenames(repo, sidebar)
+		if err != nil {
+			return nil, err
+		}
+		for _, filename := range sidebarOrder {
+			if _, ok := entriesByFilename[filename]; ok && !util.IsStringInSlice(filename, ordered) {
+				ordered = append(ordered, filename)
+			}
+		}
+	} else if !git.IsErrNotExist(err) {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		if !util.IsStringInSlice(filename, ordered) {
+			ordered = append(ordered, filename)
+		}
+	}
+
+	pages := make([]*exportPage, 0, len(ordered))
+	for _, filename := range ordered {
+		name, _ := FilenameToName(filename)
+		page, err := renderExportPage(repo, entriesByFilename[filename], name)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// sidebarFilenames returns the filenames of the pages linked by the sidebar, in order
+func sidebarFilenames(repo *models.Repository, sidebar *git.TreeEntry) ([]string, error) {
+	reader, err := sidebar.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(reader, setting.UI.MaxDisplayFileSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// the links are resolved the way the sidebar is rendered on the wiki
+	wikiPrefix := repo.HTMLURL() + "/wiki/"
+	rendered := markdown.RenderWiki(content, repo.HTMLURL(), repo.ComposeDocumentMetas())
+	filenames := make([]string, 0, 10)
+	for _, match := range hrefPattern.FindAllStringSubmatch(rendered, -1) {
+		link := html.UnescapeString(match[1])
+		if !strings.HasPrefix(link, wikiPrefix) {
+			continue
+		}
+		subURL := strings.TrimPrefix(link, wikiPrefix)
+		if i := strings.IndexAny(subURL, "?#"); i >= 0 {
+			subURL = subURL[:i]
+		}
+		name, err := url.PathUnescape(subURL)
+		if err != nil || len(name) == 0 {
+			continue
+		}
+		filenames = append(filenames, NameToFilename(name))
+	}
+	return filenames, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wiki
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportPage(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	assert.True(t, IsExportFormatSupported(ExportFormatHTML))
+	assert.False(t, IsExportFormatSupported("docx"))
+
+	document, err := ExportPage(repo, "Home", ExportFormatHTML)
+	assert.NoError(t, err)
+	assert.Contains(t, string(document), "<title>Home</title>")
+	assert.Contains(t, string(document), "This is the home page!")
+
+	_, err = ExportPage(repo, "Nonexistent", ExportFormatHTML)
+	assert.True(t, git.IsErrNotExist(err))
+}
+
+func TestExportPagesInSidebarOrder(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	gitRepo, err := git.OpenRepository(repo.WikiPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit("master")
+	assert.NoError(t, err)
+
+	// without a sidebar the pages are in alphabetical order
+	pages, err := exportPagesInSidebarOrder(repo, commit)
+	assert.NoError(t, err)
+	names := make([]string, 0, len(pages))
+	for _, page := range pages {
+		names = append(names, page.Name)
+	}
+	assert.Equal(t, []string{"Home", "Page With Image", "Page With Spaced Name"}, names)
+
+	// the sidebar orders the pages it links
+	assert.NoError(t, AddWikiPage(models.AssertExistsAndLoadBean(t, &models.User{ID: 2}).(*models.User), repo, "_Sidebar",
+		"* [[Page With Spaced Name]]\n* [Image](Page-With-Image)\n", "Add sidebar"))
+	commit, err = gitRepo.GetBranchCommit("master")
+	assert.NoError(t, err)
+	pages, err = exportPagesInSidebarOrder(repo, commit)
+	assert.NoError(t, err)
+	names = names[:0]
+	for _, page := range pages {
+		names = append(names, page.Name)
+	}
+	assert.Equal(t, []string{"Page With Spaced Name", "Page With Image", "Home"}, names)
+}
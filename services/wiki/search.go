@@ -0,0 +1,212 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wiki
+
+import (
+	"bytes"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// maxSnippetLines is the maximum number of matching lines shown for a page found by grepping the wiki
+const maxSnippetLines = 3
+
+// SearchResult a wiki page matching a search
+type SearchResult struct {
+	Name           string
+	SubURL         string
+	IsTitleMatch   bool
+	LineNumbers    []int
+	FormattedLines string
+
+	rank int
+}
+
+// SearchWiki searches the titles and the contents of the pages of a repository's wiki.
+// Pages whose title matches come first, followed by the pages matching only by their
+// content. The code indexer is used when enabled and up to date with the wiki, the
+// wiki is grepped otherwise.
+func SearchWiki(repo *models.Repository, keyword string, page, pageSize int) (int, []*SearchResult, error) {
+	if len(keyword) == 0 || !repo.HasWiki() {
+		return 0, nil, nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.WikiPath())
+	if err != nil {
+		return 0, nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit("master")
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	pages := make(map[string]*SearchResult, len(entries))
+	lowerKeyword := strings.ToLower(keyword)
+	for _, entry := range entries {
+		if !entry.IsRegular() {
+			continue
+		}
+		name, err := FilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			return 0, nil, err
+		}
+		pages[entry.Name()] = &SearchResult{
+			Name:         name,
+			SubURL:       NameToSubURL(name),
+			IsTitleMatch: strings.Contains(strings.ToLower(name), lowerKeyword),
+			rank:         -1,
+		}
+	}
+
+	if useIndexer, err := isIndexerUsable(repo); err != nil {
+		return 0, nil, err
+	} else if useIndexer {
+		err = searchIndexer(repo, keyword, pages)
+	} else {
+		err = searchGrep(repo, keyword, pages)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	results := make([]*SearchResult, 0, len(pages))
+	for _, result := range pages {
+		if result.IsTitleMatch || result.rank >= 0 {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].IsTitleMatch != results[j].IsTitleMatch {
+			return results[i].IsTitleMatch
+		}
+		if results[i].rank != results[j].rank {
+			// pages not matching by their content come last
+			return results[j].rank < 0 || (results[i].rank >= 0 && results[i].rank < results[j].rank)
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	total := len(results)
+	start := util.Min((page-1)*pageSize, total)
+	end := util.Min(start+pageSize, total)
+	return total, results[start:end], nil
+}
+
+// isIndexerUsable returns whether the code indexer holds the current pages of the wiki,
+// queuing the wiki for indexing if it does not
+func isIndexerUsable(repo *models.Repository) (bool, error) {
+	if !setting.Indexer.RepoIndexerEnabled {
+		return false, nil
+	}
+	upToDate, err := code_indexer.IsWikiIndexUpToDate(repo)
+	if err != nil {
+		return false, err
+	}
+	if !upToDate {
+		code_indexer.UpdateWikiIndexer(repo)
+	}
+	return upToDate, nil
+}
+
+func searchIndexer(repo *models.Repository, keyword string, pages map[string]*SearchResult) error {
+	// every page is a single document of the indexer, so this returns all the matches
+	_, results, _, err := code_indexer.PerformSearch([]int64{-repo.ID}, "", keyword, 1, util.Max(len(pages), 1), false)
+	if err != nil {
+		return err
+	}
+	for i, result := range results {
+		page, ok := pages[result.Filename]
+		if !ok {
+			continue
+		}
+		page.rank = i
+		page.LineNumbers = result.LineNumbers
+		page.FormattedLines = result.FormattedLines
+	}
+	return nil
+}
+
+// searchGrep greps the pages of the wiki, ranking them by their number of matching lines
+func searchGrep(repo *models.Repository, keyword string, pages map[string]*SearchResult) error {
+	stdout, err := git.NewCommand("grep", "--full-name", "-z", "-n", "-I", "-i", "-F", "-e", keyword, "master", "--").
+		RunInDirBytes(repo.WikiPath())
+	if err != nil {
+		// git grep exits with 1 when nothing matches
+		if err.Error() == "exit status 1" {
+			return nil
+		}
+		return err
+	}
+
+	counts := make(map[string]int)
+	snippets := make(map[string]*bytes.Buffer)
+	for _, line := range bytes.Split(stdout, []byte{'\n'}) {
+		// lines are formatted as master:<filename>\x00<line number>\x00<content>
+		fields := bytes.SplitN(bytes.TrimPrefix(line, []byte("master:")), []byte{0}, 3)
+		if len(fields) != 3 {
+			continue
+		}
+		filename := string(fields[0])
+		page, ok := pages[filename]
+		if !ok {
+			continue
+		}
+		counts[filename]++
+		if len(page.LineNumbers) == maxSnippetLines {
+			continue
+		}
+		lineNumber, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			log.Error("Unable to parse the line number of git grep output %q: %v", line, err)
+			continue
+		}
+		if snippets[filename] == nil {
+			snippets[filename] = &bytes.Buffer{}
+		} else {
+			snippets[filename].WriteByte('\n')
+		}
+		snippets[filename].WriteString(html.EscapeString(string(fields[2])))
+		page.LineNumbers = append(page.LineNumbers, lineNumber)
+	}
+
+	filenames := make([]string, 0, len(counts))
+	for filename := range counts {
+		filenames = append(filenames, filename)
+	}
+	sort.SliceStable(filenames, func(i, j int) bool {
+		if counts[filenames[i]] != counts[filenames[j]] {
+			return counts[filenames[i]] > counts[filenames[j]]
+		}
+		return filenames[i] < filenames[j]
+	})
+	for i, filename := range filenames {
+		pages[filename].rank = i
+		if snippet := snippets[filename]; snippet != nil {
+			pages[filename].FormattedLines = snippet.String()
+		}
+	}
+	return nil
+}
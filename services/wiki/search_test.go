@@ -0,0 +1,49 @@
+// Copyright 2021 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package wiki
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchWiki(t *testing.T) {
+	models.PrepareTestEnv(t)
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+
+	total, results, err := SearchWiki(repo, "home", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Home", results[0].Name)
+		assert.True(t, results[0].IsTitleMatch)
+		assert.Equal(t, []int{1, 3}, results[0].LineNumbers)
+	}
+
+	total, results, err = SearchWiki(repo, "spaces", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, "Page With Spaced Name", results[0].Name)
+		assert.False(t, results[0].IsTitleMatch)
+		assert.Equal(t, []int{3}, results[0].LineNumbers)
+	}
+
+	// title matches come first, then the pages with the most matching lines
+	total, results, err = SearchWiki(repo, "page", 1, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "Page With Spaced Name", results[0].Name)
+		assert.Equal(t, "Page With Image", results[1].Name)
+	}
+
+	total, results, err = SearchWiki(repo, "nonexistent", 1, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+	assert.Empty(t, results)
+}
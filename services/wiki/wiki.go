@@ -13,14 +13,17 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/git"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup/markdown"
 	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/util"
 )
 
 var (
-	reservedWikiNames = []string{"_pages", "_new", "_edit", "raw"}
+	reservedWikiNames = []string{"_pages", "_new", "_edit", "_export", "raw", "search"}
 	wikiWorkingPool   = sync.NewExclusivePool()
 )
 
@@ -38,6 +41,21 @@ func NameToSubURL(name string) string {
 	return url.QueryEscape(strings.ReplaceAll(name, " ", "-"))
 }
 
+// PageMetas returns the metas to render the pages of a repository's wiki with,
+// requesting a table of contents when the wiki is configured to include one
+func PageMetas(repo *models.Repository) map[string]string {
+	documentMetas := repo.ComposeDocumentMetas()
+	// the document metas are cached by the repository, so they are copied before being changed
+	metas := make(map[string]string, len(documentMetas)+1)
+	for k, v := range documentMetas {
+		metas[k] = v
+	}
+	if unit, err := repo.GetUnit(models.UnitTypeWiki); err == nil && unit.WikiConfig().IncludeTOC {
+		metas[markdown.IncludeTOCMetaKey] = "true"
+	}
+	return metas
+}
+
 // NormalizeWikiName normalizes a wiki name
 func NormalizeWikiName(name string) string {
 	return strings.ReplaceAll(name, "-", " ")
@@ -224,6 +242,10 @@ func updateWikiPage(doer *models.User, repo *models.Repository, oldWikiName, new
 		return fmt.Errorf("Push: %v", err)
 	}
 
+	if setting.Indexer.RepoIndexerEnabled {
+		code_indexer.UpdateWikiIndexer(repo)
+	}
+
 	return nil
 }
 
@@ -336,5 +358,9 @@ func DeleteWikiPage(doer *models.User, repo *models.Repository, wikiName string)
 		return fmt.Errorf("Push: %v", err)
 	}
 
+	if setting.Indexer.RepoIndexerEnabled {
+		code_indexer.UpdateWikiIndexer(repo)
+	}
+
 	return nil
 }
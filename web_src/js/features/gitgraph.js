@@ -14,7 +14,7 @@ export default async function initGitGraph() {
     } else {
       window.history.replaceState({}, '', window.location.pathname);
     }
-    $('.pagination a').each((_, that) => {
+    $('#pagination a').each((_, that) => {
       const href = $(that).attr('href');
       if (!href) return;
       const url = new URL(href, window.location);
@@ -28,7 +28,7 @@ export default async function initGitGraph() {
     $('#flow-color-colored').addClass('active');
     $('#flow-color-monochrome').removeClass('active');
     $('#git-graph-container').addClass('colored').removeClass('monochrome');
-    $('.pagination a').each((_, that) => {
+    $('#pagination a').each((_, that) => {
       const href = $(that).attr('href');
       if (!href) return;
       const url = new URL(href, window.location);
@@ -49,6 +49,9 @@ export default async function initGitGraph() {
   const url = new URL(window.location);
   const params = url.searchParams;
   const updateGraph = async () => {
+    // a changed selection starts again from the most recent commits
+    params.delete('skip');
+    params.delete('page');
     const queryString = params.toString();
     const ajaxUrl = new URL(url);
     ajaxUrl.searchParams.set('div-only', 'true');
@@ -66,6 +69,45 @@ export default async function initGitGraph() {
     $('#rel-container').removeClass('hide');
     $('#rev-container').removeClass('hide');
   };
+  const mergeFlows = (newSvg) => {
+    const svg = $('#rel-container svg');
+    for (const group of newSvg.children('g').toArray()) {
+      const existing = svg.children(`#${group.id}`);
+      if (!existing.length) {
+        svg.append(group);
+        continue;
+      }
+      // a flow continuing from the already rendered commits
+      const path = existing.children('path');
+      path.attr('d', `${path.attr('d')} ${$(group).children('path').attr('d')}`);
+      existing.append($(group).children('circle'));
+    }
+    // the new rows continue the existing ones, so the new view box covers all the rows
+    const [minX, minY, width, height] = newSvg.attr('viewBox').split(' ');
+    const oldWidth = svg.attr('viewBox').split(' ')[2];
+    svg.attr('viewBox', `${minX} ${minY} ${Math.max(width, oldWidth)} ${height}`);
+    if (parseInt(newSvg.attr('width')) > parseInt(svg.attr('width'))) {
+      svg.attr('width', newSvg.attr('width'));
+    }
+  };
+  $('#pagination').on('click', '#graph-load-older', async (e) => {
+    e.preventDefault();
+    const button = $(e.currentTarget);
+    if (button.hasClass('loading')) return;
+    button.addClass('loading');
+    const ajaxUrl = new URL(url);
+    ajaxUrl.searchParams.set('div-only', 'true');
+    ajaxUrl.searchParams.set('skip', button.data('skip'));
+    ajaxUrl.searchParams.set('row', button.data('row'));
+    try {
+      const div = $(await $.ajax(String(ajaxUrl)));
+      mergeFlows(div.find('#rel-container svg'));
+      $('#rev-list').append(div.find('#rev-list').children());
+      $('#pagination').html(div.find('#pagination').html());
+    } catch {
+      button.removeClass('loading');
+    }
+  });
   const dropdownSelected = params.getAll('branch');
   if (params.has('hide-pr-refs') && params.get('hide-pr-refs') === 'true') {
     dropdownSelected.splice(0, 0, '...flow-hide-pr-refs');
@@ -0,0 +1,33 @@
+const {csrf} = window.config;
+
+export default async function initPinnedIssues() {
+  const list = document.querySelector('#pinned-issues[data-url] .list');
+  if (!list) return;
+
+  const {Sortable} = await import(/* webpackChunkName: "sortable" */'sortablejs');
+  const container = list.closest('#pinned-issues');
+
+  new Sortable(list, {
+    draggable: '.item',
+    handle: '.pinned-issue-handle',
+    animation: 150,
+    onSort: (e) => {
+      const issueIds = Array.from(list.querySelectorAll('.item'), (item) => parseInt(item.dataset.issueId));
+      $.ajax({
+        url: container.dataset.url,
+        data: JSON.stringify({is_pull: container.dataset.isPull === 'true', issue_ids: issueIds}),
+        headers: {
+          'X-Csrf-Token': csrf,
+          'X-Remote': true,
+        },
+        contentType: 'application/json',
+        method: 'POST',
+        error: () => {
+          // put the item back where it was, the order on the server is unchanged
+          const reference = list.children[e.oldIndex < e.newIndex ? e.oldIndex : e.oldIndex + 1];
+          list.insertBefore(e.item, reference || null);
+        },
+      });
+    },
+  });
+}
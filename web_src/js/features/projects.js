@@ -64,6 +64,7 @@ export default async function initProject() {
     const projectTitleInput = $(this).find(
       '.content > .form > .field > .project-board-title',
     );
+    const projectTriggerInputs = $(this).find('.project-board-trigger');
 
     $(this)
       .find('.content > .form > .actions > .red')
@@ -72,7 +73,10 @@ export default async function initProject() {
 
         $.ajax({
           url: $(this).data('url'),
-          data: JSON.stringify({title: projectTitleInput.val()}),
+          data: JSON.stringify({
+            title: projectTitleInput.val(),
+            triggers: projectTriggerInputs.filter(':checked').map((_, el) => parseInt(el.value)).get(),
+          }),
           headers: {
             'X-Csrf-Token': csrf,
             'X-Remote': true,
@@ -0,0 +1,170 @@
+const {AppSubUrl, csrf} = window.config;
+
+function encodeURLEncodedBase64(value) {
+  const bytes = new Uint8Array(value);
+  let binary = '';
+  for (const byte of bytes) {
+    binary += String.fromCharCode(byte);
+  }
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=/g, '');
+}
+
+function decodeURLEncodedBase64(value) {
+  const binary = atob(value.replace(/-/g, '+').replace(/_/g, '/'));
+  return Uint8Array.from(binary, (c) => c.charCodeAt(0));
+}
+
+function webAuthnSupported() {
+  return window.isSecureContext && window.PublicKeyCredential !== undefined;
+}
+
+function webAuthnError(errorType) {
+  const elements = $('#webauthn-error .negative.message > div[id]');
+  elements.addClass('hide');
+  $(`#webauthn-error-${errorType}`).removeClass('hide');
+  $('#webauthn-error-reload').toggleClass('hide', errorType !== 'timeout');
+  $('#webauthn-error').modal('show');
+}
+
+function errorTypeOf(err) {
+  if (!window.isSecureContext) return 'insecure';
+  switch (err && err.name) {
+    case 'InvalidStateError':
+      return 'duplicated';
+    case 'NotAllowedError':
+    case 'AbortError':
+      return 'timeout';
+    default:
+      return 'unknown';
+  }
+}
+
+async function postJSON(url, data) {
+  const res = await fetch(url, {
+    method: 'POST',
+    headers: {'X-Csrf-Token': csrf, 'Content-Type': 'application/json; charset=utf-8'},
+    body: JSON.stringify(data),
+  });
+  if (!res.ok) throw new Error(`unexpected status ${res.status}`);
+  return res;
+}
+
+export async function initWebAuthnAuth() {
+  const waitForKey = $('#wait-for-webauthn-key');
+  if (waitForKey.length === 0) {
+    return;
+  }
+  if (!webAuthnSupported()) {
+    // Fallback in case the browser does not support WebAuthn
+    window.location.href = waitForKey.data('fallback-url');
+    return;
+  }
+
+  let options;
+  try {
+    const res = await fetch(`${AppSubUrl}/user/webauthn/challenge`);
+    if (!res.ok) throw new Error(`unexpected status ${res.status}`);
+    options = await res.json();
+  } catch {
+    webAuthnError('unable-to-process');
+    return;
+  }
+
+  const publicKey = options.publicKey;
+  publicKey.challenge = decodeURLEncodedBase64(publicKey.challenge);
+  for (const cred of publicKey.allowCredentials || []) {
+    cred.id = decodeURLEncodedBase64(cred.id);
+  }
+
+  let credential;
+  try {
+    credential = await navigator.credentials.get({publicKey});
+  } catch (err) {
+    webAuthnError(errorTypeOf(err));
+    return;
+  }
+
+  const {response} = credential;
+  try {
+    const res = await postJSON(`${AppSubUrl}/user/webauthn/sign`, {
+      id: credential.id,
+      rawId: encodeURLEncodedBase64(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: encodeURLEncodedBase64(response.clientDataJSON),
+        authenticatorData: encodeURLEncodedBase64(response.authenticatorData),
+        signature: encodeURLEncodedBase64(response.signature),
+        userHandle: response.userHandle ? encodeURLEncodedBase64(response.userHandle) : '',
+      },
+    });
+    window.location.replace(await res.text());
+  } catch {
+    webAuthnError('unknown');
+  }
+}
+
+async function webAuthnRegisterRequest() {
+  const nickname = $('#webauthn-nickname');
+  let options;
+  try {
+    options = await $.post(`${AppSubUrl}/user/settings/security/webauthn/request_register`, {
+      _csrf: csrf,
+      name: nickname.val(),
+    });
+  } catch (xhr) {
+    if (xhr.status === 409) {
+      nickname.closest('div.field').addClass('error');
+    } else {
+      webAuthnError('unable-to-process');
+    }
+    return;
+  }
+  nickname.closest('div.field').removeClass('error');
+  $('#register-webauthn-device').modal('show');
+
+  const publicKey = options.publicKey;
+  publicKey.challenge = decodeURLEncodedBase64(publicKey.challenge);
+  publicKey.user.id = decodeURLEncodedBase64(publicKey.user.id);
+  for (const cred of publicKey.excludeCredentials || []) {
+    cred.id = decodeURLEncodedBase64(cred.id);
+  }
+
+  let credential;
+  try {
+    credential = await navigator.credentials.create({publicKey});
+  } catch (err) {
+    webAuthnError(errorTypeOf(err));
+    return;
+  }
+
+  try {
+    await postJSON(`${AppSubUrl}/user/settings/security/webauthn/register`, {
+      id: credential.id,
+      rawId: encodeURLEncodedBase64(credential.rawId),
+      type: credential.type,
+      response: {
+        clientDataJSON: encodeURLEncodedBase64(credential.response.clientDataJSON),
+        attestationObject: encodeURLEncodedBase64(credential.response.attestationObject),
+      },
+    });
+    window.location.reload();
+  } catch {
+    webAuthnError('unknown');
+  }
+}
+
+export function initWebAuthnRegister() {
+  if ($('#register-webauthn-key').length === 0) {
+    return;
+  }
+  $('#register-webauthn-device').modal({allowMultiple: false});
+  $('#webauthn-error').modal({allowMultiple: false});
+  $('#register-webauthn-key').on('click', async (e) => {
+    e.preventDefault();
+    if (!webAuthnSupported()) {
+      webAuthnError(window.isSecureContext ? 'browser' : 'insecure');
+      return;
+    }
+    await webAuthnRegisterRequest();
+  });
+}
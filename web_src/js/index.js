@@ -13,6 +13,7 @@ import initGitGraph from './features/gitgraph.js';
 import initClipboard from './features/clipboard.js';
 import initHeatmap from './features/heatmap.js';
 import initProject from './features/projects.js';
+import initPinnedIssues from './features/pinnedissues.js';
 import initServiceWorker from './features/serviceworker.js';
 import initMarkdownAnchors from './markdown/anchors.js';
 import renderMarkdownContent from './markdown/content.js';
@@ -24,6 +25,7 @@ import initImageDiff from './features/imagediff.js';
 import ActivityTopAuthors from './components/ActivityTopAuthors.vue';
 import {initNotificationsTable, initNotificationCount} from './features/notification.js';
 import {initStopwatch} from './features/stopwatch.js';
+import {initWebAuthnAuth, initWebAuthnRegister} from './features/webauthn.js';
 import {createCodeEditor, createMonaco} from './features/codeeditor.js';
 import {svg, svgs} from './svg.js';
 import {stripTags} from './utils.js';
@@ -195,6 +197,21 @@ function updateIssuesMeta(url, action, issueIds, elementId) {
   }));
 }
 
+function initPullRequestMergeStatusChecker() {
+  const status = $('#pull-merge-status[data-url]');
+  if (!status.length) return;
+  setTimeout(() => {
+    $.get(status.data('url'), (data) => {
+      // the page shows the new status, with the conflicting files if any
+      if (data.status !== 'checking') {
+        window.location.reload();
+        return;
+      }
+      initPullRequestMergeStatusChecker();
+    });
+  }, 2000);
+}
+
 function initRepoStatusChecker() {
   const migrating = $('#repo_migrating');
   $('#repo_migrating_failed').hide();
@@ -962,6 +979,21 @@ async function initRepository() {
       event.preventDefault();
     });
 
+    // Extract comment to a new issue
+    $(document).on('click', '.extract-issue', function (event) {
+      const $this = $(this);
+
+      $this.closest('.dropdown').find('.menu').toggle('visible');
+
+      const content = $(`#comment-${$this.data('target')}`).text();
+      const $modal = $($this.data('modal'));
+      $modal.find('form').attr('action', $this.data('url'));
+      $modal.find('input[name="title"]').val(content.split('\n', 1)[0].slice(0, 255));
+      $modal.modal('show');
+
+      event.preventDefault();
+    });
+
     // Edit issue or comment content
     $(document).on('click', '.edit-content', async function (event) {
       $(this).closest('.dropdown').find('.menu').toggle('visible');
@@ -1259,8 +1291,9 @@ function initPullRequestMergeInstruction() {
 
 function initPullRequestReview() {
   if (window.location.hash && window.location.hash.startsWith('#issuecomment-')) {
-    const commentDiv = $(window.location.hash);
-    if (commentDiv) {
+    loadDiffLinesForHash().then((loaded) => {
+      const commentDiv = $(window.location.hash);
+      if (commentDiv.length === 0) return;
       // get the name of the parent id
       const groupID = commentDiv.closest('div[id^="code-comments-"]').attr('id');
       if (groupID && groupID.startsWith('code-comments-')) {
@@ -1270,8 +1303,10 @@ function initPullRequestReview() {
         $(`#code-preview-${id}`).removeClass('hide');
         $(`#hide-outdated-${id}`).removeClass('hide');
         $(window).scrollTop(commentDiv.offset().top);
+      } else if (loaded) {
+        $(window).scrollTop(commentDiv.offset().top);
       }
-    }
+    });
   }
 
   $(document).on('click', '.show-outdated', function (e) {
@@ -1323,7 +1358,7 @@ function initPullRequestReview() {
     $(this).closest('.menu').toggle('visible');
   });
 
-  $('a.add-code-comment').on('click', async function (e) {
+  $(document).on('click', 'a.add-code-comment', async function (e) {
     if ($(e.target).hasClass('btn-add-single')) return; // https://github.com/go-gitea/gitea/issues/4745
     e.preventDefault();
 
@@ -1847,9 +1882,15 @@ function initWebhook() {
   $('#test-delivery').on('click', function () {
     const $this = $(this);
     $this.addClass('loading disabled');
-    $.post($this.data('link'), {
-      _csrf: csrf
-    }).done(
+    const data = {
+      _csrf: csrf,
+      branch: $('#test-delivery-branch').val()
+    };
+    const $branchFilter = $('input[name=branch_filter]');
+    if ($branchFilter.length) {
+      data.branch_filter = $branchFilter.val();
+    }
+    $.post($this.data('link'), data).done(
       setTimeout(() => {
         window.location.href = $this.data('redirect');
       }, 5000)
@@ -1972,9 +2013,9 @@ function initAdmin() {
   // New authentication
   if ($('.admin.new.authentication').length > 0) {
     $('#auth_type').on('change', function () {
-      $('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi').hide();
+      $('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi, .saml').hide();
 
-      $('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required]').removeAttr('required');
+      $('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required], .saml [required]').removeAttr('required');
       $('.binddnrequired').removeClass('required');
 
       const authType = $(this).val();
@@ -2006,6 +2047,10 @@ function initAdmin() {
           $('.sspi').show();
           $('.sspi div.required input').attr('required', 'required');
           break;
+        case '8': // SAML
+          $('.saml').show();
+          $('.saml div.required input, .saml div.required textarea').attr('required', 'required');
+          break;
       }
       if (authType === '2' || authType === '5') {
         onSecurityProtocolChange();
@@ -2216,6 +2261,32 @@ function initCodeView() {
     const blob = await $.get(`${url}?${query}&anchor=${anchor}`);
     currentTarget.closest('tr').outerHTML = blob;
   });
+  $(document).on('click', '.diff-load-more', async ({currentTarget}) => {
+    await loadMoreDiffLines(currentTarget);
+  });
+}
+
+async function loadMoreDiffLines(button) {
+  const {url, query} = button.dataset;
+  if (!url) return;
+  const lines = await $.get(`${url}?${query}`);
+  button.closest('tr').outerHTML = lines;
+}
+
+// Loads the truncated parts of the diff until the element the location hash points to is shown
+async function loadDiffLinesForHash() {
+  let loaded = false;
+  while ($(window.location.hash).length === 0) {
+    const button = document.querySelector('.diff-load-more');
+    if (!button) break;
+    try {
+      await loadMoreDiffLines(button);
+    } catch {
+      break;
+    }
+    loaded = true;
+  }
+  return loaded;
 }
 
 function initU2FAuth() {
@@ -2567,6 +2638,19 @@ $(document).ready(async () => {
   $('.link-email-action').on('click', linkEmailAction);
 
   $('.delete-branch-button').on('click', showDeletePopup);
+  $('.delete-merged-branches-button').on('click', () => {
+    const $modal = $('.delete-merged-branches.modal');
+    const $form = $modal.find('form');
+    $modal.modal({
+      closable: false,
+      onApprove() {
+        $.post($form.attr('action'), $form.serialize()).done((data) => {
+          window.location.href = data.redirect;
+        });
+      }
+    }).modal('show');
+    return false;
+  });
 
   $('.undo-button').on('click', function () {
     const $this = $(this);
@@ -2676,12 +2760,15 @@ $(document).ready(async () => {
   initTopicbar();
   initU2FAuth();
   initU2FRegister();
+  initWebAuthnAuth();
+  initWebAuthnRegister();
   initIssueList();
   initIssueTimetracking();
   initIssueDue();
   initWipTitle();
   initPullRequestReview();
   initRepoStatusChecker();
+  initPullRequestMergeStatusChecker();
   initTemplateSearch();
   initIssueReferenceRepositorySearch();
   initContextPopups();
@@ -2709,6 +2796,7 @@ $(document).ready(async () => {
     initClipboard(),
     initHeatmap(),
     initProject(),
+    initPinnedIssues(),
     initServiceWorker(),
     initNotificationCount(),
     initStopwatch(),